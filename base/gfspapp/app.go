@@ -6,6 +6,7 @@ import (
 
 	"google.golang.org/grpc"
 
+	storagetypes "github.com/evmos/evmos/v12/x/storage/types"
 	"github.com/zkMeLabs/mechain-storage-provider/base/gfspclient"
 	"github.com/zkMeLabs/mechain-storage-provider/core/consensus"
 	corelifecycle "github.com/zkMeLabs/mechain-storage-provider/core/lifecycle"
@@ -15,7 +16,12 @@ import (
 	corercmgr "github.com/zkMeLabs/mechain-storage-provider/core/rcmgr"
 	"github.com/zkMeLabs/mechain-storage-provider/core/spdb"
 	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/mtls"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/tracing"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/uploadprogress"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/webhook"
 	"github.com/zkMeLabs/mechain-storage-provider/store/bsdb"
+	"github.com/zkMeLabs/mechain-storage-provider/store/types"
 )
 
 const (
@@ -30,6 +36,8 @@ type GfSpBaseApp struct {
 
 	server *grpc.Server
 	client gfspclient.GfSpClientAPI
+	// tlsReloader is nil if the tls of the internal endpoints is disabled
+	tlsReloader *mtls.Reloader
 
 	gfSpDB       spdb.SPDB
 	gfBsDB       bsdb.BSDB
@@ -40,6 +48,10 @@ type GfSpBaseApp struct {
 	rcmgr      corercmgr.ResourceManager
 	chain      consensus.Consensus
 	httpProbe  coreprober.Prober
+	webhook    *webhook.Dispatcher
+	// webhookCipher encrypts the webhook subscription secrets stored in SPDB.
+	webhookCipher *webhook.SecretCipher
+	progress      *uploadprogress.Hub
 
 	approver      module.Approver
 	authenticator module.Authenticator
@@ -54,6 +66,8 @@ type GfSpBaseApp struct {
 	metrics       module.Modular
 	pprof         module.Modular
 	probeSvr      module.Modular
+	// tracing is nil if the tracing is disabled
+	tracing *tracing.Provider
 
 	appCtx    context.Context
 	appCancel context.CancelFunc
@@ -205,6 +219,69 @@ func (g *GfSpBaseApp) SetProbe(prober coreprober.Prober) {
 	g.httpProbe = prober
 }
 
+// Webhook returns the webhook dispatcher which pushes the object and bucket events to
+// the bucket owners, notice it is nil if webhook is disabled and Notify is nil-safe.
+func (g *GfSpBaseApp) Webhook() *webhook.Dispatcher {
+	return g.webhook
+}
+
+// SetWebhook sets the webhook dispatcher.
+func (g *GfSpBaseApp) SetWebhook(dispatcher *webhook.Dispatcher) {
+	g.webhook = dispatcher
+}
+
+// WebhookSecretCipher returns the cipher of the webhook subscription secrets, it is nil if webhook is disabled.
+func (g *GfSpBaseApp) WebhookSecretCipher() *webhook.SecretCipher {
+	return g.webhookCipher
+}
+
+// SetWebhookSecretCipher sets the cipher of the webhook subscription secrets.
+func (g *GfSpBaseApp) SetWebhookSecretCipher(secretCipher *webhook.SecretCipher) {
+	g.webhookCipher = secretCipher
+}
+
+// NotifyObjectEvent pushes the object event to the webhook subscriber of the bucket owner,
+// it does nothing if webhook is disabled.
+func (g *GfSpBaseApp) NotifyObjectEvent(eventType webhook.EventType, objectInfo *storagetypes.ObjectInfo, detail string) {
+	if g.webhook == nil || objectInfo == nil {
+		return
+	}
+	g.webhook.Notify(&webhook.Event{
+		Type:       eventType,
+		BucketName: objectInfo.GetBucketName(),
+		ObjectName: objectInfo.GetObjectName(),
+		ObjectID:   objectInfo.Id.Uint64(),
+		Detail:     detail,
+	})
+}
+
+// UploadProgress returns the hub which fans out the upload state transitions of the objects
+// to the subscribers.
+func (g *GfSpBaseApp) UploadProgress() *uploadprogress.Hub {
+	return g.progress
+}
+
+// SetUploadProgress sets the upload progress hub.
+func (g *GfSpBaseApp) SetUploadProgress(hub *uploadprogress.Hub) {
+	g.progress = hub
+}
+
+// PublishUploadProgress publishes the upload state transition of the object, it does nothing
+// if there is no upload progress hub.
+func (g *GfSpBaseApp) PublishUploadProgress(objectInfo *storagetypes.ObjectInfo, state types.TaskState, errDescription string) {
+	if g.progress == nil || objectInfo == nil {
+		return
+	}
+	g.progress.Publish(&uploadprogress.Event{
+		BucketName:       objectInfo.GetBucketName(),
+		ObjectName:       objectInfo.GetObjectName(),
+		ObjectID:         objectInfo.Id.Uint64(),
+		State:            int32(state),
+		StateDescription: types.StateToDescription(state),
+		ErrorDescription: errDescription,
+	})
+}
+
 // Start the GfSpBaseApp and blocks the progress until signal.
 func (g *GfSpBaseApp) Start(ctx context.Context) error {
 	g.httpProbe.Healthy()
@@ -225,6 +302,9 @@ func (g *GfSpBaseApp) close(ctx context.Context) error {
 	_ = g.GfSpClient().Close()
 	_ = g.rcmgr.Close()
 	_ = g.chain.Close()
+	g.webhook.Close()
+	g.progress.Close()
+	_ = g.tracing.Close()
 	return nil
 }
 
@@ -1,10 +1,13 @@
 package gfspapp
 
 import (
+	"context"
+	"fmt"
 	"math"
 	"os"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/zkMeLabs/mechain-storage-provider/base/gfspclient"
 	"github.com/zkMeLabs/mechain-storage-provider/base/gfspconfig"
@@ -14,12 +17,16 @@ import (
 	"github.com/zkMeLabs/mechain-storage-provider/base/gfspvgmgr"
 	"github.com/zkMeLabs/mechain-storage-provider/base/gnfd"
 	"github.com/zkMeLabs/mechain-storage-provider/base/types/gfsplimit"
+	"github.com/zkMeLabs/mechain-storage-provider/core/consensus"
 	coremodule "github.com/zkMeLabs/mechain-storage-provider/core/module"
 	corercmgr "github.com/zkMeLabs/mechain-storage-provider/core/rcmgr"
 	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
 	"github.com/zkMeLabs/mechain-storage-provider/pkg/metrics"
 	"github.com/zkMeLabs/mechain-storage-provider/pkg/pprof"
 	"github.com/zkMeLabs/mechain-storage-provider/pkg/probe"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/tracing"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/uploadprogress"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/webhook"
 	"github.com/zkMeLabs/mechain-storage-provider/store/bsdb"
 	"github.com/zkMeLabs/mechain-storage-provider/store/config"
 	psclient "github.com/zkMeLabs/mechain-storage-provider/store/piecestore/client"
@@ -221,7 +228,18 @@ func DefaultStaticOption(app *GfSpBaseApp, cfg *gfspconfig.GfSpConfig) error {
 	app.signer = &coremodule.NilModular{}
 	app.metrics = &coremodule.NilModular{}
 	app.pprof = &coremodule.NilModular{}
-	app.newRPCServer()
+	tlsReloader, err := NewTLSReloader(&cfg.Endpoint)
+	if err != nil {
+		log.Errorw("failed to load tls certificates of endpoints", "error", err)
+		return err
+	}
+	app.tlsReloader = tlsReloader
+	serverOptions, err := app.tlsServerOptions(&cfg.Endpoint)
+	if err != nil {
+		log.Errorw("failed to init grpc server tls options", "error", err)
+		return err
+	}
+	app.newRPCServer(serverOptions...)
 	return nil
 }
 
@@ -256,7 +274,7 @@ func DefaultGfSpClientOption(app *GfSpBaseApp, cfg *gfspconfig.GfSpConfig) error
 	if cfg.Endpoint.AuthenticatorEndpoint == "" {
 		cfg.Endpoint.AuthenticatorEndpoint = cfg.GRPCAddress
 	}
-	app.client = gfspclient.NewGfSpClient(
+	client := gfspclient.NewGfSpClient(
 		cfg.Endpoint.ApproverEndpoint,
 		cfg.Endpoint.ManagerEndpoint,
 		cfg.Endpoint.DownloaderEndpoint,
@@ -267,6 +285,11 @@ func DefaultGfSpClientOption(app *GfSpBaseApp, cfg *gfspconfig.GfSpConfig) error
 		cfg.Endpoint.SignerEndpoint,
 		cfg.Endpoint.AuthenticatorEndpoint,
 		!cfg.Monitor.DisableMetrics)
+	if app.tlsReloader != nil {
+		client.SetTransportCredentials(app.tlsReloader.ClientCredentials())
+	}
+	client.SetCallerModules(cfg.Server)
+	app.client = client
 	return nil
 }
 
@@ -278,7 +301,11 @@ func DefaultGfSpDBOption(app *GfSpBaseApp, cfg *gfspconfig.GfSpConfig) error {
 		return nil
 	}
 	for _, v := range cfg.Server {
-		if v == coremodule.BlockSyncerModularName || v == coremodule.SignModularName || v == coremodule.GateModularName {
+		// gateway needs sp db to manage the webhook subscriptions, the bucket CORS rules and the account rate
+		// limit overrides if they are enabled
+		if v == coremodule.BlockSyncerModularName || v == coremodule.SignModularName ||
+			(v == coremodule.GateModularName && !cfg.Webhook.Enable && !cfg.Gateway.EnableBucketCORS &&
+				!cfg.APIRateLimiter.AccountLimitCfg.On) {
 			log.Infof("[%s] module doesn't need sp db", v)
 			continue
 		}
@@ -500,8 +527,7 @@ func DefaultGfSpResourceManagerOption(app *GfSpBaseApp, cfg *gfspconfig.GfSpConf
 
 func DefaultGfSpConsensusOption(app *GfSpBaseApp, cfg *gfspconfig.GfSpConfig) error {
 	if cfg.Customize.Consensus != nil {
-		app.chain = cfg.Customize.Consensus
-		return nil
+		return setGfSpConsensus(app, cfg, cfg.Customize.Consensus)
 	}
 	if cfg.Chain.ChainID == "" {
 		cfg.Chain.ChainID = DefaultChainID
@@ -510,18 +536,133 @@ func DefaultGfSpConsensusOption(app *GfSpBaseApp, cfg *gfspconfig.GfSpConfig) er
 		cfg.Chain.ChainAddress = []string{DefaultChainAddress}
 	}
 	gnfdCfg := &gnfd.GnfdChainConfig{
-		ChainID:      cfg.Chain.ChainID,
-		ChainAddress: cfg.Chain.ChainAddress,
-		RpcAddress:   cfg.Chain.RpcAddress,
+		ChainID:                       cfg.Chain.ChainID,
+		ChainAddress:                  cfg.Chain.ChainAddress,
+		RpcAddress:                    cfg.Chain.RpcAddress,
+		ProbeInterval:                 time.Duration(cfg.Chain.EndpointProbeIntervalMillisecond) * time.Millisecond,
+		MaxHeightLag:                  cfg.Chain.MaxEndpointHeightLag,
+		EnableObjectEventSubscription: cfg.Chain.EnableObjectEventSubscription,
 	}
 	chain, err := gnfd.NewGnfd(gnfdCfg)
 	if err != nil {
 		return err
 	}
-	app.chain = chain
+	return setGfSpConsensus(app, cfg, chain)
+}
+
+// setGfSpConsensus sets the consensus of the app, it is decorated by the consensus cache if the cache is enabled.
+func setGfSpConsensus(app *GfSpBaseApp, cfg *gfspconfig.GfSpConfig, chain consensus.Consensus) error {
+	if _, cached := chain.(*gnfd.CachedConsensus); cached || !cfg.Chain.EnableConsensusCache {
+		app.chain = chain
+		return nil
+	}
+	ttl := make(map[string]time.Duration, len(cfg.Chain.ConsensusCacheTTLSecond))
+	for method, second := range cfg.Chain.ConsensusCacheTTLSecond {
+		ttl[method] = time.Duration(second) * time.Second
+	}
+	cachedChain, err := gnfd.NewCachedConsensus(chain, &gnfd.ConsensusCacheConfig{
+		Size:                  cfg.Chain.ConsensusCacheSize,
+		TTL:                   ttl,
+		NegativeTTL:           time.Duration(cfg.Chain.ConsensusCacheNegativeTTLSecond) * time.Second,
+		HeightRefreshInterval: time.Duration(cfg.Chain.ConsensusCacheHeightRefreshMillisecond) * time.Millisecond,
+		QueryTimeout:          time.Duration(cfg.Chain.ConsensusCacheQueryTimeoutMillisecond) * time.Millisecond,
+	})
+	if err != nil {
+		log.Errorw("failed to init consensus cache", "error", err)
+		return err
+	}
+	app.chain = cachedChain
+	return nil
+}
+
+func DefaultGfSpWebhookOption(app *GfSpBaseApp, cfg *gfspconfig.GfSpConfig) error {
+	if !cfg.Webhook.Enable {
+		return nil
+	}
+	if val, ok := os.LookupEnv(webhook.SecretKeyEnv); ok {
+		cfg.Webhook.SecretKey = val
+	}
+	if cfg.Webhook.SecretKey == "" {
+		return fmt.Errorf("webhook is enabled without the secret key, set Webhook.SecretKey or %s", webhook.SecretKeyEnv)
+	}
+	secretCipher, err := webhook.NewSecretCipher(cfg.Webhook.SecretKey)
+	if err != nil {
+		log.Errorw("failed to init webhook secret cipher", "error", err)
+		return err
+	}
+	app.webhookCipher = secretCipher
+	if app.gfSpDB == nil {
+		log.Infow("no sp db, disable webhook dispatcher")
+		return nil
+	}
+	app.webhook = webhook.NewDispatcher(webhook.Config{
+		Workers:        cfg.Webhook.Workers,
+		QueueSize:      cfg.Webhook.QueueSize,
+		MaxRetry:       cfg.Webhook.MaxRetry,
+		Timeout:        time.Duration(cfg.Webhook.TimeoutSecond) * time.Second,
+		InitialBackoff: time.Duration(cfg.Webhook.InitialBackoffMillisecond) * time.Millisecond,
+		MaxBackoff:     time.Duration(cfg.Webhook.MaxBackoffSecond) * time.Second,
+
+		AllowPrivateNetwork: cfg.Webhook.AllowPrivateNetwork,
+	}, app.resolveWebhookSubscriptions)
+	return nil
+}
+
+// DefaultGfSpUploadProgressOption creates the hub which streams the upload state transitions
+// published by the manager to the gateway. The hub is shared by the processes through redis if
+// it is configured, otherwise it only receives the transitions of the manager in the same process.
+func DefaultGfSpUploadProgressOption(app *GfSpBaseApp, cfg *gfspconfig.GfSpConfig) error {
+	if cfg.UploadProgress.Redis.Address != "" {
+		hub, err := uploadprogress.NewRedisHub(cfg.UploadProgress.Redis)
+		if err != nil {
+			log.Errorw("failed to init upload progress redis hub", "error", err)
+			return err
+		}
+		app.progress = hub
+		return nil
+	}
+	var hasManager bool
+	for _, v := range cfg.Server {
+		if strings.EqualFold(v, coremodule.ManageModularName) {
+			hasManager = true
+		}
+	}
+	app.progress = uploadprogress.NewHub(hasManager)
 	return nil
 }
 
+// resolveWebhookSubscriptions returns the webhook subscription of the bucket owner, the
+// bucket owner is queried from chain if the event does not carry it.
+func (g *GfSpBaseApp) resolveWebhookSubscriptions(ctx context.Context, event *webhook.Event) ([]*webhook.Subscription, error) {
+	owner := event.BucketOwner
+	if owner == "" {
+		bucketInfo, err := g.Consensus().QueryBucketInfo(ctx, event.BucketName)
+		if err != nil {
+			return nil, err
+		}
+		owner = bucketInfo.GetOwner()
+		event.BucketOwner = owner
+	}
+	sub, err := g.GfSpDB().GetWebhookSubscription(owner)
+	if err != nil || sub == nil {
+		return nil, err
+	}
+	secret, err := g.webhookCipher.Open(sub.OwnerAddress, sub.Secret)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]webhook.EventType, 0, len(sub.Events))
+	for _, e := range sub.Events {
+		events = append(events, webhook.EventType(e))
+	}
+	return []*webhook.Subscription{{
+		OwnerAddress: sub.OwnerAddress,
+		URL:          sub.URL,
+		Secret:       secret,
+		Events:       events,
+	}}, nil
+}
+
 func DefaultGfSpModuleOption(app *GfSpBaseApp, cfg *gfspconfig.GfSpConfig) error {
 	for _, modular := range cfg.Server {
 		newFunc := GetNewModularFunc(strings.ToLower(modular))
@@ -574,17 +715,50 @@ func DefaultGfSpMetricOption(app *GfSpBaseApp, cfg *gfspconfig.GfSpConfig) error
 func DefaultGfSpPProfOption(app *GfSpBaseApp, cfg *gfspconfig.GfSpConfig) error {
 	if cfg.Monitor.DisablePProf {
 		log.Info("disable sp pprof")
+		if cfg.Monitor.EnableAutoProfile {
+			log.Warn("pprof is disabled, the auto profile is ignored")
+		}
 		app.pprof = &coremodule.NullModular{}
 	} else {
 		if cfg.Monitor.PProfHTTPAddress == "" {
 			cfg.Monitor.PProfHTTPAddress = DefaultPProfAddress
 		}
-		app.pprof = pprof.NewPProf(cfg.Monitor.PProfHTTPAddress)
+		pprofSvr := pprof.NewPProf(cfg.Monitor.PProfHTTPAddress)
+		if cfg.Monitor.EnableAutoProfile {
+			profiler, err := app.newAutoProfiler(&cfg.Monitor)
+			if err != nil {
+				log.Errorw("failed to new auto profiler", "error", err)
+				return err
+			}
+			pprofSvr.SetAutoProfiler(profiler)
+		}
+		app.pprof = pprofSvr
 		app.RegisterServices(app.pprof)
 	}
 	return nil
 }
 
+// DefaultGfSpTracingOption installs the otlp tracer provider, the spans of the gateway, the gRPC calls, the
+// piece store, the SPDB and the chain are dropped if the tracing is disabled.
+func DefaultGfSpTracingOption(app *GfSpBaseApp, cfg *gfspconfig.GfSpConfig) error {
+	if !cfg.Monitor.EnableTracing {
+		return nil
+	}
+	provider, err := tracing.NewProvider(tracing.Config{
+		Endpoint:    cfg.Monitor.TracingEndpoint,
+		Insecure:    cfg.Monitor.TracingInsecure,
+		Sampler:     cfg.Monitor.TracingSampler,
+		SampleRatio: cfg.Monitor.TracingSampleRatio,
+		Modules:     cfg.Server,
+	})
+	if err != nil {
+		log.Errorw("failed to new tracing provider", "error", err)
+		return err
+	}
+	app.tracing = provider
+	return nil
+}
+
 func DefaultGfSpProbeOption(app *GfSpBaseApp, cfg *gfspconfig.GfSpConfig) error {
 	if cfg.Monitor.DisableProbe {
 		log.Info("disable sp probe")
@@ -594,6 +768,12 @@ func DefaultGfSpProbeOption(app *GfSpBaseApp, cfg *gfspconfig.GfSpConfig) error
 			cfg.Monitor.ProbeHTTPAddress = DefaultProbeAddress
 		}
 		httpProbe := probe.NewHTTPProbe()
+		if !cfg.Monitor.DisableHealthCheck {
+			health := probe.NewHealthChecker(time.Duration(cfg.Monitor.HealthCheckIntervalSecond) * time.Second)
+			health.Register(app.healthChecks(time.Duration(cfg.Monitor.HealthCheckTimeoutSecond)*time.Second,
+				time.Duration(cfg.Monitor.ChainHeightStaleSecond)*time.Second)...)
+			httpProbe.SetHealthChecker(health)
+		}
 		statusProber := probe.Combine(httpProbe, probe.NewInstrumentation())
 		app.httpProbe = statusProber
 
@@ -612,11 +792,14 @@ var gfspBaseAppDefaultOptions = []Option{
 	DefaultGfSpPieceOpOption,
 	DefaultGfSpResourceManagerOption,
 	DefaultGfSpConsensusOption,
+	DefaultGfSpWebhookOption,
+	DefaultGfSpUploadProgressOption,
 	DefaultGfSpTQueueOption,
 	DefaultGfSpModuleOption,
 	DefaultGfSpMetricOption,
 	DefaultGfSpPProfOption,
 	DefaultGfSpProbeOption,
+	DefaultGfSpTracingOption,
 }
 
 func NewGfSpBaseApp(cfg *gfspconfig.GfSpConfig, opts ...gfspconfig.Option) (*GfSpBaseApp, error) {
@@ -1,20 +1,26 @@
 package gfspapp
 
 import (
+	"context"
 	"errors"
 	"os"
 	"testing"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 	"google.golang.org/grpc"
 
+	storagetypes "github.com/evmos/evmos/v12/x/storage/types"
 	"github.com/zkMeLabs/mechain-storage-provider/base/gfspconfig"
+	"github.com/zkMeLabs/mechain-storage-provider/base/gnfd"
 	"github.com/zkMeLabs/mechain-storage-provider/base/types/gfsplimit"
 	"github.com/zkMeLabs/mechain-storage-provider/core/consensus"
 	"github.com/zkMeLabs/mechain-storage-provider/core/module"
 	"github.com/zkMeLabs/mechain-storage-provider/core/piecestore"
 	"github.com/zkMeLabs/mechain-storage-provider/core/spdb"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/tracing"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/webhook"
 	"github.com/zkMeLabs/mechain-storage-provider/store/bsdb"
 	"github.com/zkMeLabs/mechain-storage-provider/store/config"
 	"github.com/zkMeLabs/mechain-storage-provider/store/sqldb"
@@ -259,6 +265,134 @@ func TestDefaultGfSpConsensusOptionSuccess2(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestDefaultGfSpConsensusOptionWithCache(t *testing.T) {
+	g := setup(t)
+	cfg := &gfspconfig.GfSpConfig{Customize: &gfspconfig.Customize{Consensus: &consensus.NullConsensus{}}}
+	cfg.Chain.EnableConsensusCache = true
+	err := DefaultGfSpConsensusOption(g, cfg)
+	assert.Nil(t, err)
+	cached, ok := g.Consensus().(*gnfd.CachedConsensus)
+	assert.True(t, ok)
+	defer func() { _ = cached.Close() }()
+
+	cfg.Customize.Consensus = cached
+	err = DefaultGfSpConsensusOption(g, cfg)
+	assert.Nil(t, err)
+	assert.Equal(t, cached, g.Consensus())
+
+	cfg.Customize.Consensus = &consensus.NullConsensus{}
+	cfg.Chain.ConsensusCacheTTLSecond = map[string]uint64{"WaitForNextBlock": 1}
+	err = DefaultGfSpConsensusOption(g, cfg)
+	assert.NotNil(t, err)
+}
+
+func TestDefaultGfSpWebhookOption(t *testing.T) {
+	t.Log("Success case description: webhook is disabled")
+	g := setup(t)
+	cfg := &gfspconfig.GfSpConfig{}
+	err := DefaultGfSpWebhookOption(g, cfg)
+	assert.Nil(t, err)
+	assert.Nil(t, g.Webhook())
+
+	t.Log("Failure case description: webhook is enabled without the secret key")
+	cfg.Webhook.Enable = true
+	err = DefaultGfSpWebhookOption(g, cfg)
+	assert.NotNil(t, err)
+	cfg.Webhook.SecretKey = "invalid"
+	err = DefaultGfSpWebhookOption(g, cfg)
+	assert.NotNil(t, err)
+
+	t.Log("Success case description: webhook is enabled")
+	ctrl := gomock.NewController(t)
+	g.gfSpDB = spdb.NewMockSPDB(ctrl)
+	cfg.Webhook.SecretKey = mockWebhookSecretKey
+	err = DefaultGfSpWebhookOption(g, cfg)
+	assert.Nil(t, err)
+	assert.NotNil(t, g.Webhook())
+	assert.NotNil(t, g.WebhookSecretCipher())
+	g.Webhook().Close()
+}
+
+func TestDefaultGfSpUploadProgressOption(t *testing.T) {
+	t.Log("Success case description: the hub receives the transitions of the manager in the same process")
+	g := setup(t)
+	err := DefaultGfSpUploadProgressOption(g, &gfspconfig.GfSpConfig{Server: []string{"gateway", "manager"}})
+	assert.Nil(t, err)
+	assert.True(t, g.UploadProgress().Complete())
+
+	t.Log("Success case description: the manager runs in another process")
+	err = DefaultGfSpUploadProgressOption(g, &gfspconfig.GfSpConfig{Server: []string{"gateway"}})
+	assert.Nil(t, err)
+	assert.False(t, g.UploadProgress().Complete())
+
+	t.Log("Success case description: the hub is shared by redis")
+	server := miniredis.RunT(t)
+	cfg := &gfspconfig.GfSpConfig{Server: []string{"gateway"}}
+	cfg.UploadProgress.Redis.Address = server.Addr()
+	err = DefaultGfSpUploadProgressOption(g, cfg)
+	assert.Nil(t, err)
+	assert.True(t, g.UploadProgress().Complete())
+	g.UploadProgress().Close()
+
+	t.Log("Failure case description: redis is unreachable")
+	server.Close()
+	err = DefaultGfSpUploadProgressOption(g, cfg)
+	assert.NotNil(t, err)
+}
+
+const mockWebhookSecretKey = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+
+func TestGfSpBaseApp_resolveWebhookSubscriptions(t *testing.T) {
+	t.Log("Success case description: query owner from chain and has subscription")
+	g := setup(t)
+	secretCipher, err := webhook.NewSecretCipher(mockWebhookSecretKey)
+	assert.Nil(t, err)
+	g.SetWebhookSecretCipher(secretCipher)
+	sealedSecret, err := secretCipher.Seal("mockOwner", "mockSecret")
+	assert.Nil(t, err)
+	ctrl := gomock.NewController(t)
+	m1 := consensus.NewMockConsensus(ctrl)
+	m1.EXPECT().QueryBucketInfo(gomock.Any(), "mockBucketName").Return(
+		&storagetypes.BucketInfo{Owner: "mockOwner"}, nil).Times(1)
+	g.chain = m1
+	m2 := spdb.NewMockSPDB(ctrl)
+	m2.EXPECT().GetWebhookSubscription("mockOwner").Return(&spdb.WebhookSubscription{
+		OwnerAddress: "mockOwner",
+		URL:          "https://example.com/hook",
+		Secret:       sealedSecret,
+		Events:       []string{string(webhook.EventObjectSealed)},
+	}, nil).Times(1)
+	m2.EXPECT().GetWebhookSubscription("mockOtherOwner").Return(nil, nil).Times(1)
+	g.gfSpDB = m2
+	event := &webhook.Event{Type: webhook.EventObjectSealed, BucketName: "mockBucketName"}
+	subs, err := g.resolveWebhookSubscriptions(context.Background(), event)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(subs))
+	assert.Equal(t, "mockSecret", subs[0].Secret)
+	assert.Equal(t, "mockOwner", event.BucketOwner)
+	assert.True(t, subs[0].Accepts(webhook.EventObjectSealed))
+	assert.False(t, subs[0].Accepts(webhook.EventObjectDeleted))
+
+	t.Log("Success case description: owner carried by event and no subscription")
+	subs, err = g.resolveWebhookSubscriptions(context.Background(), &webhook.Event{BucketOwner: "mockOtherOwner"})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(subs))
+
+	t.Log("Failure case description: failed to query bucket info")
+	m1.EXPECT().QueryBucketInfo(gomock.Any(), "mockBucketName").Return(nil, mockErr).Times(1)
+	_, err = g.resolveWebhookSubscriptions(context.Background(), &webhook.Event{BucketName: "mockBucketName"})
+	assert.Equal(t, mockErr, err)
+
+	t.Log("Failure case description: the secret is not sealed by the key")
+	m2.EXPECT().GetWebhookSubscription("mockOwner").Return(&spdb.WebhookSubscription{
+		OwnerAddress: "mockOwner",
+		URL:          "https://example.com/hook",
+		Secret:       "mockSecret",
+	}, nil).Times(1)
+	_, err = g.resolveWebhookSubscriptions(context.Background(), &webhook.Event{BucketOwner: "mockOwner"})
+	assert.Equal(t, webhook.ErrInvalidSealedSecret, err)
+}
+
 func TestDefaultGfSpModuleOptionSuccess(t *testing.T) {
 	g := setup(t)
 	mockRegisterModular(t)
@@ -306,6 +440,25 @@ func TestDefaultGfSpPProfOption(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestDefaultGfSpTracingOption(t *testing.T) {
+	g := setup(t)
+	cfg := &gfspconfig.GfSpConfig{}
+	err := DefaultGfSpTracingOption(g, cfg)
+	assert.Nil(t, err)
+	assert.Nil(t, g.tracing)
+
+	cfg.Monitor.EnableTracing = true
+	cfg.Monitor.TracingSampler = "mock"
+	err = DefaultGfSpTracingOption(g, cfg)
+	assert.NotNil(t, err)
+
+	cfg.Monitor.TracingSampler = tracing.AlwaysOffSampler
+	err = DefaultGfSpTracingOption(g, cfg)
+	assert.Nil(t, err)
+	assert.NotNil(t, g.tracing)
+	assert.Nil(t, g.tracing.Close())
+}
+
 func TestNewGfSpBaseAppFailure1(t *testing.T) {
 	t.Log("Failure case description: init would panic")
 	cfg := &gfspconfig.GfSpConfig{Customize: nil}
@@ -15,6 +15,9 @@ import (
 	"github.com/zkMeLabs/mechain-storage-provider/core/prober"
 	"github.com/zkMeLabs/mechain-storage-provider/core/rcmgr"
 	corespdb "github.com/zkMeLabs/mechain-storage-provider/core/spdb"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/uploadprogress"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/webhook"
+	"github.com/zkMeLabs/mechain-storage-provider/store/types"
 )
 
 func setup(t *testing.T) *GfSpBaseApp {
@@ -154,6 +157,43 @@ func TestGfSpBaseApp_SetResourceManager(t *testing.T) {
 	g.SetResourceManager(rcmgr.NewMockResourceManager(ctrl))
 }
 
+func TestGfSpBaseApp_NotifyObjectEvent(t *testing.T) {
+	g := setup(t)
+	t.Log("Success case description: webhook is disabled")
+	g.NotifyObjectEvent(webhook.EventObjectSealed, mockObjectInfo, "")
+	assert.Nil(t, g.Webhook())
+
+	t.Log("Success case description: webhook is enabled")
+	received := make(chan *webhook.Event, 1)
+	g.SetWebhook(webhook.NewDispatcher(webhook.Config{Workers: 1}, func(ctx context.Context, event *webhook.Event) ([]*webhook.Subscription, error) {
+		received <- event
+		return nil, nil
+	}))
+	defer g.Webhook().Close()
+	g.NotifyObjectEvent(webhook.EventObjectSealed, mockObjectInfo, "")
+	event := <-received
+	assert.Equal(t, webhook.EventObjectSealed, event.Type)
+	assert.Equal(t, mockObjectInfo.GetBucketName(), event.BucketName)
+	assert.Equal(t, mockObjectInfo.Id.Uint64(), event.ObjectID)
+}
+
+func TestGfSpBaseApp_PublishUploadProgress(t *testing.T) {
+	g := setup(t)
+	t.Log("Success case description: no upload progress hub")
+	g.PublishUploadProgress(mockObjectInfo, types.TaskState_SEAL_OBJECT_DONE, "")
+	assert.Nil(t, g.UploadProgress())
+
+	t.Log("Success case description: publish to the subscriber")
+	g.SetUploadProgress(uploadprogress.NewHub(true))
+	sub := g.UploadProgress().Subscribe(uploadprogress.ObjectFilter(mockObjectInfo.Id.Uint64()), 1)
+	defer sub.Cancel()
+	g.PublishUploadProgress(mockObjectInfo, types.TaskState_SEAL_OBJECT_DONE, "")
+	event := <-sub.C()
+	assert.Equal(t, mockObjectInfo.GetBucketName(), event.BucketName)
+	assert.Equal(t, int32(types.TaskState_SEAL_OBJECT_DONE), event.State)
+	assert.Equal(t, types.StateToDescription(types.TaskState_SEAL_OBJECT_DONE), event.StateDescription)
+}
+
 func TestGfSpBaseApp_StartAndCloseSuccess(t *testing.T) {
 	g := &GfSpBaseApp{grpcAddress: "localhost:0"}
 	ctrl := gomock.NewController(t)
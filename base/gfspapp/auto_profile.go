@@ -0,0 +1,66 @@
+package gfspapp
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/zkMeLabs/mechain-storage-provider/base/gfspconfig"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/metrics"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/pprof/autoprof"
+)
+
+const (
+	// DefaultAutoProfileDir defines the default directory of the profiles captured on the anomalies.
+	DefaultAutoProfileDir = "./profiles"
+	// AutoProfilePieceKeyPrefix defines the prefix of the piece keys of the profiles in the piece store.
+	AutoProfilePieceKeyPrefix = "autoprof"
+)
+
+// newAutoProfiler returns the profiler on the triggers of the config, the profiles of each app are stored
+// under its app id, so that the processes sharing the directory or the piece store do not overwrite each other.
+func (g *GfSpBaseApp) newAutoProfiler(cfg *gfspconfig.MonitorConfig) (*autoprof.Profiler, error) {
+	var blob autoprof.BlobStore
+	if cfg.AutoProfileToPieceStore && g.pieceStore != nil {
+		blob = autoprof.NewPieceBlobStore(g.pieceStore, AutoProfilePieceKeyPrefix+"/"+g.appID)
+	} else {
+		if cfg.AutoProfileToPieceStore {
+			log.Warn("no piece store in the process, store the profiles in the directory")
+		}
+		if cfg.AutoProfileDir == "" {
+			cfg.AutoProfileDir = DefaultAutoProfileDir
+		}
+		dir, err := autoprof.NewDirStore(filepath.Join(cfg.AutoProfileDir, g.appID))
+		if err != nil {
+			return nil, err
+		}
+		blob = dir
+	}
+
+	var triggers []autoprof.Trigger
+	if cfg.AutoProfileHeapThresholdMB > 0 {
+		triggers = append(triggers, autoprof.NewHeapTrigger(uint64(cfg.AutoProfileHeapThresholdMB)<<20))
+	}
+	if cfg.AutoProfileGoroutineThreshold > 0 || cfg.AutoProfileGoroutineSpikeRatio > 0 {
+		triggers = append(triggers, autoprof.NewGoroutineTrigger(int(cfg.AutoProfileGoroutineThreshold),
+			cfg.AutoProfileGoroutineSpikeRatio))
+	}
+	if cfg.AutoProfileLatencyMillisecond > 0 || cfg.AutoProfileLatencyRegressionRatio > 0 {
+		if m, ok := g.metrics.(*metrics.Metrics); ok {
+			triggers = append(triggers, autoprof.NewLatencyTrigger(m.Gatherer(), autoprof.DefaultLatencyMetrics,
+				time.Duration(cfg.AutoProfileLatencyMillisecond)*time.Millisecond, cfg.AutoProfileLatencyRegressionRatio))
+		} else {
+			log.Warn("metrics is disabled, the latency trigger of the auto profile is ignored")
+		}
+	}
+	if len(triggers) == 0 {
+		log.Warn("no trigger of the auto profile is configured")
+	}
+	return autoprof.NewProfiler(autoprof.Config{
+		CheckInterval: time.Duration(cfg.AutoProfileIntervalSecond) * time.Second,
+		Cooldown:      time.Duration(cfg.AutoProfileCooldownSecond) * time.Second,
+		CPUDuration:   time.Duration(cfg.AutoProfileCPUSecond) * time.Second,
+		MaxProfiles:   cfg.AutoProfileMaxProfiles,
+		MaxAge:        time.Duration(cfg.AutoProfileMaxAgeHour) * time.Hour,
+	}, autoprof.NewStore(blob), triggers...), nil
+}
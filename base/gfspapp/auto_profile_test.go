@@ -0,0 +1,44 @@
+package gfspapp
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/zkMeLabs/mechain-storage-provider/base/gfspconfig"
+	"github.com/zkMeLabs/mechain-storage-provider/core/piecestore"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/pprof/autoprof"
+)
+
+func TestGfSpBaseApp_newAutoProfiler(t *testing.T) {
+	t.Run("dir", func(t *testing.T) {
+		g := &GfSpBaseApp{appID: "mockAppID"}
+		cfg := &gfspconfig.MonitorConfig{
+			AutoProfileDir:             t.TempDir(),
+			AutoProfileToPieceStore:    true,
+			AutoProfileHeapThresholdMB: 1024,
+		}
+		profiler, err := g.newAutoProfiler(cfg)
+		assert.Nil(t, err)
+		assert.Nil(t, profiler.Store().Put(context.Background(), &autoprof.Meta{Name: "a"}, []byte("a")))
+		assert.FileExists(t, filepath.Join(cfg.AutoProfileDir, "mockAppID", "a"))
+	})
+
+	t.Run("piece store", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		ps := piecestore.NewMockPieceStore(ctrl)
+		g := &GfSpBaseApp{appID: "mockAppID", pieceStore: ps}
+		cfg := &gfspconfig.MonitorConfig{AutoProfileToPieceStore: true, AutoProfileLatencyMillisecond: 1000}
+		profiler, err := g.newAutoProfiler(cfg)
+		assert.Nil(t, err)
+
+		ps.EXPECT().GetPiece(gomock.Any(), "autoprof/mockAppID/index.json", int64(0), int64(-1)).
+			Return([]byte("[]"), nil).Times(1)
+		metas, err := profiler.Store().List(context.Background())
+		assert.Nil(t, err)
+		assert.Empty(t, metas)
+	})
+}
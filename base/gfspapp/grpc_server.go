@@ -29,6 +29,7 @@ func (g *GfSpBaseApp) newRPCServer(options ...grpc.ServerOption) {
 	if g.EnableMetrics() {
 		options = append(options, utilgrpc.GetDefaultServerInterceptor()...)
 	}
+	options = append(options, utilgrpc.GetTracingServerInterceptor()...)
 	g.server = grpc.NewServer(options...)
 	gfspserver.RegisterGfSpApprovalServiceServer(g.server, g)
 	gfspserver.RegisterGfSpAuthenticationServiceServer(g.server, g)
@@ -0,0 +1,94 @@
+package gfspapp
+
+import (
+	"errors"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/zkMeLabs/mechain-storage-provider/base/gfspconfig"
+	coremodule "github.com/zkMeLabs/mechain-storage-provider/core/module"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/mtls"
+)
+
+const (
+	// CLICallerName defines the module in the certificate of the command line, which sends the sp exit and
+	// the swap in transactions.
+	CLICallerName = "cli"
+	// SignRPCMethod defines the gRPC method of the signer, the requests of all the transactions are sent by it.
+	SignRPCMethod = "/base.types.gfspserver.GfSpSignService/GfSpSign"
+)
+
+// signTxCallers defines the callers of the signer requests which broadcast the transactions, keyed by the
+// name of the request in the oneof of GfSpSignRequest. The requests only signing the messages are open to
+// all the modules with the valid certificates.
+var signTxCallers = map[string][]string{
+	"seal_object_info":               {coremodule.ManageModularName, coremodule.ExecuteModularName},
+	"seal_object_info_v2":            {coremodule.ManageModularName, coremodule.ExecuteModularName},
+	"reject_object_info":             {coremodule.ManageModularName, coremodule.ExecuteModularName, coremodule.GateModularName, coremodule.UploadModularName},
+	"discontinue_bucket_info":        {coremodule.ManageModularName},
+	"create_global_virtual_group":    {coremodule.ManageModularName},
+	"complete_migrate_bucket":        {coremodule.ManageModularName},
+	"reject_migrate_bucket":          {coremodule.ManageModularName},
+	"swap_out":                       {coremodule.ManageModularName},
+	"complete_swap_out":              {coremodule.ManageModularName, CLICallerName},
+	"sp_exit":                        {CLICallerName},
+	"complete_sp_exit":               {coremodule.ManageModularName, CLICallerName},
+	"reserve_swap_in":                {CLICallerName},
+	"complete_swap_in":               {coremodule.ManageModularName, CLICallerName},
+	"cancel_swap_in":                 {CLICallerName},
+	"sp_storage_price":               {CLICallerName},
+	"deposit":                        {coremodule.ManageModularName},
+	"delete_global_virtual_group":    {coremodule.ManageModularName},
+	"delegate_create_object":         {coremodule.GateModularName},
+	"delegate_update_object_content": {coremodule.GateModularName},
+}
+
+// DefaultCallerPolicy returns the policy which limits the signer transactions to their callers.
+func DefaultCallerPolicy() *mtls.Policy {
+	policy := mtls.NewPolicy()
+	for request, callers := range signTxCallers {
+		policy.Allow(SignRPCMethod+"/"+request, callers...)
+	}
+	return policy
+}
+
+// NewTLSReloader returns the reloader of the certificates of the internal gRPC endpoints, nil is returned if
+// the TLS is disabled.
+func NewTLSReloader(cfg *gfspconfig.EndpointConfig) (*mtls.Reloader, error) {
+	if !cfg.EnableTLS {
+		return nil, nil
+	}
+	return mtls.NewReloader(mtls.Config{
+		CAFile:         cfg.TLSCAFile,
+		CertFile:       cfg.TLSCertFile,
+		KeyFile:        cfg.TLSKeyFile,
+		ServerName:     cfg.TLSServerName,
+		ReloadInterval: time.Duration(cfg.TLSReloadIntervalSecond) * time.Second,
+	})
+}
+
+// tlsServerOptions returns the credentials and the caller authorization of the gRPC server, the callers
+// are only identified by their certificates, so the policy is not applied without the TLS.
+func (g *GfSpBaseApp) tlsServerOptions(cfg *gfspconfig.EndpointConfig) ([]grpc.ServerOption, error) {
+	if g.tlsReloader == nil {
+		if len(cfg.CallerPolicy) != 0 {
+			return nil, errors.New("caller policy requires the tls of the endpoints")
+		}
+		return nil, nil
+	}
+	policy := mtls.NewPolicy()
+	if !cfg.DisableDefaultCallerPolicy {
+		policy = DefaultCallerPolicy()
+	}
+	if err := policy.Parse(cfg.CallerPolicy); err != nil {
+		return nil, err
+	}
+	log.Info("enable mutual tls and caller authorization of grpc server")
+	return []grpc.ServerOption{
+		grpc.Creds(g.tlsReloader.ServerCredentials()),
+		grpc.ChainUnaryInterceptor(mtls.UnaryServerInterceptor(policy)),
+		grpc.ChainStreamInterceptor(mtls.StreamServerInterceptor(policy)),
+	}, nil
+}
@@ -0,0 +1,64 @@
+package gfspapp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zkMeLabs/mechain-storage-provider/base/gfspconfig"
+	"github.com/zkMeLabs/mechain-storage-provider/base/types/gfspserver"
+	coremodule "github.com/zkMeLabs/mechain-storage-provider/core/module"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/mtls"
+)
+
+func TestDefaultCallerPolicy(t *testing.T) {
+	policy := DefaultCallerPolicy()
+	seal := &gfspserver.GfSpSignRequest{Request: &gfspserver.GfSpSignRequest_SealObjectInfo{}}
+	ping := &gfspserver.GfSpSignRequest{Request: &gfspserver.GfSpSignRequest_PingMsg{}}
+	cases := []struct {
+		name    string
+		req     interface{}
+		callers []string
+		allowed bool
+	}{
+		{"manager seals", seal, []string{coremodule.ManageModularName}, true},
+		{"executor seals", seal, []string{coremodule.GateModularName, coremodule.ExecuteModularName}, true},
+		{"gateway seals", seal, []string{coremodule.GateModularName}, false},
+		{"no caller seals", seal, nil, false},
+		{"p2p signs ping", ping, []string{coremodule.P2PModularName}, true},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Authorize(SignRPCMethod, tt.req, tt.callers)
+			if tt.allowed {
+				assert.Nil(t, err)
+			} else {
+				assert.Equal(t, codes.PermissionDenied, status.Code(err))
+			}
+		})
+	}
+}
+
+func TestGfSpBaseApp_tlsServerOptions(t *testing.T) {
+	reloader, err := NewTLSReloader(&gfspconfig.EndpointConfig{})
+	assert.Nil(t, err)
+	assert.Nil(t, reloader)
+	_, err = NewTLSReloader(&gfspconfig.EndpointConfig{EnableTLS: true})
+	assert.NotNil(t, err)
+
+	g := &GfSpBaseApp{}
+	options, err := g.tlsServerOptions(&gfspconfig.EndpointConfig{})
+	assert.Nil(t, err)
+	assert.Empty(t, options)
+	_, err = g.tlsServerOptions(&gfspconfig.EndpointConfig{CallerPolicy: []string{SignRPCMethod + "=manager"}})
+	assert.NotNil(t, err)
+
+	g.tlsReloader = &mtls.Reloader{}
+	options, err = g.tlsServerOptions(&gfspconfig.EndpointConfig{CallerPolicy: []string{SignRPCMethod + "=manager"}})
+	assert.Nil(t, err)
+	assert.Len(t, options, 3)
+	_, err = g.tlsServerOptions(&gfspconfig.EndpointConfig{CallerPolicy: []string{"invalid"}})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,124 @@
+package gfspapp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zkMeLabs/mechain-storage-provider/core/consensus"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/probe"
+)
+
+const (
+	// DefaultChainHeightStaleTime defines the default max duration the chain height stays unchanged before the
+	// chain is down.
+	DefaultChainHeightStaleTime = 60 * time.Second
+
+	// SPDBHealthCheckName defines the name of the sp db health check.
+	SPDBHealthCheckName = "spdb"
+	// BsDBHealthCheckName defines the name of the block syncer db health check.
+	BsDBHealthCheckName = "bsdb"
+	// PieceStoreHealthCheckName defines the name of the piece store health check.
+	PieceStoreHealthCheckName = "piece_store"
+	// ChainHealthCheckName defines the name of the chain health check.
+	ChainHealthCheckName = "chain"
+)
+
+// pinger is implemented by the db connections which are able to verify the connection is alive.
+type pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// bucketHeader is implemented by the piece store clients which are able to check the bucket is accessible.
+type bucketHeader interface {
+	HeadBucket(ctx context.Context) error
+}
+
+// healthChecks returns the checks of the dependencies held by the app and the checks provided by the modules.
+func (g *GfSpBaseApp) healthChecks(timeout, chainStaleTime time.Duration) []probe.Check {
+	var checks []probe.Check
+	if db, ok := g.gfSpDB.(pinger); ok {
+		checks = append(checks, pingCheck(SPDBHealthCheckName, timeout, db))
+	}
+	if db, ok := g.gfBsDBMaster.(pinger); ok {
+		checks = append(checks, pingCheck(BsDBHealthCheckName, timeout, db))
+	}
+	if store, ok := g.pieceStore.(bucketHeader); ok {
+		checks = append(checks, probe.Check{
+			Name:     PieceStoreHealthCheckName,
+			Timeout:  timeout,
+			Critical: true,
+			Func: func(ctx context.Context) (string, error) {
+				return "", store.HeadBucket(ctx)
+			},
+		})
+	}
+	if g.chain != nil {
+		checks = append(checks, probe.Check{
+			Name:     ChainHealthCheckName,
+			Timeout:  timeout,
+			Critical: true,
+			Func:     newChainHeightCheck(g.chain, chainStaleTime).check,
+		})
+	}
+	for _, service := range g.services {
+		if provider, ok := service.(probe.HealthCheckProvider); ok {
+			for _, check := range provider.HealthChecks() {
+				if check.Timeout == 0 {
+					check.Timeout = timeout
+				}
+				checks = append(checks, check)
+			}
+		}
+	}
+	return checks
+}
+
+func pingCheck(name string, timeout time.Duration, db pinger) probe.Check {
+	return probe.Check{
+		Name:     name,
+		Timeout:  timeout,
+		Critical: true,
+		Func: func(ctx context.Context) (string, error) {
+			return "", db.Ping(ctx)
+		},
+	}
+}
+
+// chainHeightCheck checks the chain rpc is reachable and the height is still growing, a node which is stuck
+// or catching up serves stale states to the sp.
+type chainHeightCheck struct {
+	chain     consensus.Consensus
+	staleTime time.Duration
+
+	mu            sync.Mutex
+	height        uint64
+	heightUpdated time.Time
+}
+
+func newChainHeightCheck(chain consensus.Consensus, staleTime time.Duration) *chainHeightCheck {
+	if staleTime <= 0 {
+		staleTime = DefaultChainHeightStaleTime
+	}
+	return &chainHeightCheck{chain: chain, staleTime: staleTime}
+}
+
+func (c *chainHeightCheck) check(ctx context.Context) (string, error) {
+	height, err := c.chain.CurrentHeight(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if height != c.height || c.heightUpdated.IsZero() {
+		c.height = height
+		c.heightUpdated = now
+	}
+	detail := fmt.Sprintf("height %d", height)
+	if stale := now.Sub(c.heightUpdated); stale > c.staleTime {
+		return detail, fmt.Errorf("chain height has not changed for %s", stale.Truncate(time.Second))
+	}
+	return detail, nil
+}
@@ -0,0 +1,84 @@
+package gfspapp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/zkMeLabs/mechain-storage-provider/core/consensus"
+	"github.com/zkMeLabs/mechain-storage-provider/core/module"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/probe"
+)
+
+type mockPinger struct {
+	err error
+}
+
+func (m *mockPinger) Ping(ctx context.Context) error {
+	return m.err
+}
+
+type mockHealthCheckModular struct {
+	*module.MockModular
+}
+
+func (m *mockHealthCheckModular) HealthChecks() []probe.Check {
+	return []probe.Check{{Name: "mock", Func: func(ctx context.Context) (string, error) { return "", nil }}}
+}
+
+func TestGfSpBaseApp_healthChecks(t *testing.T) {
+	g := setup(t)
+	ctrl := gomock.NewController(t)
+	g.chain = consensus.NewMockConsensus(ctrl)
+	g.RegisterServices(module.NewMockModular(ctrl), &mockHealthCheckModular{MockModular: module.NewMockModular(ctrl)})
+	checks := g.healthChecks(time.Second, 0)
+	assert.Equal(t, 2, len(checks))
+	assert.Equal(t, ChainHealthCheckName, checks[0].Name)
+	assert.True(t, checks[0].Critical)
+	assert.Equal(t, "mock", checks[1].Name)
+	assert.Equal(t, time.Second, checks[1].Timeout)
+	assert.False(t, checks[1].Critical)
+}
+
+func TestPingCheck(t *testing.T) {
+	check := pingCheck(SPDBHealthCheckName, time.Second, &mockPinger{})
+	_, err := check.Func(context.Background())
+	assert.Nil(t, err)
+	check = pingCheck(BsDBHealthCheckName, time.Second, &mockPinger{err: mockErr})
+	_, err = check.Func(context.Background())
+	assert.Equal(t, mockErr, err)
+}
+
+func TestChainHeightCheck(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	m := consensus.NewMockConsensus(ctrl)
+	height := uint64(10)
+	m.EXPECT().CurrentHeight(gomock.Any()).DoAndReturn(func(ctx context.Context) (uint64, error) {
+		return height, nil
+	}).AnyTimes()
+	c := newChainHeightCheck(m, 0)
+	assert.Equal(t, DefaultChainHeightStaleTime, c.staleTime)
+	c.staleTime = time.Minute
+
+	detail, err := c.check(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "height 10", detail)
+
+	// the height stays unchanged longer than the stale time
+	c.heightUpdated = time.Now().Add(-2 * time.Minute)
+	detail, err = c.check(context.Background())
+	assert.NotNil(t, err)
+	assert.Equal(t, "height 10", detail)
+
+	height = 11
+	_, err = c.check(context.Background())
+	assert.Nil(t, err)
+
+	m2 := consensus.NewMockConsensus(ctrl)
+	m2.EXPECT().CurrentHeight(gomock.Any()).Return(uint64(0), mockErr).Times(1)
+	_, err = newChainHeightCheck(m2, time.Minute).check(context.Background())
+	assert.Equal(t, mockErr, err)
+}
@@ -8,10 +8,12 @@ import (
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 
 	"github.com/zkMeLabs/mechain-storage-provider/base/types/gfsperrors"
 	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/mtls"
 	utilgrpc "github.com/zkMeLabs/mechain-storage-provider/util/grpc"
 )
 
@@ -61,6 +63,10 @@ type GfSpClient struct {
 	signerConn   *grpc.ClientConn
 	httpClient   *http.Client
 	metrics      bool
+	// creds secures the connections to the modules, the connections are insecure if it is nil
+	creds credentials.TransportCredentials
+	// callerModules are claimed in the metadata of the calls, they identify the caller without the mutual TLS
+	callerModules []string
 }
 
 func NewGfSpClient(approverEndpoint, managerEndpoint, downloaderEndpoint, receiverEndpoint, metadataEndpoint,
@@ -80,11 +86,32 @@ func NewGfSpClient(approverEndpoint, managerEndpoint, downloaderEndpoint, receiv
 	}
 }
 
+// SetTransportCredentials sets the credentials of the connections to the modules, e.g. the mutual TLS, it
+// should be called before any connection is created.
+func (s *GfSpClient) SetTransportCredentials(creds credentials.TransportCredentials) {
+	s.creds = creds
+}
+
+// SetCallerModules sets the modules of the process claimed in the gRPC metadata of the calls, so that the
+// callee can record the caller without the mutual TLS, it should be called before any connection is created.
+func (s *GfSpClient) SetCallerModules(modules []string) {
+	s.callerModules = modules
+}
+
 func (s *GfSpClient) Connection(ctx context.Context, address string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
 	options := append(DefaultClientOptions(), opts...)
 	if s.metrics {
 		options = append(options, utilgrpc.GetDefaultClientInterceptor()...)
 	}
+	options = append(options, utilgrpc.GetTracingClientInterceptor()...)
+	if len(s.callerModules) > 0 {
+		options = append(options, grpc.WithChainUnaryInterceptor(mtls.UnaryClientInterceptor(s.callerModules)),
+			grpc.WithChainStreamInterceptor(mtls.StreamClientInterceptor(s.callerModules)))
+	}
+	// the last transport credentials override the insecure ones of the default options
+	if s.creds != nil {
+		options = append(options, grpc.WithTransportCredentials(s.creds))
+	}
 	return grpc.DialContext(ctx, address, options...)
 }
 
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 const mockAddress = "localhost:0"
@@ -22,6 +23,22 @@ func TestGfSpClient_Connection(t *testing.T) {
 	defer conn.Close()
 }
 
+func TestGfSpClient_ConnectionWithTransportCredentials(t *testing.T) {
+	s := mockBufClient()
+	s.SetTransportCredentials(insecure.NewCredentials())
+	conn, err := s.Connection(context.TODO(), mockAddress)
+	assert.Nil(t, err)
+	defer conn.Close()
+}
+
+func TestGfSpClient_ConnectionWithCallerModules(t *testing.T) {
+	s := mockBufClient()
+	s.SetCallerModules([]string{"manager", "taskexecutor"})
+	conn, err := s.Connection(context.TODO(), mockAddress)
+	assert.Nil(t, err)
+	defer conn.Close()
+}
+
 func TestGfSpClient_ManagerConnSuccess(t *testing.T) {
 	s := mockBufClient()
 	conn, err := s.ManagerConn(context.TODO())
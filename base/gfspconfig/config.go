@@ -10,7 +10,10 @@ import (
 	"github.com/zkMeLabs/mechain-storage-provider/core/spdb"
 	coretaskqueue "github.com/zkMeLabs/mechain-storage-provider/core/taskqueue"
 	"github.com/zkMeLabs/mechain-storage-provider/core/vgmgr"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/accesslog"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/auditlog"
 	mwhttp "github.com/zkMeLabs/mechain-storage-provider/pkg/middleware/http"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/uploadprogress"
 	storeconfig "github.com/zkMeLabs/mechain-storage-provider/store/config"
 	"github.com/zkMeLabs/mechain-storage-provider/store/piecestore/storage"
 )
@@ -61,6 +64,10 @@ type GfSpConfig struct {
 	Manager        ManagerConfig
 	GC             GCConfig
 	Quota          QuotaConfig
+	Webhook        WebhookConfig        `comment:"optional"`
+	UploadProgress UploadProgressConfig `comment:"optional"`
+	AccessLog      accesslog.Config     `comment:"optional"`
+	AuditLog       auditlog.Config      `comment:"optional"`
 }
 
 // Apply sets the customized implement to the GfSp configuration, it will be called
@@ -126,6 +133,65 @@ type ChainConfig struct {
 	CompleteSwapInFeeAmount              uint64   `comment:"optional"`
 	CancelSwapInGasLimit                 uint64   `comment:"optional"`
 	CancelSwapInFeeAmount                uint64   `comment:"optional"`
+	// EnableSealBatch defines whether to broadcast the cosmos seal, seal v2 and reject unseal messages in batch txs.
+	EnableSealBatch bool `comment:"optional"`
+	// SealBatchWindowMillisecond defines the window for accumulating the messages of a batch tx.
+	SealBatchWindowMillisecond uint64 `comment:"optional"`
+	// SealBatchMaxMsgs defines the max number of the messages in a batch tx.
+	SealBatchMaxMsgs uint64 `comment:"optional"`
+	// SealBatchMaxGasLimit defines the max gas limit of a batch tx, the gas limit of a batch tx is the sum of its messages,
+	// the default is SealGasLimit times SealBatchMaxMsgs.
+	SealBatchMaxGasLimit uint64 `comment:"optional"`
+	// AuthzExecGasLimit defines the extra gas limit of the seal account pool wrapping the messages in the MsgExec.
+	AuthzExecGasLimit uint64 `comment:"optional"`
+	// AuthzExecFeeAmount defines the extra fee of the seal account pool wrapping the messages in the MsgExec.
+	AuthzExecFeeAmount uint64 `comment:"optional"`
+	// SealAccountCheckIntervalSecond defines the interval of checking the pending txs of the seal account pool.
+	SealAccountCheckIntervalSecond uint64 `comment:"optional"`
+	// SealAccountStuckTimeoutSecond defines the time after which the pending tx of the seal account pool is
+	// resubmitted if its sequence is not committed.
+	SealAccountStuckTimeoutSecond uint64 `comment:"optional"`
+	// EnableGasEstimation defines whether to simulate the txs for the gas limits and to pay by the min gas price of
+	// the chain, the gas limits and the fee amounts above are only used if the simulation fails.
+	EnableGasEstimation bool `comment:"optional"`
+	// GasMultiplier defines the safety multiplier of the simulated gas, default is 1.2.
+	GasMultiplier float64 `comment:"optional"`
+	// GasEstimateCacheSecond defines how long the estimation of the same messages is reused, default is 30.
+	GasEstimateCacheSecond uint64 `comment:"optional"`
+	// OperatorDailyFeeBudget, SealDailyFeeBudget and GcDailyFeeBudget define the max fee amount paid by the
+	// accounts in a UTC day, SealAccountDailyFeeBudget is the budget of each account of the seal account pool.
+	// The txs exceeding the budgets are rejected, zero is unlimited.
+	OperatorDailyFeeBudget    uint64 `comment:"optional"`
+	SealDailyFeeBudget        uint64 `comment:"optional"`
+	GcDailyFeeBudget          uint64 `comment:"optional"`
+	SealAccountDailyFeeBudget uint64 `comment:"optional"`
+	// FeeBudgetAlertRatio defines the used ratio of the budget from which an alert is logged, default is 0.8.
+	FeeBudgetAlertRatio float64 `comment:"optional"`
+	// EnableConsensusCache defines whether to cache the chain query results of the consensus, the results which change
+	// with the txs are invalidated once a new block is observed.
+	EnableConsensusCache bool `comment:"optional"`
+	// ConsensusCacheSize defines the max number of the cached query results, default is 100000.
+	ConsensusCacheSize int `comment:"optional"`
+	// ConsensusCacheTTLSecond overrides the cache time of the consensus methods by their names, e.g.
+	// {QueryBucketInfo = 5}, zero disables the cache of the method.
+	ConsensusCacheTTLSecond map[string]uint64 `comment:"optional"`
+	// ConsensusCacheNegativeTTLSecond defines the cache time of the not found results, default is 2.
+	ConsensusCacheNegativeTTLSecond uint64 `comment:"optional"`
+	// ConsensusCacheHeightRefreshMillisecond defines the interval of refreshing the block height which invalidates
+	// the cached results, default is 1000.
+	ConsensusCacheHeightRefreshMillisecond uint64 `comment:"optional"`
+	// ConsensusCacheQueryTimeoutMillisecond defines the timeout of the chain query shared by the concurrent callers,
+	// default is 10000.
+	ConsensusCacheQueryTimeoutMillisecond uint64 `comment:"optional"`
+	// EndpointProbeIntervalMillisecond defines the interval of probing the latency and the height of the chain
+	// endpoints, the reads are routed to the endpoint of the lowest latency, default is 5000.
+	EndpointProbeIntervalMillisecond uint64 `comment:"optional"`
+	// MaxEndpointHeightLag defines the max number of the blocks a chain endpoint can be behind the highest endpoint
+	// to serve the reads and the broadcasts, default is 3.
+	MaxEndpointHeightLag uint64 `comment:"optional"`
+	// EnableObjectEventSubscription defines whether to listen the seal and reject of the objects by a shared
+	// subscription of the chain events instead of polling every object, the objects are polled while it is broken.
+	EnableObjectEventSubscription bool `comment:"optional"`
 }
 
 type SpAccountConfig struct {
@@ -136,6 +202,47 @@ type SpAccountConfig struct {
 	ApprovalPrivateKey string `comment:"required"`
 	GcPrivateKey       string `comment:"required"`
 	BlsPrivateKey      string `comment:"required"`
+	// SealAccountPrivateKeys defines the private keys of the seal account pool, the seal, reject unseal and gvg
+	// creation txs are broadcast by the pool in round-robin if it is not empty. The seal and the operator
+	// accounts must grant the messages to the accounts of the pool by authz.
+	SealAccountPrivateKeys []string `comment:"optional"`
+	// KeyBackend defines where the SP keys are held, "local" loads the private keys above, "pkcs11" signs by the
+	// keys in the PKCS#11 HSM and "remote" signs by the keys of the remote signing service, default is "local".
+	// The signer only sees the public keys and the signatures of the pkcs11 and remote backends.
+	KeyBackend string `comment:"optional"`
+	// KeyIDPrefix is prefixed to the key ids of the pkcs11 and remote backends, the ids are operator, seal,
+	// approval, gc and bls, e.g. the HSM key labelled "sp0-seal" is the seal key if the prefix is "sp0-".
+	KeyIDPrefix string `comment:"optional"`
+	// SealAccountKeyIDs defines the key ids of the seal account pool in the pkcs11 and remote backends.
+	SealAccountKeyIDs []string `comment:"optional"`
+	// PKCS11Module is the path of the PKCS#11 library, the keys are found by their labels in the token. The HSMs
+	// do not hold the bls key, it is signed by the remote signing service if RemoteSignerAddress is set, otherwise
+	// it is loaded from BlsPrivateKey.
+	PKCS11Module     string `comment:"optional"`
+	PKCS11TokenLabel string `comment:"optional"`
+	PKCS11Pin        string `comment:"optional"`
+	// RemoteSignerAddress is the gRPC address of the remote signing service, it is secured by the mutual TLS of
+	// the certificate files, which are required unless the address is a loopback address.
+	RemoteSignerAddress       string `comment:"optional"`
+	RemoteSignerTLSCAFile     string `comment:"optional"`
+	RemoteSignerTLSCertFile   string `comment:"optional"`
+	RemoteSignerTLSKeyFile    string `comment:"optional"`
+	RemoteSignerTLSServerName string `comment:"optional"`
+	RemoteSignerTimeoutSecond int64  `comment:"optional"`
+	// NextSealPrivateKey, NextApprovalPrivateKey and NextBlsPrivateKey are the keys which the seal, approval and
+	// bls keys are rotated to. They are submitted to the chain by the rotate.sp.key command, and the signer switches
+	// to them once the chain reflects the change.
+	NextSealPrivateKey     string `comment:"optional"`
+	NextApprovalPrivateKey string `comment:"optional"`
+	NextBlsPrivateKey      string `comment:"optional"`
+	// NextKeys defines the keys, i.e. seal, approval and bls, which are rotated in the pkcs11 and remote backends,
+	// the next key of the "seal" key is found by the id "next-seal" with the KeyIDPrefix.
+	NextKeys []string `comment:"optional"`
+	// KeyRotationCheckIntervalSecond defines the interval of checking whether the chain reflects the next keys.
+	KeyRotationCheckIntervalSecond uint64 `comment:"optional"`
+	// KeyRotationGracePeriodSecond defines how long the previous key is still accepted by the verification after
+	// the signer switches to the next key.
+	KeyRotationGracePeriodSecond uint64 `comment:"optional"`
 }
 
 type EndpointConfig struct {
@@ -148,6 +255,21 @@ type EndpointConfig struct {
 	P2PEndpoint           string `comment:"required"`
 	SignerEndpoint        string `comment:"required"`
 	AuthenticatorEndpoint string `comment:"required"`
+	// EnableTLS secures the internal gRPC endpoints with the mutual TLS, the certificate of each process is
+	// used both as the server and as the client, the modified files are reloaded without restart.
+	EnableTLS   bool   `comment:"optional"`
+	TLSCAFile   string `comment:"optional"`
+	TLSCertFile string `comment:"optional"`
+	TLSKeyFile  string `comment:"optional"`
+	// TLSServerName overrides the name to verify the server certificates, default is the host of the endpoint.
+	TLSServerName string `comment:"optional"`
+	// TLSReloadIntervalSecond is the min interval of checking the modification of the certificate files.
+	TLSReloadIntervalSecond int64 `comment:"optional"`
+	// CallerPolicy authorizes the callers by the modules in the OrganizationalUnit of their certificates, each
+	// item is "<rule>=<module>,<module>", the rule is /<service>, /<service>/<method> or /<service>/<method>/<request>,
+	// and overrides the same rule of the default policy, which limits the signer transactions to their callers.
+	CallerPolicy               []string `comment:"optional"`
+	DisableDefaultCallerPolicy bool     `comment:"optional"`
 }
 
 type ApprovalConfig struct {
@@ -165,6 +287,32 @@ type BucketConfig struct {
 type GatewayConfig struct {
 	DomainName  string `comment:"required"`
 	HTTPAddress string `comment:"required"`
+	// EnableUploadFromURL is used to enable the api of uploading the object payload fetched from a source url.
+	EnableUploadFromURL bool `comment:"optional"`
+	// MaxUploadFromURLNumber is the max number of the concurrent upload-from-url fetches.
+	MaxUploadFromURLNumber int `comment:"optional"`
+	// UploadFromURLTimeoutSecond is the timeout of fetching and uploading a source url.
+	UploadFromURLTimeoutSecond int64 `comment:"optional"`
+	// UploadFromURLAllowPrivateNetwork allows fetching from the private network, it is only used in the test environment.
+	UploadFromURLAllowPrivateNetwork bool `comment:"optional"`
+	// EnableBucketCORS is used to enable the per-bucket CORS rules, the rules are stored in the sp db.
+	EnableBucketCORS bool `comment:"optional"`
+	// BucketCORSCacheSecond is the expiration time of the cached bucket CORS rules.
+	BucketCORSCacheSecond int64 `comment:"optional"`
+	// EnableThumbnail is used to enable rendering the image preview on the view endpoint by the w, h, fit and format queries.
+	EnableThumbnail bool `comment:"optional"`
+	// ThumbnailMaxSourceSize is the max payload size of the image which the preview is rendered from.
+	ThumbnailMaxSourceSize uint64 `comment:"optional"`
+	// ThumbnailMaxDimension is the max width and height of the preview.
+	ThumbnailMaxDimension int `comment:"optional"`
+	// ThumbnailCacheSize is the max bytes of the cached previews.
+	ThumbnailCacheSize int64 `comment:"optional"`
+	// MaxThumbnailNumber is the max number of the concurrent preview renderings.
+	MaxThumbnailNumber int `comment:"optional"`
+	// EnableUsageMetrics is used to export the requests, the errors and the traffic of the heaviest buckets and accounts.
+	EnableUsageMetrics bool `comment:"optional"`
+	// UsageMetricsTopN is the number of the heaviest buckets and accounts exported, the others are summed up.
+	UsageMetricsTopN int `comment:"optional"`
 }
 
 type ExecutorConfig struct {
@@ -281,6 +429,49 @@ type MonitorConfig struct {
 	MetricsHTTPAddress string `comment:"required"`
 	PProfHTTPAddress   string `comment:"required"`
 	ProbeHTTPAddress   string `comment:"required"`
+	// EnableTracing exports the spans to the otlp grpc collector at TracingEndpoint.
+	EnableTracing   bool   `comment:"optional"`
+	TracingEndpoint string `comment:"optional"`
+	TracingInsecure bool   `comment:"optional"`
+	// TracingSampler is one of always_on, always_off and ratio, the ratio sampler samples the root spans by
+	// TracingSampleRatio and the others follow their parents.
+	TracingSampler     string  `comment:"optional"`
+	TracingSampleRatio float64 `comment:"optional"`
+	// DisableHealthCheck disables the dependency checks, the readiness only reflects the start of the services.
+	DisableHealthCheck bool `comment:"optional"`
+	// HealthCheckIntervalSecond is the interval of running the dependency checks, the results are served by /health.
+	HealthCheckIntervalSecond int64 `comment:"optional"`
+	// HealthCheckTimeoutSecond is the timeout of each dependency check.
+	HealthCheckTimeoutSecond int64 `comment:"optional"`
+	// ChainHeightStaleSecond is the max duration the chain height stays unchanged before the chain is down.
+	ChainHeightStaleSecond int64 `comment:"optional"`
+	// EnableAutoProfile captures the heap, the goroutine and the cpu profiles when any of the triggers fires,
+	// the profiles are listed and fetched by the pprof HTTP server, so the pprof must be enabled.
+	EnableAutoProfile bool `comment:"optional"`
+	// AutoProfileDir is the directory of the profiles, the profiles are stored in the piece store instead if
+	// AutoProfileToPieceStore is true and the process has the piece store.
+	AutoProfileDir          string `comment:"optional"`
+	AutoProfileToPieceStore bool   `comment:"optional"`
+	// AutoProfileIntervalSecond is the interval of checking the triggers.
+	AutoProfileIntervalSecond int64 `comment:"optional"`
+	// AutoProfileCooldownSecond is the min interval between two captures.
+	AutoProfileCooldownSecond int64 `comment:"optional"`
+	// AutoProfileCPUSecond is the duration of the cpu profile, negative disables the cpu profile.
+	AutoProfileCPUSecond int64 `comment:"optional"`
+	// AutoProfileHeapThresholdMB fires the capture if the heap is above it, zero disables the trigger.
+	AutoProfileHeapThresholdMB int64 `comment:"optional"`
+	// AutoProfileGoroutineThreshold fires the capture if the goroutine count reaches it, and
+	// AutoProfileGoroutineSpikeRatio fires if the count spikes to the ratio of the baseline, zero disables them.
+	AutoProfileGoroutineThreshold  int64   `comment:"optional"`
+	AutoProfileGoroutineSpikeRatio float64 `comment:"optional"`
+	// AutoProfileLatencyMillisecond fires the capture if the p99 of the HTTP and gRPC request latency is above
+	// it, and AutoProfileLatencyRegressionRatio fires if the p99 regresses to the ratio of the baseline, zero
+	// disables them, the latency triggers require the metrics.
+	AutoProfileLatencyMillisecond     int64   `comment:"optional"`
+	AutoProfileLatencyRegressionRatio float64 `comment:"optional"`
+	// AutoProfileMaxProfiles and AutoProfileMaxAgeHour limit the number and the age of the kept profiles.
+	AutoProfileMaxProfiles int   `comment:"optional"`
+	AutoProfileMaxAgeHour  int64 `comment:"optional"`
 }
 
 type RcmgrConfig struct {
@@ -291,6 +482,20 @@ type RcmgrConfig struct {
 type LogConfig struct {
 	Level string `comment:"optional"`
 	Path  string `comment:"optional"`
+	// RotateInterval is hour or day, the log file is rotated hourly by default.
+	RotateInterval string `comment:"optional"`
+	// MaxSizeMB is the max size of a log file before it is rotated, zero means no limit.
+	MaxSizeMB int64 `comment:"optional"`
+	// MaxBackups is the max number of the rotated log files kept, zero means no limit.
+	MaxBackups int `comment:"optional"`
+	// MaxAgeDay is the max days the rotated log files are kept, zero means no limit.
+	MaxAgeDay int `comment:"optional"`
+	// ModuleLevels overrides the level of the modules by module=level, e.g. executor=warn.
+	ModuleLevels []string `comment:"optional"`
+	// SamplingFirst logs the first lines with the same level and message every second, and then every
+	// SamplingThereafter lines, the sampling is disabled if it is zero.
+	SamplingFirst      int `comment:"optional"`
+	SamplingThereafter int `comment:"optional"`
 }
 
 type BlockSyncerConfig struct {
@@ -339,3 +544,27 @@ type ManagerConfig struct {
 type QuotaConfig struct {
 	MonthlyFreeQuota uint64 `comment:"optional"`
 }
+
+type WebhookConfig struct {
+	// Enable is used to enable pushing object and bucket events to the webhook endpoints
+	// subscribed by the bucket owners.
+	Enable                    bool  `comment:"optional"`
+	Workers                   int   `comment:"optional"`
+	QueueSize                 int   `comment:"optional"`
+	MaxRetry                  int   `comment:"optional"`
+	TimeoutSecond             int64 `comment:"optional"`
+	InitialBackoffMillisecond int64 `comment:"optional"`
+	MaxBackoffSecond          int64 `comment:"optional"`
+	// SecretKey is the hex encoded 32 bytes key which encrypts the subscription secrets stored in SPDB, it is
+	// required if webhook is enabled and can be overridden by the WEBHOOK_SECRET_KEY environment variable.
+	SecretKey string `comment:"optional"`
+	// AllowPrivateNetwork allows the webhook endpoints in the private network, it is only used in the test environment.
+	AllowPrivateNetwork bool `comment:"optional"`
+}
+
+type UploadProgressConfig struct {
+	// Redis shares the upload state transitions among the processes by the redis protocol server, so the
+	// gateway streams the transitions published by the managers in other processes. The transitions stay
+	// in each process if the address is empty.
+	Redis uploadprogress.RedisConfig `comment:"optional"`
+}
@@ -0,0 +1,416 @@
+package gnfd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	paymenttypes "github.com/evmos/evmos/v12/x/payment/types"
+	sptypes "github.com/evmos/evmos/v12/x/sp/types"
+	storagetypes "github.com/evmos/evmos/v12/x/storage/types"
+	virtualgrouptypes "github.com/evmos/evmos/v12/x/virtualgroup/types"
+	lru "github.com/hashicorp/golang-lru"
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zkMeLabs/mechain-storage-provider/core/consensus"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/metrics"
+)
+
+const (
+	// DefaultConsensusCacheSize defines the default max number of the entries in the consensus cache.
+	DefaultConsensusCacheSize = 100000
+	// DefaultConsensusCacheNegativeTTL defines the default time the not found results are cached.
+	DefaultConsensusCacheNegativeTTL = 2 * time.Second
+	// DefaultConsensusCacheHeightRefreshInterval defines the default interval of refreshing the block height, the
+	// height bound entries are invalidated once the height changes.
+	DefaultConsensusCacheHeightRefreshInterval = time.Second
+	// DefaultConsensusCacheQueryTimeout defines the default timeout of the query shared by the concurrent callers.
+	DefaultConsensusCacheQueryTimeout = 10 * time.Second
+
+	consensusCacheHit         = "hit"
+	consensusCacheNegativeHit = "negative_hit"
+	consensusCacheMiss        = "miss"
+	consensusCacheShared      = "shared"
+)
+
+// consensusCachePolicy defines how long the results of a method are cached, the results of a height bound method
+// are only valid in the block height they are queried at, because they change with the txs of the blocks.
+type consensusCachePolicy struct {
+	ttl         time.Duration
+	heightBound bool
+}
+
+// defaultConsensusCachePolicies defines the cached methods, the methods not listed here are not cached.
+var defaultConsensusCachePolicies = map[string]consensusCachePolicy{
+	"HasAccount":                    {ttl: 30 * time.Second, heightBound: true},
+	"ListSPs":                       {ttl: 60 * time.Second, heightBound: true},
+	"QuerySP":                       {ttl: 60 * time.Second, heightBound: true},
+	"QuerySPByID":                   {ttl: 60 * time.Second, heightBound: true},
+	"QuerySPFreeQuota":              {ttl: 10 * time.Second},
+	"QuerySPPrice":                  {ttl: 60 * time.Second},
+	"QueryVirtualGroupFamily":       {ttl: 10 * time.Second, heightBound: true},
+	"QueryGlobalVirtualGroup":       {ttl: 10 * time.Second, heightBound: true},
+	"QueryVirtualGroupParams":       {ttl: 300 * time.Second},
+	"QueryStorageParams":            {ttl: 300 * time.Second},
+	"QueryStorageParamsByTimestamp": {ttl: 3600 * time.Second},
+	"QueryBucketInfo":               {ttl: 10 * time.Second, heightBound: true},
+	"QueryBucketExtraInfo":          {ttl: 10 * time.Second, heightBound: true},
+	"QueryBucketInfoById":           {ttl: 10 * time.Second, heightBound: true},
+	"QueryObjectInfo":               {ttl: 10 * time.Second, heightBound: true},
+	"QueryObjectInfoByID":           {ttl: 10 * time.Second, heightBound: true},
+	"QueryBucketInfoAndObjectInfo":  {ttl: 10 * time.Second, heightBound: true},
+	"QueryPaymentStreamRecord":      {ttl: 10 * time.Second, heightBound: true},
+	"VerifyGetObjectPermission":     {ttl: 10 * time.Second, heightBound: true},
+	"VerifyPutObjectPermission":     {ttl: 10 * time.Second, heightBound: true},
+	"VerifyUpdateObjectPermission":  {ttl: 10 * time.Second, heightBound: true},
+}
+
+// ConsensusCacheConfig defines the config of the consensus cache.
+type ConsensusCacheConfig struct {
+	// Size is the max number of the cached entries.
+	Size int
+	// TTL overrides the cache time of the methods by their names, zero disables the cache of the method.
+	TTL map[string]time.Duration
+	// NegativeTTL is the time the not found results are cached.
+	NegativeTTL time.Duration
+	// HeightRefreshInterval is the interval of refreshing the block height.
+	HeightRefreshInterval time.Duration
+	// QueryTimeout is the timeout of the query shared by the concurrent callers.
+	QueryTimeout time.Duration
+}
+
+var _ consensus.Consensus = &CachedConsensus{}
+
+// CachedConsensus decorates a Consensus with a cache of the query results. The concurrent queries of the same
+// method and args are collapsed into one. The height bound results are invalidated once a new block is observed,
+// the not found results are cached for a short time. The cached results are shared by the callers and must not be
+// modified.
+type CachedConsensus struct {
+	consensus.Consensus
+	policies     map[string]consensusCachePolicy
+	negativeTTL  time.Duration
+	queryTimeout time.Duration
+	cache        *lru.Cache
+	group        singleflight.Group
+	height       atomic.Uint64
+	stopCh       chan struct{}
+	stopOnce     sync.Once
+}
+
+type consensusCacheEntry struct {
+	value    interface{}
+	err      error
+	height   uint64
+	expireAt time.Time
+}
+
+type bucketAndObjectInfo struct {
+	bucketInfo *storagetypes.BucketInfo
+	objectInfo *storagetypes.ObjectInfo
+}
+
+// NewCachedConsensus returns the consensus which caches the query results of the inner consensus.
+func NewCachedConsensus(inner consensus.Consensus, cfg *ConsensusCacheConfig) (*CachedConsensus, error) {
+	if cfg == nil {
+		cfg = &ConsensusCacheConfig{}
+	}
+	size := cfg.Size
+	if size <= 0 {
+		size = DefaultConsensusCacheSize
+	}
+	cache, err := lru.New(size)
+	if err != nil {
+		return nil, err
+	}
+	policies := make(map[string]consensusCachePolicy, len(defaultConsensusCachePolicies))
+	for method, policy := range defaultConsensusCachePolicies {
+		policies[method] = policy
+	}
+	for method, ttl := range cfg.TTL {
+		policy, ok := policies[method]
+		if !ok {
+			return nil, fmt.Errorf("consensus method %s can not be cached", method)
+		}
+		policy.ttl = ttl
+		policies[method] = policy
+	}
+	negativeTTL := cfg.NegativeTTL
+	if negativeTTL <= 0 {
+		negativeTTL = DefaultConsensusCacheNegativeTTL
+	}
+	refreshInterval := cfg.HeightRefreshInterval
+	if refreshInterval <= 0 {
+		refreshInterval = DefaultConsensusCacheHeightRefreshInterval
+	}
+	queryTimeout := cfg.QueryTimeout
+	if queryTimeout <= 0 {
+		queryTimeout = DefaultConsensusCacheQueryTimeout
+	}
+	c := &CachedConsensus{
+		Consensus:    inner,
+		policies:     policies,
+		negativeTTL:  negativeTTL,
+		queryTimeout: queryTimeout,
+		cache:        cache,
+		stopCh:       make(chan struct{}),
+	}
+	c.refreshHeight()
+	go c.loopRefreshHeight(refreshInterval)
+	return c, nil
+}
+
+// Close stops refreshing the block height and closes the inner consensus.
+func (c *CachedConsensus) Close() error {
+	c.stopOnce.Do(func() { close(c.stopCh) })
+	return c.Consensus.Close()
+}
+
+// BroadcastEndpoint returns the broadcast endpoint of the inner consensus, it is empty if the inner consensus does
+// not route the txs.
+func (c *CachedConsensus) BroadcastEndpoint() string {
+	if router, ok := c.Consensus.(BroadcastRouter); ok {
+		return router.BroadcastEndpoint()
+	}
+	return ""
+}
+
+func (c *CachedConsensus) loopRefreshHeight(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stopCh:
+			return
+		case <-ticker.C:
+			c.refreshHeight()
+		}
+	}
+}
+
+func (c *CachedConsensus) refreshHeight() {
+	height, err := c.Consensus.CurrentHeight(context.Background())
+	if err != nil {
+		log.Warnw("failed to refresh the height of consensus cache", "error", err)
+		return
+	}
+	c.height.Store(height)
+}
+
+// query returns the cached result of the method and args, or queries it by fetch. The cached result is bypassed
+// and replaced if the context is marked by consensus.WithRefresh. The collapsed query is not canceled with the
+// context of the caller starting it, it has its own timeout, and every caller stops waiting once its context is done.
+func query[T any](c *CachedConsensus, ctx context.Context, method string, fetch func(ctx context.Context) (T, error),
+	args ...interface{},
+) (T, error) {
+	policy := c.policies[method]
+	if policy.ttl <= 0 {
+		return fetch(ctx)
+	}
+	key := method + "/" + fmt.Sprint(args...)
+	flightKey := key
+	if consensus.IsRefresh(ctx) {
+		// the refresh is not collapsed into the query in flight, which may return the outdated result
+		flightKey = "refresh/" + key
+	} else if entry, ok := c.get(key, policy); ok {
+		if entry.err != nil {
+			metrics.ConsensusCacheCounter.WithLabelValues(method, consensusCacheNegativeHit).Inc()
+			var zero T
+			return zero, entry.err
+		}
+		metrics.ConsensusCacheCounter.WithLabelValues(method, consensusCacheHit).Inc()
+		return entry.value.(T), nil
+	}
+
+	height := c.height.Load()
+	resultCh := c.group.DoChan(flightKey, func() (interface{}, error) {
+		queryCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.queryTimeout)
+		defer cancel()
+		value, err := fetch(queryCtx)
+		switch {
+		case err == nil:
+			c.add(key, &consensusCacheEntry{value: value, height: height, expireAt: time.Now().Add(policy.ttl)})
+		case isNotFound(err):
+			c.add(key, &consensusCacheEntry{err: err, height: height, expireAt: time.Now().Add(c.negativeTTL)})
+		}
+		return value, err
+	})
+	var result singleflight.Result
+	select {
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	case result = <-resultCh:
+	}
+	if result.Shared {
+		metrics.ConsensusCacheCounter.WithLabelValues(method, consensusCacheShared).Inc()
+	} else {
+		metrics.ConsensusCacheCounter.WithLabelValues(method, consensusCacheMiss).Inc()
+	}
+	if result.Err != nil {
+		var zero T
+		return zero, result.Err
+	}
+	return result.Val.(T), nil
+}
+
+func (c *CachedConsensus) get(key string, policy consensusCachePolicy) (*consensusCacheEntry, bool) {
+	v, ok := c.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	entry := v.(*consensusCacheEntry)
+	if time.Now().After(entry.expireAt) || ((policy.heightBound || entry.err != nil) && entry.height != c.height.Load()) {
+		c.cache.Remove(key)
+		return nil, false
+	}
+	return entry, true
+}
+
+func (c *CachedConsensus) add(key string, entry *consensusCacheEntry) {
+	c.cache.Add(key, entry)
+	metrics.ConsensusCacheSizeGauge.Set(float64(c.cache.Len()))
+}
+
+// isNotFound returns an indicator whether the error means the queried resource does not exist on chain.
+func isNotFound(err error) bool {
+	if status.Code(err) == codes.NotFound {
+		return true
+	}
+	return strings.Contains(err.Error(), "No such")
+}
+
+func (c *CachedConsensus) HasAccount(ctx context.Context, account string) (bool, error) {
+	return query(c, ctx, "HasAccount", func(ctx context.Context) (bool, error) {
+		return c.Consensus.HasAccount(ctx, account)
+	}, account)
+}
+
+func (c *CachedConsensus) ListSPs(ctx context.Context) ([]*sptypes.StorageProvider, error) {
+	return query(c, ctx, "ListSPs", func(ctx context.Context) ([]*sptypes.StorageProvider, error) {
+		return c.Consensus.ListSPs(ctx)
+	})
+}
+
+func (c *CachedConsensus) QuerySP(ctx context.Context, operatorAddress string) (*sptypes.StorageProvider, error) {
+	return query(c, ctx, "QuerySP", func(ctx context.Context) (*sptypes.StorageProvider, error) {
+		return c.Consensus.QuerySP(ctx, operatorAddress)
+	}, operatorAddress)
+}
+
+func (c *CachedConsensus) QuerySPByID(ctx context.Context, spID uint32) (*sptypes.StorageProvider, error) {
+	return query(c, ctx, "QuerySPByID", func(ctx context.Context) (*sptypes.StorageProvider, error) {
+		return c.Consensus.QuerySPByID(ctx, spID)
+	}, spID)
+}
+
+func (c *CachedConsensus) QuerySPFreeQuota(ctx context.Context, operatorAddress string) (uint64, error) {
+	return query(c, ctx, "QuerySPFreeQuota", func(ctx context.Context) (uint64, error) {
+		return c.Consensus.QuerySPFreeQuota(ctx, operatorAddress)
+	}, operatorAddress)
+}
+
+func (c *CachedConsensus) QuerySPPrice(ctx context.Context, operatorAddress string) (sptypes.SpStoragePrice, error) {
+	return query(c, ctx, "QuerySPPrice", func(ctx context.Context) (sptypes.SpStoragePrice, error) {
+		return c.Consensus.QuerySPPrice(ctx, operatorAddress)
+	}, operatorAddress)
+}
+
+func (c *CachedConsensus) QueryVirtualGroupFamily(ctx context.Context, vgfID uint32) (*virtualgrouptypes.GlobalVirtualGroupFamily, error) {
+	return query(c, ctx, "QueryVirtualGroupFamily", func(ctx context.Context) (*virtualgrouptypes.GlobalVirtualGroupFamily, error) {
+		return c.Consensus.QueryVirtualGroupFamily(ctx, vgfID)
+	}, vgfID)
+}
+
+func (c *CachedConsensus) QueryGlobalVirtualGroup(ctx context.Context, gvgID uint32) (*virtualgrouptypes.GlobalVirtualGroup, error) {
+	return query(c, ctx, "QueryGlobalVirtualGroup", func(ctx context.Context) (*virtualgrouptypes.GlobalVirtualGroup, error) {
+		return c.Consensus.QueryGlobalVirtualGroup(ctx, gvgID)
+	}, gvgID)
+}
+
+func (c *CachedConsensus) QueryVirtualGroupParams(ctx context.Context) (*virtualgrouptypes.Params, error) {
+	return query(c, ctx, "QueryVirtualGroupParams", func(ctx context.Context) (*virtualgrouptypes.Params, error) {
+		return c.Consensus.QueryVirtualGroupParams(ctx)
+	})
+}
+
+func (c *CachedConsensus) QueryStorageParams(ctx context.Context) (*storagetypes.Params, error) {
+	return query(c, ctx, "QueryStorageParams", func(ctx context.Context) (*storagetypes.Params, error) {
+		return c.Consensus.QueryStorageParams(ctx)
+	})
+}
+
+func (c *CachedConsensus) QueryStorageParamsByTimestamp(ctx context.Context, timestamp int64) (*storagetypes.Params, error) {
+	return query(c, ctx, "QueryStorageParamsByTimestamp", func(ctx context.Context) (*storagetypes.Params, error) {
+		return c.Consensus.QueryStorageParamsByTimestamp(ctx, timestamp)
+	}, timestamp)
+}
+
+func (c *CachedConsensus) QueryBucketInfo(ctx context.Context, bucket string) (*storagetypes.BucketInfo, error) {
+	return query(c, ctx, "QueryBucketInfo", func(ctx context.Context) (*storagetypes.BucketInfo, error) {
+		return c.Consensus.QueryBucketInfo(ctx, bucket)
+	}, bucket)
+}
+
+func (c *CachedConsensus) QueryBucketExtraInfo(ctx context.Context, bucket string) (*storagetypes.BucketExtraInfo, error) {
+	return query(c, ctx, "QueryBucketExtraInfo", func(ctx context.Context) (*storagetypes.BucketExtraInfo, error) {
+		return c.Consensus.QueryBucketExtraInfo(ctx, bucket)
+	}, bucket)
+}
+
+func (c *CachedConsensus) QueryBucketInfoById(ctx context.Context, bucketID uint64) (*storagetypes.BucketInfo, error) {
+	return query(c, ctx, "QueryBucketInfoById", func(ctx context.Context) (*storagetypes.BucketInfo, error) {
+		return c.Consensus.QueryBucketInfoById(ctx, bucketID)
+	}, bucketID)
+}
+
+func (c *CachedConsensus) QueryObjectInfo(ctx context.Context, bucket, object string) (*storagetypes.ObjectInfo, error) {
+	return query(c, ctx, "QueryObjectInfo", func(ctx context.Context) (*storagetypes.ObjectInfo, error) {
+		return c.Consensus.QueryObjectInfo(ctx, bucket, object)
+	}, bucket, "/", object)
+}
+
+func (c *CachedConsensus) QueryObjectInfoByID(ctx context.Context, objectID string) (*storagetypes.ObjectInfo, error) {
+	return query(c, ctx, "QueryObjectInfoByID", func(ctx context.Context) (*storagetypes.ObjectInfo, error) {
+		return c.Consensus.QueryObjectInfoByID(ctx, objectID)
+	}, objectID)
+}
+
+func (c *CachedConsensus) QueryBucketInfoAndObjectInfo(ctx context.Context, bucket, object string) (
+	*storagetypes.BucketInfo, *storagetypes.ObjectInfo, error,
+) {
+	info, err := query(c, ctx, "QueryBucketInfoAndObjectInfo", func(ctx context.Context) (bucketAndObjectInfo, error) {
+		bucketInfo, objectInfo, err := c.Consensus.QueryBucketInfoAndObjectInfo(ctx, bucket, object)
+		return bucketAndObjectInfo{bucketInfo: bucketInfo, objectInfo: objectInfo}, err
+	}, bucket, "/", object)
+	return info.bucketInfo, info.objectInfo, err
+}
+
+func (c *CachedConsensus) QueryPaymentStreamRecord(ctx context.Context, account string) (*paymenttypes.StreamRecord, error) {
+	return query(c, ctx, "QueryPaymentStreamRecord", func(ctx context.Context) (*paymenttypes.StreamRecord, error) {
+		return c.Consensus.QueryPaymentStreamRecord(ctx, account)
+	}, account)
+}
+
+func (c *CachedConsensus) VerifyGetObjectPermission(ctx context.Context, account, bucket, object string) (bool, error) {
+	return query(c, ctx, "VerifyGetObjectPermission", func(ctx context.Context) (bool, error) {
+		return c.Consensus.VerifyGetObjectPermission(ctx, account, bucket, object)
+	}, account, "/", bucket, "/", object)
+}
+
+func (c *CachedConsensus) VerifyPutObjectPermission(ctx context.Context, account, bucket, object string) (bool, error) {
+	return query(c, ctx, "VerifyPutObjectPermission", func(ctx context.Context) (bool, error) {
+		return c.Consensus.VerifyPutObjectPermission(ctx, account, bucket, object)
+	}, account, "/", bucket, "/", object)
+}
+
+func (c *CachedConsensus) VerifyUpdateObjectPermission(ctx context.Context, account, bucket, object string) (bool, error) {
+	return query(c, ctx, "VerifyUpdateObjectPermission", func(ctx context.Context) (bool, error) {
+		return c.Consensus.VerifyUpdateObjectPermission(ctx, account, bucket, object)
+	}, account, "/", bucket, "/", object)
+}
@@ -0,0 +1,218 @@
+package gnfd
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sptypes "github.com/evmos/evmos/v12/x/sp/types"
+	storagetypes "github.com/evmos/evmos/v12/x/storage/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zkMeLabs/mechain-storage-provider/core/consensus"
+)
+
+// mockConsensus counts the queries and returns the object info of the bucket "bucket", the queries fail if the
+// context is done before the delay.
+type mockConsensus struct {
+	consensus.NullConsensus
+	height  atomic.Uint64
+	queries atomic.Int32
+	delay   time.Duration
+	closed  bool
+}
+
+func (m *mockConsensus) CurrentHeight(context.Context) (uint64, error) {
+	return m.height.Load(), nil
+}
+
+func (m *mockConsensus) QueryObjectInfo(ctx context.Context, bucket, object string) (*storagetypes.ObjectInfo, error) {
+	m.queries.Add(1)
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(m.delay):
+	}
+	switch bucket {
+	case "bucket":
+		return &storagetypes.ObjectInfo{BucketName: bucket, ObjectName: object}, nil
+	case "error":
+		return nil, errors.New("mock error")
+	default:
+		return nil, errors.New("rpc error: code = Unknown desc = No such object: unknown request")
+	}
+}
+
+func (m *mockConsensus) QueryStorageParams(context.Context) (*storagetypes.Params, error) {
+	m.queries.Add(1)
+	return &storagetypes.Params{}, nil
+}
+
+func (m *mockConsensus) QuerySPByID(_ context.Context, spID uint32) (*sptypes.StorageProvider, error) {
+	m.queries.Add(1)
+	return &sptypes.StorageProvider{Id: spID}, nil
+}
+
+func (m *mockConsensus) Close() error {
+	m.closed = true
+	return nil
+}
+
+// mockRouterConsensus is the consensus which broadcasts the txs to the endpoint.
+type mockRouterConsensus struct {
+	mockConsensus
+	endpoint string
+}
+
+func (m *mockRouterConsensus) BroadcastEndpoint() string {
+	return m.endpoint
+}
+
+func newMockCachedConsensus(t *testing.T, ttl map[string]time.Duration) (*CachedConsensus, *mockConsensus) {
+	inner := &mockConsensus{}
+	inner.height.Store(1)
+	c, err := NewCachedConsensus(inner, &ConsensusCacheConfig{TTL: ttl, HeightRefreshInterval: time.Hour})
+	assert.Nil(t, err)
+	t.Cleanup(func() { _ = c.Close() })
+	return c, inner
+}
+
+func TestNewCachedConsensus(t *testing.T) {
+	_, err := NewCachedConsensus(&mockConsensus{}, &ConsensusCacheConfig{TTL: map[string]time.Duration{"WaitForNextBlock": time.Second}})
+	assert.NotNil(t, err)
+
+	c, err := NewCachedConsensus(&mockConsensus{}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultConsensusCacheNegativeTTL, c.negativeTTL)
+	assert.Equal(t, DefaultConsensusCacheQueryTimeout, c.queryTimeout)
+	assert.Equal(t, "", c.BroadcastEndpoint())
+	assert.Nil(t, c.Close())
+
+	c, err = NewCachedConsensus(&mockRouterConsensus{endpoint: "a"}, &ConsensusCacheConfig{HeightRefreshInterval: time.Hour})
+	assert.Nil(t, err)
+	assert.Equal(t, "a", c.BroadcastEndpoint())
+	assert.Nil(t, c.Close())
+	assert.Nil(t, c.Close())
+	assert.True(t, c.Consensus.(*mockConsensus).closed)
+}
+
+func TestCachedConsensusHeightInvalidation(t *testing.T) {
+	c, inner := newMockCachedConsensus(t, nil)
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		info, err := c.QueryObjectInfo(ctx, "bucket", "object")
+		assert.Nil(t, err)
+		assert.Equal(t, "object", info.GetObjectName())
+		_, err = c.QueryStorageParams(ctx)
+		assert.Nil(t, err)
+		_, err = c.QuerySPByID(ctx, 1)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, int32(3), inner.queries.Load())
+
+	// the object info and the sp are invalidated by the new block, the storage params are not height bound
+	inner.height.Store(2)
+	c.refreshHeight()
+	_, _ = c.QueryObjectInfo(ctx, "bucket", "object")
+	_, _ = c.QueryStorageParams(ctx)
+	_, _ = c.QuerySPByID(ctx, 1)
+	assert.Equal(t, int32(5), inner.queries.Load())
+	_, _ = c.QueryObjectInfo(ctx, "bucket", "other")
+	assert.Equal(t, int32(6), inner.queries.Load())
+}
+
+func TestCachedConsensusErrors(t *testing.T) {
+	c, inner := newMockCachedConsensus(t, nil)
+	ctx := context.Background()
+
+	// the not found results are cached until the next block
+	for i := 0; i < 2; i++ {
+		_, err := c.QueryObjectInfo(ctx, "missing", "object")
+		assert.Contains(t, err.Error(), "No such object")
+	}
+	assert.Equal(t, int32(1), inner.queries.Load())
+	inner.height.Store(2)
+	c.refreshHeight()
+	_, _ = c.QueryObjectInfo(ctx, "missing", "object")
+	assert.Equal(t, int32(2), inner.queries.Load())
+
+	// the other errors are not cached
+	for i := 0; i < 2; i++ {
+		_, err := c.QueryObjectInfo(ctx, "error", "object")
+		assert.NotNil(t, err)
+	}
+	assert.Equal(t, int32(4), inner.queries.Load())
+}
+
+func TestCachedConsensusRefresh(t *testing.T) {
+	c, inner := newMockCachedConsensus(t, nil)
+	ctx := context.Background()
+	_, _ = c.QueryStorageParams(ctx)
+	_, _ = c.QueryStorageParams(ctx)
+	assert.Equal(t, int32(1), inner.queries.Load())
+
+	// the refresh bypasses the cached result and replaces it
+	_, err := c.QueryStorageParams(consensus.WithRefresh(ctx))
+	assert.Nil(t, err)
+	assert.Equal(t, int32(2), inner.queries.Load())
+	_, _ = c.QueryStorageParams(ctx)
+	assert.Equal(t, int32(2), inner.queries.Load())
+}
+
+func TestCachedConsensusTTL(t *testing.T) {
+	c, inner := newMockCachedConsensus(t, map[string]time.Duration{
+		"QueryObjectInfo":    0,
+		"QueryStorageParams": time.Millisecond,
+	})
+	ctx := context.Background()
+	_, _ = c.QueryObjectInfo(ctx, "bucket", "object")
+	_, _ = c.QueryObjectInfo(ctx, "bucket", "object")
+	assert.Equal(t, int32(2), inner.queries.Load())
+
+	_, _ = c.QueryStorageParams(ctx)
+	time.Sleep(5 * time.Millisecond)
+	_, _ = c.QueryStorageParams(ctx)
+	assert.Equal(t, int32(4), inner.queries.Load())
+}
+
+func TestCachedConsensusSingleflight(t *testing.T) {
+	c, inner := newMockCachedConsensus(t, nil)
+	inner.delay = 50 * time.Millisecond
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			info, err := c.QueryObjectInfo(context.Background(), "bucket", "object")
+			assert.Nil(t, err)
+			assert.Equal(t, "bucket", info.GetBucketName())
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), inner.queries.Load())
+}
+
+func TestCachedConsensusSingleflightCanceled(t *testing.T) {
+	c, inner := newMockCachedConsensus(t, nil)
+	inner.delay = 50 * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, err := c.QueryObjectInfo(ctx, "bucket", "object")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	}()
+	time.Sleep(5 * time.Millisecond)
+
+	// the shared query is not canceled with the context of the caller starting it
+	info, err := c.QueryObjectInfo(context.Background(), "bucket", "object")
+	assert.Nil(t, err)
+	assert.Equal(t, "object", info.GetObjectName())
+	wg.Wait()
+	assert.Equal(t, int32(1), inner.queries.Load())
+}
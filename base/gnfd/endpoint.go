@@ -0,0 +1,157 @@
+package gnfd
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	chttp "github.com/cometbft/cometbft/rpc/client/http"
+
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/metrics"
+)
+
+const (
+	// DefaultEndpointProbeInterval defines the default interval of probing the latency and the height of the endpoints.
+	DefaultEndpointProbeInterval = 5 * time.Second
+	// DefaultMaxEndpointHeightLag defines the default max number of the blocks an endpoint can be behind the highest
+	// endpoint to serve the requests.
+	DefaultMaxEndpointHeightLag = 3
+
+	// endpointProbeTimeout defines the timeout of a probe, the endpoint is unhealthy if it does not respond in time.
+	endpointProbeTimeout = 3 * time.Second
+	// latencyWeight defines the weight of the latest probe in the moving average of the latency.
+	latencyWeight = 0.3
+	// readSwitchRatio defines the ratio of the latency of the current read endpoint the best endpoint must be below
+	// to take over the reads, so the reads do not flap between the endpoints of the similar latency.
+	readSwitchRatio = 0.8
+
+	routeRead      = "read"
+	routeBroadcast = "broadcast"
+)
+
+// endpoint is a chain endpoint scored by the latency and the height of the probes.
+type endpoint struct {
+	client   *MechainClient
+	wsClient *chttp.HTTP
+	// probe returns the latest block height of the endpoint.
+	probe func(ctx context.Context) (uint64, error)
+
+	// the scores are guarded by the mutex of Gnfd.
+	healthy bool
+	height  uint64
+	latency time.Duration
+}
+
+// eligible returns an indicator whether the endpoint is healthy and not behind the max height by more than maxLag.
+func (e *endpoint) eligible(maxHeight, maxLag uint64) bool {
+	return e.healthy && e.height+maxLag >= maxHeight
+}
+
+// probeEndpoints probes all the endpoints concurrently, updates their scores and selects the read and the broadcast
+// endpoints.
+func (g *Gnfd) probeEndpoints(ctx context.Context) {
+	type result struct {
+		height  uint64
+		latency time.Duration
+		err     error
+	}
+	results := make([]result, len(g.endpoints))
+	var wg sync.WaitGroup
+	for idx, e := range g.endpoints {
+		wg.Add(1)
+		go func(idx int, e *endpoint) {
+			defer wg.Done()
+			probeCtx, cancel := context.WithTimeout(ctx, endpointProbeTimeout)
+			defer cancel()
+			startTime := time.Now()
+			height, err := e.probe(probeCtx)
+			results[idx] = result{height: height, latency: time.Since(startTime), err: err}
+		}(idx, e)
+	}
+	wg.Wait()
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	for idx, e := range g.endpoints {
+		res := results[idx]
+		if res.err != nil {
+			log.Errorw("failed to probe chain endpoint", "node_addr", e.client.Provider, "error", res.err)
+			metrics.GnfdEndpointProbeCounter.WithLabelValues(e.client.Provider, "failure").Inc()
+			e.healthy = false
+			continue
+		}
+		metrics.GnfdEndpointProbeCounter.WithLabelValues(e.client.Provider, "success").Inc()
+		if !e.healthy || e.latency == 0 {
+			e.latency = res.latency
+		} else {
+			e.latency = time.Duration(latencyWeight*float64(res.latency) + (1-latencyWeight)*float64(e.latency))
+		}
+		e.healthy = true
+		e.height = res.height
+		e.client.currentHeight = int64(res.height)
+		e.client.updatedAt = time.Now()
+	}
+	g.selectEndpoints()
+}
+
+// selectEndpoints routes the reads to the eligible endpoint of the lowest latency, and the broadcasts to the
+// eligible endpoint of the highest height, the broadcast endpoint is kept while it is eligible so the txs of an
+// account are sent to the same mempool. The current endpoints are kept if no endpoint is eligible.
+// It must be called with the mutex held.
+func (g *Gnfd) selectEndpoints() {
+	var maxHeight uint64
+	for _, e := range g.endpoints {
+		if e.healthy && e.height > maxHeight {
+			maxHeight = e.height
+		}
+	}
+
+	var bestRead, bestBroadcast *endpoint
+	for _, e := range g.endpoints {
+		if !e.eligible(maxHeight, g.maxHeightLag) {
+			continue
+		}
+		if bestRead == nil || e.latency < bestRead.latency {
+			bestRead = e
+		}
+		if bestBroadcast == nil || e.height > bestBroadcast.height ||
+			(e.height == bestBroadcast.height && e.latency < bestBroadcast.latency) {
+			bestBroadcast = e
+		}
+	}
+	if !g.selected && bestRead != nil {
+		// the first endpoint serves until the endpoints are probed, it is not preferred over the best ones
+		g.read, g.broadcast, g.selected = bestRead, bestBroadcast, true
+	}
+	if bestRead != nil && g.read != bestRead && (!g.read.eligible(maxHeight, g.maxHeightLag) ||
+		float64(bestRead.latency) < readSwitchRatio*float64(g.read.latency)) {
+		log.Infow("switch chain read endpoint", "from", g.read.client.Provider, "to", bestRead.client.Provider,
+			"height", bestRead.height, "latency", bestRead.latency)
+		g.read = bestRead
+	}
+	if bestBroadcast != nil && g.broadcast != bestBroadcast && !g.broadcast.eligible(maxHeight, g.maxHeightLag) {
+		log.Infow("switch chain broadcast endpoint", "from", g.broadcast.client.Provider,
+			"to", bestBroadcast.client.Provider, "height", bestBroadcast.height)
+		g.broadcast = bestBroadcast
+	}
+
+	for _, e := range g.endpoints {
+		var lag uint64
+		if e.height < maxHeight {
+			lag = maxHeight - e.height
+		}
+		metrics.GnfdEndpointLatencyGauge.WithLabelValues(e.client.Provider).Set(e.latency.Seconds())
+		metrics.GnfdEndpointHeightLagGauge.WithLabelValues(e.client.Provider).Set(float64(lag))
+		metrics.GnfdEndpointSelectedGauge.WithLabelValues(e.client.Provider, routeRead).Set(boolGauge(e == g.read))
+		metrics.GnfdEndpointSelectedGauge.WithLabelValues(e.client.Provider, routeBroadcast).Set(
+			boolGauge(e == g.broadcast))
+	}
+}
+
+func boolGauge(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
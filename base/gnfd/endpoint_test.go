@@ -0,0 +1,126 @@
+package gnfd
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockEndpoint returns the height set by the test after the delay.
+type mockEndpoint struct {
+	mux    sync.Mutex
+	height uint64
+	delay  time.Duration
+	err    error
+}
+
+func (m *mockEndpoint) set(height uint64, delay time.Duration, err error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.height, m.delay, m.err = height, delay, err
+}
+
+func (m *mockEndpoint) probe(ctx context.Context) (uint64, error) {
+	m.mux.Lock()
+	height, delay, err := m.height, m.delay, m.err
+	m.mux.Unlock()
+	time.Sleep(delay)
+	return height, err
+}
+
+func newMockGnfd(providers ...string) (*Gnfd, map[string]*mockEndpoint) {
+	mocks := make(map[string]*mockEndpoint, len(providers))
+	endpoints := make([]*endpoint, 0, len(providers))
+	for _, provider := range providers {
+		m := &mockEndpoint{}
+		mocks[provider] = m
+		endpoints = append(endpoints, &endpoint{client: &MechainClient{Provider: provider}, probe: m.probe})
+	}
+	return newGnfd(endpoints, time.Hour, 2), mocks
+}
+
+func TestNewGnfd(t *testing.T) {
+	_, err := NewGnfd(&GnfdChainConfig{})
+	assert.NotNil(t, err)
+	_, err = NewGnfd(&GnfdChainConfig{ChainAddress: []string{"http://localhost:26750"}})
+	assert.NotNil(t, err)
+
+	g := newGnfd([]*endpoint{{client: &MechainClient{Provider: "a"}}}, 0, 0)
+	assert.Equal(t, DefaultEndpointProbeInterval, g.probeInterval)
+	assert.Equal(t, uint64(DefaultMaxEndpointHeightLag), g.maxHeightLag)
+	assert.Equal(t, "a", g.getCurrentClient().Provider)
+	assert.Equal(t, "a", g.BroadcastEndpoint())
+}
+
+func TestProbeEndpointsRouting(t *testing.T) {
+	g, mocks := newMockGnfd("a", "b", "c")
+	ctx := context.Background()
+
+	// the reads go to the fastest endpoint, the broadcasts go to the highest endpoint
+	mocks["a"].set(100, 30*time.Millisecond, nil)
+	mocks["b"].set(99, 0, nil)
+	mocks["c"].set(101, 30*time.Millisecond, nil)
+	g.probeEndpoints(ctx)
+	assert.Equal(t, "b", g.getCurrentClient().Provider)
+	assert.Equal(t, "c", g.BroadcastEndpoint())
+
+	// the lagging endpoint is excluded
+	mocks["a"].set(110, 30*time.Millisecond, nil)
+	mocks["b"].set(105, 0, nil)
+	mocks["c"].set(110, 30*time.Millisecond, nil)
+	g.probeEndpoints(ctx)
+	assert.Contains(t, []string{"a", "c"}, g.getCurrentClient().Provider)
+	assert.Equal(t, "c", g.BroadcastEndpoint())
+
+	// the broadcast endpoint is kept while it is eligible, even if another endpoint is higher
+	mocks["a"].set(112, 30*time.Millisecond, nil)
+	mocks["c"].set(111, 30*time.Millisecond, nil)
+	g.probeEndpoints(ctx)
+	assert.Equal(t, "c", g.BroadcastEndpoint())
+
+	// the failed endpoint is excluded
+	mocks["a"].set(113, 30*time.Millisecond, nil)
+	mocks["b"].set(112, 0, nil)
+	mocks["c"].set(0, 0, errors.New("mock error"))
+	g.probeEndpoints(ctx)
+	assert.Equal(t, "b", g.getCurrentClient().Provider)
+	assert.Equal(t, "a", g.BroadcastEndpoint())
+
+	assert.False(t, g.endpoints[2].healthy)
+}
+
+func TestProbeEndpointsNoEligible(t *testing.T) {
+	g, mocks := newMockGnfd("a", "b")
+	mocks["a"].set(0, 0, errors.New("mock error"))
+	mocks["b"].set(0, 0, errors.New("mock error"))
+	g.probeEndpoints(context.Background())
+	assert.Equal(t, "a", g.getCurrentClient().Provider)
+	assert.Equal(t, "a", g.BroadcastEndpoint())
+}
+
+func TestSelectEndpointsHysteresis(t *testing.T) {
+	g, _ := newMockGnfd("a", "b")
+	a, b := g.endpoints[0], g.endpoints[1]
+	a.healthy, a.height, a.latency = true, 100, 10*time.Millisecond
+	g.mutex.Lock()
+	g.selectEndpoints()
+	g.mutex.Unlock()
+	assert.Equal(t, "a", g.getCurrentClient().Provider)
+
+	// the endpoint of the similar latency does not take over the reads
+	b.healthy, b.height, b.latency = true, 100, 9*time.Millisecond
+	g.mutex.Lock()
+	g.selectEndpoints()
+	g.mutex.Unlock()
+	assert.Equal(t, "a", g.getCurrentClient().Provider)
+
+	b.latency = 5 * time.Millisecond
+	g.mutex.Lock()
+	g.selectEndpoints()
+	g.mutex.Unlock()
+	assert.Equal(t, "b", g.getCurrentClient().Provider)
+}
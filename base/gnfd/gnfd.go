@@ -12,14 +12,11 @@ import (
 	chainClient "github.com/evmos/evmos/v12/sdk/client"
 	"github.com/zkMeLabs/mechain-storage-provider/base/types/gfsperrors"
 	"github.com/zkMeLabs/mechain-storage-provider/core/consensus"
-	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
 	jsonclient "github.com/zkMeLabs/mechain-storage-provider/util/rpc/jsonrpc/client"
 )
 
 const (
 	MechainChain = "MechainChain"
-	// UpdateClientInternal defines the period of updating the best chain client
-	UpdateClientInternal = 60
 	// ExpectedOutputBlockInternal defines the time of estimating output block time
 	ExpectedOutputBlockInternal = 2
 )
@@ -45,19 +42,43 @@ func (client *MechainClient) GnfdClient() *chainClient.MechainClient {
 
 var _ consensus.Consensus = &Gnfd{}
 
+// BroadcastRouter is implemented by the consensus which routes the txs to one of the chain endpoints, the signer
+// broadcasts its txs to the same endpoint.
+type BroadcastRouter interface {
+	// BroadcastEndpoint returns the chain address of the endpoint the txs are broadcast to.
+	BroadcastEndpoint() string
+}
+
+var _ BroadcastRouter = &Gnfd{}
+
 type GnfdChainConfig struct {
 	ChainID      string
 	ChainAddress []string
 	RpcAddress   []string
+	// ProbeInterval is the interval of probing the latency and the height of the endpoints, default is
+	// DefaultEndpointProbeInterval.
+	ProbeInterval time.Duration
+	// MaxHeightLag is the max number of the blocks an endpoint can be behind the highest endpoint to serve the
+	// requests, default is DefaultMaxEndpointHeightLag.
+	MaxHeightLag uint64
+	// EnableObjectEventSubscription is whether to listen the seal and reject of the objects by a shared subscription
+	// of the chain events instead of polling every object, the objects are polled while the subscription is broken.
+	EnableObjectEventSubscription bool
 }
 
 type Gnfd struct {
-	client          *MechainClient
-	backUpClients   []*MechainClient
-	wsClient        *chttp.HTTP
-	backUpWsClients []*chttp.HTTP
-	stopCh          chan struct{}
-	mutex           sync.RWMutex
+	endpoints     []*endpoint
+	read          *endpoint
+	broadcast     *endpoint
+	probeInterval time.Duration
+	maxHeightLag  uint64
+	// selected indicates whether the endpoints have been selected by the probes.
+	selected bool
+	// objectEvents dispatches the subscribed object events, the objects are polled if it is nil.
+	objectEvents  *objectEventHub
+	blockInterval time.Duration
+	stopCh        chan struct{}
+	mutex         sync.RWMutex
 }
 
 // NewGnfd returns the Mechain instance.
@@ -65,103 +86,105 @@ func NewGnfd(cfg *GnfdChainConfig) (*Gnfd, error) {
 	if len(cfg.ChainAddress) == 0 {
 		return nil, errors.New("mechain nodes missing")
 	}
+	if len(cfg.RpcAddress) == 0 {
+		return nil, errors.New("mechain rpc address missing")
+	}
 
-	var clients []*MechainClient
-	var wsClients []*chttp.HTTP
-	for _, address := range cfg.ChainAddress {
-		cc, err := chainClient.NewCustomMechainClient(address, cfg.RpcAddress[0], cfg.ChainID, jsonclient.DefaultHTTPClient)
+	var endpoints []*endpoint
+	for idx, address := range cfg.ChainAddress {
+		// the chain addresses are paired with the rpc addresses if both are listed per node
+		rpcAddress := cfg.RpcAddress[0]
+		if len(cfg.RpcAddress) == len(cfg.ChainAddress) {
+			rpcAddress = cfg.RpcAddress[idx]
+		}
+		cc, err := chainClient.NewCustomMechainClient(address, rpcAddress, cfg.ChainID, jsonclient.DefaultHTTPClient)
 		if err != nil {
 			return nil, err
 		}
-
-		client := &MechainClient{
-			Provider:    address,
-			chainClient: cc,
-		}
-		clients = append(clients, client)
 		wsClient, err := chttp.New(address, "/websocket")
 		if err != nil {
 			return nil, err
 		}
-		wsClients = append(wsClients, wsClient)
-	}
-	mechain := &Gnfd{
-		client:          clients[0],
-		backUpClients:   clients,
-		wsClient:        wsClients[0],
-		backUpWsClients: wsClients,
-		stopCh:          make(chan struct{}),
+		endpoints = append(endpoints, &endpoint{
+			client: &MechainClient{
+				Provider:    address,
+				chainClient: cc,
+			},
+			wsClient: wsClient,
+			probe: func(ctx context.Context) (uint64, error) {
+				info, err := wsClient.ABCIInfo(ctx)
+				if err != nil {
+					return 0, err
+				}
+				return uint64(info.Response.LastBlockHeight), nil
+			},
+		})
 	}
-
+	mechain := newGnfd(endpoints, cfg.ProbeInterval, cfg.MaxHeightLag)
 	go mechain.updateClient()
+	if cfg.EnableObjectEventSubscription {
+		mechain.objectEvents = newObjectEventHub()
+		go mechain.runObjectEventSubscription(subscribeObjectEvents)
+	}
 	return mechain, nil
 }
 
+// newGnfd returns the Mechain instance which reads from and broadcasts to the first endpoint until they are probed.
+func newGnfd(endpoints []*endpoint, probeInterval time.Duration, maxHeightLag uint64) *Gnfd {
+	if probeInterval <= 0 {
+		probeInterval = DefaultEndpointProbeInterval
+	}
+	if maxHeightLag == 0 {
+		maxHeightLag = DefaultMaxEndpointHeightLag
+	}
+	return &Gnfd{
+		endpoints:     endpoints,
+		read:          endpoints[0],
+		broadcast:     endpoints[0],
+		probeInterval: probeInterval,
+		maxHeightLag:  maxHeightLag,
+		blockInterval: ExpectedOutputBlockInternal * time.Second,
+		stopCh:        make(chan struct{}),
+	}
+}
+
 // Close the Mechain instance.
 func (g *Gnfd) Close() error {
 	close(g.stopCh)
 	return nil
 }
 
-// getCurrentClient returns the current client to use.
+// getCurrentClient returns the client of the read endpoint.
 func (g *Gnfd) getCurrentClient() *MechainClient {
 	g.mutex.RLock()
 	defer g.mutex.RUnlock()
-	return g.client
+	return g.read.client
 }
 
-// setCurrentClient sets client to current client for using.
-func (g *Gnfd) setCurrentClient(client *MechainClient) {
-	g.mutex.Lock()
-	defer g.mutex.Unlock()
-	g.client = client
-}
-
-// getCurrentWsClient returns the current websocket client to get last block height use.
+// getCurrentWsClient returns the websocket client of the read endpoint to get last block height use.
 func (g *Gnfd) getCurrentWsClient() *chttp.HTTP {
 	g.mutex.RLock()
 	defer g.mutex.RUnlock()
-	return g.wsClient
+	return g.read.wsClient
 }
 
-// setCurrentWsAddress sets client to current websocket client for get last block height using.
-func (g *Gnfd) setCurrentWsAddress(client *chttp.HTTP) {
+// BroadcastEndpoint returns the chain address of the broadcast endpoint, which is the most up-to-date endpoint and
+// is kept while it is healthy and not lagging, so the txs of an account are sent to the same mempool.
+func (g *Gnfd) BroadcastEndpoint() string {
 	g.mutex.RLock()
 	defer g.mutex.RUnlock()
-	g.wsClient = client
+	return g.broadcast.client.Provider
 }
 
-// updateClient selects the client that block height is the largest and set to current client.
+// updateClient probes the endpoints every probe interval to route the reads and the broadcasts.
 func (g *Gnfd) updateClient() {
-	ticker := time.NewTicker(UpdateClientInternal * time.Second)
+	ticker := time.NewTicker(g.probeInterval)
+	defer ticker.Stop()
+	g.probeEndpoints(context.Background())
 	for {
 		select {
 		case <-ticker.C:
-			var (
-				maxHeight, _      = g.CurrentHeight(context.Background())
-				maxHeightClient   = g.getCurrentClient()
-				maxHeightWsClient = g.getCurrentWsClient()
-			)
-			for idx, client := range g.backUpWsClients {
-				info, err := client.ABCIInfo(context.Background())
-				if err != nil {
-					log.Errorw("failed to get latest block height",
-						"node_addr", g.backUpClients[idx].Provider, "error", err)
-					continue
-				}
-				currentHeight := uint64(info.Response.LastBlockHeight)
-				if currentHeight > maxHeight {
-					maxHeight = currentHeight
-					maxHeightClient = g.backUpClients[idx]
-					maxHeightWsClient = client
-				}
-				g.backUpClients[idx].currentHeight = (int64)(currentHeight)
-				g.backUpClients[idx].updatedAt = time.Now()
-			}
-			if maxHeightClient != g.getCurrentClient() {
-				g.setCurrentClient(maxHeightClient)
-				g.setCurrentWsAddress(maxHeightWsClient)
-			}
+			g.probeEndpoints(context.Background())
 		case <-g.stopCh:
 			return
 		}
@@ -671,7 +671,6 @@ func (g *Gnfd) QueryBucketInfoAndObjectInfo(ctx context.Context, bucket, object
 }
 
 // ListenObjectSeal returns an indication of the object is sealed.
-// TODO:: retrieve service support seal event subscription
 func (g *Gnfd) ListenObjectSeal(ctx context.Context, objectID uint64, timeoutHeight int) (seal bool, err error) {
 	startTime := time.Now()
 	defer func() {
@@ -692,7 +691,38 @@ func (g *Gnfd) ListenObjectSeal(ctx context.Context, objectID uint64, timeoutHei
 			time.Since(startTime).Seconds())
 	}()
 
-	var objectInfo *storagetypes.ObjectInfo
+	if g.objectEvents != nil {
+		seal, err = g.waitObjectEvent(ctx, objectID, timeoutHeight, func(ctx context.Context) (bool, error) {
+			objectInfo, queryErr := g.QueryObjectInfoByID(ctx, strconv.FormatUint(objectID, 10))
+			if queryErr != nil {
+				return false, queryErr
+			}
+			return objectInfo.GetObjectStatus() == storagetypes.OBJECT_STATUS_SEALED && !objectInfo.GetIsUpdating(), nil
+		})
+		if seal {
+			log.CtxDebugw(ctx, "succeed to listen object stat")
+			return true, nil
+		}
+	} else {
+		seal, err = g.pollObjectSeal(ctx, objectID, timeoutHeight)
+		if seal {
+			return true, nil
+		}
+	}
+	if err == nil {
+		log.CtxErrorw(ctx, "seal object timeout", "object_id", objectID)
+		return false, ErrSealTimeout
+	}
+	log.CtxErrorw(ctx, "failed to listen seal object", "object_id", objectID, "error", err)
+	return false, err
+}
+
+// pollObjectSeal polls the object every block until it is sealed, it returns the last error of the queries.
+func (g *Gnfd) pollObjectSeal(ctx context.Context, objectID uint64, timeoutHeight int) (bool, error) {
+	var (
+		objectInfo *storagetypes.ObjectInfo
+		err        error
+	)
 	for i := 0; i < timeoutHeight; i++ {
 		objectInfo, err = g.QueryObjectInfoByID(ctx, strconv.FormatUint(objectID, 10))
 		if err != nil {
@@ -705,16 +735,10 @@ func (g *Gnfd) ListenObjectSeal(ctx context.Context, objectID uint64, timeoutHei
 		}
 		time.Sleep(ExpectedOutputBlockInternal * time.Second)
 	}
-	if err == nil {
-		log.CtxErrorw(ctx, "seal object timeout", "object_id", objectID)
-		return false, ErrSealTimeout
-	}
-	log.CtxErrorw(ctx, "failed to listen seal object", "object_id", objectID, "error", err)
 	return false, err
 }
 
 // ListenRejectUnSealObject returns an indication of the object is rejected.
-// TODO:: retrieve service support reject unseal event subscription
 func (g *Gnfd) ListenRejectUnSealObject(ctx context.Context, objectID uint64, timeoutHeight int) (rejected bool, err error) {
 	startTime := time.Now()
 	defer func() {
@@ -735,14 +759,27 @@ func (g *Gnfd) ListenRejectUnSealObject(ctx context.Context, objectID uint64, ti
 			time.Since(startTime).Seconds())
 	}()
 
-	for i := 0; i < timeoutHeight; i++ {
-		_, err = g.QueryObjectInfoByID(ctx, strconv.FormatUint(objectID, 10))
-		if err != nil {
-			if strings.Contains(err.Error(), "No such object") {
+	if g.objectEvents != nil {
+		rejected, err = g.waitObjectEvent(ctx, objectID, timeoutHeight, func(ctx context.Context) (bool, error) {
+			_, queryErr := g.QueryObjectInfoByID(ctx, strconv.FormatUint(objectID, 10))
+			if queryErr != nil && strings.Contains(queryErr.Error(), "No such object") {
 				return true, nil
 			}
+			return false, queryErr
+		})
+		if rejected {
+			return true, nil
+		}
+	} else {
+		for i := 0; i < timeoutHeight; i++ {
+			_, err = g.QueryObjectInfoByID(ctx, strconv.FormatUint(objectID, 10))
+			if err != nil {
+				if strings.Contains(err.Error(), "No such object") {
+					return true, nil
+				}
+			}
+			time.Sleep(ExpectedOutputBlockInternal * time.Second)
 		}
-		time.Sleep(ExpectedOutputBlockInternal * time.Second)
 	}
 	if err == nil {
 		log.CtxErrorw(ctx, "reject unseal object timeout", "object_id", objectID)
@@ -0,0 +1,308 @@
+package gnfd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	chttp "github.com/cometbft/cometbft/rpc/client/http"
+	tmctypes "github.com/cometbft/cometbft/rpc/core/types"
+	"github.com/cosmos/gogoproto/proto"
+	storagetypes "github.com/evmos/evmos/v12/x/storage/types"
+
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/metrics"
+)
+
+const (
+	// objectEventSubscriber defines the subscriber name of the object event subscription.
+	objectEventSubscriber = "mechain-sp-object-event"
+	// objectEventCapacity defines the buffer size of the subscribed events.
+	objectEventCapacity = 1024
+	// objectEventCheckInterval defines the interval of checking the subscription, the broken subscription is
+	// resubscribed, and so is the subscription of the endpoint which no longer serves the reads.
+	objectEventCheckInterval = 5 * time.Second
+
+	objectEventSeal   = "seal"
+	objectEventReject = "reject"
+	objectEventResync = "resync"
+)
+
+var (
+	eventSealObject       = proto.MessageName(&storagetypes.EventSealObject{})
+	eventRejectSealObject = proto.MessageName(&storagetypes.EventRejectSealObject{})
+
+	// objectEventNames maps the subscribed event types to the names of the dispatched events.
+	objectEventNames = map[string]string{
+		eventSealObject:       objectEventSeal,
+		eventRejectSealObject: objectEventReject,
+	}
+)
+
+// objectEventSubscription is the subscription of the seal and reject events of the objects.
+type objectEventSubscription interface {
+	// Events returns the channel of the subscribed events, it is closed if the subscription is broken.
+	Events() <-chan tmctypes.ResultEvent
+	// IsRunning returns an indicator whether the subscription is alive.
+	IsRunning() bool
+	// Close closes the subscription.
+	Close()
+}
+
+// objectEventSubscribeFunc subscribes the object events of the endpoint.
+type objectEventSubscribeFunc func(ctx context.Context, provider string) (objectEventSubscription, error)
+
+// objectEventHub dispatches the object events of the shared subscription to the goroutines listening to the objects.
+type objectEventHub struct {
+	mux       sync.Mutex
+	listeners map[uint64]map[chan string]struct{}
+	connected bool
+}
+
+func newObjectEventHub() *objectEventHub {
+	return &objectEventHub{listeners: make(map[uint64]map[chan string]struct{})}
+}
+
+// listen returns the channel receiving the events of the object, it must be released after use.
+func (h *objectEventHub) listen(objectID uint64) chan string {
+	ch := make(chan string, 1)
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	if h.listeners[objectID] == nil {
+		h.listeners[objectID] = make(map[chan string]struct{})
+	}
+	h.listeners[objectID][ch] = struct{}{}
+	return ch
+}
+
+// release stops the channel from receiving the events of the object.
+func (h *objectEventHub) release(objectID uint64, ch chan string) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	delete(h.listeners[objectID], ch)
+	if len(h.listeners[objectID]) == 0 {
+		delete(h.listeners, objectID)
+	}
+}
+
+// dispatch notifies the listeners of the object, the event is dropped for the listener which has not consumed the
+// last one, since any event makes the listener check the object on chain.
+func (h *objectEventHub) dispatch(objectID uint64, event string) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	for ch := range h.listeners[objectID] {
+		notify(ch, event)
+	}
+}
+
+// setConnected sets the subscription state, all the listeners resync on the connection since the events before it
+// are missed.
+func (h *objectEventHub) setConnected(connected bool) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	h.connected = connected
+	if !connected {
+		metrics.GnfdObjectEventSubscribedGauge.Set(0)
+		return
+	}
+	metrics.GnfdObjectEventSubscribedGauge.Set(1)
+	for _, listeners := range h.listeners {
+		for ch := range listeners {
+			notify(ch, objectEventResync)
+		}
+	}
+}
+
+// isConnected returns an indicator whether the events are subscribed, the listeners poll the objects if not.
+func (h *objectEventHub) isConnected() bool {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	return h.connected
+}
+
+func notify(ch chan string, event string) {
+	select {
+	case ch <- event:
+	default:
+	}
+}
+
+// handleEvent dispatches the seal and reject events of the objects in the tx event.
+func (h *objectEventHub) handleEvent(event tmctypes.ResultEvent) {
+	for eventType, name := range objectEventNames {
+		for _, value := range event.Events[eventType+".object_id"] {
+			// the attributes of the typed events are json encoded, e.g. "\"1\""
+			objectID, err := strconv.ParseUint(strings.Trim(value, "\""), 10, 64)
+			if err != nil {
+				log.Errorw("failed to parse object id of the event", "event", eventType, "value", value, "error", err)
+				continue
+			}
+			metrics.GnfdObjectEventCounter.WithLabelValues(name).Inc()
+			h.dispatch(objectID, name)
+		}
+	}
+}
+
+// runObjectEventSubscription subscribes the object events of the read endpoint until the Gnfd is closed.
+func (g *Gnfd) runObjectEventSubscription(subscribe objectEventSubscribeFunc) {
+	ticker := time.NewTicker(objectEventCheckInterval)
+	defer ticker.Stop()
+	var (
+		sub      objectEventSubscription
+		provider string
+	)
+	disconnect := func() {
+		if sub != nil {
+			sub.Close()
+			sub = nil
+		}
+		g.objectEvents.setConnected(false)
+	}
+	defer disconnect()
+	for {
+		if sub == nil {
+			provider = g.getCurrentClient().Provider
+			s, err := subscribe(context.Background(), provider)
+			if err != nil {
+				log.Errorw("failed to subscribe object events, poll the objects instead", "node_addr", provider,
+					"error", err)
+			} else {
+				log.Infow("succeed to subscribe object events", "node_addr", provider)
+				sub = s
+				g.objectEvents.setConnected(true)
+			}
+		}
+		var events <-chan tmctypes.ResultEvent
+		if sub != nil {
+			events = sub.Events()
+		}
+		select {
+		case <-g.stopCh:
+			return
+		case event, ok := <-events:
+			if !ok {
+				log.Errorw("object event subscription is broken", "node_addr", provider)
+				disconnect()
+				continue
+			}
+			g.objectEvents.handleEvent(event)
+		case <-ticker.C:
+			if sub != nil && !sub.IsRunning() {
+				log.Errorw("object event subscription is stopped", "node_addr", provider)
+				disconnect()
+			} else if sub != nil && provider != g.getCurrentClient().Provider {
+				disconnect()
+			}
+		}
+	}
+}
+
+// waitObjectEvent waits until check returns true, check is called on the events of the object, and every block
+// while the events are not subscribed. It returns the last error of check if the object does not reach the state
+// in timeoutHeight blocks.
+func (g *Gnfd) waitObjectEvent(ctx context.Context, objectID uint64, timeoutHeight int,
+	check func(ctx context.Context) (bool, error),
+) (bool, error) {
+	ch := g.objectEvents.listen(objectID)
+	defer g.objectEvents.release(objectID, ch)
+	if ok, err := check(ctx); ok {
+		return true, err
+	}
+	timeout := time.NewTimer(time.Duration(timeoutHeight) * g.blockInterval)
+	defer timeout.Stop()
+	ticker := time.NewTicker(g.blockInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-timeout.C:
+			return check(ctx)
+		case <-ch:
+		case <-ticker.C:
+			if g.objectEvents.isConnected() {
+				continue
+			}
+		}
+		if ok, err := check(ctx); ok {
+			return true, err
+		}
+	}
+}
+
+// wsObjectEventSubscription subscribes the object events by the websocket of the CometBFT rpc.
+type wsObjectEventSubscription struct {
+	client *chttp.HTTP
+	events chan tmctypes.ResultEvent
+	stopCh chan struct{}
+}
+
+// subscribeObjectEvents subscribes the seal and reject events of the objects by the websocket of the endpoint.
+func subscribeObjectEvents(ctx context.Context, provider string) (objectEventSubscription, error) {
+	client, err := chttp.New(provider, "/websocket")
+	if err != nil {
+		return nil, err
+	}
+	if err = client.Start(); err != nil {
+		return nil, err
+	}
+	sub := &wsObjectEventSubscription{
+		client: client,
+		events: make(chan tmctypes.ResultEvent, objectEventCapacity),
+		stopCh: make(chan struct{}),
+	}
+	var channels []<-chan tmctypes.ResultEvent
+	for _, eventType := range []string{eventSealObject, eventRejectSealObject} {
+		query := fmt.Sprintf("tm.event='Tx' AND %s.object_id EXISTS", eventType)
+		ch, err := client.Subscribe(ctx, objectEventSubscriber, query, objectEventCapacity)
+		if err != nil {
+			_ = client.Stop()
+			return nil, err
+		}
+		channels = append(channels, ch)
+	}
+	go sub.forward(channels[0], channels[1])
+	return sub, nil
+}
+
+// forward merges the events of the subscriptions until the subscription is closed.
+func (s *wsObjectEventSubscription) forward(seal, reject <-chan tmctypes.ResultEvent) {
+	defer close(s.events)
+	for {
+		var (
+			event tmctypes.ResultEvent
+			ok    bool
+		)
+		select {
+		case <-s.stopCh:
+			return
+		case event, ok = <-seal:
+		case event, ok = <-reject:
+		}
+		if !ok {
+			return
+		}
+		select {
+		case s.events <- event:
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+func (s *wsObjectEventSubscription) Events() <-chan tmctypes.ResultEvent {
+	return s.events
+}
+
+func (s *wsObjectEventSubscription) IsRunning() bool {
+	return s.client.IsRunning()
+}
+
+func (s *wsObjectEventSubscription) Close() {
+	close(s.stopCh)
+	_ = s.client.UnsubscribeAll(context.Background(), objectEventSubscriber)
+	_ = s.client.Stop()
+}
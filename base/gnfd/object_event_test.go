@@ -0,0 +1,149 @@
+package gnfd
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	tmctypes "github.com/cometbft/cometbft/rpc/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockObjectEventSubscription delivers the events sent by the test.
+type mockObjectEventSubscription struct {
+	events    chan tmctypes.ResultEvent
+	closeOnce sync.Once
+	closed    atomic.Bool
+}
+
+func (m *mockObjectEventSubscription) Events() <-chan tmctypes.ResultEvent { return m.events }
+
+func (m *mockObjectEventSubscription) IsRunning() bool { return !m.closed.Load() }
+
+func (m *mockObjectEventSubscription) Close() { m.closeOnce.Do(func() { m.closed.Store(true) }) }
+
+func newMockObjectEventGnfd() *Gnfd {
+	g, _ := newMockGnfd("a")
+	g.objectEvents = newObjectEventHub()
+	g.blockInterval = 10 * time.Millisecond
+	return g
+}
+
+func sealEvent(objectIDs ...string) tmctypes.ResultEvent {
+	return tmctypes.ResultEvent{Events: map[string][]string{eventSealObject + ".object_id": objectIDs}}
+}
+
+func TestObjectEventHub(t *testing.T) {
+	h := newObjectEventHub()
+	ch1 := h.listen(1)
+	ch2 := h.listen(2)
+
+	h.handleEvent(sealEvent("\"1\"", "invalid"))
+	assert.Equal(t, objectEventSeal, <-ch1)
+	assert.Equal(t, 0, len(ch2))
+
+	h.handleEvent(tmctypes.ResultEvent{Events: map[string][]string{eventRejectSealObject + ".object_id": {"\"2\""}}})
+	assert.Equal(t, objectEventReject, <-ch2)
+
+	// the listeners resync on the connection, the events are dropped if the last one is not consumed
+	h.setConnected(true)
+	h.dispatch(1, objectEventSeal)
+	assert.True(t, h.isConnected())
+	assert.Equal(t, objectEventResync, <-ch1)
+	assert.Equal(t, 0, len(ch1))
+
+	h.release(1, ch1)
+	h.release(2, ch2)
+	assert.Equal(t, 0, len(h.listeners))
+}
+
+func TestWaitObjectEvent(t *testing.T) {
+	g := newMockObjectEventGnfd()
+	g.objectEvents.setConnected(true)
+	ctx := context.Background()
+
+	// the object is only checked on its events while the events are subscribed
+	var checks atomic.Int32
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ok, err := g.waitObjectEvent(ctx, 1, 100, func(context.Context) (bool, error) {
+			return checks.Add(1) == 2, nil
+		})
+		assert.True(t, ok)
+		assert.Nil(t, err)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), checks.Load())
+	g.objectEvents.handleEvent(sealEvent("\"1\""))
+	<-done
+	assert.Equal(t, int32(2), checks.Load())
+}
+
+func TestWaitObjectEventPollingFallback(t *testing.T) {
+	g := newMockObjectEventGnfd()
+	ctx := context.Background()
+
+	// the object is polled every block while the events are not subscribed
+	var checks atomic.Int32
+	ok, err := g.waitObjectEvent(ctx, 1, 100, func(context.Context) (bool, error) {
+		return checks.Add(1) == 3, nil
+	})
+	assert.True(t, ok)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(3), checks.Load())
+
+	// the last error is returned on the timeout
+	mockErr := errors.New("mock error")
+	g.objectEvents.setConnected(true)
+	ok, err = g.waitObjectEvent(ctx, 1, 2, func(context.Context) (bool, error) {
+		return false, mockErr
+	})
+	assert.False(t, ok)
+	assert.Equal(t, mockErr, err)
+
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = g.waitObjectEvent(cancelCtx, 1, 100, func(context.Context) (bool, error) {
+		return false, nil
+	})
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestRunObjectEventSubscription(t *testing.T) {
+	g := newMockObjectEventGnfd()
+	subs := make(chan *mockObjectEventSubscription, 2)
+	subscribe := func(ctx context.Context, provider string) (objectEventSubscription, error) {
+		assert.Equal(t, "a", provider)
+		sub := &mockObjectEventSubscription{events: make(chan tmctypes.ResultEvent)}
+		subs <- sub
+		return sub, nil
+	}
+	ch := g.objectEvents.listen(1)
+	defer g.objectEvents.release(1, ch)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		g.runObjectEventSubscription(subscribe)
+	}()
+
+	sub := <-subs
+	assert.Equal(t, objectEventResync, <-ch)
+	sub.events <- sealEvent("\"1\"")
+	assert.Equal(t, objectEventSeal, <-ch)
+
+	// the broken subscription is resubscribed at once
+	close(sub.events)
+	next := <-subs
+	assert.True(t, sub.closed.Load())
+	assert.Equal(t, objectEventResync, <-ch)
+	assert.True(t, g.objectEvents.isConnected())
+
+	assert.Nil(t, g.Close())
+	<-stopped
+	assert.True(t, next.closed.Load())
+	assert.False(t, g.objectEvents.isConnected())
+}
@@ -0,0 +1,517 @@
+package simchain
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	sdkmath "cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	paymenttypes "github.com/evmos/evmos/v12/x/payment/types"
+	sptypes "github.com/evmos/evmos/v12/x/sp/types"
+	storagetypes "github.com/evmos/evmos/v12/x/storage/types"
+	virtualgrouptypes "github.com/evmos/evmos/v12/x/virtualgroup/types"
+
+	"github.com/zkMeLabs/mechain-storage-provider/base/gnfd"
+	"github.com/zkMeLabs/mechain-storage-provider/core/consensus"
+)
+
+var _ consensus.Consensus = &Chain{}
+
+// CurrentHeight returns the latest block height.
+func (c *Chain) CurrentHeight(ctx context.Context) (uint64, error) {
+	if err := c.fault("CurrentHeight"); err != nil {
+		return 0, err
+	}
+	return c.Height(), nil
+}
+
+// HasAccount returns an indicator whether the account has been created.
+func (c *Chain) HasAccount(ctx context.Context, account string) (bool, error) {
+	if err := c.fault("HasAccount"); err != nil {
+		return false, err
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.accounts[strings.ToLower(account)], nil
+}
+
+// ListSPs returns all SP info ordered by the id.
+func (c *Chain) ListSPs(ctx context.Context) ([]*sptypes.StorageProvider, error) {
+	if err := c.fault("ListSPs"); err != nil {
+		return nil, err
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	sps := make([]*sptypes.StorageProvider, 0, len(c.sps))
+	for id := uint32(1); id <= c.nextSPID; id++ {
+		if sp, ok := c.sps[id]; ok {
+			sps = append(sps, clone(sp))
+		}
+	}
+	return sps, nil
+}
+
+// QuerySP returns the sp info by operator address.
+func (c *Chain) QuerySP(ctx context.Context, operatorAddress string) (*sptypes.StorageProvider, error) {
+	if err := c.fault("QuerySP"); err != nil {
+		return nil, err
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	sp := c.spByOperator(operatorAddress)
+	if sp == nil {
+		return nil, fmt.Errorf("%w: %s", sptypes.ErrStorageProviderNotFound, operatorAddress)
+	}
+	return clone(sp), nil
+}
+
+// QuerySPByID returns the sp info by sp id.
+func (c *Chain) QuerySPByID(ctx context.Context, spID uint32) (*sptypes.StorageProvider, error) {
+	if err := c.fault("QuerySPByID"); err != nil {
+		return nil, err
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	sp, ok := c.sps[spID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", sptypes.ErrStorageProviderNotFound, spID)
+	}
+	return clone(sp), nil
+}
+
+// QuerySPFreeQuota returns the sp free quota by operator address.
+func (c *Chain) QuerySPFreeQuota(ctx context.Context, operatorAddress string) (uint64, error) {
+	price, err := c.querySPPrice("QuerySPFreeQuota", operatorAddress)
+	if err != nil {
+		return 0, err
+	}
+	return price.FreeReadQuota, nil
+}
+
+// QuerySPPrice returns the sp price info set by SetSPPrice.
+func (c *Chain) QuerySPPrice(ctx context.Context, operatorAddress string) (sptypes.SpStoragePrice, error) {
+	return c.querySPPrice("QuerySPPrice", operatorAddress)
+}
+
+func (c *Chain) querySPPrice(method, operatorAddress string) (sptypes.SpStoragePrice, error) {
+	if err := c.fault(method); err != nil {
+		return sptypes.SpStoragePrice{}, err
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	sp := c.spByOperator(operatorAddress)
+	if sp == nil {
+		return sptypes.SpStoragePrice{}, fmt.Errorf("%w: %s", sptypes.ErrStorageProviderNotFound, operatorAddress)
+	}
+	price, ok := c.spPrices[sp.GetId()]
+	if !ok {
+		return sptypes.SpStoragePrice{}, fmt.Errorf("no storage price of the storage provider: %d", sp.GetId())
+	}
+	return price, nil
+}
+
+// ListBondedValidators returns no validator, the simulated chain has no staking.
+func (c *Chain) ListBondedValidators(ctx context.Context) ([]stakingtypes.Validator, error) {
+	if err := c.fault("ListBondedValidators"); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// ListVirtualGroupFamilies returns all the families which primary sp is spID.
+func (c *Chain) ListVirtualGroupFamilies(ctx context.Context, spID uint32) ([]*virtualgrouptypes.GlobalVirtualGroupFamily, error) {
+	if err := c.fault("ListVirtualGroupFamilies"); err != nil {
+		return nil, err
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	var families []*virtualgrouptypes.GlobalVirtualGroupFamily
+	for id := uint32(1); id <= c.nextFamilyID; id++ {
+		if family, ok := c.families[id]; ok && family.GetPrimarySpId() == spID {
+			families = append(families, clone(family))
+		}
+	}
+	return families, nil
+}
+
+// QueryVirtualGroupFamily returns the virtual group family info.
+func (c *Chain) QueryVirtualGroupFamily(ctx context.Context, vgfID uint32) (*virtualgrouptypes.GlobalVirtualGroupFamily, error) {
+	if err := c.fault("QueryVirtualGroupFamily"); err != nil {
+		return nil, err
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	family, ok := c.families[vgfID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", virtualgrouptypes.ErrGVGFamilyNotExist, vgfID)
+	}
+	return clone(family), nil
+}
+
+// QueryGlobalVirtualGroup returns the global virtual group info.
+func (c *Chain) QueryGlobalVirtualGroup(ctx context.Context, gvgID uint32) (*virtualgrouptypes.GlobalVirtualGroup, error) {
+	if err := c.fault("QueryGlobalVirtualGroup"); err != nil {
+		return nil, err
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	gvg, ok := c.gvgs[gvgID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", virtualgrouptypes.ErrGVGNotExist, gvgID)
+	}
+	return clone(gvg), nil
+}
+
+// ListGlobalVirtualGroupsByFamilyID returns the gvgs of the family.
+func (c *Chain) ListGlobalVirtualGroupsByFamilyID(ctx context.Context, vgfID uint32) ([]*virtualgrouptypes.GlobalVirtualGroup, error) {
+	if err := c.fault("ListGlobalVirtualGroupsByFamilyID"); err != nil {
+		return nil, err
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	family, ok := c.families[vgfID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", virtualgrouptypes.ErrGVGFamilyNotExist, vgfID)
+	}
+	gvgs := make([]*virtualgrouptypes.GlobalVirtualGroup, 0, len(family.GetGlobalVirtualGroupIds()))
+	for _, gvgID := range family.GetGlobalVirtualGroupIds() {
+		if gvg, ok := c.gvgs[gvgID]; ok {
+			gvgs = append(gvgs, clone(gvg))
+		}
+	}
+	return gvgs, nil
+}
+
+// AvailableGlobalVirtualGroupFamilies returns the families which exist and have gvgs.
+func (c *Chain) AvailableGlobalVirtualGroupFamilies(ctx context.Context, globalVirtualGroupFamiliesIDs []uint32) ([]uint32, error) {
+	if err := c.fault("AvailableGlobalVirtualGroupFamilies"); err != nil {
+		return nil, err
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	var available []uint32
+	for _, id := range globalVirtualGroupFamiliesIDs {
+		if family, ok := c.families[id]; ok && len(family.GetGlobalVirtualGroupIds()) > 0 {
+			available = append(available, id)
+		}
+	}
+	return available, nil
+}
+
+// QueryVirtualGroupParams returns the virtual group params.
+func (c *Chain) QueryVirtualGroupParams(ctx context.Context) (*virtualgrouptypes.Params, error) {
+	if err := c.fault("QueryVirtualGroupParams"); err != nil {
+		return nil, err
+	}
+	return c.vgParams, nil
+}
+
+// QueryStorageParams returns the storage params.
+func (c *Chain) QueryStorageParams(ctx context.Context) (*storagetypes.Params, error) {
+	if err := c.fault("QueryStorageParams"); err != nil {
+		return nil, err
+	}
+	return c.storageParams, nil
+}
+
+// QueryStorageParamsByTimestamp returns the storage params, the params are not versioned by the simulated chain.
+func (c *Chain) QueryStorageParamsByTimestamp(ctx context.Context, timestamp int64) (*storagetypes.Params, error) {
+	if err := c.fault("QueryStorageParamsByTimestamp"); err != nil {
+		return nil, err
+	}
+	return c.storageParams, nil
+}
+
+// QueryBucketInfo returns the bucket info by bucket name.
+func (c *Chain) QueryBucketInfo(ctx context.Context, bucket string) (*storagetypes.BucketInfo, error) {
+	if err := c.fault("QueryBucketInfo"); err != nil {
+		return nil, err
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	info, ok := c.buckets[bucket]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", storagetypes.ErrNoSuchBucket, bucket)
+	}
+	return clone(info), nil
+}
+
+// QueryBucketExtraInfo returns the bucket extra info by bucket name.
+func (c *Chain) QueryBucketExtraInfo(ctx context.Context, bucket string) (*storagetypes.BucketExtraInfo, error) {
+	if err := c.fault("QueryBucketExtraInfo"); err != nil {
+		return nil, err
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if _, ok := c.buckets[bucket]; !ok {
+		return nil, fmt.Errorf("%w: %s", storagetypes.ErrNoSuchBucket, bucket)
+	}
+	return &storagetypes.BucketExtraInfo{}, nil
+}
+
+// QueryBucketInfoById returns the bucket info by bucket id.
+func (c *Chain) QueryBucketInfoById(ctx context.Context, bucketId uint64) (*storagetypes.BucketInfo, error) {
+	if err := c.fault("QueryBucketInfoById"); err != nil {
+		return nil, err
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	for _, info := range c.buckets {
+		if info.Id.Uint64() == bucketId {
+			return clone(info), nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %d", storagetypes.ErrNoSuchBucket, bucketId)
+}
+
+// QueryObjectInfo returns the object info by bucket and object name.
+func (c *Chain) QueryObjectInfo(ctx context.Context, bucket, object string) (*storagetypes.ObjectInfo, error) {
+	if err := c.fault("QueryObjectInfo"); err != nil {
+		return nil, err
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	info, ok := c.objects[objectKey(bucket, object)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", storagetypes.ErrNoSuchObject, object)
+	}
+	return clone(info), nil
+}
+
+// QueryObjectInfoByID returns the object info by object ID.
+func (c *Chain) QueryObjectInfoByID(ctx context.Context, objectID string) (*storagetypes.ObjectInfo, error) {
+	if err := c.fault("QueryObjectInfoByID"); err != nil {
+		return nil, err
+	}
+	id, err := strconv.ParseUint(objectID, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	info := c.objectByID(id)
+	if info == nil {
+		return nil, fmt.Errorf("%w: %s", storagetypes.ErrNoSuchObject, objectID)
+	}
+	return clone(info), nil
+}
+
+// QueryBucketInfoAndObjectInfo returns the bucket and object info by bucket and object name, the bucket info is
+// returned with the error if the object does not exist as the Gnfd does.
+func (c *Chain) QueryBucketInfoAndObjectInfo(ctx context.Context, bucket, object string) (*storagetypes.BucketInfo,
+	*storagetypes.ObjectInfo, error,
+) {
+	if err := c.fault("QueryBucketInfoAndObjectInfo"); err != nil {
+		return nil, nil, err
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	bucketInfo, ok := c.buckets[bucket]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s", storagetypes.ErrNoSuchBucket, bucket)
+	}
+	objectInfo, ok := c.objects[objectKey(bucket, object)]
+	if !ok {
+		return clone(bucketInfo), nil, fmt.Errorf("%w: %s", storagetypes.ErrNoSuchObject, object)
+	}
+	return clone(bucketInfo), clone(objectInfo), nil
+}
+
+// QueryPaymentStreamRecord returns an active stream record of the account.
+func (c *Chain) QueryPaymentStreamRecord(ctx context.Context, account string) (*paymenttypes.StreamRecord, error) {
+	if err := c.fault("QueryPaymentStreamRecord"); err != nil {
+		return nil, err
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if !c.accounts[strings.ToLower(account)] {
+		return nil, fmt.Errorf("stream record not found: %s", account)
+	}
+	return &paymenttypes.StreamRecord{
+		Account:           account,
+		CrudTimestamp:     c.blockTime.Unix(),
+		Status:            paymenttypes.STREAM_ACCOUNT_STATUS_ACTIVE,
+		StaticBalance:     sdkmath.ZeroInt(),
+		BufferBalance:     sdkmath.ZeroInt(),
+		LockBalance:       sdkmath.ZeroInt(),
+		NetflowRate:       sdkmath.ZeroInt(),
+		FrozenNetflowRate: sdkmath.ZeroInt(),
+	}, nil
+}
+
+// VerifyGetObjectPermission allows the owner, the granted accounts, and everyone if the object is public read.
+func (c *Chain) VerifyGetObjectPermission(ctx context.Context, account, bucket, object string) (bool, error) {
+	if err := c.fault("VerifyGetObjectPermission"); err != nil {
+		return false, err
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	bucketInfo, objectInfo, err := c.permissionTarget(bucket, object)
+	if err != nil {
+		return false, err
+	}
+	visibility := objectInfo.GetVisibility()
+	if visibility == storagetypes.VISIBILITY_TYPE_INHERIT {
+		visibility = bucketInfo.GetVisibility()
+	}
+	if visibility == storagetypes.VISIBILITY_TYPE_PUBLIC_READ {
+		return true, nil
+	}
+	return c.allowed(account, bucketInfo), nil
+}
+
+// VerifyPutObjectPermission allows the owner and the granted accounts.
+func (c *Chain) VerifyPutObjectPermission(ctx context.Context, account, bucket, object string) (bool, error) {
+	if err := c.fault("VerifyPutObjectPermission"); err != nil {
+		return false, err
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	bucketInfo, _, err := c.permissionTarget(bucket, object)
+	if err != nil {
+		return false, err
+	}
+	return c.allowed(account, bucketInfo), nil
+}
+
+// VerifyUpdateObjectPermission allows the owner and the granted accounts.
+func (c *Chain) VerifyUpdateObjectPermission(ctx context.Context, account, bucket, object string) (bool, error) {
+	if err := c.fault("VerifyUpdateObjectPermission"); err != nil {
+		return false, err
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	bucketInfo, _, err := c.permissionTarget(bucket, object)
+	if err != nil {
+		return false, err
+	}
+	return c.allowed(account, bucketInfo), nil
+}
+
+// ListenObjectSeal returns true if the object is sealed before timeoutHeight blocks are produced.
+func (c *Chain) ListenObjectSeal(ctx context.Context, objectID uint64, timeoutHeight int) (bool, error) {
+	if err := c.fault("ListenObjectSeal"); err != nil {
+		return false, err
+	}
+	sealed := func() bool {
+		c.mux.Lock()
+		defer c.mux.Unlock()
+		info := c.objectByID(objectID)
+		return info != nil && info.GetObjectStatus() == storagetypes.OBJECT_STATUS_SEALED && !info.GetIsUpdating()
+	}
+	for i := 0; i < timeoutHeight; i++ {
+		if sealed() {
+			return true, nil
+		}
+		if err := c.waitBlocks(ctx, 1); err != nil {
+			return false, err
+		}
+	}
+	if sealed() {
+		return true, nil
+	}
+	return false, gnfd.ErrSealTimeout
+}
+
+// ListenRejectUnSealObject returns true if the object is rejected before timeoutHeight blocks are produced.
+func (c *Chain) ListenRejectUnSealObject(ctx context.Context, objectID uint64, timeoutHeight int) (bool, error) {
+	if err := c.fault("ListenRejectUnSealObject"); err != nil {
+		return false, err
+	}
+	rejected := func() bool {
+		c.mux.Lock()
+		defer c.mux.Unlock()
+		return c.objectByID(objectID) == nil
+	}
+	for i := 0; i < timeoutHeight; i++ {
+		if rejected() {
+			return true, nil
+		}
+		if err := c.waitBlocks(ctx, 1); err != nil {
+			return false, err
+		}
+	}
+	if rejected() {
+		return true, nil
+	}
+	return false, gnfd.ErrRejectUnSealTimeout
+}
+
+// ConfirmTransaction waits up to gnfd.ConfirmBlockNumber blocks for the tx to be included in a block, the response
+// of the failed tx has a non-zero code.
+func (c *Chain) ConfirmTransaction(ctx context.Context, txHash string) (*sdk.TxResponse, error) {
+	if err := c.fault("ConfirmTransaction"); err != nil {
+		return nil, err
+	}
+	for i := 0; i < gnfd.ConfirmBlockNumber; i++ {
+		c.mux.Lock()
+		resp, ok := c.txs[txHash]
+		c.mux.Unlock()
+		if ok {
+			return resp, nil
+		}
+		if err := c.WaitForNextBlock(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("failed to confirm transaction, tx_hash=%s", txHash)
+}
+
+// WaitForNextBlock waits for the next block up to gnfd.WaitForNextBlockTimeout.
+func (c *Chain) WaitForNextBlock(ctx context.Context) error {
+	if err := c.fault("WaitForNextBlock"); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, gnfd.WaitForNextBlockTimeout)
+	defer cancel()
+	return c.waitBlocks(ctx, 1)
+}
+
+// QuerySwapInInfo returns the not found error, the simulated chain does not support the swap in.
+func (c *Chain) QuerySwapInInfo(ctx context.Context, familyID, gvgID uint32) (*virtualgrouptypes.SwapInInfo, error) {
+	if err := c.fault("QuerySwapInInfo"); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("swap in info not found, family id: %d, gvg id: %d", familyID, gvgID)
+}
+
+// QueryShadowObjectInfo returns the not found error, the simulated chain does not support the object update.
+func (c *Chain) QueryShadowObjectInfo(ctx context.Context, bucket, object string) (*storagetypes.ShadowObjectInfo, error) {
+	if err := c.fault("QueryShadowObjectInfo"); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("%w: %s", storagetypes.ErrNoSuchObject, object)
+}
+
+// clone returns a shallow copy of the state, so the callers do not race with the txs applied in the blocks.
+func clone[T any](v *T) *T {
+	cp := *v
+	return &cp
+}
+
+// objectByID returns the object by id, it must be called with the lock held.
+func (c *Chain) objectByID(id uint64) *storagetypes.ObjectInfo {
+	for _, info := range c.objects {
+		if info.Id.Uint64() == id {
+			return info
+		}
+	}
+	return nil
+}
+
+// permissionTarget returns the bucket and the object to verify the permission, the object may be nil.
+func (c *Chain) permissionTarget(bucket, object string) (*storagetypes.BucketInfo, *storagetypes.ObjectInfo, error) {
+	bucketInfo, ok := c.buckets[bucket]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s", storagetypes.ErrNoSuchBucket, bucket)
+	}
+	return bucketInfo, c.objects[objectKey(bucket, object)], nil
+}
+
+// allowed returns whether the account is the owner of the bucket or granted by GrantPermission.
+func (c *Chain) allowed(account string, bucketInfo *storagetypes.BucketInfo) bool {
+	return sameAddress(account, bucketInfo.GetOwner()) || c.grants[strings.ToLower(account)][bucketInfo.GetBucketName()]
+}
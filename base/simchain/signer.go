@@ -0,0 +1,146 @@
+package simchain
+
+import (
+	"context"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sptypes "github.com/evmos/evmos/v12/x/sp/types"
+	storagetypes "github.com/evmos/evmos/v12/x/storage/types"
+	virtualgrouptypes "github.com/evmos/evmos/v12/x/virtualgroup/types"
+)
+
+// Signer broadcasts the txs of an SP like the signer module, its methods have the same signatures as the tx
+// methods of module.Signer, so they can back a mocked signer or gfsp client. The messages are signed by the seal,
+// the gc or the operator address of the SP as the signer module does, and wait for no block. It supports the txs
+// of sealing objects, creating objects for the users, discontinuing buckets, migrating buckets, managing the gvgs
+// and updating the storage price.
+type Signer struct {
+	chain *Chain
+	spID  uint32
+}
+
+// Signer returns the signer of the SP.
+func (c *Chain) Signer(spID uint32) *Signer {
+	return &Signer{chain: c, spID: spID}
+}
+
+// broadcast adds the msg signed by the address of the SP returned by addr to the mempool.
+func (s *Signer) broadcast(method string, addr func(operator, seal, gc string) string,
+	newMsg func(signer sdk.AccAddress) sdk.Msg,
+) (string, error) {
+	if err := s.chain.fault(method); err != nil {
+		return "", err
+	}
+	s.chain.mux.Lock()
+	sp, ok := s.chain.sps[s.spID]
+	s.chain.mux.Unlock()
+	if !ok {
+		return "", fmt.Errorf("no such storage provider: %d", s.spID)
+	}
+	signer := addr(sp.OperatorAddress, sp.SealAddress, sp.GcAddress)
+	acc, err := sdk.AccAddressFromHexUnsafe(signer)
+	if err != nil {
+		return "", err
+	}
+	resp := s.chain.checkTx(signer, []sdk.Msg{newMsg(acc)}, nil)
+	if resp.Code != 0 {
+		return "", fmt.Errorf("failed to broadcast tx, resp code: %d, code space: %s, raw log: %s",
+			resp.Code, resp.Codespace, resp.RawLog)
+	}
+	return resp.TxHash, nil
+}
+
+func operatorAddr(operator, _, _ string) string { return operator }
+
+func sealAddr(_, seal, _ string) string { return seal }
+
+func gcAddr(_, _, gc string) string { return gc }
+
+// SealObject broadcasts the MsgSealObject by the seal address.
+func (s *Signer) SealObject(ctx context.Context, object *storagetypes.MsgSealObject) (string, error) {
+	return s.broadcast("SealObject", sealAddr, func(signer sdk.AccAddress) sdk.Msg {
+		return storagetypes.NewMsgSealObject(signer, object.GetBucketName(), object.GetObjectName(),
+			object.GetGlobalVirtualGroupId(), object.GetSecondarySpBlsAggSignatures())
+	})
+}
+
+// SealObjectV2 broadcasts the MsgSealObjectV2 by the seal address.
+func (s *Signer) SealObjectV2(ctx context.Context, object *storagetypes.MsgSealObjectV2) (string, error) {
+	return s.broadcast("SealObjectV2", sealAddr, func(signer sdk.AccAddress) sdk.Msg {
+		return storagetypes.NewMsgSealObjectV2(signer, object.GetBucketName(), object.GetObjectName(),
+			object.GetGlobalVirtualGroupId(), object.GetSecondarySpBlsAggSignatures(), object.GetExpectChecksums())
+	})
+}
+
+// RejectUnSealObject broadcasts the MsgRejectSealObject by the seal address.
+func (s *Signer) RejectUnSealObject(ctx context.Context, object *storagetypes.MsgRejectSealObject) (string, error) {
+	return s.broadcast("RejectUnSealObject", sealAddr, func(signer sdk.AccAddress) sdk.Msg {
+		return storagetypes.NewMsgRejectUnsealedObject(signer, object.GetBucketName(), object.GetObjectName())
+	})
+}
+
+// DiscontinueBucket broadcasts the MsgDiscontinueBucket by the gc address.
+func (s *Signer) DiscontinueBucket(ctx context.Context, bucket *storagetypes.MsgDiscontinueBucket) (string, error) {
+	return s.broadcast("DiscontinueBucket", gcAddr, func(signer sdk.AccAddress) sdk.Msg {
+		return storagetypes.NewMsgDiscontinueBucket(signer, bucket.GetBucketName(), bucket.GetReason())
+	})
+}
+
+// CreateGlobalVirtualGroup broadcasts the MsgCreateGlobalVirtualGroup by the operator address.
+func (s *Signer) CreateGlobalVirtualGroup(ctx context.Context, gvg *virtualgrouptypes.MsgCreateGlobalVirtualGroup) (string, error) {
+	return s.broadcast("CreateGlobalVirtualGroup", operatorAddr, func(signer sdk.AccAddress) sdk.Msg {
+		return virtualgrouptypes.NewMsgCreateGlobalVirtualGroup(signer, gvg.GetFamilyId(), gvg.GetSecondarySpIds(),
+			gvg.GetDeposit())
+	})
+}
+
+// CompleteMigrateBucket broadcasts the MsgCompleteMigrateBucket by the operator address.
+func (s *Signer) CompleteMigrateBucket(ctx context.Context, migrateBucket *storagetypes.MsgCompleteMigrateBucket) (string, error) {
+	return s.broadcast("CompleteMigrateBucket", operatorAddr, func(signer sdk.AccAddress) sdk.Msg {
+		return storagetypes.NewMsgCompleteMigrateBucket(signer, migrateBucket.GetBucketName(),
+			migrateBucket.GetGlobalVirtualGroupFamilyId(), migrateBucket.GetGvgMappings())
+	})
+}
+
+// RejectMigrateBucket broadcasts the MsgRejectMigrateBucket by the operator address.
+func (s *Signer) RejectMigrateBucket(ctx context.Context, rejectMigrateBucket *storagetypes.MsgRejectMigrateBucket) (string, error) {
+	return s.broadcast("RejectMigrateBucket", operatorAddr, func(signer sdk.AccAddress) sdk.Msg {
+		return storagetypes.NewMsgRejectMigrateBucket(signer, rejectMigrateBucket.GetBucketName())
+	})
+}
+
+// DelegateCreateObject broadcasts the MsgDelegateCreateObject by the operator address.
+func (s *Signer) DelegateCreateObject(ctx context.Context, msg *storagetypes.MsgDelegateCreateObject) (string, error) {
+	return s.broadcast("DelegateCreateObject", operatorAddr, func(signer sdk.AccAddress) sdk.Msg {
+		delegate := *msg
+		delegate.Operator = signer.String()
+		return &delegate
+	})
+}
+
+// Deposit broadcasts the MsgDeposit by the operator address.
+func (s *Signer) Deposit(ctx context.Context, deposit *virtualgrouptypes.MsgDeposit) (string, error) {
+	return s.broadcast("Deposit", operatorAddr, func(signer sdk.AccAddress) sdk.Msg {
+		return virtualgrouptypes.NewMsgDeposit(signer, deposit.GetGlobalVirtualGroupId(), deposit.GetDeposit())
+	})
+}
+
+// DeleteGlobalVirtualGroup broadcasts the MsgDeleteGlobalVirtualGroup by the operator address.
+func (s *Signer) DeleteGlobalVirtualGroup(ctx context.Context, deleteGVG *virtualgrouptypes.MsgDeleteGlobalVirtualGroup) (string, error) {
+	return s.broadcast("DeleteGlobalVirtualGroup", operatorAddr, func(signer sdk.AccAddress) sdk.Msg {
+		return virtualgrouptypes.NewMsgDeleteGlobalVirtualGroup(signer, deleteGVG.GetGlobalVirtualGroupId())
+	})
+}
+
+// UpdateSPPrice broadcasts the MsgUpdateSpStoragePrice by the operator address.
+func (s *Signer) UpdateSPPrice(ctx context.Context, price *sptypes.MsgUpdateSpStoragePrice) (string, error) {
+	return s.broadcast("UpdateSPPrice", operatorAddr, func(signer sdk.AccAddress) sdk.Msg {
+		return &sptypes.MsgUpdateSpStoragePrice{
+			SpAddress:     signer.String(),
+			ReadPrice:     price.ReadPrice,
+			FreeReadQuota: price.FreeReadQuota,
+			StorePrice:    price.StorePrice,
+		}
+	})
+}
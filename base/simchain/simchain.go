@@ -0,0 +1,493 @@
+// Package simchain implements an in-process simulated mechain for the tests. It keeps the SPs, the virtual groups,
+// the buckets, the objects and the permissions in memory, implements consensus.Consensus to query them, and accepts
+// the txs of the signer, which are applied when the blocks are produced. The seals are verified against the bls
+// signatures of the secondary SPs like the chain. The faults can be injected into the methods to test the error
+// handling without a mechain devnet.
+package simchain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	sdkmath "cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sptypes "github.com/evmos/evmos/v12/x/sp/types"
+	storagetypes "github.com/evmos/evmos/v12/x/storage/types"
+	virtualgrouptypes "github.com/evmos/evmos/v12/x/virtualgroup/types"
+)
+
+const (
+	// DefaultChainID defines the default chain id of the simulated chain.
+	DefaultChainID = "mechain_5151-1"
+	// DefaultMaxSegmentSize defines the default max segment size of the storage params.
+	DefaultMaxSegmentSize = 16 * 1024 * 1024
+	// DefaultRedundantDataChunkNum defines the default number of the data chunks of the storage params.
+	DefaultRedundantDataChunkNum = 4
+	// DefaultRedundantParityChunkNum defines the default number of the parity chunks of the storage params.
+	DefaultRedundantParityChunkNum = 2
+	// DefaultMaxPayloadSize defines the default max payload size of the storage params.
+	DefaultMaxPayloadSize = 64 * 1024 * 1024 * 1024
+
+	familyAddressPrefix = 0xf1
+	gvgAddressPrefix    = 0xf2
+)
+
+// Config defines the config of the simulated chain.
+type Config struct {
+	// ChainID is the chain id, default is DefaultChainID.
+	ChainID string
+	// BlockInterval is the interval of producing the blocks after Start, the blocks are only produced by NextBlock
+	// if the chain is not started.
+	BlockInterval time.Duration
+	// StorageParams is the storage params, the default params are used if it is nil.
+	StorageParams *storagetypes.Params
+	// VirtualGroupParams is the virtual group params, the default params are used if it is nil.
+	VirtualGroupParams *virtualgrouptypes.Params
+}
+
+// Fault defines the fault injected into a method of the simulated chain.
+type Fault struct {
+	// Err is returned by the method if it is not nil.
+	Err error
+	// Delay is the time the method sleeps before it returns.
+	Delay time.Duration
+	// Times is the number of the calls the fault applies to, zero applies to all the calls until it is cleared.
+	Times int
+}
+
+// Chain is the in-process simulated mechain, it is safe for the concurrent use.
+type Chain struct {
+	mux       sync.Mutex
+	chainID   string
+	interval  time.Duration
+	height    uint64
+	blockTime time.Time
+	newBlock  chan struct{}
+
+	storageParams *storagetypes.Params
+	vgParams      *virtualgrouptypes.Params
+	sps           map[uint32]*sptypes.StorageProvider
+	spPrices      map[uint32]sptypes.SpStoragePrice
+	families      map[uint32]*virtualgrouptypes.GlobalVirtualGroupFamily
+	gvgs          map[uint32]*virtualgrouptypes.GlobalVirtualGroup
+	buckets       map[string]*storagetypes.BucketInfo
+	objects       map[string]*storagetypes.ObjectInfo
+	objectGVGs    map[uint64]uint32
+	migrations    map[string]uint32
+	grants        map[string]map[string]bool
+	accounts      map[string]bool
+
+	sequences map[string]uint64
+	mempool   []*pendingTx
+	txs       map[string]*sdk.TxResponse
+	txCount   uint64
+	faults    map[string]*Fault
+
+	nextSPID     uint32
+	nextFamilyID uint32
+	nextGVGID    uint32
+	nextBucketID uint64
+	nextObjectID uint64
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stopCh    chan struct{}
+	doneCh    chan struct{}
+}
+
+// New returns a simulated chain at height 1 without any state.
+func New(cfg *Config) *Chain {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	c := &Chain{
+		chainID:       cfg.ChainID,
+		interval:      cfg.BlockInterval,
+		height:        1,
+		blockTime:     time.Now(),
+		newBlock:      make(chan struct{}),
+		storageParams: cfg.StorageParams,
+		vgParams:      cfg.VirtualGroupParams,
+		sps:           make(map[uint32]*sptypes.StorageProvider),
+		spPrices:      make(map[uint32]sptypes.SpStoragePrice),
+		families:      make(map[uint32]*virtualgrouptypes.GlobalVirtualGroupFamily),
+		gvgs:          make(map[uint32]*virtualgrouptypes.GlobalVirtualGroup),
+		buckets:       make(map[string]*storagetypes.BucketInfo),
+		objects:       make(map[string]*storagetypes.ObjectInfo),
+		objectGVGs:    make(map[uint64]uint32),
+		migrations:    make(map[string]uint32),
+		grants:        make(map[string]map[string]bool),
+		accounts:      make(map[string]bool),
+		sequences:     make(map[string]uint64),
+		txs:           make(map[string]*sdk.TxResponse),
+		faults:        make(map[string]*Fault),
+		stopCh:        make(chan struct{}),
+		doneCh:        make(chan struct{}),
+	}
+	if c.chainID == "" {
+		c.chainID = DefaultChainID
+	}
+	if c.storageParams == nil {
+		c.storageParams = &storagetypes.Params{
+			VersionedParams: storagetypes.VersionedParams{
+				MaxSegmentSize:          DefaultMaxSegmentSize,
+				RedundantDataChunkNum:   DefaultRedundantDataChunkNum,
+				RedundantParityChunkNum: DefaultRedundantParityChunkNum,
+			},
+			MaxPayloadSize: DefaultMaxPayloadSize,
+		}
+	}
+	if c.vgParams == nil {
+		c.vgParams = &virtualgrouptypes.Params{
+			DepositDenom:       "azkme",
+			GvgStakingPerBytes: sdkmath.NewInt(1),
+		}
+	}
+	return c
+}
+
+// ChainID returns the chain id of the simulated chain.
+func (c *Chain) ChainID() string {
+	return c.chainID
+}
+
+// Start produces a block every BlockInterval until the chain is closed, it does nothing if the interval is zero.
+func (c *Chain) Start() {
+	if c.interval <= 0 {
+		return
+	}
+	c.startOnce.Do(func() {
+		go func() {
+			defer close(c.doneCh)
+			ticker := time.NewTicker(c.interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-c.stopCh:
+					return
+				case <-ticker.C:
+					c.NextBlock()
+				}
+			}
+		}()
+	})
+}
+
+// Height returns the latest block height.
+func (c *Chain) Height() uint64 {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.height
+}
+
+// NextBlock produces a block which includes the txs in the mempool, and returns its height.
+func (c *Chain) NextBlock() uint64 {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.height++
+	c.blockTime = time.Now()
+	for _, ptx := range c.mempool {
+		c.deliverTx(ptx)
+	}
+	c.mempool = nil
+	close(c.newBlock)
+	c.newBlock = make(chan struct{})
+	return c.height
+}
+
+// waitBlocks waits until the height reaches the target, or returns the error if the ctx is done.
+func (c *Chain) waitBlocks(ctx context.Context, blocks uint64) error {
+	c.mux.Lock()
+	target := c.height + blocks
+	c.mux.Unlock()
+	for {
+		c.mux.Lock()
+		height, newBlock := c.height, c.newBlock
+		c.mux.Unlock()
+		if height >= target {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-newBlock:
+		}
+	}
+}
+
+// InjectFault injects the fault into the method, e.g. "QueryObjectInfo", "BroadcastTx" or "SealObject".
+func (c *Chain) InjectFault(method string, fault Fault) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	f := fault
+	c.faults[method] = &f
+}
+
+// ClearFault clears the fault of the method.
+func (c *Chain) ClearFault(method string) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	delete(c.faults, method)
+}
+
+// fault applies the fault injected into the method, it must be called without holding the lock.
+func (c *Chain) fault(method string) error {
+	c.mux.Lock()
+	f, ok := c.faults[method]
+	if !ok {
+		c.mux.Unlock()
+		return nil
+	}
+	if f.Times > 0 {
+		f.Times--
+		if f.Times == 0 {
+			delete(c.faults, method)
+		}
+	}
+	delay, err := f.Delay, f.Err
+	c.mux.Unlock()
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+	return err
+}
+
+// AddSP adds the SP in service, the id is assigned if it is zero. The SP addresses are added as the accounts.
+func (c *Chain) AddSP(sp *sptypes.StorageProvider) uint32 {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if sp.Id == 0 {
+		c.nextSPID++
+		sp.Id = c.nextSPID
+	} else if sp.Id > c.nextSPID {
+		c.nextSPID = sp.Id
+	}
+	if sp.Status == 0 {
+		sp.Status = sptypes.STATUS_IN_SERVICE
+	}
+	c.sps[sp.Id] = sp
+	for _, addr := range []string{sp.OperatorAddress, sp.FundingAddress, sp.SealAddress, sp.ApprovalAddress,
+		sp.GcAddress, sp.MaintenanceAddress} {
+		if addr != "" {
+			c.accounts[strings.ToLower(addr)] = true
+		}
+	}
+	return sp.Id
+}
+
+// SetSPPrice sets the storage price of the SP.
+func (c *Chain) SetSPPrice(price sptypes.SpStoragePrice) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.spPrices[price.SpId] = price
+}
+
+// AddAccount adds the account, e.g. the owner of the buckets.
+func (c *Chain) AddAccount(account string) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.accounts[strings.ToLower(account)] = true
+}
+
+// AddGlobalVirtualGroup adds a gvg to the family of the primary SP, a new family is created if familyID is zero.
+func (c *Chain) AddGlobalVirtualGroup(primarySPID, familyID uint32, secondarySPIDs []uint32) (uint32, uint32, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	gvg, err := c.createGlobalVirtualGroup(primarySPID, familyID, secondarySPIDs)
+	if err != nil {
+		return 0, 0, err
+	}
+	return gvg.FamilyId, gvg.Id, nil
+}
+
+func (c *Chain) createGlobalVirtualGroup(primarySPID, familyID uint32, secondarySPIDs []uint32) (
+	*virtualgrouptypes.GlobalVirtualGroup, error,
+) {
+	if _, ok := c.sps[primarySPID]; !ok {
+		return nil, fmt.Errorf("no such storage provider: %d", primarySPID)
+	}
+	for _, spID := range secondarySPIDs {
+		if _, ok := c.sps[spID]; !ok || spID == primarySPID {
+			return nil, fmt.Errorf("invalid secondary storage provider: %d", spID)
+		}
+	}
+	family, ok := c.families[familyID]
+	switch {
+	case familyID == 0:
+		c.nextFamilyID++
+		family = &virtualgrouptypes.GlobalVirtualGroupFamily{
+			Id:                    c.nextFamilyID,
+			PrimarySpId:           primarySPID,
+			VirtualPaymentAddress: paymentAddress(familyAddressPrefix, uint64(c.nextFamilyID)),
+		}
+		c.families[family.Id] = family
+	case !ok:
+		return nil, fmt.Errorf("no such global virtual group family: %d", familyID)
+	case family.PrimarySpId != primarySPID:
+		return nil, fmt.Errorf("global virtual group family %d does not belong to storage provider %d", familyID, primarySPID)
+	}
+	c.nextGVGID++
+	gvg := &virtualgrouptypes.GlobalVirtualGroup{
+		Id:                    c.nextGVGID,
+		FamilyId:              family.Id,
+		PrimarySpId:           primarySPID,
+		SecondarySpIds:        append([]uint32{}, secondarySPIDs...),
+		VirtualPaymentAddress: paymentAddress(gvgAddressPrefix, uint64(c.nextGVGID)),
+		TotalDeposit:          sdkmath.ZeroInt(),
+	}
+	c.gvgs[gvg.Id] = gvg
+	family.GlobalVirtualGroupIds = append(family.GlobalVirtualGroupIds, gvg.Id)
+	return gvg, nil
+}
+
+// CreateBucket creates the bucket in the family, the owner is added as an account.
+func (c *Chain) CreateBucket(owner, bucket string, familyID uint32, visibility storagetypes.VisibilityType) (
+	*storagetypes.BucketInfo, error,
+) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if _, ok := c.buckets[bucket]; ok {
+		return nil, fmt.Errorf("bucket %s already exists", bucket)
+	}
+	if _, ok := c.families[familyID]; !ok {
+		return nil, fmt.Errorf("no such global virtual group family: %d", familyID)
+	}
+	c.nextBucketID++
+	info := &storagetypes.BucketInfo{
+		Owner:                      owner,
+		BucketName:                 bucket,
+		Visibility:                 visibility,
+		Id:                         sdkmath.NewUint(c.nextBucketID),
+		CreateAt:                   c.blockTime.Unix(),
+		PaymentAddress:             owner,
+		GlobalVirtualGroupFamilyId: familyID,
+		BucketStatus:               storagetypes.BUCKET_STATUS_CREATED,
+	}
+	c.buckets[bucket] = info
+	c.accounts[strings.ToLower(owner)] = true
+	return clone(info), nil
+}
+
+// CreateObject creates the object in the bucket by the bucket owner, it is sealed by the txs of the SP.
+func (c *Chain) CreateObject(bucket, object string, payloadSize uint64, checksums [][]byte) (*storagetypes.ObjectInfo, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	bucketInfo, err := c.checkCreateObject(bucket, object, payloadSize)
+	if err != nil {
+		return nil, err
+	}
+	info := c.createObject(bucketInfo, bucketInfo.Owner, object, payloadSize, storagetypes.VISIBILITY_TYPE_INHERIT, checksums)
+	return clone(info), nil
+}
+
+// checkCreateObject returns the bucket the object can be created in.
+func (c *Chain) checkCreateObject(bucket, object string, payloadSize uint64) (*storagetypes.BucketInfo, error) {
+	bucketInfo, ok := c.buckets[bucket]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", storagetypes.ErrNoSuchBucket, bucket)
+	}
+	if bucketInfo.BucketStatus != storagetypes.BUCKET_STATUS_CREATED {
+		return nil, fmt.Errorf("bucket %s is %s", bucket, bucketInfo.BucketStatus)
+	}
+	if _, ok = c.objects[objectKey(bucket, object)]; ok {
+		return nil, fmt.Errorf("object %s already exists", object)
+	}
+	if payloadSize > c.storageParams.MaxPayloadSize {
+		return nil, fmt.Errorf("payload size %d exceeds the max payload size", payloadSize)
+	}
+	return bucketInfo, nil
+}
+
+func (c *Chain) createObject(bucketInfo *storagetypes.BucketInfo, creator, object string, payloadSize uint64,
+	visibility storagetypes.VisibilityType, checksums [][]byte,
+) *storagetypes.ObjectInfo {
+	c.nextObjectID++
+	info := &storagetypes.ObjectInfo{
+		Owner:          bucketInfo.Owner,
+		Creator:        creator,
+		BucketName:     bucketInfo.BucketName,
+		ObjectName:     object,
+		Id:             sdkmath.NewUint(c.nextObjectID),
+		PayloadSize:    payloadSize,
+		Visibility:     visibility,
+		CreateAt:       c.blockTime.Unix(),
+		ObjectStatus:   storagetypes.OBJECT_STATUS_CREATED,
+		RedundancyType: storagetypes.REDUNDANCY_EC_TYPE,
+		Checksums:      checksums,
+	}
+	c.objects[objectKey(bucketInfo.BucketName, object)] = info
+	return info
+}
+
+// DeleteObject deletes the object.
+func (c *Chain) DeleteObject(bucket, object string) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	info, ok := c.objects[objectKey(bucket, object)]
+	if !ok {
+		return fmt.Errorf("%w: %s", storagetypes.ErrNoSuchObject, object)
+	}
+	delete(c.objects, objectKey(bucket, object))
+	delete(c.objectGVGs, info.Id.Uint64())
+	return nil
+}
+
+// MigrateBucket starts migrating the bucket to the dst SP, it is completed or rejected by the txs of the dst SP.
+func (c *Chain) MigrateBucket(bucket string, dstSPID uint32) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	info, ok := c.buckets[bucket]
+	if !ok {
+		return fmt.Errorf("%w: %s", storagetypes.ErrNoSuchBucket, bucket)
+	}
+	if _, ok = c.sps[dstSPID]; !ok {
+		return fmt.Errorf("no such storage provider: %d", dstSPID)
+	}
+	if info.BucketStatus != storagetypes.BUCKET_STATUS_CREATED {
+		return fmt.Errorf("bucket %s is %s", bucket, info.BucketStatus)
+	}
+	info.BucketStatus = storagetypes.BUCKET_STATUS_MIGRATING
+	c.migrations[bucket] = dstSPID
+	return nil
+}
+
+// GrantPermission grants the account to get, put and update the objects of the bucket.
+func (c *Chain) GrantPermission(account, bucket string) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if c.grants[strings.ToLower(account)] == nil {
+		c.grants[strings.ToLower(account)] = make(map[string]bool)
+	}
+	c.grants[strings.ToLower(account)][bucket] = true
+	c.accounts[strings.ToLower(account)] = true
+}
+
+// ObjectGlobalVirtualGroup returns the id of the gvg the object is sealed in.
+func (c *Chain) ObjectGlobalVirtualGroup(objectID uint64) (uint32, bool) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	gvgID, ok := c.objectGVGs[objectID]
+	return gvgID, ok
+}
+
+// Close stops producing the blocks.
+func (c *Chain) Close() error {
+	c.stopOnce.Do(func() {
+		close(c.stopCh)
+		c.startOnce.Do(func() { close(c.doneCh) })
+	})
+	<-c.doneCh
+	return nil
+}
+
+func objectKey(bucket, object string) string {
+	return bucket + "/" + object
+}
+
+// paymentAddress returns a deterministic hex address of the virtual payment account.
+func paymentAddress(prefix byte, id uint64) string {
+	return fmt.Sprintf("0x%02x%038x", prefix, id)
+}
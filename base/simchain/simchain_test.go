@@ -0,0 +1,613 @@
+package simchain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	sdkmath "cosmossdk.io/math"
+	"github.com/0xPolygon/polygon-edge/bls"
+	"github.com/cometbft/cometbft/votepool"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	ctypes "github.com/evmos/evmos/v12/sdk/types"
+	sptypes "github.com/evmos/evmos/v12/x/sp/types"
+	storagetypes "github.com/evmos/evmos/v12/x/storage/types"
+	virtualgrouptypes "github.com/evmos/evmos/v12/x/virtualgroup/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zkMeLabs/mechain-storage-provider/base/gnfd"
+)
+
+const (
+	mockOwner   = "0x00000000000000000000000000000000000000a1"
+	mockGrantee = "0x00000000000000000000000000000000000000a2"
+	mockOther   = "0x00000000000000000000000000000000000000a3"
+)
+
+// mockAddress returns the address of the kind of address of the SP.
+func mockAddress(spIndex, kind int) string {
+	return fmt.Sprintf("0x%038x%02x", spIndex, kind)
+}
+
+// mockBlsKeys are the bls keys of the SPs by the SP index.
+var mockBlsKeys = func() map[uint32]*bls.PrivateKey {
+	keys := make(map[uint32]*bls.PrivateKey)
+	for i := uint32(1); i <= 7; i++ {
+		key, err := bls.GenerateBlsKey()
+		if err != nil {
+			panic(err)
+		}
+		keys[i] = key
+	}
+	return keys
+}()
+
+// mockSealSignature returns the aggregated bls signature of the secondary SPs of the gvg over the seal of the object.
+func mockSealSignature(t *testing.T, c *Chain, gvgID uint32, objectID sdkmath.Uint, checksums [][]byte) []byte {
+	gvg, err := c.QueryGlobalVirtualGroup(context.Background(), gvgID)
+	assert.Nil(t, err)
+	signDoc := storagetypes.NewSecondarySpSealObjectSignDoc(c.ChainID(), gvgID, objectID,
+		storagetypes.GenerateHash(checksums)).GetBlsSignHash()
+	signatures := make(bls.Signatures, 0, len(gvg.GetSecondarySpIds()))
+	for _, spID := range gvg.GetSecondarySpIds() {
+		signature, err := mockBlsKeys[spID].Sign(signDoc[:], votepool.DST)
+		assert.Nil(t, err)
+		signatures = append(signatures, signature)
+	}
+	aggSignature, err := signatures.Aggregate().Marshal()
+	assert.Nil(t, err)
+	return aggSignature
+}
+
+// setupChain returns a chain with 7 SPs, a gvg of SP 1 and a private bucket "bucket" in its family.
+func setupChain(t *testing.T) (*Chain, uint32, uint32) {
+	c := New(nil)
+	t.Cleanup(func() { _ = c.Close() })
+	for i := 1; i <= 7; i++ {
+		c.AddSP(&sptypes.StorageProvider{
+			OperatorAddress: mockAddress(i, 1),
+			FundingAddress:  mockAddress(i, 2),
+			SealAddress:     mockAddress(i, 3),
+			ApprovalAddress: mockAddress(i, 4),
+			GcAddress:       mockAddress(i, 5),
+			BlsKey:          mockBlsKeys[uint32(i)].PublicKey().Marshal(),
+			Endpoint:        fmt.Sprintf("http://sp%d.local", i),
+		})
+	}
+	familyID, gvgID, err := c.AddGlobalVirtualGroup(1, 0, []uint32{2, 3, 4, 5, 6, 7})
+	assert.Nil(t, err)
+	_, err = c.CreateBucket(mockOwner, "bucket", familyID, storagetypes.VISIBILITY_TYPE_PRIVATE)
+	assert.Nil(t, err)
+	return c, familyID, gvgID
+}
+
+func TestNew(t *testing.T) {
+	c := New(nil)
+	assert.Equal(t, DefaultChainID, c.ChainID())
+	height, err := c.CurrentHeight(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), height)
+	params, err := c.QueryStorageParams(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(DefaultRedundantDataChunkNum), params.GetRedundantDataChunkNum())
+	assert.Equal(t, uint64(2), c.NextBlock())
+	assert.Nil(t, c.Close())
+	assert.Nil(t, c.Close())
+}
+
+func TestChainQueries(t *testing.T) {
+	c, familyID, gvgID := setupChain(t)
+	ctx := context.Background()
+
+	sps, err := c.ListSPs(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 7, len(sps))
+	sp, err := c.QuerySP(ctx, mockAddress(2, 1))
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(2), sp.GetId())
+	_, err = c.QuerySPByID(ctx, 8)
+	assert.NotNil(t, err)
+	_, err = c.QuerySPPrice(ctx, mockAddress(1, 1))
+	assert.NotNil(t, err)
+	c.SetSPPrice(sptypes.SpStoragePrice{SpId: 1, FreeReadQuota: 1024})
+	quota, err := c.QuerySPFreeQuota(ctx, mockAddress(1, 1))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1024), quota)
+
+	families, err := c.ListVirtualGroupFamilies(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(families))
+	gvgs, err := c.ListGlobalVirtualGroupsByFamilyID(ctx, familyID)
+	assert.Nil(t, err)
+	assert.Equal(t, gvgID, gvgs[0].GetId())
+	available, err := c.AvailableGlobalVirtualGroupFamilies(ctx, []uint32{familyID, familyID + 1})
+	assert.Nil(t, err)
+	assert.Equal(t, []uint32{familyID}, available)
+
+	has, err := c.HasAccount(ctx, mockOwner)
+	assert.Nil(t, err)
+	assert.True(t, has)
+	has, _ = c.HasAccount(ctx, mockOther)
+	assert.False(t, has)
+
+	_, err = c.QueryBucketInfo(ctx, "missing")
+	assert.Contains(t, err.Error(), "No such bucket")
+	bucket, _, err := c.QueryBucketInfoAndObjectInfo(ctx, "bucket", "missing")
+	assert.Contains(t, err.Error(), "No such object")
+	assert.Equal(t, "bucket", bucket.GetBucketName())
+	byID, err := c.QueryBucketInfoById(ctx, bucket.Id.Uint64())
+	assert.Nil(t, err)
+	assert.Equal(t, bucket, byID)
+}
+
+func TestUploadSealDownload(t *testing.T) {
+	c, _, gvgID := setupChain(t)
+	ctx := context.Background()
+	checksums := [][]byte{[]byte("checksum")}
+	object, err := c.CreateObject("bucket", "object", 1024, checksums)
+	assert.Nil(t, err)
+
+	allow, err := c.VerifyPutObjectPermission(ctx, mockOwner, "bucket", "object")
+	assert.Nil(t, err)
+	assert.True(t, allow)
+	allow, _ = c.VerifyGetObjectPermission(ctx, mockOther, "bucket", "object")
+	assert.False(t, allow)
+	c.GrantPermission(mockGrantee, "bucket")
+	allow, _ = c.VerifyGetObjectPermission(ctx, mockGrantee, "bucket", "object")
+	assert.True(t, allow)
+
+	// the object is sealed in the next block
+	txHash, err := c.Signer(1).SealObjectV2(ctx, &storagetypes.MsgSealObjectV2{
+		BucketName:                  "bucket",
+		ObjectName:                  "object",
+		GlobalVirtualGroupId:        gvgID,
+		SecondarySpBlsAggSignatures: mockSealSignature(t, c, gvgID, object.Id, checksums),
+		ExpectChecksums:             checksums,
+	})
+	assert.Nil(t, err)
+	info, _ := c.QueryObjectInfo(ctx, "bucket", "object")
+	assert.Equal(t, storagetypes.OBJECT_STATUS_CREATED, info.GetObjectStatus())
+	sealed, err := c.ListenObjectSeal(ctx, object.Id.Uint64(), 0)
+	assert.Equal(t, gnfd.ErrSealTimeout, err)
+	assert.False(t, sealed)
+
+	go c.NextBlock()
+	sealed, err = c.ListenObjectSeal(ctx, object.Id.Uint64(), 3)
+	assert.Nil(t, err)
+	assert.True(t, sealed)
+	resp, err := c.ConfirmTransaction(ctx, txHash)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(0), resp.Code)
+	info, err = c.QueryObjectInfoByID(ctx, strconv.FormatUint(object.Id.Uint64(), 10))
+	assert.Nil(t, err)
+	assert.Equal(t, storagetypes.OBJECT_STATUS_SEALED, info.GetObjectStatus())
+	sealedGVG, ok := c.ObjectGlobalVirtualGroup(object.Id.Uint64())
+	assert.True(t, ok)
+	assert.Equal(t, gvgID, sealedGVG)
+	gvg, _ := c.QueryGlobalVirtualGroup(ctx, gvgID)
+	assert.Equal(t, uint64(1024), gvg.GetStoredSize())
+
+	// sealing the sealed object again fails in the block
+	txHash, err = c.Signer(1).SealObject(ctx, &storagetypes.MsgSealObject{
+		BucketName:           "bucket",
+		ObjectName:           "object",
+		GlobalVirtualGroupId: gvgID,
+	})
+	assert.Nil(t, err)
+	c.NextBlock()
+	resp, err = c.ConfirmTransaction(ctx, txHash)
+	assert.Nil(t, err)
+	assert.NotEqual(t, uint32(0), resp.Code)
+}
+
+func TestSealObjectSignature(t *testing.T) {
+	c, familyID, gvgID := setupChain(t)
+	ctx := context.Background()
+	checksums := [][]byte{[]byte("checksum")}
+	// the gvg of the same secondary SPs in the other order
+	_, otherGVGID, err := c.AddGlobalVirtualGroup(1, familyID, []uint32{7, 6, 5, 4, 3, 2})
+	assert.Nil(t, err)
+
+	cases := []struct {
+		name      string
+		signature func(objectID sdkmath.Uint) []byte
+		wantErr   string
+	}{
+		{
+			name:      "no signature",
+			signature: func(sdkmath.Uint) []byte { return nil },
+			wantErr:   "invalid secondary sp bls signature",
+		},
+		{
+			name: "signature of other checksums",
+			signature: func(objectID sdkmath.Uint) []byte {
+				return mockSealSignature(t, c, gvgID, objectID, [][]byte{[]byte("other")})
+			},
+			wantErr: "failed to verify",
+		},
+		{
+			name: "signature of other gvg",
+			signature: func(objectID sdkmath.Uint) []byte {
+				return mockSealSignature(t, c, otherGVGID, objectID, checksums)
+			},
+			wantErr: "failed to verify",
+		},
+		{
+			name: "signature of the secondary SPs",
+			signature: func(objectID sdkmath.Uint) []byte {
+				return mockSealSignature(t, c, gvgID, objectID, checksums)
+			},
+		},
+	}
+	for i, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			object, err := c.CreateObject("bucket", fmt.Sprintf("object-%d", i), 1024, checksums)
+			assert.Nil(t, err)
+			txHash, err := c.Signer(1).SealObject(ctx, &storagetypes.MsgSealObject{
+				BucketName:                  "bucket",
+				ObjectName:                  object.GetObjectName(),
+				GlobalVirtualGroupId:        gvgID,
+				SecondarySpBlsAggSignatures: tt.signature(object.Id),
+			})
+			assert.Nil(t, err)
+			c.NextBlock()
+			resp, err := c.ConfirmTransaction(ctx, txHash)
+			assert.Nil(t, err)
+			if tt.wantErr != "" {
+				assert.Contains(t, resp.RawLog, tt.wantErr)
+				return
+			}
+			assert.Equal(t, uint32(0), resp.Code)
+		})
+	}
+}
+
+func TestDelegateCreateObject(t *testing.T) {
+	c, _, gvgID := setupChain(t)
+	ctx := context.Background()
+	msg := &storagetypes.MsgDelegateCreateObject{
+		Creator:     mockGrantee,
+		BucketName:  "bucket",
+		ObjectName:  "object",
+		PayloadSize: 1024,
+		Visibility:  storagetypes.VISIBILITY_TYPE_PRIVATE,
+	}
+
+	// the creator needs the permission of the bucket
+	txHash, err := c.Signer(1).DelegateCreateObject(ctx, msg)
+	assert.Nil(t, err)
+	c.NextBlock()
+	resp, err := c.ConfirmTransaction(ctx, txHash)
+	assert.Nil(t, err)
+	assert.Contains(t, resp.RawLog, "no permission")
+
+	c.GrantPermission(mockGrantee, "bucket")
+	_, err = c.Signer(1).DelegateCreateObject(ctx, msg)
+	assert.Nil(t, err)
+	c.NextBlock()
+	object, err := c.QueryObjectInfo(ctx, "bucket", "object")
+	assert.Nil(t, err)
+	assert.Equal(t, mockOwner, object.GetOwner())
+	assert.Equal(t, mockGrantee, object.GetCreator())
+	assert.Equal(t, 0, len(object.GetChecksums()))
+
+	// the object created without checksums is sealed with the expected checksums
+	checksums := [][]byte{[]byte("checksum")}
+	_, err = c.Signer(1).SealObjectV2(ctx, &storagetypes.MsgSealObjectV2{
+		BucketName:                  "bucket",
+		ObjectName:                  "object",
+		GlobalVirtualGroupId:        gvgID,
+		SecondarySpBlsAggSignatures: mockSealSignature(t, c, gvgID, object.Id, checksums),
+		ExpectChecksums:             checksums,
+	})
+	assert.Nil(t, err)
+	c.NextBlock()
+	object, _ = c.QueryObjectInfo(ctx, "bucket", "object")
+	assert.Equal(t, storagetypes.OBJECT_STATUS_SEALED, object.GetObjectStatus())
+	assert.Equal(t, checksums, object.GetChecksums())
+}
+
+func TestGlobalVirtualGroupTxs(t *testing.T) {
+	c, familyID, gvgID := setupChain(t)
+	ctx := context.Background()
+	_, emptyGVGID, err := c.AddGlobalVirtualGroup(1, familyID, []uint32{2, 3, 4, 5, 6, 7})
+	assert.Nil(t, err)
+	object, err := c.CreateObject("bucket", "object", 1024, nil)
+	assert.Nil(t, err)
+	_, err = c.Signer(1).SealObject(ctx, &storagetypes.MsgSealObject{
+		BucketName:                  "bucket",
+		ObjectName:                  "object",
+		GlobalVirtualGroupId:        gvgID,
+		SecondarySpBlsAggSignatures: mockSealSignature(t, c, gvgID, object.Id, nil),
+	})
+	assert.Nil(t, err)
+	c.NextBlock()
+
+	cases := []struct {
+		name    string
+		signer  uint32
+		tx      func(s *Signer) (string, error)
+		wantErr string
+	}{
+		{
+			name:   "deposit by the secondary SP",
+			signer: 2,
+			tx: func(s *Signer) (string, error) {
+				return s.Deposit(ctx, &virtualgrouptypes.MsgDeposit{GlobalVirtualGroupId: gvgID,
+					Deposit: sdk.NewCoin("azkme", sdkmath.NewInt(100))})
+			},
+			wantErr: "is not the operator address of the primary storage provider",
+		},
+		{
+			name:   "deposit of other denom",
+			signer: 1,
+			tx: func(s *Signer) (string, error) {
+				return s.Deposit(ctx, &virtualgrouptypes.MsgDeposit{GlobalVirtualGroupId: gvgID,
+					Deposit: sdk.NewCoin("other", sdkmath.NewInt(100))})
+			},
+			wantErr: "invalid deposit",
+		},
+		{
+			name:   "deposit",
+			signer: 1,
+			tx: func(s *Signer) (string, error) {
+				return s.Deposit(ctx, &virtualgrouptypes.MsgDeposit{GlobalVirtualGroupId: gvgID,
+					Deposit: sdk.NewCoin("azkme", sdkmath.NewInt(100))})
+			},
+		},
+		{
+			name:   "delete the gvg storing the object",
+			signer: 1,
+			tx: func(s *Signer) (string, error) {
+				return s.DeleteGlobalVirtualGroup(ctx, &virtualgrouptypes.MsgDeleteGlobalVirtualGroup{GlobalVirtualGroupId: gvgID})
+			},
+			wantErr: "is not empty",
+		},
+		{
+			name:   "delete the empty gvg",
+			signer: 1,
+			tx: func(s *Signer) (string, error) {
+				return s.DeleteGlobalVirtualGroup(ctx, &virtualgrouptypes.MsgDeleteGlobalVirtualGroup{GlobalVirtualGroupId: emptyGVGID})
+			},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			txHash, err := tt.tx(c.Signer(tt.signer))
+			assert.Nil(t, err)
+			c.NextBlock()
+			resp, err := c.ConfirmTransaction(ctx, txHash)
+			assert.Nil(t, err)
+			if tt.wantErr != "" {
+				assert.Contains(t, resp.RawLog, tt.wantErr)
+				return
+			}
+			assert.Equal(t, uint32(0), resp.Code)
+		})
+	}
+	gvg, err := c.QueryGlobalVirtualGroup(ctx, gvgID)
+	assert.Nil(t, err)
+	assert.True(t, sdkmath.NewInt(100).Equal(gvg.GetTotalDeposit()))
+	_, err = c.QueryGlobalVirtualGroup(ctx, emptyGVGID)
+	assert.NotNil(t, err)
+	gvgs, err := c.ListGlobalVirtualGroupsByFamilyID(ctx, familyID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(gvgs))
+}
+
+func TestUpdateSPPrice(t *testing.T) {
+	c, _, _ := setupChain(t)
+	ctx := context.Background()
+	_, err := c.Signer(1).UpdateSPPrice(ctx, &sptypes.MsgUpdateSpStoragePrice{
+		ReadPrice:     sdk.NewDec(1),
+		FreeReadQuota: 1024,
+		StorePrice:    sdk.NewDec(2),
+	})
+	assert.Nil(t, err)
+	c.NextBlock()
+	price, err := c.QuerySPPrice(ctx, mockAddress(1, 1))
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(1), price.GetSpId())
+	assert.Equal(t, uint64(1024), price.GetFreeReadQuota())
+	assert.True(t, sdk.NewDec(2).Equal(price.StorePrice))
+}
+
+func TestRejectUnSealObject(t *testing.T) {
+	c, _, gvgID := setupChain(t)
+	ctx := context.Background()
+	object, err := c.CreateObject("bucket", "object", 1024, nil)
+	assert.Nil(t, err)
+
+	// only the seal address of the primary SP can seal the object
+	txHash, err := c.Signer(2).SealObject(ctx, &storagetypes.MsgSealObject{
+		BucketName:           "bucket",
+		ObjectName:           "object",
+		GlobalVirtualGroupId: gvgID,
+	})
+	assert.Nil(t, err)
+	_, err = c.Signer(1).RejectUnSealObject(ctx, &storagetypes.MsgRejectSealObject{
+		BucketName: "bucket",
+		ObjectName: "object",
+	})
+	assert.Nil(t, err)
+	c.NextBlock()
+	resp, err := c.ConfirmTransaction(ctx, txHash)
+	assert.Nil(t, err)
+	assert.Contains(t, resp.RawLog, "seal address")
+	rejected, err := c.ListenRejectUnSealObject(ctx, object.Id.Uint64(), 1)
+	assert.Nil(t, err)
+	assert.True(t, rejected)
+	_, err = c.QueryObjectInfo(ctx, "bucket", "object")
+	assert.True(t, errors.Is(err, storagetypes.ErrNoSuchObject))
+}
+
+func TestMigrateBucket(t *testing.T) {
+	c, _, srcGVGID := setupChain(t)
+	ctx := context.Background()
+	object, err := c.CreateObject("bucket", "object", 1024, nil)
+	assert.Nil(t, err)
+	_, err = c.Signer(1).SealObject(ctx, &storagetypes.MsgSealObject{
+		BucketName:                  "bucket",
+		ObjectName:                  "object",
+		GlobalVirtualGroupId:        srcGVGID,
+		SecondarySpBlsAggSignatures: mockSealSignature(t, c, srcGVGID, object.Id, nil),
+	})
+	assert.Nil(t, err)
+	c.NextBlock()
+
+	// the dst SP creates a gvg in a new family by the tx
+	_, err = c.Signer(2).CreateGlobalVirtualGroup(ctx, &virtualgrouptypes.MsgCreateGlobalVirtualGroup{
+		SecondarySpIds: []uint32{1, 3, 4, 5, 6, 7},
+	})
+	assert.Nil(t, err)
+	c.NextBlock()
+	families, err := c.ListVirtualGroupFamilies(ctx, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(families))
+	dstFamilyID, dstGVGID := families[0].GetId(), families[0].GetGlobalVirtualGroupIds()[0]
+
+	// the rejected migration restores the bucket
+	assert.Nil(t, c.MigrateBucket("bucket", 2))
+	assert.NotNil(t, c.MigrateBucket("bucket", 2))
+	_, err = c.Signer(2).RejectMigrateBucket(ctx, &storagetypes.MsgRejectMigrateBucket{BucketName: "bucket"})
+	assert.Nil(t, err)
+	c.NextBlock()
+	bucket, _ := c.QueryBucketInfo(ctx, "bucket")
+	assert.Equal(t, storagetypes.BUCKET_STATUS_CREATED, bucket.GetBucketStatus())
+
+	assert.Nil(t, c.MigrateBucket("bucket", 2))
+	// the incomplete mappings fail the tx
+	txHash, err := c.Signer(2).CompleteMigrateBucket(ctx, &storagetypes.MsgCompleteMigrateBucket{
+		BucketName:                 "bucket",
+		GlobalVirtualGroupFamilyId: dstFamilyID,
+	})
+	assert.Nil(t, err)
+	c.NextBlock()
+	resp, err := c.ConfirmTransaction(ctx, txHash)
+	assert.Nil(t, err)
+	assert.Contains(t, resp.RawLog, "is not migrated")
+
+	_, err = c.Signer(2).CompleteMigrateBucket(ctx, &storagetypes.MsgCompleteMigrateBucket{
+		BucketName:                 "bucket",
+		GlobalVirtualGroupFamilyId: dstFamilyID,
+		GvgMappings: []*storagetypes.GVGMapping{
+			{SrcGlobalVirtualGroupId: srcGVGID, DstGlobalVirtualGroupId: dstGVGID},
+		},
+	})
+	assert.Nil(t, err)
+	c.NextBlock()
+	bucket, _ = c.QueryBucketInfo(ctx, "bucket")
+	assert.Equal(t, storagetypes.BUCKET_STATUS_CREATED, bucket.GetBucketStatus())
+	assert.Equal(t, dstFamilyID, bucket.GetGlobalVirtualGroupFamilyId())
+	gvgID, _ := c.ObjectGlobalVirtualGroup(object.Id.Uint64())
+	assert.Equal(t, dstGVGID, gvgID)
+	srcGVG, _ := c.QueryGlobalVirtualGroup(ctx, srcGVGID)
+	assert.Equal(t, uint64(0), srcGVG.GetStoredSize())
+}
+
+func TestDiscontinueBucket(t *testing.T) {
+	c, _, _ := setupChain(t)
+	ctx := context.Background()
+	_, err := c.Signer(1).DiscontinueBucket(ctx, &storagetypes.MsgDiscontinueBucket{BucketName: "bucket"})
+	assert.Nil(t, err)
+	c.NextBlock()
+	bucket, _ := c.QueryBucketInfo(ctx, "bucket")
+	assert.Equal(t, storagetypes.BUCKET_STATUS_DISCONTINUED, bucket.GetBucketStatus())
+	_, err = c.CreateObject("bucket", "object", 1, nil)
+	assert.NotNil(t, err)
+}
+
+func TestClientBroadcastTx(t *testing.T) {
+	c, _, _ := setupChain(t)
+	ctx := context.Background()
+	client := c.Client(mockAddress(1, 5))
+	signer, err := sdk.AccAddressFromHexUnsafe(mockAddress(1, 5))
+	assert.Nil(t, err)
+	msgs := []sdk.Msg{storagetypes.NewMsgDiscontinueBucket(signer, "bucket", "test")}
+
+	simulate, err := client.SimulateTx(ctx, msgs, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(simulatedGasPerMsg), simulate.GetGasInfo().GetGasUsed())
+
+	cases := []struct {
+		name     string
+		nonce    uint64
+		wantCode bool
+	}{
+		{name: "next sequence", nonce: 0},
+		{name: "reused sequence", nonce: 0, wantCode: true},
+		{name: "future sequence", nonce: 5, wantCode: true},
+		{name: "sequence after the mempool tx", nonce: 1},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.BroadcastTx(ctx, msgs, &ctypes.TxOption{Nonce: tt.nonce})
+			assert.Nil(t, err)
+			if tt.wantCode {
+				assert.NotEqual(t, uint32(0), resp.GetTxResponse().Code)
+				assert.Contains(t, resp.GetTxResponse().RawLog, "account sequence mismatch")
+			} else {
+				assert.Equal(t, uint32(0), resp.GetTxResponse().Code)
+			}
+		})
+	}
+	nonce, err := client.GetNonce(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), nonce)
+
+	// the msg signed by the other account fails the tx
+	resp, err := c.Client(mockOther).BroadcastTx(ctx, msgs, nil)
+	assert.Nil(t, err)
+	c.NextBlock()
+	txResp, err := c.ConfirmTransaction(ctx, resp.GetTxResponse().TxHash)
+	assert.Nil(t, err)
+	assert.Contains(t, txResp.RawLog, "unauthorized signer")
+}
+
+func TestInjectFault(t *testing.T) {
+	c, _, _ := setupChain(t)
+	ctx := context.Background()
+	mockErr := errors.New("mock error")
+
+	c.InjectFault("QueryBucketInfo", Fault{Err: mockErr, Times: 2})
+	for i := 0; i < 2; i++ {
+		_, err := c.QueryBucketInfo(ctx, "bucket")
+		assert.Equal(t, mockErr, err)
+	}
+	_, err := c.QueryBucketInfo(ctx, "bucket")
+	assert.Nil(t, err)
+
+	c.InjectFault("DiscontinueBucket", Fault{Err: mockErr})
+	for i := 0; i < 3; i++ {
+		_, err = c.Signer(1).DiscontinueBucket(ctx, &storagetypes.MsgDiscontinueBucket{BucketName: "bucket"})
+		assert.Equal(t, mockErr, err)
+	}
+	c.ClearFault("DiscontinueBucket")
+	_, err = c.Signer(1).DiscontinueBucket(ctx, &storagetypes.MsgDiscontinueBucket{BucketName: "bucket"})
+	assert.Nil(t, err)
+
+	c.InjectFault("CurrentHeight", Fault{Delay: 20 * time.Millisecond, Times: 1})
+	start := time.Now()
+	_, err = c.CurrentHeight(ctx)
+	assert.Nil(t, err)
+	assert.True(t, time.Since(start) >= 20*time.Millisecond)
+}
+
+func TestStart(t *testing.T) {
+	c := New(&Config{BlockInterval: 5 * time.Millisecond})
+	c.Start()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.Nil(t, c.WaitForNextBlock(ctx))
+	assert.Nil(t, c.WaitForNextBlock(ctx))
+	assert.True(t, c.Height() >= 3)
+	assert.Nil(t, c.Close())
+
+	// the wait returns the error of the ctx if no block is produced
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, c.WaitForNextBlock(ctx))
+}
@@ -0,0 +1,525 @@
+package simchain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	sdkmath "cosmossdk.io/math"
+	"github.com/0xPolygon/polygon-edge/bls"
+	"github.com/cometbft/cometbft/votepool"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkErrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	ctypes "github.com/evmos/evmos/v12/sdk/types"
+	sptypes "github.com/evmos/evmos/v12/x/sp/types"
+	storagetypes "github.com/evmos/evmos/v12/x/storage/types"
+	virtualgrouptypes "github.com/evmos/evmos/v12/x/virtualgroup/types"
+	"google.golang.org/grpc"
+)
+
+const (
+	// simulatedGasPerMsg defines the gas used by a message in the simulation.
+	simulatedGasPerMsg = 1000
+	// codespace defines the codespace of the failed txs.
+	codespace = "simchain"
+)
+
+// pendingTx is the tx in the mempool which is applied in the next block.
+type pendingTx struct {
+	hash   string
+	signer string
+	msgs   []sdk.Msg
+}
+
+// Client broadcasts the txs of an account, it has the same tx methods as the mechain client used by the signer.
+type Client struct {
+	chain   *Chain
+	address string
+}
+
+// Client returns the tx client of the account.
+func (c *Chain) Client(address string) *Client {
+	return &Client{chain: c, address: address}
+}
+
+// GetNonce returns the sequence of the next tx of the account, including the txs in the mempool.
+func (cl *Client) GetNonce(ctx context.Context) (uint64, error) {
+	if err := cl.chain.fault("GetNonce"); err != nil {
+		return 0, err
+	}
+	cl.chain.mux.Lock()
+	defer cl.chain.mux.Unlock()
+	return cl.chain.sequences[strings.ToLower(cl.address)], nil
+}
+
+// BroadcastTx adds the tx to the mempool if its nonce is the next sequence of the account, the messages are
+// applied in the next block, the failure is reported by the tx response of ConfirmTransaction.
+func (cl *Client) BroadcastTx(ctx context.Context, msgs []sdk.Msg, txOpt *ctypes.TxOption, opts ...grpc.CallOption) (
+	*tx.BroadcastTxResponse, error,
+) {
+	if err := cl.chain.fault("BroadcastTx"); err != nil {
+		return nil, err
+	}
+	var nonce *uint64
+	if txOpt != nil {
+		nonce = &txOpt.Nonce
+	}
+	resp := cl.chain.checkTx(cl.address, msgs, nonce)
+	return &tx.BroadcastTxResponse{TxResponse: resp}, nil
+}
+
+// SimulateTx returns the gas used by the messages.
+func (cl *Client) SimulateTx(ctx context.Context, msgs []sdk.Msg, txOpt *ctypes.TxOption, opts ...grpc.CallOption) (
+	*tx.SimulateResponse, error,
+) {
+	if err := cl.chain.fault("SimulateTx"); err != nil {
+		return nil, err
+	}
+	gas := uint64(len(msgs)) * simulatedGasPerMsg
+	return &tx.SimulateResponse{GasInfo: &sdk.GasInfo{GasWanted: gas, GasUsed: gas}}, nil
+}
+
+// checkTx adds the tx to the mempool, the nonce is the next sequence of the signer if it is nil.
+func (c *Chain) checkTx(signer string, msgs []sdk.Msg, nonce *uint64) *sdk.TxResponse {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	expected := c.sequences[strings.ToLower(signer)]
+	if nonce != nil && *nonce != expected {
+		return &sdk.TxResponse{
+			Code:      sdkErrors.ErrWrongSequence.ABCICode(),
+			Codespace: sdkErrors.ErrWrongSequence.Codespace(),
+			RawLog: fmt.Sprintf("account sequence mismatch, expected %d, got %d: incorrect account sequence",
+				expected, *nonce),
+		}
+	}
+	if len(msgs) == 0 {
+		return &sdk.TxResponse{Code: 1, Codespace: codespace, RawLog: "empty tx"}
+	}
+	c.txCount++
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s/%d/%d", signer, expected, c.txCount)))
+	ptx := &pendingTx{hash: strings.ToUpper(hex.EncodeToString(hash[:])), signer: signer, msgs: msgs}
+	c.sequences[strings.ToLower(signer)] = expected + 1
+	c.accounts[strings.ToLower(signer)] = true
+	c.mempool = append(c.mempool, ptx)
+	return &sdk.TxResponse{TxHash: ptx.hash}
+}
+
+// deliverTx applies the messages of the tx, none of them is applied if any of them fails.
+func (c *Chain) deliverTx(ptx *pendingTx) {
+	resp := &sdk.TxResponse{Height: int64(c.height), TxHash: ptx.hash, GasUsed: int64(len(ptx.msgs)) * simulatedGasPerMsg}
+	c.txs[ptx.hash] = resp
+	msgs, err := c.unwrapMsgs(ptx.signer, ptx.msgs)
+	if err != nil {
+		resp.Code, resp.Codespace, resp.RawLog = 1, codespace, err.Error()
+		return
+	}
+	applies := make([]func(), 0, len(msgs))
+	for _, msg := range msgs {
+		apply, err := c.checkMsg(msg)
+		if err != nil {
+			resp.Code, resp.Codespace, resp.RawLog = 1, codespace, err.Error()
+			return
+		}
+		applies = append(applies, apply)
+	}
+	for _, apply := range applies {
+		apply()
+	}
+}
+
+// unwrapMsgs returns the messages of the authz MsgExec, the signers of the messages must be the signer of the tx,
+// or the grantee of the MsgExec which is the signer of the tx.
+func (c *Chain) unwrapMsgs(signer string, msgs []sdk.Msg) ([]sdk.Msg, error) {
+	unwrapped := make([]sdk.Msg, 0, len(msgs))
+	for _, msg := range msgs {
+		if exec, ok := msg.(*authz.MsgExec); ok {
+			if !sameAddress(exec.Grantee, signer) {
+				return nil, fmt.Errorf("grantee %s is not the signer %s", exec.Grantee, signer)
+			}
+			execMsgs, err := exec.GetMessages()
+			if err != nil {
+				return nil, err
+			}
+			unwrapped = append(unwrapped, execMsgs...)
+			continue
+		}
+		if signers := msg.GetSigners(); len(signers) == 0 || !sameAddress(signers[0].String(), signer) {
+			return nil, fmt.Errorf("unauthorized signer %s of %s", signer, sdk.MsgTypeURL(msg))
+		}
+		unwrapped = append(unwrapped, msg)
+	}
+	return unwrapped, nil
+}
+
+// checkMsg checks the message against the state, and returns the func to apply it.
+func (c *Chain) checkMsg(msg sdk.Msg) (func(), error) {
+	operator := ""
+	if signers := msg.GetSigners(); len(signers) != 0 {
+		operator = signers[0].String()
+	}
+	switch m := msg.(type) {
+	case *storagetypes.MsgSealObject:
+		return c.checkSealObject(operator, m.GetBucketName(), m.GetObjectName(), m.GetGlobalVirtualGroupId(),
+			m.GetSecondarySpBlsAggSignatures(), nil)
+	case *storagetypes.MsgSealObjectV2:
+		return c.checkSealObject(operator, m.GetBucketName(), m.GetObjectName(), m.GetGlobalVirtualGroupId(),
+			m.GetSecondarySpBlsAggSignatures(), m.GetExpectChecksums())
+	case *storagetypes.MsgRejectSealObject:
+		return c.checkRejectSealObject(operator, m.GetBucketName(), m.GetObjectName())
+	case *storagetypes.MsgDiscontinueBucket:
+		return c.checkDiscontinueBucket(operator, m.GetBucketName())
+	case *virtualgrouptypes.MsgCreateGlobalVirtualGroup:
+		return c.checkCreateGlobalVirtualGroup(operator, m.GetFamilyId(), m.GetSecondarySpIds())
+	case *storagetypes.MsgCompleteMigrateBucket:
+		return c.checkCompleteMigrateBucket(operator, m.GetBucketName(), m.GetGlobalVirtualGroupFamilyId(),
+			m.GetGvgMappings())
+	case *storagetypes.MsgRejectMigrateBucket:
+		return c.checkRejectMigrateBucket(operator, m.GetBucketName())
+	case *storagetypes.MsgDelegateCreateObject:
+		return c.checkDelegateCreateObject(operator, m)
+	case *virtualgrouptypes.MsgDeposit:
+		return c.checkDeposit(operator, m.GetGlobalVirtualGroupId(), m.GetDeposit())
+	case *virtualgrouptypes.MsgDeleteGlobalVirtualGroup:
+		return c.checkDeleteGlobalVirtualGroup(operator, m.GetGlobalVirtualGroupId())
+	case *sptypes.MsgUpdateSpStoragePrice:
+		return c.checkUpdateSPPrice(operator, m)
+	default:
+		return nil, fmt.Errorf("unsupported message %s", sdk.MsgTypeURL(msg))
+	}
+}
+
+// primarySP returns the primary SP of the bucket.
+func (c *Chain) primarySP(bucket *storagetypes.BucketInfo) (*sptypes.StorageProvider, error) {
+	family, ok := c.families[bucket.GlobalVirtualGroupFamilyId]
+	if !ok {
+		return nil, fmt.Errorf("no such global virtual group family: %d", bucket.GlobalVirtualGroupFamilyId)
+	}
+	sp, ok := c.sps[family.PrimarySpId]
+	if !ok {
+		return nil, fmt.Errorf("no such storage provider: %d", family.PrimarySpId)
+	}
+	return sp, nil
+}
+
+// checkSealObject checks the seal of the object, the secondary SPs of the gvg must have signed the seal with their bls
+// keys over the checksums, which are the expected checksums of MsgSealObjectV2 if they are set.
+func (c *Chain) checkSealObject(operator, bucket, object string, gvgID uint32, aggSignature []byte,
+	checksums [][]byte,
+) (func(), error) {
+	bucketInfo, ok := c.buckets[bucket]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", storagetypes.ErrNoSuchBucket, bucket)
+	}
+	objectInfo, ok := c.objects[objectKey(bucket, object)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", storagetypes.ErrNoSuchObject, object)
+	}
+	if objectInfo.ObjectStatus != storagetypes.OBJECT_STATUS_CREATED {
+		return nil, fmt.Errorf("object %s is %s", object, objectInfo.ObjectStatus)
+	}
+	sp, err := c.primarySP(bucketInfo)
+	if err != nil {
+		return nil, err
+	}
+	if !sameAddress(operator, sp.SealAddress) {
+		return nil, fmt.Errorf("%s is not the seal address of the primary storage provider", operator)
+	}
+	gvg, ok := c.gvgs[gvgID]
+	if !ok || gvg.FamilyId != bucketInfo.GlobalVirtualGroupFamilyId {
+		return nil, fmt.Errorf("global virtual group %d is not in the family of bucket %s", gvgID, bucket)
+	}
+	if checksums != nil && len(objectInfo.Checksums) != 0 {
+		if len(checksums) != len(objectInfo.Checksums) {
+			return nil, fmt.Errorf("checksums of object %s mismatch", object)
+		}
+		for i := range checksums {
+			if hex.EncodeToString(checksums[i]) != hex.EncodeToString(objectInfo.Checksums[i]) {
+				return nil, fmt.Errorf("checksums of object %s mismatch", object)
+			}
+		}
+	}
+	signedChecksums := objectInfo.Checksums
+	if checksums != nil {
+		signedChecksums = checksums
+	}
+	if err = c.checkSecondarySignature(gvg, objectInfo.Id, signedChecksums, aggSignature); err != nil {
+		return nil, err
+	}
+	return func() {
+		objectInfo.ObjectStatus = storagetypes.OBJECT_STATUS_SEALED
+		if checksums != nil {
+			objectInfo.Checksums = checksums
+		}
+		gvg.StoredSize += objectInfo.PayloadSize
+		c.objectGVGs[objectInfo.Id.Uint64()] = gvgID
+	}, nil
+}
+
+// checkSecondarySignature verifies the aggregated bls signature of the secondary SPs of the gvg over the seal sign doc
+// of the object, as the storage module of mechain does.
+func (c *Chain) checkSecondarySignature(gvg *virtualgrouptypes.GlobalVirtualGroup, objectID sdkmath.Uint,
+	checksums [][]byte, aggSignature []byte,
+) error {
+	publicKeys := make([]*bls.PublicKey, 0, len(gvg.SecondarySpIds))
+	for _, spID := range gvg.SecondarySpIds {
+		sp, ok := c.sps[spID]
+		if !ok {
+			return fmt.Errorf("no such storage provider: %d", spID)
+		}
+		publicKey, err := bls.UnmarshalPublicKey(sp.BlsKey)
+		if err != nil {
+			return fmt.Errorf("invalid bls key of storage provider %d: %w", spID, err)
+		}
+		publicKeys = append(publicKeys, publicKey)
+	}
+	signature, err := bls.UnmarshalSignature(aggSignature)
+	if err != nil {
+		return fmt.Errorf("invalid secondary sp bls signature: %w", err)
+	}
+	signDoc := storagetypes.NewSecondarySpSealObjectSignDoc(c.chainID, gvg.Id, objectID,
+		storagetypes.GenerateHash(checksums)).GetBlsSignHash()
+	if !signature.VerifyAggregated(publicKeys, signDoc[:], votepool.DST) {
+		return fmt.Errorf("failed to verify the secondary sp bls signature of global virtual group %d", gvg.Id)
+	}
+	return nil
+}
+
+func (c *Chain) checkRejectSealObject(operator, bucket, object string) (func(), error) {
+	bucketInfo, ok := c.buckets[bucket]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", storagetypes.ErrNoSuchBucket, bucket)
+	}
+	objectInfo, ok := c.objects[objectKey(bucket, object)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", storagetypes.ErrNoSuchObject, object)
+	}
+	if objectInfo.ObjectStatus != storagetypes.OBJECT_STATUS_CREATED {
+		return nil, fmt.Errorf("object %s is %s", object, objectInfo.ObjectStatus)
+	}
+	sp, err := c.primarySP(bucketInfo)
+	if err != nil {
+		return nil, err
+	}
+	if !sameAddress(operator, sp.SealAddress) {
+		return nil, fmt.Errorf("%s is not the seal address of the primary storage provider", operator)
+	}
+	return func() {
+		delete(c.objects, objectKey(bucket, object))
+	}, nil
+}
+
+func (c *Chain) checkDiscontinueBucket(operator, bucket string) (func(), error) {
+	bucketInfo, ok := c.buckets[bucket]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", storagetypes.ErrNoSuchBucket, bucket)
+	}
+	sp, err := c.primarySP(bucketInfo)
+	if err != nil {
+		return nil, err
+	}
+	if !sameAddress(operator, sp.GcAddress) {
+		return nil, fmt.Errorf("%s is not the gc address of the primary storage provider", operator)
+	}
+	return func() {
+		bucketInfo.BucketStatus = storagetypes.BUCKET_STATUS_DISCONTINUED
+	}, nil
+}
+
+func (c *Chain) checkCreateGlobalVirtualGroup(operator string, familyID uint32, secondarySPIDs []uint32) (func(), error) {
+	sp := c.spByOperator(operator)
+	if sp == nil {
+		return nil, fmt.Errorf("%s is not the operator address of a storage provider", operator)
+	}
+	if family, ok := c.families[familyID]; familyID != 0 && (!ok || family.PrimarySpId != sp.Id) {
+		return nil, fmt.Errorf("global virtual group family %d does not belong to storage provider %d", familyID, sp.Id)
+	}
+	for _, spID := range secondarySPIDs {
+		if _, ok := c.sps[spID]; !ok || spID == sp.Id {
+			return nil, fmt.Errorf("invalid secondary storage provider: %d", spID)
+		}
+	}
+	return func() {
+		// the args are checked above, it can not fail
+		_, _ = c.createGlobalVirtualGroup(sp.Id, familyID, secondarySPIDs)
+	}, nil
+}
+
+func (c *Chain) checkCompleteMigrateBucket(operator, bucket string, familyID uint32,
+	mappings []*storagetypes.GVGMapping,
+) (func(), error) {
+	bucketInfo, dstSP, err := c.checkMigration(operator, bucket)
+	if err != nil {
+		return nil, err
+	}
+	family, ok := c.families[familyID]
+	if !ok || family.PrimarySpId != dstSP.Id {
+		return nil, fmt.Errorf("global virtual group family %d does not belong to storage provider %d", familyID, dstSP.Id)
+	}
+	dstGVGs := make(map[uint32]uint32, len(mappings))
+	for _, mapping := range mappings {
+		dst, ok := c.gvgs[mapping.GetDstGlobalVirtualGroupId()]
+		if !ok || dst.FamilyId != familyID {
+			return nil, fmt.Errorf("global virtual group %d is not in family %d", mapping.GetDstGlobalVirtualGroupId(), familyID)
+		}
+		dstGVGs[mapping.GetSrcGlobalVirtualGroupId()] = dst.Id
+	}
+	for _, objectInfo := range c.objects {
+		if objectInfo.BucketName != bucket {
+			continue
+		}
+		if src, ok := c.objectGVGs[objectInfo.Id.Uint64()]; ok {
+			if _, ok = dstGVGs[src]; !ok {
+				return nil, fmt.Errorf("global virtual group %d of bucket %s is not migrated", src, bucket)
+			}
+		}
+	}
+	return func() {
+		for _, objectInfo := range c.objects {
+			if objectInfo.BucketName != bucket {
+				continue
+			}
+			if src, ok := c.objectGVGs[objectInfo.Id.Uint64()]; ok {
+				c.objectGVGs[objectInfo.Id.Uint64()] = dstGVGs[src]
+				c.gvgs[src].StoredSize -= objectInfo.PayloadSize
+				c.gvgs[dstGVGs[src]].StoredSize += objectInfo.PayloadSize
+			}
+		}
+		bucketInfo.GlobalVirtualGroupFamilyId = familyID
+		bucketInfo.BucketStatus = storagetypes.BUCKET_STATUS_CREATED
+		delete(c.migrations, bucket)
+	}, nil
+}
+
+func (c *Chain) checkRejectMigrateBucket(operator, bucket string) (func(), error) {
+	bucketInfo, _, err := c.checkMigration(operator, bucket)
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		bucketInfo.BucketStatus = storagetypes.BUCKET_STATUS_CREATED
+		delete(c.migrations, bucket)
+	}, nil
+}
+
+func (c *Chain) checkDelegateCreateObject(operator string, msg *storagetypes.MsgDelegateCreateObject) (func(), error) {
+	bucketInfo, err := c.checkCreateObject(msg.GetBucketName(), msg.GetObjectName(), msg.GetPayloadSize())
+	if err != nil {
+		return nil, err
+	}
+	sp, err := c.primarySP(bucketInfo)
+	if err != nil {
+		return nil, err
+	}
+	if !sameAddress(operator, sp.OperatorAddress) {
+		return nil, fmt.Errorf("%s is not the operator address of the primary storage provider", operator)
+	}
+	if !c.allowed(msg.GetCreator(), bucketInfo) {
+		return nil, fmt.Errorf("%s has no permission to create object in bucket %s", msg.GetCreator(), msg.GetBucketName())
+	}
+	return func() {
+		c.createObject(bucketInfo, msg.GetCreator(), msg.GetObjectName(), msg.GetPayloadSize(), msg.GetVisibility(),
+			msg.GetExpectChecksums())
+	}, nil
+}
+
+func (c *Chain) checkDeposit(operator string, gvgID uint32, deposit sdk.Coin) (func(), error) {
+	gvg, err := c.checkPrimaryGlobalVirtualGroup(operator, gvgID)
+	if err != nil {
+		return nil, err
+	}
+	if deposit.Denom != c.vgParams.GetDepositDenom() || deposit.Amount.IsNil() || !deposit.Amount.IsPositive() {
+		return nil, fmt.Errorf("invalid deposit: %s", deposit)
+	}
+	return func() {
+		gvg.TotalDeposit = gvg.TotalDeposit.Add(deposit.Amount)
+	}, nil
+}
+
+func (c *Chain) checkDeleteGlobalVirtualGroup(operator string, gvgID uint32) (func(), error) {
+	gvg, err := c.checkPrimaryGlobalVirtualGroup(operator, gvgID)
+	if err != nil {
+		return nil, err
+	}
+	if gvg.StoredSize != 0 {
+		return nil, fmt.Errorf("global virtual group %d is not empty", gvgID)
+	}
+	return func() {
+		delete(c.gvgs, gvgID)
+		family := c.families[gvg.FamilyId]
+		gvgIDs := make([]uint32, 0, len(family.GlobalVirtualGroupIds))
+		for _, id := range family.GlobalVirtualGroupIds {
+			if id != gvgID {
+				gvgIDs = append(gvgIDs, id)
+			}
+		}
+		family.GlobalVirtualGroupIds = gvgIDs
+	}, nil
+}
+
+// checkPrimaryGlobalVirtualGroup returns the gvg whose primary SP has the operator address.
+func (c *Chain) checkPrimaryGlobalVirtualGroup(operator string, gvgID uint32) (*virtualgrouptypes.GlobalVirtualGroup, error) {
+	gvg, ok := c.gvgs[gvgID]
+	if !ok {
+		return nil, fmt.Errorf("no such global virtual group: %d", gvgID)
+	}
+	sp := c.spByOperator(operator)
+	if sp == nil || sp.Id != gvg.PrimarySpId {
+		return nil, fmt.Errorf("%s is not the operator address of the primary storage provider of global virtual group %d",
+			operator, gvgID)
+	}
+	return gvg, nil
+}
+
+func (c *Chain) checkUpdateSPPrice(operator string, msg *sptypes.MsgUpdateSpStoragePrice) (func(), error) {
+	sp := c.spByOperator(operator)
+	if sp == nil {
+		return nil, fmt.Errorf("%s is not the operator address of a storage provider", operator)
+	}
+	if msg.ReadPrice.IsNil() || msg.ReadPrice.IsNegative() || msg.StorePrice.IsNil() || msg.StorePrice.IsNegative() {
+		return nil, fmt.Errorf("invalid storage price of storage provider %d", sp.Id)
+	}
+	return func() {
+		c.spPrices[sp.Id] = sptypes.SpStoragePrice{
+			SpId:          sp.Id,
+			UpdateTimeSec: c.blockTime.Unix(),
+			ReadPrice:     msg.ReadPrice,
+			FreeReadQuota: msg.FreeReadQuota,
+			StorePrice:    msg.StorePrice,
+		}
+	}, nil
+}
+
+// checkMigration returns the migrating bucket and its dst SP whose operator address is the operator.
+func (c *Chain) checkMigration(operator, bucket string) (*storagetypes.BucketInfo, *sptypes.StorageProvider, error) {
+	bucketInfo, ok := c.buckets[bucket]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s", storagetypes.ErrNoSuchBucket, bucket)
+	}
+	dstSPID, ok := c.migrations[bucket]
+	if !ok || bucketInfo.BucketStatus != storagetypes.BUCKET_STATUS_MIGRATING {
+		return nil, nil, fmt.Errorf("bucket %s is not migrating", bucket)
+	}
+	dstSP := c.sps[dstSPID]
+	if !sameAddress(operator, dstSP.OperatorAddress) {
+		return nil, nil, fmt.Errorf("%s is not the operator address of the dst storage provider", operator)
+	}
+	return bucketInfo, dstSP, nil
+}
+
+func (c *Chain) spByOperator(operator string) *sptypes.StorageProvider {
+	for _, sp := range c.sps {
+		if sameAddress(sp.OperatorAddress, operator) {
+			return sp
+		}
+	}
+	return nil
+}
+
+// sameAddress returns an indicator whether the hex addresses are the same regardless of the checksum case.
+func sameAddress(a, b string) bool {
+	return strings.EqualFold(a, b)
+}
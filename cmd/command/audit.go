@@ -0,0 +1,136 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/zkMeLabs/mechain-storage-provider/cmd/utils"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/auditlog"
+)
+
+const auditCommands = "AUDIT COMMANDS"
+
+var auditFileFlag = &cli.StringFlag{
+	Name:  "file",
+	Usage: "The audit log file, default to the file path of the audit log in the config",
+}
+
+var auditFromFlag = &cli.TimestampFlag{
+	Name:   "from",
+	Usage:  "Export the entries at or after the time, e.g. 2024-01-01T00:00:00Z",
+	Layout: time.RFC3339,
+}
+
+var auditToFlag = &cli.TimestampFlag{
+	Name:   "to",
+	Usage:  "Export the entries before the time, e.g. 2024-01-02T00:00:00Z",
+	Layout: time.RFC3339,
+}
+
+var auditOutputFlag = &cli.StringFlag{
+	Name:    "output",
+	Aliases: []string{"o"},
+	Usage:   "The file to write the exported entries, default to the stdout",
+}
+
+var VerifyAuditLogCmd = &cli.Command{
+	Action: verifyAuditLogAction,
+	Name:   "verify.audit.log",
+	Usage:  "Verify the hash chain of the signer audit log",
+	Flags: []cli.Flag{
+		utils.ConfigFileFlag,
+		auditFileFlag,
+	},
+	Category: auditCommands,
+	Description: `The verify.audit.log command checks the hashes, the sequences and the links of the entries ` +
+		`of the signer audit log, and prints the last entry, whose sequence and hash should be compared with ` +
+		`the ones recorded last time to detect the removed tail of the log.`,
+}
+
+var ExportAuditLogCmd = &cli.Command{
+	Action: exportAuditLogAction,
+	Name:   "export.audit.log",
+	Usage:  "Export the entries of the signer audit log by time range",
+	Flags: []cli.Flag{
+		utils.ConfigFileFlag,
+		auditFileFlag,
+		auditFromFlag,
+		auditToFlag,
+		auditOutputFlag,
+	},
+	Category: auditCommands,
+	Description: `The export.audit.log command exports the entries of the signer audit log in [from, to) as ` +
+		`json lines, the exported entries keep their hashes and can be verified by verify.audit.log.`,
+}
+
+// openAuditLog opens the audit log file from the flag or the config.
+func openAuditLog(ctx *cli.Context) (*os.File, error) {
+	path := ctx.String(auditFileFlag.Name)
+	if path == "" {
+		cfg, err := utils.MakeConfig(ctx)
+		if err != nil {
+			return nil, err
+		}
+		path = cfg.AuditLog.FilePath
+	}
+	if path == "" {
+		return nil, errors.New("audit log file is not set in the config or the file flag")
+	}
+	return os.Open(path)
+}
+
+// verifyAuditLogAction is the verify.audit.log command action.
+func verifyAuditLogAction(ctx *cli.Context) error {
+	file, err := openAuditLog(ctx)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	count, last, err := auditlog.Verify(file)
+	if err != nil {
+		return fmt.Errorf("verified %d entries: %w", count, err)
+	}
+	if last == nil {
+		fmt.Println("audit log is empty")
+		return nil
+	}
+	fmt.Printf("succeed to verify %d entries, last seq: %d, time: %s, hash: %s\n", count, last.Seq,
+		last.Time.Format(time.RFC3339), last.Hash)
+	return nil
+}
+
+// exportAuditLogAction is the export.audit.log command action.
+func exportAuditLogAction(ctx *cli.Context) error {
+	file, err := openAuditLog(ctx)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	var from, to time.Time
+	if t := ctx.Timestamp(auditFromFlag.Name); t != nil {
+		from = *t
+	}
+	if t := ctx.Timestamp(auditToFlag.Name); t != nil {
+		to = *t
+	}
+	var w io.Writer = os.Stdout
+	if output := ctx.String(auditOutputFlag.Name); output != "" {
+		out, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+		w = out
+	}
+	count, err := auditlog.Export(file, w, from, to)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "succeed to export %d entries\n", count)
+	return nil
+}
@@ -0,0 +1,115 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/zkMeLabs/mechain-storage-provider/base/gfspapp"
+	"github.com/zkMeLabs/mechain-storage-provider/cmd/utils"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/pprof/autoprof"
+)
+
+const (
+	profileCommands = "PROFILE COMMANDS"
+	// profileRequestTimeout is the timeout of listing or fetching the profiles from the pprof server.
+	profileRequestTimeout = time.Minute
+)
+
+var profileNameFlag = &cli.StringFlag{
+	Name:     "name",
+	Usage:    "The name of the captured profile",
+	Required: true,
+}
+
+var profileOutputFlag = &cli.StringFlag{
+	Name:    "output",
+	Aliases: []string{"o"},
+	Usage:   "The file to write the profile, default to the name of the profile",
+}
+
+var ListProfilesCmd = &cli.Command{
+	Action: listProfilesAction,
+	Name:   "list.profiles",
+	Usage:  "List the profiles captured on the anomalies",
+	Flags: []cli.Flag{
+		utils.ConfigFileFlag,
+		utils.PProfHTTPFlag,
+	},
+	Category: profileCommands,
+	Description: `The list.profiles command lists the heap, goroutine and cpu profiles captured by the auto ` +
+		`profile from the pprof HTTP server, the address is read from the config or the pprof.addr flag.`,
+}
+
+var FetchProfileCmd = &cli.Command{
+	Action: fetchProfileAction,
+	Name:   "fetch.profile",
+	Usage:  "Fetch a profile captured on the anomalies",
+	Flags: []cli.Flag{
+		utils.ConfigFileFlag,
+		utils.PProfHTTPFlag,
+		profileNameFlag,
+		profileOutputFlag,
+	},
+	Category: profileCommands,
+	Description: `The fetch.profile command fetches the profile of the name from the pprof HTTP server and ` +
+		`writes it to the file, the profile can be analysed by 'go tool pprof'.`,
+}
+
+// pprofAddress returns the address of the pprof HTTP server from the config and the flags.
+func pprofAddress(ctx *cli.Context) (string, error) {
+	cfg, err := utils.MakeConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+	if cfg.Monitor.PProfHTTPAddress == "" {
+		return gfspapp.DefaultPProfAddress, nil
+	}
+	return cfg.Monitor.PProfHTTPAddress, nil
+}
+
+// listProfilesAction is the list.profiles command action.
+func listProfilesAction(ctx *cli.Context) error {
+	address, err := pprofAddress(ctx)
+	if err != nil {
+		return err
+	}
+	reqCtx, cancel := context.WithTimeout(context.Background(), profileRequestTimeout)
+	defer cancel()
+	metas, err := autoprof.ListProfiles(reqCtx, address)
+	if err != nil {
+		return err
+	}
+	for _, meta := range metas {
+		fmt.Printf("%-60s %-10s %-10s %10d %s %s\n", meta.Name, meta.Trigger, meta.Profile, meta.Size,
+			meta.CreatedAt.Format(time.RFC3339), meta.Reason)
+	}
+	return nil
+}
+
+// fetchProfileAction is the fetch.profile command action.
+func fetchProfileAction(ctx *cli.Context) error {
+	address, err := pprofAddress(ctx)
+	if err != nil {
+		return err
+	}
+	name := ctx.String(profileNameFlag.Name)
+	output := ctx.String(profileOutputFlag.Name)
+	if output == "" {
+		output = name
+	}
+	reqCtx, cancel := context.WithTimeout(context.Background(), profileRequestTimeout)
+	defer cancel()
+	data, err := autoprof.FetchProfile(reqCtx, address, name)
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(output, data, 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("succeed to fetch profile %s to %s, size: %d\n", name, output, len(data))
+	return nil
+}
@@ -0,0 +1,47 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/zkMeLabs/mechain-storage-provider/cmd/utils"
+	"github.com/zkMeLabs/mechain-storage-provider/modular/signer"
+)
+
+var rotateKeyFlag = &cli.StringSliceFlag{
+	Name:     "key",
+	Usage:    "The key to rotate, one of " + strings.Join(signer.RotatableKeyIDs, ", ") + ", it can be repeated",
+	Required: true,
+}
+
+var RotateSPKeyCmd = &cli.Command{
+	Action: rotateSPKeyAction,
+	Name:   "rotate.sp.key",
+	Usage:  "Rotate the seal, approval or bls key of the SP on chain",
+	Flags: []cli.Flag{
+		utils.ConfigFileFlag,
+		rotateKeyFlag,
+	},
+	Category: "KEY COMMANDS",
+	Description: `The rotate.sp.key command sends a txn signed by the operator key to update the SP keys on ` +
+		`mechain chain to the next keys in the config, e.g. NextSealPrivateKey or the "next-seal" key of the ` +
+		`pkcs11 and remote backends. The running signer loaded with the same next keys switches to them once ` +
+		`the chain reflects the update, and keeps accepting the previous keys in the verification during the ` +
+		`grace period.`,
+}
+
+func rotateSPKeyAction(ctx *cli.Context) error {
+	cfg, err := utils.MakeConfig(ctx)
+	if err != nil {
+		return err
+	}
+	keyIDs := ctx.StringSlice(rotateKeyFlag.Name)
+	txHash, err := signer.SubmitKeyRotation(ctx.Context, cfg, keyIDs)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("send rotate sp key txn successfully, keys: %s, txn hash: %s\n", strings.Join(keyIDs, ","), txHash)
+	return nil
+}
@@ -142,6 +142,14 @@ func init() {
 		command.QueryRecoverProcessCmd,
 		command.ListGlobalVirtualGroupsBySecondarySPCmd,
 		command.ListVirtualGroupFamiliesBySpIDCmd,
+		// profile commands
+		command.ListProfilesCmd,
+		command.FetchProfileCmd,
+		// audit commands
+		command.VerifyAuditLogCmd,
+		command.ExportAuditLogCmd,
+		// key commands
+		command.RotateSPKeyCmd,
 	}
 	registerModular()
 }
@@ -2,7 +2,10 @@ package utils
 
 import (
 	"errors"
+	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/cmd/utils"
 	"github.com/pelletier/go-toml/v2"
@@ -90,7 +93,29 @@ func initLog(ctx *cli.Context, cfg *gfspconfig.GfSpConfig) error {
 	if err != nil {
 		return err
 	}
-	log.Init(level, cfg.Log.Path)
+	moduleLevels := make(map[string]log.Level, len(cfg.Log.ModuleLevels))
+	for _, moduleLevel := range cfg.Log.ModuleLevels {
+		module, lvl, found := strings.Cut(moduleLevel, "=")
+		if !found || module == "" {
+			return fmt.Errorf("invalid module level: %s", moduleLevel)
+		}
+		if moduleLevels[module], err = log.ParseLevel(lvl); err != nil {
+			return err
+		}
+	}
+	if cfg.Log.RotateInterval != "" && cfg.Log.RotateInterval != log.RotateHourly && cfg.Log.RotateInterval != log.RotateDaily {
+		return fmt.Errorf("invalid log rotate interval: %s", cfg.Log.RotateInterval)
+	}
+	log.Init(level, cfg.Log.Path, log.WithRotation(log.RotateConfig{
+		Interval:   cfg.Log.RotateInterval,
+		MaxSize:    cfg.Log.MaxSizeMB * 1024 * 1024,
+		MaxBackups: cfg.Log.MaxBackups,
+		MaxAge:     time.Duration(cfg.Log.MaxAgeDay) * 24 * time.Hour,
+	}))
+	for module, lvl := range moduleLevels {
+		log.SetModuleLevel(module, lvl)
+	}
+	log.SetSampling(time.Second, cfg.Log.SamplingFirst, cfg.Log.SamplingThereafter)
 	return nil
 }
 
@@ -136,6 +161,13 @@ func MakeGfSpClient(cfg *gfspconfig.GfSpConfig) *gfspclient.GfSpClient {
 		cfg.Endpoint.SignerEndpoint,
 		cfg.Endpoint.AuthenticatorEndpoint,
 		false)
+	tlsReloader, err := gfspapp.NewTLSReloader(&cfg.Endpoint)
+	if err != nil {
+		log.Panicw("failed to load tls certificates of endpoints", "error", err)
+	}
+	if tlsReloader != nil {
+		client.SetTransportCredentials(tlsReloader.ClientCredentials())
+	}
 	return client
 }
 
@@ -147,9 +179,12 @@ func MakeGnfd(cfg *gfspconfig.GfSpConfig) (*gnfd.Gnfd, error) {
 		cfg.Chain.ChainAddress = []string{gfspapp.DefaultChainAddress}
 	}
 	gnfdCfg := &gnfd.GnfdChainConfig{
-		ChainID:      cfg.Chain.ChainID,
-		ChainAddress: cfg.Chain.ChainAddress,
-		RpcAddress:   cfg.Chain.RpcAddress,
+		ChainID:                       cfg.Chain.ChainID,
+		ChainAddress:                  cfg.Chain.ChainAddress,
+		RpcAddress:                    cfg.Chain.RpcAddress,
+		ProbeInterval:                 time.Duration(cfg.Chain.EndpointProbeIntervalMillisecond) * time.Millisecond,
+		MaxHeightLag:                  cfg.Chain.MaxEndpointHeightLag,
+		EnableObjectEventSubscription: cfg.Chain.EnableObjectEventSubscription,
 	}
 	return gnfd.NewGnfd(gnfdCfg)
 }
@@ -184,3 +184,17 @@ func (c *NullConsensus) QueryShadowObjectInfo(ctx context.Context, bucket, objec
 	return nil, nil
 }
 func (*NullConsensus) Close() error { return nil }
+
+type refreshKey struct{}
+
+// WithRefresh returns the context whose queries bypass the cached results of the consensus, e.g. the caller
+// finds the cached result outdated, the fresh result replaces the cached one.
+func WithRefresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, refreshKey{}, true)
+}
+
+// IsRefresh returns whether the queries of the context bypass the cached results of the consensus.
+func IsRefresh(ctx context.Context) bool {
+	refresh, _ := ctx.Value(refreshKey{}).(bool)
+	return refresh
+}
@@ -41,3 +41,12 @@ func TestNullConsensus(t *testing.T) {
 	_, _ = nc.QueryShadowObjectInfo(context.TODO(), "", "")
 	_ = nc.Close()
 }
+
+func TestWithRefresh(t *testing.T) {
+	if IsRefresh(context.Background()) {
+		t.Error("the background context should not refresh")
+	}
+	if !IsRefresh(WithRefresh(context.Background())) {
+		t.Error("the refresh context should refresh")
+	}
+}
@@ -202,3 +202,42 @@ type MigrateBucketProgressMeta struct {
 	LastGcObjectID uint64 // After bucket migration is complete, the progress of GC, up to which object is GC performed.
 	LastGcGvgID    uint64 // which GVG is GC performed.
 }
+
+// WebhookSubscription defines the webhook endpoint which receives the object and bucket events
+// of the buckets owned by the owner address.
+type WebhookSubscription struct {
+	OwnerAddress          string
+	URL                   string
+	Secret                string
+	Events                []string // empty means subscribing all events
+	CreateTimestampSecond int64
+	UpdateTimestampSecond int64
+}
+
+// BucketCORSRule defines the cross-origin requests which are allowed by the bucket.
+type BucketCORSRule struct {
+	AllowedOrigins []string `json:"allowed_origins"`
+	AllowedMethods []string `json:"allowed_methods"`
+	AllowedHeaders []string `json:"allowed_headers,omitempty"`
+	ExposeHeaders  []string `json:"expose_headers,omitempty"`
+	MaxAgeSeconds  int64    `json:"max_age_seconds,omitempty"`
+}
+
+// BucketCORS defines the CORS rules of the bucket set by the bucket owner.
+type BucketCORS struct {
+	BucketName            string
+	Rules                 []*BucketCORSRule
+	UpdateTimestampSecond int64
+}
+
+// AccountRateLimit overrides the default rate limits of the account, or bans the account. The override
+// expires at ExpireTimestampSecond, the zero value means it never expires.
+type AccountRateLimit struct {
+	Account               string
+	ReadRateLimit         int64
+	WriteRateLimit        int64
+	Banned                bool
+	Reason                string
+	ExpireTimestampSecond int64
+	UpdateTimestampSecond int64
+}
@@ -21,6 +21,9 @@ type SPDB interface {
 	OffChainAuthKeyV2DB
 	MigrateDB
 	ExitRecoverDB
+	WebhookDB
+	BucketCORSDB
+	AccountRateLimitDB
 }
 
 // UploadObjectProgressDB interface which records upload object related progress(includes foreground and background) and state.
@@ -291,3 +294,38 @@ type ExitRecoverDB interface {
 	// CountRecoverFailedObject return the failed object total count
 	CountRecoverFailedObject() (int64, error)
 }
+
+// WebhookDB is used to support pushing object and bucket events to the bucket owners.
+type WebhookDB interface {
+	// SetWebhookSubscription sets(maybe overwrite) the webhook subscription of the bucket owner.
+	SetWebhookSubscription(sub *WebhookSubscription) error
+	// GetWebhookSubscription returns the webhook subscription of the bucket owner,
+	// notice maybe return (nil, nil) while there is no subscription.
+	GetWebhookSubscription(ownerAddress string) (*WebhookSubscription, error)
+	// DeleteWebhookSubscription deletes the webhook subscription of the bucket owner.
+	DeleteWebhookSubscription(ownerAddress string) error
+}
+
+// BucketCORSDB is used to support the per-bucket CORS rules evaluated by the gateway.
+type BucketCORSDB interface {
+	// SetBucketCORS sets(maybe overwrite) the CORS rules of the bucket.
+	SetBucketCORS(cors *BucketCORS) error
+	// GetBucketCORS returns the CORS rules of the bucket,
+	// notice maybe return (nil, nil) while the bucket has no CORS rules.
+	GetBucketCORS(bucketName string) (*BucketCORS, error)
+	// DeleteBucketCORS deletes the CORS rules of the bucket.
+	DeleteBucketCORS(bucketName string) error
+}
+
+// AccountRateLimitDB is used to support the per-account rate limit overrides and bans of the gateway.
+type AccountRateLimitDB interface {
+	// SetAccountRateLimit sets(maybe overwrite) the rate limit override of the account.
+	SetAccountRateLimit(limit *AccountRateLimit) error
+	// GetAccountRateLimit returns the rate limit override of the account,
+	// notice maybe return (nil, nil) while the account has no override.
+	GetAccountRateLimit(account string) (*AccountRateLimit, error)
+	// DeleteAccountRateLimit deletes the rate limit override of the account.
+	DeleteAccountRateLimit(account string) error
+	// ListAccountRateLimits returns all the rate limit overrides including the expired ones.
+	ListAccountRateLimits() ([]*AccountRateLimit, error)
+}
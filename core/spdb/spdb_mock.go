@@ -100,6 +100,20 @@ func (mr *MockSPDBMockRecorder) CountRecoverFailedObject() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountRecoverFailedObject", reflect.TypeOf((*MockSPDB)(nil).CountRecoverFailedObject))
 }
 
+// DeleteAccountRateLimit mocks base method.
+func (m *MockSPDB) DeleteAccountRateLimit(account string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccountRateLimit", account)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccountRateLimit indicates an expected call of DeleteAccountRateLimit.
+func (mr *MockSPDBMockRecorder) DeleteAccountRateLimit(account any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccountRateLimit", reflect.TypeOf((*MockSPDB)(nil).DeleteAccountRateLimit), account)
+}
+
 // DeleteAllReplicatePieceChecksum mocks base method.
 func (m *MockSPDB) DeleteAllReplicatePieceChecksum(objectID uint64, redundancyIdx int32, pieceCount uint32) error {
 	m.ctrl.T.Helper()
@@ -143,6 +157,20 @@ func (mr *MockSPDBMockRecorder) DeleteAuthKeysV2(userAddress, domain, publicKey
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAuthKeysV2", reflect.TypeOf((*MockSPDB)(nil).DeleteAuthKeysV2), userAddress, domain, publicKey)
 }
 
+// DeleteBucketCORS mocks base method.
+func (m *MockSPDB) DeleteBucketCORS(bucketName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBucketCORS", bucketName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBucketCORS indicates an expected call of DeleteBucketCORS.
+func (mr *MockSPDBMockRecorder) DeleteBucketCORS(bucketName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBucketCORS", reflect.TypeOf((*MockSPDB)(nil).DeleteBucketCORS), bucketName)
+}
+
 // DeleteExpiredBucketTraffic mocks base method.
 func (m *MockSPDB) DeleteExpiredBucketTraffic(yearMonth string) error {
 	m.ctrl.T.Helper()
@@ -325,6 +353,20 @@ func (mr *MockSPDBMockRecorder) DeleteUploadProgress(objectID any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUploadProgress", reflect.TypeOf((*MockSPDB)(nil).DeleteUploadProgress), objectID)
 }
 
+// DeleteWebhookSubscription mocks base method.
+func (m *MockSPDB) DeleteWebhookSubscription(ownerAddress string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhookSubscription", ownerAddress)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhookSubscription indicates an expected call of DeleteWebhookSubscription.
+func (mr *MockSPDBMockRecorder) DeleteWebhookSubscription(ownerAddress any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhookSubscription", reflect.TypeOf((*MockSPDB)(nil).DeleteWebhookSubscription), ownerAddress)
+}
+
 // FetchAllSp mocks base method.
 func (m *MockSPDB) FetchAllSp(status ...types0.Status) ([]*types0.StorageProvider, error) {
 	m.ctrl.T.Helper()
@@ -363,6 +405,21 @@ func (mr *MockSPDBMockRecorder) FetchAllSpWithoutOwnSp(status ...any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchAllSpWithoutOwnSp", reflect.TypeOf((*MockSPDB)(nil).FetchAllSpWithoutOwnSp), status...)
 }
 
+// GetAccountRateLimit mocks base method.
+func (m *MockSPDB) GetAccountRateLimit(account string) (*AccountRateLimit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountRateLimit", account)
+	ret0, _ := ret[0].(*AccountRateLimit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountRateLimit indicates an expected call of GetAccountRateLimit.
+func (mr *MockSPDBMockRecorder) GetAccountRateLimit(account any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountRateLimit", reflect.TypeOf((*MockSPDB)(nil).GetAccountRateLimit), account)
+}
+
 // GetAllReplicatePieceChecksum mocks base method.
 func (m *MockSPDB) GetAllReplicatePieceChecksum(objectID uint64, redundancyIdx int32, pieceCount uint32) ([][]byte, error) {
 	m.ctrl.T.Helper()
@@ -423,6 +480,21 @@ func (mr *MockSPDBMockRecorder) GetAuthKeyV2(userAddress, domain, publicKey any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuthKeyV2", reflect.TypeOf((*MockSPDB)(nil).GetAuthKeyV2), userAddress, domain, publicKey)
 }
 
+// GetBucketCORS mocks base method.
+func (m *MockSPDB) GetBucketCORS(bucketName string) (*BucketCORS, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBucketCORS", bucketName)
+	ret0, _ := ret[0].(*BucketCORS)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBucketCORS indicates an expected call of GetBucketCORS.
+func (mr *MockSPDBMockRecorder) GetBucketCORS(bucketName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBucketCORS", reflect.TypeOf((*MockSPDB)(nil).GetBucketCORS), bucketName)
+}
+
 // GetBucketReadRecord mocks base method.
 func (m *MockSPDB) GetBucketReadRecord(bucketID uint64, timeRange *TrafficTimeRange) ([]*ReadRecord, error) {
 	m.ctrl.T.Helper()
@@ -799,6 +871,21 @@ func (mr *MockSPDBMockRecorder) GetUserReadRecord(userAddress, timeRange any) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserReadRecord", reflect.TypeOf((*MockSPDB)(nil).GetUserReadRecord), userAddress, timeRange)
 }
 
+// GetWebhookSubscription mocks base method.
+func (m *MockSPDB) GetWebhookSubscription(ownerAddress string) (*WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookSubscription", ownerAddress)
+	ret0, _ := ret[0].(*WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookSubscription indicates an expected call of GetWebhookSubscription.
+func (mr *MockSPDBMockRecorder) GetWebhookSubscription(ownerAddress any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookSubscription", reflect.TypeOf((*MockSPDB)(nil).GetWebhookSubscription), ownerAddress)
+}
+
 // InitBucketTraffic mocks base method.
 func (m *MockSPDB) InitBucketTraffic(record *ReadRecord, quota *BucketQuota) error {
 	m.ctrl.T.Helper()
@@ -925,6 +1012,21 @@ func (mr *MockSPDBMockRecorder) InsertUploadProgress(objectID, isAgentUpload any
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertUploadProgress", reflect.TypeOf((*MockSPDB)(nil).InsertUploadProgress), objectID, isAgentUpload)
 }
 
+// ListAccountRateLimits mocks base method.
+func (m *MockSPDB) ListAccountRateLimits() ([]*AccountRateLimit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountRateLimits")
+	ret0, _ := ret[0].([]*AccountRateLimit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountRateLimits indicates an expected call of ListAccountRateLimits.
+func (mr *MockSPDBMockRecorder) ListAccountRateLimits() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountRateLimits", reflect.TypeOf((*MockSPDB)(nil).ListAccountRateLimits))
+}
+
 // ListAuthKeysV2 mocks base method.
 func (m *MockSPDB) ListAuthKeysV2(userAddress, domain string) ([]string, error) {
 	m.ctrl.T.Helper()
@@ -1150,6 +1252,34 @@ func (mr *MockSPDBMockRecorder) QuerySwapOutUnitInSrcSP(swapOutKey any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QuerySwapOutUnitInSrcSP", reflect.TypeOf((*MockSPDB)(nil).QuerySwapOutUnitInSrcSP), swapOutKey)
 }
 
+// SetAccountRateLimit mocks base method.
+func (m *MockSPDB) SetAccountRateLimit(limit *AccountRateLimit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAccountRateLimit", limit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetAccountRateLimit indicates an expected call of SetAccountRateLimit.
+func (mr *MockSPDBMockRecorder) SetAccountRateLimit(limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAccountRateLimit", reflect.TypeOf((*MockSPDB)(nil).SetAccountRateLimit), limit)
+}
+
+// SetBucketCORS mocks base method.
+func (m *MockSPDB) SetBucketCORS(cors *BucketCORS) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetBucketCORS", cors)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetBucketCORS indicates an expected call of SetBucketCORS.
+func (mr *MockSPDBMockRecorder) SetBucketCORS(cors any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBucketCORS", reflect.TypeOf((*MockSPDB)(nil).SetBucketCORS), cors)
+}
+
 // SetObjectIntegrity mocks base method.
 func (m *MockSPDB) SetObjectIntegrity(integrity *IntegrityMeta) error {
 	m.ctrl.T.Helper()
@@ -1220,6 +1350,20 @@ func (mr *MockSPDBMockRecorder) SetShadowObjectIntegrity(integrity any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetShadowObjectIntegrity", reflect.TypeOf((*MockSPDB)(nil).SetShadowObjectIntegrity), integrity)
 }
 
+// SetWebhookSubscription mocks base method.
+func (m *MockSPDB) SetWebhookSubscription(sub *WebhookSubscription) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWebhookSubscription", sub)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetWebhookSubscription indicates an expected call of SetWebhookSubscription.
+func (mr *MockSPDBMockRecorder) SetWebhookSubscription(sub any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWebhookSubscription", reflect.TypeOf((*MockSPDB)(nil).SetWebhookSubscription), sub)
+}
+
 // UpdateAllSp mocks base method.
 func (m *MockSPDB) UpdateAllSp(spList []*types0.StorageProvider) error {
 	m.ctrl.T.Helper()
@@ -3360,3 +3504,216 @@ func (mr *MockExitRecoverDBMockRecorder) UpdateRecoverGVGStats(stats any) *gomoc
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRecoverGVGStats", reflect.TypeOf((*MockExitRecoverDB)(nil).UpdateRecoverGVGStats), stats)
 }
+
+// MockWebhookDB is a mock of WebhookDB interface.
+type MockWebhookDB struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookDBMockRecorder
+}
+
+// MockWebhookDBMockRecorder is the mock recorder for MockWebhookDB.
+type MockWebhookDBMockRecorder struct {
+	mock *MockWebhookDB
+}
+
+// NewMockWebhookDB creates a new mock instance.
+func NewMockWebhookDB(ctrl *gomock.Controller) *MockWebhookDB {
+	mock := &MockWebhookDB{ctrl: ctrl}
+	mock.recorder = &MockWebhookDBMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookDB) EXPECT() *MockWebhookDBMockRecorder {
+	return m.recorder
+}
+
+// DeleteWebhookSubscription mocks base method.
+func (m *MockWebhookDB) DeleteWebhookSubscription(ownerAddress string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhookSubscription", ownerAddress)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhookSubscription indicates an expected call of DeleteWebhookSubscription.
+func (mr *MockWebhookDBMockRecorder) DeleteWebhookSubscription(ownerAddress any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhookSubscription", reflect.TypeOf((*MockWebhookDB)(nil).DeleteWebhookSubscription), ownerAddress)
+}
+
+// GetWebhookSubscription mocks base method.
+func (m *MockWebhookDB) GetWebhookSubscription(ownerAddress string) (*WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookSubscription", ownerAddress)
+	ret0, _ := ret[0].(*WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookSubscription indicates an expected call of GetWebhookSubscription.
+func (mr *MockWebhookDBMockRecorder) GetWebhookSubscription(ownerAddress any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookSubscription", reflect.TypeOf((*MockWebhookDB)(nil).GetWebhookSubscription), ownerAddress)
+}
+
+// SetWebhookSubscription mocks base method.
+func (m *MockWebhookDB) SetWebhookSubscription(sub *WebhookSubscription) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWebhookSubscription", sub)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetWebhookSubscription indicates an expected call of SetWebhookSubscription.
+func (mr *MockWebhookDBMockRecorder) SetWebhookSubscription(sub any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWebhookSubscription", reflect.TypeOf((*MockWebhookDB)(nil).SetWebhookSubscription), sub)
+}
+
+// MockBucketCORSDB is a mock of BucketCORSDB interface.
+type MockBucketCORSDB struct {
+	ctrl     *gomock.Controller
+	recorder *MockBucketCORSDBMockRecorder
+}
+
+// MockBucketCORSDBMockRecorder is the mock recorder for MockBucketCORSDB.
+type MockBucketCORSDBMockRecorder struct {
+	mock *MockBucketCORSDB
+}
+
+// NewMockBucketCORSDB creates a new mock instance.
+func NewMockBucketCORSDB(ctrl *gomock.Controller) *MockBucketCORSDB {
+	mock := &MockBucketCORSDB{ctrl: ctrl}
+	mock.recorder = &MockBucketCORSDBMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBucketCORSDB) EXPECT() *MockBucketCORSDBMockRecorder {
+	return m.recorder
+}
+
+// DeleteBucketCORS mocks base method.
+func (m *MockBucketCORSDB) DeleteBucketCORS(bucketName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBucketCORS", bucketName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBucketCORS indicates an expected call of DeleteBucketCORS.
+func (mr *MockBucketCORSDBMockRecorder) DeleteBucketCORS(bucketName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBucketCORS", reflect.TypeOf((*MockBucketCORSDB)(nil).DeleteBucketCORS), bucketName)
+}
+
+// GetBucketCORS mocks base method.
+func (m *MockBucketCORSDB) GetBucketCORS(bucketName string) (*BucketCORS, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBucketCORS", bucketName)
+	ret0, _ := ret[0].(*BucketCORS)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBucketCORS indicates an expected call of GetBucketCORS.
+func (mr *MockBucketCORSDBMockRecorder) GetBucketCORS(bucketName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBucketCORS", reflect.TypeOf((*MockBucketCORSDB)(nil).GetBucketCORS), bucketName)
+}
+
+// SetBucketCORS mocks base method.
+func (m *MockBucketCORSDB) SetBucketCORS(cors *BucketCORS) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetBucketCORS", cors)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetBucketCORS indicates an expected call of SetBucketCORS.
+func (mr *MockBucketCORSDBMockRecorder) SetBucketCORS(cors any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBucketCORS", reflect.TypeOf((*MockBucketCORSDB)(nil).SetBucketCORS), cors)
+}
+
+// MockAccountRateLimitDB is a mock of AccountRateLimitDB interface.
+type MockAccountRateLimitDB struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccountRateLimitDBMockRecorder
+}
+
+// MockAccountRateLimitDBMockRecorder is the mock recorder for MockAccountRateLimitDB.
+type MockAccountRateLimitDBMockRecorder struct {
+	mock *MockAccountRateLimitDB
+}
+
+// NewMockAccountRateLimitDB creates a new mock instance.
+func NewMockAccountRateLimitDB(ctrl *gomock.Controller) *MockAccountRateLimitDB {
+	mock := &MockAccountRateLimitDB{ctrl: ctrl}
+	mock.recorder = &MockAccountRateLimitDBMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccountRateLimitDB) EXPECT() *MockAccountRateLimitDBMockRecorder {
+	return m.recorder
+}
+
+// DeleteAccountRateLimit mocks base method.
+func (m *MockAccountRateLimitDB) DeleteAccountRateLimit(account string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccountRateLimit", account)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccountRateLimit indicates an expected call of DeleteAccountRateLimit.
+func (mr *MockAccountRateLimitDBMockRecorder) DeleteAccountRateLimit(account any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccountRateLimit", reflect.TypeOf((*MockAccountRateLimitDB)(nil).DeleteAccountRateLimit), account)
+}
+
+// GetAccountRateLimit mocks base method.
+func (m *MockAccountRateLimitDB) GetAccountRateLimit(account string) (*AccountRateLimit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountRateLimit", account)
+	ret0, _ := ret[0].(*AccountRateLimit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountRateLimit indicates an expected call of GetAccountRateLimit.
+func (mr *MockAccountRateLimitDBMockRecorder) GetAccountRateLimit(account any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountRateLimit", reflect.TypeOf((*MockAccountRateLimitDB)(nil).GetAccountRateLimit), account)
+}
+
+// ListAccountRateLimits mocks base method.
+func (m *MockAccountRateLimitDB) ListAccountRateLimits() ([]*AccountRateLimit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountRateLimits")
+	ret0, _ := ret[0].([]*AccountRateLimit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountRateLimits indicates an expected call of ListAccountRateLimits.
+func (mr *MockAccountRateLimitDBMockRecorder) ListAccountRateLimits() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountRateLimits", reflect.TypeOf((*MockAccountRateLimitDB)(nil).ListAccountRateLimits))
+}
+
+// SetAccountRateLimit mocks base method.
+func (m *MockAccountRateLimitDB) SetAccountRateLimit(limit *AccountRateLimit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAccountRateLimit", limit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetAccountRateLimit indicates an expected call of SetAccountRateLimit.
+func (mr *MockAccountRateLimitDBMockRecorder) SetAccountRateLimit(limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAccountRateLimit", reflect.TypeOf((*MockAccountRateLimitDB)(nil).SetAccountRateLimit), limit)
+}
@@ -19,6 +19,7 @@ import (
 	metadatatypes "github.com/zkMeLabs/mechain-storage-provider/modular/metadata/types"
 	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
 	"github.com/zkMeLabs/mechain-storage-provider/pkg/metrics"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/webhook"
 	"github.com/zkMeLabs/mechain-storage-provider/store/sqldb"
 	"github.com/zkMeLabs/mechain-storage-provider/util"
 )
@@ -310,6 +311,17 @@ func (e *ExecuteModular) HandleGCObjectTask(ctx context.Context, task coretask.G
 			return
 		}
 		log.CtxDebugw(ctx, "succeed to gc an object", "object_info", objectInfo, "deleted_at_block_id", currentGCBlockID)
+		// only the primary sp notifies the bucket owner to avoid duplicate events
+		if gvg.GetPrimarySpId() == spId {
+			e.baseApp.Webhook().Notify(&webhook.Event{
+				Type:        webhook.EventObjectDeleted,
+				BucketName:  objectInfo.GetBucketName(),
+				BucketID:    bucketInfo.BucketInfo.Id.Uint64(),
+				BucketOwner: bucketInfo.BucketInfo.GetOwner(),
+				ObjectName:  objectInfo.GetObjectName(),
+				ObjectID:    currentGCObjectID,
+			})
+		}
 		gcObjectNumber++
 	}
 	isSucceed = true
@@ -18,6 +18,7 @@ import (
 	"github.com/zkMeLabs/mechain-common/go/hash"
 	"github.com/zkMeLabs/mechain-common/go/redundancy"
 	"github.com/zkMeLabs/mechain-storage-provider/base/types/gfsptask"
+	"github.com/zkMeLabs/mechain-storage-provider/core/consensus"
 	coretask "github.com/zkMeLabs/mechain-storage-provider/core/task"
 	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
 	"github.com/zkMeLabs/mechain-storage-provider/pkg/metrics"
@@ -193,7 +194,7 @@ func (e *ExecuteModular) handleReplicatePiece(ctx context.Context, rTask coretas
 			signature, innerErr := e.doneReplicatePiece(ctx, rTask, spEp, int32(rIdx))
 			if innerErr == nil {
 				msg := storagetypes.NewSecondarySpSealObjectSignDoc(e.baseApp.ChainID(), gvg.Id, rTask.GetObjectInfo().Id, storagetypes.GenerateHash(rTask.GetObjectInfo().GetChecksums()[:])).GetBlsSignHash()
-				err = veritySecondarySpBlsSignature(e.getSpByID(gvg.GetSecondarySpIds()[rIdx]), signature, msg[:])
+				err = e.verifySecondarySpBlsSignature(ctx, gvg.GetSecondarySpIds()[rIdx], signature, msg[:])
 				if err != nil {
 					rTask.SetNotAvailableSpIdx(int32(rIdx))
 					log.CtxErrorw(ctx, "failed to verify secondary SP bls signature", "secondary_sp_id", gvg.GetSecondarySpIds()[rIdx], "error", err.Error())
@@ -408,6 +409,26 @@ func (e *ExecuteModular) doneReplicatePiece(ctx context.Context, rTask coretask.
 	return signature, nil
 }
 
+// verifySecondarySpBlsSignature verifies the bls signature by the cached secondary SP info, it is verified again
+// by the SP info on chain if it fails, e.g. the secondary SP has rotated its bls key and the cache is not updated.
+// The SP info is queried bypassing the consensus cache, which may hold the same outdated info.
+func (e *ExecuteModular) verifySecondarySpBlsSignature(ctx context.Context, spID uint32, signature, sigDoc []byte) error {
+	if secondarySp := e.getSpByID(spID); secondarySp != nil {
+		if err := veritySecondarySpBlsSignature(secondarySp, signature, sigDoc); err == nil {
+			return nil
+		}
+	}
+	secondarySp, err := e.baseApp.Consensus().QuerySPByID(consensus.WithRefresh(ctx), spID)
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to query secondary sp", "secondary_sp_id", spID, "error", err)
+		return err
+	}
+	e.mutex.Lock()
+	e.spMap[spID] = secondarySp
+	e.mutex.Unlock()
+	return veritySecondarySpBlsSignature(secondarySp, signature, sigDoc)
+}
+
 func veritySecondarySpBlsSignature(secondarySp *sptypes.StorageProvider, signature, sigDoc []byte) error {
 	publicKey, err := bls.UnmarshalPublicKey(secondarySp.BlsKey)
 	if err != nil {
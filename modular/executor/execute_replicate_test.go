@@ -1 +1,297 @@
 package executor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/bls"
+	"github.com/cometbft/cometbft/votepool"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+
+	sptypes "github.com/evmos/evmos/v12/x/sp/types"
+	storagetypes "github.com/evmos/evmos/v12/x/storage/types"
+	virtualgrouptypes "github.com/evmos/evmos/v12/x/virtualgroup/types"
+	"github.com/zkMeLabs/mechain-common/go/hash"
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+	"github.com/zkMeLabs/mechain-storage-provider/base/gfspapp"
+	"github.com/zkMeLabs/mechain-storage-provider/base/gfspclient"
+	"github.com/zkMeLabs/mechain-storage-provider/base/gfspconfig"
+	"github.com/zkMeLabs/mechain-storage-provider/base/gfsppieceop"
+	"github.com/zkMeLabs/mechain-storage-provider/base/gfsptqueue"
+	"github.com/zkMeLabs/mechain-storage-provider/base/simchain"
+	"github.com/zkMeLabs/mechain-storage-provider/base/types/gfsptask"
+	"github.com/zkMeLabs/mechain-storage-provider/core/piecestore"
+	corespdb "github.com/zkMeLabs/mechain-storage-provider/core/spdb"
+	coretask "github.com/zkMeLabs/mechain-storage-provider/core/task"
+	"github.com/zkMeLabs/mechain-storage-provider/modular/downloader"
+	"github.com/zkMeLabs/mechain-storage-provider/modular/uploader"
+)
+
+const flowBucketOwner = "0x00000000000000000000000000000000000000a1"
+
+// flowSP returns the endpoint and the address of the kind of address of the SP in the flow test.
+func flowSP(spIndex, kind int) (string, string) {
+	return fmt.Sprintf("http://sp%d.local", spIndex), fmt.Sprintf("0x%038x%02x", spIndex, kind)
+}
+
+// replicateFlow runs the modules of the primary SP 1 against the simulated chain. The secondary SPs 2 to 7 are
+// simulated by the gfsp client of the primary SP, they keep the replicated pieces and sign the seal of the object
+// with their bls keys like the receiver.
+type replicateFlow struct {
+	chain      *simchain.Chain
+	app        *gfspapp.GfSpBaseApp
+	params     *storagetypes.Params
+	bucket     *storagetypes.BucketInfo
+	gvgID      uint32
+	blsKeys    map[string]*bls.PrivateKey
+	mux        sync.Mutex
+	pieces     map[string][]byte
+	replicated map[string][]byte
+	reported   chan struct{}
+}
+
+func setupReplicateFlow(t *testing.T) *replicateFlow {
+	t.Helper()
+	f := &replicateFlow{
+		chain:      simchain.New(&simchain.Config{BlockInterval: 10 * time.Millisecond}),
+		app:        &gfspapp.GfSpBaseApp{},
+		blsKeys:    make(map[string]*bls.PrivateKey),
+		pieces:     make(map[string][]byte),
+		replicated: make(map[string][]byte),
+		reported:   make(chan struct{}, 1),
+	}
+	f.chain.Start()
+	t.Cleanup(func() { _ = f.chain.Close() })
+	for i := 1; i <= 7; i++ {
+		key, err := bls.GenerateBlsKey()
+		assert.Nil(t, err)
+		endpoint, _ := flowSP(i, 0)
+		_, operator := flowSP(i, 1)
+		_, seal := flowSP(i, 3)
+		f.chain.AddSP(&sptypes.StorageProvider{
+			OperatorAddress: operator,
+			SealAddress:     seal,
+			BlsKey:          key.PublicKey().Marshal(),
+			Endpoint:        endpoint,
+		})
+		f.blsKeys[endpoint] = key
+	}
+	familyID, gvgID, err := f.chain.AddGlobalVirtualGroup(1, 0, []uint32{2, 3, 4, 5, 6, 7})
+	assert.Nil(t, err)
+	f.gvgID = gvgID
+	f.bucket, err = f.chain.CreateBucket(flowBucketOwner, "bucket", familyID, storagetypes.VISIBILITY_TYPE_PRIVATE)
+	assert.Nil(t, err)
+	f.params, err = f.chain.QueryStorageParams(context.Background())
+	assert.Nil(t, err)
+
+	ctrl := gomock.NewController(t)
+	pieceStore := piecestore.NewMockPieceStore(ctrl)
+	pieceStore.EXPECT().PutPiece(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, key string, value []byte) error {
+			f.mux.Lock()
+			defer f.mux.Unlock()
+			f.pieces[key] = append([]byte{}, value...)
+			return nil
+		}).AnyTimes()
+	pieceStore.EXPECT().GetPiece(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, key string, offset, limit int64) ([]byte, error) {
+			f.mux.Lock()
+			defer f.mux.Unlock()
+			piece, ok := f.pieces[key]
+			if !ok {
+				return nil, errors.New("no such key")
+			}
+			if limit < 0 {
+				return piece[offset:], nil
+			}
+			return piece[offset : offset+limit], nil
+		}).AnyTimes()
+	db := corespdb.NewMockSPDB(ctrl)
+	db.EXPECT().SetObjectIntegrity(gomock.Any()).Return(nil).AnyTimes()
+	db.EXPECT().UpdateUploadProgress(gomock.Any()).Return(nil).AnyTimes()
+
+	client := gfspclient.NewMockGfSpClientAPI(ctrl)
+	client.EXPECT().ReportTask(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, report coretask.Task) error {
+		f.reported <- struct{}{}
+		return nil
+	}).AnyTimes()
+	client.EXPECT().SignReceiveTask(gomock.Any(), gomock.Any()).Return([]byte("mockSig"), nil).AnyTimes()
+	client.EXPECT().ReplicatePieceToSecondary(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, endpoint string, receive coretask.ReceivePieceTask, data []byte) error {
+			if !bytes.Equal(hash.GenerateChecksum(data), receive.GetPieceChecksum()) {
+				return errors.New("mismatched piece checksum")
+			}
+			f.mux.Lock()
+			defer f.mux.Unlock()
+			f.replicated[endpoint] = append([]byte{}, data...)
+			return nil
+		}).AnyTimes()
+	client.EXPECT().DoneReplicatePieceToSecondary(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		f.doneReplicatePiece).AnyTimes()
+	client.EXPECT().GetGlobalVirtualGroupByGvgID(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, gvgID uint32, opts ...grpc.DialOption) (*virtualgrouptypes.GlobalVirtualGroup, error) {
+			return f.chain.QueryGlobalVirtualGroup(ctx, gvgID)
+		}).AnyTimes()
+	client.EXPECT().SealObject(gomock.Any(), gomock.Any()).DoAndReturn(f.chain.Signer(1).SealObject).AnyTimes()
+
+	_, operator := flowSP(1, 1)
+	f.app.SetConsensus(f.chain)
+	f.app.SetChainID(f.chain.ChainID())
+	f.app.SetOperatorAddress(operator)
+	f.app.SetPieceOp(&gfsppieceop.GfSpPieceOp{})
+	f.app.SetPieceStore(pieceStore)
+	f.app.SetGfSpDB(db)
+	f.app.SetGfSpClient(client)
+	return f
+}
+
+// doneReplicatePiece checks the integrity hash of the piece replicated to the secondary SP, and signs the seal of
+// the object by its bls key.
+func (f *replicateFlow) doneReplicatePiece(ctx context.Context, endpoint string, receive coretask.ReceivePieceTask) (
+	[]byte, error,
+) {
+	f.mux.Lock()
+	piece, ok := f.replicated[endpoint]
+	key := f.blsKeys[endpoint]
+	f.mux.Unlock()
+	if !ok {
+		return nil, errors.New("no replicated piece")
+	}
+	checksums := receive.GetObjectInfo().GetChecksums()
+	if !bytes.Equal(hash.GenerateIntegrityHash([][]byte{hash.GenerateChecksum(piece)}),
+		checksums[receive.GetRedundancyIdx()+1]) {
+		return nil, errors.New("mismatched integrity hash")
+	}
+	signDoc := storagetypes.NewSecondarySpSealObjectSignDoc(f.chain.ChainID(), receive.GetGlobalVirtualGroupId(),
+		receive.GetObjectInfo().Id, storagetypes.GenerateHash(checksums)).GetBlsSignHash()
+	signature, err := key.Sign(signDoc[:], votepool.DST)
+	if err != nil {
+		return nil, err
+	}
+	return signature.Marshal()
+}
+
+// createObject creates the object of the payload on the chain with its integrity hashes.
+func (f *replicateFlow) createObject(t *testing.T, name string, payload []byte) *storagetypes.ObjectInfo {
+	t.Helper()
+	ecPieces, err := redundancy.EncodeRawSegment(payload, int(f.params.VersionedParams.GetRedundantDataChunkNum()),
+		int(f.params.VersionedParams.GetRedundantParityChunkNum()))
+	assert.Nil(t, err)
+	checksums := [][]byte{hash.GenerateIntegrityHash([][]byte{hash.GenerateChecksum(payload)})}
+	for _, piece := range ecPieces {
+		checksums = append(checksums, hash.GenerateIntegrityHash([][]byte{hash.GenerateChecksum(piece)}))
+	}
+	object, err := f.chain.CreateObject("bucket", name, uint64(len(payload)), checksums)
+	assert.Nil(t, err)
+	return object
+}
+
+func (f *replicateFlow) replicateTask(t *testing.T, object *storagetypes.ObjectInfo) *gfsptask.GfSpReplicatePieceTask {
+	t.Helper()
+	gvg, err := f.chain.QueryGlobalVirtualGroup(context.Background(), f.gvgID)
+	assert.Nil(t, err)
+	task := &gfsptask.GfSpReplicatePieceTask{}
+	task.InitReplicatePieceTask(object, f.params, coretask.DefaultLargerTaskPriority, 60, 1, false)
+	task.GlobalVirtualGroupId = f.gvgID
+	for _, spID := range gvg.GetSecondarySpIds() {
+		endpoint, _ := flowSP(int(spID), 0)
+		task.SecondaryEndpoints = append(task.SecondaryEndpoints, endpoint)
+	}
+	return task
+}
+
+func (f *replicateFlow) executor(t *testing.T) *ExecuteModular {
+	t.Helper()
+	cfg := &gfspconfig.GfSpConfig{}
+	cfg.Executor.ListenSealRetryTimeout = 1
+	module, err := NewExecuteModular(f.app, cfg)
+	assert.Nil(t, err)
+	e := module.(*ExecuteModular)
+	e.spMap = make(map[uint32]*sptypes.StorageProvider)
+	return e
+}
+
+// TestUploadReplicateSealDownloadFlow uploads the object to the uploader of the primary SP, replicates and seals it by
+// the executor, and downloads it from the downloader. The seal is checked against the bls signatures of the
+// secondary SPs by both the executor and the simulated chain.
+func TestUploadReplicateSealDownloadFlow(t *testing.T) {
+	f := setupReplicateFlow(t)
+	ctx := context.Background()
+	payload := make([]byte, 1000)
+	for i := range payload {
+		payload[i] = byte(i)
+	}
+	object := f.createObject(t, "object", payload)
+
+	upload, err := uploader.NewUploadModular(f.app, &gfspconfig.GfSpConfig{
+		Customize: &gfspconfig.Customize{NewStrategyTQueueFunc: gfsptqueue.NewGfSpTQueue},
+	})
+	assert.Nil(t, err)
+	uploadTask := &gfsptask.GfSpUploadObjectTask{}
+	uploadTask.InitUploadObjectTask(f.bucket.GetGlobalVirtualGroupFamilyId(), object, f.params, 60, false)
+	err = upload.(*uploader.UploadModular).HandleUploadObjectTask(ctx, uploadTask, bytes.NewReader(payload))
+	assert.Nil(t, err)
+	<-f.reported
+
+	replicateTask := f.replicateTask(t, object)
+	f.executor(t).HandleReplicatePieceTask(ctx, replicateTask)
+	assert.Nil(t, replicateTask.Error())
+	assert.True(t, replicateTask.GetSealed())
+	sealed, err := f.chain.QueryObjectInfo(ctx, "bucket", "object")
+	assert.Nil(t, err)
+	assert.Equal(t, storagetypes.OBJECT_STATUS_SEALED, sealed.GetObjectStatus())
+	gvgID, ok := f.chain.ObjectGlobalVirtualGroup(object.Id.Uint64())
+	assert.True(t, ok)
+	assert.Equal(t, f.gvgID, gvgID)
+
+	// the payload is recovered by the pieces of any data chunk number of secondary SPs
+	dataChunks := int(f.params.VersionedParams.GetRedundantDataChunkNum())
+	parityChunks := int(f.params.VersionedParams.GetRedundantParityChunkNum())
+	ecPieces := make([][]byte, dataChunks+parityChunks)
+	for i, endpoint := range replicateTask.GetSecondaryEndpoints() {
+		if i >= parityChunks {
+			ecPieces[i] = f.replicated[endpoint]
+		}
+	}
+	recovered, err := redundancy.DecodeRawSegment(ecPieces, int64(len(payload)), dataChunks, parityChunks)
+	assert.Nil(t, err)
+	assert.Equal(t, payload, recovered)
+
+	download, err := downloader.NewDownloadModular(f.app, &gfspconfig.GfSpConfig{})
+	assert.Nil(t, err)
+	downloadTask := &gfsptask.GfSpDownloadObjectTask{}
+	downloadTask.InitDownloadObjectTask(sealed, f.bucket, f.params, coretask.DefaultSmallerPriority, flowBucketOwner,
+		0, int64(len(payload)-1), 60, 1)
+	data, err := download.(*downloader.DownloadModular).HandleDownloadObjectTask(ctx, downloadTask)
+	assert.Nil(t, err)
+	assert.Equal(t, payload, data)
+}
+
+func TestReplicateFlowInvalidSecondarySignature(t *testing.T) {
+	f := setupReplicateFlow(t)
+	ctx := context.Background()
+	payload := []byte("payload")
+	object := f.createObject(t, "object", payload)
+	assert.Nil(t, f.app.PieceStore().PutPiece(ctx, f.app.PieceOp().SegmentPieceKey(object.Id.Uint64(), 0, 0), payload))
+
+	// the secondary SP signs the seal by a key unknown to the chain
+	endpoint, _ := flowSP(4, 0)
+	key, err := bls.GenerateBlsKey()
+	assert.Nil(t, err)
+	f.blsKeys[endpoint] = key
+
+	replicateTask := f.replicateTask(t, object)
+	f.executor(t).HandleReplicatePieceTask(ctx, replicateTask)
+	assert.Equal(t, ErrInvalidSecondaryBlsSignature, replicateTask.Error())
+	assert.False(t, replicateTask.GetSealed())
+	info, err := f.chain.QueryObjectInfo(ctx, "bucket", "object")
+	assert.Nil(t, err)
+	assert.Equal(t, storagetypes.OBJECT_STATUS_CREATED, info.GetObjectStatus())
+}
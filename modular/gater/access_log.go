@@ -0,0 +1,122 @@
+package gater
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/accesslog"
+	mwhttp "github.com/zkMeLabs/mechain-storage-provider/pkg/middleware/http"
+)
+
+// MaxRequestIDLength defines the max length of the request id passed by the client, the longer one is
+// replaced by a generated request id.
+const MaxRequestIDLength = 64
+
+// statusResponseWriter records the status code and the bytes of the response for the middlewares.
+type statusResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	bytes       int64
+}
+
+func (w *statusResponseWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.status = code
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusResponseWriter) Write(data []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(data)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush supports the event stream handlers.
+func (w *statusResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap supports http.ResponseController.
+func (w *statusResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// accessLogBody records the bytes of the request body read by the handler.
+type accessLogBody struct {
+	io.ReadCloser
+	bytes int64
+}
+
+func (b *accessLogBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.bytes += int64(n)
+	return n, err
+}
+
+// accessLogMiddleware writes one access log record per request and observes the per-bucket and per-account
+// usage metrics by the record. The account and the error code are filled by the RequestContext of the
+// handler, and the request id is sent back to the client so that the record can be found by it.
+func (g *GateModular) accessLogMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		startTime := time.Now()
+		requestID := r.Header.Get(GnfdRequestIDHeader)
+		if requestID == "" || len(requestID) > MaxRequestIDLength {
+			requestID = newRequestID()
+		}
+		w.Header().Set(GnfdRequestIDHeader, requestID)
+
+		vars := mux.Vars(r)
+		record := &accesslog.Record{
+			Time:      startTime,
+			RequestID: requestID,
+			RemoteIP:  mwhttp.GetIP(r),
+			Method:    r.Method,
+			Host:      r.Host,
+			Path:      r.URL.Path,
+			Bucket:    vars["bucket"],
+			Object:    vars["object"],
+			UserAgent: r.UserAgent(),
+		}
+		if route := mux.CurrentRoute(r); route != nil {
+			record.Route = route.GetName()
+		}
+		if spanCtx := trace.SpanContextFromContext(r.Context()); spanCtx.IsValid() {
+			record.TraceID = spanCtx.TraceID().String()
+		}
+		body := &accessLogBody{ReadCloser: r.Body}
+		r.Body = body
+		writer := &statusResponseWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(writer, r.WithContext(accesslog.WithRecord(r.Context(), record)))
+
+		record.Status = writer.status
+		record.BytesIn = body.bytes
+		record.BytesOut = writer.bytes
+		record.LatencyMs = time.Since(startTime).Milliseconds()
+		if g.accessLogger != nil {
+			g.accessLogger.Log(record)
+		}
+		if g.bucketUsage != nil {
+			failed := record.Status >= http.StatusBadRequest
+			g.bucketUsage.Observe(record.Bucket, record.BytesIn, record.BytesOut, failed)
+			g.accountUsage.Observe(record.Account, record.BytesIn, record.BytesOut, failed)
+		}
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
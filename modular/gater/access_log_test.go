@@ -0,0 +1,103 @@
+package gater
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/accesslog"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/metrics/topk"
+)
+
+func TestGateModular_accessLogMiddleware(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "access.log")
+	accessLogger, err := accesslog.NewLogger(accesslog.Config{Sink: accesslog.FileSink, FilePath: filePath})
+	assert.Nil(t, err)
+	g := setup(t)
+	g.accessLogger = accessLogger
+	router := mux.NewRouter().SkipClean(true)
+	router.Use(g.accessLogMiddleware)
+	router.Path("/{bucket}/{object:.+}").Name(putObjectRouterName).HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCtx := &RequestContext{accessRecord: accesslog.FromContext(r.Context())}
+		reqCtx.accessRecord.Account = "0x01"
+		reqCtx.SetError(ErrNoAccountRateLimit)
+		_, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("not found"))
+	})
+
+	// the request id of the client is kept
+	req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/%s/%s", mockBucketName, mockObjectName), strings.NewReader("payload"))
+	req.Header.Set(GnfdRequestIDHeader, "request-id")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "request-id", w.Header().Get(GnfdRequestIDHeader))
+
+	// the request id is generated if it is absent or too long
+	req = httptest.NewRequest(http.MethodPut, fmt.Sprintf("/%s/%s", mockBucketName, mockObjectName), nil)
+	req.Header.Set(GnfdRequestIDHeader, strings.Repeat("a", MaxRequestIDLength+1))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 32, len(w.Header().Get(GnfdRequestIDHeader)))
+	assert.Nil(t, accessLogger.Close())
+
+	data, err := os.ReadFile(filePath)
+	assert.Nil(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Equal(t, 2, len(lines))
+	record := &accesslog.Record{}
+	assert.Nil(t, json.Unmarshal([]byte(lines[0]), record))
+	assert.Equal(t, "request-id", record.RequestID)
+	assert.Equal(t, putObjectRouterName, record.Route)
+	assert.Equal(t, "0x01", record.Account)
+	assert.Equal(t, mockBucketName, record.Bucket)
+	assert.Equal(t, mockObjectName, record.Object)
+	assert.Equal(t, http.StatusNotFound, record.Status)
+	assert.Equal(t, ErrNoAccountRateLimit.GetInnerCode(), record.ErrorCode)
+	assert.Equal(t, int64(len("payload")), record.BytesIn)
+	assert.Equal(t, int64(len("not found")), record.BytesOut)
+}
+
+func TestGateModular_accessLogMiddlewareUsageMetrics(t *testing.T) {
+	g := setup(t)
+	g.bucketUsage = topk.NewUsageTracker("bucket", 10)
+	g.accountUsage = topk.NewUsageTracker("account", 10)
+	router := mux.NewRouter().SkipClean(true)
+	router.Use(g.accessLogMiddleware)
+	router.Path("/{bucket}/{object:.+}").Name(getObjectRouterName).HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCtx := &RequestContext{accessRecord: accesslog.FromContext(r.Context())}
+		reqCtx.accessRecord.Account = "0x01"
+		_, _ = w.Write([]byte("payload"))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/%s/%s", mockBucketName, mockObjectName), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(g.bucketUsage, g.accountUsage)
+	expected := fmt.Sprintf(`
+# HELP top_account_requests Track the estimated number of the requests of the heaviest accounts.
+# TYPE top_account_requests gauge
+top_account_requests{account="0x01"} 1
+top_account_requests{account="_other"} 0
+# HELP top_bucket_download_bytes Track the estimated bytes downloaded by the heaviest buckets.
+# TYPE top_bucket_download_bytes gauge
+top_bucket_download_bytes{bucket="%s"} 7
+top_bucket_download_bytes{bucket="_other"} 0
+`, mockBucketName)
+	assert.Nil(t, testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"top_account_requests", "top_bucket_download_bytes"))
+}
@@ -0,0 +1,286 @@
+package gater
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/zkMeLabs/mechain-storage-provider/base/types/gfsperrors"
+	"github.com/zkMeLabs/mechain-storage-provider/core/spdb"
+	modelgateway "github.com/zkMeLabs/mechain-storage-provider/model/gateway"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/metrics"
+	mwhttp "github.com/zkMeLabs/mechain-storage-provider/pkg/middleware/http"
+)
+
+const (
+	// MaxAccountRateLimitBodySize defines the max size of the account rate limit request body.
+	MaxAccountRateLimitBodySize = 4 * 1024
+	// MaxAccountRateLimitReasonLength defines the max length of the reason of the account rate limit.
+	MaxAccountRateLimitReasonLength = 256
+)
+
+// AccountRateLimit is the request and response body of the account rate limit api. The zero rate limit
+// uses the default of the config. The override expires after DurationSecond, the zero DurationSecond means
+// it never expires.
+type AccountRateLimit struct {
+	XMLName               xml.Name `xml:"AccountRateLimit"`
+	Account               string   `xml:"Account,omitempty"`
+	ReadRateLimit         int64    `xml:"ReadRateLimit"`
+	WriteRateLimit        int64    `xml:"WriteRateLimit"`
+	Banned                bool     `xml:"Banned"`
+	Reason                string   `xml:"Reason,omitempty"`
+	DurationSecond        int64    `xml:"DurationSecond,omitempty"`
+	ExpireTimestampSecond int64    `xml:"ExpireTimestampSecond,omitempty"`
+	UpdateTimestampSecond int64    `xml:"UpdateTimestampSecond,omitempty"`
+}
+
+// AccountRateLimits is the response body of listing the account rate limits.
+type AccountRateLimits struct {
+	XMLName xml.Name            `xml:"AccountRateLimits"`
+	Limits  []*AccountRateLimit `xml:"AccountRateLimit"`
+}
+
+// putAccountRateLimitHandler handles the request of throttling or banning the account, only the sp operator
+// and the admin accounts of the config are allowed.
+func (g *GateModular) putAccountRateLimitHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err     error
+		reqCtx  *RequestContext
+		account string
+		body    []byte
+	)
+	startTime := time.Now()
+	defer func() {
+		reqCtx.Cancel()
+		if err != nil {
+			reqCtx.SetError(gfsperrors.MakeGfSpError(err))
+			reqCtx.SetHTTPCode(int(gfsperrors.MakeGfSpError(err).GetHttpStatusCode()))
+			modelgateway.MakeErrorResponse(w, gfsperrors.MakeGfSpError(err))
+			metrics.ReqCounter.WithLabelValues(GatewayTotalFailure).Inc()
+			metrics.ReqTime.WithLabelValues(GatewayTotalFailure).Observe(time.Since(startTime).Seconds())
+		} else {
+			reqCtx.SetHTTPCode(http.StatusOK)
+			metrics.ReqCounter.WithLabelValues(GatewayTotalSuccess).Inc()
+			metrics.ReqTime.WithLabelValues(GatewayTotalSuccess).Observe(time.Since(startTime).Seconds())
+		}
+		log.CtxDebugw(reqCtx.Context(), reqCtx.String())
+	}()
+
+	reqCtx, err = NewRequestContext(r, g)
+	if err != nil {
+		return
+	}
+	if account, err = g.checkAccountRateLimitRequest(reqCtx); err != nil {
+		return
+	}
+	body, err = io.ReadAll(io.LimitReader(r.Body, MaxAccountRateLimitBodySize))
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to read account rate limit body", "error", err)
+		err = ErrExceptionStream
+		return
+	}
+	req := &AccountRateLimit{}
+	if err = xml.Unmarshal(body, req); err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to unmarshal account rate limit", "error", err)
+		err = ErrDecodeMsg
+		return
+	}
+	if req.ReadRateLimit < 0 || req.WriteRateLimit < 0 || req.DurationSecond < 0 {
+		err = ErrInvalidAccountRateLimitWithDetail("the rate limits and the duration must not be negative")
+		return
+	}
+	if len(req.Reason) > MaxAccountRateLimitReasonLength {
+		err = ErrInvalidAccountRateLimitWithDetail("the reason is too long")
+		return
+	}
+
+	now := time.Now().Unix()
+	limit := &spdb.AccountRateLimit{
+		Account:               account,
+		ReadRateLimit:         req.ReadRateLimit,
+		WriteRateLimit:        req.WriteRateLimit,
+		Banned:                req.Banned,
+		Reason:                req.Reason,
+		UpdateTimestampSecond: now,
+	}
+	if req.DurationSecond > 0 {
+		limit.ExpireTimestampSecond = now + req.DurationSecond
+	}
+	if err = g.baseApp.GfSpDB().SetAccountRateLimit(limit); err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to set account rate limit", "error", err)
+		return
+	}
+	g.accountLimiter.Invalidate(account)
+	log.CtxInfow(reqCtx.Context(), "succeed to set account rate limit", "admin", reqCtx.Account(), "account", account,
+		"read_rate_limit", limit.ReadRateLimit, "write_rate_limit", limit.WriteRateLimit, "banned", limit.Banned,
+		"reason", limit.Reason, "expire_timestamp_second", limit.ExpireTimestampSecond)
+}
+
+// getAccountRateLimitHandler handles the request of querying the rate limit override of the account, all the
+// overrides are listed if the account is not specified.
+func (g *GateModular) getAccountRateLimitHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err     error
+		reqCtx  *RequestContext
+		account string
+		limits  []*spdb.AccountRateLimit
+		b       []byte
+	)
+	startTime := time.Now()
+	defer func() {
+		reqCtx.Cancel()
+		if err != nil {
+			reqCtx.SetError(gfsperrors.MakeGfSpError(err))
+			reqCtx.SetHTTPCode(int(gfsperrors.MakeGfSpError(err).GetHttpStatusCode()))
+			modelgateway.MakeErrorResponse(w, gfsperrors.MakeGfSpError(err))
+			metrics.ReqCounter.WithLabelValues(GatewayTotalFailure).Inc()
+			metrics.ReqTime.WithLabelValues(GatewayTotalFailure).Observe(time.Since(startTime).Seconds())
+		} else {
+			reqCtx.SetHTTPCode(http.StatusOK)
+			metrics.ReqCounter.WithLabelValues(GatewayTotalSuccess).Inc()
+			metrics.ReqTime.WithLabelValues(GatewayTotalSuccess).Observe(time.Since(startTime).Seconds())
+		}
+		log.CtxDebugw(reqCtx.Context(), reqCtx.String())
+	}()
+
+	reqCtx, err = NewRequestContext(r, g)
+	if err != nil {
+		return
+	}
+	if r.URL.Query().Get(AccountQuery) == "" {
+		if err = g.checkAccountLimitAdmin(reqCtx); err != nil {
+			return
+		}
+		if limits, err = g.baseApp.GfSpDB().ListAccountRateLimits(); err != nil {
+			log.CtxErrorw(reqCtx.Context(), "failed to list account rate limits", "error", err)
+			return
+		}
+		resp := &AccountRateLimits{}
+		for _, limit := range limits {
+			resp.Limits = append(resp.Limits, toAccountRateLimitResponse(limit))
+		}
+		b, err = xml.Marshal(resp)
+	} else {
+		if account, err = g.checkAccountRateLimitRequest(reqCtx); err != nil {
+			return
+		}
+		var limit *spdb.AccountRateLimit
+		if limit, err = g.baseApp.GfSpDB().GetAccountRateLimit(account); err != nil {
+			log.CtxErrorw(reqCtx.Context(), "failed to get account rate limit", "error", err)
+			return
+		}
+		if limit == nil {
+			err = ErrNoAccountRateLimit
+			return
+		}
+		b, err = xml.Marshal(toAccountRateLimitResponse(limit))
+	}
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to marshal account rate limit", "error", err)
+		err = ErrEncodeResponseWithDetail("failed to marshal account rate limit, error: " + err.Error())
+		return
+	}
+	w.Header().Set(ContentTypeHeader, ContentTypeXMLHeaderValue)
+	if _, err = w.Write(b); err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to write account rate limit", "error", err)
+	}
+}
+
+// deleteAccountRateLimitHandler handles the request of lifting the rate limit override of the account.
+func (g *GateModular) deleteAccountRateLimitHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err     error
+		reqCtx  *RequestContext
+		account string
+	)
+	startTime := time.Now()
+	defer func() {
+		reqCtx.Cancel()
+		if err != nil {
+			reqCtx.SetError(gfsperrors.MakeGfSpError(err))
+			reqCtx.SetHTTPCode(int(gfsperrors.MakeGfSpError(err).GetHttpStatusCode()))
+			modelgateway.MakeErrorResponse(w, gfsperrors.MakeGfSpError(err))
+			metrics.ReqCounter.WithLabelValues(GatewayTotalFailure).Inc()
+			metrics.ReqTime.WithLabelValues(GatewayTotalFailure).Observe(time.Since(startTime).Seconds())
+		} else {
+			reqCtx.SetHTTPCode(http.StatusOK)
+			metrics.ReqCounter.WithLabelValues(GatewayTotalSuccess).Inc()
+			metrics.ReqTime.WithLabelValues(GatewayTotalSuccess).Observe(time.Since(startTime).Seconds())
+		}
+		log.CtxDebugw(reqCtx.Context(), reqCtx.String())
+	}()
+
+	reqCtx, err = NewRequestContext(r, g)
+	if err != nil {
+		return
+	}
+	if account, err = g.checkAccountRateLimitRequest(reqCtx); err != nil {
+		return
+	}
+	if err = g.baseApp.GfSpDB().DeleteAccountRateLimit(account); err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to delete account rate limit", "error", err)
+		return
+	}
+	g.accountLimiter.Invalidate(account)
+	log.CtxInfow(reqCtx.Context(), "succeed to delete account rate limit", "admin", reqCtx.Account(), "account", account)
+}
+
+// checkAccountRateLimitRequest checks the request signer is the admin and returns the lower-case account of the query.
+func (g *GateModular) checkAccountRateLimitRequest(reqCtx *RequestContext) (string, error) {
+	if err := g.checkAccountLimitAdmin(reqCtx); err != nil {
+		return "", err
+	}
+	account := reqCtx.request.URL.Query().Get(AccountQuery)
+	if !common.IsHexAddress(account) {
+		log.CtxErrorw(reqCtx.Context(), "failed to check account", "account", account)
+		return "", ErrInvalidAccountRateLimitWithDetail("invalid account: " + account)
+	}
+	return strings.ToLower(account), nil
+}
+
+func (g *GateModular) checkAccountLimitAdmin(reqCtx *RequestContext) error {
+	if g.accountLimiter == nil {
+		return ErrAccountLimitDisabled
+	}
+	if !g.isAccountLimitAdmin(reqCtx.Account()) {
+		log.CtxErrorw(reqCtx.Context(), "no permission to manage account rate limit", "account", reqCtx.Account())
+		return ErrNoPermission
+	}
+	return nil
+}
+
+// isAccountLimitAdmin returns whether the account is the sp operator or the admin account of the config.
+func (g *GateModular) isAccountLimitAdmin(account string) bool {
+	return strings.EqualFold(account, g.baseApp.OperatorAddress()) || g.accountLimiter.IsAdmin(account)
+}
+
+// loadAccountRateLimit loads the account rate limit override from the sp db for the account limiter.
+func (g *GateModular) loadAccountRateLimit(account string) (*mwhttp.AccountLimitOverride, error) {
+	limit, err := g.baseApp.GfSpDB().GetAccountRateLimit(account)
+	if err != nil || limit == nil {
+		return nil, err
+	}
+	return &mwhttp.AccountLimitOverride{
+		ReadRateLimit:         int(limit.ReadRateLimit),
+		WriteRateLimit:        int(limit.WriteRateLimit),
+		Banned:                limit.Banned,
+		ExpireTimestampSecond: limit.ExpireTimestampSecond,
+	}, nil
+}
+
+func toAccountRateLimitResponse(limit *spdb.AccountRateLimit) *AccountRateLimit {
+	return &AccountRateLimit{
+		Account:               limit.Account,
+		ReadRateLimit:         limit.ReadRateLimit,
+		WriteRateLimit:        limit.WriteRateLimit,
+		Banned:                limit.Banned,
+		Reason:                limit.Reason,
+		ExpireTimestampSecond: limit.ExpireTimestampSecond,
+		UpdateTimestampSecond: limit.UpdateTimestampSecond,
+	}
+}
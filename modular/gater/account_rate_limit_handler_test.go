@@ -0,0 +1,303 @@
+package gater
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	commonhttp "github.com/zkMeLabs/mechain-common/go/http"
+	"github.com/zkMeLabs/mechain-storage-provider/base/gfspclient"
+	"github.com/zkMeLabs/mechain-storage-provider/core/spdb"
+	mwhttp "github.com/zkMeLabs/mechain-storage-provider/pkg/middleware/http"
+)
+
+const (
+	mockAccountRateLimitAdmin   = "0x76d244CE05c3De4BbC6fDd7F56379B145709ade9"
+	mockAccountRateLimitAccount = "0x2Ff2A6d7C3a6b2A1e8f3b1eE0cC2a4D5b6C7d8E9"
+	mockAccountRateLimitBody    = "<AccountRateLimit><ReadRateLimit>10</ReadRateLimit><Banned>true</Banned>" +
+		"<Reason>abuse</Reason><DurationSecond>3600</DurationSecond></AccountRateLimit>"
+)
+
+func mockAccountRateLimitHandlerRoute(t *testing.T, g *GateModular) *mux.Router {
+	t.Helper()
+	router := mux.NewRouter().SkipClean(true)
+	router.Path(AccountRateLimitPath).Name(putAccountRateLimitRouterName).Methods(http.MethodPut).HandlerFunc(g.putAccountRateLimitHandler)
+	router.Path(AccountRateLimitPath).Name(getAccountRateLimitRouterName).Methods(http.MethodGet).HandlerFunc(g.getAccountRateLimitHandler)
+	router.Path(AccountRateLimitPath).Name(deleteAccountRateLimitRouterName).Methods(http.MethodDelete).HandlerFunc(g.deleteAccountRateLimitHandler)
+	return router
+}
+
+func mockAccountRateLimitRequest(method, signer, account, body string) *http.Request {
+	path := scheme + testDomain + AccountRateLimitPath
+	if account != "" {
+		path += "?" + AccountQuery + "=" + account
+	}
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	validExpiryDateStr := time.Now().Add(time.Hour * 60).Format(ExpiryDateFormat)
+	req.Header.Set(commonhttp.HTTPHeaderExpiryTimestamp, validExpiryDateStr)
+	req.Header.Set(GnfdAuthorizationHeader, "GNFD1-EDDSA,Signature=48656c6c6f20476f7068657221")
+	req.Header.Set(GnfdUserAddressHeader, signer)
+	return req
+}
+
+func mockAccountRateLimitGateModular(t *testing.T, enable bool, db spdb.SPDB) *GateModular {
+	g := setup(t)
+	ctrl := gomock.NewController(t)
+	clientMock := gfspclient.NewMockGfSpClientAPI(ctrl)
+	clientMock.EXPECT().VerifyGNFD1EddsaSignature(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
+		gomock.Any()).Return(true, nil).Times(1)
+	g.baseApp.SetGfSpClient(clientMock)
+	g.baseApp.SetGfSpDB(db)
+	if enable {
+		cfg := mwhttp.AccountLimitConfig{On: true, ReadRateLimit: 100, WriteRateLimit: 100, AdminAccounts: []string{mockAccountRateLimitAdmin}}
+		accountLimiter, err := mwhttp.NewAccountLimiter(cfg, mwhttp.RateLimiterStoreConfig{}, g.loadAccountRateLimit)
+		assert.Nil(t, err)
+		g.accountLimiter = accountLimiter
+	}
+	return g
+}
+
+func TestGateModular_putAccountRateLimitHandler(t *testing.T) {
+	cases := []struct {
+		name         string
+		fn           func() *GateModular
+		request      func() *http.Request
+		wantedCode   int
+		wantedResult string
+	}{
+		{
+			name: "account rate limit is disabled",
+			fn: func() *GateModular {
+				return mockAccountRateLimitGateModular(t, false, nil)
+			},
+			request: func() *http.Request {
+				return mockAccountRateLimitRequest(http.MethodPut, mockAccountRateLimitAdmin, mockAccountRateLimitAccount, mockAccountRateLimitBody)
+			},
+			wantedCode:   http.StatusNotImplemented,
+			wantedResult: "account rate limit is not enabled",
+		},
+		{
+			name: "signer is not the admin",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				dbMock := spdb.NewMockSPDB(ctrl)
+				dbMock.EXPECT().GetAccountRateLimit(gomock.Any()).Return(nil, nil).Times(1)
+				return mockAccountRateLimitGateModular(t, true, dbMock)
+			},
+			request: func() *http.Request {
+				return mockAccountRateLimitRequest(http.MethodPut, mockAccountRateLimitAccount, mockAccountRateLimitAccount, mockAccountRateLimitBody)
+			},
+			wantedCode:   http.StatusUnauthorized,
+			wantedResult: "no permission",
+		},
+		{
+			name: "signer is banned",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				dbMock := spdb.NewMockSPDB(ctrl)
+				dbMock.EXPECT().GetAccountRateLimit(strings.ToLower(mockAccountRateLimitAccount)).Return(
+					&spdb.AccountRateLimit{Banned: true}, nil).Times(1)
+				return mockAccountRateLimitGateModular(t, true, dbMock)
+			},
+			request: func() *http.Request {
+				return mockAccountRateLimitRequest(http.MethodPut, mockAccountRateLimitAccount, mockAccountRateLimitAccount, mockAccountRateLimitBody)
+			},
+			wantedCode:   http.StatusForbidden,
+			wantedResult: "the account is banned",
+		},
+		{
+			name: "invalid account",
+			fn: func() *GateModular {
+				return mockAccountRateLimitGateModular(t, true, nil)
+			},
+			request: func() *http.Request {
+				return mockAccountRateLimitRequest(http.MethodPut, mockAccountRateLimitAdmin, "0x01", mockAccountRateLimitBody)
+			},
+			wantedCode:   http.StatusBadRequest,
+			wantedResult: "invalid account",
+		},
+		{
+			name: "failed to unmarshal body",
+			fn: func() *GateModular {
+				return mockAccountRateLimitGateModular(t, true, nil)
+			},
+			request: func() *http.Request {
+				return mockAccountRateLimitRequest(http.MethodPut, mockAccountRateLimitAdmin, mockAccountRateLimitAccount, "<AccountRateLimit>")
+			},
+			wantedCode:   http.StatusBadRequest,
+			wantedResult: "gnfd msg decoding error",
+		},
+		{
+			name: "negative rate limit",
+			fn: func() *GateModular {
+				return mockAccountRateLimitGateModular(t, true, nil)
+			},
+			request: func() *http.Request {
+				return mockAccountRateLimitRequest(http.MethodPut, mockAccountRateLimitAdmin, mockAccountRateLimitAccount,
+					"<AccountRateLimit><ReadRateLimit>-1</ReadRateLimit></AccountRateLimit>")
+			},
+			wantedCode:   http.StatusBadRequest,
+			wantedResult: "must not be negative",
+		},
+		{
+			name: "failed to set account rate limit",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				dbMock := spdb.NewMockSPDB(ctrl)
+				dbMock.EXPECT().SetAccountRateLimit(gomock.Any()).Return(mockErr).Times(1)
+				return mockAccountRateLimitGateModular(t, true, dbMock)
+			},
+			request: func() *http.Request {
+				return mockAccountRateLimitRequest(http.MethodPut, mockAccountRateLimitAdmin, mockAccountRateLimitAccount, mockAccountRateLimitBody)
+			},
+			wantedCode:   http.StatusInternalServerError,
+			wantedResult: "mock error",
+		},
+		{
+			name: "success",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				dbMock := spdb.NewMockSPDB(ctrl)
+				dbMock.EXPECT().SetAccountRateLimit(gomock.Any()).DoAndReturn(func(limit *spdb.AccountRateLimit) error {
+					assert.Equal(t, strings.ToLower(mockAccountRateLimitAccount), limit.Account)
+					assert.Equal(t, int64(10), limit.ReadRateLimit)
+					assert.True(t, limit.Banned)
+					assert.Equal(t, "abuse", limit.Reason)
+					assert.Equal(t, limit.UpdateTimestampSecond+3600, limit.ExpireTimestampSecond)
+					return nil
+				}).Times(1)
+				return mockAccountRateLimitGateModular(t, true, dbMock)
+			},
+			request: func() *http.Request {
+				return mockAccountRateLimitRequest(http.MethodPut, mockAccountRateLimitAdmin, mockAccountRateLimitAccount, mockAccountRateLimitBody)
+			},
+			wantedCode:   http.StatusOK,
+			wantedResult: "",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			router := mockAccountRateLimitHandlerRoute(t, tt.fn())
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, tt.request())
+			assert.Equal(t, tt.wantedCode, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantedResult)
+		})
+	}
+}
+
+func TestGateModular_getAccountRateLimitHandler(t *testing.T) {
+	cases := []struct {
+		name         string
+		fn           func() *GateModular
+		account      string
+		wantedCode   int
+		wantedResult string
+	}{
+		{
+			name: "failed to list account rate limits",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				dbMock := spdb.NewMockSPDB(ctrl)
+				dbMock.EXPECT().ListAccountRateLimits().Return(nil, mockErr).Times(1)
+				return mockAccountRateLimitGateModular(t, true, dbMock)
+			},
+			wantedCode:   http.StatusInternalServerError,
+			wantedResult: "mock error",
+		},
+		{
+			name: "list account rate limits",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				dbMock := spdb.NewMockSPDB(ctrl)
+				dbMock.EXPECT().ListAccountRateLimits().Return([]*spdb.AccountRateLimit{
+					{Account: "0x01", Banned: true}, {Account: "0x02", ReadRateLimit: 5}}, nil).Times(1)
+				return mockAccountRateLimitGateModular(t, true, dbMock)
+			},
+			wantedCode: http.StatusOK,
+			wantedResult: "<AccountRateLimits><AccountRateLimit><Account>0x01</Account><ReadRateLimit>0</ReadRateLimit>" +
+				"<WriteRateLimit>0</WriteRateLimit><Banned>true</Banned></AccountRateLimit>",
+		},
+		{
+			name: "no account rate limit",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				dbMock := spdb.NewMockSPDB(ctrl)
+				dbMock.EXPECT().GetAccountRateLimit(strings.ToLower(mockAccountRateLimitAccount)).Return(nil, nil).Times(1)
+				return mockAccountRateLimitGateModular(t, true, dbMock)
+			},
+			account:      mockAccountRateLimitAccount,
+			wantedCode:   http.StatusNotFound,
+			wantedResult: "the account has no rate limit override",
+		},
+		{
+			name: "get account rate limit",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				dbMock := spdb.NewMockSPDB(ctrl)
+				dbMock.EXPECT().GetAccountRateLimit(strings.ToLower(mockAccountRateLimitAccount)).Return(
+					&spdb.AccountRateLimit{Account: strings.ToLower(mockAccountRateLimitAccount), WriteRateLimit: 5, Reason: "abuse"}, nil).Times(1)
+				return mockAccountRateLimitGateModular(t, true, dbMock)
+			},
+			account:      mockAccountRateLimitAccount,
+			wantedCode:   http.StatusOK,
+			wantedResult: "<WriteRateLimit>5</WriteRateLimit><Banned>false</Banned><Reason>abuse</Reason>",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			router := mockAccountRateLimitHandlerRoute(t, tt.fn())
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, mockAccountRateLimitRequest(http.MethodGet, mockAccountRateLimitAdmin, tt.account, ""))
+			assert.Equal(t, tt.wantedCode, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantedResult)
+		})
+	}
+}
+
+func TestGateModular_deleteAccountRateLimitHandler(t *testing.T) {
+	cases := []struct {
+		name         string
+		fn           func() *GateModular
+		wantedCode   int
+		wantedResult string
+	}{
+		{
+			name: "failed to delete account rate limit",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				dbMock := spdb.NewMockSPDB(ctrl)
+				dbMock.EXPECT().DeleteAccountRateLimit(gomock.Any()).Return(mockErr).Times(1)
+				return mockAccountRateLimitGateModular(t, true, dbMock)
+			},
+			wantedCode:   http.StatusInternalServerError,
+			wantedResult: "mock error",
+		},
+		{
+			name: "success",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				dbMock := spdb.NewMockSPDB(ctrl)
+				dbMock.EXPECT().DeleteAccountRateLimit(strings.ToLower(mockAccountRateLimitAccount)).Return(nil).Times(1)
+				return mockAccountRateLimitGateModular(t, true, dbMock)
+			},
+			wantedCode:   http.StatusOK,
+			wantedResult: "",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			router := mockAccountRateLimitHandlerRoute(t, tt.fn())
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, mockAccountRateLimitRequest(http.MethodDelete, mockAccountRateLimitAdmin, mockAccountRateLimitAccount, ""))
+			assert.Equal(t, tt.wantedCode, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantedResult)
+		})
+	}
+}
@@ -0,0 +1,295 @@
+package gater
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"time"
+
+	storagetypes "github.com/evmos/evmos/v12/x/storage/types"
+	"github.com/gorilla/mux"
+
+	"github.com/zkMeLabs/mechain-storage-provider/base/types/gfsperrors"
+	"github.com/zkMeLabs/mechain-storage-provider/core/spdb"
+	modelgateway "github.com/zkMeLabs/mechain-storage-provider/model/gateway"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/cors"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/metrics"
+)
+
+// MaxBucketCORSBodySize defines the max size of the bucket cors request body.
+const MaxBucketCORSBodySize = 64 * 1024
+
+// CORSConfiguration is the request and response body of the bucket cors api, it is compatible with S3.
+type CORSConfiguration struct {
+	XMLName   xml.Name    `xml:"CORSConfiguration"`
+	CORSRules []*CORSRule `xml:"CORSRule"`
+}
+
+// CORSRule defines the cross-origin requests which are allowed by the bucket.
+type CORSRule struct {
+	AllowedOrigins []string `xml:"AllowedOrigin"`
+	AllowedMethods []string `xml:"AllowedMethod"`
+	AllowedHeaders []string `xml:"AllowedHeader,omitempty"`
+	ExposeHeaders  []string `xml:"ExposeHeader,omitempty"`
+	MaxAgeSeconds  int64    `xml:"MaxAgeSeconds,omitempty"`
+}
+
+// putBucketCORSHandler handles the request of setting the cors configuration of the bucket, only the
+// bucket owner is allowed. The configuration is stored in the sp db, so it only takes effect on this sp.
+func (g *GateModular) putBucketCORSHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err    error
+		reqCtx *RequestContext
+		body   []byte
+	)
+	startTime := time.Now()
+	defer func() {
+		reqCtx.Cancel()
+		if err != nil {
+			reqCtx.SetError(gfsperrors.MakeGfSpError(err))
+			reqCtx.SetHTTPCode(int(gfsperrors.MakeGfSpError(err).GetHttpStatusCode()))
+			modelgateway.MakeErrorResponse(w, gfsperrors.MakeGfSpError(err))
+			metrics.ReqCounter.WithLabelValues(GatewayTotalFailure).Inc()
+			metrics.ReqTime.WithLabelValues(GatewayTotalFailure).Observe(time.Since(startTime).Seconds())
+		} else {
+			reqCtx.SetHTTPCode(http.StatusOK)
+			metrics.ReqCounter.WithLabelValues(GatewayTotalSuccess).Inc()
+			metrics.ReqTime.WithLabelValues(GatewayTotalSuccess).Observe(time.Since(startTime).Seconds())
+		}
+		log.CtxDebugw(reqCtx.Context(), reqCtx.String())
+	}()
+
+	reqCtx, err = NewRequestContext(r, g)
+	if err != nil {
+		return
+	}
+	if g.corsCache == nil {
+		err = ErrBucketCORSDisabled
+		return
+	}
+	if err = g.checkBucketOwner(reqCtx); err != nil {
+		return
+	}
+	body, err = io.ReadAll(io.LimitReader(r.Body, MaxBucketCORSBodySize))
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to read bucket cors body", "error", err)
+		err = ErrExceptionStream
+		return
+	}
+	req := &CORSConfiguration{}
+	if err = xml.Unmarshal(body, req); err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to unmarshal bucket cors", "error", err)
+		err = ErrDecodeMsg
+		return
+	}
+	config := &cors.Config{}
+	rules := make([]*spdb.BucketCORSRule, 0, len(req.CORSRules))
+	for _, rule := range req.CORSRules {
+		config.Rules = append(config.Rules, (*cors.Rule)(rule))
+		rules = append(rules, (*spdb.BucketCORSRule)(rule))
+	}
+	if err = config.Validate(); err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to check bucket cors", "error", err)
+		err = ErrInvalidCORSConfigWithDetail("invalid cors configuration, error: " + err.Error())
+		return
+	}
+	if err = g.baseApp.GfSpDB().SetBucketCORS(&spdb.BucketCORS{
+		BucketName:            reqCtx.bucketName,
+		Rules:                 rules,
+		UpdateTimestampSecond: time.Now().Unix(),
+	}); err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to set bucket cors", "error", err)
+		return
+	}
+	g.corsCache.Invalidate(reqCtx.bucketName)
+	log.CtxInfow(reqCtx.Context(), "succeed to set bucket cors", "bucket_name", reqCtx.bucketName, "rules", len(rules))
+}
+
+// getBucketCORSHandler handles the request of querying the cors configuration of the bucket, only the
+// bucket owner is allowed.
+func (g *GateModular) getBucketCORSHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err        error
+		reqCtx     *RequestContext
+		bucketCORS *spdb.BucketCORS
+		b          []byte
+	)
+	startTime := time.Now()
+	defer func() {
+		reqCtx.Cancel()
+		if err != nil {
+			reqCtx.SetError(gfsperrors.MakeGfSpError(err))
+			reqCtx.SetHTTPCode(int(gfsperrors.MakeGfSpError(err).GetHttpStatusCode()))
+			modelgateway.MakeErrorResponse(w, gfsperrors.MakeGfSpError(err))
+			metrics.ReqCounter.WithLabelValues(GatewayTotalFailure).Inc()
+			metrics.ReqTime.WithLabelValues(GatewayTotalFailure).Observe(time.Since(startTime).Seconds())
+		} else {
+			reqCtx.SetHTTPCode(http.StatusOK)
+			metrics.ReqCounter.WithLabelValues(GatewayTotalSuccess).Inc()
+			metrics.ReqTime.WithLabelValues(GatewayTotalSuccess).Observe(time.Since(startTime).Seconds())
+		}
+		log.CtxDebugw(reqCtx.Context(), reqCtx.String())
+	}()
+
+	reqCtx, err = NewRequestContext(r, g)
+	if err != nil {
+		return
+	}
+	if g.corsCache == nil {
+		err = ErrBucketCORSDisabled
+		return
+	}
+	if err = g.checkBucketOwner(reqCtx); err != nil {
+		return
+	}
+	bucketCORS, err = g.baseApp.GfSpDB().GetBucketCORS(reqCtx.bucketName)
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to get bucket cors", "error", err)
+		return
+	}
+	if bucketCORS == nil {
+		err = ErrNoBucketCORS
+		return
+	}
+	resp := &CORSConfiguration{}
+	for _, rule := range bucketCORS.Rules {
+		resp.CORSRules = append(resp.CORSRules, (*CORSRule)(rule))
+	}
+	b, err = xml.Marshal(resp)
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to marshal bucket cors", "error", err)
+		err = ErrEncodeResponseWithDetail("failed to marshal bucket cors, error: " + err.Error())
+		return
+	}
+	w.Header().Set(ContentTypeHeader, ContentTypeXMLHeaderValue)
+	if _, err = w.Write(b); err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to write bucket cors", "error", err)
+	}
+}
+
+// deleteBucketCORSHandler handles the request of deleting the cors configuration of the bucket, only the
+// bucket owner is allowed.
+func (g *GateModular) deleteBucketCORSHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err    error
+		reqCtx *RequestContext
+	)
+	startTime := time.Now()
+	defer func() {
+		reqCtx.Cancel()
+		if err != nil {
+			reqCtx.SetError(gfsperrors.MakeGfSpError(err))
+			reqCtx.SetHTTPCode(int(gfsperrors.MakeGfSpError(err).GetHttpStatusCode()))
+			modelgateway.MakeErrorResponse(w, gfsperrors.MakeGfSpError(err))
+			metrics.ReqCounter.WithLabelValues(GatewayTotalFailure).Inc()
+			metrics.ReqTime.WithLabelValues(GatewayTotalFailure).Observe(time.Since(startTime).Seconds())
+		} else {
+			reqCtx.SetHTTPCode(http.StatusOK)
+			metrics.ReqCounter.WithLabelValues(GatewayTotalSuccess).Inc()
+			metrics.ReqTime.WithLabelValues(GatewayTotalSuccess).Observe(time.Since(startTime).Seconds())
+		}
+		log.CtxDebugw(reqCtx.Context(), reqCtx.String())
+	}()
+
+	reqCtx, err = NewRequestContext(r, g)
+	if err != nil {
+		return
+	}
+	if g.corsCache == nil {
+		err = ErrBucketCORSDisabled
+		return
+	}
+	if err = g.checkBucketOwner(reqCtx); err != nil {
+		return
+	}
+	if err = g.baseApp.GfSpDB().DeleteBucketCORS(reqCtx.bucketName); err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to delete bucket cors", "error", err)
+		return
+	}
+	g.corsCache.Invalidate(reqCtx.bucketName)
+	log.CtxInfow(reqCtx.Context(), "succeed to delete bucket cors", "bucket_name", reqCtx.bucketName)
+}
+
+// checkBucketOwner checks the request signer is the owner of the bucket on the chain.
+func (g *GateModular) checkBucketOwner(reqCtx *RequestContext) error {
+	var (
+		err        error
+		bucketInfo *storagetypes.BucketInfo
+	)
+	bucketInfo, err = g.baseApp.Consensus().QueryBucketInfo(reqCtx.Context(), reqCtx.bucketName)
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to get bucket info from consensus", "error", err)
+		return ErrConsensusWithDetail("failed to get bucket info from consensus, bucket_name: " + reqCtx.bucketName + ", error: " + err.Error())
+	}
+	if bucketInfo.GetOwner() != reqCtx.Account() {
+		log.CtxErrorw(reqCtx.Context(), "no permission to operate", "owner", bucketInfo.GetOwner(), "account", reqCtx.Account())
+		return ErrNoPermission
+	}
+	return nil
+}
+
+// corsPreflightHandler returns the handler of the cors preflight request. The bucket is resolved by matching
+// the router with the method of Access-Control-Request-Method, since the preflight request has no signature
+// and the bucket may be in the host or in the path.
+func (g *GateModular) corsPreflightHandler(router *mux.Router) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			origin         = r.Header.Get(cors.OriginHeader)
+			method         = r.Header.Get(cors.RequestMethodHeader)
+			requestHeaders = cors.ParseRequestHeaders(r)
+			match          mux.RouteMatch
+			config         *cors.Config
+			err            error
+		)
+		target := r.Clone(r.Context())
+		target.Method = method
+		if router.Match(target, &match) && match.Vars["bucket"] != "" {
+			config, err = g.corsCache.Get(match.Vars["bucket"])
+			if err != nil {
+				log.Errorw("failed to get bucket cors", "bucket_name", match.Vars["bucket"], "error", err)
+				modelgateway.MakeErrorResponse(w, gfsperrors.MakeGfSpError(err))
+				return
+			}
+		}
+		rule := config.Match(origin, method, requestHeaders)
+		if rule == nil {
+			log.Debugw("cors preflight request is not allowed", "origin", origin, "method", method, "url", r.URL.String())
+			modelgateway.MakeErrorResponse(w, ErrCORSNotAllowed)
+			return
+		}
+		cors.WritePreflightHeaders(w, rule, origin, method, requestHeaders)
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// corsMiddleware writes the cors headers of the actual request if the cors configuration of the bucket
+// allows the origin.
+func (g *GateModular) corsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get(cors.OriginHeader)
+		bucketName := mux.Vars(r)["bucket"]
+		if origin != "" && bucketName != "" {
+			config, err := g.corsCache.Get(bucketName)
+			if err != nil {
+				log.Errorw("failed to get bucket cors", "bucket_name", bucketName, "error", err)
+			} else if rule := config.Match(origin, r.Method, nil); rule != nil {
+				cors.WriteActualHeaders(w, rule, origin)
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// loadBucketCORS loads the cors configuration of the bucket from the sp db.
+func (g *GateModular) loadBucketCORS(bucketName string) (*cors.Config, error) {
+	bucketCORS, err := g.baseApp.GfSpDB().GetBucketCORS(bucketName)
+	if err != nil || bucketCORS == nil {
+		return nil, err
+	}
+	config := &cors.Config{}
+	for _, rule := range bucketCORS.Rules {
+		config.Rules = append(config.Rules, (*cors.Rule)(rule))
+	}
+	return config, nil
+}
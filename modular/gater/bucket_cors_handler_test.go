@@ -0,0 +1,403 @@
+package gater
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	storagetypes "github.com/evmos/evmos/v12/x/storage/types"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	commonhttp "github.com/zkMeLabs/mechain-common/go/http"
+	"github.com/zkMeLabs/mechain-storage-provider/base/gfspclient"
+	"github.com/zkMeLabs/mechain-storage-provider/core/consensus"
+	"github.com/zkMeLabs/mechain-storage-provider/core/spdb"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/cors"
+)
+
+const (
+	mockCORSOwner  = "0x76d244CE05c3De4BbC6fDd7F56379B145709ade9"
+	mockCORSOrigin = "https://app.example.com"
+	mockCORSBody   = "<CORSConfiguration><CORSRule><AllowedOrigin>https://*.example.com</AllowedOrigin>" +
+		"<AllowedMethod>GET</AllowedMethod><AllowedMethod>PUT</AllowedMethod><AllowedHeader>*</AllowedHeader>" +
+		"<ExposeHeader>ETag</ExposeHeader><MaxAgeSeconds>600</MaxAgeSeconds></CORSRule></CORSConfiguration>"
+)
+
+func mockBucketCORS() *spdb.BucketCORS {
+	return &spdb.BucketCORS{
+		BucketName: mockBucketName,
+		Rules: []*spdb.BucketCORSRule{{
+			AllowedOrigins: []string{"https://*.example.com"},
+			AllowedMethods: []string{http.MethodGet, http.MethodPut},
+			AllowedHeaders: []string{"*"},
+			ExposeHeaders:  []string{"ETag"},
+			MaxAgeSeconds:  600,
+		}},
+	}
+}
+
+func mockBucketCORSHandlerRoute(t *testing.T, g *GateModular) *mux.Router {
+	t.Helper()
+	router := mux.NewRouter().SkipClean(true)
+	r := router.PathPrefix("/{bucket}").Subrouter()
+	r.NewRoute().Name(putBucketCORSRouterName).Methods(http.MethodPut).Queries(BucketCORSQuery, "").HandlerFunc(g.putBucketCORSHandler)
+	r.NewRoute().Name(getBucketCORSRouterName).Methods(http.MethodGet).Queries(BucketCORSQuery, "").HandlerFunc(g.getBucketCORSHandler)
+	r.NewRoute().Name(deleteBucketCORSRouterName).Methods(http.MethodDelete).Queries(BucketCORSQuery, "").HandlerFunc(g.deleteBucketCORSHandler)
+	return router
+}
+
+func mockBucketCORSRequest(method, body string) *http.Request {
+	path := fmt.Sprintf("%s%s/%s?%s", scheme, testDomain, mockBucketName, BucketCORSQuery)
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	validExpiryDateStr := time.Now().Add(time.Hour * 60).Format(ExpiryDateFormat)
+	req.Header.Set(commonhttp.HTTPHeaderExpiryTimestamp, validExpiryDateStr)
+	req.Header.Set(GnfdAuthorizationHeader, "GNFD1-EDDSA,Signature=48656c6c6f20476f7068657221")
+	req.Header.Set(GnfdUserAddressHeader, mockCORSOwner)
+	return req
+}
+
+// mockBucketCORSGateModular returns the gateway with the bucket cors enabled if enable is true, the bucket is
+// owned by owner and the sp db is db.
+func mockBucketCORSGateModular(t *testing.T, ctrl *gomock.Controller, enable bool, verifyErr error, owner string,
+	db spdb.SPDB) *GateModular {
+	g := setup(t)
+	clientMock := gfspclient.NewMockGfSpClientAPI(ctrl)
+	clientMock.EXPECT().VerifyGNFD1EddsaSignature(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
+		gomock.Any()).Return(verifyErr == nil, verifyErr).Times(1)
+	g.baseApp.SetGfSpClient(clientMock)
+	consensusMock := consensus.NewMockConsensus(ctrl)
+	if owner != "" {
+		consensusMock.EXPECT().QueryBucketInfo(gomock.Any(), gomock.Any()).Return(
+			&storagetypes.BucketInfo{BucketName: mockBucketName, Owner: owner}, nil).Times(1)
+	}
+	g.baseApp.SetConsensus(consensusMock)
+	g.baseApp.SetGfSpDB(db)
+	if enable {
+		g.corsCache = cors.NewCache(time.Minute, 0, g.loadBucketCORS)
+	}
+	return g
+}
+
+func TestGateModular_putBucketCORSHandler(t *testing.T) {
+	cases := []struct {
+		name         string
+		fn           func(ctrl *gomock.Controller) *GateModular
+		body         string
+		wantedCode   int
+		wantedResult string
+	}{
+		{
+			name: "new request context error",
+			fn: func(ctrl *gomock.Controller) *GateModular {
+				return mockBucketCORSGateModular(t, ctrl, true, mockErr, "", nil)
+			},
+			body:         mockCORSBody,
+			wantedCode:   http.StatusInternalServerError,
+			wantedResult: "mock error",
+		},
+		{
+			name: "bucket cors is disabled",
+			fn: func(ctrl *gomock.Controller) *GateModular {
+				return mockBucketCORSGateModular(t, ctrl, false, nil, "", nil)
+			},
+			body:         mockCORSBody,
+			wantedCode:   http.StatusNotImplemented,
+			wantedResult: "bucket cors is not enabled",
+		},
+		{
+			name: "failed to query bucket info",
+			fn: func(ctrl *gomock.Controller) *GateModular {
+				g := mockBucketCORSGateModular(t, ctrl, true, nil, "", nil)
+				consensusMock := consensus.NewMockConsensus(ctrl)
+				consensusMock.EXPECT().QueryBucketInfo(gomock.Any(), gomock.Any()).Return(nil, mockErr).Times(1)
+				g.baseApp.SetConsensus(consensusMock)
+				return g
+			},
+			body:         mockCORSBody,
+			wantedCode:   http.StatusInternalServerError,
+			wantedResult: "failed to get bucket info from consensus",
+		},
+		{
+			name: "not the bucket owner",
+			fn: func(ctrl *gomock.Controller) *GateModular {
+				return mockBucketCORSGateModular(t, ctrl, true, nil, "0x0000000000000000000000000000000000000001", nil)
+			},
+			body:         mockCORSBody,
+			wantedCode:   http.StatusUnauthorized,
+			wantedResult: "no permission",
+		},
+		{
+			name: "failed to unmarshal body",
+			fn: func(ctrl *gomock.Controller) *GateModular {
+				return mockBucketCORSGateModular(t, ctrl, true, nil, mockCORSOwner, nil)
+			},
+			body:         "<CORSConfiguration>",
+			wantedCode:   http.StatusBadRequest,
+			wantedResult: "gnfd msg decoding error",
+		},
+		{
+			name: "invalid cors configuration",
+			fn: func(ctrl *gomock.Controller) *GateModular {
+				return mockBucketCORSGateModular(t, ctrl, true, nil, mockCORSOwner, nil)
+			},
+			body:         strings.Replace(mockCORSBody, "<AllowedMethod>GET</AllowedMethod>", "<AllowedMethod>TRACE</AllowedMethod>", 1),
+			wantedCode:   http.StatusBadRequest,
+			wantedResult: "unsupported allowed method TRACE",
+		},
+		{
+			name: "failed to set bucket cors",
+			fn: func(ctrl *gomock.Controller) *GateModular {
+				dbMock := spdb.NewMockSPDB(ctrl)
+				dbMock.EXPECT().SetBucketCORS(gomock.Any()).Return(mockErr).Times(1)
+				return mockBucketCORSGateModular(t, ctrl, true, nil, mockCORSOwner, dbMock)
+			},
+			body:         mockCORSBody,
+			wantedCode:   http.StatusInternalServerError,
+			wantedResult: "mock error",
+		},
+		{
+			name: "success",
+			fn: func(ctrl *gomock.Controller) *GateModular {
+				dbMock := spdb.NewMockSPDB(ctrl)
+				dbMock.EXPECT().SetBucketCORS(gomock.Any()).DoAndReturn(func(bucketCORS *spdb.BucketCORS) error {
+					want := mockBucketCORS()
+					assert.Equal(t, want.BucketName, bucketCORS.BucketName)
+					assert.Equal(t, want.Rules, bucketCORS.Rules)
+					return nil
+				}).Times(1)
+				return mockBucketCORSGateModular(t, ctrl, true, nil, mockCORSOwner, dbMock)
+			},
+			body:       mockCORSBody,
+			wantedCode: http.StatusOK,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			g := tt.fn(ctrl)
+			w := httptest.NewRecorder()
+			mockBucketCORSHandlerRoute(t, g).ServeHTTP(w, mockBucketCORSRequest(http.MethodPut, tt.body))
+			assert.Equal(t, tt.wantedCode, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantedResult)
+		})
+	}
+}
+
+func TestGateModular_getBucketCORSHandler(t *testing.T) {
+	cases := []struct {
+		name         string
+		fn           func(ctrl *gomock.Controller) *GateModular
+		wantedCode   int
+		wantedResult string
+	}{
+		{
+			name: "bucket cors is disabled",
+			fn: func(ctrl *gomock.Controller) *GateModular {
+				return mockBucketCORSGateModular(t, ctrl, false, nil, "", nil)
+			},
+			wantedCode:   http.StatusNotImplemented,
+			wantedResult: "bucket cors is not enabled",
+		},
+		{
+			name: "failed to get bucket cors",
+			fn: func(ctrl *gomock.Controller) *GateModular {
+				dbMock := spdb.NewMockSPDB(ctrl)
+				dbMock.EXPECT().GetBucketCORS(mockBucketName).Return(nil, mockErr).Times(1)
+				return mockBucketCORSGateModular(t, ctrl, true, nil, mockCORSOwner, dbMock)
+			},
+			wantedCode:   http.StatusInternalServerError,
+			wantedResult: "mock error",
+		},
+		{
+			name: "no bucket cors",
+			fn: func(ctrl *gomock.Controller) *GateModular {
+				dbMock := spdb.NewMockSPDB(ctrl)
+				dbMock.EXPECT().GetBucketCORS(mockBucketName).Return(nil, nil).Times(1)
+				return mockBucketCORSGateModular(t, ctrl, true, nil, mockCORSOwner, dbMock)
+			},
+			wantedCode:   http.StatusNotFound,
+			wantedResult: "the bucket has no cors configuration",
+		},
+		{
+			name: "success",
+			fn: func(ctrl *gomock.Controller) *GateModular {
+				dbMock := spdb.NewMockSPDB(ctrl)
+				dbMock.EXPECT().GetBucketCORS(mockBucketName).Return(mockBucketCORS(), nil).Times(1)
+				return mockBucketCORSGateModular(t, ctrl, true, nil, mockCORSOwner, dbMock)
+			},
+			wantedCode:   http.StatusOK,
+			wantedResult: mockCORSBody,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			g := tt.fn(ctrl)
+			w := httptest.NewRecorder()
+			mockBucketCORSHandlerRoute(t, g).ServeHTTP(w, mockBucketCORSRequest(http.MethodGet, ""))
+			assert.Equal(t, tt.wantedCode, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantedResult)
+		})
+	}
+}
+
+func TestGateModular_deleteBucketCORSHandler(t *testing.T) {
+	cases := []struct {
+		name         string
+		fn           func(ctrl *gomock.Controller) *GateModular
+		wantedCode   int
+		wantedResult string
+	}{
+		{
+			name: "not the bucket owner",
+			fn: func(ctrl *gomock.Controller) *GateModular {
+				return mockBucketCORSGateModular(t, ctrl, true, nil, "0x0000000000000000000000000000000000000001", nil)
+			},
+			wantedCode:   http.StatusUnauthorized,
+			wantedResult: "no permission",
+		},
+		{
+			name: "failed to delete bucket cors",
+			fn: func(ctrl *gomock.Controller) *GateModular {
+				dbMock := spdb.NewMockSPDB(ctrl)
+				dbMock.EXPECT().DeleteBucketCORS(mockBucketName).Return(mockErr).Times(1)
+				return mockBucketCORSGateModular(t, ctrl, true, nil, mockCORSOwner, dbMock)
+			},
+			wantedCode:   http.StatusInternalServerError,
+			wantedResult: "mock error",
+		},
+		{
+			name: "success",
+			fn: func(ctrl *gomock.Controller) *GateModular {
+				dbMock := spdb.NewMockSPDB(ctrl)
+				dbMock.EXPECT().DeleteBucketCORS(mockBucketName).Return(nil).Times(1)
+				return mockBucketCORSGateModular(t, ctrl, true, nil, mockCORSOwner, dbMock)
+			},
+			wantedCode: http.StatusOK,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			g := tt.fn(ctrl)
+			w := httptest.NewRecorder()
+			mockBucketCORSHandlerRoute(t, g).ServeHTTP(w, mockBucketCORSRequest(http.MethodDelete, ""))
+			assert.Equal(t, tt.wantedCode, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantedResult)
+		})
+	}
+}
+
+func TestGateModular_corsPreflightHandler(t *testing.T) {
+	cases := []struct {
+		name          string
+		url           string
+		method        string
+		headers       string
+		wantedCode    int
+		wantedHeaders map[string]string
+	}{
+		{
+			name:       "allowed path style preflight",
+			url:        fmt.Sprintf("%s%s/%s/%s", scheme, testDomain, mockBucketName, mockObjectName),
+			method:     http.MethodPut,
+			headers:    "Content-Type, X-Gnfd-Txn-Hash",
+			wantedCode: http.StatusOK,
+			wantedHeaders: map[string]string{
+				cors.AllowOriginHeader:   mockCORSOrigin,
+				cors.AllowMethodsHeader:  http.MethodPut,
+				cors.AllowHeadersHeader:  "content-type, x-gnfd-txn-hash",
+				cors.ExposeHeadersHeader: "ETag",
+				cors.MaxAgeHeader:        "600",
+			},
+		},
+		{
+			name:       "allowed virtual host style preflight",
+			url:        fmt.Sprintf("%s%s.%s/%s", scheme, mockBucketName, testDomain, mockObjectName),
+			method:     http.MethodGet,
+			wantedCode: http.StatusOK,
+			wantedHeaders: map[string]string{
+				cors.AllowOriginHeader:  mockCORSOrigin,
+				cors.AllowMethodsHeader: http.MethodGet,
+			},
+		},
+		{
+			name:       "method is not allowed",
+			url:        fmt.Sprintf("%s%s/%s/%s", scheme, testDomain, mockBucketName, mockObjectName),
+			method:     http.MethodPost,
+			wantedCode: http.StatusForbidden,
+		},
+		{
+			name:       "no bucket in the request",
+			url:        scheme + testDomain + WebhookSubscriptionPath,
+			method:     http.MethodPut,
+			wantedCode: http.StatusForbidden,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			dbMock := spdb.NewMockSPDB(ctrl)
+			dbMock.EXPECT().GetBucketCORS(mockBucketName).Return(mockBucketCORS(), nil).MaxTimes(1)
+			g := setup(t)
+			g.baseApp.SetGfSpDB(dbMock)
+			g.corsCache = cors.NewCache(time.Minute, 0, g.loadBucketCORS)
+			router := mux.NewRouter().SkipClean(true)
+			g.RegisterHandler(router)
+
+			req := httptest.NewRequest(http.MethodOptions, tt.url, nil)
+			req.Header.Set(cors.OriginHeader, mockCORSOrigin)
+			req.Header.Set(cors.RequestMethodHeader, tt.method)
+			if tt.headers != "" {
+				req.Header.Set(cors.RequestHeadersHeader, tt.headers)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantedCode, w.Code)
+			for k, v := range tt.wantedHeaders {
+				assert.Equal(t, v, w.Header().Get(k))
+			}
+			if tt.wantedCode != http.StatusOK {
+				assert.Empty(t, w.Header().Get(cors.AllowOriginHeader))
+			}
+		})
+	}
+}
+
+func TestGateModular_corsMiddleware(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dbMock := spdb.NewMockSPDB(ctrl)
+	dbMock.EXPECT().GetBucketCORS(mockBucketName).Return(mockBucketCORS(), nil).Times(1)
+	g := setup(t)
+	g.baseApp.SetGfSpDB(dbMock)
+	g.corsCache = cors.NewCache(time.Minute, 0, g.loadBucketCORS)
+	router := mux.NewRouter().SkipClean(true)
+	router.Use(g.corsMiddleware)
+	router.Path("/{bucket}/{object:.+}").HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	// the allowed origin, the config is loaded once and cached
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/%s/%s", mockBucketName, mockObjectName), nil)
+		req.Header.Set(cors.OriginHeader, mockCORSOrigin)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, mockCORSOrigin, w.Header().Get(cors.AllowOriginHeader))
+		assert.Equal(t, "ETag", w.Header().Get(cors.ExposeHeadersHeader))
+		assert.Equal(t, cors.OriginHeader, w.Header().Get(cors.VaryHeader))
+	}
+
+	// the disallowed origin
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/%s/%s", mockBucketName, mockObjectName), nil)
+	req.Header.Set(cors.OriginHeader, "https://evil.com")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get(cors.AllowOriginHeader))
+}
@@ -20,6 +20,10 @@ const (
 	ContentTypeJSONHeaderValue = "application/json"
 	// ContentTypeXMLHeaderValue is used to indicate xml
 	ContentTypeXMLHeaderValue = "application/xml"
+	// ContentTypeEventStreamHeaderValue is used to indicate server-sent events
+	ContentTypeEventStreamHeaderValue = "text/event-stream"
+	// CacheControlHeader is used to indicate the caching directives
+	CacheControlHeader = "Cache-Control"
 	// ContentDispositionHeader is used to indicate the media disposition of the resource
 	ContentDispositionHeader = "Content-Disposition"
 	// ContentDispositionAttachmentValue is used to indicate attachment
@@ -40,6 +44,14 @@ const (
 	ActionQuery = "action"
 	// UploadProgressQuery defines upload progress query, which is used to route request
 	UploadProgressQuery = "upload-progress"
+	// UploadProgressStreamQuery defines upload progress stream query, which is used to route the server-sent events request
+	UploadProgressStreamQuery = "upload-progress-stream"
+	// UploadFromURLQuery defines upload from url query, which is used to route the request of uploading the payload from a source url
+	UploadFromURLQuery = "upload-from-url"
+	// BucketCORSQuery defines bucket cors query, which is used to route the request of managing the cors configuration of the bucket
+	BucketCORSQuery = "cors"
+	// AccountQuery defines account query, which is used to specify the account of the rate limit override
+	AccountQuery = "account"
 	// UploadContextQuery defines an upload context query, which is used to route request, it includes upload offset,
 	UploadContextQuery      = "upload-context"
 	ResumableUploadComplete = "complete"
@@ -325,6 +337,12 @@ const (
 	objectSpecialSuffixUrlReplacement = "?" + UniversalEndpointSpecialSuffixQuery + "="
 	// StatusPath defines the path for sp status
 	StatusPath = "/status"
+	// WebhookSubscriptionPath defines the path for bucket owner to manage the webhook subscription
+	WebhookSubscriptionPath = "/mechain/webhook/v1/subscription"
+	// TusUploadPath defines the path of the tus resumable upload protocol, the upload url is TusUploadPath/{bucket}/{object}
+	TusUploadPath = "/mechain/tus/v1/"
+	// AccountRateLimitPath defines the path for the admin to throttle or ban the accounts
+	AccountRateLimitPath = "/mechain/admin/v1/account-rate-limit"
 )
 
 const (
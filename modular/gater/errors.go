@@ -61,8 +61,55 @@ var (
 	// 3. Contains "\": May indicate an attempt at illegal path or file operations, especially in Windows systems.
 	// 4. Fails SQL Injection Test (util.IsSQLInjection): Object name contains patterns that might be used for SQL injection, like ';select', 'xxx;insert', etc., or SQL comment patterns.
 	ErrInvalidObjectName = gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 50044, "invalid object name")
+
+	ErrWebhookDisabled       = gfsperrors.Register(module.GateModularName, http.StatusNotImplemented, 50045, "webhook is not enabled")
+	ErrInvalidWebhookURL     = gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 50046, "invalid webhook url, only http and https are supported")
+	ErrInvalidWebhookEvent   = gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 50047, "invalid webhook event type")
+	ErrNoWebhookSubscription = gfsperrors.Register(module.GateModularName, http.StatusNotFound, 50048, "no webhook subscription")
+	ErrInvalidWebhookSecret  = gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 50049, "invalid webhook secret")
+
+	ErrStreamingUnsupported = gfsperrors.Register(module.GateModularName, http.StatusInternalServerError, 50050, "streaming is not supported")
+
+	ErrTusVersionUnsupported  = gfsperrors.Register(module.GateModularName, http.StatusPreconditionFailed, 50051, "unsupported tus version, only "+TusVersion+" is supported")
+	ErrTusInvalidContentType  = gfsperrors.Register(module.GateModularName, http.StatusUnsupportedMediaType, 50052, "invalid content type, "+ContentTypeOffsetOctetStreamHeaderValue+" is required")
+	ErrTusOffsetMismatch      = gfsperrors.Register(module.GateModularName, http.StatusConflict, 50053, "upload offset does not match the current offset")
+	ErrTusInvalidUploadLength = gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 50054, "upload length does not match the object payload size")
+	ErrTusInvalidMetadata     = gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 50055, "invalid upload metadata, the bucket and object keys are required")
+	ErrTusInvalidChunk        = gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 50056, "invalid chunk, the chunk must be aligned to the segment size unless it is the last one")
+	ErrTusChecksumUnsupported = gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 50057, "unsupported checksum algorithm")
+	ErrTusChecksumMismatch    = gfsperrors.Register(module.GateModularName, TusChecksumMismatchStatusCode, 50058, "checksum mismatch")
+	ErrTusChunkTooLarge       = gfsperrors.Register(module.GateModularName, http.StatusRequestEntityTooLarge, 50059, "chunk is too large to verify the checksum")
+
+	ErrUploadFromURLDisabled = gfsperrors.Register(module.GateModularName, http.StatusNotImplemented, 50060, "upload from url is not enabled")
+	ErrInvalidSourceURL      = gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 50061, "invalid source url, only the public http and https url is supported")
+	ErrUploadFromURLBusy     = gfsperrors.Register(module.GateModularName, http.StatusServiceUnavailable, 50062, "too many concurrent uploads from url, please try again later")
+	ErrSourceSizeMismatch    = gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 50063, "source content length does not match the object payload size")
+	ErrBucketCORSDisabled    = gfsperrors.Register(module.GateModularName, http.StatusNotImplemented, 50065, "bucket cors is not enabled")
+	ErrNoBucketCORS          = gfsperrors.Register(module.GateModularName, http.StatusNotFound, 50066, "the bucket has no cors configuration")
+	ErrCORSNotAllowed        = gfsperrors.Register(module.GateModularName, http.StatusForbidden, 50067, "cors request is not allowed")
+	ErrThumbnailTooLarge     = gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 50070, "the image is too large to render the preview")
+	ErrUnsupportedThumbnail  = gfsperrors.Register(module.GateModularName, http.StatusUnsupportedMediaType, 50071, "unsupported image format, only jpeg, png and gif are supported")
+	ErrThumbnailBusy         = gfsperrors.Register(module.GateModularName, http.StatusServiceUnavailable, 50072, "too many concurrent preview renderings, please try again later")
+	ErrAccountLimitDisabled  = gfsperrors.Register(module.GateModularName, http.StatusNotImplemented, 50073, "account rate limit is not enabled")
+	ErrNoAccountRateLimit    = gfsperrors.Register(module.GateModularName, http.StatusNotFound, 50074, "the account has no rate limit override")
 )
 
+func ErrFetchSourceURLWithDetail(detail string) *gfsperrors.GfSpError {
+	return gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 50064, detail)
+}
+
+func ErrInvalidCORSConfigWithDetail(detail string) *gfsperrors.GfSpError {
+	return gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 50068, detail)
+}
+
+func ErrInvalidThumbnailOptionsWithDetail(detail string) *gfsperrors.GfSpError {
+	return gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 50069, detail)
+}
+
+func ErrInvalidAccountRateLimitWithDetail(detail string) *gfsperrors.GfSpError {
+	return gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 50075, detail)
+}
+
 func ErrEncodeResponseWithDetail(detail string) *gfsperrors.GfSpError {
 	return gfsperrors.Register(module.GateModularName, http.StatusInternalServerError, 50011, detail)
 }
@@ -75,6 +122,10 @@ func ErrNotifySwapOutWithDetail(detail string) *gfsperrors.GfSpError {
 	return gfsperrors.Register(module.GateModularName, http.StatusInternalServerError, 50034, detail)
 }
 
+func ErrWebhookSecretWithDetail(detail string) *gfsperrors.GfSpError {
+	return gfsperrors.Register(module.GateModularName, http.StatusInternalServerError, 50076, detail)
+}
+
 func ErrConsensusWithDetail(detail string) *gfsperrors.GfSpError {
 	return gfsperrors.Register(module.GateModularName, http.StatusInternalServerError, 55001, detail)
 }
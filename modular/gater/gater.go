@@ -10,8 +10,14 @@ import (
 	"github.com/zkMeLabs/mechain-storage-provider/base/gfspapp"
 	"github.com/zkMeLabs/mechain-storage-provider/core/module"
 	"github.com/zkMeLabs/mechain-storage-provider/core/rcmgr"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/accesslog"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/cors"
 	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
 	"github.com/zkMeLabs/mechain-storage-provider/pkg/metrics"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/metrics/topk"
+	mwhttp "github.com/zkMeLabs/mechain-storage-provider/pkg/middleware/http"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/thumbnail"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/urlfetch"
 )
 
 const ReadHeaderTimeout = 20 * time.Minute
@@ -28,6 +34,35 @@ type GateModular struct {
 
 	maxListReadQuota int64
 	maxPayloadSize   uint64
+	enableWebhook    bool
+	// progressPoller polls the upload states for the streams if the hub is not complete
+	progressPoller *uploadProgressPoller
+	// webhookAllowPrivateNetwork allows the webhook endpoints in the private network
+	webhookAllowPrivateNetwork bool
+
+	// urlFetcher is nil if the upload-from-url api is disabled
+	urlFetcher           *urlfetch.Fetcher
+	uploadFromURLSlots   chan struct{}
+	uploadFromURLTimeout time.Duration
+
+	// corsCache is nil if the bucket cors is disabled
+	corsCache *cors.Cache
+
+	// thumbnailCache is nil if the image preview is disabled
+	thumbnailCache         *thumbnail.Cache
+	thumbnailSlots         chan struct{}
+	thumbnailMaxSourceSize uint64
+	thumbnailMaxDimension  int
+
+	// accountLimiter is nil if the account rate limit is disabled
+	accountLimiter *mwhttp.AccountLimiter
+
+	// accessLogger is nil if the access log is disabled
+	accessLogger *accesslog.Logger
+
+	// bucketUsage and accountUsage are nil if the usage metrics are disabled
+	bucketUsage  *topk.UsageTracker
+	accountUsage *topk.UsageTracker
 
 	spID        uint32
 	spCachePool *SPCachePool
@@ -68,6 +103,9 @@ func (g *GateModular) server(ctx context.Context) {
 func (g *GateModular) Stop(ctx context.Context) error {
 	g.scope.Release()
 	_ = g.httpServer.Shutdown(ctx)
+	if g.accessLogger != nil {
+		_ = g.accessLogger.Close()
+	}
 	return nil
 }
 
@@ -2,22 +2,38 @@ package gater
 
 import (
 	"strings"
+	"time"
 
 	"github.com/zkMeLabs/mechain-storage-provider/base/gfspapp"
 	"github.com/zkMeLabs/mechain-storage-provider/base/gfspconfig"
 	coremodule "github.com/zkMeLabs/mechain-storage-provider/core/module"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/accesslog"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/cors"
 	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/metrics"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/metrics/topk"
 	mwhttp "github.com/zkMeLabs/mechain-storage-provider/pkg/middleware/http"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/thumbnail"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/urlfetch"
 )
 
 const (
 	DefaultGatewayDomainName = "localhost:9133"
 	DefaultMaxListReadQuota  = 100
 	DefaultMaxPayloadSize    = 2 * 1024 * 1024 * 1024
+
+	DefaultMaxUploadFromURLNumber     = 16
+	DefaultUploadFromURLTimeoutSecond = 3600
+
+	DefaultBucketCORSCacheSecond = 60
+
+	DefaultThumbnailMaxSourceSize = 32 * 1024 * 1024
+	DefaultThumbnailMaxDimension  = 2048
+	DefaultMaxThumbnailNumber     = 8
 )
 
 func NewGateModular(app *gfspapp.GfSpBaseApp, cfg *gfspconfig.GfSpConfig) (coremodule.Modular, error) {
-	gater := &GateModular{baseApp: app}
+	gater := &GateModular{baseApp: app, progressPoller: newUploadProgressPoller()}
 	if err := defaultGaterOptions(gater, cfg); err != nil {
 		return nil, err
 	}
@@ -42,11 +58,71 @@ func defaultGaterOptions(gater *GateModular, cfg *gfspconfig.GfSpConfig) error {
 	gater.domain = cfg.Gateway.DomainName
 	gater.httpAddress = cfg.Gateway.HTTPAddress
 	gater.maxListReadQuota = cfg.Bucket.MaxListReadQuotaNumber
+	gater.enableWebhook = cfg.Webhook.Enable
+	gater.webhookAllowPrivateNetwork = cfg.Webhook.AllowPrivateNetwork
+	if cfg.Gateway.EnableUploadFromURL {
+		if cfg.Gateway.MaxUploadFromURLNumber == 0 {
+			cfg.Gateway.MaxUploadFromURLNumber = DefaultMaxUploadFromURLNumber
+		}
+		if cfg.Gateway.UploadFromURLTimeoutSecond == 0 {
+			cfg.Gateway.UploadFromURLTimeoutSecond = DefaultUploadFromURLTimeoutSecond
+		}
+		gater.urlFetcher = urlfetch.NewFetcher(urlfetch.Config{AllowPrivateNetwork: cfg.Gateway.UploadFromURLAllowPrivateNetwork})
+		gater.uploadFromURLSlots = make(chan struct{}, cfg.Gateway.MaxUploadFromURLNumber)
+		gater.uploadFromURLTimeout = time.Duration(cfg.Gateway.UploadFromURLTimeoutSecond) * time.Second
+	}
+	if cfg.Gateway.EnableBucketCORS {
+		if cfg.Gateway.BucketCORSCacheSecond == 0 {
+			cfg.Gateway.BucketCORSCacheSecond = DefaultBucketCORSCacheSecond
+		}
+		gater.corsCache = cors.NewCache(time.Duration(cfg.Gateway.BucketCORSCacheSecond)*time.Second, 0, gater.loadBucketCORS)
+	}
+	if cfg.Gateway.EnableThumbnail {
+		if cfg.Gateway.ThumbnailMaxSourceSize == 0 {
+			cfg.Gateway.ThumbnailMaxSourceSize = DefaultThumbnailMaxSourceSize
+		}
+		if cfg.Gateway.ThumbnailMaxDimension == 0 {
+			cfg.Gateway.ThumbnailMaxDimension = DefaultThumbnailMaxDimension
+		}
+		if cfg.Gateway.ThumbnailCacheSize == 0 {
+			cfg.Gateway.ThumbnailCacheSize = thumbnail.DefaultCacheSize
+		}
+		if cfg.Gateway.MaxThumbnailNumber == 0 {
+			cfg.Gateway.MaxThumbnailNumber = DefaultMaxThumbnailNumber
+		}
+		gater.thumbnailCache = thumbnail.NewCache(cfg.Gateway.ThumbnailCacheSize)
+		gater.thumbnailSlots = make(chan struct{}, cfg.Gateway.MaxThumbnailNumber)
+		gater.thumbnailMaxSourceSize = cfg.Gateway.ThumbnailMaxSourceSize
+		gater.thumbnailMaxDimension = cfg.Gateway.ThumbnailMaxDimension
+	}
 	rateCfg := makeAPIRateLimitCfg(cfg.APIRateLimiter)
 	if err := mwhttp.NewAPILimiter(rateCfg); err != nil {
 		log.Errorw("failed to new api limiter", "err", err)
 		return err
 	}
+	if cfg.APIRateLimiter.AccountLimitCfg.On {
+		accountLimiter, err := mwhttp.NewAccountLimiter(cfg.APIRateLimiter.AccountLimitCfg, cfg.APIRateLimiter.Store,
+			gater.loadAccountRateLimit)
+		if err != nil {
+			log.Errorw("failed to new account limiter", "err", err)
+			return err
+		}
+		gater.accountLimiter = accountLimiter
+	}
+	if cfg.AccessLog.Sink != "" {
+		accessLogger, err := accesslog.NewLogger(cfg.AccessLog)
+		if err != nil {
+			log.Errorw("failed to new access logger", "err", err)
+			return err
+		}
+		gater.accessLogger = accessLogger
+	}
+	if cfg.Gateway.EnableUsageMetrics && !cfg.Monitor.DisableMetrics {
+		gater.bucketUsage = topk.NewUsageTracker("bucket", cfg.Gateway.UsageMetricsTopN)
+		gater.accountUsage = topk.NewUsageTracker("account", cfg.Gateway.UsageMetricsTopN)
+		metrics.AddMetrics(gater.bucketUsage)
+		metrics.AddMetrics(gater.accountUsage)
+	}
 	return nil
 }
 
@@ -97,5 +173,6 @@ func makeAPIRateLimitCfg(cfg mwhttp.RateLimiterConfig) *mwhttp.APILimiterConfig
 		HostSequence: hostSequence,
 		APILimits:    apiLimitsMap,
 		IPLimitCfg:   cfg.IPLimitCfg,
+		Store:        cfg.Store,
 	}
 }
@@ -1,12 +1,14 @@
 package gater
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/zkMeLabs/mechain-storage-provider/base/gfspapp"
 	"github.com/zkMeLabs/mechain-storage-provider/base/gfspconfig"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/accesslog"
 	mwhttp "github.com/zkMeLabs/mechain-storage-provider/pkg/middleware/http"
 )
 
@@ -18,6 +20,39 @@ func TestNewGateModularSuccess(t *testing.T) {
 	assert.NotNil(t, result)
 }
 
+func TestNewGateModularWithUploadFromURL(t *testing.T) {
+	app := &gfspapp.GfSpBaseApp{}
+	cfg := &gfspconfig.GfSpConfig{Gateway: gfspconfig.GatewayConfig{EnableUploadFromURL: true}}
+	result, err := NewGateModular(app, cfg)
+	assert.Nil(t, err)
+	g := result.(*GateModular)
+	assert.NotNil(t, g.urlFetcher)
+	assert.Equal(t, DefaultMaxUploadFromURLNumber, cap(g.uploadFromURLSlots))
+	assert.Equal(t, int64(DefaultUploadFromURLTimeoutSecond), cfg.Gateway.UploadFromURLTimeoutSecond)
+}
+
+func TestNewGateModularWithBucketCORS(t *testing.T) {
+	app := &gfspapp.GfSpBaseApp{}
+	cfg := &gfspconfig.GfSpConfig{Gateway: gfspconfig.GatewayConfig{EnableBucketCORS: true}}
+	result, err := NewGateModular(app, cfg)
+	assert.Nil(t, err)
+	g := result.(*GateModular)
+	assert.NotNil(t, g.corsCache)
+	assert.Equal(t, int64(DefaultBucketCORSCacheSecond), cfg.Gateway.BucketCORSCacheSecond)
+}
+
+func TestNewGateModularWithThumbnail(t *testing.T) {
+	app := &gfspapp.GfSpBaseApp{}
+	cfg := &gfspconfig.GfSpConfig{Gateway: gfspconfig.GatewayConfig{EnableThumbnail: true}}
+	result, err := NewGateModular(app, cfg)
+	assert.Nil(t, err)
+	g := result.(*GateModular)
+	assert.NotNil(t, g.thumbnailCache)
+	assert.Equal(t, DefaultMaxThumbnailNumber, cap(g.thumbnailSlots))
+	assert.Equal(t, uint64(DefaultThumbnailMaxSourceSize), g.thumbnailMaxSourceSize)
+	assert.Equal(t, DefaultThumbnailMaxDimension, g.thumbnailMaxDimension)
+}
+
 func TestNewGateModularFailure(t *testing.T) {
 	app := &gfspapp.GfSpBaseApp{}
 	apiLimits := mwhttp.KeyToRateLimiterNameCell{
@@ -82,3 +117,48 @@ func Test_makeAPIRateLimitCfg(t *testing.T) {
 	result := makeAPIRateLimitCfg(cfg)
 	assert.NotNil(t, result)
 }
+
+func TestNewGateModularWithAccountLimit(t *testing.T) {
+	app := &gfspapp.GfSpBaseApp{}
+	cfg := &gfspconfig.GfSpConfig{APIRateLimiter: mwhttp.RateLimiterConfig{
+		AccountLimitCfg: mwhttp.AccountLimitConfig{On: true, ReadRateLimit: 10}}}
+	result, err := NewGateModular(app, cfg)
+	assert.Nil(t, err)
+	g := result.(*GateModular)
+	assert.NotNil(t, g.accountLimiter)
+
+	cfg.APIRateLimiter.AccountLimitCfg.RatePeriod = "X"
+	_, err = NewGateModular(app, cfg)
+	assert.NotNil(t, err)
+}
+
+func TestNewGateModularWithAccessLog(t *testing.T) {
+	app := &gfspapp.GfSpBaseApp{}
+	cfg := &gfspconfig.GfSpConfig{AccessLog: accesslog.Config{Sink: accesslog.FileSink,
+		FilePath: filepath.Join(t.TempDir(), "access.log")}}
+	result, err := NewGateModular(app, cfg)
+	assert.Nil(t, err)
+	g := result.(*GateModular)
+	assert.NotNil(t, g.accessLogger)
+	assert.Nil(t, g.accessLogger.Close())
+
+	cfg.AccessLog.Sink = "kafka"
+	_, err = NewGateModular(app, cfg)
+	assert.NotNil(t, err)
+}
+
+func TestNewGateModularWithUsageMetrics(t *testing.T) {
+	app := &gfspapp.GfSpBaseApp{}
+	cfg := &gfspconfig.GfSpConfig{Gateway: gfspconfig.GatewayConfig{EnableUsageMetrics: true}}
+	cfg.Monitor.DisableMetrics = true
+	result, err := NewGateModular(app, cfg)
+	assert.Nil(t, err)
+	assert.Nil(t, result.(*GateModular).bucketUsage)
+
+	cfg.Monitor.DisableMetrics = false
+	result, err = NewGateModular(app, cfg)
+	assert.Nil(t, err)
+	g := result.(*GateModular)
+	assert.NotNil(t, g.bucketUsage)
+	assert.NotNil(t, g.accountUsage)
+}
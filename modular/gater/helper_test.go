@@ -24,5 +24,7 @@ func setup(t *testing.T) *GateModular {
 		env:     gfspapp.EnvLocal,
 		domain:  testDomain,
 		baseApp: &gfspapp.GfSpBaseApp{},
+
+		progressPoller: newUploadProgressPoller(),
 	}
 }
@@ -1,6 +1,7 @@
 package gater
 
 import (
+	"context"
 	"encoding/hex"
 	"encoding/json"
 	"encoding/xml"
@@ -28,6 +29,7 @@ import (
 	"github.com/zkMeLabs/mechain-storage-provider/modular/metadata"
 	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
 	"github.com/zkMeLabs/mechain-storage-provider/pkg/metrics"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/thumbnail"
 	"github.com/zkMeLabs/mechain-storage-provider/store/sqldb"
 	servicetypes "github.com/zkMeLabs/mechain-storage-provider/store/types"
 	"github.com/zkMeLabs/mechain-storage-provider/util"
@@ -533,6 +535,14 @@ func (g *GateModular) getObjectHandler(w http.ResponseWriter, r *http.Request) {
 
 	} // else anonymous users can get public object.
 
+	if thumbnailOpts != nil {
+		if err = g.viewThumbnail(w, reqCtx, objectInfo, thumbnailOpts); err != nil {
+			return
+		}
+		log.CtxDebugw(reqCtx.Context(), "succeed to view object preview for universal endpoint", "options", thumbnailOpts.String())
+		return
+	}
+
 	// do the actual download
 	err = g.downloadObject(w, reqCtx)
 	if err != nil {
@@ -777,25 +787,9 @@ func (g *GateModular) queryUploadProgressHandler(w http.ResponseWriter, r *http.
 		err = ErrConsensusWithDetail("failed to get object info from consensus, object_name: " + reqCtx.objectName + ", bucket_name: " + reqCtx.bucketName + " ,error: " + err.Error())
 		return
 	}
-	if objectInfo.GetObjectStatus() == storagetypes.OBJECT_STATUS_CREATED {
-		taskState, errDescription, err = g.baseApp.GfSpClient().GetUploadObjectState(reqCtx.Context(), objectInfo.Id.Uint64())
-		if err != nil {
-			log.CtxErrorw(reqCtx.Context(), "failed to get uploading job state", "error", err)
-			if !strings.Contains(err.Error(), "no uploading record") {
-				return
-			}
-			taskState = int32(servicetypes.TaskState_TASK_STATE_INIT_UNSPECIFIED)
-			taskStateDescription = servicetypes.StateToDescription(servicetypes.TaskState(taskState))
-			err = nil
-		} else {
-			taskStateDescription = servicetypes.StateToDescription(servicetypes.TaskState(taskState))
-		}
-	} else if objectInfo.GetObjectStatus() == storagetypes.OBJECT_STATUS_SEALED && !objectInfo.GetIsUpdating() {
-		taskState = int32(servicetypes.TaskState_TASK_STATE_SEAL_OBJECT_DONE)
-		taskStateDescription = servicetypes.StateToDescription(servicetypes.TaskState(taskState))
-	} else if objectInfo.GetObjectStatus() == storagetypes.OBJECT_STATUS_DISCONTINUED {
-		taskState = int32(servicetypes.TaskState_TASK_STATE_OBJECT_DISCONTINUED)
-		taskStateDescription = servicetypes.StateToDescription(servicetypes.TaskState(taskState))
+	taskState, taskStateDescription, errDescription, err = g.getUploadProgressState(reqCtx.Context(), objectInfo)
+	if err != nil {
+		return
 	}
 
 	xmlInfo := struct {
@@ -823,6 +817,31 @@ func (g *GateModular) queryUploadProgressHandler(w http.ResponseWriter, r *http.
 	log.Debugw("succeed to query upload progress", "xml_info", xmlInfo)
 }
 
+// getUploadProgressState returns the upload task state of the object, the uploading state is queried
+// from the manager if the object is not sealed yet, otherwise it is derived from the object status.
+func (g *GateModular) getUploadProgressState(ctx context.Context, objectInfo *storagetypes.ObjectInfo) (
+	taskState int32, taskStateDescription string, errDescription string, err error) {
+	if objectInfo.GetObjectStatus() == storagetypes.OBJECT_STATUS_CREATED {
+		taskState, errDescription, err = g.baseApp.GfSpClient().GetUploadObjectState(ctx, objectInfo.Id.Uint64())
+		if err != nil {
+			log.CtxErrorw(ctx, "failed to get uploading job state", "error", err)
+			if !strings.Contains(err.Error(), "no uploading record") {
+				return 0, "", "", err
+			}
+			taskState = int32(servicetypes.TaskState_TASK_STATE_INIT_UNSPECIFIED)
+			err = nil
+		}
+		taskStateDescription = servicetypes.StateToDescription(servicetypes.TaskState(taskState))
+	} else if objectInfo.GetObjectStatus() == storagetypes.OBJECT_STATUS_SEALED && !objectInfo.GetIsUpdating() {
+		taskState = int32(servicetypes.TaskState_TASK_STATE_SEAL_OBJECT_DONE)
+		taskStateDescription = servicetypes.StateToDescription(servicetypes.TaskState(taskState))
+	} else if objectInfo.GetObjectStatus() == storagetypes.OBJECT_STATUS_DISCONTINUED {
+		taskState = int32(servicetypes.TaskState_TASK_STATE_OBJECT_DISCONTINUED)
+		taskStateDescription = servicetypes.StateToDescription(servicetypes.TaskState(taskState))
+	}
+	return taskState, taskStateDescription, errDescription, nil
+}
+
 // getObjectByUniversalEndpointHandler handles the get object request sent by universal endpoint
 func (g *GateModular) getObjectByUniversalEndpointHandler(w http.ResponseWriter, r *http.Request, isDownload bool) {
 	var (
@@ -835,6 +854,7 @@ func (g *GateModular) getObjectByUniversalEndpointHandler(w http.ResponseWriter,
 		isRequestFromBrowser bool
 		spEndpoint           string
 		getEndpointErr       error
+		thumbnailOpts        *thumbnail.Options
 	)
 	startTime := time.Now()
 	defer func() {
@@ -888,6 +908,13 @@ func (g *GateModular) getObjectByUniversalEndpointHandler(w http.ResponseWriter,
 		err = ErrInvalidQuery
 		return
 	}
+	if !isDownload && g.thumbnailCache != nil {
+		if thumbnailOpts, err = thumbnail.ParseOptions(r.URL.Query(), g.thumbnailMaxDimension); err != nil {
+			log.Errorw("failed to parse preview options", "error", err)
+			err = ErrInvalidThumbnailOptionsWithDetail(err.Error())
+			return
+		}
+	}
 
 	getBucketInfoRes, getBucketInfoErr := g.baseApp.GfSpClient().GetBucketByBucketName(reqCtx.Context(), reqCtx.bucketName, true)
 	if getBucketInfoErr != nil || getBucketInfoRes == nil || getBucketInfoRes.GetBucketInfo() == nil {
@@ -134,3 +134,22 @@ func (g *GateModular) getBucketTotalSize(ctx context.Context, bucketID uint64) (
 func fromSpMaintenanceAcct(spStatus sptypes.Status, spMaintenanceAddr, creatorAddr string) bool {
 	return spStatus == sptypes.STATUS_IN_MAINTENANCE && spMaintenanceAddr == creatorAddr
 }
+
+// queryUploadingObject returns the bucket, the object and the storage params which the object is created with.
+func (g *GateModular) queryUploadingObject(reqCtx *RequestContext) (*storagetypes.BucketInfo, *storagetypes.ObjectInfo, *storagetypes.Params, error) {
+	bucketInfo, objectInfo, err := g.baseApp.Consensus().QueryBucketInfoAndObjectInfo(reqCtx.Context(), reqCtx.bucketName, reqCtx.objectName)
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to get object info from consensus", "error", err)
+		return nil, nil, nil, ErrConsensusWithDetail("failed to get object info from consensus, object_name: " + reqCtx.objectName + ", bucket_name: " + reqCtx.bucketName + ", error: " + err.Error())
+	}
+	if err = g.checkAndAssignShadowObjectInfo(reqCtx, objectInfo); err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to get shadow object info from consensus", "error", err)
+		return nil, nil, nil, ErrConsensusWithDetail("failed to get shadow object info from consensus, object_name: " + reqCtx.objectName + ", bucket_name: " + reqCtx.bucketName + ", error: " + err.Error())
+	}
+	params, err := g.baseApp.Consensus().QueryStorageParamsByTimestamp(reqCtx.Context(), objectInfo.GetLatestUpdatedTime())
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to get storage params from consensus", "error", err)
+		return nil, nil, nil, ErrConsensusWithDetail("failed to get storage params from consensus, object_name: " + reqCtx.objectName + ", bucket_name: " + reqCtx.bucketName + ", error: " + err.Error())
+	}
+	return bucketInfo, objectInfo, params, nil
+}
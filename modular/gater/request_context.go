@@ -12,10 +12,13 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/crypto/secp256k1"
 	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/exp/slices"
 
 	commonhash "github.com/zkMeLabs/mechain-common/go/hash"
 	commonhttp "github.com/zkMeLabs/mechain-common/go/http"
+	"github.com/zkMeLabs/mechain-storage-provider/base/types/gfsperrors"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/accesslog"
 	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
 )
 
@@ -38,6 +41,8 @@ type RequestContext struct {
 	cancel    func()
 	err       error
 	startTime time.Time
+	// accessRecord is the access log record of the request, nil if the access log is disabled.
+	accessRecord *accesslog.Record
 }
 
 var skipAuthRouterNames = []string{
@@ -62,18 +67,20 @@ func NewRequestContext(r *http.Request, g *GateModular) (*RequestContext, error)
 	if mux.CurrentRoute(r) != nil {
 		routerName = mux.CurrentRoute(r).GetName()
 	}
-	ctx, cancel := context.WithCancel(context.Background())
+	// the request context is not canceled with the connection, but it carries the span of the request
+	ctx, cancel := context.WithCancel(trace.ContextWithSpan(context.Background(), trace.SpanFromContext(r.Context())))
 	reqCtx := &RequestContext{
-		g:          g,
-		ctx:        ctx,
-		cancel:     cancel,
-		request:    r,
-		routerName: routerName,
-		bucketName: vars["bucket"],
-		objectName: vars["object"],
-		account:    vars["account_id"],
-		vars:       vars,
-		startTime:  time.Now(),
+		g:            g,
+		ctx:          ctx,
+		cancel:       cancel,
+		request:      r,
+		routerName:   routerName,
+		bucketName:   vars["bucket"],
+		objectName:   vars["object"],
+		account:      vars["account_id"],
+		vars:         vars,
+		startTime:    time.Now(),
+		accessRecord: accesslog.FromContext(r.Context()),
 	}
 	if slices.Contains(skipAuthRouterNames, routerName) {
 		return reqCtx, nil
@@ -89,9 +96,30 @@ func NewRequestContext(r *http.Request, g *GateModular) (*RequestContext, error)
 		return reqCtx, err
 	}
 	reqCtx.account = account
+	if reqCtx.accessRecord != nil {
+		reqCtx.accessRecord.Account = account
+	}
+
+	// the admin is never limited so that it is always able to lift the limits
+	if g.accountLimiter != nil && !g.isAccountLimitAdmin(account) {
+		if err = g.accountLimiter.Allow(ctx, account, reqCtx.bucketName, isWriteMethod(r.Method)); err != nil {
+			log.CtxErrorw(ctx, "account is limited", "account", account, "bucket", reqCtx.bucketName, "error", err)
+			return reqCtx, err
+		}
+	}
 	return reqCtx, nil
 }
 
+// isWriteMethod returns whether the request is charged by the write budget of the account limits.
+func isWriteMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	default:
+		return true
+	}
+}
+
 // Context returns the RequestContext runtime context.
 func (r *RequestContext) Context() context.Context {
 	return r.ctx
@@ -115,6 +143,9 @@ func (r *RequestContext) SetHTTPCode(code int) {
 // SetError sets the request err to RequestContext for logging and debugging.
 func (r *RequestContext) SetError(err error) {
 	r.err = err
+	if r.accessRecord != nil {
+		r.accessRecord.ErrorCode = gfsperrors.MakeGfSpError(err).GetInnerCode()
+	}
 }
 
 // String shows the detail result of the request for logging and debugging.
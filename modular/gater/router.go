@@ -7,6 +7,7 @@ import (
 	"github.com/gorilla/mux"
 
 	"github.com/zkMeLabs/mechain-storage-provider/base/gfspapp"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/cors"
 	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
 	mwhttp "github.com/zkMeLabs/mechain-storage-provider/pkg/middleware/http"
 )
@@ -36,6 +37,8 @@ const (
 	listUserPublicKeyV2RouterName                  = "ListUserPublicKeyV2"
 	deleteUserPublicKeyV2RouterName                = "DeleteUserPublicKeyV2"
 	queryUploadProgressRouterName                  = "QueryUploadProgress"
+	uploadProgressStreamRouterName                 = "UploadProgressStream"
+	bucketUploadProgressStreamRouterName           = "BucketUploadProgressStream"
 	downloadObjectByUniversalEndpointName          = "DownloadObjectByUniversalEndpoint"
 	viewObjectByUniversalEndpointName              = "ViewObjectByUniversalEndpoint"
 	getObjectMetaRouterName                        = "GetObjectMeta"
@@ -88,6 +91,22 @@ const (
 	getBucketSizeRouterName                        = "GetBucketSize"
 	getRecommendedVGFRouterName                    = "GetRecommendedVGF"
 	getBsDBDataInfo                                = "GetBsDBDataInfo"
+	putWebhookSubscriptionRouterName               = "PutWebhookSubscription"
+	getWebhookSubscriptionRouterName               = "GetWebhookSubscription"
+	deleteWebhookSubscriptionRouterName            = "DeleteWebhookSubscription"
+	tusOptionsRouterName                           = "TusOptions"
+	tusCreateUploadRouterName                      = "TusCreateUpload"
+	tusGetOffsetRouterName                         = "TusGetOffset"
+	tusPatchRouterName                             = "TusPatch"
+	tusTerminateRouterName                         = "TusTerminate"
+	uploadFromURLRouterName                        = "UploadFromURL"
+	putBucketCORSRouterName                        = "PutBucketCORS"
+	getBucketCORSRouterName                        = "GetBucketCORS"
+	deleteBucketCORSRouterName                     = "DeleteBucketCORS"
+	corsPreflightRouterName                        = "CORSPreflight"
+	putAccountRateLimitRouterName                  = "PutAccountRateLimit"
+	getAccountRateLimitRouterName                  = "GetAccountRateLimit"
+	deleteAccountRateLimitRouterName               = "DeleteAccountRateLimit"
 )
 
 const (
@@ -106,6 +125,21 @@ func (g *GateModular) notFoundHandler(w http.ResponseWriter, r *http.Request) {
 
 // RegisterHandler registers the handlers to the gateway router.
 func (g *GateModular) RegisterHandler(router *mux.Router) {
+	// the span of the request is started first so that the access log records its trace id
+	router.Use(g.tracingMiddleware)
+	// the access log is the outermost middleware so that the requests rejected by the others are logged and
+	// counted by the usage metrics too
+	if g.accessLogger != nil || g.bucketUsage != nil {
+		router.Use(g.accessLogMiddleware)
+	}
+	// bucket cors, the preflight request is routed before the others to bypass their method matchers
+	if g.corsCache != nil {
+		router.Use(g.corsMiddleware)
+		router.Methods(http.MethodOptions).Name(corsPreflightRouterName).MatcherFunc(func(r *http.Request, _ *mux.RouteMatch) bool {
+			return cors.IsPreflight(r)
+		}).HandlerFunc(g.corsPreflightHandler(router))
+	}
+
 	// off-chain-auth router
 	router.Path(AuthRequestNoncePath).Name(requestNonceRouterName).Methods(http.MethodGet).HandlerFunc(g.requestNonceHandler)
 	router.Path(AuthUpdateKeyPath).Name(updateUserPublicKeyRouterName).Methods(http.MethodPost).HandlerFunc(g.updateUserPublicKeyHandler)
@@ -165,10 +199,29 @@ func (g *GateModular) RegisterHandler(router *mux.Router) {
 
 	router.Path(StatusPath).Name(getStatusRouterName).Methods(http.MethodGet).HandlerFunc(g.getStatusHandler)
 
+	// webhook subscription of the bucket owner
+	router.Path(WebhookSubscriptionPath).Name(putWebhookSubscriptionRouterName).Methods(http.MethodPut).HandlerFunc(g.putWebhookSubscriptionHandler)
+	router.Path(WebhookSubscriptionPath).Name(getWebhookSubscriptionRouterName).Methods(http.MethodGet).HandlerFunc(g.getWebhookSubscriptionHandler)
+	router.Path(WebhookSubscriptionPath).Name(deleteWebhookSubscriptionRouterName).Methods(http.MethodDelete).HandlerFunc(g.deleteWebhookSubscriptionHandler)
+
+	// account rate limit overrides managed by the admin
+	router.Path(AccountRateLimitPath).Name(putAccountRateLimitRouterName).Methods(http.MethodPut).HandlerFunc(g.putAccountRateLimitHandler)
+	router.Path(AccountRateLimitPath).Name(getAccountRateLimitRouterName).Methods(http.MethodGet).HandlerFunc(g.getAccountRateLimitHandler)
+	router.Path(AccountRateLimitPath).Name(deleteAccountRateLimitRouterName).Methods(http.MethodDelete).HandlerFunc(g.deleteAccountRateLimitHandler)
+
+	// tus resumable upload protocol
+	router.PathPrefix(TusUploadPath).Name(tusOptionsRouterName).Methods(http.MethodOptions).HandlerFunc(g.tusOptionsHandler)
+	router.Path(TusUploadPath).Name(tusCreateUploadRouterName).Methods(http.MethodPost).HandlerFunc(g.tusCreateUploadHandler)
+	router.Path(TusUploadPath + "{bucket:[^/]*}/{object:.+}").Name(tusGetOffsetRouterName).Methods(http.MethodHead).HandlerFunc(g.tusGetOffsetHandler)
+	router.Path(TusUploadPath + "{bucket:[^/]*}/{object:.+}").Name(tusPatchRouterName).Methods(http.MethodPatch).HandlerFunc(g.tusPatchHandler)
+	router.Path(TusUploadPath + "{bucket:[^/]*}/{object:.+}").Name(tusTerminateRouterName).Methods(http.MethodDelete).HandlerFunc(g.tusTerminateHandler)
+
 	var routers []*mux.Router
 	routers = append(routers, router.Host("{bucket:.+}."+g.domain).Subrouter())
 	routers = append(routers, router.PathPrefix("/{bucket}").Subrouter())
 	for _, r := range routers {
+		// Upload Object From URL
+		r.NewRoute().Name(uploadFromURLRouterName).Methods(http.MethodPost).Path("/{object:.+}").Queries(UploadFromURLQuery, "").HandlerFunc(g.uploadFromURLHandler)
 		// Delegate Put Object By Offset
 		r.NewRoute().Name(delegateResumablePutObjectRouterName).Methods(http.MethodPost).Path("/{object:.+}").Queries(Delegate, "").HandlerFunc(g.delegateResumablePutObjectHandler)
 		// Put Object By Offset
@@ -185,6 +238,18 @@ func (g *GateModular) RegisterHandler(router *mux.Router) {
 		r.NewRoute().Name(queryResumeOffsetName).Methods(http.MethodGet).Path("/{object:.+}").HandlerFunc(g.queryResumeOffsetHandler).
 			Queries(UploadContextQuery, "")
 
+		// Stream upload progress of an object
+		r.NewRoute().Name(uploadProgressStreamRouterName).Methods(http.MethodGet).Path("/{object:.+}").HandlerFunc(g.uploadProgressStreamHandler).
+			Queries(UploadProgressStreamQuery, "")
+		// Stream upload progress of all objects in the bucket
+		r.NewRoute().Name(bucketUploadProgressStreamRouterName).Methods(http.MethodGet).HandlerFunc(g.bucketUploadProgressStreamHandler).
+			Queries(UploadProgressStreamQuery, "")
+
+		// Bucket CORS
+		r.NewRoute().Name(putBucketCORSRouterName).Methods(http.MethodPut).Queries(BucketCORSQuery, "").HandlerFunc(g.putBucketCORSHandler)
+		r.NewRoute().Name(getBucketCORSRouterName).Methods(http.MethodGet).Queries(BucketCORSQuery, "").HandlerFunc(g.getBucketCORSHandler)
+		r.NewRoute().Name(deleteBucketCORSRouterName).Methods(http.MethodDelete).Queries(BucketCORSQuery, "").HandlerFunc(g.deleteBucketCORSHandler)
+
 		// Query upload progress
 		r.NewRoute().Name(queryUploadProgressRouterName).Methods(http.MethodGet).Path("/{object:.+}").HandlerFunc(g.queryUploadProgressHandler).
 			Queries(UploadProgressQuery, "")
@@ -188,6 +188,38 @@ func TestRouters(t *testing.T) {
 			shouldMatch:      true,
 			wantedRouterName: queryUploadProgressRouterName,
 		},
+		{
+			name:             "Stream object upload progress router, virtual host style",
+			router:           gwRouter,
+			method:           http.MethodGet,
+			url:              fmt.Sprintf("%s%s.%s/%s?%s", scheme, mockBucketName, testDomain, mockObjectName, UploadProgressStreamQuery),
+			shouldMatch:      true,
+			wantedRouterName: uploadProgressStreamRouterName,
+		},
+		{
+			name:             "Stream object upload progress router, path style",
+			router:           gwRouter,
+			method:           http.MethodGet,
+			url:              fmt.Sprintf("%s%s/%s/%s?%s", scheme, testDomain, mockBucketName, mockObjectName, UploadProgressStreamQuery),
+			shouldMatch:      true,
+			wantedRouterName: uploadProgressStreamRouterName,
+		},
+		{
+			name:             "Stream bucket upload progress router, virtual host style",
+			router:           gwRouter,
+			method:           http.MethodGet,
+			url:              fmt.Sprintf("%s%s.%s?%s", scheme, mockBucketName, testDomain, UploadProgressStreamQuery),
+			shouldMatch:      true,
+			wantedRouterName: bucketUploadProgressStreamRouterName,
+		},
+		{
+			name:             "Stream bucket upload progress router, path style",
+			router:           gwRouter,
+			method:           http.MethodGet,
+			url:              fmt.Sprintf("%s%s/%s?%s", scheme, testDomain, mockBucketName, UploadProgressStreamQuery),
+			shouldMatch:      true,
+			wantedRouterName: bucketUploadProgressStreamRouterName,
+		},
 		{
 			name:             "Get object router, virtual host style",
 			router:           gwRouter,
@@ -757,6 +789,134 @@ func TestRouters(t *testing.T) {
 			shouldMatch:      true,
 			wantedRouterName: getBsDBDataInfo,
 		},
+		{
+			name:             "tus options router",
+			router:           gwRouter,
+			method:           http.MethodOptions,
+			url:              fmt.Sprintf("%s%s%s", scheme, testDomain, TusUploadPath),
+			shouldMatch:      true,
+			wantedRouterName: tusOptionsRouterName,
+		},
+		{
+			name:             "tus create upload router",
+			router:           gwRouter,
+			method:           http.MethodPost,
+			url:              fmt.Sprintf("%s%s%s", scheme, testDomain, TusUploadPath),
+			shouldMatch:      true,
+			wantedRouterName: tusCreateUploadRouterName,
+		},
+		{
+			name:             "tus get offset router",
+			router:           gwRouter,
+			method:           http.MethodHead,
+			url:              fmt.Sprintf("%s%s%s%s/%s", scheme, testDomain, TusUploadPath, mockBucketName, mockObjectName),
+			shouldMatch:      true,
+			wantedRouterName: tusGetOffsetRouterName,
+		},
+		{
+			name:             "tus patch router",
+			router:           gwRouter,
+			method:           http.MethodPatch,
+			url:              fmt.Sprintf("%s%s%s%s/%s", scheme, testDomain, TusUploadPath, mockBucketName, mockObjectName),
+			shouldMatch:      true,
+			wantedRouterName: tusPatchRouterName,
+		},
+		{
+			name:             "tus terminate router",
+			router:           gwRouter,
+			method:           http.MethodDelete,
+			url:              fmt.Sprintf("%s%s%s%s/%s", scheme, testDomain, TusUploadPath, mockBucketName, mockObjectName),
+			shouldMatch:      true,
+			wantedRouterName: tusTerminateRouterName,
+		},
+		{
+			name:             "upload from url virtual host style router",
+			router:           gwRouter,
+			method:           http.MethodPost,
+			url:              fmt.Sprintf("%s%s.%s/%s?%s", scheme, mockBucketName, testDomain, mockObjectName, UploadFromURLQuery),
+			shouldMatch:      true,
+			wantedRouterName: uploadFromURLRouterName,
+		},
+		{
+			name:             "upload from url path style router",
+			router:           gwRouter,
+			method:           http.MethodPost,
+			url:              fmt.Sprintf("%s%s/%s/%s?%s", scheme, testDomain, mockBucketName, mockObjectName, UploadFromURLQuery),
+			shouldMatch:      true,
+			wantedRouterName: uploadFromURLRouterName,
+		},
+		{
+			name:             "put bucket cors virtual host style router",
+			router:           gwRouter,
+			method:           http.MethodPut,
+			url:              fmt.Sprintf("%s%s.%s/?%s", scheme, mockBucketName, testDomain, BucketCORSQuery),
+			shouldMatch:      true,
+			wantedRouterName: putBucketCORSRouterName,
+		},
+		{
+			name:             "put bucket cors path style router",
+			router:           gwRouter,
+			method:           http.MethodPut,
+			url:              fmt.Sprintf("%s%s/%s?%s", scheme, testDomain, mockBucketName, BucketCORSQuery),
+			shouldMatch:      true,
+			wantedRouterName: putBucketCORSRouterName,
+		},
+		{
+			name:             "get bucket cors virtual host style router",
+			router:           gwRouter,
+			method:           http.MethodGet,
+			url:              fmt.Sprintf("%s%s.%s/?%s", scheme, mockBucketName, testDomain, BucketCORSQuery),
+			shouldMatch:      true,
+			wantedRouterName: getBucketCORSRouterName,
+		},
+		{
+			name:             "get bucket cors path style router",
+			router:           gwRouter,
+			method:           http.MethodGet,
+			url:              fmt.Sprintf("%s%s/%s?%s", scheme, testDomain, mockBucketName, BucketCORSQuery),
+			shouldMatch:      true,
+			wantedRouterName: getBucketCORSRouterName,
+		},
+		{
+			name:             "delete bucket cors virtual host style router",
+			router:           gwRouter,
+			method:           http.MethodDelete,
+			url:              fmt.Sprintf("%s%s.%s/?%s", scheme, mockBucketName, testDomain, BucketCORSQuery),
+			shouldMatch:      true,
+			wantedRouterName: deleteBucketCORSRouterName,
+		},
+		{
+			name:             "delete bucket cors path style router",
+			router:           gwRouter,
+			method:           http.MethodDelete,
+			url:              fmt.Sprintf("%s%s/%s?%s", scheme, testDomain, mockBucketName, BucketCORSQuery),
+			shouldMatch:      true,
+			wantedRouterName: deleteBucketCORSRouterName,
+		},
+		{
+			name:             "put account rate limit router",
+			router:           gwRouter,
+			method:           http.MethodPut,
+			url:              fmt.Sprintf("%s%s%s?%s=%s", scheme, testDomain, AccountRateLimitPath, AccountQuery, mockAccountRateLimitAccount),
+			shouldMatch:      true,
+			wantedRouterName: putAccountRateLimitRouterName,
+		},
+		{
+			name:             "get account rate limit router",
+			router:           gwRouter,
+			method:           http.MethodGet,
+			url:              fmt.Sprintf("%s%s%s", scheme, testDomain, AccountRateLimitPath),
+			shouldMatch:      true,
+			wantedRouterName: getAccountRateLimitRouterName,
+		},
+		{
+			name:             "delete account rate limit router",
+			router:           gwRouter,
+			method:           http.MethodDelete,
+			url:              fmt.Sprintf("%s%s%s?%s=%s", scheme, testDomain, AccountRateLimitPath, AccountQuery, mockAccountRateLimitAccount),
+			shouldMatch:      true,
+			wantedRouterName: deleteAccountRateLimitRouterName,
+		},
 	}
 	for _, tt := range cases {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,88 @@
+package gater
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+
+	storagetypes "github.com/evmos/evmos/v12/x/storage/types"
+
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/thumbnail"
+	"github.com/zkMeLabs/mechain-storage-provider/util"
+)
+
+// DefaultThumbnailMaxPixels defines the max pixels of the image which the preview is rendered from, it
+// limits the memory of decoding the image.
+const DefaultThumbnailMaxPixels = 64 * 1024 * 1024
+
+// bufferResponseWriter collects the object payload downloaded for rendering the preview.
+type bufferResponseWriter struct {
+	header http.Header
+	buf    bytes.Buffer
+}
+
+func newBufferResponseWriter() *bufferResponseWriter {
+	return &bufferResponseWriter{header: make(http.Header)}
+}
+
+func (b *bufferResponseWriter) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferResponseWriter) Write(data []byte) (int, error) {
+	return b.buf.Write(data)
+}
+
+func (b *bufferResponseWriter) WriteHeader(int) {}
+
+// viewThumbnail writes the preview of the image object. The preview is rendered from the whole object which
+// is downloaded as the normal view request, so the read quota is charged by the original bytes. The rendered
+// preview is cached by the object id and version, and the cached preview is served without reading the object.
+func (g *GateModular) viewThumbnail(w http.ResponseWriter, reqCtx *RequestContext, objectInfo *storagetypes.ObjectInfo,
+	opts *thumbnail.Options) error {
+	key := thumbnail.Key(objectInfo.Id.Uint64(), objectInfo.GetVersion(), opts)
+	entry, ok := g.thumbnailCache.Get(key)
+	if !ok {
+		if objectInfo.GetPayloadSize() > g.thumbnailMaxSourceSize {
+			log.CtxErrorw(reqCtx.Context(), "failed to render preview due to too large object",
+				"payload_size", objectInfo.GetPayloadSize(), "max_source_size", g.thumbnailMaxSourceSize)
+			return ErrThumbnailTooLarge
+		}
+		select {
+		case g.thumbnailSlots <- struct{}{}:
+			defer func() { <-g.thumbnailSlots }()
+		default:
+			return ErrThumbnailBusy
+		}
+
+		// the preview is rendered from the whole object regardless of the range
+		reqCtx.request.Header.Del(RangeHeader)
+		source := newBufferResponseWriter()
+		if err := g.downloadObject(source, reqCtx); err != nil {
+			return err
+		}
+		data, contentType, err := thumbnail.Render(source.buf.Bytes(), opts, DefaultThumbnailMaxPixels)
+		if err != nil {
+			log.CtxErrorw(reqCtx.Context(), "failed to render preview", "error", err)
+			switch {
+			case errors.Is(err, thumbnail.ErrUnsupportedImage):
+				return ErrUnsupportedThumbnail
+			case errors.Is(err, thumbnail.ErrImageTooLarge):
+				return ErrThumbnailTooLarge
+			default:
+				return ErrEncodeResponseWithDetail("failed to render preview, error: " + err.Error())
+			}
+		}
+		entry = &thumbnail.Entry{Data: data, ContentType: contentType}
+		g.thumbnailCache.Add(key, entry)
+	}
+
+	w.Header().Set(ContentTypeHeader, entry.ContentType)
+	w.Header().Set(ContentLengthHeader, util.Uint64ToString(uint64(len(entry.Data))))
+	if _, err := w.Write(entry.Data); err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to write preview", "error", err)
+		return ErrReplyData
+	}
+	return nil
+}
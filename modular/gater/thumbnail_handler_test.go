@@ -0,0 +1,165 @@
+package gater
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	sdkmath "cosmossdk.io/math"
+	sptypes "github.com/evmos/evmos/v12/x/sp/types"
+	storagetypes "github.com/evmos/evmos/v12/x/storage/types"
+	virtualgrouptypes "github.com/evmos/evmos/v12/x/virtualgroup/types"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/zkMeLabs/mechain-storage-provider/base/gfspclient"
+	"github.com/zkMeLabs/mechain-storage-provider/core/consensus"
+	"github.com/zkMeLabs/mechain-storage-provider/core/piecestore"
+	metadatatypes "github.com/zkMeLabs/mechain-storage-provider/modular/metadata/types"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/thumbnail"
+)
+
+func mockThumbnailImage(t *testing.T, width, height int) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	assert.Nil(t, png.Encode(buf, image.NewRGBA(image.Rect(0, 0, width, height))))
+	return buf.Bytes()
+}
+
+// mockThumbnailGateModular returns the gateway with the image preview enabled, the public object payload is
+// data and it is expected to be read pieceTimes times.
+func mockThumbnailGateModular(t *testing.T, ctrl *gomock.Controller, data []byte, pieceTimes int) *GateModular {
+	g := setup(t)
+	g.thumbnailCache = thumbnail.NewCache(0)
+	g.thumbnailSlots = make(chan struct{}, 1)
+	g.thumbnailMaxSourceSize = DefaultThumbnailMaxSourceSize
+	g.thumbnailMaxDimension = DefaultThumbnailMaxDimension
+
+	clientMock := gfspclient.NewMockGfSpClientAPI(ctrl)
+	clientMock.EXPECT().GetBucketByBucketName(gomock.Any(), gomock.Any(), gomock.Any()).Return(
+		&metadatatypes.Bucket{BucketInfo: &storagetypes.BucketInfo{}}, nil).AnyTimes()
+	clientMock.EXPECT().GetObjectMeta(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(
+		&metadatatypes.Object{ObjectInfo: &storagetypes.ObjectInfo{Id: sdkmath.NewUint(1), PayloadSize: uint64(len(data)),
+			ObjectStatus: storagetypes.OBJECT_STATUS_SEALED, Visibility: storagetypes.VISIBILITY_TYPE_PUBLIC_READ}}, nil).AnyTimes()
+	clientMock.EXPECT().GetPiece(gomock.Any(), gomock.Any()).Return(data, nil).Times(pieceTimes)
+	g.baseApp.SetGfSpClient(clientMock)
+
+	consensusMock := consensus.NewMockConsensus(ctrl)
+	consensusMock.EXPECT().QuerySP(gomock.Any(), gomock.Any()).Return(&sptypes.StorageProvider{Id: 1}, nil).MaxTimes(1)
+	consensusMock.EXPECT().QueryVirtualGroupFamily(gomock.Any(), gomock.Any()).Return(
+		&virtualgrouptypes.GlobalVirtualGroupFamily{PrimarySpId: 1}, nil).AnyTimes()
+	consensusMock.EXPECT().QueryObjectInfo(gomock.Any(), gomock.Any(), gomock.Any()).Return(
+		&storagetypes.ObjectInfo{Id: sdkmath.NewUint(1), PayloadSize: uint64(len(data))}, nil).Times(pieceTimes)
+	consensusMock.EXPECT().QueryBucketInfo(gomock.Any(), gomock.Any()).Return(
+		&storagetypes.BucketInfo{Id: sdkmath.NewUint(2)}, nil).Times(pieceTimes)
+	consensusMock.EXPECT().QueryStorageParamsByTimestamp(gomock.Any(), gomock.Any()).Return(
+		&storagetypes.Params{MaxPayloadSize: uint64(len(data))}, nil).Times(pieceTimes)
+	g.baseApp.SetConsensus(consensusMock)
+
+	pieceOpMock := piecestore.NewMockPieceOp(ctrl)
+	pieceOpMock.EXPECT().SegmentPieceCount(gomock.Any(), gomock.Any()).Return(uint32(1)).AnyTimes()
+	pieceOpMock.EXPECT().SegmentPieceKey(gomock.Any(), gomock.Any(), gomock.Any()).Return("test").AnyTimes()
+	g.baseApp.SetPieceOp(pieceOpMock)
+	return g
+}
+
+func mockThumbnailRequest(query string) *http.Request {
+	path := fmt.Sprintf("%s%s/view/%s/%s?%s", scheme, testDomain, mockBucketName, mockObjectName, query)
+	return httptest.NewRequest(http.MethodGet, path, nil)
+}
+
+func TestGateModular_viewThumbnail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	// the preview is rendered once and then served from the cache
+	g := mockThumbnailGateModular(t, ctrl, mockThumbnailImage(t, 64, 32), 1)
+	router := mockGetObjectByUniversalEndpointHandlerRoute(t, g)
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, mockThumbnailRequest("w=16&format=jpeg"))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "image/jpeg", w.Header().Get(ContentTypeHeader))
+		assert.Equal(t, fmt.Sprint(w.Body.Len()), w.Header().Get(ContentLengthHeader))
+		config, _, err := image.DecodeConfig(w.Body)
+		assert.Nil(t, err)
+		assert.Equal(t, 16, config.Width)
+		assert.Equal(t, 8, config.Height)
+	}
+}
+
+func TestGateModular_viewThumbnailFailure(t *testing.T) {
+	cases := []struct {
+		name         string
+		fn           func(ctrl *gomock.Controller) *GateModular
+		query        string
+		wantedCode   int
+		wantedResult string
+	}{
+		{
+			name: "invalid preview options",
+			fn: func(ctrl *gomock.Controller) *GateModular {
+				return mockThumbnailGateModular(t, ctrl, nil, 0)
+			},
+			query:        "w=0",
+			wantedCode:   http.StatusBadRequest,
+			wantedResult: "w must be in",
+		},
+		{
+			name: "too large object",
+			fn: func(ctrl *gomock.Controller) *GateModular {
+				g := mockThumbnailGateModular(t, ctrl, mockThumbnailImage(t, 64, 32), 0)
+				g.thumbnailMaxSourceSize = 1
+				return g
+			},
+			query:        "w=16",
+			wantedCode:   http.StatusBadRequest,
+			wantedResult: "the image is too large to render the preview",
+		},
+		{
+			name: "too many concurrent renderings",
+			fn: func(ctrl *gomock.Controller) *GateModular {
+				g := mockThumbnailGateModular(t, ctrl, mockThumbnailImage(t, 64, 32), 0)
+				g.thumbnailSlots <- struct{}{}
+				return g
+			},
+			query:        "w=16",
+			wantedCode:   http.StatusServiceUnavailable,
+			wantedResult: "too many concurrent preview renderings",
+		},
+		{
+			name: "unsupported image",
+			fn: func(ctrl *gomock.Controller) *GateModular {
+				return mockThumbnailGateModular(t, ctrl, []byte("not an image"), 1)
+			},
+			query:        "w=16",
+			wantedCode:   http.StatusUnsupportedMediaType,
+			wantedResult: "unsupported image format",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			router := mockGetObjectByUniversalEndpointHandlerRoute(t, tt.fn(ctrl))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, mockThumbnailRequest(tt.query))
+			assert.Equal(t, tt.wantedCode, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantedResult)
+		})
+	}
+}
+
+func TestGateModular_viewThumbnailDisabled(t *testing.T) {
+	// the preview options are ignored and the original object is served
+	ctrl := gomock.NewController(t)
+	data := mockThumbnailImage(t, 64, 32)
+	g := mockThumbnailGateModular(t, ctrl, data, 1)
+	g.thumbnailCache = nil
+	router := mockGetObjectByUniversalEndpointHandlerRoute(t, g)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, mockThumbnailRequest("w=16"))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, data, w.Body.Bytes())
+}
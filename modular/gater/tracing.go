@@ -0,0 +1,45 @@
+package gater
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/tracing"
+)
+
+// tracingMiddleware starts the server span of the request named by the route, the trace is continued if
+// the client sends the w3c traceparent header. The span is carried by the RequestContext to the gRPC calls
+// of the handler, the spans are dropped if the tracing is disabled.
+func (g *GateModular) tracingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		name := r.Method
+		if route := mux.CurrentRoute(r); route != nil && route.GetName() != "" {
+			name = route.GetName()
+		}
+		vars := mux.Vars(r)
+		ctx, span := tracing.Tracer().Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.URLPath(r.URL.Path),
+				attribute.String("sp.bucket", vars["bucket"]),
+				attribute.String("sp.object", vars["object"])))
+		defer span.End()
+
+		writer := &statusResponseWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(writer, r.WithContext(ctx))
+		span.SetAttributes(semconv.HTTPResponseStatusCode(writer.status))
+		if writer.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, fmt.Sprintf("http status %d", writer.status))
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package gater
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestGateModular_tracingMiddleware(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	}()
+
+	g := setup(t)
+	router := mux.NewRouter().SkipClean(true)
+	router.Use(g.tracingMiddleware)
+	var handlerSpan trace.SpanContext
+	router.Path("/{bucket}/{object:.+}").Name(getObjectRouterName).HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the request context carries the span of the request
+		reqCtx, _ := NewRequestContext(r, g)
+		handlerSpan = trace.SpanContextFromContext(reqCtx.Context())
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	// the trace of the client is continued
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/%s/%s", mockBucketName, mockObjectName), nil)
+	req.Header.Set("traceparent", fmt.Sprintf("00-%s-00f067aa0ba902b7-01", traceID))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	spans := exporter.GetSpans()
+	assert.Equal(t, 1, len(spans))
+	assert.Equal(t, getObjectRouterName, spans[0].Name)
+	assert.Equal(t, traceID, spans[0].SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent.SpanID().String())
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.Equal(t, spans[0].SpanContext.SpanID(), handlerSpan.SpanID())
+}
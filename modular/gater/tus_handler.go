@@ -0,0 +1,554 @@
+package gater
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	storagetypes "github.com/evmos/evmos/v12/x/storage/types"
+
+	"github.com/zkMeLabs/mechain-storage-provider/base/types/gfsperrors"
+	"github.com/zkMeLabs/mechain-storage-provider/base/types/gfsptask"
+	coremodule "github.com/zkMeLabs/mechain-storage-provider/core/module"
+	modelgateway "github.com/zkMeLabs/mechain-storage-provider/model/gateway"
+	"github.com/zkMeLabs/mechain-storage-provider/modular/metadata"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/metrics"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/webhook"
+	"github.com/zkMeLabs/mechain-storage-provider/util"
+)
+
+// The tus 1.0 resumable upload protocol, see https://tus.io/protocols/resumable-upload, it is mapped onto
+// the ResumableUploadObjectTask. The object must be created onto the chain before the upload is created, and
+// the chunks are aligned to the max segment size, so that the upload offset is always segment-aligned.
+const (
+	// TusVersion defines the supported tus protocol version.
+	TusVersion = "1.0.0"
+	// TusExtensions defines the supported tus protocol extensions.
+	TusExtensions = "creation,checksum,termination"
+	// TusChecksumAlgorithms defines the supported algorithms of the checksum extension.
+	TusChecksumAlgorithms = "md5,sha1,sha256"
+	// TusChecksumMismatchStatusCode defines the http status code of the checksum mismatch.
+	TusChecksumMismatchStatusCode = 460
+	// TusMetadataBucketKey defines the Upload-Metadata key of the bucket name.
+	TusMetadataBucketKey = "bucket"
+	// TusMetadataObjectKey defines the Upload-Metadata key of the object name.
+	TusMetadataObjectKey = "object"
+	// MaxTusChecksumChunkSize defines the max size of a chunk with Upload-Checksum, the chunk is buffered
+	// in memory to verify the checksum before it is uploaded.
+	MaxTusChecksumChunkSize = 64 * 1024 * 1024
+
+	// TusResumableHeader defines the tus protocol version of the request and response.
+	TusResumableHeader = "Tus-Resumable"
+	// TusVersionHeader defines the supported tus protocol versions.
+	TusVersionHeader = "Tus-Version"
+	// TusExtensionHeader defines the supported tus protocol extensions.
+	TusExtensionHeader = "Tus-Extension"
+	// TusChecksumAlgorithmHeader defines the supported checksum algorithms.
+	TusChecksumAlgorithmHeader = "Tus-Checksum-Algorithm"
+	// UploadOffsetHeader defines the byte offset of the upload.
+	UploadOffsetHeader = "Upload-Offset"
+	// UploadLengthHeader defines the size of the entire upload in bytes.
+	UploadLengthHeader = "Upload-Length"
+	// UploadMetadataHeader defines the key-value pairs of the upload, the values are base64 encoded.
+	UploadMetadataHeader = "Upload-Metadata"
+	// UploadChecksumHeader defines the checksum of the chunk, e.g. "sha1 Kq5sNclPz7QV2+lfQIuc6R7oRu0=".
+	UploadChecksumHeader = "Upload-Checksum"
+	// LocationHeader defines the url of the created upload.
+	LocationHeader = "Location"
+	// GnfdSegmentSizeHeader defines the segment size, the chunk except the last one must be aligned to it.
+	GnfdSegmentSizeHeader = "X-Gnfd-Segment-Size"
+	// ContentTypeOffsetOctetStreamHeaderValue is used to indicate the tus chunk
+	ContentTypeOffsetOctetStreamHeaderValue = "application/offset+octet-stream"
+)
+
+// tusOptionsHandler handles the tus OPTIONS request which discovers the supported protocol versions and extensions.
+func (g *GateModular) tusOptionsHandler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set(TusResumableHeader, TusVersion)
+	w.Header().Set(TusVersionHeader, TusVersion)
+	w.Header().Set(TusExtensionHeader, TusExtensions)
+	w.Header().Set(TusChecksumAlgorithmHeader, TusChecksumAlgorithms)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// tusCreateUploadHandler handles the tus creation request, the object is specified by the bucket and object
+// keys of Upload-Metadata, and it must have been created onto the chain with the payload size of Upload-Length.
+func (g *GateModular) tusCreateUploadHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err           error
+		reqCtx        *RequestContext
+		authenticated bool
+		uploadLength  uint64
+		uploadMeta    map[string]string
+		objectInfo    *storagetypes.ObjectInfo
+		params        *storagetypes.Params
+	)
+	startTime := time.Now()
+	defer func() {
+		reqCtx.Cancel()
+		if err != nil {
+			reqCtx.SetError(gfsperrors.MakeGfSpError(err))
+			reqCtx.SetHTTPCode(int(gfsperrors.MakeGfSpError(err).GetHttpStatusCode()))
+			modelgateway.MakeErrorResponse(w, gfsperrors.MakeGfSpError(err))
+			metrics.ReqCounter.WithLabelValues(GatewayTotalFailure).Inc()
+			metrics.ReqTime.WithLabelValues(GatewayTotalFailure).Observe(time.Since(startTime).Seconds())
+		} else {
+			reqCtx.SetHTTPCode(http.StatusCreated)
+			metrics.ReqCounter.WithLabelValues(GatewayTotalSuccess).Inc()
+			metrics.ReqTime.WithLabelValues(GatewayTotalSuccess).Observe(time.Since(startTime).Seconds())
+		}
+		log.CtxDebugw(reqCtx.Context(), reqCtx.String())
+	}()
+
+	w.Header().Set(TusResumableHeader, TusVersion)
+	reqCtx, err = NewRequestContext(r, g)
+	if err != nil {
+		return
+	}
+	if err = checkTusResumable(w, r); err != nil {
+		return
+	}
+	uploadLength, err = strconv.ParseUint(r.Header.Get(UploadLengthHeader), 10, 64)
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to parse upload length", "error", err)
+		err = ErrTusInvalidUploadLength
+		return
+	}
+	uploadMeta, err = parseTusMetadata(r.Header.Get(UploadMetadataHeader))
+	if err != nil || uploadMeta[TusMetadataBucketKey] == "" || uploadMeta[TusMetadataObjectKey] == "" {
+		log.CtxErrorw(reqCtx.Context(), "failed to parse upload metadata", "metadata", r.Header.Get(UploadMetadataHeader), "error", err)
+		err = ErrTusInvalidMetadata
+		return
+	}
+	reqCtx.bucketName = uploadMeta[TusMetadataBucketKey]
+	reqCtx.objectName = uploadMeta[TusMetadataObjectKey]
+
+	authenticated, err = g.baseApp.GfSpClient().VerifyAuthentication(reqCtx.Context(),
+		coremodule.AuthOpTypePutObject, reqCtx.Account(), reqCtx.bucketName, reqCtx.objectName)
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to verify authentication", "error", err)
+		return
+	}
+	if !authenticated {
+		log.CtxErrorw(reqCtx.Context(), "no permission to operate")
+		err = ErrNoPermission
+		return
+	}
+	_, objectInfo, params, err = g.queryUploadingObject(reqCtx)
+	if err != nil {
+		return
+	}
+	if objectInfo.GetObjectStatus() != storagetypes.OBJECT_STATUS_CREATED && !objectInfo.GetIsUpdating() {
+		log.CtxErrorw(reqCtx.Context(), "object is not in created or updating status", "status", objectInfo.GetObjectStatus())
+		err = ErrNotCreatedState
+		return
+	}
+	if objectInfo.GetPayloadSize() != uploadLength {
+		log.CtxErrorw(reqCtx.Context(), "upload length mismatch", "upload_length", uploadLength,
+			"payload_size", objectInfo.GetPayloadSize())
+		err = ErrTusInvalidUploadLength
+		return
+	}
+	w.Header().Set(LocationHeader, tusUploadLocation(reqCtx.bucketName, reqCtx.objectName))
+	w.Header().Set(GnfdSegmentSizeHeader, util.Uint64ToString(params.GetMaxSegmentSize()))
+	w.WriteHeader(http.StatusCreated)
+	log.CtxDebugw(reqCtx.Context(), "succeed to create tus upload", "bucket_name", reqCtx.bucketName,
+		"object_name", reqCtx.objectName)
+}
+
+// tusGetOffsetHandler handles the tus HEAD request which queries the current offset of the upload.
+func (g *GateModular) tusGetOffsetHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err           error
+		reqCtx        *RequestContext
+		authenticated bool
+		objectInfo    *storagetypes.ObjectInfo
+		params        *storagetypes.Params
+		offset        uint64
+	)
+	startTime := time.Now()
+	defer func() {
+		reqCtx.Cancel()
+		if err != nil {
+			reqCtx.SetError(gfsperrors.MakeGfSpError(err))
+			reqCtx.SetHTTPCode(int(gfsperrors.MakeGfSpError(err).GetHttpStatusCode()))
+			modelgateway.MakeErrorResponse(w, gfsperrors.MakeGfSpError(err))
+			metrics.ReqCounter.WithLabelValues(GatewayTotalFailure).Inc()
+			metrics.ReqTime.WithLabelValues(GatewayTotalFailure).Observe(time.Since(startTime).Seconds())
+		} else {
+			reqCtx.SetHTTPCode(http.StatusOK)
+			metrics.ReqCounter.WithLabelValues(GatewayTotalSuccess).Inc()
+			metrics.ReqTime.WithLabelValues(GatewayTotalSuccess).Observe(time.Since(startTime).Seconds())
+		}
+		log.CtxDebugw(reqCtx.Context(), reqCtx.String())
+	}()
+
+	w.Header().Set(TusResumableHeader, TusVersion)
+	reqCtx, err = NewRequestContext(r, g)
+	if err != nil {
+		return
+	}
+	if err = checkTusResumable(w, r); err != nil {
+		return
+	}
+	authenticated, err = g.baseApp.GfSpClient().VerifyAuthentication(reqCtx.Context(),
+		coremodule.AuthOpTypeGetUploadingState, reqCtx.Account(), reqCtx.bucketName, reqCtx.objectName)
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to verify authentication", "error", err)
+		return
+	}
+	if !authenticated {
+		log.CtxErrorw(reqCtx.Context(), "no permission to operate")
+		err = ErrNoPermission
+		return
+	}
+	_, objectInfo, params, err = g.queryUploadingObject(reqCtx)
+	if err != nil {
+		return
+	}
+	offset, err = g.getTusUploadOffset(reqCtx.Context(), objectInfo, params)
+	if err != nil {
+		return
+	}
+	w.Header().Set(UploadOffsetHeader, util.Uint64ToString(offset))
+	w.Header().Set(UploadLengthHeader, util.Uint64ToString(objectInfo.GetPayloadSize()))
+	w.Header().Set(GnfdSegmentSizeHeader, util.Uint64ToString(params.GetMaxSegmentSize()))
+	w.Header().Set(CacheControlHeader, "no-store")
+	w.WriteHeader(http.StatusOK)
+}
+
+// tusPatchHandler handles the tus PATCH request which appends the chunk at the upload offset. The chunk is
+// truncated to the segment size alignment unless it reaches the end of the object, the client continues
+// from the Upload-Offset of the response as defined by the protocol.
+func (g *GateModular) tusPatchHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err           error
+		reqCtx        *RequestContext
+		authenticated bool
+		bucketInfo    *storagetypes.BucketInfo
+		objectInfo    *storagetypes.ObjectInfo
+		params        *storagetypes.Params
+		requestOffset uint64
+		offset        uint64
+		chunkSize     uint64
+		chunk         io.Reader
+	)
+	uploadPrimaryStartTime := time.Now()
+	defer func() {
+		reqCtx.Cancel()
+		if err != nil {
+			reqCtx.SetError(gfsperrors.MakeGfSpError(err))
+			reqCtx.SetHTTPCode(int(gfsperrors.MakeGfSpError(err).GetHttpStatusCode()))
+			modelgateway.MakeErrorResponse(w, gfsperrors.MakeGfSpError(err))
+			metrics.ReqCounter.WithLabelValues(GatewayTotalFailure).Inc()
+			metrics.ReqTime.WithLabelValues(GatewayTotalFailure).Observe(time.Since(uploadPrimaryStartTime).Seconds())
+		} else {
+			reqCtx.SetHTTPCode(http.StatusNoContent)
+			metrics.ReqCounter.WithLabelValues(GatewayTotalSuccess).Inc()
+			metrics.ReqTime.WithLabelValues(GatewayTotalSuccess).Observe(time.Since(uploadPrimaryStartTime).Seconds())
+		}
+		log.CtxDebugw(reqCtx.Context(), reqCtx.String())
+	}()
+
+	w.Header().Set(TusResumableHeader, TusVersion)
+	reqCtx, err = NewRequestContext(r, g)
+	if err != nil {
+		return
+	}
+	if err = checkTusResumable(w, r); err != nil {
+		return
+	}
+	if r.Header.Get(ContentTypeHeader) != ContentTypeOffsetOctetStreamHeaderValue {
+		err = ErrTusInvalidContentType
+		return
+	}
+	requestOffset, err = strconv.ParseUint(r.Header.Get(UploadOffsetHeader), 10, 64)
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to parse upload offset", "error", err)
+		err = ErrInvalidOffset
+		return
+	}
+	if r.ContentLength < 0 {
+		log.CtxErrorw(reqCtx.Context(), "content length is required")
+		err = ErrInvalidHeader
+		return
+	}
+
+	if err = g.checkSPAndBucketStatus(reqCtx.Context(), reqCtx.bucketName, reqCtx.account); err != nil {
+		log.CtxErrorw(reqCtx.Context(), "tus patch failed to check sp and bucket status", "error", err)
+		return
+	}
+	authenticated, err = g.baseApp.GfSpClient().VerifyAuthentication(reqCtx.Context(),
+		coremodule.AuthOpTypePutObject, reqCtx.Account(), reqCtx.bucketName, reqCtx.objectName)
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to verify authentication", "error", err)
+		return
+	}
+	if !authenticated {
+		log.CtxErrorw(reqCtx.Context(), "no permission to operate")
+		err = ErrNoPermission
+		return
+	}
+	bucketInfo, objectInfo, params, err = g.queryUploadingObject(reqCtx)
+	if err != nil {
+		return
+	}
+	if objectInfo.GetObjectStatus() != storagetypes.OBJECT_STATUS_CREATED && !objectInfo.GetIsUpdating() {
+		log.CtxErrorw(reqCtx.Context(), "object is not in created or updating status", "status", objectInfo.GetObjectStatus())
+		err = ErrNotCreatedState
+		return
+	}
+	if objectInfo.GetPayloadSize() == 0 || objectInfo.GetPayloadSize() > params.GetMaxPayloadSize() {
+		log.CtxErrorw(reqCtx.Context(), "invalid payload size", "payload_size", objectInfo.GetPayloadSize())
+		err = ErrInvalidPayloadSize
+		return
+	}
+	offset, err = g.getTusUploadOffset(reqCtx.Context(), objectInfo, params)
+	if err != nil {
+		return
+	}
+	if requestOffset != offset {
+		log.CtxErrorw(reqCtx.Context(), "upload offset mismatch", "request_offset", requestOffset, "offset", offset)
+		err = ErrTusOffsetMismatch
+		return
+	}
+	chunkSize = tusChunkSize(uint64(r.ContentLength), offset, objectInfo.GetPayloadSize(), params.GetMaxSegmentSize())
+	if chunkSize == 0 {
+		if offset == objectInfo.GetPayloadSize() {
+			w.Header().Set(UploadOffsetHeader, util.Uint64ToString(offset))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		log.CtxErrorw(reqCtx.Context(), "chunk is smaller than the segment size", "content_length", r.ContentLength,
+			"segment_size", params.GetMaxSegmentSize())
+		err = ErrTusInvalidChunk
+		return
+	}
+	if r.Header.Get(UploadChecksumHeader) != "" {
+		if chunk, err = readAndVerifyTusChunk(r, chunkSize); err != nil {
+			log.CtxErrorw(reqCtx.Context(), "failed to verify the chunk checksum", "error", err)
+			return
+		}
+	} else {
+		chunk = io.LimitReader(r.Body, int64(chunkSize))
+	}
+
+	complete := offset+chunkSize == objectInfo.GetPayloadSize()
+	task := &gfsptask.GfSpResumableUploadObjectTask{}
+	task.InitResumableUploadObjectTask(bucketInfo.GetGlobalVirtualGroupFamilyId(), objectInfo, params,
+		g.baseApp.TaskTimeout(task, objectInfo.GetPayloadSize()), complete, offset, false)
+	task.SetCreateTime(uploadPrimaryStartTime.Unix())
+	task.AppendLog("gateway-create-tus-upload-task")
+	ctx := log.WithValue(reqCtx.Context(), log.CtxKeyTask, task.Key().String())
+	uploadDataTime := time.Now()
+	err = g.baseApp.GfSpClient().ResumableUploadObject(ctx, task, chunk)
+	metrics.PerfPutObjectTime.WithLabelValues("gateway_tus_put_object_data_cost").Observe(time.Since(uploadDataTime).Seconds())
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to upload tus chunk", "error", err)
+		return
+	}
+	w.Header().Set(UploadOffsetHeader, util.Uint64ToString(offset+chunkSize))
+	w.WriteHeader(http.StatusNoContent)
+	log.CtxDebugw(ctx, "succeed to upload tus chunk", "offset", offset, "chunk_size", chunkSize, "complete", complete)
+}
+
+// tusTerminateHandler handles the tus termination request, the SP rejects to seal the object so that the
+// object is removed from the chain and the uploaded segments are garbage collected. Only the creator or
+// the owner of the object is allowed.
+func (g *GateModular) tusTerminateHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err           error
+		reqCtx        *RequestContext
+		authenticated bool
+		objectInfo    *storagetypes.ObjectInfo
+	)
+	startTime := time.Now()
+	defer func() {
+		reqCtx.Cancel()
+		if err != nil {
+			reqCtx.SetError(gfsperrors.MakeGfSpError(err))
+			reqCtx.SetHTTPCode(int(gfsperrors.MakeGfSpError(err).GetHttpStatusCode()))
+			modelgateway.MakeErrorResponse(w, gfsperrors.MakeGfSpError(err))
+			metrics.ReqCounter.WithLabelValues(GatewayTotalFailure).Inc()
+			metrics.ReqTime.WithLabelValues(GatewayTotalFailure).Observe(time.Since(startTime).Seconds())
+		} else {
+			reqCtx.SetHTTPCode(http.StatusNoContent)
+			metrics.ReqCounter.WithLabelValues(GatewayTotalSuccess).Inc()
+			metrics.ReqTime.WithLabelValues(GatewayTotalSuccess).Observe(time.Since(startTime).Seconds())
+		}
+		log.CtxDebugw(reqCtx.Context(), reqCtx.String())
+	}()
+
+	w.Header().Set(TusResumableHeader, TusVersion)
+	reqCtx, err = NewRequestContext(r, g)
+	if err != nil {
+		return
+	}
+	if err = checkTusResumable(w, r); err != nil {
+		return
+	}
+	authenticated, err = g.baseApp.GfSpClient().VerifyAuthentication(reqCtx.Context(),
+		coremodule.AuthOpTypePutObject, reqCtx.Account(), reqCtx.bucketName, reqCtx.objectName)
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to verify authentication", "error", err)
+		return
+	}
+	if !authenticated {
+		log.CtxErrorw(reqCtx.Context(), "no permission to operate")
+		err = ErrNoPermission
+		return
+	}
+	objectInfo, err = g.baseApp.Consensus().QueryObjectInfo(reqCtx.Context(), reqCtx.bucketName, reqCtx.objectName)
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to get object info from consensus", "error", err)
+		err = ErrConsensusWithDetail("failed to get object info from consensus, object_name: " + reqCtx.objectName + ", bucket_name: " + reqCtx.bucketName + ", error: " + err.Error())
+		return
+	}
+	if objectInfo.GetCreator() != reqCtx.Account() && objectInfo.GetOwner() != reqCtx.Account() {
+		log.CtxErrorw(reqCtx.Context(), "only the creator or owner can terminate the upload")
+		err = ErrNoPermission
+		return
+	}
+	if objectInfo.GetObjectStatus() != storagetypes.OBJECT_STATUS_CREATED || objectInfo.GetIsUpdating() {
+		log.CtxErrorw(reqCtx.Context(), "object is not in created status", "status", objectInfo.GetObjectStatus())
+		err = ErrNotCreatedState
+		return
+	}
+	if _, err = g.baseApp.GfSpClient().RejectUnSealObject(reqCtx.Context(), &storagetypes.MsgRejectSealObject{
+		BucketName: objectInfo.GetBucketName(),
+		ObjectName: objectInfo.GetObjectName(),
+	}); err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to reject unseal object", "error", err)
+		return
+	}
+	g.baseApp.NotifyObjectEvent(webhook.EventObjectSealFailed, objectInfo, "tus_terminated")
+	w.WriteHeader(http.StatusNoContent)
+	log.CtxInfow(reqCtx.Context(), "succeed to terminate tus upload", "object_id", objectInfo.Id.Uint64())
+}
+
+// getTusUploadOffset returns the upload offset which is the size of the uploaded segments, it is the payload
+// size if the object has been sealed.
+func (g *GateModular) getTusUploadOffset(ctx context.Context, objectInfo *storagetypes.ObjectInfo, params *storagetypes.Params) (uint64, error) {
+	if objectInfo.GetObjectStatus() == storagetypes.OBJECT_STATUS_SEALED && !objectInfo.GetIsUpdating() {
+		return objectInfo.GetPayloadSize(), nil
+	}
+	segmentCount, err := g.baseApp.GfSpClient().GetUploadObjectSegment(ctx, objectInfo.Id.Uint64())
+	if err != nil && err.Error() != metadata.ErrNoRecord.String() {
+		log.CtxErrorw(ctx, "failed to get uploading object segment", "error", err)
+		return 0, err
+	}
+	offset := uint64(segmentCount) * params.GetMaxSegmentSize()
+	if offset > objectInfo.GetPayloadSize() {
+		offset = objectInfo.GetPayloadSize()
+	}
+	return offset, nil
+}
+
+// tusChunkSize returns the size of the chunk which is accepted, it is truncated to the segment size alignment
+// unless it reaches the end of the object.
+func tusChunkSize(contentLength, offset, payloadSize, segmentSize uint64) uint64 {
+	if offset >= payloadSize {
+		return 0
+	}
+	size := contentLength
+	if remaining := payloadSize - offset; size >= remaining {
+		return remaining
+	}
+	if segmentSize == 0 {
+		return 0
+	}
+	return size - size%segmentSize
+}
+
+// readAndVerifyTusChunk reads the whole chunk into memory and verifies it with Upload-Checksum, the chunk is
+// not truncated since the checksum covers the whole request body.
+func readAndVerifyTusChunk(r *http.Request, chunkSize uint64) (io.Reader, error) {
+	if r.ContentLength > MaxTusChecksumChunkSize {
+		return nil, ErrTusChunkTooLarge
+	}
+	if uint64(r.ContentLength) != chunkSize {
+		return nil, ErrTusInvalidChunk
+	}
+	algorithm, expected, err := parseTusChecksum(r.Header.Get(UploadChecksumHeader))
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, chunkSize)
+	if _, err = io.ReadFull(r.Body, data); err != nil {
+		return nil, ErrExceptionStream
+	}
+	algorithm.Write(data)
+	if !bytes.Equal(algorithm.Sum(nil), expected) {
+		return nil, ErrTusChecksumMismatch
+	}
+	return bytes.NewReader(data), nil
+}
+
+// parseTusChecksum parses Upload-Checksum which is the algorithm and the base64 encoded checksum separated by a space.
+func parseTusChecksum(header string) (hash.Hash, []byte, error) {
+	algorithm, encoded, found := strings.Cut(header, " ")
+	if !found {
+		return nil, nil, ErrTusChecksumUnsupported
+	}
+	var h hash.Hash
+	switch algorithm {
+	case "md5":
+		h = md5.New()
+	case "sha1":
+		h = sha1.New()
+	case "sha256":
+		h = sha256.New()
+	default:
+		return nil, nil, ErrTusChecksumUnsupported
+	}
+	checksum, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, nil, ErrTusChecksumUnsupported
+	}
+	return h, checksum, nil
+}
+
+// parseTusMetadata parses Upload-Metadata which consists of the comma separated key-value pairs, the key and
+// the base64 encoded value are separated by a space, and the value may be omitted.
+func parseTusMetadata(header string) (map[string]string, error) {
+	meta := make(map[string]string)
+	if header == "" {
+		return meta, nil
+	}
+	for _, pair := range strings.Split(header, ",") {
+		key, encoded, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			return nil, fmt.Errorf("empty metadata key")
+		}
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid metadata value of key %s: %w", key, err)
+		}
+		meta[key] = string(value)
+	}
+	return meta, nil
+}
+
+// checkTusResumable checks the tus protocol version of the request.
+func checkTusResumable(w http.ResponseWriter, r *http.Request) error {
+	if r.Header.Get(TusResumableHeader) != TusVersion {
+		w.Header().Set(TusVersionHeader, TusVersion)
+		return ErrTusVersionUnsupported
+	}
+	return nil
+}
+
+func tusUploadLocation(bucketName, objectName string) string {
+	return (&url.URL{Path: TusUploadPath + bucketName + "/" + objectName}).EscapedPath()
+}
@@ -0,0 +1,501 @@
+package gater
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	sdkmath "cosmossdk.io/math"
+	sptypes "github.com/evmos/evmos/v12/x/sp/types"
+	storagetypes "github.com/evmos/evmos/v12/x/storage/types"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+
+	commonhttp "github.com/zkMeLabs/mechain-common/go/http"
+	"github.com/zkMeLabs/mechain-storage-provider/core/consensus"
+	coretask "github.com/zkMeLabs/mechain-storage-provider/core/task"
+)
+
+const (
+	mockTusSegmentSize = 16
+	mockTusPayloadSize = 40
+)
+
+var mockTusObjectPath = fmt.Sprintf("%s%s%s%s/%s", scheme, testDomain, TusUploadPath, mockBucketName, mockObjectName)
+
+func mockTusHandlerRoute(t *testing.T, g *GateModular) *mux.Router {
+	t.Helper()
+	router := mux.NewRouter().SkipClean(true)
+	router.Path(TusUploadPath).Name(tusCreateUploadRouterName).Methods(http.MethodPost).HandlerFunc(g.tusCreateUploadHandler)
+	router.Path(TusUploadPath + "{bucket:[^/]*}/{object:.+}").Name(tusGetOffsetRouterName).Methods(http.MethodHead).HandlerFunc(g.tusGetOffsetHandler)
+	router.Path(TusUploadPath + "{bucket:[^/]*}/{object:.+}").Name(tusPatchRouterName).Methods(http.MethodPatch).HandlerFunc(g.tusPatchHandler)
+	router.Path(TusUploadPath + "{bucket:[^/]*}/{object:.+}").Name(tusTerminateRouterName).Methods(http.MethodDelete).HandlerFunc(g.tusTerminateHandler)
+	return router
+}
+
+func mockTusRequest(method, path string, body io.Reader) *http.Request {
+	req := httptest.NewRequest(method, path, body)
+	validExpiryDateStr := time.Now().Add(time.Hour * 60).Format(ExpiryDateFormat)
+	req.Header.Set(commonhttp.HTTPHeaderExpiryTimestamp, validExpiryDateStr)
+	req.Header.Set(GnfdAuthorizationHeader, "GNFD1-EDDSA,Signature=48656c6c6f20476f7068657221")
+	req.Header.Set(GnfdUserAddressHeader, mockStreamAccount)
+	req.Header.Set(TusResumableHeader, TusVersion)
+	return req
+}
+
+func mockTusPatchRequest(offset uint64, body string) *http.Request {
+	req := mockTusRequest(http.MethodPatch, mockTusObjectPath, strings.NewReader(body))
+	req.Header.Set(ContentTypeHeader, ContentTypeOffsetOctetStreamHeaderValue)
+	req.Header.Set(UploadOffsetHeader, fmt.Sprint(offset))
+	return req
+}
+
+func mockTusObjectInfo(status storagetypes.ObjectStatus) *storagetypes.ObjectInfo {
+	return &storagetypes.ObjectInfo{
+		Id:           sdkmath.NewUint(1),
+		BucketName:   mockBucketName,
+		ObjectName:   mockObjectName,
+		Creator:      mockStreamAccount,
+		Owner:        mockStreamAccount,
+		ObjectStatus: status,
+		PayloadSize:  mockTusPayloadSize,
+	}
+}
+
+func mockTusConsensus(ctrl *gomock.Controller, objectInfo *storagetypes.ObjectInfo) *consensus.MockConsensus {
+	consensusMock := consensus.NewMockConsensus(ctrl)
+	consensusMock.EXPECT().QueryBucketInfoAndObjectInfo(gomock.Any(), gomock.Any(), gomock.Any()).Return(
+		&storagetypes.BucketInfo{BucketName: mockBucketName}, objectInfo, nil).AnyTimes()
+	consensusMock.EXPECT().QueryStorageParamsByTimestamp(gomock.Any(), gomock.Any()).Return(
+		&storagetypes.Params{VersionedParams: storagetypes.VersionedParams{MaxSegmentSize: mockTusSegmentSize},
+			MaxPayloadSize: 1024}, nil).AnyTimes()
+	consensusMock.EXPECT().QuerySP(gomock.Any(), gomock.Any()).Return(
+		&sptypes.StorageProvider{Status: sptypes.STATUS_IN_SERVICE}, nil).AnyTimes()
+	consensusMock.EXPECT().QueryBucketInfo(gomock.Any(), gomock.Any()).Return(
+		&storagetypes.BucketInfo{BucketStatus: storagetypes.BUCKET_STATUS_CREATED, Id: sdkmath.NewUint(1)}, nil).AnyTimes()
+	return consensusMock
+}
+
+func sha1Checksum(data string) string {
+	sum := sha1.Sum([]byte(data))
+	return "sha1 " + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func TestParseTusMetadata(t *testing.T) {
+	meta, err := parseTusMetadata("bucket " + base64.StdEncoding.EncodeToString([]byte("b")) + ", object " +
+		base64.StdEncoding.EncodeToString([]byte("dir/o")) + ",is_confidential")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"bucket": "b", "object": "dir/o", "is_confidential": ""}, meta)
+
+	meta, err = parseTusMetadata("")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(meta))
+
+	_, err = parseTusMetadata("bucket !!!")
+	assert.NotNil(t, err)
+	_, err = parseTusMetadata(",")
+	assert.NotNil(t, err)
+}
+
+func TestParseTusChecksum(t *testing.T) {
+	h, checksum, err := parseTusChecksum(sha1Checksum("hello"))
+	assert.Nil(t, err)
+	h.Write([]byte("hello"))
+	assert.Equal(t, checksum, h.Sum(nil))
+
+	_, _, err = parseTusChecksum("crc32 AAAA")
+	assert.Equal(t, ErrTusChecksumUnsupported, err)
+	_, _, err = parseTusChecksum("sha1")
+	assert.Equal(t, ErrTusChecksumUnsupported, err)
+	_, _, err = parseTusChecksum("sha1 !!!")
+	assert.Equal(t, ErrTusChecksumUnsupported, err)
+}
+
+func TestTusChunkSize(t *testing.T) {
+	cases := []struct {
+		name                                        string
+		contentLength, offset, payloadSize, segment uint64
+		wanted                                      uint64
+	}{
+		{name: "aligned chunk", contentLength: 32, offset: 0, payloadSize: 40, segment: 16, wanted: 32},
+		{name: "truncated chunk", contentLength: 20, offset: 0, payloadSize: 40, segment: 16, wanted: 16},
+		{name: "smaller than segment", contentLength: 10, offset: 0, payloadSize: 40, segment: 16, wanted: 0},
+		{name: "last chunk", contentLength: 8, offset: 32, payloadSize: 40, segment: 16, wanted: 8},
+		{name: "exceed the payload", contentLength: 100, offset: 32, payloadSize: 40, segment: 16, wanted: 8},
+		{name: "upload completed", contentLength: 8, offset: 40, payloadSize: 40, segment: 16, wanted: 0},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wanted, tusChunkSize(tt.contentLength, tt.offset, tt.payloadSize, tt.segment))
+		})
+	}
+}
+
+func TestGateModular_tusOptionsHandler(t *testing.T) {
+	g := setup(t)
+	w := httptest.NewRecorder()
+	g.tusOptionsHandler(w, httptest.NewRequest(http.MethodOptions, TusUploadPath, nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, TusVersion, w.Header().Get(TusVersionHeader))
+	assert.Equal(t, TusExtensions, w.Header().Get(TusExtensionHeader))
+	assert.Equal(t, TusChecksumAlgorithms, w.Header().Get(TusChecksumAlgorithmHeader))
+}
+
+func TestGateModular_tusCreateUploadHandler(t *testing.T) {
+	validMetadata := "bucket " + base64.StdEncoding.EncodeToString([]byte(mockBucketName)) + ",object " +
+		base64.StdEncoding.EncodeToString([]byte(mockObjectName))
+	cases := []struct {
+		name         string
+		fn           func() *GateModular
+		request      func() *http.Request
+		wantedCode   int
+		wantedResult string
+	}{
+		{
+			name: "unsupported tus version",
+			fn: func() *GateModular {
+				g, _ := mockUploadProgressStreamGateModular(t, gomock.NewController(t), true, nil)
+				return g
+			},
+			request: func() *http.Request {
+				req := mockTusRequest(http.MethodPost, scheme+testDomain+TusUploadPath, nil)
+				req.Header.Set(TusResumableHeader, "0.2.2")
+				return req
+			},
+			wantedCode:   http.StatusPreconditionFailed,
+			wantedResult: "tus version",
+		},
+		{
+			name: "invalid upload metadata",
+			fn: func() *GateModular {
+				g, _ := mockUploadProgressStreamGateModular(t, gomock.NewController(t), true, nil)
+				return g
+			},
+			request: func() *http.Request {
+				req := mockTusRequest(http.MethodPost, scheme+testDomain+TusUploadPath, nil)
+				req.Header.Set(UploadLengthHeader, fmt.Sprint(mockTusPayloadSize))
+				req.Header.Set(UploadMetadataHeader, "bucket "+base64.StdEncoding.EncodeToString([]byte(mockBucketName)))
+				return req
+			},
+			wantedCode:   http.StatusBadRequest,
+			wantedResult: "metadata",
+		},
+		{
+			name: "upload length mismatch",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				g, _ := mockUploadProgressStreamGateModular(t, ctrl, true, nil)
+				g.baseApp.SetConsensus(mockTusConsensus(ctrl, mockTusObjectInfo(storagetypes.OBJECT_STATUS_CREATED)))
+				return g
+			},
+			request: func() *http.Request {
+				req := mockTusRequest(http.MethodPost, scheme+testDomain+TusUploadPath, nil)
+				req.Header.Set(UploadLengthHeader, "1")
+				req.Header.Set(UploadMetadataHeader, validMetadata)
+				return req
+			},
+			wantedCode:   http.StatusBadRequest,
+			wantedResult: "upload length",
+		},
+		{
+			name: "object has been sealed",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				g, _ := mockUploadProgressStreamGateModular(t, ctrl, true, nil)
+				g.baseApp.SetConsensus(mockTusConsensus(ctrl, mockTusObjectInfo(storagetypes.OBJECT_STATUS_SEALED)))
+				return g
+			},
+			request: func() *http.Request {
+				req := mockTusRequest(http.MethodPost, scheme+testDomain+TusUploadPath, nil)
+				req.Header.Set(UploadLengthHeader, fmt.Sprint(mockTusPayloadSize))
+				req.Header.Set(UploadMetadataHeader, validMetadata)
+				return req
+			},
+			wantedCode:   http.StatusBadRequest,
+			wantedResult: "created state",
+		},
+		{
+			name: "success",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				g, _ := mockUploadProgressStreamGateModular(t, ctrl, true, nil)
+				g.baseApp.SetConsensus(mockTusConsensus(ctrl, mockTusObjectInfo(storagetypes.OBJECT_STATUS_CREATED)))
+				return g
+			},
+			request: func() *http.Request {
+				req := mockTusRequest(http.MethodPost, scheme+testDomain+TusUploadPath, nil)
+				req.Header.Set(UploadLengthHeader, fmt.Sprint(mockTusPayloadSize))
+				req.Header.Set(UploadMetadataHeader, validMetadata)
+				return req
+			},
+			wantedCode: http.StatusCreated,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			router := mockTusHandlerRoute(t, tt.fn())
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, tt.request())
+			assert.Equal(t, tt.wantedCode, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantedResult)
+			assert.Equal(t, TusVersion, w.Header().Get(TusResumableHeader))
+			if tt.wantedCode == http.StatusCreated {
+				assert.Equal(t, TusUploadPath+mockBucketName+"/"+mockObjectName, w.Header().Get(LocationHeader))
+				assert.Equal(t, fmt.Sprint(mockTusSegmentSize), w.Header().Get(GnfdSegmentSizeHeader))
+			}
+		})
+	}
+}
+
+func TestGateModular_tusGetOffsetHandler(t *testing.T) {
+	cases := []struct {
+		name         string
+		fn           func() *GateModular
+		wantedCode   int
+		wantedOffset string
+	}{
+		{
+			name: "object has been sealed",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				g, _ := mockUploadProgressStreamGateModular(t, ctrl, true, nil)
+				g.baseApp.SetConsensus(mockTusConsensus(ctrl, mockTusObjectInfo(storagetypes.OBJECT_STATUS_SEALED)))
+				return g
+			},
+			wantedCode:   http.StatusOK,
+			wantedOffset: fmt.Sprint(mockTusPayloadSize),
+		},
+		{
+			name: "failed to get uploading object segment",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				g, clientMock := mockUploadProgressStreamGateModular(t, ctrl, true, nil)
+				clientMock.EXPECT().GetUploadObjectSegment(gomock.Any(), gomock.Any()).Return(uint32(0), mockErr).Times(1)
+				g.baseApp.SetConsensus(mockTusConsensus(ctrl, mockTusObjectInfo(storagetypes.OBJECT_STATUS_CREATED)))
+				return g
+			},
+			wantedCode: http.StatusInternalServerError,
+		},
+		{
+			name: "object is uploading",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				g, clientMock := mockUploadProgressStreamGateModular(t, ctrl, true, nil)
+				clientMock.EXPECT().GetUploadObjectSegment(gomock.Any(), gomock.Any()).Return(uint32(2), nil).Times(1)
+				g.baseApp.SetConsensus(mockTusConsensus(ctrl, mockTusObjectInfo(storagetypes.OBJECT_STATUS_CREATED)))
+				return g
+			},
+			wantedCode:   http.StatusOK,
+			wantedOffset: fmt.Sprint(2 * mockTusSegmentSize),
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			router := mockTusHandlerRoute(t, tt.fn())
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, mockTusRequest(http.MethodHead, mockTusObjectPath, nil))
+			assert.Equal(t, tt.wantedCode, w.Code)
+			assert.Equal(t, tt.wantedOffset, w.Header().Get(UploadOffsetHeader))
+			if tt.wantedCode == http.StatusOK {
+				assert.Equal(t, fmt.Sprint(mockTusPayloadSize), w.Header().Get(UploadLengthHeader))
+			}
+		})
+	}
+}
+
+func TestGateModular_tusPatchHandler(t *testing.T) {
+	cases := []struct {
+		name         string
+		fn           func() *GateModular
+		request      func() *http.Request
+		wantedCode   int
+		wantedOffset string
+	}{
+		{
+			name: "invalid content type",
+			fn: func() *GateModular {
+				g, _ := mockUploadProgressStreamGateModular(t, gomock.NewController(t), true, nil)
+				return g
+			},
+			request: func() *http.Request {
+				req := mockTusPatchRequest(0, strings.Repeat("a", 16))
+				req.Header.Set(ContentTypeHeader, "application/octet-stream")
+				return req
+			},
+			wantedCode: http.StatusUnsupportedMediaType,
+		},
+		{
+			name: "upload offset mismatch",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				g, clientMock := mockUploadProgressStreamGateModular(t, ctrl, true, nil)
+				clientMock.EXPECT().GetUploadObjectSegment(gomock.Any(), gomock.Any()).Return(uint32(1), nil).Times(1)
+				g.baseApp.SetConsensus(mockTusConsensus(ctrl, mockTusObjectInfo(storagetypes.OBJECT_STATUS_CREATED)))
+				return g
+			},
+			request:    func() *http.Request { return mockTusPatchRequest(0, strings.Repeat("a", 16)) },
+			wantedCode: http.StatusConflict,
+		},
+		{
+			name: "chunk is smaller than the segment size",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				g, clientMock := mockUploadProgressStreamGateModular(t, ctrl, true, nil)
+				clientMock.EXPECT().GetUploadObjectSegment(gomock.Any(), gomock.Any()).Return(uint32(0), nil).Times(1)
+				g.baseApp.SetConsensus(mockTusConsensus(ctrl, mockTusObjectInfo(storagetypes.OBJECT_STATUS_CREATED)))
+				return g
+			},
+			request:    func() *http.Request { return mockTusPatchRequest(0, strings.Repeat("a", 10)) },
+			wantedCode: http.StatusBadRequest,
+		},
+		{
+			name: "checksum mismatch",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				g, clientMock := mockUploadProgressStreamGateModular(t, ctrl, true, nil)
+				clientMock.EXPECT().GetUploadObjectSegment(gomock.Any(), gomock.Any()).Return(uint32(0), nil).Times(1)
+				g.baseApp.SetConsensus(mockTusConsensus(ctrl, mockTusObjectInfo(storagetypes.OBJECT_STATUS_CREATED)))
+				return g
+			},
+			request: func() *http.Request {
+				req := mockTusPatchRequest(0, strings.Repeat("a", 16))
+				req.Header.Set(UploadChecksumHeader, sha1Checksum(strings.Repeat("b", 16)))
+				return req
+			},
+			wantedCode: TusChecksumMismatchStatusCode,
+		},
+		{
+			name: "failed to upload chunk",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				g, clientMock := mockUploadProgressStreamGateModular(t, ctrl, true, nil)
+				clientMock.EXPECT().GetUploadObjectSegment(gomock.Any(), gomock.Any()).Return(uint32(0), nil).Times(1)
+				clientMock.EXPECT().ResumableUploadObject(gomock.Any(), gomock.Any(), gomock.Any()).Return(mockErr).Times(1)
+				g.baseApp.SetConsensus(mockTusConsensus(ctrl, mockTusObjectInfo(storagetypes.OBJECT_STATUS_CREATED)))
+				return g
+			},
+			request:    func() *http.Request { return mockTusPatchRequest(0, strings.Repeat("a", 16)) },
+			wantedCode: http.StatusInternalServerError,
+		},
+		{
+			name: "truncate the chunk to the segment size",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				g, clientMock := mockUploadProgressStreamGateModular(t, ctrl, true, nil)
+				clientMock.EXPECT().GetUploadObjectSegment(gomock.Any(), gomock.Any()).Return(uint32(0), nil).Times(1)
+				clientMock.EXPECT().ResumableUploadObject(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, task coretask.ResumableUploadObjectTask, data io.Reader, _ ...grpc.DialOption) error {
+						chunk, _ := io.ReadAll(data)
+						assert.Equal(t, mockTusSegmentSize, len(chunk))
+						assert.False(t, task.GetCompleted())
+						return nil
+					}).Times(1)
+				g.baseApp.SetConsensus(mockTusConsensus(ctrl, mockTusObjectInfo(storagetypes.OBJECT_STATUS_CREATED)))
+				return g
+			},
+			request:      func() *http.Request { return mockTusPatchRequest(0, strings.Repeat("a", 20)) },
+			wantedCode:   http.StatusNoContent,
+			wantedOffset: fmt.Sprint(mockTusSegmentSize),
+		},
+		{
+			name: "upload the last chunk with checksum",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				g, clientMock := mockUploadProgressStreamGateModular(t, ctrl, true, nil)
+				clientMock.EXPECT().GetUploadObjectSegment(gomock.Any(), gomock.Any()).Return(uint32(2), nil).Times(1)
+				clientMock.EXPECT().ResumableUploadObject(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, task coretask.ResumableUploadObjectTask, _ io.Reader, _ ...grpc.DialOption) error {
+						assert.True(t, task.GetCompleted())
+						assert.Equal(t, uint64(2*mockTusSegmentSize), task.GetResumeOffset())
+						return nil
+					}).Times(1)
+				g.baseApp.SetConsensus(mockTusConsensus(ctrl, mockTusObjectInfo(storagetypes.OBJECT_STATUS_CREATED)))
+				return g
+			},
+			request: func() *http.Request {
+				req := mockTusPatchRequest(2*mockTusSegmentSize, strings.Repeat("a", 8))
+				req.Header.Set(UploadChecksumHeader, sha1Checksum(strings.Repeat("a", 8)))
+				return req
+			},
+			wantedCode:   http.StatusNoContent,
+			wantedOffset: fmt.Sprint(mockTusPayloadSize),
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			router := mockTusHandlerRoute(t, tt.fn())
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, tt.request())
+			assert.Equal(t, tt.wantedCode, w.Code)
+			assert.Equal(t, tt.wantedOffset, w.Header().Get(UploadOffsetHeader))
+		})
+	}
+}
+
+func TestGateModular_tusTerminateHandler(t *testing.T) {
+	cases := []struct {
+		name       string
+		fn         func() *GateModular
+		wantedCode int
+	}{
+		{
+			name: "not the creator or owner",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				g, _ := mockUploadProgressStreamGateModular(t, ctrl, true, nil)
+				objectInfo := mockTusObjectInfo(storagetypes.OBJECT_STATUS_CREATED)
+				objectInfo.Creator = "0x0000000000000000000000000000000000000001"
+				objectInfo.Owner = "0x0000000000000000000000000000000000000001"
+				consensusMock := consensus.NewMockConsensus(ctrl)
+				consensusMock.EXPECT().QueryObjectInfo(gomock.Any(), gomock.Any(), gomock.Any()).Return(objectInfo, nil).Times(1)
+				g.baseApp.SetConsensus(consensusMock)
+				return g
+			},
+			wantedCode: http.StatusUnauthorized,
+		},
+		{
+			name: "object has been sealed",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				g, _ := mockUploadProgressStreamGateModular(t, ctrl, true, nil)
+				consensusMock := consensus.NewMockConsensus(ctrl)
+				consensusMock.EXPECT().QueryObjectInfo(gomock.Any(), gomock.Any(), gomock.Any()).Return(
+					mockTusObjectInfo(storagetypes.OBJECT_STATUS_SEALED), nil).Times(1)
+				g.baseApp.SetConsensus(consensusMock)
+				return g
+			},
+			wantedCode: http.StatusBadRequest,
+		},
+		{
+			name: "success",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				g, clientMock := mockUploadProgressStreamGateModular(t, ctrl, true, nil)
+				clientMock.EXPECT().RejectUnSealObject(gomock.Any(), gomock.Any()).Return("mockTxHash", nil).Times(1)
+				consensusMock := consensus.NewMockConsensus(ctrl)
+				consensusMock.EXPECT().QueryObjectInfo(gomock.Any(), gomock.Any(), gomock.Any()).Return(
+					mockTusObjectInfo(storagetypes.OBJECT_STATUS_CREATED), nil).Times(1)
+				g.baseApp.SetConsensus(consensusMock)
+				return g
+			},
+			wantedCode: http.StatusNoContent,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			router := mockTusHandlerRoute(t, tt.fn())
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, mockTusRequest(http.MethodDelete, mockTusObjectPath, nil))
+			assert.Equal(t, tt.wantedCode, w.Code)
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package gater
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"time"
+
+	storagetypes "github.com/evmos/evmos/v12/x/storage/types"
+
+	"github.com/zkMeLabs/mechain-storage-provider/base/types/gfsperrors"
+	"github.com/zkMeLabs/mechain-storage-provider/base/types/gfsptask"
+	coremodule "github.com/zkMeLabs/mechain-storage-provider/core/module"
+	modelgateway "github.com/zkMeLabs/mechain-storage-provider/model/gateway"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/metrics"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/urlfetch"
+	"github.com/zkMeLabs/mechain-storage-provider/util"
+)
+
+// MaxUploadFromURLBodySize defines the max size of the upload-from-url request body.
+const MaxUploadFromURLBodySize = 4 * 1024
+
+// UploadFromURL is the request body of the upload-from-url api.
+type UploadFromURL struct {
+	XMLName   xml.Name `xml:"UploadFromURL"`
+	SourceURL string   `xml:"SourceURL"`
+}
+
+// UploadFromURLResult is the response body of the upload-from-url api, the upload is processed in
+// the background and its state is queried by the upload-progress api.
+type UploadFromURLResult struct {
+	XMLName    xml.Name `xml:"UploadFromURLResult"`
+	BucketName string   `xml:"BucketName"`
+	ObjectName string   `xml:"ObjectName"`
+	ObjectID   string   `xml:"ObjectID"`
+}
+
+// uploadFromURLHandler handles the request of uploading the payload of a created object from a source url.
+// The source is opened before the response so that the invalid source is reported to the client, then the
+// payload is streamed to the uploader in the background through the same pipeline as putObjectHandler, and
+// the uploader verifies the integrity hash with the checksums of the object on chain before it is sealed.
+func (g *GateModular) uploadFromURLHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err           error
+		reqCtx        *RequestContext
+		authenticated bool
+		body          []byte
+		bucketInfo    *storagetypes.BucketInfo
+		objectInfo    *storagetypes.ObjectInfo
+		params        *storagetypes.Params
+		source        *http.Response
+		b             []byte
+	)
+	uploadPrimaryStartTime := time.Now()
+	defer func() {
+		reqCtx.Cancel()
+		if err != nil {
+			reqCtx.SetError(gfsperrors.MakeGfSpError(err))
+			reqCtx.SetHTTPCode(int(gfsperrors.MakeGfSpError(err).GetHttpStatusCode()))
+			modelgateway.MakeErrorResponse(w, gfsperrors.MakeGfSpError(err))
+			metrics.ReqCounter.WithLabelValues(GatewayTotalFailure).Inc()
+			metrics.ReqTime.WithLabelValues(GatewayTotalFailure).Observe(time.Since(uploadPrimaryStartTime).Seconds())
+		} else {
+			reqCtx.SetHTTPCode(http.StatusAccepted)
+			metrics.ReqCounter.WithLabelValues(GatewayTotalSuccess).Inc()
+			metrics.ReqTime.WithLabelValues(GatewayTotalSuccess).Observe(time.Since(uploadPrimaryStartTime).Seconds())
+		}
+		log.CtxDebugw(reqCtx.Context(), reqCtx.String())
+	}()
+
+	reqCtx, err = NewRequestContext(r, g)
+	if err != nil {
+		return
+	}
+	if g.urlFetcher == nil {
+		err = ErrUploadFromURLDisabled
+		return
+	}
+	body, err = io.ReadAll(io.LimitReader(r.Body, MaxUploadFromURLBodySize))
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to read upload from url body", "error", err)
+		err = ErrExceptionStream
+		return
+	}
+	req := &UploadFromURL{}
+	if err = xml.Unmarshal(body, req); err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to unmarshal upload from url body", "error", err)
+		err = ErrDecodeMsg
+		return
+	}
+	if _, err = urlfetch.ParseURL(req.SourceURL); err != nil {
+		log.CtxErrorw(reqCtx.Context(), "invalid source url", "source_url", req.SourceURL, "error", err)
+		err = ErrInvalidSourceURL
+		return
+	}
+
+	if err = g.checkSPAndBucketStatus(reqCtx.Context(), reqCtx.bucketName, reqCtx.account); err != nil {
+		log.CtxErrorw(reqCtx.Context(), "upload from url failed to check sp and bucket status", "error", err)
+		return
+	}
+	authenticated, err = g.baseApp.GfSpClient().VerifyAuthentication(reqCtx.Context(),
+		coremodule.AuthOpTypePutObject, reqCtx.Account(), reqCtx.bucketName, reqCtx.objectName)
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to verify authentication", "error", err)
+		return
+	}
+	if !authenticated {
+		log.CtxErrorw(reqCtx.Context(), "no permission to operate")
+		err = ErrNoPermission
+		return
+	}
+	bucketInfo, objectInfo, params, err = g.queryUploadingObject(reqCtx)
+	if err != nil {
+		return
+	}
+	if objectInfo.GetObjectStatus() != storagetypes.OBJECT_STATUS_CREATED && !objectInfo.GetIsUpdating() {
+		log.CtxErrorw(reqCtx.Context(), "object is not in created or updating status", "status", objectInfo.GetObjectStatus())
+		err = ErrNotCreatedState
+		return
+	}
+	if objectInfo.GetPayloadSize() == 0 || objectInfo.GetPayloadSize() > g.maxPayloadSize {
+		log.CtxErrorw(reqCtx.Context(), "invalid payload size", "payload_size", objectInfo.GetPayloadSize())
+		err = ErrInvalidPayloadSize
+		return
+	}
+
+	select {
+	case g.uploadFromURLSlots <- struct{}{}:
+	default:
+		err = ErrUploadFromURLBusy
+		return
+	}
+	// the fetch outlives the request, it is bounded by the upload from url timeout instead
+	ctx, cancel := context.WithTimeout(context.Background(), g.uploadFromURLTimeout)
+	source, err = g.urlFetcher.Open(ctx, req.SourceURL)
+	if err == nil && source.ContentLength >= 0 && uint64(source.ContentLength) != objectInfo.GetPayloadSize() {
+		log.CtxErrorw(reqCtx.Context(), "source content length mismatch", "content_length", source.ContentLength,
+			"payload_size", objectInfo.GetPayloadSize())
+		_ = source.Body.Close()
+		err = ErrSourceSizeMismatch
+	}
+	if err != nil {
+		cancel()
+		<-g.uploadFromURLSlots
+		if _, ok := err.(*gfsperrors.GfSpError); !ok {
+			log.CtxErrorw(reqCtx.Context(), "failed to fetch source url", "source_url", req.SourceURL, "error", err)
+			err = ErrFetchSourceURLWithDetail("failed to fetch source url, error: " + err.Error())
+		}
+		return
+	}
+
+	task := &gfsptask.GfSpUploadObjectTask{}
+	task.InitUploadObjectTask(bucketInfo.GetGlobalVirtualGroupFamilyId(), objectInfo, params, g.baseApp.TaskTimeout(task, objectInfo.GetPayloadSize()), false)
+	task.SetCreateTime(uploadPrimaryStartTime.Unix())
+	task.AppendLog("gateway-create-upload-from-url-task")
+	go g.uploadObjectFromSource(log.WithValue(ctx, log.CtxKeyTask, task.Key().String()), cancel, task, source.Body)
+
+	resp := &UploadFromURLResult{
+		BucketName: objectInfo.GetBucketName(),
+		ObjectName: objectInfo.GetObjectName(),
+		ObjectID:   util.Uint64ToString(objectInfo.Id.Uint64()),
+	}
+	b, err = xml.Marshal(resp)
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to marshal upload from url result", "error", err)
+		err = ErrEncodeResponseWithDetail("failed to marshal upload from url result, error: " + err.Error())
+		return
+	}
+	w.Header().Set(ContentTypeHeader, ContentTypeXMLHeaderValue)
+	w.WriteHeader(http.StatusAccepted)
+	if _, err = w.Write(b); err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to write upload from url result", "error", err)
+	}
+	log.CtxInfow(reqCtx.Context(), "succeed to start upload from url", "object_id", resp.ObjectID, "source_url", req.SourceURL)
+}
+
+// uploadObjectFromSource streams the source payload to the uploader, and releases the upload from url slot
+// after it is finished. The payload is limited to one byte more than the payload size so that an oversized
+// source fails the payload size check of the uploader rather than being read endlessly.
+func (g *GateModular) uploadObjectFromSource(ctx context.Context, cancel context.CancelFunc,
+	task *gfsptask.GfSpUploadObjectTask, source io.ReadCloser,
+) {
+	defer func() {
+		_ = source.Close()
+		cancel()
+		<-g.uploadFromURLSlots
+	}()
+	uploadDataTime := time.Now()
+	err := g.baseApp.GfSpClient().UploadObject(ctx, task, io.LimitReader(source, int64(task.GetObjectInfo().GetPayloadSize())+1))
+	metrics.PerfPutObjectTime.WithLabelValues("gateway_upload_from_url_data_cost").Observe(time.Since(uploadDataTime).Seconds())
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to upload payload data from source url", "error", err)
+		return
+	}
+	log.CtxDebug(ctx, "succeed to upload payload data from source url")
+}
@@ -0,0 +1,180 @@
+package gater
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	storagetypes "github.com/evmos/evmos/v12/x/storage/types"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+
+	"github.com/zkMeLabs/mechain-storage-provider/base/gfspclient"
+	coretask "github.com/zkMeLabs/mechain-storage-provider/core/task"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/urlfetch"
+)
+
+func mockUploadFromURLHandlerRoute(t *testing.T, g *GateModular) *mux.Router {
+	t.Helper()
+	router := mux.NewRouter().SkipClean(true)
+	var routers []*mux.Router
+	routers = append(routers, router.Host("{bucket:.+}."+g.domain).Subrouter())
+	routers = append(routers, router.PathPrefix("/{bucket}").Subrouter())
+	for _, r := range routers {
+		r.NewRoute().Name(uploadFromURLRouterName).Methods(http.MethodPost).Path("/{object:.+}").Queries(UploadFromURLQuery, "").
+			HandlerFunc(g.uploadFromURLHandler)
+	}
+	return router
+}
+
+func mockUploadFromURLGateModular(t *testing.T, ctrl *gomock.Controller) (*GateModular, *gfspclient.MockGfSpClientAPI) {
+	g, clientMock := mockUploadProgressStreamGateModular(t, ctrl, true, nil)
+	g.maxPayloadSize = DefaultMaxPayloadSize
+	g.urlFetcher = urlfetch.NewFetcher(urlfetch.Config{AllowPrivateNetwork: true})
+	g.uploadFromURLSlots = make(chan struct{}, 1)
+	g.uploadFromURLTimeout = time.Minute
+	return g, clientMock
+}
+
+func mockUploadFromURLRequest(sourceURL string) *http.Request {
+	path := fmt.Sprintf("%s%s.%s/%s?%s", scheme, mockBucketName, testDomain, mockObjectName, UploadFromURLQuery)
+	return mockTusRequest(http.MethodPost, path,
+		strings.NewReader("<UploadFromURL><SourceURL>"+sourceURL+"</SourceURL></UploadFromURL>"))
+}
+
+func TestGateModular_uploadFromURLHandler(t *testing.T) {
+	payload := strings.Repeat("a", mockTusPayloadSize)
+	source := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/object":
+			_, _ = w.Write([]byte(payload))
+		case "/short":
+			_, _ = w.Write([]byte("a"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer source.Close()
+
+	cases := []struct {
+		name         string
+		fn           func() *GateModular
+		sourceURL    string
+		wantedCode   int
+		wantedResult string
+	}{
+		{
+			name: "upload from url is disabled",
+			fn: func() *GateModular {
+				g, _ := mockUploadProgressStreamGateModular(t, gomock.NewController(t), true, nil)
+				return g
+			},
+			sourceURL:    source.URL + "/object",
+			wantedCode:   http.StatusNotImplemented,
+			wantedResult: "upload from url is not enabled",
+		},
+		{
+			name: "invalid source url",
+			fn: func() *GateModular {
+				g, _ := mockUploadFromURLGateModular(t, gomock.NewController(t))
+				return g
+			},
+			sourceURL:    "file:///etc/passwd",
+			wantedCode:   http.StatusBadRequest,
+			wantedResult: "invalid source url",
+		},
+		{
+			name: "object has been sealed",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				g, _ := mockUploadFromURLGateModular(t, ctrl)
+				g.baseApp.SetConsensus(mockTusConsensus(ctrl, mockTusObjectInfo(storagetypes.OBJECT_STATUS_SEALED)))
+				return g
+			},
+			sourceURL:    source.URL + "/object",
+			wantedCode:   http.StatusBadRequest,
+			wantedResult: "created state",
+		},
+		{
+			name: "too many concurrent uploads",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				g, _ := mockUploadFromURLGateModular(t, ctrl)
+				g.uploadFromURLSlots <- struct{}{}
+				g.baseApp.SetConsensus(mockTusConsensus(ctrl, mockTusObjectInfo(storagetypes.OBJECT_STATUS_CREATED)))
+				return g
+			},
+			sourceURL:    source.URL + "/object",
+			wantedCode:   http.StatusServiceUnavailable,
+			wantedResult: "too many concurrent uploads",
+		},
+		{
+			name: "source is not found",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				g, _ := mockUploadFromURLGateModular(t, ctrl)
+				g.baseApp.SetConsensus(mockTusConsensus(ctrl, mockTusObjectInfo(storagetypes.OBJECT_STATUS_CREATED)))
+				return g
+			},
+			sourceURL:    source.URL + "/missing",
+			wantedCode:   http.StatusBadRequest,
+			wantedResult: "failed to fetch source url",
+		},
+		{
+			name: "source content length mismatch",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				g, _ := mockUploadFromURLGateModular(t, ctrl)
+				g.baseApp.SetConsensus(mockTusConsensus(ctrl, mockTusObjectInfo(storagetypes.OBJECT_STATUS_CREATED)))
+				return g
+			},
+			sourceURL:    source.URL + "/short",
+			wantedCode:   http.StatusBadRequest,
+			wantedResult: "source content length",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			g := tt.fn()
+			router := mockUploadFromURLHandlerRoute(t, g)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, mockUploadFromURLRequest(tt.sourceURL))
+			assert.Equal(t, tt.wantedCode, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantedResult)
+		})
+	}
+
+	t.Run("upload the source in the background", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		g, clientMock := mockUploadFromURLGateModular(t, ctrl)
+		g.baseApp.SetConsensus(mockTusConsensus(ctrl, mockTusObjectInfo(storagetypes.OBJECT_STATUS_CREATED)))
+		uploaded := make(chan string, 1)
+		clientMock.EXPECT().UploadObject(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, task coretask.UploadObjectTask, stream io.Reader, _ ...grpc.DialOption) error {
+				assert.Equal(t, uint64(1), task.GetObjectInfo().Id.Uint64())
+				data, _ := io.ReadAll(stream)
+				uploaded <- string(data)
+				return nil
+			}).Times(1)
+
+		router := mockUploadFromURLHandlerRoute(t, g)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, mockUploadFromURLRequest(source.URL+"/object"))
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Contains(t, w.Body.String(), "<ObjectID>1</ObjectID>")
+		select {
+		case data := <-uploaded:
+			assert.Equal(t, payload, data)
+		case <-time.After(5 * time.Second):
+			t.Fatal("the source is not uploaded")
+		}
+		assert.Eventually(t, func() bool { return len(g.uploadFromURLSlots) == 0 }, time.Second, time.Millisecond)
+	})
+}
@@ -0,0 +1,103 @@
+package gater
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/uploadprogress"
+)
+
+// uploadProgressPollFunc returns the current upload state of the object as an upload progress event.
+type uploadProgressPollFunc func(ctx context.Context) (*uploadprogress.Event, error)
+
+// uploadProgressPoller polls the upload states of the streamed objects when the hub of the gateway does
+// not receive the transitions of the managers in other processes. The object is polled by one goroutine
+// shared by all its streams, and the polled states are fanned out by the hub of the poller.
+type uploadProgressPoller struct {
+	hub     *uploadprogress.Hub
+	mu      sync.Mutex
+	objects map[uint64]*objectProgressPoll
+}
+
+// objectProgressPoll is the shared poll of an object.
+type objectProgressPoll struct {
+	refs   int
+	cancel context.CancelFunc
+}
+
+func newUploadProgressPoller() *uploadProgressPoller {
+	return &uploadProgressPoller{
+		hub:     uploadprogress.NewHub(false),
+		objects: make(map[uint64]*objectProgressPoll),
+	}
+}
+
+// subscribe returns the subscription of the polled states of the object, the object is polled by poll
+// every uploadProgressStreamPollInterval until all its subscriptions are released or the state is final.
+func (p *uploadProgressPoller) subscribe(objectID uint64, poll uploadProgressPollFunc) (*uploadprogress.Subscription, func()) {
+	sub := p.hub.Subscribe(uploadprogress.ObjectFilter(objectID), 0)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	op, ok := p.objects[objectID]
+	if !ok {
+		// the poll is detached from the stream which starts it, it is shared by the later streams
+		ctx, cancel := context.WithCancel(context.Background())
+		op = &objectProgressPoll{cancel: cancel}
+		p.objects[objectID] = op
+		go p.poll(ctx, objectID, poll)
+	}
+	op.refs++
+	var once sync.Once
+	return sub, func() {
+		once.Do(func() {
+			sub.Cancel()
+			p.release(objectID, op)
+		})
+	}
+}
+
+func (p *uploadProgressPoller) release(objectID uint64, op *objectProgressPoll) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if op.refs--; op.refs == 0 {
+		op.cancel()
+		if p.objects[objectID] == op {
+			delete(p.objects, objectID)
+		}
+	}
+}
+
+func (p *uploadProgressPoller) poll(ctx context.Context, objectID uint64, poll uploadProgressPollFunc) {
+	ticker := time.NewTicker(uploadProgressStreamPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		event, err := poll(ctx)
+		if err != nil {
+			log.CtxErrorw(ctx, "failed to poll upload progress", "object_id", objectID, "error", err)
+			continue
+		}
+		p.hub.Publish(event)
+		if isUploadProgressTerminal(event.State) {
+			p.finish(objectID)
+			return
+		}
+	}
+}
+
+// finish stops sharing the poll of the object in the final state, the streams subscribing it later start
+// a new poll.
+func (p *uploadProgressPoller) finish(objectID uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.objects, objectID)
+}
@@ -0,0 +1,56 @@
+package gater
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/uploadprogress"
+	servicetypes "github.com/zkMeLabs/mechain-storage-provider/store/types"
+)
+
+func TestUploadProgressPoller(t *testing.T) {
+	pollInterval := uploadProgressStreamPollInterval
+	uploadProgressStreamPollInterval = 10 * time.Millisecond
+	defer func() { uploadProgressStreamPollInterval = pollInterval }()
+
+	p := newUploadProgressPoller()
+	var polls atomic.Int32
+	state := atomic.Int32{}
+	state.Store(int32(servicetypes.TaskState_TASK_STATE_SEAL_OBJECT_DOING))
+	poll := func(ctx context.Context) (*uploadprogress.Event, error) {
+		if polls.Add(1) == 1 {
+			return nil, errors.New("mock error")
+		}
+		return &uploadprogress.Event{ObjectID: 1, State: state.Load()}, nil
+	}
+
+	// the streams of the object share one poll
+	sub1, release1 := p.subscribe(1, poll)
+	sub2, release2 := p.subscribe(1, poll)
+	assert.Equal(t, 1, len(p.objects))
+	assert.Equal(t, int32(servicetypes.TaskState_TASK_STATE_SEAL_OBJECT_DOING), (<-sub1.C()).State)
+	assert.Equal(t, int32(servicetypes.TaskState_TASK_STATE_SEAL_OBJECT_DOING), (<-sub2.C()).State)
+
+	// the poll continues until all the streams are released
+	release1()
+	release1()
+	assert.Equal(t, 1, len(p.objects))
+	release2()
+	assert.Equal(t, 0, len(p.objects))
+
+	// the poll stops at the final state
+	state.Store(int32(servicetypes.TaskState_TASK_STATE_SEAL_OBJECT_DONE))
+	sub3, release3 := p.subscribe(1, poll)
+	defer release3()
+	assert.Equal(t, int32(servicetypes.TaskState_TASK_STATE_SEAL_OBJECT_DONE), (<-sub3.C()).State)
+	assert.Eventually(t, func() bool {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		return len(p.objects) == 0
+	}, time.Second, time.Millisecond)
+}
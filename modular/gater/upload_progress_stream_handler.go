@@ -0,0 +1,304 @@
+package gater
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	storagetypes "github.com/evmos/evmos/v12/x/storage/types"
+
+	"github.com/zkMeLabs/mechain-storage-provider/base/types/gfsperrors"
+	coremodule "github.com/zkMeLabs/mechain-storage-provider/core/module"
+	modelgateway "github.com/zkMeLabs/mechain-storage-provider/model/gateway"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/metrics"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/uploadprogress"
+	servicetypes "github.com/zkMeLabs/mechain-storage-provider/store/types"
+	"github.com/zkMeLabs/mechain-storage-provider/util"
+)
+
+// UploadProgressStreamEvent defines the server-sent event name of the upload progress.
+const UploadProgressStreamEvent = "progress"
+
+var (
+	// uploadProgressStreamPollInterval defines the interval of querying the upload state, it makes the object
+	// stream work when the manager runs in another process and the hub is not shared by redis, the object is
+	// polled once for all its streams.
+	uploadProgressStreamPollInterval = 5 * time.Second
+	// uploadProgressStreamHeartbeatInterval defines the interval of the heartbeat comment which keeps
+	// the idle connection alive through the proxies.
+	uploadProgressStreamHeartbeatInterval = 15 * time.Second
+	// uploadProgressStreamMaxDuration defines the max duration of a stream, the client is expected to reconnect.
+	uploadProgressStreamMaxDuration = 30 * time.Minute
+)
+
+// uploadProgressStreamHandler streams the upload state transitions of an object as server-sent events,
+// the stream ends after the object is sealed or failed.
+func (g *GateModular) uploadProgressStreamHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err           error
+		reqCtx        *RequestContext
+		authenticated bool
+		objectInfo    *storagetypes.ObjectInfo
+		sub           *uploadprogress.Subscription
+		current       *uploadprogress.Event
+		stream        *uploadProgressStream
+	)
+	startTime := time.Now()
+	defer func() {
+		reqCtx.Cancel()
+		if err != nil {
+			reqCtx.SetError(gfsperrors.MakeGfSpError(err))
+			reqCtx.SetHTTPCode(int(gfsperrors.MakeGfSpError(err).GetHttpStatusCode()))
+			modelgateway.MakeErrorResponse(w, gfsperrors.MakeGfSpError(err))
+			metrics.ReqCounter.WithLabelValues(GatewayTotalFailure).Inc()
+			metrics.ReqTime.WithLabelValues(GatewayTotalFailure).Observe(time.Since(startTime).Seconds())
+		} else {
+			reqCtx.SetHTTPCode(http.StatusOK)
+			metrics.ReqCounter.WithLabelValues(GatewayTotalSuccess).Inc()
+			metrics.ReqTime.WithLabelValues(GatewayTotalSuccess).Observe(time.Since(startTime).Seconds())
+		}
+		log.CtxDebugw(reqCtx.Context(), reqCtx.String())
+	}()
+
+	reqCtx, err = NewRequestContext(r, g)
+	if err != nil {
+		return
+	}
+	authenticated, err = g.baseApp.GfSpClient().VerifyAuthentication(reqCtx.Context(),
+		coremodule.AuthOpTypeGetUploadingState, reqCtx.Account(), reqCtx.bucketName, reqCtx.objectName)
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to verify authentication", "error", err)
+		return
+	}
+	if !authenticated {
+		log.CtxErrorw(reqCtx.Context(), "no permission to operate")
+		err = ErrNoPermission
+		return
+	}
+	objectInfo, err = g.baseApp.Consensus().QueryObjectInfo(reqCtx.Context(), reqCtx.bucketName, reqCtx.objectName)
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to get object info from consensus", "error", err)
+		err = ErrConsensusWithDetail("failed to get object info from consensus, object_name: " + reqCtx.objectName + ", bucket_name: " + reqCtx.bucketName + " ,error: " + err.Error())
+		return
+	}
+	// subscribe before querying the current state, so that no transition is missed in between
+	if hub := g.baseApp.UploadProgress(); hub != nil && hub.Complete() {
+		sub = hub.Subscribe(uploadprogress.ObjectFilter(objectInfo.Id.Uint64()), 0)
+		defer sub.Cancel()
+	}
+	current, err = g.queryUploadProgressEvent(reqCtx.Context(), objectInfo)
+	if err != nil {
+		return
+	}
+	if stream, err = newUploadProgressStream(w); err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to start upload progress stream", "error", err)
+		return
+	}
+	// the response has been started, the following errors only end the stream
+	if writeErr := stream.send(current); writeErr != nil || isUploadProgressTerminal(current.State) {
+		return
+	}
+	if sub == nil {
+		// the transitions are not received by the hub, the polled states are subscribed instead
+		objectID := util.Uint64ToString(objectInfo.Id.Uint64())
+		var release func()
+		sub, release = g.progressPoller.subscribe(objectInfo.Id.Uint64(), func(ctx context.Context) (*uploadprogress.Event, error) {
+			latest, queryErr := g.baseApp.Consensus().QueryObjectInfoByID(ctx, objectID)
+			if queryErr != nil {
+				return nil, queryErr
+			}
+			return g.queryUploadProgressEvent(ctx, latest)
+		})
+		defer release()
+	}
+
+	events := sub.C()
+	heartbeatTicker := time.NewTicker(uploadProgressStreamHeartbeatInterval)
+	defer heartbeatTicker.Stop()
+	deadline := time.NewTimer(uploadProgressStreamMaxDuration)
+	defer deadline.Stop()
+	for {
+		var next *uploadprogress.Event
+		select {
+		case <-r.Context().Done():
+			return
+		case <-deadline.C:
+			return
+		case <-heartbeatTicker.C:
+			if writeErr := stream.heartbeat(); writeErr != nil {
+				return
+			}
+			continue
+		case event, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			next = event
+		}
+		if next.State == current.State && next.ErrorDescription == current.ErrorDescription {
+			continue
+		}
+		current = next
+		if writeErr := stream.send(current); writeErr != nil || isUploadProgressTerminal(current.State) {
+			return
+		}
+	}
+}
+
+// bucketUploadProgressStreamHandler streams the upload state transitions of all objects in the bucket as
+// server-sent events, only the bucket owner is allowed. The transitions are received from the manager in
+// the same process or the managers sharing the hub by redis, it is not supported if the hub does not receive
+// the transitions of all the managers. The stream ends after uploadProgressStreamMaxDuration and the client
+// is expected to reconnect.
+func (g *GateModular) bucketUploadProgressStreamHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err        error
+		reqCtx     *RequestContext
+		bucketInfo *storagetypes.BucketInfo
+		hub        *uploadprogress.Hub
+		stream     *uploadProgressStream
+	)
+	startTime := time.Now()
+	defer func() {
+		reqCtx.Cancel()
+		if err != nil {
+			reqCtx.SetError(gfsperrors.MakeGfSpError(err))
+			reqCtx.SetHTTPCode(int(gfsperrors.MakeGfSpError(err).GetHttpStatusCode()))
+			modelgateway.MakeErrorResponse(w, gfsperrors.MakeGfSpError(err))
+			metrics.ReqCounter.WithLabelValues(GatewayTotalFailure).Inc()
+			metrics.ReqTime.WithLabelValues(GatewayTotalFailure).Observe(time.Since(startTime).Seconds())
+		} else {
+			reqCtx.SetHTTPCode(http.StatusOK)
+			metrics.ReqCounter.WithLabelValues(GatewayTotalSuccess).Inc()
+			metrics.ReqTime.WithLabelValues(GatewayTotalSuccess).Observe(time.Since(startTime).Seconds())
+		}
+		log.CtxDebugw(reqCtx.Context(), reqCtx.String())
+	}()
+
+	reqCtx, err = NewRequestContext(r, g)
+	if err != nil {
+		return
+	}
+	bucketInfo, err = g.baseApp.Consensus().QueryBucketInfo(reqCtx.Context(), reqCtx.bucketName)
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to get bucket info from consensus", "error", err)
+		err = ErrConsensusWithDetail("failed to get bucket info from consensus, bucket_name: " + reqCtx.bucketName + ", error: " + err.Error())
+		return
+	}
+	if bucketInfo.GetOwner() != reqCtx.Account() {
+		log.CtxErrorw(reqCtx.Context(), "no permission to operate", "owner", bucketInfo.GetOwner(), "account", reqCtx.Account())
+		err = ErrNoPermission
+		return
+	}
+	if hub = g.baseApp.UploadProgress(); hub == nil || !hub.Complete() {
+		log.CtxErrorw(reqCtx.Context(), "no upload progress hub receiving the transitions of all the managers")
+		err = ErrStreamingUnsupported
+		return
+	}
+	sub := hub.Subscribe(uploadprogress.BucketFilter(reqCtx.bucketName), 0)
+	defer sub.Cancel()
+	if stream, err = newUploadProgressStream(w); err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to start upload progress stream", "error", err)
+		return
+	}
+
+	heartbeatTicker := time.NewTicker(uploadProgressStreamHeartbeatInterval)
+	defer heartbeatTicker.Stop()
+	deadline := time.NewTimer(uploadProgressStreamMaxDuration)
+	defer deadline.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-deadline.C:
+			return
+		case <-heartbeatTicker.C:
+			if writeErr := stream.heartbeat(); writeErr != nil {
+				return
+			}
+		case event, ok := <-sub.C():
+			if !ok {
+				return
+			}
+			if writeErr := stream.send(event); writeErr != nil {
+				return
+			}
+		}
+	}
+}
+
+// queryUploadProgressEvent returns the current upload state of the object as an upload progress event.
+func (g *GateModular) queryUploadProgressEvent(ctx context.Context, objectInfo *storagetypes.ObjectInfo) (*uploadprogress.Event, error) {
+	taskState, taskStateDescription, errDescription, err := g.getUploadProgressState(ctx, objectInfo)
+	if err != nil {
+		return nil, err
+	}
+	return &uploadprogress.Event{
+		BucketName:       objectInfo.GetBucketName(),
+		ObjectName:       objectInfo.GetObjectName(),
+		ObjectID:         objectInfo.Id.Uint64(),
+		State:            taskState,
+		StateDescription: taskStateDescription,
+		ErrorDescription: errDescription,
+		Timestamp:        time.Now().Unix(),
+	}, nil
+}
+
+// isUploadProgressTerminal returns an indicator whether the upload state is final.
+func isUploadProgressTerminal(state int32) bool {
+	switch servicetypes.TaskState(state) {
+	case servicetypes.TaskState_TASK_STATE_SEAL_OBJECT_DONE,
+		servicetypes.TaskState_TASK_STATE_OBJECT_DISCONTINUED,
+		servicetypes.TaskState_TASK_STATE_UPLOAD_OBJECT_ERROR,
+		servicetypes.TaskState_TASK_STATE_ALLOC_SECONDARY_ERROR,
+		servicetypes.TaskState_TASK_STATE_REPLICATE_OBJECT_ERROR,
+		servicetypes.TaskState_TASK_STATE_SIGN_OBJECT_ERROR,
+		servicetypes.TaskState_TASK_STATE_SEAL_OBJECT_ERROR:
+		return true
+	default:
+		return false
+	}
+}
+
+// uploadProgressStream writes the upload progress events in the server-sent events format.
+type uploadProgressStream struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+func newUploadProgressStream(w http.ResponseWriter) (*uploadProgressStream, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, ErrStreamingUnsupported
+	}
+	w.Header().Set(ContentTypeHeader, ContentTypeEventStreamHeaderValue)
+	w.Header().Set(CacheControlHeader, "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	return &uploadProgressStream{w: w, flusher: flusher}, nil
+}
+
+func (s *uploadProgressStream) send(event *uploadprogress.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if _, err = fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", UploadProgressStreamEvent, data); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+func (s *uploadProgressStream) heartbeat() error {
+	if _, err := fmt.Fprint(s.w, ": heartbeat\n\n"); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
@@ -0,0 +1,320 @@
+package gater
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	sdkmath "cosmossdk.io/math"
+	storagetypes "github.com/evmos/evmos/v12/x/storage/types"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	commonhttp "github.com/zkMeLabs/mechain-common/go/http"
+	"github.com/zkMeLabs/mechain-storage-provider/base/gfspclient"
+	"github.com/zkMeLabs/mechain-storage-provider/core/consensus"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/uploadprogress"
+	servicetypes "github.com/zkMeLabs/mechain-storage-provider/store/types"
+)
+
+const mockStreamAccount = "0x76d244CE05c3De4BbC6fDd7F56379B145709ade9"
+
+// notifyResponseRecorder signals every write of the response body, so that the tests know the event has
+// been streamed before ending the request.
+type notifyResponseRecorder struct {
+	*httptest.ResponseRecorder
+	written chan struct{}
+}
+
+func (n *notifyResponseRecorder) Write(b []byte) (int, error) {
+	size, err := n.ResponseRecorder.Write(b)
+	select {
+	case n.written <- struct{}{}:
+	default:
+	}
+	return size, err
+}
+
+func mockUploadProgressStreamHandlerRoute(t *testing.T, g *GateModular) *mux.Router {
+	t.Helper()
+	router := mux.NewRouter().SkipClean(true)
+	var routers []*mux.Router
+	routers = append(routers, router.Host("{bucket:.+}."+g.domain).Subrouter())
+	routers = append(routers, router.PathPrefix("/{bucket}").Subrouter())
+	for _, r := range routers {
+		r.NewRoute().Name(uploadProgressStreamRouterName).Methods(http.MethodGet).Path("/{object:.+}").HandlerFunc(g.uploadProgressStreamHandler).
+			Queries(UploadProgressStreamQuery, "")
+		r.NewRoute().Name(bucketUploadProgressStreamRouterName).Methods(http.MethodGet).HandlerFunc(g.bucketUploadProgressStreamHandler).
+			Queries(UploadProgressStreamQuery, "")
+	}
+	return router
+}
+
+func mockUploadProgressStreamRequest(path string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, path, strings.NewReader(""))
+	validExpiryDateStr := time.Now().Add(time.Hour * 60).Format(ExpiryDateFormat)
+	req.Header.Set(commonhttp.HTTPHeaderExpiryTimestamp, validExpiryDateStr)
+	req.Header.Set(GnfdAuthorizationHeader, "GNFD1-EDDSA,Signature=48656c6c6f20476f7068657221")
+	req.Header.Set(GnfdUserAddressHeader, mockStreamAccount)
+	return req
+}
+
+func mockUploadProgressStreamGateModular(t *testing.T, ctrl *gomock.Controller, authenticated bool, authErr error) (*GateModular, *gfspclient.MockGfSpClientAPI) {
+	g := setup(t)
+	clientMock := gfspclient.NewMockGfSpClientAPI(ctrl)
+	clientMock.EXPECT().VerifyGNFD1EddsaSignature(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
+		gomock.Any()).Return(false, nil).Times(1)
+	clientMock.EXPECT().VerifyAuthentication(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
+		gomock.Any()).Return(authenticated, authErr).AnyTimes()
+	g.baseApp.SetGfSpClient(clientMock)
+	return g, clientMock
+}
+
+func waitForSubscriber(t *testing.T, hub *uploadprogress.Hub) {
+	t.Helper()
+	assert.Eventually(t, func() bool { return hub.SubscriberNumber() > 0 }, time.Second, time.Millisecond)
+}
+
+func TestGateModular_uploadProgressStreamHandler(t *testing.T) {
+	objectPath := fmt.Sprintf("%s%s.%s/%s?%s", scheme, mockBucketName, testDomain, mockObjectName, UploadProgressStreamQuery)
+	cases := []struct {
+		name          string
+		fn            func() *GateModular
+		wantedCode    int
+		wantedResults []string
+	}{
+		{
+			name: "failed to verify authentication",
+			fn: func() *GateModular {
+				g, _ := mockUploadProgressStreamGateModular(t, gomock.NewController(t), false, mockErr)
+				return g
+			},
+			wantedCode:    http.StatusInternalServerError,
+			wantedResults: []string{"mock error"},
+		},
+		{
+			name: "no permission to operate",
+			fn: func() *GateModular {
+				g, _ := mockUploadProgressStreamGateModular(t, gomock.NewController(t), false, nil)
+				return g
+			},
+			wantedCode:    http.StatusUnauthorized,
+			wantedResults: []string{"no permission"},
+		},
+		{
+			name: "failed to get object info from consensus",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				g, _ := mockUploadProgressStreamGateModular(t, ctrl, true, nil)
+				consensusMock := consensus.NewMockConsensus(ctrl)
+				consensusMock.EXPECT().QueryObjectInfo(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, mockErr).Times(1)
+				g.baseApp.SetConsensus(consensusMock)
+				return g
+			},
+			wantedCode:    http.StatusInternalServerError,
+			wantedResults: []string{"failed to get object info from consensus"},
+		},
+		{
+			name: "failed to get uploading job state",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				g, clientMock := mockUploadProgressStreamGateModular(t, ctrl, true, nil)
+				clientMock.EXPECT().GetUploadObjectState(gomock.Any(), gomock.Any()).Return(int32(0), "", mockErr).Times(1)
+				consensusMock := consensus.NewMockConsensus(ctrl)
+				consensusMock.EXPECT().QueryObjectInfo(gomock.Any(), gomock.Any(), gomock.Any()).Return(
+					&storagetypes.ObjectInfo{ObjectStatus: storagetypes.OBJECT_STATUS_CREATED, Id: sdkmath.NewUint(1)}, nil).Times(1)
+				g.baseApp.SetConsensus(consensusMock)
+				return g
+			},
+			wantedCode:    http.StatusInternalServerError,
+			wantedResults: []string{"mock error"},
+		},
+		{
+			name: "object has been sealed",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				g, _ := mockUploadProgressStreamGateModular(t, ctrl, true, nil)
+				consensusMock := consensus.NewMockConsensus(ctrl)
+				consensusMock.EXPECT().QueryObjectInfo(gomock.Any(), gomock.Any(), gomock.Any()).Return(
+					&storagetypes.ObjectInfo{ObjectStatus: storagetypes.OBJECT_STATUS_SEALED, Id: sdkmath.NewUint(1)}, nil).Times(1)
+				g.baseApp.SetConsensus(consensusMock)
+				return g
+			},
+			wantedCode:    http.StatusOK,
+			wantedResults: []string{"event: progress", `"state":14`},
+		},
+		{
+			name: "stream transitions from hub",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				g, clientMock := mockUploadProgressStreamGateModular(t, ctrl, true, nil)
+				clientMock.EXPECT().GetUploadObjectState(gomock.Any(), gomock.Any()).Return(
+					int32(servicetypes.TaskState_TASK_STATE_UPLOAD_OBJECT_DOING), "", nil).Times(1)
+				consensusMock := consensus.NewMockConsensus(ctrl)
+				consensusMock.EXPECT().QueryObjectInfo(gomock.Any(), gomock.Any(), gomock.Any()).Return(
+					&storagetypes.ObjectInfo{ObjectStatus: storagetypes.OBJECT_STATUS_CREATED, Id: sdkmath.NewUint(1)}, nil).Times(1)
+				consensusMock.EXPECT().QueryObjectInfoByID(gomock.Any(), gomock.Any()).Return(nil, mockErr).AnyTimes()
+				g.baseApp.SetConsensus(consensusMock)
+				hub := uploadprogress.NewHub(true)
+				g.baseApp.SetUploadProgress(hub)
+				go func() {
+					waitForSubscriber(t, hub)
+					hub.Publish(&uploadprogress.Event{BucketName: mockBucketName, ObjectID: 2,
+						State: int32(servicetypes.TaskState_TASK_STATE_REPLICATE_OBJECT_DOING)})
+					hub.Publish(&uploadprogress.Event{BucketName: mockBucketName, ObjectID: 1,
+						State: int32(servicetypes.TaskState_TASK_STATE_SEAL_OBJECT_DOING)})
+					hub.Publish(&uploadprogress.Event{BucketName: mockBucketName, ObjectID: 1,
+						State: int32(servicetypes.TaskState_TASK_STATE_SEAL_OBJECT_DONE)})
+				}()
+				return g
+			},
+			wantedCode:    http.StatusOK,
+			wantedResults: []string{`"state":1,`, `"state":13,`, `"state":14,`},
+		},
+		{
+			name: "stream transitions by polling",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				g, clientMock := mockUploadProgressStreamGateModular(t, ctrl, true, nil)
+				clientMock.EXPECT().GetUploadObjectState(gomock.Any(), gomock.Any()).Return(
+					int32(servicetypes.TaskState_TASK_STATE_SEAL_OBJECT_DOING), "", nil).Times(1)
+				consensusMock := consensus.NewMockConsensus(ctrl)
+				consensusMock.EXPECT().QueryObjectInfo(gomock.Any(), gomock.Any(), gomock.Any()).Return(
+					&storagetypes.ObjectInfo{ObjectStatus: storagetypes.OBJECT_STATUS_CREATED, Id: sdkmath.NewUint(1)}, nil).Times(1)
+				consensusMock.EXPECT().QueryObjectInfoByID(gomock.Any(), gomock.Any()).Return(
+					&storagetypes.ObjectInfo{ObjectStatus: storagetypes.OBJECT_STATUS_SEALED, Id: sdkmath.NewUint(1)}, nil).Times(1)
+				g.baseApp.SetConsensus(consensusMock)
+				return g
+			},
+			wantedCode:    http.StatusOK,
+			wantedResults: []string{`"state":13,`, `"state":14,`},
+		},
+	}
+
+	pollInterval := uploadProgressStreamPollInterval
+	uploadProgressStreamPollInterval = 10 * time.Millisecond
+	defer func() { uploadProgressStreamPollInterval = pollInterval }()
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			router := mockUploadProgressStreamHandlerRoute(t, tt.fn())
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, mockUploadProgressStreamRequest(objectPath))
+			assert.Equal(t, tt.wantedCode, w.Code)
+			for _, result := range tt.wantedResults {
+				assert.Contains(t, w.Body.String(), result)
+			}
+			if tt.wantedCode == http.StatusOK {
+				assert.Equal(t, ContentTypeEventStreamHeaderValue, w.Header().Get(ContentTypeHeader))
+			}
+		})
+	}
+}
+
+func TestGateModular_bucketUploadProgressStreamHandler(t *testing.T) {
+	bucketPath := fmt.Sprintf("%s%s.%s?%s", scheme, mockBucketName, testDomain, UploadProgressStreamQuery)
+	cases := []struct {
+		name         string
+		fn           func() *GateModular
+		wantedCode   int
+		wantedResult string
+	}{
+		{
+			name: "failed to get bucket info from consensus",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				g, _ := mockUploadProgressStreamGateModular(t, ctrl, true, nil)
+				consensusMock := consensus.NewMockConsensus(ctrl)
+				consensusMock.EXPECT().QueryBucketInfo(gomock.Any(), gomock.Any()).Return(nil, mockErr).Times(1)
+				g.baseApp.SetConsensus(consensusMock)
+				return g
+			},
+			wantedCode:   http.StatusInternalServerError,
+			wantedResult: "failed to get bucket info from consensus",
+		},
+		{
+			name: "not the bucket owner",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				g, _ := mockUploadProgressStreamGateModular(t, ctrl, true, nil)
+				consensusMock := consensus.NewMockConsensus(ctrl)
+				consensusMock.EXPECT().QueryBucketInfo(gomock.Any(), gomock.Any()).Return(
+					&storagetypes.BucketInfo{Owner: "0x0000000000000000000000000000000000000001"}, nil).Times(1)
+				g.baseApp.SetConsensus(consensusMock)
+				return g
+			},
+			wantedCode:   http.StatusUnauthorized,
+			wantedResult: "no permission",
+		},
+		{
+			name: "hub does not receive the transitions of all the managers",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				g, _ := mockUploadProgressStreamGateModular(t, ctrl, true, nil)
+				consensusMock := consensus.NewMockConsensus(ctrl)
+				consensusMock.EXPECT().QueryBucketInfo(gomock.Any(), gomock.Any()).Return(
+					&storagetypes.BucketInfo{Owner: mockStreamAccount}, nil).Times(1)
+				g.baseApp.SetConsensus(consensusMock)
+				g.baseApp.SetUploadProgress(uploadprogress.NewHub(false))
+				return g
+			},
+			wantedCode:   http.StatusInternalServerError,
+			wantedResult: "streaming is not supported",
+		},
+		{
+			name: "no upload progress hub",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				g, _ := mockUploadProgressStreamGateModular(t, ctrl, true, nil)
+				consensusMock := consensus.NewMockConsensus(ctrl)
+				consensusMock.EXPECT().QueryBucketInfo(gomock.Any(), gomock.Any()).Return(
+					&storagetypes.BucketInfo{Owner: mockStreamAccount}, nil).Times(1)
+				g.baseApp.SetConsensus(consensusMock)
+				return g
+			},
+			wantedCode:   http.StatusInternalServerError,
+			wantedResult: "streaming is not supported",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			router := mockUploadProgressStreamHandlerRoute(t, tt.fn())
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, mockUploadProgressStreamRequest(bucketPath))
+			assert.Equal(t, tt.wantedCode, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantedResult)
+		})
+	}
+
+	t.Run("stream the events of the bucket", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		g, _ := mockUploadProgressStreamGateModular(t, ctrl, true, nil)
+		consensusMock := consensus.NewMockConsensus(ctrl)
+		consensusMock.EXPECT().QueryBucketInfo(gomock.Any(), gomock.Any()).Return(
+			&storagetypes.BucketInfo{Owner: mockStreamAccount}, nil).Times(1)
+		g.baseApp.SetConsensus(consensusMock)
+		hub := uploadprogress.NewHub(true)
+		g.baseApp.SetUploadProgress(hub)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		w := &notifyResponseRecorder{ResponseRecorder: httptest.NewRecorder(), written: make(chan struct{}, 1)}
+		go func() {
+			waitForSubscriber(t, hub)
+			hub.Publish(&uploadprogress.Event{BucketName: "other", ObjectName: "other-object", ObjectID: 2})
+			hub.Publish(&uploadprogress.Event{BucketName: mockBucketName, ObjectName: mockObjectName, ObjectID: 1,
+				State: int32(servicetypes.TaskState_TASK_STATE_SEAL_OBJECT_DONE)})
+			<-w.written
+			cancel()
+		}()
+		router := mockUploadProgressStreamHandlerRoute(t, g)
+		router.ServeHTTP(w, mockUploadProgressStreamRequest(bucketPath).WithContext(ctx))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"object_name":"`+mockObjectName+`"`)
+		assert.NotContains(t, w.Body.String(), "other-object")
+		assert.Equal(t, 0, hub.SubscriberNumber())
+	})
+}
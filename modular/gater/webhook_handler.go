@@ -0,0 +1,237 @@
+package gater
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/zkMeLabs/mechain-storage-provider/base/types/gfsperrors"
+	"github.com/zkMeLabs/mechain-storage-provider/core/spdb"
+	modelgateway "github.com/zkMeLabs/mechain-storage-provider/model/gateway"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/metrics"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/urlfetch"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/webhook"
+)
+
+const (
+	// MaxWebhookSubscriptionBodySize defines the max size of the webhook subscription request body.
+	MaxWebhookSubscriptionBodySize = 16 * 1024
+	// MinWebhookSecretLength defines the min length of the webhook secret.
+	MinWebhookSecretLength = 16
+	// MaxWebhookSecretLength defines the max length of the webhook secret.
+	MaxWebhookSecretLength = 256
+	// MaxWebhookURLLength defines the max length of the webhook url.
+	MaxWebhookURLLength = 1024
+)
+
+// WebhookSubscription is the request and response body of the webhook subscription api,
+// the secret is never returned.
+type WebhookSubscription struct {
+	XMLName               xml.Name `xml:"WebhookSubscription"`
+	OwnerAddress          string   `xml:"OwnerAddress,omitempty"`
+	URL                   string   `xml:"URL"`
+	Secret                string   `xml:"Secret,omitempty"`
+	Events                []string `xml:"Events,omitempty"`
+	CreateTimestampSecond int64    `xml:"CreateTimestampSecond,omitempty"`
+	UpdateTimestampSecond int64    `xml:"UpdateTimestampSecond,omitempty"`
+}
+
+// putWebhookSubscriptionHandler handles the request of setting the webhook subscription, the
+// request signer is the owner of the subscription and receives the events of the buckets it owns.
+func (g *GateModular) putWebhookSubscriptionHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err    error
+		reqCtx *RequestContext
+		body   []byte
+		sub    *spdb.WebhookSubscription
+	)
+	startTime := time.Now()
+	defer func() {
+		reqCtx.Cancel()
+		if err != nil {
+			reqCtx.SetError(gfsperrors.MakeGfSpError(err))
+			reqCtx.SetHTTPCode(int(gfsperrors.MakeGfSpError(err).GetHttpStatusCode()))
+			modelgateway.MakeErrorResponse(w, gfsperrors.MakeGfSpError(err))
+			metrics.ReqCounter.WithLabelValues(GatewayTotalFailure).Inc()
+			metrics.ReqTime.WithLabelValues(GatewayTotalFailure).Observe(time.Since(startTime).Seconds())
+		} else {
+			reqCtx.SetHTTPCode(http.StatusOK)
+			metrics.ReqCounter.WithLabelValues(GatewayTotalSuccess).Inc()
+			metrics.ReqTime.WithLabelValues(GatewayTotalSuccess).Observe(time.Since(startTime).Seconds())
+		}
+		log.CtxDebugw(reqCtx.Context(), reqCtx.String())
+	}()
+
+	reqCtx, err = NewRequestContext(r, g)
+	if err != nil {
+		return
+	}
+	if !g.enableWebhook {
+		err = ErrWebhookDisabled
+		return
+	}
+	body, err = io.ReadAll(io.LimitReader(r.Body, MaxWebhookSubscriptionBodySize))
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to read webhook subscription body", "error", err)
+		err = ErrExceptionStream
+		return
+	}
+	req := &WebhookSubscription{}
+	if err = xml.Unmarshal(body, req); err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to unmarshal webhook subscription", "error", err)
+		err = ErrDecodeMsg
+		return
+	}
+	if err = checkWebhookSubscription(req, g.webhookAllowPrivateNetwork); err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to check webhook subscription", "error", err)
+		return
+	}
+	secret, err := g.baseApp.WebhookSecretCipher().Seal(reqCtx.Account(), req.Secret)
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to seal webhook secret", "error", err)
+		err = ErrWebhookSecretWithDetail("failed to seal webhook secret, error: " + err.Error())
+		return
+	}
+
+	now := time.Now().Unix()
+	sub = &spdb.WebhookSubscription{
+		OwnerAddress:          reqCtx.Account(),
+		URL:                   req.URL,
+		Secret:                secret,
+		Events:                req.Events,
+		CreateTimestampSecond: now,
+		UpdateTimestampSecond: now,
+	}
+	if err = g.baseApp.GfSpDB().SetWebhookSubscription(sub); err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to set webhook subscription", "error", err)
+		return
+	}
+	log.CtxInfow(reqCtx.Context(), "succeed to set webhook subscription", "owner", sub.OwnerAddress,
+		"url", sub.URL, "events", sub.Events)
+}
+
+// getWebhookSubscriptionHandler handles the request of querying the webhook subscription of the request signer.
+func (g *GateModular) getWebhookSubscriptionHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err    error
+		reqCtx *RequestContext
+		sub    *spdb.WebhookSubscription
+		b      []byte
+	)
+	startTime := time.Now()
+	defer func() {
+		reqCtx.Cancel()
+		if err != nil {
+			reqCtx.SetError(gfsperrors.MakeGfSpError(err))
+			reqCtx.SetHTTPCode(int(gfsperrors.MakeGfSpError(err).GetHttpStatusCode()))
+			modelgateway.MakeErrorResponse(w, gfsperrors.MakeGfSpError(err))
+			metrics.ReqCounter.WithLabelValues(GatewayTotalFailure).Inc()
+			metrics.ReqTime.WithLabelValues(GatewayTotalFailure).Observe(time.Since(startTime).Seconds())
+		} else {
+			reqCtx.SetHTTPCode(http.StatusOK)
+			metrics.ReqCounter.WithLabelValues(GatewayTotalSuccess).Inc()
+			metrics.ReqTime.WithLabelValues(GatewayTotalSuccess).Observe(time.Since(startTime).Seconds())
+		}
+		log.CtxDebugw(reqCtx.Context(), reqCtx.String())
+	}()
+
+	reqCtx, err = NewRequestContext(r, g)
+	if err != nil {
+		return
+	}
+	if !g.enableWebhook {
+		err = ErrWebhookDisabled
+		return
+	}
+	sub, err = g.baseApp.GfSpDB().GetWebhookSubscription(reqCtx.Account())
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to get webhook subscription", "error", err)
+		return
+	}
+	if sub == nil {
+		err = ErrNoWebhookSubscription
+		return
+	}
+	resp := &WebhookSubscription{
+		OwnerAddress:          sub.OwnerAddress,
+		URL:                   sub.URL,
+		Events:                sub.Events,
+		CreateTimestampSecond: sub.CreateTimestampSecond,
+		UpdateTimestampSecond: sub.UpdateTimestampSecond,
+	}
+	b, err = xml.Marshal(resp)
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to marshal webhook subscription", "error", err)
+		err = ErrEncodeResponseWithDetail("failed to marshal webhook subscription, error: " + err.Error())
+		return
+	}
+	w.Header().Set(ContentTypeHeader, ContentTypeXMLHeaderValue)
+	if _, err = w.Write(b); err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to write webhook subscription", "error", err)
+	}
+}
+
+// deleteWebhookSubscriptionHandler handles the request of deleting the webhook subscription of the request signer.
+func (g *GateModular) deleteWebhookSubscriptionHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err    error
+		reqCtx *RequestContext
+	)
+	startTime := time.Now()
+	defer func() {
+		reqCtx.Cancel()
+		if err != nil {
+			reqCtx.SetError(gfsperrors.MakeGfSpError(err))
+			reqCtx.SetHTTPCode(int(gfsperrors.MakeGfSpError(err).GetHttpStatusCode()))
+			modelgateway.MakeErrorResponse(w, gfsperrors.MakeGfSpError(err))
+			metrics.ReqCounter.WithLabelValues(GatewayTotalFailure).Inc()
+			metrics.ReqTime.WithLabelValues(GatewayTotalFailure).Observe(time.Since(startTime).Seconds())
+		} else {
+			reqCtx.SetHTTPCode(http.StatusOK)
+			metrics.ReqCounter.WithLabelValues(GatewayTotalSuccess).Inc()
+			metrics.ReqTime.WithLabelValues(GatewayTotalSuccess).Observe(time.Since(startTime).Seconds())
+		}
+		log.CtxDebugw(reqCtx.Context(), reqCtx.String())
+	}()
+
+	reqCtx, err = NewRequestContext(r, g)
+	if err != nil {
+		return
+	}
+	if !g.enableWebhook {
+		err = ErrWebhookDisabled
+		return
+	}
+	if err = g.baseApp.GfSpDB().DeleteWebhookSubscription(reqCtx.Account()); err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to delete webhook subscription", "error", err)
+		return
+	}
+	log.CtxInfow(reqCtx.Context(), "succeed to delete webhook subscription", "owner", reqCtx.Account())
+}
+
+// checkWebhookSubscription checks the subscription, the url must not be a loopback, private or otherwise
+// non-public address unless allowPrivateNetwork is set, and the address it resolves to is checked again
+// when the events are delivered.
+func checkWebhookSubscription(sub *WebhookSubscription, allowPrivateNetwork bool) error {
+	if len(sub.URL) == 0 || len(sub.URL) > MaxWebhookURLLength {
+		return ErrInvalidWebhookURL
+	}
+	u, err := urlfetch.ParseURL(sub.URL)
+	if err != nil {
+		return ErrInvalidWebhookURL
+	}
+	if !allowPrivateNetwork && urlfetch.CheckHost(u) != nil {
+		return ErrInvalidWebhookURL
+	}
+	if len(sub.Secret) < MinWebhookSecretLength || len(sub.Secret) > MaxWebhookSecretLength {
+		return ErrInvalidWebhookSecret
+	}
+	for _, e := range sub.Events {
+		if !webhook.IsValidEventType(e) {
+			return ErrInvalidWebhookEvent
+		}
+	}
+	return nil
+}
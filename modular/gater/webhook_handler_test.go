@@ -0,0 +1,287 @@
+package gater
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	commonhttp "github.com/zkMeLabs/mechain-common/go/http"
+	"github.com/zkMeLabs/mechain-storage-provider/base/gfspclient"
+	"github.com/zkMeLabs/mechain-storage-provider/core/spdb"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/webhook"
+)
+
+const (
+	mockWebhookOwner     = "0x76d244CE05c3De4BbC6fDd7F56379B145709ade9"
+	mockWebhookSecretKey = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+	mockWebhookBody      = "<WebhookSubscription><URL>https://example.com/hook</URL><Secret>0123456789abcdef</Secret>" +
+		"<Events>object.sealed</Events><Events>object.deleted</Events></WebhookSubscription>"
+)
+
+func mockWebhookSubscriptionHandlerRoute(t *testing.T, g *GateModular) *mux.Router {
+	t.Helper()
+	router := mux.NewRouter().SkipClean(true)
+	router.Path(WebhookSubscriptionPath).Name(putWebhookSubscriptionRouterName).Methods(http.MethodPut).HandlerFunc(g.putWebhookSubscriptionHandler)
+	router.Path(WebhookSubscriptionPath).Name(getWebhookSubscriptionRouterName).Methods(http.MethodGet).HandlerFunc(g.getWebhookSubscriptionHandler)
+	router.Path(WebhookSubscriptionPath).Name(deleteWebhookSubscriptionRouterName).Methods(http.MethodDelete).HandlerFunc(g.deleteWebhookSubscriptionHandler)
+	return router
+}
+
+func mockWebhookSubscriptionRequest(method, body string) *http.Request {
+	req := httptest.NewRequest(method, scheme+testDomain+WebhookSubscriptionPath, strings.NewReader(body))
+	validExpiryDateStr := time.Now().Add(time.Hour * 60).Format(ExpiryDateFormat)
+	req.Header.Set(commonhttp.HTTPHeaderExpiryTimestamp, validExpiryDateStr)
+	req.Header.Set(GnfdAuthorizationHeader, "GNFD1-EDDSA,Signature=48656c6c6f20476f7068657221")
+	req.Header.Set(GnfdUserAddressHeader, mockWebhookOwner)
+	return req
+}
+
+func mockWebhookGateModular(t *testing.T, enable bool, verifyErr error, db spdb.SPDB) *GateModular {
+	g := setup(t)
+	g.enableWebhook = enable
+	ctrl := gomock.NewController(t)
+	clientMock := gfspclient.NewMockGfSpClientAPI(ctrl)
+	clientMock.EXPECT().VerifyGNFD1EddsaSignature(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
+		gomock.Any()).Return(verifyErr == nil, verifyErr).Times(1)
+	g.baseApp.SetGfSpClient(clientMock)
+	g.baseApp.SetGfSpDB(db)
+	secretCipher, err := webhook.NewSecretCipher(mockWebhookSecretKey)
+	assert.Nil(t, err)
+	g.baseApp.SetWebhookSecretCipher(secretCipher)
+	return g
+}
+
+func TestGateModular_putWebhookSubscriptionHandler(t *testing.T) {
+	cases := []struct {
+		name         string
+		fn           func() *GateModular
+		request      func() *http.Request
+		wantedCode   int
+		wantedResult string
+	}{
+		{
+			name: "new request context error",
+			fn: func() *GateModular {
+				return mockWebhookGateModular(t, true, mockErr, nil)
+			},
+			request: func() *http.Request {
+				return mockWebhookSubscriptionRequest(http.MethodPut, mockWebhookBody)
+			},
+			wantedCode:   http.StatusInternalServerError,
+			wantedResult: "mock error",
+		},
+		{
+			name: "webhook is disabled",
+			fn: func() *GateModular {
+				return mockWebhookGateModular(t, false, nil, nil)
+			},
+			request: func() *http.Request {
+				return mockWebhookSubscriptionRequest(http.MethodPut, mockWebhookBody)
+			},
+			wantedCode:   http.StatusNotImplemented,
+			wantedResult: "webhook is not enabled",
+		},
+		{
+			name: "failed to unmarshal body",
+			fn: func() *GateModular {
+				return mockWebhookGateModular(t, true, nil, nil)
+			},
+			request: func() *http.Request {
+				return mockWebhookSubscriptionRequest(http.MethodPut, "<WebhookSubscription>")
+			},
+			wantedCode:   http.StatusBadRequest,
+			wantedResult: "gnfd msg decoding error",
+		},
+		{
+			name: "invalid url scheme",
+			fn: func() *GateModular {
+				return mockWebhookGateModular(t, true, nil, nil)
+			},
+			request: func() *http.Request {
+				return mockWebhookSubscriptionRequest(http.MethodPut, strings.Replace(mockWebhookBody, "https://", "ftp://", 1))
+			},
+			wantedCode:   http.StatusBadRequest,
+			wantedResult: "invalid webhook url",
+		},
+		{
+			name: "private network url",
+			fn: func() *GateModular {
+				return mockWebhookGateModular(t, true, nil, nil)
+			},
+			request: func() *http.Request {
+				return mockWebhookSubscriptionRequest(http.MethodPut, strings.Replace(mockWebhookBody, "example.com", "169.254.169.254", 1))
+			},
+			wantedCode:   http.StatusBadRequest,
+			wantedResult: "invalid webhook url",
+		},
+		{
+			name: "invalid secret",
+			fn: func() *GateModular {
+				return mockWebhookGateModular(t, true, nil, nil)
+			},
+			request: func() *http.Request {
+				return mockWebhookSubscriptionRequest(http.MethodPut, strings.Replace(mockWebhookBody, "0123456789abcdef", "short", 1))
+			},
+			wantedCode:   http.StatusBadRequest,
+			wantedResult: "invalid webhook secret",
+		},
+		{
+			name: "invalid event type",
+			fn: func() *GateModular {
+				return mockWebhookGateModular(t, true, nil, nil)
+			},
+			request: func() *http.Request {
+				return mockWebhookSubscriptionRequest(http.MethodPut, strings.Replace(mockWebhookBody, "object.deleted", "object.unknown", 1))
+			},
+			wantedCode:   http.StatusBadRequest,
+			wantedResult: "invalid webhook event type",
+		},
+		{
+			name: "failed to set subscription",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				dbMock := spdb.NewMockSPDB(ctrl)
+				dbMock.EXPECT().SetWebhookSubscription(gomock.Any()).Return(mockErr).Times(1)
+				return mockWebhookGateModular(t, true, nil, dbMock)
+			},
+			request: func() *http.Request {
+				return mockWebhookSubscriptionRequest(http.MethodPut, mockWebhookBody)
+			},
+			wantedCode:   http.StatusInternalServerError,
+			wantedResult: "mock error",
+		},
+		{
+			name: "success",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				dbMock := spdb.NewMockSPDB(ctrl)
+				dbMock.EXPECT().SetWebhookSubscription(gomock.Any()).DoAndReturn(func(sub *spdb.WebhookSubscription) error {
+					assert.NotEmpty(t, sub.OwnerAddress)
+					assert.Equal(t, "https://example.com/hook", sub.URL)
+					assert.NotEqual(t, "0123456789abcdef", sub.Secret)
+					assert.Equal(t, []string{"object.sealed", "object.deleted"}, sub.Events)
+					return nil
+				}).Times(1)
+				return mockWebhookGateModular(t, true, nil, dbMock)
+			},
+			request: func() *http.Request {
+				return mockWebhookSubscriptionRequest(http.MethodPut, mockWebhookBody)
+			},
+			wantedCode:   http.StatusOK,
+			wantedResult: "",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			router := mockWebhookSubscriptionHandlerRoute(t, tt.fn())
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, tt.request())
+			assert.Equal(t, tt.wantedCode, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantedResult)
+		})
+	}
+}
+
+func TestGateModular_getWebhookSubscriptionHandler(t *testing.T) {
+	cases := []struct {
+		name         string
+		fn           func() *GateModular
+		wantedCode   int
+		wantedResult string
+	}{
+		{
+			name: "failed to get subscription",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				dbMock := spdb.NewMockSPDB(ctrl)
+				dbMock.EXPECT().GetWebhookSubscription(gomock.Any()).Return(nil, mockErr).Times(1)
+				return mockWebhookGateModular(t, true, nil, dbMock)
+			},
+			wantedCode:   http.StatusInternalServerError,
+			wantedResult: "mock error",
+		},
+		{
+			name: "no subscription",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				dbMock := spdb.NewMockSPDB(ctrl)
+				dbMock.EXPECT().GetWebhookSubscription(gomock.Any()).Return(nil, nil).Times(1)
+				return mockWebhookGateModular(t, true, nil, dbMock)
+			},
+			wantedCode:   http.StatusNotFound,
+			wantedResult: "no webhook subscription",
+		},
+		{
+			name: "success",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				dbMock := spdb.NewMockSPDB(ctrl)
+				dbMock.EXPECT().GetWebhookSubscription(gomock.Any()).Return(&spdb.WebhookSubscription{
+					OwnerAddress: mockWebhookOwner,
+					URL:          "https://example.com/hook",
+					Secret:       "0123456789abcdef",
+				}, nil).Times(1)
+				return mockWebhookGateModular(t, true, nil, dbMock)
+			},
+			wantedCode:   http.StatusOK,
+			wantedResult: "<URL>https://example.com/hook</URL>",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			router := mockWebhookSubscriptionHandlerRoute(t, tt.fn())
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, mockWebhookSubscriptionRequest(http.MethodGet, ""))
+			assert.Equal(t, tt.wantedCode, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantedResult)
+			assert.NotContains(t, w.Body.String(), "0123456789abcdef")
+		})
+	}
+}
+
+func TestGateModular_deleteWebhookSubscriptionHandler(t *testing.T) {
+	cases := []struct {
+		name         string
+		fn           func() *GateModular
+		wantedCode   int
+		wantedResult string
+	}{
+		{
+			name: "failed to delete subscription",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				dbMock := spdb.NewMockSPDB(ctrl)
+				dbMock.EXPECT().DeleteWebhookSubscription(gomock.Any()).Return(mockErr).Times(1)
+				return mockWebhookGateModular(t, true, nil, dbMock)
+			},
+			wantedCode:   http.StatusInternalServerError,
+			wantedResult: "mock error",
+		},
+		{
+			name: "success",
+			fn: func() *GateModular {
+				ctrl := gomock.NewController(t)
+				dbMock := spdb.NewMockSPDB(ctrl)
+				dbMock.EXPECT().DeleteWebhookSubscription(gomock.Any()).Return(nil).Times(1)
+				return mockWebhookGateModular(t, true, nil, dbMock)
+			},
+			wantedCode:   http.StatusOK,
+			wantedResult: "",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			router := mockWebhookSubscriptionHandlerRoute(t, tt.fn())
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, mockWebhookSubscriptionRequest(http.MethodDelete, ""))
+			assert.Equal(t, tt.wantedCode, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantedResult)
+		})
+	}
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/zkMeLabs/mechain-storage-provider/core/vgmgr"
 	"github.com/zkMeLabs/mechain-storage-provider/modular/metadata/types"
 	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/webhook"
 	storetypes "github.com/zkMeLabs/mechain-storage-provider/store/types"
 	"github.com/zkMeLabs/mechain-storage-provider/util"
 )
@@ -756,6 +757,12 @@ func (s *BucketMigrateScheduler) confirmCompleteTxEvents(ctx context.Context, ev
 		if err = UpdateBucketMigrationProgress(s.manager.baseApp, bucketID, storetypes.BucketMigrationState_BUCKET_MIGRATION_STATE_MIGRATION_FINISHED); err != nil {
 			return
 		}
+		s.manager.baseApp.Webhook().Notify(&webhook.Event{
+			Type:        webhook.EventBucketMigrationCompleted,
+			BucketName:  bucket.BucketInfo.GetBucketName(),
+			BucketID:    bucketID,
+			BucketOwner: bucket.BucketInfo.GetOwner(),
+		})
 		log.CtxInfow(ctx, "succeed to confirm complete events", "EventMigrationBucket", event)
 	}
 }
@@ -28,6 +28,7 @@ import (
 	"github.com/zkMeLabs/mechain-storage-provider/core/vgmgr"
 	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
 	"github.com/zkMeLabs/mechain-storage-provider/pkg/metrics"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/webhook"
 	"github.com/zkMeLabs/mechain-storage-provider/store/types"
 	"github.com/zkMeLabs/mechain-storage-provider/util"
 )
@@ -92,6 +93,7 @@ func (m *ManageModular) HandleCreateUploadObjectTask(ctx context.Context, task t
 		log.CtxErrorw(ctx, "failed to push upload object task to queue", "task_info", task.Info(), "error", err)
 		return err
 	}
+	m.baseApp.PublishUploadProgress(task.GetObjectInfo(), types.TaskState_TASK_STATE_UPLOAD_OBJECT_DOING, "")
 	if err := m.baseApp.GfSpDB().InsertUploadProgress(task.GetObjectInfo().Id.Uint64(), task.GetIsAgentUpload()); err != nil {
 		if strings.Contains(err.Error(), "Duplicate entry") {
 			log.Infow("insert upload progress with duplicate entry", "task_info", task.Info())
@@ -116,6 +118,7 @@ func (m *ManageModular) HandleDoneUploadObjectTask(ctx context.Context, task tas
 		return ErrRepeatedTask
 	}
 	if task.Error() != nil {
+		m.baseApp.PublishUploadProgress(task.GetObjectInfo(), types.TaskState_TASK_STATE_UPLOAD_OBJECT_ERROR, task.Error().Error())
 		go func() {
 			err := m.baseApp.GfSpDB().UpdateUploadProgress(&spdb.UploadObjectMeta{
 				ObjectID:         task.GetObjectInfo().Id.Uint64(),
@@ -135,6 +138,7 @@ func (m *ManageModular) HandleDoneUploadObjectTask(ctx context.Context, task tas
 		metrics.ManagerCounter.WithLabelValues(ManagerSuccessUpload).Inc()
 		metrics.ManagerTime.WithLabelValues(ManagerSuccessUpload).Observe(
 			time.Since(time.Unix(task.GetCreateTime(), 0)).Seconds())
+		m.baseApp.NotifyObjectEvent(webhook.EventObjectUploaded, task.GetObjectInfo(), "")
 	}
 	log.Debugw("UploadObjectTask info", "task", task)
 	return m.pickGVGAndReplicate(ctx, task.GetVirtualGroupFamilyId(), task, task.GetIsAgentUpload())
@@ -166,6 +170,7 @@ func (m *ManageModular) pickGVGAndReplicate(ctx context.Context, vgfID uint32, t
 		log.CtxErrorw(ctx, "failed to push replicate piece task to queue", "error", err)
 		return err
 	}
+	m.baseApp.PublishUploadProgress(task.GetObjectInfo(), types.TaskState_TASK_STATE_REPLICATE_OBJECT_DOING, "")
 	go m.backUpTask()
 	go func() {
 		err = m.baseApp.GfSpDB().UpdateUploadProgress(&spdb.UploadObjectMeta{
@@ -201,6 +206,7 @@ func (m *ManageModular) HandleCreateResumableUploadObjectTask(ctx context.Contex
 		log.CtxErrorw(ctx, "failed to push resumable upload object task to queue", "task_info", task.Info(), "error", err)
 		return err
 	}
+	m.baseApp.PublishUploadProgress(task.GetObjectInfo(), types.TaskState_TASK_STATE_UPLOAD_OBJECT_DOING, "")
 	if err := m.baseApp.GfSpDB().InsertUploadProgress(task.GetObjectInfo().Id.Uint64(), task.GetIsAgentUpload()); err != nil {
 		if strings.Contains(err.Error(), "Duplicate entry") {
 			return nil
@@ -225,6 +231,7 @@ func (m *ManageModular) HandleDoneResumableUploadObjectTask(ctx context.Context,
 		return ErrRepeatedTask
 	}
 	if task.Error() != nil {
+		m.baseApp.PublishUploadProgress(task.GetObjectInfo(), types.TaskState_TASK_STATE_UPLOAD_OBJECT_ERROR, task.Error().Error())
 		go func() error {
 			err := m.baseApp.GfSpDB().UpdateUploadProgress(&spdb.UploadObjectMeta{
 				ObjectID:         task.GetObjectInfo().Id.Uint64(),
@@ -274,6 +281,7 @@ func (m *ManageModular) HandleDoneResumableUploadObjectTask(ctx context.Context,
 		log.CtxErrorw(ctx, "failed to push replicate piece task to queue", "error", err)
 		return err
 	}
+	m.baseApp.PublishUploadProgress(task.GetObjectInfo(), types.TaskState_TASK_STATE_REPLICATE_OBJECT_DOING, "")
 	go m.backUpTask()
 	go func() error {
 		err = m.baseApp.GfSpDB().UpdateUploadProgress(&spdb.UploadObjectMeta{
@@ -316,6 +324,7 @@ func (m *ManageModular) HandleReplicatePieceTask(ctx context.Context, task task.
 	}
 	if task.GetSealed() {
 		task.AppendLog(fmt.Sprintf("manager-handle-succeed-replicate-task-retry:%d", task.GetRetry()))
+		m.baseApp.PublishUploadProgress(task.GetObjectInfo(), types.TaskState_TASK_STATE_SEAL_OBJECT_DONE, "")
 		go func() {
 			_ = m.baseApp.GfSpDB().InsertPutEvent(task)
 			log.Debugw("replicate piece object task has combined seal object task", "task_info", task.Info())
@@ -375,6 +384,7 @@ func (m *ManageModular) HandleReplicatePieceTask(ctx context.Context, task task.
 		log.CtxErrorw(ctx, "failed to push seal object task to queue", "task_info", task.Info(), "error", err)
 		return err
 	}
+	m.baseApp.PublishUploadProgress(task.GetObjectInfo(), types.TaskState_TASK_STATE_SEAL_OBJECT_DOING, "")
 	go m.backUpTask()
 	go func() {
 		if err = m.baseApp.GfSpDB().UpdateUploadProgress(&spdb.UploadObjectMeta{
@@ -460,6 +470,7 @@ func (m *ManageModular) handleFailedReplicatePieceTask(ctx context.Context, hand
 		metrics.ManagerCounter.WithLabelValues(ManagerCancelReplicate).Inc()
 		metrics.ManagerTime.WithLabelValues(ManagerCancelReplicate).Observe(
 			time.Since(time.Unix(handleTask.GetCreateTime(), 0)).Seconds())
+		m.baseApp.PublishUploadProgress(handleTask.GetObjectInfo(), types.TaskState_TASK_STATE_REPLICATE_OBJECT_ERROR, "exceed_replicate_retry")
 		go func() {
 			_ = m.baseApp.GfSpDB().InsertPutEvent(shadowTask)
 			if err := m.baseApp.GfSpDB().UpdateUploadProgress(&spdb.UploadObjectMeta{
@@ -493,6 +504,8 @@ func (m *ManageModular) HandleSealObjectTask(ctx context.Context, task task.Seal
 		metrics.ManagerCounter.WithLabelValues(ManagerSuccessSeal).Inc()
 		metrics.ManagerTime.WithLabelValues(ManagerSuccessSeal).Observe(
 			time.Since(time.Unix(task.GetUpdateTime(), 0)).Seconds())
+		m.baseApp.NotifyObjectEvent(webhook.EventObjectSealed, task.GetObjectInfo(), "")
+		m.baseApp.PublishUploadProgress(task.GetObjectInfo(), types.TaskState_TASK_STATE_SEAL_OBJECT_DONE, "")
 	}
 	go func() {
 		m.sealQueue.PopByKey(task.Key())
@@ -537,6 +550,8 @@ func (m *ManageModular) handleFailedSealObjectTask(ctx context.Context, handleTa
 		metrics.ManagerCounter.WithLabelValues(ManagerCancelSeal).Inc()
 		metrics.ManagerTime.WithLabelValues(ManagerCancelSeal).Observe(
 			time.Since(time.Unix(handleTask.GetCreateTime(), 0)).Seconds())
+		m.baseApp.NotifyObjectEvent(webhook.EventObjectSealFailed, handleTask.GetObjectInfo(), "exceed_seal_retry")
+		m.baseApp.PublishUploadProgress(handleTask.GetObjectInfo(), types.TaskState_TASK_STATE_SEAL_OBJECT_ERROR, "exceed_seal_retry")
 		go func() {
 			if err := m.baseApp.GfSpDB().UpdateUploadProgress(&spdb.UploadObjectMeta{
 				ObjectID:         handleTask.GetObjectInfo().Id.Uint64(),
@@ -25,6 +25,7 @@ import (
 	"github.com/zkMeLabs/mechain-storage-provider/core/vgmgr"
 	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
 	"github.com/zkMeLabs/mechain-storage-provider/pkg/metrics"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/webhook"
 	"github.com/zkMeLabs/mechain-storage-provider/store/types"
 	"github.com/zkMeLabs/mechain-storage-provider/util"
 )
@@ -888,6 +889,8 @@ func (m *ManageModular) RejectUnSealObject(ctx context.Context, object *storaget
 				log.CtxErrorw(ctx, "failed to reject unseal object")
 				continue
 			}
+			m.baseApp.NotifyObjectEvent(webhook.EventObjectSealFailed, object, "reject_unseal_object")
+			m.baseApp.PublishUploadProgress(object, types.TaskState_TASK_STATE_SEAL_OBJECT_ERROR, "reject_unseal_object")
 			return nil
 		}
 	}
@@ -16,7 +16,9 @@ import (
 	"github.com/zkMeLabs/mechain-storage-provider/core/piecestore"
 	"github.com/zkMeLabs/mechain-storage-provider/core/spdb"
 	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/webhook"
 	"github.com/zkMeLabs/mechain-storage-provider/store/sqldb"
+	"github.com/zkMeLabs/mechain-storage-provider/store/types"
 	"github.com/zkMeLabs/mechain-storage-provider/util"
 )
 
@@ -441,6 +443,8 @@ func (s *TaskRetryScheduler) retryRejectUnsealTask(meta *spdb.UploadObjectMeta)
 	err = sendAndConfirmRejectUnsealObjectTx(s.manager.baseApp, rejectUnsealMsg)
 	if err == nil {
 		_ = s.manager.baseApp.GfSpDB().DeleteUploadProgress(objectInfo.Id.Uint64())
+		s.manager.baseApp.NotifyObjectEvent(webhook.EventObjectSealFailed, objectInfo, "reject_unseal_object")
+		s.manager.baseApp.PublishUploadProgress(objectInfo, types.TaskState_TASK_STATE_SEAL_OBJECT_ERROR, "reject_unseal_object")
 	}
 	return err
 }
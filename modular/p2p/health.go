@@ -0,0 +1,30 @@
+package p2p
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/probe"
+)
+
+// P2PHealthCheckName defines the name of the p2p peer count health check.
+const P2PHealthCheckName = "p2p"
+
+var _ probe.HealthCheckProvider = &P2PModular{}
+
+// HealthChecks returns the check of the connected p2p peers. The sp is still able to serve the requests
+// without peers, so the check is not critical and only reported.
+func (p *P2PModular) HealthChecks() []probe.Check {
+	return []probe.Check{{
+		Name: P2PHealthCheckName,
+		Func: func(ctx context.Context) (string, error) {
+			peers := p.node.PeerCount()
+			detail := fmt.Sprintf("%d peers", peers)
+			if peers == 0 {
+				return detail, errors.New("no connected peer")
+			}
+			return detail, nil
+		},
+	}}
+}
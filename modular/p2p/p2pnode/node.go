@@ -173,6 +173,11 @@ func (n *Node) PeersProvider() *PeerProvider {
 	return n.peers
 }
 
+// PeerCount returns the number of the connected peers
+func (n *Node) PeerCount() int {
+	return len(n.node.Network().Peers())
+}
+
 // GetSecondaryReplicatePieceApproval broadcast get approval request and blocking
 // goroutine until timeout or collect expect accept approval response number
 func (n *Node) GetSecondaryReplicatePieceApproval(ctx context.Context, task coretask.ApprovalReplicatePieceTask,
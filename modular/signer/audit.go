@@ -0,0 +1,74 @@
+package signer
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/cosmos/gogoproto/proto"
+	"google.golang.org/grpc/peer"
+
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/auditlog"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/mtls"
+)
+
+// auditSign appends the entry of the signature to the audit log, the payload is the signed bytes.
+func (s *SignModular) auditSign(ctx context.Context, method string, scope string, msg interface{}, signBytes []byte, err error) {
+	if s.auditLog == nil {
+		return
+	}
+	s.audit(ctx, method, scope, msg, signBytes, "", err)
+}
+
+// auditTx appends the entry of the tx to the audit log, the payload is the encoded message.
+func (s *SignModular) auditTx(ctx context.Context, method string, scope SignType, msg proto.Message, txHash string, err error) {
+	if s.auditLog == nil {
+		return
+	}
+	var payload []byte
+	// the dangling messages are rejected by the client, they are audited with the digest of the empty payload
+	if msg != nil && !reflect.ValueOf(msg).IsNil() {
+		payload, _ = proto.Marshal(msg)
+	}
+	s.audit(ctx, method, string(scope), msg, payload, txHash, err)
+}
+
+func (s *SignModular) audit(ctx context.Context, method string, scope string, msg interface{}, payload []byte,
+	txHash string, err error,
+) {
+	entry := &auditlog.Entry{
+		Method:        method,
+		MsgType:       auditMsgType(msg),
+		Scope:         scope,
+		PayloadDigest: auditlog.Digest(payload),
+		TxHash:        txHash,
+		Result:        auditlog.ResultSuccess,
+	}
+	if err != nil {
+		entry.Result = err.Error()
+	}
+	if modules, ok := mtls.CallerModules(ctx); ok {
+		entry.Caller = strings.Join(modules, ",")
+	} else if modules, ok = mtls.ClaimedCallerModules(ctx); ok {
+		// without the mutual TLS, the modules claimed by the gfsp client are recorded
+		entry.Caller = strings.Join(modules, ",")
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		entry.Peer = p.Addr.String()
+	}
+	if appendErr := s.auditLog.Append(entry); appendErr != nil {
+		log.CtxErrorw(ctx, "failed to append audit log", "method", method, "error", appendErr)
+	}
+}
+
+// auditMsgType returns the proto name of the message, or its go type if it is not a registered proto message.
+func auditMsgType(msg interface{}) string {
+	if m, ok := msg.(proto.Message); ok {
+		if name := proto.MessageName(m); name != "" {
+			return name
+		}
+	}
+	return fmt.Sprintf("%T", msg)
+}
@@ -0,0 +1,100 @@
+package signer
+
+import (
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/evmos/evmos/v12/sdk/client"
+	"github.com/evmos/evmos/v12/sdk/keys"
+
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+)
+
+// broadcastRoute returns the chain address of the endpoint the txs are broadcast to, it is the broadcast endpoint
+// selected by the consensus, so the txs of an account are sent to the same mempool.
+type broadcastRoute func() string
+
+// broadcastEndpoint is a chain endpoint other than the first one in the config.
+type broadcastEndpoint struct {
+	chainAddr string
+	rpcAddr   string
+}
+
+// newBroadcastEndpoints returns the chain endpoints other than the first one, the chain addresses are paired with
+// the rpc addresses if both are listed per node, the same as the consensus pairs them.
+func newBroadcastEndpoints(chainAddrs, rpcAddrs []string) []broadcastEndpoint {
+	var endpoints []broadcastEndpoint
+	for idx := 1; idx < len(chainAddrs); idx++ {
+		rpcAddr := rpcAddrs[0]
+		if len(rpcAddrs) == len(chainAddrs) {
+			rpcAddr = rpcAddrs[idx]
+		}
+		endpoints = append(endpoints, broadcastEndpoint{chainAddr: chainAddrs[idx], rpcAddr: rpcAddr})
+	}
+	return endpoints
+}
+
+// routedTxClients are the chain clients of an account by the chain addresses of the other endpoints.
+type routedTxClients map[string]poolTxClient
+
+// newRoutedTxClients returns the chain clients of the account of the key manager for the endpoints.
+func newRoutedTxClients(endpoints []broadcastEndpoint, chainID string, km keys.KeyManager) (routedTxClients, error) {
+	clients := make(routedTxClients, len(endpoints))
+	for _, e := range endpoints {
+		c, err := client.NewMechainClient(e.chainAddr, e.rpcAddr, chainID, client.WithKeyManager(km))
+		if err != nil {
+			log.Errorw("failed to new mechain client of the broadcast endpoint", "node_addr", e.chainAddr, "error", err)
+			return nil, err
+		}
+		clients[e.chainAddr] = c
+	}
+	return clients, nil
+}
+
+// pick returns the client of the routed endpoint, it returns the client of the first endpoint if the txs are not
+// routed or routed to the first endpoint.
+func (c routedTxClients) pick(route broadcastRoute, first poolTxClient) poolTxClient {
+	if route == nil {
+		return first
+	}
+	if routed, ok := c[route()]; ok {
+		return routed
+	}
+	return first
+}
+
+// routeBroadcasts creates the chain clients of the keys and the evm clients for the other endpoints, the txs are
+// broadcast by the clients of the endpoint returned by route.
+func (client *MechainChainSignClient) routeBroadcasts(endpoints []broadcastEndpoint, chainID string,
+	route broadcastRoute,
+) error {
+	broadcastClients := make(map[SignType]routedTxClients, len(client.mechainClients))
+	for scope, c := range client.mechainClients {
+		km, err := c.GetKeyManager()
+		if err != nil {
+			return err
+		}
+		if broadcastClients[scope], err = newRoutedTxClients(endpoints, chainID, km); err != nil {
+			return err
+		}
+	}
+	evmClients := make(map[string]*ethclient.Client, len(endpoints))
+	for _, e := range endpoints {
+		evmClient, err := ethclient.Dial(e.rpcAddr)
+		if err != nil {
+			log.Errorw("failed to new evm client of the broadcast endpoint", "rpc_addr", e.rpcAddr, "error", err)
+			return err
+		}
+		evmClients[e.chainAddr] = evmClient
+	}
+	client.broadcastClients, client.evmClients, client.broadcastRoute = broadcastClients, evmClients, route
+	return nil
+}
+
+// evmTxClient returns the evm client of the routed endpoint.
+func (client *MechainChainSignClient) evmTxClient() *ethclient.Client {
+	if client.broadcastRoute != nil {
+		if evmClient, ok := client.evmClients[client.broadcastRoute()]; ok {
+			return evmClient
+		}
+	}
+	return client.evmClient
+}
@@ -0,0 +1,73 @@
+package signer
+
+import (
+	"context"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	storagetypes "github.com/evmos/evmos/v12/x/storage/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBroadcastEndpoints(t *testing.T) {
+	cases := []struct {
+		name       string
+		chainAddrs []string
+		rpcAddrs   []string
+		wanted     []broadcastEndpoint
+	}{
+		{
+			name:       "single endpoint",
+			chainAddrs: []string{"a"},
+			rpcAddrs:   []string{"rpc-a"},
+		},
+		{
+			name:       "paired rpc addresses",
+			chainAddrs: []string{"a", "b", "c"},
+			rpcAddrs:   []string{"rpc-a", "rpc-b", "rpc-c"},
+			wanted:     []broadcastEndpoint{{chainAddr: "b", rpcAddr: "rpc-b"}, {chainAddr: "c", rpcAddr: "rpc-c"}},
+		},
+		{
+			name:       "shared rpc address",
+			chainAddrs: []string{"a", "b"},
+			rpcAddrs:   []string{"rpc-a"},
+			wanted:     []broadcastEndpoint{{chainAddr: "b", rpcAddr: "rpc-a"}},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wanted, newBroadcastEndpoints(tt.chainAddrs, tt.rpcAddrs))
+		})
+	}
+}
+
+func TestRoutedTxClientsPick(t *testing.T) {
+	first, b := newMockPoolTxClient(0), newMockPoolTxClient(0)
+	routed := routedTxClients{"b": b}
+	assert.Same(t, first, routed.pick(nil, first))
+	assert.Same(t, first, routed.pick(func() string { return "a" }, first))
+	assert.Same(t, b, routed.pick(func() string { return "b" }, first))
+	assert.Same(t, first, routedTxClients(nil).pick(func() string { return "b" }, first))
+}
+
+func TestSealAccountPool_BroadcastRoute(t *testing.T) {
+	c0, routedC0 := newMockPoolTxClient(0), newMockPoolTxClient(0)
+	pool := newMockSealAccountPool(t, c0)
+	pool.accounts[0].routed = routedTxClients{"b": routedC0}
+	endpoint := "a"
+	pool.route = func() string { return endpoint }
+	seal := storagetypes.NewMsgSealObject(pool.accounts[0].addr, "bucket", "object", 1, nil)
+
+	_, err := pool.broadcast(context.Background(), []sdk.Msg{seal}, GasInfo{GasLimit: DefaultSealGasLimit})
+	assert.Nil(t, err)
+	assert.Len(t, c0.txs, 1)
+
+	// the txs follow the broadcast endpoint of the consensus
+	endpoint = "b"
+	routedC0.sequence = 1
+	_, err = pool.broadcast(context.Background(), []sdk.Msg{seal}, GasInfo{GasLimit: DefaultSealGasLimit})
+	assert.Nil(t, err)
+	assert.Len(t, c0.txs, 1)
+	assert.Len(t, routedC0.txs, 1)
+	assert.Equal(t, seal, routedC0.txs[1][0])
+}
@@ -0,0 +1,148 @@
+package signer
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	sdkmath "cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ctypes "github.com/evmos/evmos/v12/sdk/types"
+
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/metrics"
+)
+
+// DefaultFeeBudgetAlertRatio defines the default used ratio of the daily fee budget from which an alert is logged.
+const DefaultFeeBudgetAlertRatio = 0.8
+
+// feeBudget limits the fee amount in the fee denom paid by each account in a UTC day. The fee of a tx is spent
+// before it is broadcast and refunded if the broadcast fails, so the concurrent txs can not exceed the budget.
+// The accounts are named by their key ids, e.g. "seal" or "seal-account-0", so the budget of an account is kept
+// after its key is rotated.
+type feeBudget struct {
+	mux        sync.Mutex
+	limits     map[string]sdkmath.Int
+	alertRatio float64
+	day        string
+	spent      map[string]sdkmath.Int
+	alerted    map[string]bool
+	now        func() time.Time
+}
+
+// newFeeBudget returns the fee budget of the accounts by their key ids, the accounts without the limits or with the
+// zero limits are unlimited, their spent fees are still tracked by the metrics.
+func newFeeBudget(limits map[string]uint64, alertRatio float64) *feeBudget {
+	if alertRatio <= 0 || alertRatio > 1 {
+		alertRatio = DefaultFeeBudgetAlertRatio
+	}
+	b := &feeBudget{
+		limits:     make(map[string]sdkmath.Int),
+		alertRatio: alertRatio,
+		spent:      make(map[string]sdkmath.Int),
+		alerted:    make(map[string]bool),
+		now:        time.Now,
+	}
+	for account, limit := range limits {
+		b.setLimit(account, limit)
+	}
+	return b
+}
+
+// setLimit sets the daily budget of the account, zero is unlimited.
+func (b *feeBudget) setLimit(account string, limit uint64) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	if limit == 0 {
+		delete(b.limits, account)
+		return
+	}
+	b.limits[account] = sdkmath.NewIntFromUint64(limit)
+}
+
+// spend adds the fee to the spent fee of the account in the current day, an error is returned without spending if
+// the fee exceeds the budget.
+func (b *feeBudget) spend(account string, fee sdk.Coins) error {
+	amount := fee.AmountOf(ctypes.Denom)
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.rollover()
+	spent, ok := b.spent[account]
+	if !ok {
+		spent = sdkmath.ZeroInt()
+	}
+	limit, limited := b.limits[account]
+	if limited && spent.Add(amount).GT(limit) {
+		return fmt.Errorf("daily fee budget of %s account is exceeded, spent: %s, fee: %s, budget: %s",
+			account, spent, amount, limit)
+	}
+	b.spent[account] = spent.Add(amount)
+	b.report(account)
+	return nil
+}
+
+// refund subtracts the fee of the tx which is not broadcast from the spent fee of the account.
+func (b *feeBudget) refund(account string, fee sdk.Coins) {
+	amount := fee.AmountOf(ctypes.Denom)
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	spent, ok := b.spent[account]
+	if !ok {
+		return
+	}
+	if spent.LT(amount) {
+		b.spent[account] = sdkmath.ZeroInt()
+	} else {
+		b.spent[account] = spent.Sub(amount)
+	}
+	b.report(account)
+}
+
+// rollover resets the spent fees at the start of a UTC day.
+func (b *feeBudget) rollover() {
+	day := b.now().UTC().Format(time.DateOnly)
+	if day == b.day {
+		return
+	}
+	b.day = day
+	b.spent = make(map[string]sdkmath.Int)
+	b.alerted = make(map[string]bool)
+	for account := range b.limits {
+		metrics.SignerFeeSpentGauge.WithLabelValues(account).Set(0)
+		metrics.SignerFeeBudgetUsageGauge.WithLabelValues(account).Set(0)
+	}
+}
+
+// report updates the metrics of the account, and logs an alert once a day when the spent fee reaches the alert
+// ratio of the budget.
+func (b *feeBudget) report(account string) {
+	spent := b.spent[account]
+	metrics.SignerFeeSpentGauge.WithLabelValues(account).Set(intToFloat(spent))
+	limit, ok := b.limits[account]
+	if !ok {
+		return
+	}
+	usage := intToFloat(spent) / intToFloat(limit)
+	metrics.SignerFeeBudgetUsageGauge.WithLabelValues(account).Set(usage)
+	if usage >= b.alertRatio && !b.alerted[account] {
+		b.alerted[account] = true
+		log.Warnw("daily fee budget of the signer account is approaching the limit", "account", account,
+			"spent", spent.String(), "budget", limit.String(), "usage", usage)
+	}
+}
+
+// evmFee returns the max fee of the evm tx, the unused gas is refunded by the chain after the execution.
+func evmFee(txOpts *bind.TransactOpts) sdk.Coins {
+	if txOpts.GasPrice == nil {
+		return sdk.NewCoins()
+	}
+	amount := new(big.Int).Mul(txOpts.GasPrice, new(big.Int).SetUint64(txOpts.GasLimit))
+	return sdk.NewCoins(sdk.NewCoin(ctypes.Denom, sdkmath.NewIntFromBigInt(amount)))
+}
+
+func intToFloat(i sdkmath.Int) float64 {
+	f, _ := new(big.Float).SetInt(i.BigInt()).Float64()
+	return f
+}
@@ -0,0 +1,58 @@
+package signer
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ctypes "github.com/evmos/evmos/v12/sdk/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockFee(amount int64) sdk.Coins {
+	return sdk.NewCoins(sdk.NewCoin(ctypes.Denom, sdk.NewInt(amount)))
+}
+
+func TestFeeBudgetSpend(t *testing.T) {
+	b := newFeeBudget(map[string]uint64{"seal": 1000, "gc": 0}, 0.5)
+	assert.Nil(t, b.spend("seal", mockFee(400)))
+	assert.False(t, b.alerted["seal"])
+	assert.Nil(t, b.spend("seal", mockFee(400)))
+	assert.True(t, b.alerted["seal"])
+	assert.NotNil(t, b.spend("seal", mockFee(400)))
+	assert.Equal(t, "800", b.spent["seal"].String())
+
+	b.refund("seal", mockFee(400))
+	assert.Nil(t, b.spend("seal", mockFee(600)))
+	b.refund("seal", mockFee(2000))
+	assert.True(t, b.spent["seal"].IsZero())
+
+	// the accounts without the limits are unlimited
+	assert.Nil(t, b.spend("gc", mockFee(1e9)))
+	assert.Nil(t, b.spend("operator", mockFee(1e9)))
+}
+
+func TestFeeBudgetRollover(t *testing.T) {
+	now := time.Date(2024, 1, 1, 23, 0, 0, 0, time.UTC)
+	b := newFeeBudget(map[string]uint64{"seal": 1000}, 0)
+	assert.Equal(t, DefaultFeeBudgetAlertRatio, b.alertRatio)
+	b.now = func() time.Time { return now }
+	assert.Nil(t, b.spend("seal", mockFee(1000)))
+	assert.NotNil(t, b.spend("seal", mockFee(1)))
+	assert.True(t, b.alerted["seal"])
+
+	now = now.Add(2 * time.Hour)
+	assert.Nil(t, b.spend("seal", mockFee(1)))
+	assert.False(t, b.alerted["seal"])
+	assert.Equal(t, "1", b.spent["seal"].String())
+
+	b.setLimit("seal", 0)
+	assert.Nil(t, b.spend("seal", mockFee(1e9)))
+}
+
+func TestEvmFee(t *testing.T) {
+	assert.True(t, evmFee(&bind.TransactOpts{GasLimit: 1000}).IsZero())
+	assert.Equal(t, mockFee(2000), evmFee(&bind.TransactOpts{GasLimit: 1000, GasPrice: big.NewInt(2)}))
+}
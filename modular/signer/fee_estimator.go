@@ -0,0 +1,172 @@
+package signer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	sdkmath "cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	ctypes "github.com/evmos/evmos/v12/sdk/types"
+	"google.golang.org/grpc"
+
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/metrics"
+)
+
+const (
+	// DefaultGasMultiplier defines the default safety multiplier of the simulated gas.
+	DefaultGasMultiplier = 1.2
+	// DefaultGasEstimateCacheSecond defines the default time the estimation of the same messages is reused.
+	DefaultGasEstimateCacheSecond = 30
+
+	// msgsSizeGranularity defines the granularity of the encoded size of the messages in the estimation key, the
+	// gas of the messages of the same types grows with their encoded size.
+	msgsSizeGranularity = 256
+
+	gasEstimateSimulated = "simulated"
+	gasEstimateCached    = "cached"
+	gasEstimateStatic    = "static"
+)
+
+// txSimulator simulates the txs of an account.
+type txSimulator interface {
+	SimulateTx(ctx context.Context, msgs []sdk.Msg, txOpt *ctypes.TxOption, opts ...grpc.CallOption) (*tx.SimulateResponse, error)
+}
+
+// gasEstimate is the estimated gas info of the messages which is reused until expireAt.
+type gasEstimate struct {
+	gasInfo  GasInfo
+	expireAt time.Time
+}
+
+// feeEstimator estimates the gas limits of the txs by the simulation with a safety multiplier, and the fees by the
+// min gas price returned by the simulation. The estimation of the messages of the same types, numbers and similar
+// encoded size is cached, so the txs of a burst, e.g. the seals, do not simulate one by one. The static gas info of
+// the config is used if the simulation fails.
+type feeEstimator struct {
+	multiplier float64
+	cacheTTL   time.Duration
+
+	mux   sync.Mutex
+	cache map[string]gasEstimate
+}
+
+func newFeeEstimator(multiplier float64, cacheTTL time.Duration) *feeEstimator {
+	if multiplier < 1 {
+		multiplier = DefaultGasMultiplier
+	}
+	if cacheTTL <= 0 {
+		cacheTTL = DefaultGasEstimateCacheSecond * time.Second
+	}
+	return &feeEstimator{multiplier: multiplier, cacheTTL: cacheTTL, cache: make(map[string]gasEstimate)}
+}
+
+// estimate returns the gas info of the messages sent by the account with the nonce, static is returned if the
+// messages can not be simulated.
+func (e *feeEstimator) estimate(ctx context.Context, simulator txSimulator, msgs []sdk.Msg, nonce uint64, static GasInfo) GasInfo {
+	key, types := msgsKey(msgs)
+	now := time.Now()
+	e.mux.Lock()
+	cached, ok := e.cache[key]
+	e.mux.Unlock()
+	if ok && now.Before(cached.expireAt) {
+		metrics.SignerGasEstimateCounter.WithLabelValues(types, gasEstimateCached).Inc()
+		return cached.gasInfo
+	}
+
+	gasInfo, err := e.simulate(ctx, simulator, msgs, nonce, static)
+	if err != nil {
+		metrics.SignerGasEstimateCounter.WithLabelValues(types, gasEstimateStatic).Inc()
+		log.CtxWarnw(ctx, "failed to estimate gas, use the static gas info", "msgs", key,
+			"gas_limit", static.GasLimit, "fee_amount", static.FeeAmount.String(), "error", err)
+		return static
+	}
+	metrics.SignerGasEstimateCounter.WithLabelValues(types, gasEstimateSimulated).Inc()
+	e.mux.Lock()
+	e.cache[key] = gasEstimate{gasInfo: gasInfo, expireAt: now.Add(e.cacheTTL)}
+	e.mux.Unlock()
+	log.CtxDebugw(ctx, "succeed to estimate gas", "msgs", key, "gas_limit", gasInfo.GasLimit,
+		"fee_amount", gasInfo.FeeAmount.String(), "static_gas_limit", static.GasLimit,
+		"static_fee_amount", static.FeeAmount.String())
+	return gasInfo
+}
+
+func (e *feeEstimator) simulate(ctx context.Context, simulator txSimulator, msgs []sdk.Msg, nonce uint64, static GasInfo) (GasInfo, error) {
+	resp, err := simulator.SimulateTx(ctx, msgs, &ctypes.TxOption{
+		GasLimit:  static.GasLimit,
+		FeeAmount: static.FeeAmount,
+		Nonce:     nonce,
+	})
+	if err != nil {
+		return GasInfo{}, err
+	}
+	if resp.GetGasInfo() == nil || resp.GetGasInfo().GetGasUsed() == 0 {
+		return GasInfo{}, fmt.Errorf("no gas used in the simulation")
+	}
+	gasLimit := uint64(math.Ceil(float64(resp.GetGasInfo().GetGasUsed()) * e.multiplier))
+	return GasInfo{GasLimit: gasLimit, FeeAmount: estimateFee(gasLimit, resp.GetGasInfo().GetMinGasPrice(), static)}, nil
+}
+
+// estimateFee returns the fee of the gas limit by the min gas price of the chain, the price of the static gas info
+// is used if the min gas price is unavailable.
+func estimateFee(gasLimit uint64, minGasPrice string, static GasInfo) sdk.Coins {
+	if price, err := sdk.ParseDecCoin(minGasPrice); err == nil && price.IsPositive() {
+		amount := price.Amount.MulInt64(int64(gasLimit)).Ceil().TruncateInt()
+		return sdk.NewCoins(sdk.NewCoin(price.Denom, amount))
+	}
+	if static.GasLimit == 0 {
+		return static.FeeAmount
+	}
+	fee := make(sdk.Coins, 0, len(static.FeeAmount))
+	for _, coin := range static.FeeAmount {
+		amount := coin.Amount.Mul(sdkmath.NewIntFromUint64(gasLimit)).Quo(sdkmath.NewIntFromUint64(static.GasLimit))
+		fee = append(fee, sdk.NewCoin(coin.Denom, amount))
+	}
+	return sdk.NewCoins(fee...)
+}
+
+// msgsKey returns the key of the messages by their types, numbers and encoded size rounded up to
+// msgsSizeGranularity, e.g. "/mechain.storage.MsgSealObject*2@512", and the types of the messages.
+func msgsKey(msgs []sdk.Msg) (string, string) {
+	counts := make(map[string]int)
+	size := 0
+	for _, msg := range msgs {
+		counts[sdk.MsgTypeURL(msg)]++
+		if sized, ok := msg.(interface{ Size() int }); ok {
+			size += sized.Size()
+		}
+	}
+	size = (size + msgsSizeGranularity - 1) / msgsSizeGranularity * msgsSizeGranularity
+	types := make([]string, 0, len(counts))
+	for typeURL := range counts {
+		types = append(types, typeURL)
+	}
+	sort.Strings(types)
+	keys := make([]string, 0, len(types))
+	for _, typeURL := range types {
+		keys = append(keys, fmt.Sprintf("%s*%d", typeURL, counts[typeURL]))
+	}
+	return fmt.Sprintf("%s@%d", strings.Join(keys, ","), size), strings.Join(types, ",")
+}
+
+// applyFee sets the estimated gas limit and fee of the tx option if the estimator is enabled, and spends the fee
+// from the daily budget of the account if the budget is enabled. The fee must be refunded if the tx is not
+// broadcast.
+func applyFee(ctx context.Context, estimator *feeEstimator, budget *feeBudget, simulator txSimulator, account string,
+	msgs []sdk.Msg, txOpt *ctypes.TxOption,
+) error {
+	if estimator != nil {
+		gasInfo := estimator.estimate(ctx, simulator, msgs, txOpt.Nonce, GasInfo{GasLimit: txOpt.GasLimit, FeeAmount: txOpt.FeeAmount})
+		txOpt.GasLimit, txOpt.FeeAmount = gasInfo.GasLimit, gasInfo.FeeAmount
+	}
+	if budget != nil {
+		return budget.spend(account, txOpt.FeeAmount)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	ctypes "github.com/evmos/evmos/v12/sdk/types"
+	storagetypes "github.com/evmos/evmos/v12/x/storage/types"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+type mockTxSimulator struct {
+	gasUsed     uint64
+	minGasPrice string
+	err         error
+	calls       int
+}
+
+func (m *mockTxSimulator) SimulateTx(ctx context.Context, msgs []sdk.Msg, txOpt *ctypes.TxOption,
+	opts ...grpc.CallOption,
+) (*tx.SimulateResponse, error) {
+	m.calls++
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &tx.SimulateResponse{GasInfo: &sdk.GasInfo{GasUsed: m.gasUsed, MinGasPrice: m.minGasPrice}}, nil
+}
+
+func TestFeeEstimatorEstimate(t *testing.T) {
+	static := GasInfo{GasLimit: 1000, FeeAmount: sdk.NewCoins(sdk.NewCoin(ctypes.Denom, sdk.NewInt(5000)))}
+	cases := []struct {
+		name      string
+		simulator *mockTxSimulator
+		expected  GasInfo
+	}{
+		{"simulation error", &mockTxSimulator{err: errors.New("mock error")}, static},
+		{"no gas used", &mockTxSimulator{}, static},
+		{"min gas price", &mockTxSimulator{gasUsed: 500, minGasPrice: "2" + ctypes.Denom},
+			GasInfo{GasLimit: 600, FeeAmount: sdk.NewCoins(sdk.NewCoin(ctypes.Denom, sdk.NewInt(1200)))}},
+		{"static gas price", &mockTxSimulator{gasUsed: 2000},
+			GasInfo{GasLimit: 2400, FeeAmount: sdk.NewCoins(sdk.NewCoin(ctypes.Denom, sdk.NewInt(12000)))}},
+	}
+	msgs := []sdk.Msg{storagetypes.NewMsgSealObject(sdk.AccAddress("mock-address"), "bucket", "object", 1, nil)}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newFeeEstimator(1.2, time.Minute)
+			assert.Equal(t, tt.expected, e.estimate(context.Background(), tt.simulator, msgs, 0, static))
+		})
+	}
+}
+
+func TestFeeEstimatorCache(t *testing.T) {
+	static := GasInfo{GasLimit: 1000}
+	simulator := &mockTxSimulator{gasUsed: 100}
+	seal := storagetypes.NewMsgSealObject(sdk.AccAddress("mock-address"), "bucket", "object", 1, nil)
+	e := newFeeEstimator(0, time.Minute)
+	assert.Equal(t, DefaultGasMultiplier, e.multiplier)
+
+	assert.Equal(t, uint64(120), e.estimate(context.Background(), simulator, []sdk.Msg{seal}, 0, static).GasLimit)
+	simulator.gasUsed = 200
+	assert.Equal(t, uint64(120), e.estimate(context.Background(), simulator, []sdk.Msg{seal}, 1, static).GasLimit)
+	assert.Equal(t, 1, simulator.calls)
+	// the batch of the same messages is estimated separately
+	assert.Equal(t, uint64(240), e.estimate(context.Background(), simulator, []sdk.Msg{seal, seal}, 1, static).GasLimit)
+	assert.Equal(t, 2, simulator.calls)
+
+	e.cacheTTL = -time.Second
+	e.cache = make(map[string]gasEstimate)
+	e.estimate(context.Background(), simulator, []sdk.Msg{seal}, 0, static)
+	e.estimate(context.Background(), simulator, []sdk.Msg{seal}, 0, static)
+	assert.Equal(t, 4, simulator.calls)
+}
+
+func TestMsgsKey(t *testing.T) {
+	addr := sdk.AccAddress("mock-address")
+	seal := storagetypes.NewMsgSealObject(addr, "bucket", "object", 1, nil)
+	reject := storagetypes.NewMsgRejectUnsealedObject(addr, "bucket", "object")
+	key, types := msgsKey([]sdk.Msg{seal, reject, seal})
+	assert.Equal(t, sdk.MsgTypeURL(reject)+"*1,"+sdk.MsgTypeURL(seal)+"*2@256", key)
+	assert.Equal(t, sdk.MsgTypeURL(reject)+","+sdk.MsgTypeURL(seal), types)
+
+	// the messages of different sizes are estimated separately
+	large := storagetypes.NewMsgSealObject(addr, "bucket", strings.Repeat("o", 512), 1, nil)
+	key, _ = msgsKey([]sdk.Msg{large})
+	assert.Equal(t, sdk.MsgTypeURL(seal)+"*1@768", key)
+}
@@ -0,0 +1,46 @@
+package signer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/probe"
+)
+
+// SignerHealthCheckName defines the name of the signer key health check.
+const SignerHealthCheckName = "signer"
+
+var _ probe.HealthCheckProvider = &SignModular{}
+
+// healthCheckMsg is signed and verified by each key to make sure the key is loaded and usable.
+var healthCheckMsg = []byte("sp signer health check")
+
+// HealthChecks returns the check of the signer keys, the sp can not seal objects or sign approvals if any of
+// the keys is unavailable.
+func (s *SignModular) HealthChecks() []probe.Check {
+	return []probe.Check{{
+		Name:     SignerHealthCheckName,
+		Critical: true,
+		Func: func(ctx context.Context) (string, error) {
+			return s.client.checkKeys()
+		},
+	}}
+}
+
+// checkKeys signs the health check msg with each key and verifies the signature.
+func (client *MechainChainSignClient) checkKeys() (string, error) {
+	scopes := []SignType{SignOperator, SignSeal, SignApproval, SignGc}
+	for _, scope := range scopes {
+		if client.mechainClients[scope] == nil {
+			return "", fmt.Errorf("%s key is not loaded", scope)
+		}
+		sig, err := client.Sign(scope, healthCheckMsg)
+		if err != nil {
+			return "", fmt.Errorf("failed to sign with %s key: %w", scope, err)
+		}
+		if !client.VerifySignature(scope, healthCheckMsg, sig) {
+			return "", fmt.Errorf("failed to verify the signature of %s key", scope)
+		}
+	}
+	return fmt.Sprintf("%d keys available", len(scopes)), nil
+}
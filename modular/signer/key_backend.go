@@ -0,0 +1,366 @@
+package signer
+
+import (
+	"fmt"
+	"time"
+
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/evmos/evmos/v12/crypto/ethsecp256k1"
+	"github.com/evmos/evmos/v12/sdk/keys"
+
+	"github.com/zkMeLabs/mechain-storage-provider/base/gfspconfig"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/keysigner"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/mtls"
+)
+
+const (
+	// KeyBackendLocal defines the backend of the private keys loaded from the config or the env.
+	KeyBackendLocal = "local"
+	// KeyBackendPKCS11 defines the backend of the keys in the PKCS#11 HSM.
+	KeyBackendPKCS11 = "pkcs11"
+	// KeyBackendRemote defines the backend of the keys in the remote signing service.
+	KeyBackendRemote = "remote"
+
+	OperatorKeyID = "operator"
+	SealKeyID     = "seal"
+	ApprovalKeyID = "approval"
+	GcKeyID       = "gc"
+	BlsKeyID      = "bls"
+	// sealAccountKeyID is the key id of the seal account pool keys in the local backend.
+	sealAccountKeyID = "seal-account-%d"
+)
+
+// spSigners are the signers of the SP keys in the key backends.
+type spSigners struct {
+	backends     []keysigner.Backend
+	txSigners    map[SignType]keysigner.Signer
+	bls          keysigner.Signer
+	sealAccounts []keysigner.Signer
+	// rotating are the signers of the seal, approval and bls keys by the key ids, they switch to the next keys
+	// once the chain reflects the rotation.
+	rotating map[string]*rotatingSigner
+}
+
+// Close closes the key backends.
+func (s *spSigners) Close() error {
+	var err error
+	for _, backend := range s.backends {
+		if closeErr := backend.Close(); closeErr != nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// loadSPSigners loads the signers of the SP keys from the key backend of the config.
+func loadSPSigners(cfg *gfspconfig.SpAccountConfig) (signers *spSigners, err error) {
+	signers = &spSigners{
+		txSigners: make(map[SignType]keysigner.Signer),
+		rotating:  make(map[string]*rotatingSigner),
+	}
+	defer func() {
+		if err != nil {
+			_ = signers.Close()
+		}
+	}()
+
+	var (
+		backend           keysigner.Backend
+		blsBackend        keysigner.Backend
+		keyIDPrefix       = cfg.KeyIDPrefix
+		sealAccountKeyIDs = cfg.SealAccountKeyIDs
+		nextKeys          = cfg.NextKeys
+	)
+	switch cfg.KeyBackend {
+	case "", KeyBackendLocal:
+		local, err := newLocalKeyBackend(cfg)
+		if err != nil {
+			return nil, err
+		}
+		signers.backends = append(signers.backends, local)
+		backend, blsBackend, keyIDPrefix = local, local, ""
+		sealAccountKeyIDs = make([]string, 0, len(cfg.SealAccountPrivateKeys))
+		for i := range cfg.SealAccountPrivateKeys {
+			sealAccountKeyIDs = append(sealAccountKeyIDs, fmt.Sprintf(sealAccountKeyID, i))
+		}
+	case KeyBackendPKCS11:
+		if backend, err = keysigner.NewPKCS11Backend(keysigner.PKCS11Config{
+			Module:     cfg.PKCS11Module,
+			TokenLabel: cfg.PKCS11TokenLabel,
+			Pin:        cfg.PKCS11Pin,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to open pkcs11 key backend: %w", err)
+		}
+		signers.backends = append(signers.backends, backend)
+		if cfg.RemoteSignerAddress != "" {
+			if blsBackend, err = newRemoteKeyBackend(cfg); err != nil {
+				return nil, err
+			}
+			signers.backends = append(signers.backends, blsBackend)
+		} else {
+			bls, err := newBlsKeySigner(cfg.BlsPrivateKey)
+			if err != nil {
+				return nil, err
+			}
+			local, _ := keysigner.NewLocalBackend(nil)
+			local.Add(keyIDPrefix+BlsKeyID, bls)
+			if cfg.NextBlsPrivateKey != "" {
+				nextBls, err := newBlsKeySigner(cfg.NextBlsPrivateKey)
+				if err != nil {
+					return nil, err
+				}
+				local.Add(keyIDPrefix+nextKeyIDPrefix+BlsKeyID, nextBls)
+			}
+			blsBackend = local
+		}
+	case KeyBackendRemote:
+		if backend, err = newRemoteKeyBackend(cfg); err != nil {
+			return nil, err
+		}
+		signers.backends = append(signers.backends, backend)
+		blsBackend = backend
+	default:
+		return nil, fmt.Errorf("unknown key backend %s", cfg.KeyBackend)
+	}
+
+	for scope, keyID := range map[SignType]string{
+		SignOperator: OperatorKeyID,
+		SignSeal:     SealKeyID,
+		SignApproval: ApprovalKeyID,
+		SignGc:       GcKeyID,
+	} {
+		if signers.txSigners[scope], err = secp256k1Signer(backend, keyIDPrefix+keyID); err != nil {
+			return nil, err
+		}
+	}
+	if signers.bls, err = blsBackend.Signer(keyIDPrefix + BlsKeyID); err != nil {
+		return nil, fmt.Errorf("failed to load bls key: %w", err)
+	}
+	if signers.bls.KeyType() != keysigner.KeyTypeBLS {
+		return nil, fmt.Errorf("key %s is not a bls key", keyIDPrefix+BlsKeyID)
+	}
+	if err = signers.loadNextKeys(backend, blsBackend, keyIDPrefix, nextKeys); err != nil {
+		return nil, err
+	}
+	for _, keyID := range sealAccountKeyIDs {
+		signer, err := secp256k1Signer(backend, keyID)
+		if err != nil {
+			return nil, err
+		}
+		signers.sealAccounts = append(signers.sealAccounts, signer)
+	}
+	return signers, nil
+}
+
+// loadNextKeys wraps the seal, approval and bls signers in the rotating signers, the next keys are loaded
+// from the backends for the keys being rotated.
+func (s *spSigners) loadNextKeys(backend, blsBackend keysigner.Backend, keyIDPrefix string, nextKeys []string) error {
+	for _, keyID := range RotatableKeyIDs {
+		var active keysigner.Signer
+		switch keyID {
+		case SealKeyID:
+			active = s.txSigners[SignSeal]
+		case ApprovalKeyID:
+			active = s.txSigners[SignApproval]
+		case BlsKeyID:
+			active = s.bls
+		}
+		s.rotating[keyID] = newRotatingSigner(active, nil)
+	}
+	for _, keyID := range nextKeys {
+		signer, ok := s.rotating[keyID]
+		if !ok {
+			return fmt.Errorf("key %s can not be rotated", keyID)
+		}
+		nextKeyID := keyIDPrefix + nextKeyIDPrefix + keyID
+		if keyID == BlsKeyID {
+			next, err := blsBackend.Signer(nextKeyID)
+			if err != nil {
+				return fmt.Errorf("failed to load bls key %s: %w", nextKeyID, err)
+			}
+			if next.KeyType() != keysigner.KeyTypeBLS {
+				return fmt.Errorf("key %s is not a bls key", nextKeyID)
+			}
+			signer.next = next
+			continue
+		}
+		next, err := secp256k1Signer(backend, nextKeyID)
+		if err != nil {
+			return err
+		}
+		signer.next = next
+	}
+	s.txSigners[SignSeal] = s.rotating[SealKeyID]
+	s.txSigners[SignApproval] = s.rotating[ApprovalKeyID]
+	s.bls = s.rotating[BlsKeyID]
+	return nil
+}
+
+// localNextKeys returns the ids of the keys whose next private keys are set in the config.
+func localNextKeys(cfg *gfspconfig.SpAccountConfig) []string {
+	var nextKeys []string
+	for keyID, hexKey := range map[string]string{
+		SealKeyID:     cfg.NextSealPrivateKey,
+		ApprovalKeyID: cfg.NextApprovalPrivateKey,
+		BlsKeyID:      cfg.NextBlsPrivateKey,
+	} {
+		if hexKey != "" {
+			nextKeys = append(nextKeys, keyID)
+		}
+	}
+	return nextKeys
+}
+
+func secp256k1Signer(backend keysigner.Backend, keyID string) (keysigner.Signer, error) {
+	signer, err := backend.Signer(keyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load key %s: %w", keyID, err)
+	}
+	if signer.KeyType() != keysigner.KeyTypeSecp256k1 {
+		return nil, fmt.Errorf("key %s is not a secp256k1 key", keyID)
+	}
+	return signer, nil
+}
+
+// newLocalKeyBackend returns the backend of the hex private keys of the config.
+func newLocalKeyBackend(cfg *gfspconfig.SpAccountConfig) (*keysigner.LocalBackend, error) {
+	hexKeys := map[string]string{
+		OperatorKeyID: cfg.OperatorPrivateKey,
+		SealKeyID:     cfg.SealPrivateKey,
+		ApprovalKeyID: cfg.ApprovalPrivateKey,
+		GcKeyID:       cfg.GcPrivateKey,
+	}
+	for i, hexKey := range cfg.SealAccountPrivateKeys {
+		hexKeys[fmt.Sprintf(sealAccountKeyID, i)] = hexKey
+	}
+	if cfg.NextSealPrivateKey != "" {
+		hexKeys[nextKeyIDPrefix+SealKeyID] = cfg.NextSealPrivateKey
+	}
+	if cfg.NextApprovalPrivateKey != "" {
+		hexKeys[nextKeyIDPrefix+ApprovalKeyID] = cfg.NextApprovalPrivateKey
+	}
+	backend, err := keysigner.NewLocalBackend(hexKeys)
+	if err != nil {
+		return nil, err
+	}
+	bls, err := newBlsKeySigner(cfg.BlsPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	backend.Add(BlsKeyID, bls)
+	if cfg.NextBlsPrivateKey != "" {
+		nextBls, err := newBlsKeySigner(cfg.NextBlsPrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		backend.Add(nextKeyIDPrefix+BlsKeyID, nextBls)
+	}
+	return backend, nil
+}
+
+// newRemoteKeyBackend returns the backend of the remote signing service of the config.
+func newRemoteKeyBackend(cfg *gfspconfig.SpAccountConfig) (*keysigner.RemoteBackend, error) {
+	remoteCfg := keysigner.RemoteConfig{
+		Address: cfg.RemoteSignerAddress,
+		Timeout: time.Duration(cfg.RemoteSignerTimeoutSecond) * time.Second,
+	}
+	if cfg.RemoteSignerTLSCertFile != "" {
+		reloader, err := mtls.NewReloader(mtls.Config{
+			CAFile:     cfg.RemoteSignerTLSCAFile,
+			CertFile:   cfg.RemoteSignerTLSCertFile,
+			KeyFile:    cfg.RemoteSignerTLSKeyFile,
+			ServerName: cfg.RemoteSignerTLSServerName,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to load remote signer tls: %w", err)
+		}
+		remoteCfg.Creds = reloader.ClientCredentials()
+	}
+	backend, err := keysigner.NewRemoteBackend(remoteCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect remote signer: %w", err)
+	}
+	return backend, nil
+}
+
+// blsKeySigner is the signer of the hex bls private key in memory.
+type blsKeySigner struct {
+	km keys.KeyManager
+}
+
+func newBlsKeySigner(hexKey string) (keysigner.Signer, error) {
+	km, err := keys.NewBlsPrivateKeyManager(hexKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bls private key: %w", err)
+	}
+	return &blsKeySigner{km: km}, nil
+}
+
+func (s *blsKeySigner) KeyType() keysigner.KeyType {
+	return keysigner.KeyTypeBLS
+}
+
+func (s *blsKeySigner) PublicKey() []byte {
+	return s.km.PubKey().Bytes()
+}
+
+func (s *blsKeySigner) Sign(msg []byte) ([]byte, error) {
+	return s.km.Sign(msg)
+}
+
+var _ keys.KeyManager = &backendKeyManager{}
+
+// backendKeyManager is the key manager of the mechain clients which signs by the secp256k1 key in the backend,
+// the private key is never exposed, so Bytes returns nil. The public key is read from the signer on each call,
+// so the key manager follows the rotating signer.
+type backendKeyManager struct {
+	signer keysigner.Signer
+}
+
+func newBackendKeyManager(signer keysigner.Signer) keys.KeyManager {
+	return &backendKeyManager{signer: signer}
+}
+
+// secp256k1Address returns the account address of the compressed secp256k1 public key.
+func secp256k1Address(pubKey []byte) sdk.AccAddress {
+	return sdk.AccAddress((&ethsecp256k1.PubKey{Key: pubKey}).Address())
+}
+
+func (km *backendKeyManager) GetAddr() sdk.AccAddress {
+	return secp256k1Address(km.signer.PublicKey())
+}
+
+// Sign signs the keccak256 hash of the msg like the ethsecp256k1 private key, the msg of 32 bytes is signed as
+// the digest.
+func (km *backendKeyManager) Sign(msg []byte) ([]byte, error) {
+	if len(msg) != keysigner.DigestLength {
+		msg = crypto.Keccak256(msg)
+	}
+	return km.signer.Sign(msg)
+}
+
+func (km *backendKeyManager) PubKey() cryptotypes.PubKey {
+	return &ethsecp256k1.PubKey{Key: km.signer.PublicKey()}
+}
+
+func (km *backendKeyManager) Bytes() []byte {
+	return nil
+}
+
+func (km *backendKeyManager) Equals(other cryptotypes.LedgerPrivKey) bool {
+	return other != nil && km.PubKey().Equals(other.PubKey())
+}
+
+func (km *backendKeyManager) Type() string {
+	return ethsecp256k1.KeyType
+}
+
+func (km *backendKeyManager) Reset() {}
+
+func (km *backendKeyManager) String() string {
+	return fmt.Sprintf("backendKeyManager{%s}", km.GetAddr())
+}
+
+func (km *backendKeyManager) ProtoMessage() {}
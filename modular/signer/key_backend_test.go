@@ -0,0 +1,58 @@
+package signer
+
+import (
+	"testing"
+
+	"github.com/evmos/evmos/v12/sdk/keys"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zkMeLabs/mechain-storage-provider/base/gfspconfig"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/keysigner"
+)
+
+const mockHexKey = "b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291"
+
+func TestBackendKeyManager(t *testing.T) {
+	expected, err := keys.NewPrivateKeyManager(mockHexKey)
+	assert.Nil(t, err)
+	signer, err := keysigner.NewSecp256k1Signer(mockHexKey)
+	assert.Nil(t, err)
+	km := newBackendKeyManager(signer)
+
+	assert.Equal(t, expected.GetAddr(), km.GetAddr())
+	assert.True(t, km.PubKey().Equals(expected.PubKey()))
+	assert.True(t, km.Equals(expected))
+	assert.Nil(t, km.Bytes())
+	for _, msg := range [][]byte{[]byte("mock approval bytes"), make([]byte, keysigner.DigestLength)} {
+		sig, err := km.Sign(msg)
+		assert.Nil(t, err)
+		expectedSig, err := expected.Sign(msg)
+		assert.Nil(t, err)
+		assert.Equal(t, expectedSig, sig)
+		assert.True(t, expected.PubKey().VerifySignature(msg, sig))
+	}
+}
+
+func TestLoadSPSigners(t *testing.T) {
+	cases := []struct {
+		name string
+		cfg  *gfspconfig.SpAccountConfig
+	}{
+		{"unknown backend", &gfspconfig.SpAccountConfig{KeyBackend: "kms"}},
+		{"invalid local key", &gfspconfig.SpAccountConfig{OperatorPrivateKey: "invalid"}},
+		{"missing remote address", &gfspconfig.SpAccountConfig{KeyBackend: KeyBackendRemote}},
+		{"invalid next key", &gfspconfig.SpAccountConfig{
+			OperatorPrivateKey: mockHexKey,
+			SealPrivateKey:     mockHexKey,
+			ApprovalPrivateKey: mockHexKey,
+			GcPrivateKey:       mockHexKey,
+			NextSealPrivateKey: "invalid",
+		}},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadSPSigners(tt.cfg)
+			assert.NotNil(t, err)
+		})
+	}
+}
@@ -0,0 +1,334 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/evmos/evmos/v12/sdk/client"
+	ctypes "github.com/evmos/evmos/v12/sdk/types"
+	sptypes "github.com/evmos/evmos/v12/x/sp/types"
+
+	"github.com/zkMeLabs/mechain-storage-provider/base/gfspconfig"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/keysigner"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+)
+
+const (
+	// nextKeyIDPrefix is prefixed to the key id of the key which the key is rotated to, e.g. "next-seal".
+	nextKeyIDPrefix = "next-"
+
+	// DefaultKeyRotationCheckIntervalSecond defines the default interval of checking whether the chain reflects
+	// the next keys, it is close to the block time so that the signer switches soon after the chain.
+	DefaultKeyRotationCheckIntervalSecond = 3
+	// DefaultKeyRotationGracePeriodSecond defines the default time the previous key is still accepted by the
+	// verification after the switch.
+	DefaultKeyRotationGracePeriodSecond = 600
+
+	// DefaultEditSPGasLimit defines the default gas limit of the MsgEditStorageProvider of the key rotation.
+	DefaultEditSPGasLimit = 12000
+	// DefaultEditSPFeeAmount defines the default fee of the MsgEditStorageProvider of the key rotation.
+	DefaultEditSPFeeAmount = 60000000000000
+
+	// SpNextSealPrivKey defines env variable name for the next sp seal private key
+	SpNextSealPrivKey = "SIGNER_NEXT_SEAL_PRIV_KEY"
+	// SpNextApprovalPrivKey defines env variable name for the next sp approval private key
+	SpNextApprovalPrivKey = "SIGNER_NEXT_APPROVAL_PRIV_KEY"
+	// SpNextBlsPrivKey defines env variable name for the next sp bls private key
+	SpNextBlsPrivKey = "SIGNER_NEXT_BLS_PRIV_KEY"
+)
+
+// RotatableKeyIDs are the ids of the SP keys which can be rotated on chain.
+var RotatableKeyIDs = []string{SealKeyID, ApprovalKeyID, BlsKeyID}
+
+var _ keysigner.Signer = &rotatingSigner{}
+
+// rotatingSigner signs by the active key of a rotatable SP key. It switches to the next key atomically once the
+// chain reflects the next key, the replaced key is kept as the previous key and is still accepted by the
+// verification until the end of the grace period.
+type rotatingSigner struct {
+	mux           sync.RWMutex
+	active        keysigner.Signer
+	next          keysigner.Signer
+	previous      keysigner.Signer
+	previousUntil time.Time
+}
+
+func newRotatingSigner(active, next keysigner.Signer) *rotatingSigner {
+	return &rotatingSigner{active: active, next: next}
+}
+
+func (s *rotatingSigner) KeyType() keysigner.KeyType {
+	return s.current().KeyType()
+}
+
+func (s *rotatingSigner) PublicKey() []byte {
+	return s.current().PublicKey()
+}
+
+func (s *rotatingSigner) Sign(msg []byte) ([]byte, error) {
+	return s.current().Sign(msg)
+}
+
+// current returns the signer of the active key.
+func (s *rotatingSigner) current() keysigner.Signer {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	return s.active
+}
+
+// nextKey returns the signer of the next key, nil is returned if the key is not being rotated.
+func (s *rotatingSigner) nextKey() keysigner.Signer {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	return s.next
+}
+
+// promote switches the active key to the next key, the replaced key is accepted by the verification until the
+// previousUntil. It returns false if there is no next key.
+func (s *rotatingSigner) promote(previousUntil time.Time) bool {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.next == nil {
+		return false
+	}
+	s.previous, s.previousUntil = s.active, previousUntil
+	s.active, s.next = s.next, nil
+	return true
+}
+
+// verifiers returns the signers of the keys accepted by the verification at the time, i.e. the active key and
+// the previous key within the grace period.
+func (s *rotatingSigner) verifiers(now time.Time) []keysigner.Signer {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	if s.previous != nil && now.Before(s.previousUntil) {
+		return []keysigner.Signer{s.active, s.previous}
+	}
+	return []keysigner.Signer{s.active}
+}
+
+// reflectedOnChain returns whether the chain holds the public key of the signer as the SP key of the id.
+func reflectedOnChain(keyID string, sp *sptypes.StorageProvider, signer keysigner.Signer) bool {
+	switch keyID {
+	case SealKeyID:
+		return sameAddress(sp.GetSealAddress(), signer)
+	case ApprovalKeyID:
+		return sameAddress(sp.GetApprovalAddress(), signer)
+	case BlsKeyID:
+		return bytes.Equal(sp.GetBlsKey(), signer.PublicKey())
+	default:
+		return false
+	}
+}
+
+func sameAddress(address string, signer keysigner.Signer) bool {
+	addr, err := sdk.AccAddressFromHexUnsafe(address)
+	if err != nil {
+		return false
+	}
+	return addr.Equals(secp256k1Address(signer.PublicKey()))
+}
+
+// keyRotator switches the rotating signers to their next keys once the chain reflects them. The chain only
+// accepts the keys it holds, so the signer keeps signing with the active key until the on-chain update is
+// committed, and then switches to the next key before the next signature.
+type keyRotator struct {
+	signers map[string]*rotatingSigner
+	querySP func(ctx context.Context) (*sptypes.StorageProvider, error)
+	// switchKey runs the promotion of the key, e.g. the seal key is promoted under the seal lock and its nonce
+	// is reset after the promotion.
+	switchKey     func(ctx context.Context, keyID string, promote func() bool)
+	checkInterval time.Duration
+	gracePeriod   time.Duration
+
+	stopCh chan struct{}
+	doneCh chan struct{}
+	start  sync.Once
+	stop   sync.Once
+}
+
+func newKeyRotator(signers map[string]*rotatingSigner, querySP func(ctx context.Context) (*sptypes.StorageProvider, error),
+	switchKey func(ctx context.Context, keyID string, promote func() bool), checkInterval, gracePeriod time.Duration,
+) *keyRotator {
+	if checkInterval <= 0 {
+		checkInterval = DefaultKeyRotationCheckIntervalSecond * time.Second
+	}
+	if gracePeriod <= 0 {
+		gracePeriod = DefaultKeyRotationGracePeriodSecond * time.Second
+	}
+	return &keyRotator{
+		signers:       signers,
+		querySP:       querySP,
+		switchKey:     switchKey,
+		checkInterval: checkInterval,
+		gracePeriod:   gracePeriod,
+		stopCh:        make(chan struct{}),
+		doneCh:        make(chan struct{}),
+	}
+}
+
+// rotating returns whether any key has the next key.
+func (r *keyRotator) rotating() bool {
+	for _, signer := range r.signers {
+		if signer.nextKey() != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Start checks the chain once, e.g. the signer is restarted after the chain reflects the next keys, and then
+// starts the background loop of checking the chain until all the keys are switched.
+func (r *keyRotator) Start(ctx context.Context) {
+	r.start.Do(func() {
+		if !r.rotating() {
+			close(r.doneCh)
+			return
+		}
+		r.check(ctx, time.Now())
+		go r.loop()
+	})
+}
+
+// Stop stops checking the chain.
+func (r *keyRotator) Stop() {
+	r.stop.Do(func() {
+		close(r.stopCh)
+		r.start.Do(func() { close(r.doneCh) })
+		<-r.doneCh
+	})
+}
+
+func (r *keyRotator) loop() {
+	defer close(r.doneCh)
+	ticker := time.NewTicker(r.checkInterval)
+	defer ticker.Stop()
+	for r.rotating() {
+		select {
+		case <-r.stopCh:
+			return
+		case <-ticker.C:
+			r.check(context.Background(), time.Now())
+		}
+	}
+	log.Info("all the rotated sp keys are switched")
+}
+
+// check switches the keys whose next keys are held by the chain, the switched keys are returned.
+func (r *keyRotator) check(ctx context.Context, now time.Time) []string {
+	sp, err := r.querySP(ctx)
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to query sp info for key rotation", "error", err)
+		return nil
+	}
+	var switched []string
+	for _, keyID := range RotatableKeyIDs {
+		signer, ok := r.signers[keyID]
+		if !ok {
+			continue
+		}
+		next := signer.nextKey()
+		if next == nil {
+			continue
+		}
+		if !reflectedOnChain(keyID, sp, next) {
+			if !reflectedOnChain(keyID, sp, signer.current()) {
+				log.CtxWarnw(ctx, "chain holds neither the active nor the next sp key", "key", keyID)
+			}
+			continue
+		}
+		promote := func() bool { return signer.promote(now.Add(r.gracePeriod)) }
+		if r.switchKey != nil {
+			r.switchKey(ctx, keyID, promote)
+		} else {
+			promote()
+		}
+		log.CtxInfow(ctx, "switched sp key to the next key reflected by the chain", "key", keyID,
+			"previous_valid_until", now.Add(r.gracePeriod))
+		switched = append(switched, keyID)
+	}
+	return switched
+}
+
+// SubmitKeyRotation submits the MsgEditStorageProvider which updates the keys of the ids to their next keys, it is
+// signed by the operator key of the key backend. The running signer switches to the next keys once the chain
+// reflects the update, the tx hash is returned.
+func SubmitKeyRotation(ctx context.Context, cfg *gfspconfig.GfSpConfig, keyIDs []string) (string, error) {
+	if len(cfg.Chain.ChainAddress) == 0 || len(cfg.Chain.RpcAddress) == 0 {
+		return "", fmt.Errorf("chain address missing")
+	}
+	overrideSpAccountFromEnv(&cfg.SpAccount)
+	signers, err := loadSPSigners(&cfg.SpAccount)
+	if err != nil {
+		return "", err
+	}
+	defer signers.Close()
+
+	msg, err := newEditSPKeysMsg(cfg.SpAccount.SpOperatorAddress, signers.rotating, keyIDs)
+	if err != nil {
+		return "", err
+	}
+	operatorClient, err := client.NewMechainClient(cfg.Chain.ChainAddress[0], cfg.Chain.RpcAddress[0], cfg.Chain.ChainID,
+		client.WithKeyManager(newBackendKeyManager(signers.txSigners[SignOperator])))
+	if err != nil {
+		return "", err
+	}
+	mode := tx.BroadcastMode_BROADCAST_MODE_SYNC
+	resp, err := operatorClient.BroadcastTx(ctx, []sdk.Msg{msg}, &ctypes.TxOption{
+		Mode:      &mode,
+		GasLimit:  DefaultEditSPGasLimit,
+		FeeAmount: sdk.NewCoins(sdk.NewCoin(ctypes.Denom, sdk.NewInt(DefaultEditSPFeeAmount))),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to broadcast edit sp tx: %w", err)
+	}
+	if resp.TxResponse.Code != 0 {
+		return "", fmt.Errorf("failed to broadcast edit sp tx, resp code: %d, code space: %s, log: %s",
+			resp.TxResponse.Code, resp.TxResponse.Codespace, resp.TxResponse.RawLog)
+	}
+	return resp.TxResponse.TxHash, nil
+}
+
+// newEditSPKeysMsg returns the MsgEditStorageProvider which updates the keys of the ids to their next keys, the
+// new bls key is proved by its signature of the sha256 hash of the public key.
+func newEditSPKeysMsg(spAddress string, rotating map[string]*rotatingSigner, keyIDs []string) (
+	*sptypes.MsgEditStorageProvider, error,
+) {
+	if len(keyIDs) == 0 {
+		return nil, fmt.Errorf("no key to rotate")
+	}
+	msg := &sptypes.MsgEditStorageProvider{SpAddress: spAddress}
+	for _, keyID := range keyIDs {
+		signer, ok := rotating[keyID]
+		if !ok {
+			return nil, fmt.Errorf("key %s can not be rotated", keyID)
+		}
+		next := signer.nextKey()
+		if next == nil {
+			return nil, fmt.Errorf("next %s key is not configured", keyID)
+		}
+		switch keyID {
+		case SealKeyID:
+			msg.SealAddress = secp256k1Address(next.PublicKey()).String()
+		case ApprovalKeyID:
+			msg.ApprovalAddress = secp256k1Address(next.PublicKey()).String()
+		case BlsKeyID:
+			pubKey := next.PublicKey()
+			hash := sha256.Sum256(pubKey)
+			proof, err := next.Sign(hash[:])
+			if err != nil {
+				return nil, fmt.Errorf("failed to sign bls proof: %w", err)
+			}
+			msg.BlsKey = hex.EncodeToString(pubKey)
+			msg.BlsProof = hex.EncodeToString(proof)
+		}
+	}
+	return msg, nil
+}
@@ -0,0 +1,166 @@
+package signer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	sptypes "github.com/evmos/evmos/v12/x/sp/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/keysigner"
+)
+
+const mockNextHexKey = "8f2a55949038a9610f50fb23b5883af3b4ecb3c3bb792cbcefbd1542c692be63"
+
+// mockBlsSigner returns the fixed bls public key and the signature of the msg prefixed by the public key.
+type mockBlsSigner struct {
+	pubKey []byte
+}
+
+func (s *mockBlsSigner) KeyType() keysigner.KeyType {
+	return keysigner.KeyTypeBLS
+}
+
+func (s *mockBlsSigner) PublicKey() []byte {
+	return s.pubKey
+}
+
+func (s *mockBlsSigner) Sign(msg []byte) ([]byte, error) {
+	return append(append([]byte{}, s.pubKey...), msg...), nil
+}
+
+func newMockRotatingSigner(t *testing.T) (*rotatingSigner, keysigner.Signer, keysigner.Signer) {
+	active, err := keysigner.NewSecp256k1Signer(mockHexKey)
+	assert.Nil(t, err)
+	next, err := keysigner.NewSecp256k1Signer(mockNextHexKey)
+	assert.Nil(t, err)
+	return newRotatingSigner(active, next), active, next
+}
+
+func TestRotatingSigner(t *testing.T) {
+	signer, active, next := newMockRotatingSigner(t)
+	now := time.Now()
+	assert.Equal(t, active.PublicKey(), signer.PublicKey())
+	assert.Equal(t, next, signer.nextKey())
+	assert.Equal(t, []keysigner.Signer{active}, signer.verifiers(now))
+
+	assert.True(t, signer.promote(now.Add(time.Minute)))
+	assert.Equal(t, next.PublicKey(), signer.PublicKey())
+	assert.Nil(t, signer.nextKey())
+	assert.Equal(t, []keysigner.Signer{next, active}, signer.verifiers(now))
+	assert.Equal(t, []keysigner.Signer{next}, signer.verifiers(now.Add(time.Minute)))
+
+	digest := make([]byte, keysigner.DigestLength)
+	sig, err := signer.Sign(digest)
+	assert.Nil(t, err)
+	expected, err := next.Sign(digest)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, sig)
+	assert.False(t, signer.promote(now))
+}
+
+func TestKeyRotatorCheck(t *testing.T) {
+	activeBls := &mockBlsSigner{pubKey: []byte("active bls key")}
+	nextBls := &mockBlsSigner{pubKey: []byte("next bls key")}
+	active, err := keysigner.NewSecp256k1Signer(mockHexKey)
+	assert.Nil(t, err)
+	next, err := keysigner.NewSecp256k1Signer(mockNextHexKey)
+	assert.Nil(t, err)
+	activeAddr := secp256k1Address(active.PublicKey()).String()
+	nextAddr := secp256k1Address(next.PublicKey()).String()
+
+	cases := []struct {
+		name     string
+		sp       *sptypes.StorageProvider
+		queryErr error
+		switched []string
+	}{
+		{"query error", nil, errors.New("mock error"), nil},
+		{"not reflected", &sptypes.StorageProvider{SealAddress: activeAddr, ApprovalAddress: activeAddr, BlsKey: activeBls.pubKey}, nil, nil},
+		{"seal reflected", &sptypes.StorageProvider{SealAddress: nextAddr, ApprovalAddress: activeAddr, BlsKey: activeBls.pubKey}, nil, []string{SealKeyID}},
+		{"all reflected", &sptypes.StorageProvider{SealAddress: nextAddr, ApprovalAddress: nextAddr, BlsKey: nextBls.pubKey}, nil, []string{SealKeyID, BlsKeyID}},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			signers := map[string]*rotatingSigner{
+				SealKeyID:     newRotatingSigner(active, next),
+				ApprovalKeyID: newRotatingSigner(active, nil),
+				BlsKeyID:      newRotatingSigner(activeBls, nextBls),
+			}
+			var locked []string
+			r := newKeyRotator(signers, func(ctx context.Context) (*sptypes.StorageProvider, error) {
+				return tt.sp, tt.queryErr
+			}, func(ctx context.Context, keyID string, promote func() bool) {
+				locked = append(locked, keyID)
+				promote()
+			}, 0, time.Minute)
+			assert.Equal(t, tt.switched, r.check(context.Background(), time.Now()))
+			assert.Equal(t, tt.switched, locked)
+			for _, keyID := range tt.switched {
+				assert.Nil(t, signers[keyID].nextKey())
+			}
+			assert.Equal(t, len(tt.switched) != 2, r.rotating())
+		})
+	}
+}
+
+func TestKeyRotatorStartStop(t *testing.T) {
+	signer, _, next := newMockRotatingSigner(t)
+	r := newKeyRotator(map[string]*rotatingSigner{SealKeyID: signer}, func(ctx context.Context) (*sptypes.StorageProvider, error) {
+		return &sptypes.StorageProvider{SealAddress: secp256k1Address(next.PublicKey()).String()}, nil
+	}, nil, time.Millisecond, time.Minute)
+	r.Start(context.Background())
+	assert.Equal(t, next.PublicKey(), signer.PublicKey())
+	r.Stop()
+
+	idle := newKeyRotator(map[string]*rotatingSigner{SealKeyID: newRotatingSigner(next, nil)}, nil, nil, 0, 0)
+	idle.Start(context.Background())
+	idle.Stop()
+}
+
+func TestVerifySignatureDuringGracePeriod(t *testing.T) {
+	signer, active, _ := newMockRotatingSigner(t)
+	client := &MechainChainSignClient{txSigners: map[SignType]keysigner.Signer{SignApproval: signer}}
+	msg := []byte("mock approval bytes")
+	oldSig, err := newBackendKeyManager(active).Sign(msg)
+	assert.Nil(t, err)
+	assert.True(t, client.VerifySignature(SignApproval, msg, oldSig))
+
+	assert.True(t, signer.promote(time.Now().Add(time.Hour)))
+	newSig, err := newBackendKeyManager(signer).Sign(msg)
+	assert.Nil(t, err)
+	assert.True(t, client.VerifySignature(SignApproval, msg, newSig))
+	assert.True(t, client.VerifySignature(SignApproval, msg, oldSig))
+
+	signer.previousUntil = time.Now().Add(-time.Second)
+	assert.True(t, client.VerifySignature(SignApproval, msg, newSig))
+	assert.False(t, client.VerifySignature(SignApproval, msg, oldSig))
+}
+
+func TestNewEditSPKeysMsg(t *testing.T) {
+	seal, _, next := newMockRotatingSigner(t)
+	nextBls := &mockBlsSigner{pubKey: []byte("next bls key")}
+	rotating := map[string]*rotatingSigner{
+		SealKeyID:     seal,
+		ApprovalKeyID: newRotatingSigner(next, nil),
+		BlsKeyID:      newRotatingSigner(&mockBlsSigner{pubKey: []byte("active bls key")}, nextBls),
+	}
+
+	msg, err := newEditSPKeysMsg("0x0000000000000000000000000000000000000001", rotating, []string{SealKeyID, BlsKeyID})
+	assert.Nil(t, err)
+	assert.Equal(t, secp256k1Address(next.PublicKey()).String(), msg.SealAddress)
+	assert.Empty(t, msg.ApprovalAddress)
+	assert.Equal(t, hex.EncodeToString(nextBls.pubKey), msg.BlsKey)
+	hash := sha256.Sum256(nextBls.pubKey)
+	proof, _ := nextBls.Sign(hash[:])
+	assert.Equal(t, hex.EncodeToString(proof), msg.BlsProof)
+
+	for _, keyIDs := range [][]string{nil, {GcKeyID}, {ApprovalKeyID}} {
+		_, err = newEditSPKeysMsg("0x0000000000000000000000000000000000000001", rotating, keyIDs)
+		assert.NotNil(t, err)
+	}
+}
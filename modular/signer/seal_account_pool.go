@@ -0,0 +1,434 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkErrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	"github.com/evmos/evmos/v12/sdk/client"
+	ctypes "github.com/evmos/evmos/v12/sdk/types"
+	"google.golang.org/grpc"
+
+	"github.com/zkMeLabs/mechain-storage-provider/base/types/gfsperrors"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/keysigner"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+)
+
+const (
+	// DefaultAuthzExecGasLimit defines the default extra gas limit of wrapping the messages in the MsgExec.
+	DefaultAuthzExecGasLimit = 12000
+	// DefaultAuthzExecFeeAmount defines the default extra fee of wrapping the messages in the MsgExec.
+	DefaultAuthzExecFeeAmount = 60000000000000
+	// DefaultSealAccountCheckIntervalSecond defines the default interval of checking the pending txs of the
+	// seal accounts.
+	DefaultSealAccountCheckIntervalSecond = 10
+	// DefaultSealAccountStuckTimeoutSecond defines the default time after which a pending tx is regarded as
+	// stuck or dropped if its sequence is not committed.
+	DefaultSealAccountStuckTimeoutSecond = 60
+
+	// SpSealAccountPrivKeys defines env variable name for the comma separated private keys of the seal account pool
+	SpSealAccountPrivKeys = "SIGNER_SEAL_ACCOUNT_PRIV_KEYS"
+)
+
+var expectedSequenceRegexp = regexp.MustCompile(`account sequence mismatch, expected (\d+)`)
+
+// errPoolTxDropped is the error of the tx which is dropped by the pool, its messages are not included.
+var errPoolTxDropped = errors.New("seal account pool tx is dropped")
+
+// poolTxClient is the chain client of an account in the seal account pool.
+type poolTxClient interface {
+	txSimulator
+	BroadcastTx(ctx context.Context, msgs []sdk.Msg, txOpt *ctypes.TxOption, opts ...grpc.CallOption) (*tx.BroadcastTxResponse, error)
+	GetNonce(ctx context.Context) (uint64, error)
+}
+
+// txHandle follows a tx broadcast by the seal account pool. The pool resubmits the stuck tx with a new hash, so
+// the handle holds the hash of the latest submission. It is finished once the sequence of the tx is committed, or
+// with the error if the tx is dropped.
+type txHandle struct {
+	mu     sync.Mutex
+	txHash string
+	err    error
+	done   chan struct{}
+}
+
+func newTxHandle(txHash string) *txHandle {
+	return &txHandle{txHash: txHash, done: make(chan struct{})}
+}
+
+// newFinishedTxHandle returns the handle of a tx which is not followed by the pool.
+func newFinishedTxHandle(txHash string) *txHandle {
+	h := newTxHandle(txHash)
+	h.finish(nil)
+	return h
+}
+
+// TxHash returns the hash of the latest submission of the tx.
+func (h *txHandle) TxHash() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.txHash
+}
+
+// Err returns the error of dropping the tx.
+func (h *txHandle) Err() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.err
+}
+
+// finished returns whether the pool stops following the tx.
+func (h *txHandle) finished() bool {
+	select {
+	case <-h.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (h *txHandle) resubmit(txHash string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.txHash = txHash
+}
+
+// finish stops following the tx, the err is nil if the sequence of the tx is committed.
+func (h *txHandle) finish(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.finished() {
+		return
+	}
+	h.err = err
+	close(h.done)
+}
+
+// pendingTx is a tx accepted by the mempool whose sequence is not committed yet.
+type pendingTx struct {
+	handle  *txHandle
+	msgs    []sdk.Msg
+	gasInfo GasInfo
+	sentAt  time.Time
+}
+
+// poolAccount is an account of the seal account pool, it tracks its own nonce and the pending txs. The routed
+// clients of the account broadcast its txs to the other chain endpoints.
+type poolAccount struct {
+	mu      sync.Mutex
+	name    string
+	addr    sdk.AccAddress
+	client  poolTxClient
+	routed  routedTxClients
+	nonce   uint64
+	pending map[uint64]*pendingTx
+}
+
+// sealAccountPool broadcasts the seal, reject unseal and gvg creation txs by a pool of accounts in round-robin,
+// so the txs are not serialized by the nonce of a single account. The messages are signed by the seal or the
+// operator account, an account of the pool broadcasts them directly if it is the signer, otherwise it wraps
+// them in the authz MsgExec, the signer account must grant the messages to the account by authz.
+//
+// The pool checks the pending txs periodically, the txs whose sequences are not committed within the stuck
+// timeout are regarded as dropped and resubmitted with the right sequences.
+type sealAccountPool struct {
+	accounts         []*poolAccount
+	next             uint64
+	execGasInfo      GasInfo
+	waitForNextBlock func(ctx context.Context) error
+	checkInterval    time.Duration
+	stuckTimeout     time.Duration
+	feeEstimator     *feeEstimator
+	feeBudget        *feeBudget
+	// route returns the endpoint the txs are broadcast to, the txs are broadcast by the clients of the accounts if
+	// it is nil.
+	route broadcastRoute
+
+	stopCh chan struct{}
+	doneCh chan struct{}
+	start  sync.Once
+	stop   sync.Once
+}
+
+func newSealAccountPool(ctx context.Context, clients []poolTxClient, addrs []sdk.AccAddress, execGasInfo GasInfo,
+	waitForNextBlock func(ctx context.Context) error, checkInterval, stuckTimeout time.Duration,
+) (*sealAccountPool, error) {
+	if len(clients) == 0 || len(clients) != len(addrs) {
+		return nil, fmt.Errorf("invalid seal account pool, clients: %d, addresses: %d", len(clients), len(addrs))
+	}
+	if checkInterval <= 0 {
+		checkInterval = DefaultSealAccountCheckIntervalSecond * time.Second
+	}
+	if stuckTimeout <= 0 {
+		stuckTimeout = DefaultSealAccountStuckTimeoutSecond * time.Second
+	}
+	pool := &sealAccountPool{
+		execGasInfo:      execGasInfo,
+		waitForNextBlock: waitForNextBlock,
+		checkInterval:    checkInterval,
+		stuckTimeout:     stuckTimeout,
+		stopCh:           make(chan struct{}),
+		doneCh:           make(chan struct{}),
+	}
+	for i, c := range clients {
+		nonce, err := c.GetNonce(ctx)
+		if err != nil {
+			log.Errorw("failed to get seal account pool nonce", "address", addrs[i].String(), "error", err)
+			return nil, err
+		}
+		pool.accounts = append(pool.accounts, &poolAccount{
+			name:    fmt.Sprintf(sealAccountKeyID, i),
+			addr:    addrs[i],
+			client:  c,
+			nonce:   nonce,
+			pending: make(map[uint64]*pendingTx),
+		})
+	}
+	return pool, nil
+}
+
+// newSealAccountPoolClients returns the chain clients and the addresses of the signers of the seal account pool.
+func newSealAccountPoolClients(rpcAddr, evmRpcAddr, chainID string, signers []keysigner.Signer) ([]poolTxClient, []sdk.AccAddress, error) {
+	clients := make([]poolTxClient, 0, len(signers))
+	addrs := make([]sdk.AccAddress, 0, len(signers))
+	for _, signer := range signers {
+		km := newBackendKeyManager(signer)
+		c, err := client.NewMechainClient(rpcAddr, evmRpcAddr, chainID, client.WithKeyManager(km))
+		if err != nil {
+			log.Errorw("failed to new seal account pool mechain client", "error", err)
+			return nil, nil, err
+		}
+		clients = append(clients, c)
+		addrs = append(addrs, km.GetAddr())
+	}
+	return clients, addrs, nil
+}
+
+// Start starts the background loop of checking the pending txs.
+func (p *sealAccountPool) Start() {
+	p.start.Do(func() { go p.loop() })
+}
+
+// Stop stops checking the pending txs.
+func (p *sealAccountPool) Stop() {
+	p.stop.Do(func() {
+		close(p.stopCh)
+		p.start.Do(func() { close(p.doneCh) })
+		<-p.doneCh
+	})
+}
+
+// acquire returns a locked account, it prefers the idle accounts from the round-robin position.
+func (p *sealAccountPool) acquire() *poolAccount {
+	start := int(atomic.AddUint64(&p.next, 1) % uint64(len(p.accounts)))
+	for i := 0; i < len(p.accounts); i++ {
+		acc := p.accounts[(start+i)%len(p.accounts)]
+		if acc.mu.TryLock() {
+			return acc
+		}
+	}
+	acc := p.accounts[start]
+	acc.mu.Lock()
+	return acc
+}
+
+// broadcastMsg broadcasts a message by the pool and sets the on chain error of the message type on failure.
+func (p *sealAccountPool) broadcastMsg(ctx context.Context, msg sdk.Msg, gasInfo GasInfo, onChainErr *gfsperrors.GfSpError) (string, error) {
+	handle, err := p.broadcast(ctx, []sdk.Msg{msg}, gasInfo)
+	if err != nil {
+		onChainErr.SetError(fmt.Errorf("failed to broadcast tx by seal account pool, error: %v", err))
+		return "", onChainErr
+	}
+	log.CtxDebugw(ctx, "succeed to broadcast tx by seal account pool", "tx_hash", handle.TxHash(), "msg", msg)
+	return handle.TxHash(), nil
+}
+
+// broadcast broadcasts the messages which have the same signer as one tx by an account of the pool, the returned
+// handle follows the resubmissions of the tx.
+func (p *sealAccountPool) broadcast(ctx context.Context, msgs []sdk.Msg, gasInfo GasInfo) (*txHandle, error) {
+	acc := p.acquire()
+	defer acc.mu.Unlock()
+
+	txMsgs := msgs
+	if signers := msgs[0].GetSigners(); len(signers) == 0 || !signers[0].Equals(acc.addr) {
+		exec := authz.NewMsgExec(acc.addr, msgs)
+		txMsgs = []sdk.Msg{&exec}
+		gasInfo = GasInfo{
+			GasLimit:  gasInfo.GasLimit + p.execGasInfo.GasLimit,
+			FeeAmount: gasInfo.FeeAmount.Add(p.execGasInfo.FeeAmount...),
+		}
+	}
+
+	var err error
+	for i := 0; i < BroadcastTxRetry; i++ {
+		nonce := acc.nonce
+		var txHash string
+		txHash, err = p.broadcastTx(ctx, acc, txMsgs, gasInfo, nonce)
+		if err == nil {
+			handle := newTxHandle(txHash)
+			acc.pending[nonce] = &pendingTx{handle: handle, msgs: txMsgs, gasInfo: gasInfo, sentAt: time.Now()}
+			acc.nonce = nonce + 1
+			return handle, nil
+		}
+		log.CtxErrorw(ctx, "failed to broadcast tx by seal account pool", "address", acc.addr.String(),
+			"nonce", nonce, "retry_number", i, "error", err)
+		if expected, ok := expectedSequence(err); ok {
+			p.resetNonce(acc, expected)
+			continue
+		}
+		if strings.Contains(err.Error(), sdkErrors.ErrWrongSequence.Error()) {
+			// the expected sequence is unknown, wait for next block, reset nonce by querying the nonce on chain
+			if waitErr := p.waitForNextBlock(ctx); waitErr != nil {
+				return nil, waitErr
+			}
+			onChain, nonceErr := acc.client.GetNonce(ctx)
+			if nonceErr != nil {
+				return nil, fmt.Errorf("failed to get seal account pool nonce, error: %v", nonceErr)
+			}
+			p.resetNonce(acc, onChain)
+		}
+	}
+	return nil, err
+}
+
+// broadcastTx broadcasts the tx with the sequence, the raw log is kept in the error to parse the expected sequence.
+func (p *sealAccountPool) broadcastTx(ctx context.Context, acc *poolAccount, msgs []sdk.Msg, gasInfo GasInfo, nonce uint64) (string, error) {
+	mode := tx.BroadcastMode_BROADCAST_MODE_SYNC
+	txOpt := &ctypes.TxOption{
+		NoSimulate: false,
+		Mode:       &mode,
+		GasLimit:   gasInfo.GasLimit,
+		FeeAmount:  gasInfo.FeeAmount,
+		Nonce:      nonce,
+	}
+	if err := applyFee(ctx, p.feeEstimator, p.feeBudget, acc.client, acc.name, msgs, txOpt); err != nil {
+		return "", err
+	}
+	resp, err := acc.routed.pick(p.route, acc.client).BroadcastTx(ctx, msgs, txOpt)
+	if err == nil && resp.TxResponse.Code != 0 {
+		err = fmt.Errorf("failed to broadcast tx, resp code: %d, code space: %s, raw log: %s",
+			resp.TxResponse.Code, resp.TxResponse.Codespace, resp.TxResponse.RawLog)
+	}
+	if err != nil {
+		if p.feeBudget != nil {
+			p.feeBudget.refund(acc.name, txOpt.FeeAmount)
+		}
+		return "", err
+	}
+	return resp.TxResponse.TxHash, nil
+}
+
+// resetNonce resets the nonce of the account, the pending txs from the nonce are dropped from the mempool and
+// replaced by the later txs.
+func (p *sealAccountPool) resetNonce(acc *poolAccount, nonce uint64) {
+	for n, ptx := range acc.pending {
+		if n >= nonce {
+			ptx.handle.finish(fmt.Errorf("%w, tx_hash: %s, sequence: %d", errPoolTxDropped, ptx.handle.TxHash(), n))
+			delete(acc.pending, n)
+		}
+	}
+	acc.nonce = nonce
+}
+
+func (p *sealAccountPool) loop() {
+	defer close(p.doneCh)
+	ticker := time.NewTicker(p.checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stopCh:
+			return
+		case <-ticker.C:
+			for _, acc := range p.accounts {
+				p.check(context.Background(), acc, time.Now())
+			}
+		}
+	}
+}
+
+// check releases the committed txs of the account and resubmits the txs if the lowest pending one is stuck.
+func (p *sealAccountPool) check(ctx context.Context, acc *poolAccount, now time.Time) {
+	acc.mu.Lock()
+	defer acc.mu.Unlock()
+	onChain, err := acc.client.GetNonce(ctx)
+	if err != nil {
+		log.Errorw("failed to get seal account pool nonce", "address", acc.addr.String(), "error", err)
+		return
+	}
+	for n, ptx := range acc.pending {
+		if n < onChain {
+			ptx.handle.finish(nil)
+			delete(acc.pending, n)
+		}
+	}
+	if len(acc.pending) == 0 {
+		if acc.nonce != onChain {
+			log.Warnw("reset seal account pool nonce", "address", acc.addr.String(), "nonce", acc.nonce, "on_chain_nonce", onChain)
+			acc.nonce = onChain
+		}
+		return
+	}
+	lowest, ok := acc.pending[onChain]
+	if ok && now.Sub(lowest.sentAt) < p.stuckTimeout {
+		return
+	}
+	p.resubmit(ctx, acc, onChain, now)
+}
+
+// resubmit resubmits the pending txs in order from the on chain sequence, the txs which fail are dropped and the
+// later txs take their sequences.
+func (p *sealAccountPool) resubmit(ctx context.Context, acc *poolAccount, onChain uint64, now time.Time) {
+	nonces := make([]uint64, 0, len(acc.pending))
+	for n := range acc.pending {
+		nonces = append(nonces, n)
+	}
+	sort.Slice(nonces, func(i, j int) bool { return nonces[i] < nonces[j] })
+
+	pending := make(map[uint64]*pendingTx, len(nonces))
+	sequence := onChain
+	for _, n := range nonces {
+		ptx := acc.pending[n]
+		txHash, err := p.broadcastTx(ctx, acc, ptx.msgs, ptx.gasInfo, sequence)
+		if err != nil && n == sequence && strings.Contains(err.Error(), "tx already exists in cache") {
+			// the same tx is still in the mempool
+			txHash, err = ptx.handle.TxHash(), nil
+		}
+		if err != nil {
+			log.Errorw("failed to resubmit stuck tx, drop it", "address", acc.addr.String(),
+				"tx_hash", ptx.handle.TxHash(), "sequence", sequence, "error", err)
+			ptx.handle.finish(fmt.Errorf("%w, tx_hash: %s, error: %v", errPoolTxDropped, ptx.handle.TxHash(), err))
+			continue
+		}
+		log.Warnw("resubmit stuck tx", "address", acc.addr.String(), "tx_hash", ptx.handle.TxHash(),
+			"new_tx_hash", txHash, "nonce", n, "sequence", sequence)
+		ptx.handle.resubmit(txHash)
+		pending[sequence] = &pendingTx{handle: ptx.handle, msgs: ptx.msgs, gasInfo: ptx.gasInfo, sentAt: now}
+		sequence++
+	}
+	acc.pending = pending
+	acc.nonce = sequence
+}
+
+// expectedSequence parses the expected sequence from the error of the sequence mismatch.
+func expectedSequence(err error) (uint64, bool) {
+	matches := expectedSequenceRegexp.FindStringSubmatch(err.Error())
+	if len(matches) != 2 {
+		return 0, false
+	}
+	sequence, parseErr := strconv.ParseUint(matches[1], 10, 64)
+	if parseErr != nil {
+		return 0, false
+	}
+	return sequence, true
+}
@@ -0,0 +1,204 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	ctypes "github.com/evmos/evmos/v12/sdk/types"
+	storagetypes "github.com/evmos/evmos/v12/x/storage/types"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+type mockPoolTxClient struct {
+	mu        sync.Mutex
+	sequence  uint64
+	committed uint64
+	txs       map[uint64][]sdk.Msg
+	reject    func(msgs []sdk.Msg) error
+	// hashPrefix tells the resubmitted txs from the txs of the same sequence
+	hashPrefix string
+}
+
+func newMockPoolTxClient(sequence uint64) *mockPoolTxClient {
+	return &mockPoolTxClient{sequence: sequence, committed: sequence, txs: make(map[uint64][]sdk.Msg)}
+}
+
+func (m *mockPoolTxClient) BroadcastTx(ctx context.Context, msgs []sdk.Msg, txOpt *ctypes.TxOption,
+	opts ...grpc.CallOption,
+) (*tx.BroadcastTxResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if txOpt.Nonce != m.sequence {
+		return &tx.BroadcastTxResponse{TxResponse: &sdk.TxResponse{Code: 32, Codespace: "sdk",
+			RawLog: fmt.Sprintf("account sequence mismatch, expected %d, got %d: incorrect account sequence", m.sequence, txOpt.Nonce)}}, nil
+	}
+	if m.reject != nil {
+		if err := m.reject(msgs); err != nil {
+			return nil, err
+		}
+	}
+	m.txs[txOpt.Nonce] = msgs
+	m.sequence++
+	return &tx.BroadcastTxResponse{TxResponse: &sdk.TxResponse{TxHash: fmt.Sprintf("%stx-%d", m.hashPrefix, txOpt.Nonce)}}, nil
+}
+
+func (m *mockPoolTxClient) SimulateTx(ctx context.Context, msgs []sdk.Msg, txOpt *ctypes.TxOption,
+	opts ...grpc.CallOption,
+) (*tx.SimulateResponse, error) {
+	return &tx.SimulateResponse{GasInfo: &sdk.GasInfo{GasUsed: 1000}}, nil
+}
+
+func (m *mockPoolTxClient) GetNonce(ctx context.Context) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.committed, nil
+}
+
+// drop drops the txs in the mempool which are not committed.
+func (m *mockPoolTxClient) drop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sequence = m.committed
+}
+
+func newMockSealAccountPool(t *testing.T, clients ...*mockPoolTxClient) *sealAccountPool {
+	poolClients := make([]poolTxClient, 0, len(clients))
+	addrs := make([]sdk.AccAddress, 0, len(clients))
+	for i, c := range clients {
+		poolClients = append(poolClients, c)
+		addrs = append(addrs, sdk.AccAddress(fmt.Sprintf("mock-pool-address-%d", i)))
+	}
+	pool, err := newSealAccountPool(context.Background(), poolClients, addrs, GasInfo{GasLimit: DefaultAuthzExecGasLimit},
+		func(ctx context.Context) error { return nil }, time.Hour, time.Minute)
+	assert.Nil(t, err)
+	return pool
+}
+
+func TestNewSealAccountPool(t *testing.T) {
+	_, err := newSealAccountPool(context.Background(), nil, nil, GasInfo{}, nil, 0, 0)
+	assert.NotNil(t, err)
+}
+
+func TestSealAccountPool_Broadcast(t *testing.T) {
+	c0, c1 := newMockPoolTxClient(5), newMockPoolTxClient(0)
+	pool := newMockSealAccountPool(t, c0, c1)
+	seal := storagetypes.NewMsgSealObject(pool.accounts[0].addr, "bucket", "object", 1, nil)
+
+	for i := 0; i < 4; i++ {
+		_, err := pool.broadcast(context.Background(), []sdk.Msg{seal}, GasInfo{GasLimit: DefaultSealGasLimit})
+		assert.Nil(t, err)
+	}
+	assert.Len(t, c0.txs, 2)
+	assert.Len(t, c1.txs, 2)
+	assert.Equal(t, uint64(7), pool.accounts[0].nonce)
+	assert.Equal(t, uint64(2), pool.accounts[1].nonce)
+	assert.Len(t, pool.accounts[0].pending, 2)
+	// the account which is not the signer wraps the messages in the MsgExec
+	assert.Equal(t, seal, c0.txs[5][0])
+	_, ok := c1.txs[0][0].(*authz.MsgExec)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(DefaultSealGasLimit+DefaultAuthzExecGasLimit), pool.accounts[1].pending[0].gasInfo.GasLimit)
+}
+
+func TestSealAccountPool_BroadcastSequenceMismatch(t *testing.T) {
+	c0 := newMockPoolTxClient(0)
+	pool := newMockSealAccountPool(t, c0)
+	c0.sequence = 3
+	seal := storagetypes.NewMsgSealObject(pool.accounts[0].addr, "bucket", "object", 1, nil)
+
+	txHash, err := pool.broadcastMsg(context.Background(), seal, GasInfo{GasLimit: DefaultSealGasLimit}, ErrSealObjectOnChain)
+	assert.Nil(t, err)
+	assert.Equal(t, "tx-3", txHash)
+	assert.Equal(t, uint64(4), pool.accounts[0].nonce)
+
+	c0.reject = func(msgs []sdk.Msg) error { return errors.New("mock invalid object") }
+	_, err = pool.broadcastMsg(context.Background(), seal, GasInfo{GasLimit: DefaultSealGasLimit}, ErrSealObjectOnChain)
+	assert.Equal(t, ErrSealObjectOnChain, err)
+	assert.Equal(t, uint64(4), pool.accounts[0].nonce)
+}
+
+func TestSealAccountPool_Check(t *testing.T) {
+	c0 := newMockPoolTxClient(0)
+	pool := newMockSealAccountPool(t, c0)
+	acc := pool.accounts[0]
+	var handles []*txHandle
+	for _, object := range []string{"a", "b", "c"} {
+		seal := storagetypes.NewMsgSealObject(acc.addr, "bucket", object, 1, nil)
+		handle, err := pool.broadcast(context.Background(), []sdk.Msg{seal}, GasInfo{GasLimit: DefaultSealGasLimit})
+		assert.Nil(t, err)
+		handles = append(handles, handle)
+	}
+
+	// the committed txs are released
+	c0.committed = 1
+	now := time.Now()
+	pool.check(context.Background(), acc, now)
+	assert.Len(t, acc.pending, 2)
+	assert.Equal(t, uint64(3), acc.nonce)
+	assert.True(t, handles[0].finished())
+	assert.Nil(t, handles[0].Err())
+	assert.False(t, handles[1].finished())
+
+	// the dropped txs are resubmitted with the sequences from the on chain nonce after the stuck timeout, the
+	// handles follow the new hashes
+	c0.drop()
+	c0.hashPrefix = "resubmitted-"
+	pool.check(context.Background(), acc, now)
+	assert.Len(t, c0.txs, 3)
+	assert.Equal(t, "tx-1", handles[1].TxHash())
+	pool.check(context.Background(), acc, now.Add(2*time.Minute))
+	assert.Equal(t, uint64(3), c0.sequence)
+	assert.Equal(t, uint64(3), acc.nonce)
+	assert.Equal(t, "b", c0.txs[1][0].(*storagetypes.MsgSealObject).GetObjectName())
+	assert.Equal(t, "resubmitted-tx-1", handles[1].TxHash())
+	assert.False(t, handles[1].finished())
+
+	// the tx which can not be resubmitted is dropped, the later tx takes its sequence
+	c0.drop()
+	c0.reject = func(msgs []sdk.Msg) error {
+		if msgs[0].(*storagetypes.MsgSealObject).GetObjectName() == "b" {
+			return errors.New("mock invalid object")
+		}
+		return nil
+	}
+	pool.check(context.Background(), acc, now.Add(4*time.Minute))
+	assert.Len(t, acc.pending, 1)
+	assert.Equal(t, uint64(2), acc.nonce)
+	assert.Equal(t, "c", c0.txs[1][0].(*storagetypes.MsgSealObject).GetObjectName())
+	assert.ErrorIs(t, handles[1].Err(), errPoolTxDropped)
+	assert.Equal(t, "resubmitted-tx-1", handles[2].TxHash())
+
+	// the nonce is synchronized with the chain if there is no pending tx
+	c0.committed, c0.sequence = 5, 5
+	pool.check(context.Background(), acc, now.Add(6*time.Minute))
+	assert.Empty(t, acc.pending)
+	assert.Equal(t, uint64(5), acc.nonce)
+}
+
+func TestSealAccountPool_BroadcastWithFeeBudget(t *testing.T) {
+	c0 := newMockPoolTxClient(0)
+	pool := newMockSealAccountPool(t, c0)
+	acc := pool.accounts[0]
+	assert.Equal(t, "seal-account-0", acc.name)
+	pool.feeEstimator = newFeeEstimator(1.2, time.Minute)
+	pool.feeBudget = newFeeBudget(map[string]uint64{acc.name: 2000}, 0)
+	gasInfo := GasInfo{GasLimit: 1000, FeeAmount: sdk.NewCoins(sdk.NewCoin(ctypes.Denom, sdk.NewInt(1000)))}
+	seal := storagetypes.NewMsgSealObject(acc.addr, "bucket", "object", 1, nil)
+
+	_, err := pool.broadcast(context.Background(), []sdk.Msg{seal}, gasInfo)
+	assert.Nil(t, err)
+	assert.Equal(t, "1200", pool.feeBudget.spent[acc.name].String())
+
+	_, err = pool.broadcast(context.Background(), []sdk.Msg{seal}, gasInfo)
+	assert.NotNil(t, err)
+	assert.Len(t, c0.txs, 1)
+	assert.Equal(t, uint64(1), acc.nonce)
+}
@@ -0,0 +1,452 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	sdkerrors "cosmossdk.io/errors"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkErrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	ctypes "github.com/evmos/evmos/v12/sdk/types"
+	storagetypes "github.com/evmos/evmos/v12/x/storage/types"
+
+	"github.com/zkMeLabs/mechain-storage-provider/base/types/gfsperrors"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+)
+
+const (
+	// DefaultSealBatchWindowMillisecond defines the default window for accumulating the seal requests.
+	DefaultSealBatchWindowMillisecond = 200
+	// DefaultSealBatchMaxMsgs defines the default max number of the messages in one batch tx.
+	DefaultSealBatchMaxMsgs = 50
+	// DefaultSealBatchMaxGasLimit defines the default max gas limit of one batch tx, which fits a full batch of
+	// the seal messages. It is derived from the configured seal gas limit if that is set.
+	DefaultSealBatchMaxGasLimit = DefaultSealGasLimit * DefaultSealBatchMaxMsgs
+)
+
+// ErrSealBatcherStopped is returned to the requests which are not broadcast before the batcher stops.
+var ErrSealBatcherStopped = errors.New("seal batcher has been stopped")
+
+// sealBroadcaster broadcasts the messages as one tx with the gas info and returns the handle of the tx.
+type sealBroadcaster func(ctx context.Context, msgs []sdk.Msg, gasInfo GasInfo) (*txHandle, error)
+
+// txConfirmer waits for the tx to be included in a block and returns its result.
+type txConfirmer func(ctx context.Context, txHash string) (*sdk.TxResponse, error)
+
+// errSealTxUnconfirmed is returned if the inclusion of the broadcast tx is unknown, the tx may still be included.
+var errSealTxUnconfirmed = errors.New("seal batch tx is not confirmed")
+
+const (
+	// sealTxFollowTimeout defines the max time of following the resubmissions of a seal tx by the seal account pool.
+	sealTxFollowTimeout = 5 * time.Minute
+	// sealTxFollowInterval defines the interval of confirming the followed seal tx again.
+	sealTxFollowInterval = time.Second
+)
+
+// sealObjectMsg is implemented by the seal, seal v2 and reject unseal messages.
+type sealObjectMsg interface {
+	GetBucketName() string
+	GetObjectName() string
+}
+
+// sealResult is the result of a seal request fanned back to the caller.
+type sealResult struct {
+	txHash string
+	err    error
+}
+
+// sealRequest is a seal, seal v2 or reject unseal message waiting for the batch broadcast.
+type sealRequest struct {
+	ctx     context.Context
+	msg     sdk.Msg
+	gasInfo GasInfo
+	result  chan sealResult
+}
+
+// sealBatcher accumulates the cosmos messages of the seal account for a short window and broadcasts them as a
+// single multi-message tx, so the seal throughput is not capped by one tx per nonce round-trip. The messages of
+// a tx are executed atomically, the batch tx is confirmed by its inclusion and result code, and if it fails,
+// every message is retried in its own tx so that one invalid message does not fail the other callers. The evm
+// txs are not batched, an evm tx only carries one precompile call.
+type sealBatcher struct {
+	broadcast sealBroadcaster
+	confirm   txConfirmer
+	addr      func() (sdk.AccAddress, error)
+	gasInfo   map[GasInfoType]GasInfo
+	window    time.Duration
+	maxMsgs   int
+	maxGas    uint64
+
+	requests chan *sealRequest
+	flushing sync.WaitGroup
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+	start    sync.Once
+	stop     sync.Once
+}
+
+func newSealBatcher(broadcast sealBroadcaster, confirm txConfirmer, addr func() (sdk.AccAddress, error),
+	gasInfo map[GasInfoType]GasInfo, window time.Duration, maxMsgs int, maxGas uint64,
+) *sealBatcher {
+	if window <= 0 {
+		window = DefaultSealBatchWindowMillisecond * time.Millisecond
+	}
+	if maxMsgs <= 0 {
+		maxMsgs = DefaultSealBatchMaxMsgs
+	}
+	if maxGas == 0 {
+		maxGas = gasInfo[Seal].GasLimit * uint64(maxMsgs)
+	}
+	if maxGas == 0 {
+		maxGas = DefaultSealBatchMaxGasLimit
+	}
+	return &sealBatcher{
+		broadcast: broadcast,
+		confirm:   confirm,
+		addr:      addr,
+		gasInfo:   gasInfo,
+		window:    window,
+		maxMsgs:   maxMsgs,
+		maxGas:    maxGas,
+		requests:  make(chan *sealRequest, maxMsgs),
+		stopCh:    make(chan struct{}),
+		doneCh:    make(chan struct{}),
+	}
+}
+
+// Start starts the background loop of accumulating and broadcasting the batches.
+func (b *sealBatcher) Start() {
+	b.start.Do(func() { go b.loop() })
+}
+
+// Stop broadcasts the accumulated requests and stops the batcher, the later requests are rejected.
+func (b *sealBatcher) Stop() {
+	b.stop.Do(func() {
+		close(b.stopCh)
+		b.start.Do(func() { close(b.doneCh) })
+		<-b.doneCh
+	})
+}
+
+// SealObject submits the MsgSealObject to the batch and waits for the tx hash.
+func (b *sealBatcher) SealObject(ctx context.Context, sealObject *storagetypes.MsgSealObject) (string, error) {
+	if sealObject == nil {
+		log.CtxError(ctx, "failed to seal object due to pointer dangling")
+		return "", ErrDanglingPointer
+	}
+	ctx = log.WithValue(ctx, log.CtxKeyBucketName, sealObject.GetBucketName())
+	ctx = log.WithValue(ctx, log.CtxKeyObjectName, sealObject.GetObjectName())
+	addr, err := b.addr()
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to get private key", "error", err)
+		return "", ErrSignMsg
+	}
+	msg := storagetypes.NewMsgSealObject(addr, sealObject.GetBucketName(), sealObject.GetObjectName(),
+		sealObject.GetGlobalVirtualGroupId(), sealObject.GetSecondarySpBlsAggSignatures())
+	return b.submit(ctx, msg, b.gasInfo[Seal], ErrSealObjectOnChain)
+}
+
+// SealObjectV2 submits the MsgSealObjectV2 to the batch and waits for the tx hash.
+func (b *sealBatcher) SealObjectV2(ctx context.Context, sealObject *storagetypes.MsgSealObjectV2) (string, error) {
+	if sealObject == nil {
+		log.CtxError(ctx, "failed to seal object due to pointer dangling")
+		return "", ErrDanglingPointer
+	}
+	ctx = log.WithValue(ctx, log.CtxKeyBucketName, sealObject.GetBucketName())
+	ctx = log.WithValue(ctx, log.CtxKeyObjectName, sealObject.GetObjectName())
+	addr, err := b.addr()
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to get private key", "error", err)
+		return "", ErrSignMsg
+	}
+	msg := storagetypes.NewMsgSealObjectV2(addr, sealObject.GetBucketName(), sealObject.GetObjectName(),
+		sealObject.GetGlobalVirtualGroupId(), sealObject.GetSecondarySpBlsAggSignatures(), sealObject.GetExpectChecksums())
+	return b.submit(ctx, msg, b.gasInfo[Seal], ErrSealObjectOnChain)
+}
+
+// RejectUnSealObject submits the MsgRejectSealObject to the batch and waits for the tx hash.
+func (b *sealBatcher) RejectUnSealObject(ctx context.Context, rejectObject *storagetypes.MsgRejectSealObject) (string, error) {
+	if rejectObject == nil {
+		log.CtxError(ctx, "failed to reject unseal object due to pointer dangling")
+		return "", ErrDanglingPointer
+	}
+	ctx = log.WithValue(ctx, log.CtxKeyBucketName, rejectObject.GetBucketName())
+	ctx = log.WithValue(ctx, log.CtxKeyObjectName, rejectObject.GetObjectName())
+	addr, err := b.addr()
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to get private key", "error", err)
+		return "", ErrSignMsg
+	}
+	msg := storagetypes.NewMsgRejectUnsealedObject(addr, rejectObject.GetBucketName(), rejectObject.GetObjectName())
+	return b.submit(ctx, msg, b.gasInfo[RejectSeal], ErrRejectUnSealObjectOnChain)
+}
+
+// submit queues the message and waits for its result. The message may still be broadcast if the context is
+// canceled after it has been queued.
+func (b *sealBatcher) submit(ctx context.Context, msg sdk.Msg, gasInfo GasInfo, onChainErr *gfsperrors.GfSpError) (string, error) {
+	req := &sealRequest{ctx: ctx, msg: msg, gasInfo: gasInfo, result: make(chan sealResult, 1)}
+	select {
+	case <-b.stopCh:
+		return "", ErrSealBatcherStopped
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case b.requests <- req:
+	}
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case res := <-req.result:
+		return res.unwrap(onChainErr)
+	case <-b.doneCh:
+		// the request may be queued after the batcher drains the queue
+		select {
+		case res := <-req.result:
+			return res.unwrap(onChainErr)
+		default:
+			return "", ErrSealBatcherStopped
+		}
+	}
+}
+
+func (r sealResult) unwrap(onChainErr *gfsperrors.GfSpError) (string, error) {
+	if r.err != nil {
+		onChainErr.SetError(fmt.Errorf("failed to broadcast seal batch tx, error: %v", r.err))
+		return "", onChainErr
+	}
+	return r.txHash, nil
+}
+
+func (b *sealBatcher) loop() {
+	defer close(b.doneCh)
+	defer b.flushing.Wait()
+	var pending *sealRequest
+	for {
+		first := pending
+		pending = nil
+		if first == nil {
+			select {
+			case <-b.stopCh:
+				b.drain()
+				return
+			case first = <-b.requests:
+			}
+		}
+		batch := []*sealRequest{first}
+		gas := first.gasInfo.GasLimit
+		timer := time.NewTimer(b.window)
+		stopped := false
+	collect:
+		for len(batch) < b.maxMsgs {
+			select {
+			case <-b.stopCh:
+				stopped = true
+				break collect
+			case <-timer.C:
+				break collect
+			case req := <-b.requests:
+				if gas+req.gasInfo.GasLimit > b.maxGas {
+					pending = req
+					break collect
+				}
+				batch = append(batch, req)
+				gas += req.gasInfo.GasLimit
+			}
+		}
+		timer.Stop()
+		// the batches are broadcast concurrently, they are serialized by the seal account nonce unless the seal
+		// account pool is enabled
+		b.flushing.Add(1)
+		go func(batch []*sealRequest) {
+			defer b.flushing.Done()
+			b.flush(batch)
+		}(batch)
+		if stopped {
+			if pending != nil {
+				b.flush([]*sealRequest{pending})
+			}
+			b.drain()
+			return
+		}
+	}
+}
+
+// drain broadcasts the requests queued before the batcher stops.
+func (b *sealBatcher) drain() {
+	var (
+		batch []*sealRequest
+		gas   uint64
+	)
+	for {
+		select {
+		case req := <-b.requests:
+			if len(batch) == b.maxMsgs || (len(batch) != 0 && gas+req.gasInfo.GasLimit > b.maxGas) {
+				b.flush(batch)
+				batch, gas = nil, 0
+			}
+			batch = append(batch, req)
+			gas += req.gasInfo.GasLimit
+		default:
+			if len(batch) != 0 {
+				b.flush(batch)
+			}
+			return
+		}
+	}
+}
+
+// flush broadcasts the batch as one tx and fans the result back to the callers, if the tx fails, the messages
+// are broadcast one by one so that the valid messages are not failed by the invalid one.
+func (b *sealBatcher) flush(batch []*sealRequest) {
+	// the requests of the same object, e.g. a seal retried before the previous one returns, share one message,
+	// the duplicate message in the same tx would fail the whole batch
+	var (
+		unique     = make([]*sealRequest, 0, len(batch))
+		first      = make(map[string]*sealRequest, len(batch))
+		duplicates = make(map[*sealRequest][]*sealRequest)
+	)
+	for _, req := range batch {
+		key := sealMsgKey(req.msg)
+		if f, ok := first[key]; ok {
+			duplicates[f] = append(duplicates[f], req)
+			continue
+		}
+		first[key] = req
+		unique = append(unique, req)
+	}
+	reply := func(req *sealRequest, res sealResult) {
+		req.result <- res
+		for _, dup := range duplicates[req] {
+			dup.result <- res
+		}
+	}
+
+	msgs := make([]sdk.Msg, 0, len(unique))
+	gasInfo := GasInfo{}
+	for _, req := range unique {
+		msgs = append(msgs, req.msg)
+		gasInfo.GasLimit += req.gasInfo.GasLimit
+		gasInfo.FeeAmount = gasInfo.FeeAmount.Add(req.gasInfo.FeeAmount...)
+	}
+	// the batch is shared by the callers, so it is not canceled by the context of any caller
+	txHash, err := b.broadcastAndConfirm(context.Background(), msgs, gasInfo)
+	if err == nil || len(unique) == 1 || errors.Is(err, errSealTxUnconfirmed) {
+		// the unconfirmed tx may still be included, the messages are not resubmitted to fail as duplicates
+		if err == nil {
+			log.Debugw("succeed to seal batch tx", "tx_hash", txHash, "msg_number", len(unique))
+		}
+		for _, req := range unique {
+			reply(req, sealResult{txHash: txHash, err: err})
+		}
+		return
+	}
+	log.Errorw("failed to seal batch tx, retry the messages one by one", "msg_number", len(unique), "error", err)
+	for _, req := range unique {
+		txHash, err = b.broadcastAndConfirm(req.ctx, []sdk.Msg{req.msg}, req.gasInfo)
+		reply(req, sealResult{txHash: txHash, err: err})
+	}
+}
+
+// broadcastAndConfirm broadcasts the messages as one tx and waits for the tx to be included, the included tx
+// with a non-zero code is failed.
+func (b *sealBatcher) broadcastAndConfirm(ctx context.Context, msgs []sdk.Msg, gasInfo GasInfo) (string, error) {
+	handle, err := b.broadcast(ctx, msgs, gasInfo)
+	if err != nil {
+		return "", err
+	}
+	txHash, resp, err := b.confirmTx(ctx, handle)
+	if err != nil {
+		if dropErr := handle.Err(); dropErr != nil {
+			return "", dropErr
+		}
+		return "", fmt.Errorf("%w, tx_hash: %s, error: %v", errSealTxUnconfirmed, txHash, err)
+	}
+	if resp.Code != 0 {
+		return "", fmt.Errorf("seal tx failed, tx_hash: %s, code: %d, code space: %s, raw log: %s",
+			txHash, resp.Code, resp.Codespace, resp.RawLog)
+	}
+	return txHash, nil
+}
+
+// confirmTx waits for the tx of the handle to be included. The seal account pool resubmits the stuck tx with a
+// new hash, so the latest hash is confirmed until the pool stops following the tx, at most sealTxFollowTimeout.
+func (b *sealBatcher) confirmTx(ctx context.Context, handle *txHandle) (string, *sdk.TxResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, sealTxFollowTimeout)
+	defer cancel()
+	for {
+		finished := handle.finished()
+		txHash := handle.TxHash()
+		resp, err := b.confirm(ctx, txHash)
+		if err == nil {
+			return txHash, resp, nil
+		}
+		if finished && handle.TxHash() == txHash {
+			return txHash, nil, err
+		}
+		log.Debugw("confirm the seal tx followed by the seal account pool again", "tx_hash", txHash,
+			"latest_tx_hash", handle.TxHash(), "error", err)
+		select {
+		case <-ctx.Done():
+			return txHash, nil, err
+		case <-handle.done:
+		case <-time.After(sealTxFollowInterval):
+		}
+	}
+}
+
+// sealMsgKey returns the key of the object the message belongs to.
+func sealMsgKey(msg sdk.Msg) string {
+	m, ok := msg.(sealObjectMsg)
+	if !ok {
+		return sdk.MsgTypeURL(msg)
+	}
+	return sdk.MsgTypeURL(msg) + "/" + m.GetBucketName() + "/" + m.GetObjectName()
+}
+
+// broadcastSealTx broadcasts the messages of the seal account as one tx, it shares the seal account nonce with
+// the other seal methods, or broadcasts by the seal account pool if it is enabled.
+func (client *MechainChainSignClient) broadcastSealTx(ctx context.Context, msgs []sdk.Msg, gasInfo GasInfo) (*txHandle, error) {
+	if client.sealPool != nil {
+		return client.sealPool.broadcast(ctx, msgs, gasInfo)
+	}
+	client.sealLock.Lock()
+	defer client.sealLock.Unlock()
+
+	mode := tx.BroadcastMode_BROADCAST_MODE_SYNC
+	var (
+		txHash   string
+		nonce    uint64
+		err      error
+		nonceErr error
+	)
+	for i := 0; i < BroadcastTxRetry; i++ {
+		nonce = client.sealAccNonce
+		txOpt := &ctypes.TxOption{
+			NoSimulate: false,
+			Mode:       &mode,
+			GasLimit:   gasInfo.GasLimit,
+			FeeAmount:  gasInfo.FeeAmount,
+			Nonce:      nonce,
+		}
+		txHash, err = client.broadcastTx(ctx, client.mechainClients[SignSeal], msgs, txOpt)
+		if sdkerrors.IsOf(err, sdkErrors.ErrWrongSequence) {
+			// if nonce mismatch, wait for next block, reset nonce by querying the nonce on chain
+			nonce, nonceErr = client.getNonceOnChain(ctx, client.mechainClients[SignSeal])
+			if nonceErr != nil {
+				log.CtxErrorw(ctx, "failed to get seal account nonce", "error", nonceErr)
+				return nil, fmt.Errorf("failed to get seal account nonce, error: %v", nonceErr)
+			}
+			client.sealAccNonce = nonce
+		}
+		if err != nil {
+			log.CtxErrorw(ctx, "failed to broadcast seal batch tx", "retry_number", i, "msg_number", len(msgs), "error", err)
+			continue
+		}
+		client.sealAccNonce = nonce + 1
+		return newFinishedTxHandle(txHash), nil
+	}
+	return nil, err
+}
@@ -0,0 +1,283 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	storagetypes "github.com/evmos/evmos/v12/x/storage/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var mockSealAddr = sdk.AccAddress("mock-seal-address")
+
+type mockSealBroadcaster struct {
+	mu       sync.Mutex
+	txs      [][]sdk.Msg
+	gasInfos []GasInfo
+	fail     func(msgs []sdk.Msg) error
+	// failCode returns the result code of the included tx
+	failCode    func(msgs []sdk.Msg) uint32
+	unconfirmed bool
+	// follow returns the handles followed like the seal account pool, the lost txs are not found by the confirm
+	follow  bool
+	handles []*txHandle
+	lost    map[string]bool
+}
+
+func (m *mockSealBroadcaster) broadcast(ctx context.Context, msgs []sdk.Msg, gasInfo GasInfo) (*txHandle, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.fail != nil {
+		if err := m.fail(msgs); err != nil {
+			return nil, err
+		}
+	}
+	m.txs = append(m.txs, msgs)
+	m.gasInfos = append(m.gasInfos, gasInfo)
+	txHash := fmt.Sprintf("tx-%d", len(m.txs))
+	if !m.follow {
+		return newFinishedTxHandle(txHash), nil
+	}
+	handle := newTxHandle(txHash)
+	m.handles = append(m.handles, handle)
+	return handle, nil
+}
+
+func (m *mockSealBroadcaster) handle(idx int) *txHandle {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if idx >= len(m.handles) {
+		return nil
+	}
+	return m.handles[idx]
+}
+
+func (m *mockSealBroadcaster) confirm(ctx context.Context, txHash string) (*sdk.TxResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.unconfirmed || m.lost[txHash] {
+		return nil, errors.New("mock tx not found")
+	}
+	var idx int
+	_, _ = fmt.Sscanf(txHash, "tx-%d", &idx)
+	resp := &sdk.TxResponse{TxHash: txHash}
+	if m.failCode != nil {
+		resp.Code = m.failCode(m.txs[idx-1])
+	}
+	return resp, nil
+}
+
+func newMockSealBatcher(m *mockSealBroadcaster, window time.Duration, maxMsgs int, maxGas uint64) *sealBatcher {
+	gasInfo := map[GasInfoType]GasInfo{
+		Seal:       {GasLimit: DefaultSealGasLimit, FeeAmount: sdk.NewCoins(sdk.NewCoin("azkme", sdk.NewInt(DefaultSealFeeAmount)))},
+		RejectSeal: {GasLimit: DefaultRejectSealGasLimit, FeeAmount: sdk.NewCoins(sdk.NewCoin("azkme", sdk.NewInt(DefaultRejectSealFeeAmount)))},
+	}
+	return newSealBatcher(m.broadcast, m.confirm, func() (sdk.AccAddress, error) { return mockSealAddr, nil }, gasInfo,
+		window, maxMsgs, maxGas)
+}
+
+func sealConcurrently(b *sealBatcher, objects []string) ([]string, []error) {
+	var wg sync.WaitGroup
+	hashes := make([]string, len(objects))
+	errs := make([]error, len(objects))
+	for i, object := range objects {
+		wg.Add(1)
+		go func(i int, object string) {
+			defer wg.Done()
+			hashes[i], errs[i] = b.SealObject(context.Background(), &storagetypes.MsgSealObject{BucketName: "bucket", ObjectName: object})
+		}(i, object)
+	}
+	wg.Wait()
+	return hashes, errs
+}
+
+func TestSealBatcher_Batch(t *testing.T) {
+	m := &mockSealBroadcaster{}
+	b := newMockSealBatcher(m, 100*time.Millisecond, 10, 0)
+	b.Start()
+	defer b.Stop()
+
+	hashes, errs := sealConcurrently(b, []string{"a", "b", "c"})
+	for i := range hashes {
+		assert.Nil(t, errs[i])
+		assert.Equal(t, "tx-1", hashes[i])
+	}
+	assert.Len(t, m.txs, 1)
+	assert.Len(t, m.txs[0], 3)
+	assert.Equal(t, uint64(3*DefaultSealGasLimit), m.gasInfos[0].GasLimit)
+	assert.Equal(t, sdk.NewInt(3*DefaultSealFeeAmount), m.gasInfos[0].FeeAmount.AmountOf("azkme"))
+}
+
+func TestSealBatcher_Limits(t *testing.T) {
+	cases := []struct {
+		name    string
+		maxMsgs int
+		maxGas  uint64
+	}{
+		{"max msgs", 2, 0},
+		{"max gas", 10, 2 * DefaultSealGasLimit},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockSealBroadcaster{}
+			b := newMockSealBatcher(m, 100*time.Millisecond, tt.maxMsgs, tt.maxGas)
+			b.Start()
+			defer b.Stop()
+
+			_, errs := sealConcurrently(b, []string{"a", "b", "c", "d", "e"})
+			for _, err := range errs {
+				assert.Nil(t, err)
+			}
+			assert.Len(t, m.txs, 3)
+			for _, msgs := range m.txs {
+				assert.LessOrEqual(t, len(msgs), 2)
+			}
+		})
+	}
+}
+
+func hasBadObject(msgs []sdk.Msg) bool {
+	for _, msg := range msgs {
+		if msg.(*storagetypes.MsgSealObject).GetObjectName() == "bad" {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSealBatcher_PartialFailure(t *testing.T) {
+	cases := []struct {
+		name     string
+		m        *mockSealBroadcaster
+		wantedTx int
+	}{
+		{"broadcast failure", &mockSealBroadcaster{fail: func(msgs []sdk.Msg) error {
+			if hasBadObject(msgs) {
+				return errors.New("mock invalid object")
+			}
+			return nil
+		}}, 2},
+		// the batch tx is accepted by the mempool but fails in the block
+		{"execution failure", &mockSealBroadcaster{failCode: func(msgs []sdk.Msg) uint32 {
+			if hasBadObject(msgs) {
+				return 1
+			}
+			return 0
+		}}, 4},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newMockSealBatcher(tt.m, 100*time.Millisecond, 10, 0)
+			b.Start()
+			defer b.Stop()
+
+			hashes, errs := sealConcurrently(b, []string{"a", "bad", "c"})
+			for i, object := range []string{"a", "bad", "c"} {
+				if object == "bad" {
+					assert.Equal(t, ErrSealObjectOnChain, errs[i])
+					continue
+				}
+				assert.Nil(t, errs[i])
+				assert.NotEmpty(t, hashes[i])
+			}
+			assert.Len(t, tt.m.txs, tt.wantedTx)
+		})
+	}
+}
+
+func TestSealBatcher_Unconfirmed(t *testing.T) {
+	m := &mockSealBroadcaster{unconfirmed: true}
+	b := newMockSealBatcher(m, 100*time.Millisecond, 10, 0)
+	b.Start()
+	defer b.Stop()
+
+	// the messages of the unconfirmed tx are not resubmitted
+	_, errs := sealConcurrently(b, []string{"a", "b"})
+	for _, err := range errs {
+		assert.Equal(t, ErrSealObjectOnChain, err)
+	}
+	assert.Len(t, m.txs, 1)
+}
+
+func TestSealBatcher_Resubmitted(t *testing.T) {
+	m := &mockSealBroadcaster{follow: true, lost: map[string]bool{"tx-1": true}}
+	b := newMockSealBatcher(m, 100*time.Millisecond, 10, 0)
+	b.Start()
+	defer b.Stop()
+
+	// the pool resubmits the stuck tx with a new hash, the batch is confirmed by the new hash
+	go func() {
+		for m.handle(0) == nil {
+			time.Sleep(time.Millisecond)
+		}
+		m.handle(0).resubmit("tx-1-resubmitted")
+		m.handle(0).finish(nil)
+	}()
+	hashes, errs := sealConcurrently(b, []string{"a", "b"})
+	for i := range hashes {
+		assert.Nil(t, errs[i])
+		assert.Equal(t, "tx-1-resubmitted", hashes[i])
+	}
+	assert.Len(t, m.txs, 1)
+}
+
+func TestSealBatcher_Duplicate(t *testing.T) {
+	m := &mockSealBroadcaster{}
+	b := newMockSealBatcher(m, 100*time.Millisecond, 10, 0)
+	b.Start()
+	defer b.Stop()
+
+	hashes, errs := sealConcurrently(b, []string{"a", "a", "b"})
+	for i := range hashes {
+		assert.Nil(t, errs[i])
+		assert.Equal(t, "tx-1", hashes[i])
+	}
+	assert.Len(t, m.txs, 1)
+	assert.Len(t, m.txs[0], 2)
+	assert.Equal(t, uint64(2*DefaultSealGasLimit), m.gasInfos[0].GasLimit)
+}
+
+func TestNewSealBatcher_MaxGas(t *testing.T) {
+	b := newMockSealBatcher(&mockSealBroadcaster{}, 0, 10, 0)
+	assert.Equal(t, uint64(10*DefaultSealGasLimit), b.maxGas)
+	b = newSealBatcher(nil, nil, nil, nil, 0, 0, 0)
+	assert.Equal(t, uint64(DefaultSealBatchMaxGasLimit), b.maxGas)
+}
+
+func TestSealBatcher_RejectUnSealObject(t *testing.T) {
+	m := &mockSealBroadcaster{}
+	b := newMockSealBatcher(m, time.Millisecond, 10, 0)
+	b.Start()
+	defer b.Stop()
+
+	_, err := b.RejectUnSealObject(context.Background(), nil)
+	assert.Equal(t, ErrDanglingPointer, err)
+	txHash, err := b.RejectUnSealObject(context.Background(), &storagetypes.MsgRejectSealObject{BucketName: "bucket", ObjectName: "a"})
+	assert.Nil(t, err)
+	assert.Equal(t, "tx-1", txHash)
+	assert.Equal(t, uint64(DefaultRejectSealGasLimit), m.gasInfos[0].GasLimit)
+}
+
+func TestSealBatcher_Stop(t *testing.T) {
+	m := &mockSealBroadcaster{}
+	b := newMockSealBatcher(m, time.Hour, 10, 0)
+
+	result := make(chan error)
+	go func() {
+		_, err := b.SealObject(context.Background(), &storagetypes.MsgSealObject{BucketName: "bucket", ObjectName: "a"})
+		result <- err
+	}()
+	assert.Eventually(t, func() bool { return len(b.requests) == 1 }, time.Second, time.Millisecond)
+	b.Start()
+	b.Stop()
+	assert.Nil(t, <-result)
+	assert.Len(t, m.txs, 1)
+
+	_, err := b.SealObject(context.Background(), &storagetypes.MsgSealObject{BucketName: "bucket", ObjectName: "b"})
+	assert.Equal(t, ErrSealBatcherStopped, err)
+}
@@ -6,24 +6,39 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/evmos/evmos/v12/x/evm/precompiles/storage"
 	"github.com/evmos/evmos/v12/x/evm/precompiles/storageprovider"
 	"github.com/evmos/evmos/v12/x/evm/precompiles/virtualgroup"
+
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/keysigner"
 )
 
-func CreateTxOpts(ctx context.Context, client *ethclient.Client, hexPrivateKey string, chain *big.Int, gasLimit uint64, nonce uint64) (*bind.TransactOpts, error) {
-	// create private key
-	privateKey, err := crypto.HexToECDSA(hexPrivateKey)
+// CreateTxOpts returns the transact opts signing the evm txs by the secp256k1 signer.
+func CreateTxOpts(ctx context.Context, client *ethclient.Client, signer keysigner.Signer, chain *big.Int, gasLimit uint64, nonce uint64) (*bind.TransactOpts, error) {
+	pubKey, err := crypto.DecompressPubkey(signer.PublicKey())
 	if err != nil {
 		return nil, err
 	}
 
-	// Build transact tx opts with private key
-	txOpts, err := bind.NewKeyedTransactorWithChainID(privateKey, chain)
-	if err != nil {
-		return nil, err
+	// Build transact tx opts with the signer, like bind.NewKeyedTransactorWithChainID without the private key
+	from := crypto.PubkeyToAddress(*pubKey)
+	latestSigner := types.LatestSignerForChainID(chain)
+	txOpts := &bind.TransactOpts{
+		From: from,
+		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != from {
+				return nil, bind.ErrNotAuthorized
+			}
+			signature, err := signer.Sign(latestSigner.Hash(tx).Bytes())
+			if err != nil {
+				return nil, err
+			}
+			return tx.WithSignature(latestSigner, signature)
+		},
+		Context: context.Background(),
 	}
 
 	// set gas limit and gas price
@@ -17,6 +17,7 @@ import (
 	"github.com/zkMeLabs/mechain-storage-provider/core/module"
 	"github.com/zkMeLabs/mechain-storage-provider/core/rcmgr"
 	"github.com/zkMeLabs/mechain-storage-provider/core/task"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/auditlog"
 	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
 )
 
@@ -46,8 +47,12 @@ var (
 var _ module.Signer = &SignModular{}
 
 type SignModular struct {
-	baseApp *gfspapp.GfSpBaseApp
-	client  *MechainChainSignClient
+	baseApp     *gfspapp.GfSpBaseApp
+	client      *MechainChainSignClient
+	sealBatcher *sealBatcher
+	signers     *spSigners
+	auditLog    *auditlog.Log
+	keyRotator  *keyRotator
 }
 
 func (s *SignModular) Name() string {
@@ -55,10 +60,36 @@ func (s *SignModular) Name() string {
 }
 
 func (s *SignModular) Start(ctx context.Context) error {
+	if s.sealBatcher != nil {
+		s.sealBatcher.Start()
+	}
+	if s.client.sealPool != nil {
+		s.client.sealPool.Start()
+	}
+	if s.keyRotator != nil {
+		s.keyRotator.Start(ctx)
+	}
 	return nil
 }
 
 func (s *SignModular) Stop(ctx context.Context) error {
+	if s.keyRotator != nil {
+		s.keyRotator.Stop()
+	}
+	if s.sealBatcher != nil {
+		s.sealBatcher.Stop()
+	}
+	if s.client.sealPool != nil {
+		s.client.sealPool.Stop()
+	}
+	if s.auditLog != nil {
+		if err := s.auditLog.Close(); err != nil {
+			log.Errorw("failed to close audit log", "error", err)
+		}
+	}
+	if s.signers != nil {
+		return s.signers.Close()
+	}
 	return nil
 }
 
@@ -72,124 +103,148 @@ func (s *SignModular) ReleaseResource(ctx context.Context, span rcmgr.ResourceSc
 	span.Done()
 }
 
-func (s *SignModular) SignCreateBucketApproval(ctx context.Context, bucket *storagetypes.MsgCreateBucket) ([]byte, error) {
+func (s *SignModular) SignCreateBucketApproval(ctx context.Context, bucket *storagetypes.MsgCreateBucket) (sig []byte, err error) {
 	msg := bucket.GetApprovalBytes()
-	sig, err := s.client.Sign(SignApproval, msg)
+	defer func() { s.auditSign(ctx, "SignCreateBucketApproval", string(SignApproval), bucket, msg, err) }()
+	sig, err = s.client.Sign(SignApproval, msg)
 	if err != nil {
 		return nil, err
 	}
 	return sig, nil
 }
 
-func (s *SignModular) SignMigrateBucketApproval(ctx context.Context, migrateBucket *storagetypes.MsgMigrateBucket) ([]byte, error) {
+func (s *SignModular) SignMigrateBucketApproval(ctx context.Context, migrateBucket *storagetypes.MsgMigrateBucket) (sig []byte, err error) {
 	msg := migrateBucket.GetApprovalBytes()
-	sig, err := s.client.Sign(SignApproval, msg)
+	defer func() { s.auditSign(ctx, "SignMigrateBucketApproval", string(SignApproval), migrateBucket, msg, err) }()
+	sig, err = s.client.Sign(SignApproval, msg)
 	if err != nil {
 		return nil, err
 	}
 	return sig, nil
 }
 
-func (s *SignModular) SignCreateObjectApproval(ctx context.Context, object *storagetypes.MsgCreateObject) ([]byte, error) {
+func (s *SignModular) SignCreateObjectApproval(ctx context.Context, object *storagetypes.MsgCreateObject) (sig []byte, err error) {
 	msg := object.GetApprovalBytes()
-	sig, err := s.client.Sign(SignApproval, msg)
+	defer func() { s.auditSign(ctx, "SignCreateObjectApproval", string(SignApproval), object, msg, err) }()
+	sig, err = s.client.Sign(SignApproval, msg)
 	if err != nil {
 		return nil, err
 	}
 	return sig, nil
 }
 
-func (s *SignModular) SignReplicatePieceApproval(ctx context.Context, task task.ApprovalReplicatePieceTask) ([]byte, error) {
+func (s *SignModular) SignReplicatePieceApproval(ctx context.Context, task task.ApprovalReplicatePieceTask) (sig []byte, err error) {
 	msg := task.GetSignBytes()
-	sig, err := s.client.Sign(SignOperator, msg)
+	defer func() { s.auditSign(ctx, "SignReplicatePieceApproval", string(SignOperator), task, msg, err) }()
+	sig, err = s.client.Sign(SignOperator, msg)
 	if err != nil {
 		return nil, err
 	}
 	return sig, nil
 }
 
-func (s *SignModular) SignReceivePieceTask(ctx context.Context, task task.ReceivePieceTask) ([]byte, error) {
+func (s *SignModular) SignReceivePieceTask(ctx context.Context, task task.ReceivePieceTask) (sig []byte, err error) {
 	msg := task.GetSignBytes()
-	sig, err := s.client.Sign(SignOperator, msg)
+	defer func() { s.auditSign(ctx, "SignReceivePieceTask", string(SignOperator), task, msg, err) }()
+	sig, err = s.client.Sign(SignOperator, msg)
 	if err != nil {
 		return nil, err
 	}
 	return sig, nil
 }
 
-func (s *SignModular) SignSecondarySealBls(ctx context.Context, objectID uint64, gvgId uint32, checksums [][]byte) ([]byte, error) {
-	msg := storagetypes.NewSecondarySpSealObjectSignDoc(s.baseApp.ChainID(), gvgId, sdkmath.NewUint(objectID), storagetypes.GenerateHash(checksums)).GetBlsSignHash()
-	sig, err := s.client.blsKm.Sign(msg[:])
+func (s *SignModular) SignSecondarySealBls(ctx context.Context, objectID uint64, gvgId uint32, checksums [][]byte) (sig []byte, err error) {
+	signDoc := storagetypes.NewSecondarySpSealObjectSignDoc(s.baseApp.ChainID(), gvgId, sdkmath.NewUint(objectID), storagetypes.GenerateHash(checksums))
+	msg := signDoc.GetBlsSignHash()
+	defer func() { s.auditSign(ctx, "SignSecondarySealBls", BlsKeyID, signDoc, msg[:], err) }()
+	sig, err = s.client.blsSigner.Sign(msg[:])
 	if err != nil {
 		return nil, err
 	}
-	log.Debugw("bls signature", "len", len(sig), "object_id", objectID, "gvg_id", gvgId, "sign_doc", hex.EncodeToString(msg[:]), "pub_key", hex.EncodeToString(s.client.blsKm.PubKey().Bytes()), "sig", hex.EncodeToString(sig))
+	log.Debugw("bls signature", "len", len(sig), "object_id", objectID, "gvg_id", gvgId, "sign_doc", hex.EncodeToString(msg[:]), "pub_key", hex.EncodeToString(s.client.blsSigner.PublicKey()), "sig", hex.EncodeToString(sig))
 	return sig, nil
 }
 
-func (s *SignModular) SignRecoveryPieceTask(ctx context.Context, task task.RecoveryPieceTask) (
-	[]byte, error,
-) {
+func (s *SignModular) SignRecoveryPieceTask(ctx context.Context, task task.RecoveryPieceTask) (sig []byte, err error) {
 	msg := task.GetSignBytes()
-	sig, err := s.client.Sign(SignOperator, msg)
+	defer func() { s.auditSign(ctx, "SignRecoveryPieceTask", string(SignOperator), task, msg, err) }()
+	sig, err = s.client.Sign(SignOperator, msg)
 	if err != nil {
 		return nil, err
 	}
 	return sig, nil
 }
 
-func (s *SignModular) SignP2PPingMsg(ctx context.Context, ping *gfspp2p.GfSpPing) ([]byte, error) {
+func (s *SignModular) SignP2PPingMsg(ctx context.Context, ping *gfspp2p.GfSpPing) (sig []byte, err error) {
 	msg := ping.GetSignBytes()
-	sig, err := s.client.Sign(SignOperator, msg)
+	defer func() { s.auditSign(ctx, "SignP2PPingMsg", string(SignOperator), ping, msg, err) }()
+	sig, err = s.client.Sign(SignOperator, msg)
 	if err != nil {
 		return nil, err
 	}
 	return sig, nil
 }
 
-func (s *SignModular) SignP2PPongMsg(ctx context.Context, pong *gfspp2p.GfSpPong) ([]byte, error) {
+func (s *SignModular) SignP2PPongMsg(ctx context.Context, pong *gfspp2p.GfSpPong) (sig []byte, err error) {
 	msg := pong.GetSignBytes()
-	sig, err := s.client.Sign(SignOperator, msg)
+	defer func() { s.auditSign(ctx, "SignP2PPongMsg", string(SignOperator), pong, msg, err) }()
+	sig, err = s.client.Sign(SignOperator, msg)
 	if err != nil {
 		return nil, err
 	}
 	return sig, nil
 }
 
-func (s *SignModular) SealObject(ctx context.Context, object *storagetypes.MsgSealObject) (string, error) {
+func (s *SignModular) SealObject(ctx context.Context, object *storagetypes.MsgSealObject) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "SealObject", SignSeal, object, txHash, err) }()
+	if s.sealBatcher != nil {
+		return s.sealBatcher.SealObject(ctx, object)
+	}
 	return s.client.SealObject(ctx, SignSeal, object)
 }
 
-func (s *SignModular) SealObjectEvm(ctx context.Context, object *storagetypes.MsgSealObject) (string, error) {
+func (s *SignModular) SealObjectEvm(ctx context.Context, object *storagetypes.MsgSealObject) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "SealObjectEvm", SignSeal, object, txHash, err) }()
 	return s.client.SealObjectEvm(ctx, SignSeal, object)
 }
 
-func (s *SignModular) RejectUnSealObject(ctx context.Context, rejectObject *storagetypes.MsgRejectSealObject) (string, error) {
+func (s *SignModular) RejectUnSealObject(ctx context.Context, rejectObject *storagetypes.MsgRejectSealObject) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "RejectUnSealObject", SignSeal, rejectObject, txHash, err) }()
+	if s.sealBatcher != nil {
+		return s.sealBatcher.RejectUnSealObject(ctx, rejectObject)
+	}
 	return s.client.RejectUnSealObject(ctx, SignSeal, rejectObject)
 }
 
-func (s *SignModular) RejectUnSealObjectEvm(ctx context.Context, rejectObject *storagetypes.MsgRejectSealObject) (string, error) {
+func (s *SignModular) RejectUnSealObjectEvm(ctx context.Context, rejectObject *storagetypes.MsgRejectSealObject) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "RejectUnSealObjectEvm", SignSeal, rejectObject, txHash, err) }()
 	return s.client.RejectUnSealObjectEvm(ctx, SignSeal, rejectObject)
 }
 
-func (s *SignModular) DiscontinueBucket(ctx context.Context, bucket *storagetypes.MsgDiscontinueBucket) (string, error) {
+func (s *SignModular) DiscontinueBucket(ctx context.Context, bucket *storagetypes.MsgDiscontinueBucket) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "DiscontinueBucket", SignGc, bucket, txHash, err) }()
 	return s.client.DiscontinueBucket(ctx, SignGc, bucket)
 }
 
-func (s *SignModular) DiscontinueBucketEvm(ctx context.Context, bucket *storagetypes.MsgDiscontinueBucket) (string, error) {
+func (s *SignModular) DiscontinueBucketEvm(ctx context.Context, bucket *storagetypes.MsgDiscontinueBucket) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "DiscontinueBucketEvm", SignGc, bucket, txHash, err) }()
 	return s.client.DiscontinueBucketEvm(ctx, SignGc, bucket)
 }
 
-func (s *SignModular) CreateGlobalVirtualGroup(ctx context.Context, gvg *virtualgrouptypes.MsgCreateGlobalVirtualGroup) (string, error) {
+func (s *SignModular) CreateGlobalVirtualGroup(ctx context.Context, gvg *virtualgrouptypes.MsgCreateGlobalVirtualGroup) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "CreateGlobalVirtualGroup", SignOperator, gvg, txHash, err) }()
 	return s.client.CreateGlobalVirtualGroup(ctx, SignOperator, gvg)
 }
 
-func (s *SignModular) CreateGlobalVirtualGroupEvm(ctx context.Context, gvg *virtualgrouptypes.MsgCreateGlobalVirtualGroup) (string, error) {
+func (s *SignModular) CreateGlobalVirtualGroupEvm(ctx context.Context, gvg *virtualgrouptypes.MsgCreateGlobalVirtualGroup) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "CreateGlobalVirtualGroupEvm", SignOperator, gvg, txHash, err) }()
 	return s.client.CreateGlobalVirtualGroupEvm(ctx, SignOperator, gvg)
 }
 
-func (s *SignModular) SignMigrateGVG(ctx context.Context, mp *gfsptask.GfSpMigrateGVGTask) ([]byte, error) {
-	sig, err := s.client.Sign(SignOperator, mp.GetSignBytes())
+func (s *SignModular) SignMigrateGVG(ctx context.Context, mp *gfsptask.GfSpMigrateGVGTask) (sig []byte, err error) {
+	msg := mp.GetSignBytes()
+	defer func() { s.auditSign(ctx, "SignMigrateGVG", string(SignOperator), mp, msg, err) }()
+	sig, err = s.client.Sign(SignOperator, msg)
 	if err != nil {
 		log.Errorw("failed to sign migrate gvg", "error", err)
 		return nil, err
@@ -197,8 +252,10 @@ func (s *SignModular) SignMigrateGVG(ctx context.Context, mp *gfsptask.GfSpMigra
 	return sig, nil
 }
 
-func (s *SignModular) SignBucketMigrationInfo(ctx context.Context, mp *gfsptask.GfSpBucketMigrationInfo) ([]byte, error) {
-	sig, err := s.client.Sign(SignOperator, mp.GetSignBytes())
+func (s *SignModular) SignBucketMigrationInfo(ctx context.Context, mp *gfsptask.GfSpBucketMigrationInfo) (sig []byte, err error) {
+	msg := mp.GetSignBytes()
+	defer func() { s.auditSign(ctx, "SignBucketMigrationInfo", string(SignOperator), mp, msg, err) }()
+	sig, err = s.client.Sign(SignOperator, msg)
 	if err != nil {
 		log.Errorw("failed to sign bucket migration info", "error", err)
 		return nil, err
@@ -206,140 +263,175 @@ func (s *SignModular) SignBucketMigrationInfo(ctx context.Context, mp *gfsptask.
 	return sig, nil
 }
 
-func (s *SignModular) CompleteMigrateBucket(ctx context.Context, migrateBucket *storagetypes.MsgCompleteMigrateBucket) (string, error) {
+func (s *SignModular) CompleteMigrateBucket(ctx context.Context, migrateBucket *storagetypes.MsgCompleteMigrateBucket) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "CompleteMigrateBucket", SignOperator, migrateBucket, txHash, err) }()
 	return s.client.CompleteMigrateBucket(ctx, SignOperator, migrateBucket)
 }
 
-func (s *SignModular) CompleteMigrateBucketEvm(ctx context.Context, migrateBucket *storagetypes.MsgCompleteMigrateBucket) (string, error) {
+func (s *SignModular) CompleteMigrateBucketEvm(ctx context.Context, migrateBucket *storagetypes.MsgCompleteMigrateBucket) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "CompleteMigrateBucketEvm", SignOperator, migrateBucket, txHash, err) }()
 	return s.client.CompleteMigrateBucketEvm(ctx, SignOperator, migrateBucket)
 }
 
-func (s *SignModular) UpdateSPPrice(ctx context.Context, price *sptypes.MsgUpdateSpStoragePrice) (string, error) {
+func (s *SignModular) UpdateSPPrice(ctx context.Context, price *sptypes.MsgUpdateSpStoragePrice) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "UpdateSPPrice", SignOperator, price, txHash, err) }()
 	return s.client.UpdateSPPrice(ctx, SignOperator, price)
 }
 
-func (s *SignModular) UpdateSPPriceEvm(ctx context.Context, price *sptypes.MsgUpdateSpStoragePrice) (string, error) {
+func (s *SignModular) UpdateSPPriceEvm(ctx context.Context, price *sptypes.MsgUpdateSpStoragePrice) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "UpdateSPPriceEvm", SignOperator, price, txHash, err) }()
 	return s.client.UpdateSPPriceEvm(ctx, SignOperator, price)
 }
 
-func (s *SignModular) SignSecondarySPMigrationBucket(ctx context.Context, signDoc *storagetypes.SecondarySpMigrationBucketSignDoc) ([]byte, error) {
+func (s *SignModular) SignSecondarySPMigrationBucket(ctx context.Context, signDoc *storagetypes.SecondarySpMigrationBucketSignDoc) (sig []byte, err error) {
 	msg := signDoc.GetBlsSignHash()
-	sig, err := s.client.blsKm.Sign(msg[:])
+	defer func() { s.auditSign(ctx, "SignSecondarySPMigrationBucket", BlsKeyID, signDoc, msg[:], err) }()
+	sig, err = s.client.blsSigner.Sign(msg[:])
 	if err != nil {
 		return nil, err
 	}
 	return sig, nil
 }
 
-func (s *SignModular) SwapOut(ctx context.Context, swapOut *virtualgrouptypes.MsgSwapOut) (string, error) {
+func (s *SignModular) SwapOut(ctx context.Context, swapOut *virtualgrouptypes.MsgSwapOut) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "SwapOut", SignOperator, swapOut, txHash, err) }()
 	return s.client.SwapOut(ctx, SignOperator, swapOut)
 }
 
-func (s *SignModular) SwapOutEvm(ctx context.Context, swapOut *virtualgrouptypes.MsgSwapOut) (string, error) {
+func (s *SignModular) SwapOutEvm(ctx context.Context, swapOut *virtualgrouptypes.MsgSwapOut) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "SwapOutEvm", SignOperator, swapOut, txHash, err) }()
 	return s.client.SwapOutEvm(ctx, SignOperator, swapOut)
 }
 
-func (s *SignModular) SignSwapOut(ctx context.Context, swapOut *virtualgrouptypes.MsgSwapOut) ([]byte, error) {
+func (s *SignModular) SignSwapOut(ctx context.Context, swapOut *virtualgrouptypes.MsgSwapOut) (sig []byte, err error) {
 	msg := swapOut.GetApprovalBytes()
-	sig, err := s.client.Sign(SignApproval, msg)
+	defer func() { s.auditSign(ctx, "SignSwapOut", string(SignApproval), swapOut, msg, err) }()
+	sig, err = s.client.Sign(SignApproval, msg)
 	if err != nil {
 		return nil, err
 	}
 	return sig, nil
 }
 
-func (s *SignModular) CompleteSwapOut(ctx context.Context, completeSwapOut *virtualgrouptypes.MsgCompleteSwapOut) (string, error) {
+func (s *SignModular) CompleteSwapOut(ctx context.Context, completeSwapOut *virtualgrouptypes.MsgCompleteSwapOut) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "CompleteSwapOut", SignOperator, completeSwapOut, txHash, err) }()
 	return s.client.CompleteSwapOut(ctx, SignOperator, completeSwapOut)
 }
 
-func (s *SignModular) CompleteSwapOutEvm(ctx context.Context, completeSwapOut *virtualgrouptypes.MsgCompleteSwapOut) (string, error) {
+func (s *SignModular) CompleteSwapOutEvm(ctx context.Context, completeSwapOut *virtualgrouptypes.MsgCompleteSwapOut) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "CompleteSwapOutEvm", SignOperator, completeSwapOut, txHash, err) }()
 	return s.client.CompleteSwapOutEvm(ctx, SignOperator, completeSwapOut)
 }
 
-func (s *SignModular) SPExit(ctx context.Context, spExit *virtualgrouptypes.MsgStorageProviderExit) (string, error) {
+func (s *SignModular) SPExit(ctx context.Context, spExit *virtualgrouptypes.MsgStorageProviderExit) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "SPExit", SignOperator, spExit, txHash, err) }()
 	return s.client.SPExit(ctx, SignOperator, spExit)
 }
 
-func (s *SignModular) SPExitEvm(ctx context.Context, spExit *virtualgrouptypes.MsgStorageProviderExit) (string, error) {
+func (s *SignModular) SPExitEvm(ctx context.Context, spExit *virtualgrouptypes.MsgStorageProviderExit) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "SPExitEvm", SignOperator, spExit, txHash, err) }()
 	return s.client.SPExitEvm(ctx, SignOperator, spExit)
 }
 
-func (s *SignModular) CompleteSPExit(ctx context.Context, completeSPExit *virtualgrouptypes.MsgCompleteStorageProviderExit) (string, error) {
+func (s *SignModular) CompleteSPExit(ctx context.Context, completeSPExit *virtualgrouptypes.MsgCompleteStorageProviderExit) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "CompleteSPExit", SignOperator, completeSPExit, txHash, err) }()
 	return s.client.CompleteSPExit(ctx, SignOperator, completeSPExit)
 }
 
-func (s *SignModular) CompleteSPExitEvm(ctx context.Context, completeSPExit *virtualgrouptypes.MsgCompleteStorageProviderExit) (string, error) {
+func (s *SignModular) CompleteSPExitEvm(ctx context.Context, completeSPExit *virtualgrouptypes.MsgCompleteStorageProviderExit) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "CompleteSPExitEvm", SignOperator, completeSPExit, txHash, err) }()
 	return s.client.CompleteSPExitEvm(ctx, SignOperator, completeSPExit)
 }
 
-func (s *SignModular) RejectMigrateBucket(ctx context.Context, rejectMigrateBucket *storagetypes.MsgRejectMigrateBucket) (string, error) {
+func (s *SignModular) RejectMigrateBucket(ctx context.Context, rejectMigrateBucket *storagetypes.MsgRejectMigrateBucket) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "RejectMigrateBucket", SignOperator, rejectMigrateBucket, txHash, err) }()
 	return s.client.RejectMigrateBucket(ctx, SignOperator, rejectMigrateBucket)
 }
 
-func (s *SignModular) RejectMigrateBucketEvm(ctx context.Context, rejectMigrateBucket *storagetypes.MsgRejectMigrateBucket) (string, error) {
+func (s *SignModular) RejectMigrateBucketEvm(ctx context.Context, rejectMigrateBucket *storagetypes.MsgRejectMigrateBucket) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "RejectMigrateBucketEvm", SignOperator, rejectMigrateBucket, txHash, err) }()
 	return s.client.RejectMigrateBucketEvm(ctx, SignOperator, rejectMigrateBucket)
 }
 
-func (s *SignModular) ReserveSwapIn(ctx context.Context, reserveSwapIn *virtualgrouptypes.MsgReserveSwapIn) (string, error) {
+func (s *SignModular) ReserveSwapIn(ctx context.Context, reserveSwapIn *virtualgrouptypes.MsgReserveSwapIn) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "ReserveSwapIn", SignOperator, reserveSwapIn, txHash, err) }()
 	return s.client.ReserveSwapIn(ctx, SignOperator, reserveSwapIn)
 }
 
-func (s *SignModular) ReserveSwapInEvm(ctx context.Context, reserveSwapIn *virtualgrouptypes.MsgReserveSwapIn) (string, error) {
+func (s *SignModular) ReserveSwapInEvm(ctx context.Context, reserveSwapIn *virtualgrouptypes.MsgReserveSwapIn) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "ReserveSwapInEvm", SignOperator, reserveSwapIn, txHash, err) }()
 	return s.client.ReserveSwapInEvm(ctx, SignOperator, reserveSwapIn)
 }
 
-func (s *SignModular) CompleteSwapIn(ctx context.Context, completeSwapIn *virtualgrouptypes.MsgCompleteSwapIn) (string, error) {
+func (s *SignModular) CompleteSwapIn(ctx context.Context, completeSwapIn *virtualgrouptypes.MsgCompleteSwapIn) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "CompleteSwapIn", SignOperator, completeSwapIn, txHash, err) }()
 	return s.client.CompleteSwapIn(ctx, SignOperator, completeSwapIn)
 }
 
-func (s *SignModular) CompleteSwapInEvm(ctx context.Context, completeSwapIn *virtualgrouptypes.MsgCompleteSwapIn) (string, error) {
+func (s *SignModular) CompleteSwapInEvm(ctx context.Context, completeSwapIn *virtualgrouptypes.MsgCompleteSwapIn) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "CompleteSwapInEvm", SignOperator, completeSwapIn, txHash, err) }()
 	return s.client.CompleteSwapInEvm(ctx, SignOperator, completeSwapIn)
 }
 
-func (s *SignModular) CancelSwapIn(ctx context.Context, cancelSwapIn *virtualgrouptypes.MsgCancelSwapIn) (string, error) {
+func (s *SignModular) CancelSwapIn(ctx context.Context, cancelSwapIn *virtualgrouptypes.MsgCancelSwapIn) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "CancelSwapIn", SignOperator, cancelSwapIn, txHash, err) }()
 	return s.client.CancelSwapIn(ctx, SignOperator, cancelSwapIn)
 }
 
-func (s *SignModular) CancelSwapInEvm(ctx context.Context, cancelSwapIn *virtualgrouptypes.MsgCancelSwapIn) (string, error) {
+func (s *SignModular) CancelSwapInEvm(ctx context.Context, cancelSwapIn *virtualgrouptypes.MsgCancelSwapIn) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "CancelSwapInEvm", SignOperator, cancelSwapIn, txHash, err) }()
 	return s.client.CancelSwapInEvm(ctx, SignOperator, cancelSwapIn)
 }
 
-func (s *SignModular) Deposit(ctx context.Context, deposit *virtualgrouptypes.MsgDeposit) (string, error) {
+func (s *SignModular) Deposit(ctx context.Context, deposit *virtualgrouptypes.MsgDeposit) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "Deposit", SignOperator, deposit, txHash, err) }()
 	return s.client.Deposit(ctx, SignOperator, deposit)
 }
 
-func (s *SignModular) DepositEvm(ctx context.Context, deposit *virtualgrouptypes.MsgDeposit) (string, error) {
+func (s *SignModular) DepositEvm(ctx context.Context, deposit *virtualgrouptypes.MsgDeposit) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "DepositEvm", SignOperator, deposit, txHash, err) }()
 	return s.client.DepositEvm(ctx, SignOperator, deposit)
 }
 
-func (s *SignModular) DeleteGlobalVirtualGroup(ctx context.Context, deleteGVG *virtualgrouptypes.MsgDeleteGlobalVirtualGroup) (string, error) {
+func (s *SignModular) DeleteGlobalVirtualGroup(ctx context.Context, deleteGVG *virtualgrouptypes.MsgDeleteGlobalVirtualGroup) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "DeleteGlobalVirtualGroup", SignOperator, deleteGVG, txHash, err) }()
 	return s.client.DeleteGlobalVirtualGroup(ctx, SignOperator, deleteGVG)
 }
 
-func (s *SignModular) DeleteGlobalVirtualGroupEvm(ctx context.Context, deleteGVG *virtualgrouptypes.MsgDeleteGlobalVirtualGroup) (string, error) {
+func (s *SignModular) DeleteGlobalVirtualGroupEvm(ctx context.Context, deleteGVG *virtualgrouptypes.MsgDeleteGlobalVirtualGroup) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "DeleteGlobalVirtualGroupEvm", SignOperator, deleteGVG, txHash, err) }()
 	return s.client.DeleteGlobalVirtualGroupEvm(ctx, SignOperator, deleteGVG)
 }
 
-func (s *SignModular) DelegateUpdateObjectContent(ctx context.Context, msg *storagetypes.MsgDelegateUpdateObjectContent) (string, error) {
+func (s *SignModular) DelegateUpdateObjectContent(ctx context.Context, msg *storagetypes.MsgDelegateUpdateObjectContent) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "DelegateUpdateObjectContent", SignOperator, msg, txHash, err) }()
 	return s.client.DelegateUpdateObjectContent(ctx, SignOperator, msg)
 }
 
-func (s *SignModular) DelegateUpdateObjectContentEvm(ctx context.Context, msg *storagetypes.MsgDelegateUpdateObjectContent) (string, error) {
+func (s *SignModular) DelegateUpdateObjectContentEvm(ctx context.Context, msg *storagetypes.MsgDelegateUpdateObjectContent) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "DelegateUpdateObjectContentEvm", SignOperator, msg, txHash, err) }()
 	return s.client.DelegateUpdateObjectContentEvm(ctx, SignOperator, msg)
 }
 
-func (s *SignModular) DelegateCreateObject(ctx context.Context, msg *storagetypes.MsgDelegateCreateObject) (string, error) {
+func (s *SignModular) DelegateCreateObject(ctx context.Context, msg *storagetypes.MsgDelegateCreateObject) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "DelegateCreateObject", SignOperator, msg, txHash, err) }()
 	return s.client.DelegateCreateObject(ctx, SignOperator, msg)
 }
 
-func (s *SignModular) DelegateCreateObjectEvm(ctx context.Context, msg *storagetypes.MsgDelegateCreateObject) (string, error) {
+func (s *SignModular) DelegateCreateObjectEvm(ctx context.Context, msg *storagetypes.MsgDelegateCreateObject) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "DelegateCreateObjectEvm", SignOperator, msg, txHash, err) }()
 	return s.client.DelegateCreateObjectEvm(ctx, SignOperator, msg)
 }
 
-func (s *SignModular) SealObjectV2(ctx context.Context, object *storagetypes.MsgSealObjectV2) (string, error) {
+func (s *SignModular) SealObjectV2(ctx context.Context, object *storagetypes.MsgSealObjectV2) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "SealObjectV2", SignSeal, object, txHash, err) }()
+	if s.sealBatcher != nil {
+		return s.sealBatcher.SealObjectV2(ctx, object)
+	}
 	return s.client.SealObjectV2(ctx, SignSeal, object)
 }
 
-func (s *SignModular) SealObjectV2Evm(ctx context.Context, object *storagetypes.MsgSealObjectV2) (string, error) {
+func (s *SignModular) SealObjectV2Evm(ctx context.Context, object *storagetypes.MsgSealObjectV2) (txHash string, err error) {
+	defer func() { s.auditTx(ctx, "SealObjectV2Evm", SignSeal, object, txHash, err) }()
 	return s.client.SealObjectV2Evm(ctx, SignSeal, object)
 }
@@ -6,11 +6,13 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"cosmossdk.io/errors"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	sdkErrors "github.com/cosmos/cosmos-sdk/types/errors"
 	"github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	ethcmn "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"google.golang.org/grpc"
@@ -18,7 +20,6 @@ import (
 	"github.com/ethereum/go-ethereum/ethclient"
 
 	"github.com/evmos/evmos/v12/sdk/client"
-	"github.com/evmos/evmos/v12/sdk/keys"
 	ctypes "github.com/evmos/evmos/v12/sdk/types"
 	"github.com/evmos/evmos/v12/types"
 	"github.com/evmos/evmos/v12/types/common"
@@ -27,6 +28,7 @@ import (
 	sptypes "github.com/evmos/evmos/v12/x/sp/types"
 	storagetypes "github.com/evmos/evmos/v12/x/storage/types"
 	virtualgrouptypes "github.com/evmos/evmos/v12/x/virtualgroup/types"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/keysigner"
 	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
 )
 
@@ -87,26 +89,28 @@ type MechainChainSignClient struct {
 
 	gasInfo          map[GasInfoType]GasInfo
 	mechainClients   map[SignType]*client.MechainClient
-	privateKeys      map[SignType]string
+	txSigners        map[SignType]keysigner.Signer
 	evmClient        *ethclient.Client
+	sealPool         *sealAccountPool
+	feeEstimator     *feeEstimator
+	feeBudget        *feeBudget
 	operatorAccNonce uint64
 	sealAccNonce     uint64
 	gcAccNonce       uint64
-	blsKm            keys.KeyManager
+	blsSigner        keysigner.Signer
+
+	// broadcastClients and evmClients are the clients of the other chain endpoints, the txs are broadcast by the
+	// clients of the endpoint returned by broadcastRoute.
+	broadcastClients map[SignType]routedTxClients
+	evmClients       map[string]*ethclient.Client
+	broadcastRoute   broadcastRoute
 }
 
-// NewMechainChainSignClient return the MechainChainSignClient instance
-func NewMechainChainSignClient(rpcAddr, evmRpcAddr, chainID string, gasInfo map[GasInfoType]GasInfo, operatorPrivateKey, fundingPrivateKey,
-	sealPrivateKey, approvalPrivateKey, gcPrivateKey string, blsPrivKey string,
+// NewMechainChainSignClient return the MechainChainSignClient instance, the txs and the approvals are signed by
+// the signers of the SP keys, the private keys are held by the key backend.
+func NewMechainChainSignClient(rpcAddr, evmRpcAddr, chainID string, gasInfo map[GasInfoType]GasInfo,
+	txSigners map[SignType]keysigner.Signer, blsSigner keysigner.Signer,
 ) (*MechainChainSignClient, error) {
-	// init clients
-	// TODO: Get private key from KMS(AWS, GCP, Azure, Aliyun)
-	operatorKM, err := keys.NewPrivateKeyManager(operatorPrivateKey)
-	if err != nil {
-		log.Errorw("failed to new operator private key manager", "error", err)
-		return nil, err
-	}
-
 	// creat chain client
 	evmClient, err := ethclient.Dial(evmRpcAddr)
 	if err != nil {
@@ -114,7 +118,8 @@ func NewMechainChainSignClient(rpcAddr, evmRpcAddr, chainID string, gasInfo map[
 		return nil, err
 	}
 
-	operatorClient, err := client.NewMechainClient(rpcAddr, evmRpcAddr, chainID, client.WithKeyManager(operatorKM))
+	operatorClient, err := client.NewMechainClient(rpcAddr, evmRpcAddr, chainID,
+		client.WithKeyManager(newBackendKeyManager(txSigners[SignOperator])))
 	if err != nil {
 		log.Errorw("failed to new operator mechain client", "error", err)
 		return nil, err
@@ -125,18 +130,8 @@ func NewMechainChainSignClient(rpcAddr, evmRpcAddr, chainID string, gasInfo map[
 		return nil, err
 	}
 
-	blsKM, err := keys.NewBlsPrivateKeyManager(blsPrivKey)
-	if err != nil {
-		log.Errorw("failed to new bls private key manager", "error", err)
-		return nil, err
-	}
-
-	sealKM, err := keys.NewPrivateKeyManager(sealPrivateKey)
-	if err != nil {
-		log.Errorw("failed to new seal private key manager", "error", err)
-		return nil, err
-	}
-	sealClient, err := client.NewMechainClient(rpcAddr, evmRpcAddr, chainID, client.WithKeyManager(sealKM))
+	sealClient, err := client.NewMechainClient(rpcAddr, evmRpcAddr, chainID,
+		client.WithKeyManager(newBackendKeyManager(txSigners[SignSeal])))
 	if err != nil {
 		log.Errorw("failed to new seal mechain client", "error", err)
 		return nil, err
@@ -147,23 +142,15 @@ func NewMechainChainSignClient(rpcAddr, evmRpcAddr, chainID string, gasInfo map[
 		return nil, err
 	}
 
-	approvalKM, err := keys.NewPrivateKeyManager(approvalPrivateKey)
-	if err != nil {
-		log.Errorw("failed to new approval private key manager", "error", err)
-		return nil, err
-	}
-	approvalClient, err := client.NewMechainClient(rpcAddr, evmRpcAddr, chainID, client.WithKeyManager(approvalKM))
+	approvalClient, err := client.NewMechainClient(rpcAddr, evmRpcAddr, chainID,
+		client.WithKeyManager(newBackendKeyManager(txSigners[SignApproval])))
 	if err != nil {
 		log.Errorw("failed to new approval mechain client", "error", err)
 		return nil, err
 	}
 
-	gcKM, err := keys.NewPrivateKeyManager(gcPrivateKey)
-	if err != nil {
-		log.Errorw("failed to new gc private key manager", "error", err)
-		return nil, err
-	}
-	gcClient, err := client.NewMechainClient(rpcAddr, evmRpcAddr, chainID, client.WithKeyManager(gcKM))
+	gcClient, err := client.NewMechainClient(rpcAddr, evmRpcAddr, chainID,
+		client.WithKeyManager(newBackendKeyManager(txSigners[SignGc])))
 	if err != nil {
 		log.Errorw("failed to new gc mechain client", "error", err)
 		return nil, err
@@ -174,13 +161,6 @@ func NewMechainChainSignClient(rpcAddr, evmRpcAddr, chainID string, gasInfo map[
 		return nil, err
 	}
 
-	privateKeys := map[SignType]string{
-		SignOperator: operatorPrivateKey,
-		SignSeal:     sealPrivateKey,
-		SignApproval: approvalPrivateKey,
-		SignGc:       gcPrivateKey,
-	}
-
 	mechainClients := map[SignType]*client.MechainClient{
 		SignOperator: operatorClient,
 		SignSeal:     sealClient,
@@ -191,11 +171,11 @@ func NewMechainChainSignClient(rpcAddr, evmRpcAddr, chainID string, gasInfo map[
 	return &MechainChainSignClient{
 		gasInfo:          gasInfo,
 		mechainClients:   mechainClients,
-		privateKeys:      privateKeys,
+		txSigners:        txSigners,
 		sealAccNonce:     sealAccNonce,
 		gcAccNonce:       gcAccNonce,
 		operatorAccNonce: operatorAccNonce,
-		blsKm:            blsKM,
+		blsSigner:        blsSigner,
 		evmClient:        evmClient,
 	}, nil
 }
@@ -218,8 +198,17 @@ func (client *MechainChainSignClient) Sign(scope SignType, msg []byte) ([]byte,
 	return km.Sign(msg)
 }
 
-// VerifySignature verifies the signature.
+// VerifySignature verifies the signature, the signature of the previous key is still valid during the grace
+// period after the key is rotated.
 func (client *MechainChainSignClient) VerifySignature(scope SignType, msg, sig []byte) bool {
+	if rotating, ok := client.txSigners[scope].(*rotatingSigner); ok {
+		for _, signer := range rotating.verifiers(time.Now()) {
+			if types.VerifySignature(secp256k1Address(signer.PublicKey()), crypto.Keccak256(msg), sig) == nil {
+				return true
+			}
+		}
+		return false
+	}
 	km, err := client.mechainClients[scope].GetKeyManager()
 	if err != nil {
 		return false
@@ -227,6 +216,27 @@ func (client *MechainChainSignClient) VerifySignature(scope SignType, msg, sig [
 	return types.VerifySignature(km.GetAddr(), crypto.Keccak256(msg), sig) == nil
 }
 
+// switchKey promotes the rotated key, the seal key is promoted under the seal lock so that no seal tx is signed
+// during the switch, and the nonce is reset to the nonce of the new seal account.
+func (client *MechainChainSignClient) switchKey(ctx context.Context, keyID string, promote func() bool) {
+	if keyID != SealKeyID {
+		promote()
+		return
+	}
+	client.sealLock.Lock()
+	defer client.sealLock.Unlock()
+	if !promote() {
+		return
+	}
+	nonce, err := client.mechainClients[SignSeal].GetNonce(ctx)
+	if err != nil {
+		// the nonce is reset by the next sequence mismatch
+		log.CtxErrorw(ctx, "failed to get the nonce of the rotated seal account", "error", err)
+		return
+	}
+	client.sealAccNonce = nonce
+}
+
 // SealObject seal the object on the mechain chain.
 func (client *MechainChainSignClient) SealObject(ctx context.Context, scope SignType,
 	sealObject *storagetypes.MsgSealObject,
@@ -243,12 +253,15 @@ func (client *MechainChainSignClient) SealObject(ctx context.Context, scope Sign
 		return "", ErrSignMsg
 	}
 
-	client.sealLock.Lock()
-	defer client.sealLock.Unlock()
-
 	msgSealObject := storagetypes.NewMsgSealObject(km.GetAddr(),
 		sealObject.GetBucketName(), sealObject.GetObjectName(), sealObject.GetGlobalVirtualGroupId(),
 		sealObject.GetSecondarySpBlsAggSignatures())
+	if client.sealPool != nil {
+		return client.sealPool.broadcastMsg(ctx, msgSealObject, client.gasInfo[Seal], ErrSealObjectOnChain)
+	}
+
+	client.sealLock.Lock()
+	defer client.sealLock.Unlock()
 
 	mode := tx.BroadcastMode_BROADCAST_MODE_SYNC
 
@@ -334,18 +347,24 @@ func (client *MechainChainSignClient) SealObjectEvm(ctx context.Context, scope S
 	for i := 0; i < BroadcastTxRetry; i++ {
 		nonce = client.sealAccNonce
 
-		txOpts, err := CreateTxOpts(ctx, client.evmClient, client.privateKeys[SignSeal], chainId, client.gasInfo[Seal].GasLimit, nonce)
+		evmClient := client.evmTxClient()
+		txOpts, err := CreateTxOpts(ctx, evmClient, client.txSigners[SignSeal], chainId, client.gasInfo[Seal].GasLimit, nonce)
 		if err != nil {
 			log.CtxErrorw(ctx, "failed to create tx opts", "error", err)
 			return "", err
 		}
 
-		session, err := CreateStorageSession(client.evmClient, *txOpts, types.StorageAddress)
+		session, err := CreateStorageSession(evmClient, *txOpts, types.StorageAddress)
 		if err != nil {
 			log.CtxErrorw(ctx, "failed to create session", "error", err)
 			return "", err
 		}
 
+		if err = client.spendEvmFee(SignSeal, txOpts); err != nil {
+			log.CtxErrorw(ctx, "failed to spend the fee budget", "error", err)
+			ErrSealObjectOnChain.SetError(fmt.Errorf("failed to spend the fee budget, error: %v", err))
+			return "", ErrSealObjectOnChain
+		}
 		txRsp, err := session.SealObject(
 			ethcmn.BytesToAddress(km.GetAddr().Bytes()),
 			sealObject.GetBucketName(),
@@ -355,6 +374,7 @@ func (client *MechainChainSignClient) SealObjectEvm(ctx context.Context, scope S
 		)
 
 		if err != nil {
+			client.refundEvmFee(SignSeal, txOpts)
 			if strings.Contains(err.Error(), "invalid nonce") {
 				// if nonce mismatch, wait for next block, reset nonce by querying the nonce on chain
 				nonce, nonceErr = client.getNonceOnChain(ctx, client.mechainClients[scope])
@@ -396,10 +416,14 @@ func (client *MechainChainSignClient) RejectUnSealObject(ctx context.Context, sc
 		return "", ErrSignMsg
 	}
 
+	msgRejectUnSealObject := storagetypes.NewMsgRejectUnsealedObject(km.GetAddr(), rejectObject.GetBucketName(), rejectObject.GetObjectName())
+	if client.sealPool != nil {
+		return client.sealPool.broadcastMsg(ctx, msgRejectUnSealObject, client.gasInfo[RejectSeal], ErrRejectUnSealObjectOnChain)
+	}
+
 	client.sealLock.Lock()
 	defer client.sealLock.Unlock()
 
-	msgRejectUnSealObject := storagetypes.NewMsgRejectUnsealedObject(km.GetAddr(), rejectObject.GetBucketName(), rejectObject.GetObjectName())
 	mode := tx.BroadcastMode_BROADCAST_MODE_SYNC
 
 	var (
@@ -481,23 +505,30 @@ func (client *MechainChainSignClient) RejectUnSealObjectEvm(ctx context.Context,
 
 	for i := 0; i < BroadcastTxRetry; i++ {
 		nonce = client.sealAccNonce
-		txOpts, err := CreateTxOpts(ctx, client.evmClient, client.privateKeys[scope], chainId, client.gasInfo[RejectSeal].GasLimit, nonce)
+		evmClient := client.evmTxClient()
+		txOpts, err := CreateTxOpts(ctx, evmClient, client.txSigners[scope], chainId, client.gasInfo[RejectSeal].GasLimit, nonce)
 		if err != nil {
 			log.CtxErrorw(ctx, "failed to create tx opts", "error", err)
 			return "", err
 		}
 
-		session, err := CreateStorageSession(client.evmClient, *txOpts, types.StorageAddress)
+		session, err := CreateStorageSession(evmClient, *txOpts, types.StorageAddress)
 		if err != nil {
 			log.CtxErrorw(ctx, "failed to create session", "error", err)
 			return "", err
 		}
 
+		if err = client.spendEvmFee(scope, txOpts); err != nil {
+			log.CtxErrorw(ctx, "failed to spend the fee budget", "error", err)
+			ErrRejectUnSealObjectOnChain.SetError(fmt.Errorf("failed to spend the fee budget, error: %v", err))
+			return "", ErrRejectUnSealObjectOnChain
+		}
 		txRsp, err := session.RejectSealObject(
 			rejectObject.GetBucketName(),
 			rejectObject.GetObjectName(),
 		)
 		if err != nil {
+			client.refundEvmFee(scope, txOpts)
 			if strings.Contains(err.Error(), "invalid nonce") {
 				// if nonce mismatch, wait for next block, reset nonce by querying the nonce on chain
 				nonce, nonceErr = client.getNonceOnChain(ctx, client.mechainClients[scope])
@@ -590,24 +621,31 @@ func (client *MechainChainSignClient) DiscontinueBucketEvm(ctx context.Context,
 	msgDiscontinueBucket := storagetypes.NewMsgDiscontinueBucket(km.GetAddr(),
 		discontinueBucket.BucketName, discontinueBucket.Reason)
 
-	txOpts, err := CreateTxOpts(ctx, client.evmClient, client.privateKeys[scope], chainId, client.gasInfo[DiscontinueBucket].GasLimit, nonce)
+	evmClient := client.evmTxClient()
+	txOpts, err := CreateTxOpts(ctx, evmClient, client.txSigners[scope], chainId, client.gasInfo[DiscontinueBucket].GasLimit, nonce)
 	if err != nil {
 		log.CtxErrorw(ctx, "failed to create tx opts", "error", err)
 		return "", err
 	}
 
-	session, err := CreateStorageSession(client.evmClient, *txOpts, types.StorageAddress)
+	session, err := CreateStorageSession(evmClient, *txOpts, types.StorageAddress)
 	if err != nil {
 		log.CtxErrorw(ctx, "failed to create session", "error", err)
 		return "", err
 	}
 
+	if err = client.spendEvmFee(scope, txOpts); err != nil {
+		log.CtxErrorw(ctx, "failed to spend the fee budget", "error", err)
+		ErrDiscontinueBucketOnChain.SetError(fmt.Errorf("failed to spend the fee budget, error: %v", err))
+		return "", ErrDiscontinueBucketOnChain
+	}
 	txRsp, err := session.DiscontinueBucket(
 		discontinueBucket.GetBucketName(),
 		discontinueBucket.GetReason(),
 	)
 
 	if err != nil {
+		client.refundEvmFee(scope, txOpts)
 		if strings.Contains(err.Error(), "invalid nonce") {
 			// if nonce mismatch, wait for next block, reset nonce by querying the nonce on chain
 			nonce, nonceErr := client.getNonceOnChain(ctx, client.mechainClients[scope])
@@ -641,11 +679,15 @@ func (client *MechainChainSignClient) CreateGlobalVirtualGroup(ctx context.Conte
 		return "", ErrSignMsg
 	}
 
+	msgCreateGlobalVirtualGroup := virtualgrouptypes.NewMsgCreateGlobalVirtualGroup(km.GetAddr(),
+		gvg.FamilyId, gvg.GetSecondarySpIds(), gvg.GetDeposit())
+	if client.sealPool != nil {
+		return client.sealPool.broadcastMsg(ctx, msgCreateGlobalVirtualGroup, client.gasInfo[CreateGlobalVirtualGroup], ErrCreateGVGOnChain)
+	}
+
 	client.opLock.Lock()
 	defer client.opLock.Unlock()
 
-	msgCreateGlobalVirtualGroup := virtualgrouptypes.NewMsgCreateGlobalVirtualGroup(km.GetAddr(),
-		gvg.FamilyId, gvg.GetSecondarySpIds(), gvg.GetDeposit())
 	mode := tx.BroadcastMode_BROADCAST_MODE_SYNC
 
 	var (
@@ -725,13 +767,14 @@ func (client *MechainChainSignClient) CreateGlobalVirtualGroupEvm(ctx context.Co
 	)
 	for i := 0; i < BroadcastTxRetry; i++ {
 		nonce = client.operatorAccNonce
-		txOpts, err := CreateTxOpts(ctx, client.evmClient, client.privateKeys[scope], chainId, client.gasInfo[CreateGlobalVirtualGroup].GasLimit, nonce)
+		evmClient := client.evmTxClient()
+		txOpts, err := CreateTxOpts(ctx, evmClient, client.txSigners[scope], chainId, client.gasInfo[CreateGlobalVirtualGroup].GasLimit, nonce)
 		if err != nil {
 			log.CtxErrorw(ctx, "failed to create tx opts", "error", err)
 			return "", err
 		}
 
-		session, err := CreateVirtualGroupSession(client.evmClient, *txOpts, types.VirtualGroupAddress)
+		session, err := CreateVirtualGroupSession(evmClient, *txOpts, types.VirtualGroupAddress)
 		if err != nil {
 			log.CtxErrorw(ctx, "failed to create session", "error", err)
 			return "", err
@@ -741,6 +784,11 @@ func (client *MechainChainSignClient) CreateGlobalVirtualGroupEvm(ctx context.Co
 			Denom:  gvg.GetDeposit().Denom,
 			Amount: gvg.GetDeposit().Amount.BigInt(),
 		}
+		if err = client.spendEvmFee(scope, txOpts); err != nil {
+			log.CtxErrorw(ctx, "failed to spend the fee budget", "error", err)
+			ErrCreateGVGOnChain.SetError(fmt.Errorf("failed to spend the fee budget, error: %v", err))
+			return "", ErrCreateGVGOnChain
+		}
 		txRsp, err := session.CreateGlobalVirtualGroup(
 			gvg.FamilyId,
 			gvg.GetSecondarySpIds(),
@@ -748,6 +796,7 @@ func (client *MechainChainSignClient) CreateGlobalVirtualGroupEvm(ctx context.Co
 		)
 
 		if err != nil {
+			client.refundEvmFee(scope, txOpts)
 			if strings.Contains(err.Error(), "invalid nonce") {
 				// if nonce mismatches, waiting for next block, reset nonce by querying the nonce on chain
 				nonce, nonceErr = client.getNonceOnChain(ctx, client.mechainClients[scope])
@@ -868,13 +917,14 @@ func (client *MechainChainSignClient) CompleteMigrateBucketEvm(ctx context.Conte
 	)
 	for i := 0; i < BroadcastTxRetry; i++ {
 		nonce = client.operatorAccNonce
-		txOpts, err := CreateTxOpts(ctx, client.evmClient, client.privateKeys[scope], chainId, client.gasInfo[CompleteMigrateBucket].GasLimit, nonce)
+		evmClient := client.evmTxClient()
+		txOpts, err := CreateTxOpts(ctx, evmClient, client.txSigners[scope], chainId, client.gasInfo[CompleteMigrateBucket].GasLimit, nonce)
 		if err != nil {
 			log.CtxErrorw(ctx, "failed to create tx opts", "error", err)
 			return "", err
 		}
 
-		session, err := CreateStorageSession(client.evmClient, *txOpts, types.StorageAddress)
+		session, err := CreateStorageSession(evmClient, *txOpts, types.StorageAddress)
 		if err != nil {
 			log.CtxErrorw(ctx, "failed to create session", "error", err)
 			return "", err
@@ -892,6 +942,11 @@ func (client *MechainChainSignClient) CompleteMigrateBucketEvm(ctx context.Conte
 			}
 		}
 
+		if err = client.spendEvmFee(scope, txOpts); err != nil {
+			log.CtxErrorw(ctx, "failed to spend the fee budget", "error", err)
+			ErrCompleteMigrateBucketOnChain.SetError(fmt.Errorf("failed to spend the fee budget, error: %v", err))
+			return "", ErrCompleteMigrateBucketOnChain
+		}
 		txRsp, err := session.CompleteMigrateBucket(
 			migrateBucket.GetBucketName(),
 			migrateBucket.GetGlobalVirtualGroupFamilyId(),
@@ -899,6 +954,7 @@ func (client *MechainChainSignClient) CompleteMigrateBucketEvm(ctx context.Conte
 		)
 
 		if err != nil {
+			client.refundEvmFee(scope, txOpts)
 			if strings.Contains(err.Error(), "invalid nonce") {
 				// if nonce mismatches, waiting for next block, reset nonce by querying the nonce on chain
 				nonce, nonceErr = client.getNonceOnChain(ctx, client.mechainClients[scope])
@@ -1012,18 +1068,24 @@ func (client *MechainChainSignClient) UpdateSPPriceEvm(ctx context.Context, scop
 		FreeReadQuota: priceInfo.FreeReadQuota,
 		StorePrice:    priceInfo.StorePrice,
 	}
-	txOpts, err := CreateTxOpts(ctx, client.evmClient, client.privateKeys[scope], chainId, client.gasInfo[UpdateSPPrice].GasLimit, nonce)
+	evmClient := client.evmTxClient()
+	txOpts, err := CreateTxOpts(ctx, evmClient, client.txSigners[scope], chainId, client.gasInfo[UpdateSPPrice].GasLimit, nonce)
 	if err != nil {
 		log.CtxErrorw(ctx, "failed to create tx opts", "error", err)
 		return "", err
 	}
 
-	session, err := CreateStorageProviderSession(client.evmClient, *txOpts, types.SpAddress)
+	session, err := CreateStorageProviderSession(evmClient, *txOpts, types.SpAddress)
 	if err != nil {
 		log.CtxErrorw(ctx, "failed to create session", "error", err)
 		return "", err
 	}
 
+	if err = client.spendEvmFee(scope, txOpts); err != nil {
+		log.CtxErrorw(ctx, "failed to spend the fee budget", "error", err)
+		ErrUpdateSPPriceOnChain.SetError(fmt.Errorf("failed to spend the fee budget, error: %v", err))
+		return "", ErrUpdateSPPriceOnChain
+	}
 	txRsp, err := session.UpdateSPPrice(
 		priceInfo.ReadPrice.BigInt(),
 		priceInfo.FreeReadQuota,
@@ -1031,6 +1093,7 @@ func (client *MechainChainSignClient) UpdateSPPriceEvm(ctx context.Context, scop
 	)
 
 	if err != nil {
+		client.refundEvmFee(scope, txOpts)
 		if strings.Contains(err.Error(), "invalid nonce") {
 			// if nonce mismatches, waiting for next block, reset nonce by querying the nonce on chain
 			nonce, nonceErr := client.getNonceOnChain(ctx, client.mechainClients[scope])
@@ -1159,13 +1222,14 @@ func (client *MechainChainSignClient) SwapOutEvm(ctx context.Context, scope Sign
 
 	for i := 0; i < BroadcastTxRetry; i++ {
 		nonce = client.operatorAccNonce
-		txOpts, err := CreateTxOpts(ctx, client.evmClient, client.privateKeys[scope], chainId, client.gasInfo[SwapOut].GasLimit, nonce)
+		evmClient := client.evmTxClient()
+		txOpts, err := CreateTxOpts(ctx, evmClient, client.txSigners[scope], chainId, client.gasInfo[SwapOut].GasLimit, nonce)
 		if err != nil {
 			log.CtxErrorw(ctx, "failed to create tx opts", "error", err)
 			return "", err
 		}
 
-		session, err := CreateVirtualGroupSession(client.evmClient, *txOpts, types.VirtualGroupAddress)
+		session, err := CreateVirtualGroupSession(evmClient, *txOpts, types.VirtualGroupAddress)
 		if err != nil {
 			log.CtxErrorw(ctx, "failed to create session", "error", err)
 			return "", err
@@ -1176,6 +1240,11 @@ func (client *MechainChainSignClient) SwapOutEvm(ctx context.Context, scope Sign
 			GlobalVirtualGroupFamilyId: msgSwapOut.SuccessorSpApproval.GlobalVirtualGroupFamilyId,
 			Sig:                        msgSwapOut.SuccessorSpApproval.Sig,
 		}
+		if err = client.spendEvmFee(scope, txOpts); err != nil {
+			log.CtxErrorw(ctx, "failed to spend the fee budget", "error", err)
+			ErrSwapOutOnChain.SetError(fmt.Errorf("failed to spend the fee budget, error: %v", err))
+			return "", ErrSwapOutOnChain
+		}
 		txRsp, err := session.SwapOut(
 			swapOut.GetGlobalVirtualGroupFamilyId(),
 			swapOut.GetGlobalVirtualGroupIds(),
@@ -1184,6 +1253,7 @@ func (client *MechainChainSignClient) SwapOutEvm(ctx context.Context, scope Sign
 		)
 
 		if err != nil {
+			client.refundEvmFee(scope, txOpts)
 			if strings.Contains(err.Error(), "invalid nonce") {
 				// if nonce mismatches, waiting for next block, reset nonce by querying the nonce on chain
 				nonce, nonceErr = client.getNonceOnChain(ctx, client.mechainClients[scope])
@@ -1304,24 +1374,31 @@ func (client *MechainChainSignClient) CompleteSwapOutEvm(ctx context.Context, sc
 
 	for i := 0; i < BroadcastTxRetry; i++ {
 		nonce = client.operatorAccNonce
-		txOpts, err := CreateTxOpts(ctx, client.evmClient, client.privateKeys[scope], chainId, client.gasInfo[CompleteSwapOut].GasLimit, nonce)
+		evmClient := client.evmTxClient()
+		txOpts, err := CreateTxOpts(ctx, evmClient, client.txSigners[scope], chainId, client.gasInfo[CompleteSwapOut].GasLimit, nonce)
 		if err != nil {
 			log.CtxErrorw(ctx, "failed to create tx opts", "error", err)
 			return "", err
 		}
 
-		session, err := CreateVirtualGroupSession(client.evmClient, *txOpts, types.VirtualGroupAddress)
+		session, err := CreateVirtualGroupSession(evmClient, *txOpts, types.VirtualGroupAddress)
 		if err != nil {
 			log.CtxErrorw(ctx, "failed to create session", "error", err)
 			return "", err
 		}
 
+		if err = client.spendEvmFee(scope, txOpts); err != nil {
+			log.CtxErrorw(ctx, "failed to spend the fee budget", "error", err)
+			ErrCompleteSwapOutOnChain.SetError(fmt.Errorf("failed to spend the fee budget, error: %v", err))
+			return "", ErrCompleteSwapOutOnChain
+		}
 		txRsp, err := session.CompleteSwapOut(
 			completeSwapOut.GetGlobalVirtualGroupFamilyId(),
 			completeSwapOut.GetGlobalVirtualGroupIds(),
 		)
 
 		if err != nil {
+			client.refundEvmFee(scope, txOpts)
 			if strings.Contains(err.Error(), "invalid nonce") {
 				// if nonce mismatches, waiting for next block, reset nonce by querying the nonce on chain
 				nonce, nonceErr = client.getNonceOnChain(ctx, client.mechainClients[scope])
@@ -1439,21 +1516,28 @@ func (client *MechainChainSignClient) SPExitEvm(ctx context.Context, scope SignT
 
 	for i := 0; i < BroadcastTxRetry; i++ {
 		nonce = client.operatorAccNonce
-		txOpts, err := CreateTxOpts(ctx, client.evmClient, client.privateKeys[scope], chainId, client.gasInfo[SPExit].GasLimit, nonce)
+		evmClient := client.evmTxClient()
+		txOpts, err := CreateTxOpts(ctx, evmClient, client.txSigners[scope], chainId, client.gasInfo[SPExit].GasLimit, nonce)
 		if err != nil {
 			log.CtxErrorw(ctx, "failed to create tx opts", "error", err)
 			return "", err
 		}
 
-		session, err := CreateVirtualGroupSession(client.evmClient, *txOpts, types.VirtualGroupAddress)
+		session, err := CreateVirtualGroupSession(evmClient, *txOpts, types.VirtualGroupAddress)
 		if err != nil {
 			log.CtxErrorw(ctx, "failed to create session", "error", err)
 			return "", err
 		}
 
+		if err = client.spendEvmFee(scope, txOpts); err != nil {
+			log.CtxErrorw(ctx, "failed to spend the fee budget", "error", err)
+			ErrSPExitOnChain.SetError(fmt.Errorf("failed to spend the fee budget, error: %v", err))
+			return "", ErrSPExitOnChain
+		}
 		txRsp, err := session.SpExit()
 
 		if err != nil {
+			client.refundEvmFee(scope, txOpts)
 			if strings.Contains(err.Error(), "invalid nonce") {
 				// if nonce mismatches, waiting for next block, reset nonce by querying the nonce on chain
 				nonce, nonceErr = client.getNonceOnChain(ctx, client.mechainClients[scope])
@@ -1566,24 +1650,31 @@ func (client *MechainChainSignClient) CompleteSPExitEvm(ctx context.Context, sco
 	)
 	for i := 0; i < BroadcastTxRetry; i++ {
 		nonce = client.operatorAccNonce
-		txOpts, err := CreateTxOpts(ctx, client.evmClient, client.privateKeys[scope], chainId, client.gasInfo[CompleteSPExit].GasLimit, nonce)
+		evmClient := client.evmTxClient()
+		txOpts, err := CreateTxOpts(ctx, evmClient, client.txSigners[scope], chainId, client.gasInfo[CompleteSPExit].GasLimit, nonce)
 		if err != nil {
 			log.CtxErrorw(ctx, "failed to create tx opts", "error", err)
 			return "", err
 		}
 
-		session, err := CreateVirtualGroupSession(client.evmClient, *txOpts, types.VirtualGroupAddress)
+		session, err := CreateVirtualGroupSession(evmClient, *txOpts, types.VirtualGroupAddress)
 		if err != nil {
 			log.CtxErrorw(ctx, "failed to create session", "error", err)
 			return "", err
 		}
 
+		if err = client.spendEvmFee(scope, txOpts); err != nil {
+			log.CtxErrorw(ctx, "failed to spend the fee budget", "error", err)
+			ErrCompleteSPExitOnChain.SetError(fmt.Errorf("failed to spend the fee budget, error: %v", err))
+			return "", ErrCompleteSPExitOnChain
+		}
 		txRsp, err := session.CompleteSPExit(
 			completeSPExit.StorageProvider,
 			completeSPExit.Operator,
 		)
 
 		if err != nil {
+			client.refundEvmFee(scope, txOpts)
 			if strings.Contains(err.Error(), "invalid nonce") {
 				// if nonce mismatches, waiting for next block, reset nonce by querying the nonce on chain
 				nonce, nonceErr = client.getNonceOnChain(ctx, client.mechainClients[scope])
@@ -1696,23 +1787,30 @@ func (client *MechainChainSignClient) RejectMigrateBucketEvm(ctx context.Context
 	)
 	for i := 0; i < BroadcastTxRetry; i++ {
 		nonce = client.operatorAccNonce
-		txOpts, err := CreateTxOpts(ctx, client.evmClient, client.privateKeys[scope], chainId, client.gasInfo[RejectMigrateBucket].GasLimit, nonce)
+		evmClient := client.evmTxClient()
+		txOpts, err := CreateTxOpts(ctx, evmClient, client.txSigners[scope], chainId, client.gasInfo[RejectMigrateBucket].GasLimit, nonce)
 		if err != nil {
 			log.CtxErrorw(ctx, "failed to create tx opts", "error", err)
 			return "", err
 		}
 
-		session, err := CreateStorageSession(client.evmClient, *txOpts, types.StorageAddress)
+		session, err := CreateStorageSession(evmClient, *txOpts, types.StorageAddress)
 		if err != nil {
 			log.CtxErrorw(ctx, "failed to create session", "error", err)
 			return "", err
 		}
 
+		if err = client.spendEvmFee(scope, txOpts); err != nil {
+			log.CtxErrorw(ctx, "failed to spend the fee budget", "error", err)
+			ErrRejectMigrateBucketOnChain.SetError(fmt.Errorf("failed to spend the fee budget, error: %v", err))
+			return "", ErrRejectMigrateBucketOnChain
+		}
 		txRsp, err := session.RejectMigrateBucket(
 			msg.GetBucketName(),
 		)
 
 		if err != nil {
+			client.refundEvmFee(scope, txOpts)
 			if strings.Contains(err.Error(), "invalid nonce") {
 				// if nonce mismatches, waiting for next block, reset nonce by querying the nonce on chain
 				nonce, nonceErr = client.getNonceOnChain(ctx, client.mechainClients[scope])
@@ -1826,13 +1924,14 @@ func (client *MechainChainSignClient) DepositEvm(ctx context.Context, scope Sign
 	)
 	for i := 0; i < BroadcastTxRetry; i++ {
 		nonce = client.operatorAccNonce
-		txOpts, err := CreateTxOpts(ctx, client.evmClient, client.privateKeys[scope], chainId, client.gasInfo[Deposit].GasLimit, nonce)
+		evmClient := client.evmTxClient()
+		txOpts, err := CreateTxOpts(ctx, evmClient, client.txSigners[scope], chainId, client.gasInfo[Deposit].GasLimit, nonce)
 		if err != nil {
 			log.CtxErrorw(ctx, "failed to create tx opts", "error", err)
 			return "", err
 		}
 
-		session, err := CreateVirtualGroupSession(client.evmClient, *txOpts, types.VirtualGroupAddress)
+		session, err := CreateVirtualGroupSession(evmClient, *txOpts, types.VirtualGroupAddress)
 		if err != nil {
 			log.CtxErrorw(ctx, "failed to create session", "error", err)
 			return "", err
@@ -1842,12 +1941,18 @@ func (client *MechainChainSignClient) DepositEvm(ctx context.Context, scope Sign
 			Denom:  msg.Deposit.Denom,
 			Amount: msg.Deposit.Amount.BigInt(),
 		}
+		if err = client.spendEvmFee(scope, txOpts); err != nil {
+			log.CtxErrorw(ctx, "failed to spend the fee budget", "error", err)
+			ErrDepositOnChain.SetError(fmt.Errorf("failed to spend the fee budget, error: %v", err))
+			return "", ErrDepositOnChain
+		}
 		txRsp, err := session.Deposit(
 			msg.GlobalVirtualGroupId,
 			deposit,
 		)
 
 		if err != nil {
+			client.refundEvmFee(scope, txOpts)
 			if strings.Contains(err.Error(), "invalid nonce") {
 				// if nonce mismatches, waiting for next block, reset nonce by querying the nonce on chain
 				nonce, nonceErr = client.getNonceOnChain(ctx, client.mechainClients[scope])
@@ -1961,23 +2066,30 @@ func (client *MechainChainSignClient) DeleteGlobalVirtualGroupEvm(ctx context.Co
 	)
 	for i := 0; i < BroadcastTxRetry; i++ {
 		nonce = client.operatorAccNonce
-		txOpts, err := CreateTxOpts(ctx, client.evmClient, client.privateKeys[scope], chainId, client.gasInfo[DeleteGlobalVirtualGroup].GasLimit, nonce)
+		evmClient := client.evmTxClient()
+		txOpts, err := CreateTxOpts(ctx, evmClient, client.txSigners[scope], chainId, client.gasInfo[DeleteGlobalVirtualGroup].GasLimit, nonce)
 		if err != nil {
 			log.CtxErrorw(ctx, "failed to create tx opts", "error", err)
 			return "", err
 		}
 
-		session, err := CreateVirtualGroupSession(client.evmClient, *txOpts, types.VirtualGroupAddress)
+		session, err := CreateVirtualGroupSession(evmClient, *txOpts, types.VirtualGroupAddress)
 		if err != nil {
 			log.CtxErrorw(ctx, "failed to create session", "error", err)
 			return "", err
 		}
 
+		if err = client.spendEvmFee(scope, txOpts); err != nil {
+			log.CtxErrorw(ctx, "failed to spend the fee budget", "error", err)
+			ErrDeleteGVGOnChain.SetError(fmt.Errorf("failed to spend the fee budget, error: %v", err))
+			return "", ErrDeleteGVGOnChain
+		}
 		txRsp, err := session.DeleteGlobalVirtualGroup(
 			msg.GetGlobalVirtualGroupId(),
 		)
 
 		if err != nil {
+			client.refundEvmFee(scope, txOpts)
 			if strings.Contains(err.Error(), "invalid nonce") {
 				// if nonce mismatches, waiting for next block, reset nonce by querying the nonce on chain
 				nonce, nonceErr = client.getNonceOnChain(ctx, client.mechainClients[scope])
@@ -2088,13 +2200,14 @@ func (client *MechainChainSignClient) DelegateCreateObjectEvm(ctx context.Contex
 	)
 	for i := 0; i < BroadcastTxRetry; i++ {
 		nonce = client.operatorAccNonce
-		txOpts, err := CreateTxOpts(ctx, client.evmClient, client.privateKeys[scope], chainId, client.gasInfo[DelegateCreateObject].GasLimit, nonce)
+		evmClient := client.evmTxClient()
+		txOpts, err := CreateTxOpts(ctx, evmClient, client.txSigners[scope], chainId, client.gasInfo[DelegateCreateObject].GasLimit, nonce)
 		if err != nil {
 			log.CtxErrorw(ctx, "failed to create tx opts", "error", err)
 			return "", err
 		}
 
-		session, err := CreateStorageSession(client.evmClient, *txOpts, types.StorageAddress)
+		session, err := CreateStorageSession(evmClient, *txOpts, types.StorageAddress)
 		if err != nil {
 			log.CtxErrorw(ctx, "failed to create session", "error", err)
 			return "", err
@@ -2105,6 +2218,11 @@ func (client *MechainChainSignClient) DelegateCreateObjectEvm(ctx context.Contex
 			checksumStr := base64.StdEncoding.EncodeToString(checksum)
 			expectChecksums = append(expectChecksums, checksumStr)
 		}
+		if err = client.spendEvmFee(scope, txOpts); err != nil {
+			log.CtxErrorw(ctx, "failed to spend the fee budget", "error", err)
+			ErrDelegateCreateObjectOnChain.SetError(fmt.Errorf("failed to spend the fee budget, error: %v", err))
+			return "", ErrDelegateCreateObjectOnChain
+		}
 		txRsp, err := session.DelegateCreateObject(
 			msg.Creator,
 			msg.BucketName,
@@ -2117,6 +2235,7 @@ func (client *MechainChainSignClient) DelegateCreateObjectEvm(ctx context.Contex
 		)
 
 		if err != nil {
+			client.refundEvmFee(scope, txOpts)
 			if strings.Contains(err.Error(), "invalid nonce") {
 				// if nonce mismatches, waiting for next block, reset nonce by querying the nonce on chain
 				nonce, nonceErr = client.getNonceOnChain(ctx, client.mechainClients[scope])
@@ -2227,13 +2346,14 @@ func (client *MechainChainSignClient) DelegateUpdateObjectContentEvm(ctx context
 	)
 	for i := 0; i < BroadcastTxRetry; i++ {
 		nonce = client.operatorAccNonce
-		txOpts, err := CreateTxOpts(ctx, client.evmClient, client.privateKeys[scope], chainId, client.gasInfo[DelegateUpdateObjectContent].GasLimit, nonce)
+		evmClient := client.evmTxClient()
+		txOpts, err := CreateTxOpts(ctx, evmClient, client.txSigners[scope], chainId, client.gasInfo[DelegateUpdateObjectContent].GasLimit, nonce)
 		if err != nil {
 			log.CtxErrorw(ctx, "failed to create tx opts", "error", err)
 			return "", err
 		}
 
-		session, err := CreateStorageSession(client.evmClient, *txOpts, types.StorageAddress)
+		session, err := CreateStorageSession(evmClient, *txOpts, types.StorageAddress)
 		if err != nil {
 			log.CtxErrorw(ctx, "failed to create session", "error", err)
 			return "", err
@@ -2244,6 +2364,11 @@ func (client *MechainChainSignClient) DelegateUpdateObjectContentEvm(ctx context
 			checksumStr := base64.StdEncoding.EncodeToString(checksum)
 			expectChecksums = append(expectChecksums, checksumStr)
 		}
+		if err = client.spendEvmFee(scope, txOpts); err != nil {
+			log.CtxErrorw(ctx, "failed to spend the fee budget", "error", err)
+			ErrDelegateUpdateObjectContentOnChain.SetError(fmt.Errorf("failed to spend the fee budget, error: %v", err))
+			return "", ErrDelegateUpdateObjectContentOnChain
+		}
 		txRsp, err := session.DelegateUpdateObjectContent(
 			msg.Updater,
 			msg.BucketName,
@@ -2254,6 +2379,7 @@ func (client *MechainChainSignClient) DelegateUpdateObjectContentEvm(ctx context
 		)
 
 		if err != nil {
+			client.refundEvmFee(scope, txOpts)
 			if strings.Contains(err.Error(), "invalid nonce") {
 				// if nonce mismatches, waiting for next block, reset nonce by querying the nonce on chain
 				nonce, nonceErr = client.getNonceOnChain(ctx, client.mechainClients[scope])
@@ -2294,8 +2420,18 @@ func (client *MechainChainSignClient) getNonceOnChain(ctx context.Context, gnfdC
 
 func (client *MechainChainSignClient) broadcastTx(ctx context.Context, gnfdClient *client.MechainClient,
 	msgs []sdk.Msg, txOpt *ctypes.TxOption, opts ...grpc.CallOption,
-) (string, error) {
-	resp, err := gnfdClient.BroadcastTx(ctx, msgs, txOpt, opts...)
+) (txHash string, err error) {
+	account := client.accountName(gnfdClient)
+	if err = applyFee(ctx, client.feeEstimator, client.feeBudget, gnfdClient, account, msgs, txOpt); err != nil {
+		return "", err
+	}
+	defer func() {
+		if err != nil && client.feeBudget != nil {
+			client.feeBudget.refund(account, txOpt.FeeAmount)
+		}
+	}()
+	resp, err := client.broadcastClients[SignType(account)].pick(client.broadcastRoute, gnfdClient).BroadcastTx(
+		ctx, msgs, txOpt, opts...)
 	if err != nil {
 		if strings.Contains(err.Error(), "account sequence mismatch") {
 			return "", sdkErrors.ErrWrongSequence
@@ -2311,6 +2447,34 @@ func (client *MechainChainSignClient) broadcastTx(ctx context.Context, gnfdClien
 	return resp.TxResponse.TxHash, nil
 }
 
+// accountName returns the account name of the chain client in the fee budget, the accounts are named by their key
+// ids, which are the same as the scopes and do not change with the key rotation.
+func (client *MechainChainSignClient) accountName(gnfdClient *client.MechainClient) string {
+	for scope, c := range client.mechainClients {
+		if c == gnfdClient {
+			return string(scope)
+		}
+	}
+	return "unknown"
+}
+
+// spendEvmFee spends the max fee of the evm tx from the daily budget of the scope account, the fee must be refunded
+// by refundEvmFee if the tx is not sent.
+func (client *MechainChainSignClient) spendEvmFee(scope SignType, txOpts *bind.TransactOpts) error {
+	if client.feeBudget == nil {
+		return nil
+	}
+	return client.feeBudget.spend(string(scope), evmFee(txOpts))
+}
+
+// refundEvmFee refunds the max fee of the evm tx which is not sent to the daily budget of the scope account.
+func (client *MechainChainSignClient) refundEvmFee(scope SignType, txOpts *bind.TransactOpts) {
+	if client.feeBudget == nil {
+		return
+	}
+	client.feeBudget.refund(string(scope), evmFee(txOpts))
+}
+
 func (client *MechainChainSignClient) ReserveSwapIn(ctx context.Context, scope SignType,
 	msg *virtualgrouptypes.MsgReserveSwapIn,
 ) (string, error) {
@@ -2400,18 +2564,24 @@ func (client *MechainChainSignClient) ReserveSwapInEvm(ctx context.Context, scop
 	)
 	for i := 0; i < BroadcastTxRetry; i++ {
 		nonce = client.operatorAccNonce
-		txOpts, err := CreateTxOpts(ctx, client.evmClient, client.privateKeys[scope], chainId, client.gasInfo[ReserveSwapIn].GasLimit, nonce)
+		evmClient := client.evmTxClient()
+		txOpts, err := CreateTxOpts(ctx, evmClient, client.txSigners[scope], chainId, client.gasInfo[ReserveSwapIn].GasLimit, nonce)
 		if err != nil {
 			log.CtxErrorw(ctx, "failed to create tx opts", "error", err)
 			return "", err
 		}
 
-		session, err := CreateVirtualGroupSession(client.evmClient, *txOpts, types.VirtualGroupAddress)
+		session, err := CreateVirtualGroupSession(evmClient, *txOpts, types.VirtualGroupAddress)
 		if err != nil {
 			log.CtxErrorw(ctx, "failed to create session", "error", err)
 			return "", err
 		}
 
+		if err = client.spendEvmFee(scope, txOpts); err != nil {
+			log.CtxErrorw(ctx, "failed to spend the fee budget", "error", err)
+			ErrReserveSwapIn.SetError(fmt.Errorf("failed to spend the fee budget, error: %v", err))
+			return "", ErrReserveSwapIn
+		}
 		txRsp, err := session.ReserveSwapIn(
 			msg.GetTargetSpId(),
 			msg.GetGlobalVirtualGroupFamilyId(),
@@ -2419,6 +2589,7 @@ func (client *MechainChainSignClient) ReserveSwapInEvm(ctx context.Context, scop
 		)
 
 		if err != nil {
+			client.refundEvmFee(scope, txOpts)
 			if strings.Contains(err.Error(), "invalid nonce") {
 				// if nonce mismatches, waiting for next block, reset nonce by querying the nonce on chain
 				nonce, nonceErr = client.getNonceOnChain(ctx, client.mechainClients[scope])
@@ -2532,24 +2703,31 @@ func (client *MechainChainSignClient) CompleteSwapInEvm(ctx context.Context, sco
 	)
 	for i := 0; i < BroadcastTxRetry; i++ {
 		nonce = client.operatorAccNonce
-		txOpts, err := CreateTxOpts(ctx, client.evmClient, client.privateKeys[scope], chainId, client.gasInfo[CompleteSwapIn].GasLimit, nonce)
+		evmClient := client.evmTxClient()
+		txOpts, err := CreateTxOpts(ctx, evmClient, client.txSigners[scope], chainId, client.gasInfo[CompleteSwapIn].GasLimit, nonce)
 		if err != nil {
 			log.CtxErrorw(ctx, "failed to create tx opts", "error", err)
 			return "", err
 		}
 
-		session, err := CreateVirtualGroupSession(client.evmClient, *txOpts, types.VirtualGroupAddress)
+		session, err := CreateVirtualGroupSession(evmClient, *txOpts, types.VirtualGroupAddress)
 		if err != nil {
 			log.CtxErrorw(ctx, "failed to create session", "error", err)
 			return "", err
 		}
 
+		if err = client.spendEvmFee(scope, txOpts); err != nil {
+			log.CtxErrorw(ctx, "failed to spend the fee budget", "error", err)
+			ErrCompleteSwapIn.SetError(fmt.Errorf("failed to spend the fee budget, error: %v", err))
+			return "", ErrCompleteSwapIn
+		}
 		txRsp, err := session.CompleteSwapIn(
 			msg.GetGlobalVirtualGroupFamilyId(),
 			msg.GetGlobalVirtualGroupId(),
 		)
 
 		if err != nil {
+			client.refundEvmFee(scope, txOpts)
 			if strings.Contains(err.Error(), "invalid nonce") {
 				// if nonce mismatches, waiting for next block, reset nonce by querying the nonce on chain
 				nonce, nonceErr = client.getNonceOnChain(ctx, client.mechainClients[scope])
@@ -2663,24 +2841,31 @@ func (client *MechainChainSignClient) CancelSwapInEvm(ctx context.Context, scope
 	)
 	for i := 0; i < BroadcastTxRetry; i++ {
 		nonce = client.operatorAccNonce
-		txOpts, err := CreateTxOpts(ctx, client.evmClient, client.privateKeys[scope], chainId, client.gasInfo[CancelSwapIn].GasLimit, nonce)
+		evmClient := client.evmTxClient()
+		txOpts, err := CreateTxOpts(ctx, evmClient, client.txSigners[scope], chainId, client.gasInfo[CancelSwapIn].GasLimit, nonce)
 		if err != nil {
 			log.CtxErrorw(ctx, "failed to create tx opts", "error", err)
 			return "", err
 		}
 
-		session, err := CreateVirtualGroupSession(client.evmClient, *txOpts, types.VirtualGroupAddress)
+		session, err := CreateVirtualGroupSession(evmClient, *txOpts, types.VirtualGroupAddress)
 		if err != nil {
 			log.CtxErrorw(ctx, "failed to create session", "error", err)
 			return "", err
 		}
 
+		if err = client.spendEvmFee(scope, txOpts); err != nil {
+			log.CtxErrorw(ctx, "failed to spend the fee budget", "error", err)
+			ErrCancelSwapIn.SetError(fmt.Errorf("failed to spend the fee budget, error: %v", err))
+			return "", ErrCancelSwapIn
+		}
 		txRsp, err := session.CancelSwapIn(
 			msg.GetGlobalVirtualGroupFamilyId(),
 			msg.GetGlobalVirtualGroupId(),
 		)
 
 		if err != nil {
+			client.refundEvmFee(scope, txOpts)
 			if strings.Contains(err.Error(), "invalid nonce") {
 				// if nonce mismatches, waiting for next block, reset nonce by querying the nonce on chain
 				nonce, nonceErr = client.getNonceOnChain(ctx, client.mechainClients[scope])
@@ -2721,12 +2906,15 @@ func (client *MechainChainSignClient) SealObjectV2(ctx context.Context, scope Si
 		return "", ErrSignMsg
 	}
 
-	client.sealLock.Lock()
-	defer client.sealLock.Unlock()
-
 	msgSealObject := storagetypes.NewMsgSealObjectV2(km.GetAddr(),
 		sealObject.GetBucketName(), sealObject.GetObjectName(), sealObject.GetGlobalVirtualGroupId(),
 		sealObject.GetSecondarySpBlsAggSignatures(), sealObject.GetExpectChecksums())
+	if client.sealPool != nil {
+		return client.sealPool.broadcastMsg(ctx, msgSealObject, client.gasInfo[Seal], ErrSealObjectOnChain)
+	}
+
+	client.sealLock.Lock()
+	defer client.sealLock.Unlock()
 
 	mode := tx.BroadcastMode_BROADCAST_MODE_SYNC
 
@@ -2809,13 +2997,14 @@ func (client *MechainChainSignClient) SealObjectV2Evm(ctx context.Context, scope
 	)
 	for i := 0; i < BroadcastTxRetry; i++ {
 		nonce = client.sealAccNonce
-		txOpts, err := CreateTxOpts(ctx, client.evmClient, client.privateKeys[SignSeal], chainId, client.gasInfo[Seal].GasLimit, nonce)
+		evmClient := client.evmTxClient()
+		txOpts, err := CreateTxOpts(ctx, evmClient, client.txSigners[SignSeal], chainId, client.gasInfo[Seal].GasLimit, nonce)
 		if err != nil {
 			log.CtxErrorw(ctx, "failed to create tx opts", "error", err)
 			return "", err
 		}
 
-		session, err := CreateStorageSession(client.evmClient, *txOpts, types.StorageAddress)
+		session, err := CreateStorageSession(evmClient, *txOpts, types.StorageAddress)
 		if err != nil {
 			log.CtxErrorw(ctx, "failed to create session", "error", err)
 			return "", err
@@ -2826,6 +3015,11 @@ func (client *MechainChainSignClient) SealObjectV2Evm(ctx context.Context, scope
 			expectChecksums = append(expectChecksums, checksumStr)
 		}
 
+		if err = client.spendEvmFee(SignSeal, txOpts); err != nil {
+			log.CtxErrorw(ctx, "failed to spend the fee budget", "error", err)
+			ErrSealObjectOnChain.SetError(fmt.Errorf("failed to spend the fee budget, error: %v", err))
+			return "", ErrSealObjectOnChain
+		}
 		txRsp, err := session.SealObjectV2(
 			ethcmn.BytesToAddress(km.GetAddr().Bytes()),
 			sealObject.GetBucketName(),
@@ -2836,6 +3030,7 @@ func (client *MechainChainSignClient) SealObjectV2Evm(ctx context.Context, scope
 		)
 
 		if err != nil {
+			client.refundEvmFee(SignSeal, txOpts)
 			if strings.Contains(err.Error(), "invalid nonce") {
 				// if nonce mismatch, wait for next block, reset nonce by querying the nonce on chain
 				nonce, nonceErr = client.getNonceOnChain(ctx, client.mechainClients[scope])
@@ -1,15 +1,22 @@
 package signer
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 
 	"github.com/evmos/evmos/v12/sdk/types"
+	sptypes "github.com/evmos/evmos/v12/x/sp/types"
 	"github.com/zkMeLabs/mechain-storage-provider/base/gfspapp"
 	"github.com/zkMeLabs/mechain-storage-provider/base/gfspconfig"
+	"github.com/zkMeLabs/mechain-storage-provider/base/gnfd"
 	coremodule "github.com/zkMeLabs/mechain-storage-provider/core/module"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/auditlog"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
 )
 
 const (
@@ -60,6 +67,8 @@ const (
 	SpBlsPrivKey = "SIGNER_BLS_PRIV_KEY"
 	// SpGcPrivKey defines env variable name for sp gc private key
 	SpGcPrivKey = "SIGNER_GC_PRIV_KEY"
+	// SpPKCS11Pin defines env variable name for the user pin of the PKCS#11 token
+	SpPKCS11Pin = "SIGNER_PKCS11_PIN"
 )
 
 func NewSignModular(app *gfspapp.GfSpBaseApp, cfg *gfspconfig.GfSpConfig) (coremodule.Modular, error) {
@@ -182,21 +191,13 @@ func DefaultSignerOptions(signer *SignModular, cfg *gfspconfig.GfSpConfig) error
 	if cfg.Chain.CancelSwapInFeeAmount == 0 {
 		cfg.Chain.CancelSwapInFeeAmount = DefaultCancelSwapInFeeAmount
 	}
-	if val, ok := os.LookupEnv(SpOperatorPrivKey); ok {
-		cfg.SpAccount.OperatorPrivateKey = val
-	}
-	if val, ok := os.LookupEnv(SpSealPrivKey); ok {
-		cfg.SpAccount.SealPrivateKey = val
-	}
-	if val, ok := os.LookupEnv(SpBlsPrivKey); ok {
-		cfg.SpAccount.BlsPrivateKey = val
-	}
-	if val, ok := os.LookupEnv(SpApprovalPrivKey); ok {
-		cfg.SpAccount.ApprovalPrivateKey = val
+	if cfg.Chain.AuthzExecGasLimit == 0 {
+		cfg.Chain.AuthzExecGasLimit = DefaultAuthzExecGasLimit
 	}
-	if val, ok := os.LookupEnv(SpGcPrivKey); ok {
-		cfg.SpAccount.GcPrivateKey = val
+	if cfg.Chain.AuthzExecFeeAmount == 0 {
+		cfg.Chain.AuthzExecFeeAmount = DefaultAuthzExecFeeAmount
 	}
+	overrideSpAccountFromEnv(&cfg.SpAccount)
 
 	gasInfo := make(map[GasInfoType]GasInfo)
 	gasInfo[Seal] = GasInfo{
@@ -272,13 +273,117 @@ func DefaultSignerOptions(signer *SignModular, cfg *gfspconfig.GfSpConfig) error
 		FeeAmount: sdk.NewCoins(sdk.NewCoin(types.Denom, sdk.NewInt(int64(cfg.Chain.CancelSwapInFeeAmount)))),
 	}
 
+	signers, err := loadSPSigners(&cfg.SpAccount)
+	if err != nil {
+		return err
+	}
+	signer.signers = signers
 	client, err := NewMechainChainSignClient(cfg.Chain.ChainAddress[0], cfg.Chain.RpcAddress[0], cfg.Chain.ChainID,
-		gasInfo, cfg.SpAccount.OperatorPrivateKey, cfg.SpAccount.FundingPrivateKey,
-		cfg.SpAccount.SealPrivateKey, cfg.SpAccount.ApprovalPrivateKey, cfg.SpAccount.GcPrivateKey, cfg.SpAccount.BlsPrivateKey)
+		gasInfo, signers.txSigners, signers.bls)
 	if err != nil {
 		return err
 	}
 	signer.client = client
 	client.signer = signer
+	// the txs follow the broadcast endpoint of the consensus if more than one chain endpoint is configured
+	broadcastEndpoints := newBroadcastEndpoints(cfg.Chain.ChainAddress, cfg.Chain.RpcAddress)
+	var route broadcastRoute
+	if router, ok := signer.baseApp.Consensus().(gnfd.BroadcastRouter); ok && len(broadcastEndpoints) != 0 {
+		route = router.BroadcastEndpoint
+		if err = client.routeBroadcasts(broadcastEndpoints, cfg.Chain.ChainID, route); err != nil {
+			return err
+		}
+	}
+	if cfg.Chain.EnableGasEstimation {
+		client.feeEstimator = newFeeEstimator(cfg.Chain.GasMultiplier,
+			time.Duration(cfg.Chain.GasEstimateCacheSecond)*time.Second)
+	}
+	client.feeBudget = newFeeBudget(map[string]uint64{
+		string(SignOperator): cfg.Chain.OperatorDailyFeeBudget,
+		string(SignSeal):     cfg.Chain.SealDailyFeeBudget,
+		string(SignGc):       cfg.Chain.GcDailyFeeBudget,
+	}, cfg.Chain.FeeBudgetAlertRatio)
+	if len(signers.sealAccounts) != 0 {
+		poolClients, poolAddrs, err := newSealAccountPoolClients(cfg.Chain.ChainAddress[0], cfg.Chain.RpcAddress[0],
+			cfg.Chain.ChainID, signers.sealAccounts)
+		if err != nil {
+			return err
+		}
+		execGasInfo := GasInfo{
+			GasLimit:  cfg.Chain.AuthzExecGasLimit,
+			FeeAmount: sdk.NewCoins(sdk.NewCoin(types.Denom, sdk.NewInt(int64(cfg.Chain.AuthzExecFeeAmount)))),
+		}
+		client.sealPool, err = newSealAccountPool(context.Background(), poolClients, poolAddrs, execGasInfo,
+			signer.baseApp.Consensus().WaitForNextBlock,
+			time.Duration(cfg.Chain.SealAccountCheckIntervalSecond)*time.Second,
+			time.Duration(cfg.Chain.SealAccountStuckTimeoutSecond)*time.Second)
+		if err != nil {
+			return err
+		}
+		client.sealPool.feeEstimator, client.sealPool.feeBudget = client.feeEstimator, client.feeBudget
+		for i, acc := range client.sealPool.accounts {
+			client.feeBudget.setLimit(acc.name, cfg.Chain.SealAccountDailyFeeBudget)
+			if route == nil {
+				continue
+			}
+			if acc.routed, err = newRoutedTxClients(broadcastEndpoints, cfg.Chain.ChainID,
+				newBackendKeyManager(signers.sealAccounts[i])); err != nil {
+				return err
+			}
+		}
+		client.sealPool.route = route
+		log.Warnw("the seal account pool only broadcasts the cosmos seal, reject unseal and gvg creation txs, "+
+			"the evm txs are still broadcast by the seal and operator accounts", "pool_size", len(signers.sealAccounts))
+	}
+	signer.keyRotator = newKeyRotator(signers.rotating, func(ctx context.Context) (*sptypes.StorageProvider, error) {
+		return signer.baseApp.Consensus().QuerySP(ctx, cfg.SpAccount.SpOperatorAddress)
+	}, client.switchKey, time.Duration(cfg.SpAccount.KeyRotationCheckIntervalSecond)*time.Second,
+		time.Duration(cfg.SpAccount.KeyRotationGracePeriodSecond)*time.Second)
+	if cfg.AuditLog.FilePath != "" {
+		if signer.auditLog, err = auditlog.Open(cfg.AuditLog); err != nil {
+			return err
+		}
+	}
+	if cfg.Chain.EnableSealBatch {
+		signer.sealBatcher = newSealBatcher(client.broadcastSealTx, signer.baseApp.Consensus().ConfirmTransaction,
+			func() (sdk.AccAddress, error) {
+				return client.GetAddr(SignSeal)
+			}, gasInfo, time.Duration(cfg.Chain.SealBatchWindowMillisecond)*time.Millisecond,
+			int(cfg.Chain.SealBatchMaxMsgs), cfg.Chain.SealBatchMaxGasLimit)
+	}
 	return nil
 }
+
+// overrideSpAccountFromEnv overrides the SP keys of the config by the env variables.
+func overrideSpAccountFromEnv(cfg *gfspconfig.SpAccountConfig) {
+	if val, ok := os.LookupEnv(SpOperatorPrivKey); ok {
+		cfg.OperatorPrivateKey = val
+	}
+	if val, ok := os.LookupEnv(SpSealPrivKey); ok {
+		cfg.SealPrivateKey = val
+	}
+	if val, ok := os.LookupEnv(SpBlsPrivKey); ok {
+		cfg.BlsPrivateKey = val
+	}
+	if val, ok := os.LookupEnv(SpApprovalPrivKey); ok {
+		cfg.ApprovalPrivateKey = val
+	}
+	if val, ok := os.LookupEnv(SpGcPrivKey); ok {
+		cfg.GcPrivateKey = val
+	}
+	if val, ok := os.LookupEnv(SpSealAccountPrivKeys); ok {
+		cfg.SealAccountPrivateKeys = strings.Split(val, ",")
+	}
+	if val, ok := os.LookupEnv(SpNextSealPrivKey); ok {
+		cfg.NextSealPrivateKey = val
+	}
+	if val, ok := os.LookupEnv(SpNextApprovalPrivKey); ok {
+		cfg.NextApprovalPrivateKey = val
+	}
+	if val, ok := os.LookupEnv(SpNextBlsPrivKey); ok {
+		cfg.NextBlsPrivateKey = val
+	}
+	if val, ok := os.LookupEnv(SpPKCS11Pin); ok {
+		cfg.PKCS11Pin = val
+	}
+}
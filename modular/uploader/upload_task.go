@@ -21,6 +21,7 @@ import (
 	"github.com/zkMeLabs/mechain-storage-provider/core/taskqueue"
 	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
 	"github.com/zkMeLabs/mechain-storage-provider/pkg/metrics"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/webhook"
 	"github.com/zkMeLabs/mechain-storage-provider/store/types"
 )
 
@@ -493,4 +494,5 @@ func (u *UploadModular) rejectCreateObject(ctx context.Context, objectInfo *stor
 		log.CtxErrorw(ctx, "failed to reject unseal object", "error", err, "objectID", objectInfo.Id.Uint64())
 		return
 	}
+	u.baseApp.NotifyObjectEvent(webhook.EventObjectSealFailed, objectInfo, "reject_create_object")
 }
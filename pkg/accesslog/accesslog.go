@@ -0,0 +1,178 @@
+package accesslog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+)
+
+const (
+	// FileSink writes the records to the file which is rotated hourly.
+	FileSink = "file"
+	// SyslogSink writes the records to the local or remote syslog daemon.
+	SyslogSink = "syslog"
+	// UDPSink writes each record as a udp datagram.
+	UDPSink = "udp"
+
+	// DefaultBufferSize defines the default number of the records buffered before they are written to the
+	// sink, the records are dropped if the buffer is full so that the requests are never blocked.
+	DefaultBufferSize = 10000
+	// DefaultSyslogTag defines the default tag of the syslog sink.
+	DefaultSyslogTag = "mechain-sp-gateway"
+)
+
+// Config defines the access log of the gateway, the access log is disabled if Sink is empty.
+type Config struct {
+	// Sink is one of file, syslog and udp.
+	Sink string `comment:"optional"`
+	// FilePath is the path of the file sink.
+	FilePath string `comment:"optional"`
+	// Network is tcp or udp for the remote syslog daemon, the local syslog daemon is used if it is empty.
+	Network string `comment:"optional"`
+	// Address is the address of the remote syslog daemon or the udp sink.
+	Address    string `comment:"optional"`
+	SyslogTag  string `comment:"optional"`
+	BufferSize int    `comment:"optional"`
+	// SampleRates logs the successful requests of the route by the rate in [0, 1], the failed requests are
+	// always logged, and so are the routes which are not listed.
+	SampleRates []SampleRate `comment:"optional"`
+}
+
+// SampleRate defines the sample rate of the route.
+type SampleRate struct {
+	Route string
+	Rate  float64
+}
+
+// Record is the access log of a request.
+type Record struct {
+	Time      time.Time `json:"time"`
+	RequestID string    `json:"request_id"`
+	TraceID   string    `json:"trace_id,omitempty"`
+	RemoteIP  string    `json:"remote_ip"`
+	Method    string    `json:"method"`
+	Host      string    `json:"host"`
+	Path      string    `json:"path"`
+	Route     string    `json:"route"`
+	Account   string    `json:"account,omitempty"`
+	Bucket    string    `json:"bucket,omitempty"`
+	Object    string    `json:"object,omitempty"`
+	Status    int       `json:"status"`
+	ErrorCode int32     `json:"error_code,omitempty"`
+	BytesIn   int64     `json:"bytes_in"`
+	BytesOut  int64     `json:"bytes_out"`
+	LatencyMs int64     `json:"latency_ms"`
+	UserAgent string    `json:"user_agent,omitempty"`
+}
+
+type recordKey struct{}
+
+// WithRecord returns the context carrying the record, the handlers fill the fields known after the
+// authentication by FromContext.
+func WithRecord(ctx context.Context, record *Record) context.Context {
+	return context.WithValue(ctx, recordKey{}, record)
+}
+
+// FromContext returns the record of the request, nil if the access log is disabled.
+func FromContext(ctx context.Context) *Record {
+	record, _ := ctx.Value(recordKey{}).(*Record)
+	return record
+}
+
+// Logger writes the records to the sink asynchronously.
+type Logger struct {
+	sinkName    string
+	sink        Sink
+	sampleRates map[string]float64
+	records     chan *Record
+	dropped     atomic.Uint64
+	wg          sync.WaitGroup
+}
+
+// NewLogger returns a Logger writing to the sink of the config.
+func NewLogger(cfg Config) (*Logger, error) {
+	for _, s := range cfg.SampleRates {
+		if s.Rate < 0 || s.Rate > 1 {
+			return nil, fmt.Errorf("invalid sample rate %v of route %s", s.Rate, s.Route)
+		}
+	}
+	sink, err := NewSink(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return newLogger(cfg, sink), nil
+}
+
+func newLogger(cfg Config, sink Sink) *Logger {
+	if cfg.BufferSize == 0 {
+		cfg.BufferSize = DefaultBufferSize
+	}
+	l := &Logger{
+		sinkName:    cfg.Sink,
+		sink:        sink,
+		sampleRates: make(map[string]float64, len(cfg.SampleRates)),
+		records:     make(chan *Record, cfg.BufferSize),
+	}
+	for _, s := range cfg.SampleRates {
+		l.sampleRates[strings.ToLower(s.Route)] = s.Rate
+	}
+	l.wg.Add(1)
+	go l.loop()
+	return l
+}
+
+// Log writes the record if it is sampled, it never blocks.
+func (l *Logger) Log(record *Record) {
+	if !l.sampled(record) {
+		return
+	}
+	select {
+	case l.records <- record:
+	default:
+		l.dropped.Add(1)
+	}
+}
+
+// Dropped returns the number of the records dropped due to the full buffer.
+func (l *Logger) Dropped() uint64 {
+	return l.dropped.Load()
+}
+
+// Close writes the buffered records and closes the sink.
+func (l *Logger) Close() error {
+	close(l.records)
+	l.wg.Wait()
+	return l.sink.Close()
+}
+
+func (l *Logger) sampled(record *Record) bool {
+	if record.Status >= 400 {
+		return true
+	}
+	rate, ok := l.sampleRates[strings.ToLower(record.Route)]
+	if !ok {
+		return true
+	}
+	return rand.Float64() < rate
+}
+
+func (l *Logger) loop() {
+	defer l.wg.Done()
+	for record := range l.records {
+		data, err := json.Marshal(record)
+		if err != nil {
+			log.Errorw("failed to marshal access log", "error", err)
+			continue
+		}
+		if _, err = l.sink.Write(data); err != nil {
+			log.Errorw("failed to write access log", "sink", l.sinkName, "error", err)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package accesslog
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockSink struct {
+	records chan []byte
+}
+
+func (m *mockSink) Write(data []byte) (int, error) {
+	m.records <- append([]byte(nil), data...)
+	return len(data), nil
+}
+
+func (m *mockSink) Close() error {
+	close(m.records)
+	return nil
+}
+
+func TestContext(t *testing.T) {
+	assert.Nil(t, FromContext(context.Background()))
+	record := &Record{Route: "GetObject"}
+	assert.Equal(t, record, FromContext(WithRecord(context.Background(), record)))
+}
+
+func TestLoggerSampling(t *testing.T) {
+	sink := &mockSink{records: make(chan []byte, 10)}
+	l := newLogger(Config{SampleRates: []SampleRate{{Route: "GetObject", Rate: 0}}}, sink)
+	// the sampled out route is logged only if it fails
+	l.Log(&Record{Route: "getobject", Status: 200})
+	l.Log(&Record{Route: "GetObject", Status: 404, ErrorCode: 50001})
+	l.Log(&Record{Route: "PutObject", Status: 200, Account: "0x01"})
+	assert.Nil(t, l.Close())
+
+	var records []*Record
+	for data := range sink.records {
+		record := &Record{}
+		assert.Nil(t, json.Unmarshal(data, record))
+		records = append(records, record)
+	}
+	assert.Equal(t, 2, len(records))
+	assert.Equal(t, int32(50001), records[0].ErrorCode)
+	assert.Equal(t, "0x01", records[1].Account)
+}
+
+func TestLoggerDropped(t *testing.T) {
+	sink := &mockSink{records: make(chan []byte)}
+	l := newLogger(Config{BufferSize: 1}, sink)
+	// the sink is blocked, so at most one record is being written and one is buffered
+	for i := 0; i < 5; i++ {
+		l.Log(&Record{Status: 200})
+	}
+	assert.True(t, l.Dropped() >= 3)
+	go func() {
+		for range sink.records {
+		}
+	}()
+	assert.Nil(t, l.Close())
+}
+
+func TestNewLoggerFailure(t *testing.T) {
+	_, err := NewLogger(Config{Sink: "kafka"})
+	assert.NotNil(t, err)
+	_, err = NewLogger(Config{Sink: FileSink})
+	assert.NotNil(t, err)
+	_, err = NewLogger(Config{Sink: UDPSink})
+	assert.NotNil(t, err)
+	_, err = NewLogger(Config{Sink: UDPSink, Address: "127.0.0.1:1", SampleRates: []SampleRate{{Route: "GetObject", Rate: 2}}})
+	assert.NotNil(t, err)
+}
+
+func TestUDPSink(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer conn.Close()
+	l, err := NewLogger(Config{Sink: UDPSink, Address: conn.LocalAddr().String()})
+	assert.Nil(t, err)
+	l.Log(&Record{Route: "GetObject", Bucket: "bucket", Status: 200})
+	assert.Nil(t, l.Close())
+
+	buf := make([]byte, 4096)
+	assert.Nil(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	n, _, err := conn.ReadFrom(buf)
+	assert.Nil(t, err)
+	record := &Record{}
+	assert.Nil(t, json.Unmarshal(buf[:n], record))
+	assert.Equal(t, "bucket", record.Bucket)
+}
+
+func TestFileSink(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "access", "access.log")
+	l, err := NewLogger(Config{Sink: FileSink, FilePath: filePath})
+	assert.Nil(t, err)
+	l.Log(&Record{Route: "GetObject", Status: 200})
+	l.Log(&Record{Route: "PutObject", Status: 200})
+	assert.Nil(t, l.Close())
+
+	data, err := os.ReadFile(filePath)
+	assert.Nil(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Equal(t, 2, len(lines))
+	assert.Contains(t, lines[1], `"route":"PutObject"`)
+}
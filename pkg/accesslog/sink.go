@@ -0,0 +1,60 @@
+package accesslog
+
+import (
+	"fmt"
+	"io"
+	"log/syslog"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+)
+
+// Sink receives the json encoded records, each Write is a whole record.
+type Sink interface {
+	io.WriteCloser
+}
+
+// NewSink returns the sink of the config.
+func NewSink(cfg Config) (Sink, error) {
+	switch cfg.Sink {
+	case FileSink:
+		if cfg.FilePath == "" {
+			return nil, fmt.Errorf("the file path of the %s access log sink is required", FileSink)
+		}
+		if err := os.MkdirAll(filepath.Dir(cfg.FilePath), 0o755); err != nil {
+			return nil, err
+		}
+		return &fileSink{writer: log.NewAsyncFileWriter(cfg.FilePath, int64(DefaultBufferSize))}, nil
+	case SyslogSink:
+		if cfg.SyslogTag == "" {
+			cfg.SyslogTag = DefaultSyslogTag
+		}
+		writer, err := syslog.Dial(cfg.Network, cfg.Address, syslog.LOG_INFO|syslog.LOG_LOCAL0, cfg.SyslogTag)
+		if err != nil {
+			return nil, err
+		}
+		return writer, nil
+	case UDPSink:
+		if cfg.Address == "" {
+			return nil, fmt.Errorf("the address of the %s access log sink is required", UDPSink)
+		}
+		return net.Dial("udp", cfg.Address)
+	default:
+		return nil, fmt.Errorf("unsupported access log sink: %s", cfg.Sink)
+	}
+}
+
+// fileSink writes one record per line to the file rotated hourly.
+type fileSink struct {
+	writer *log.AsyncFileWriter
+}
+
+func (f *fileSink) Write(data []byte) (int, error) {
+	return f.writer.Write(append(data, '\n'))
+}
+
+func (f *fileSink) Close() error {
+	return f.writer.Stop()
+}
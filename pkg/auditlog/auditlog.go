@@ -0,0 +1,251 @@
+// Package auditlog provides the append-only audit log whose entries are chained by their hashes, so that any
+// modified, removed or reordered entry is detected by Verify.
+package auditlog
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// ResultSuccess is the result of the successful signature or tx.
+	ResultSuccess = "success"
+
+	// maxEntrySize defines the max size of an entry in the file.
+	maxEntrySize = 1024 * 1024
+)
+
+// Config defines the audit log of the signer, the audit log is disabled if FilePath is empty.
+type Config struct {
+	// FilePath is the path of the append-only file, the entries are appended as json lines.
+	FilePath string `comment:"optional"`
+	// Sync flushes every entry to the disk before the signature or the tx hash is returned.
+	Sync bool `comment:"optional"`
+}
+
+// Entry is an audit log entry of a signature or a tx.
+type Entry struct {
+	// Seq is the sequence of the entry starting from 1.
+	Seq  uint64    `json:"seq"`
+	Time time.Time `json:"time"`
+	// Caller is the modules of the caller identified by the mutual TLS, or claimed in the gRPC metadata if the
+	// mutual TLS is disabled, Peer is the address of the caller.
+	Caller string `json:"caller,omitempty"`
+	Peer   string `json:"peer,omitempty"`
+	Method string `json:"method"`
+	// MsgType is the type of the signed message.
+	MsgType string `json:"msg_type"`
+	// Scope is the key which signs the message.
+	Scope string `json:"scope"`
+	// PayloadDigest is the hex sha256 of the signed bytes or the encoded tx message.
+	PayloadDigest string `json:"payload_digest"`
+	TxHash        string `json:"tx_hash,omitempty"`
+	// Result is ResultSuccess or the error.
+	Result string `json:"result"`
+	// PrevHash is the hash of the previous entry, it is empty for the first entry.
+	PrevHash string `json:"prev_hash"`
+	// Hash is the hex sha256 of the entry without the hash.
+	Hash string `json:"hash"`
+}
+
+// ComputeHash returns the hash of the entry without its hash.
+func (e Entry) ComputeHash() (string, error) {
+	e.Hash = ""
+	bz, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(bz)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Digest returns the hex sha256 of the payload.
+func Digest(payload []byte) string {
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// Log appends the entries to the file and chains them by the hashes.
+type Log struct {
+	mux      sync.Mutex
+	file     *os.File
+	sync     bool
+	seq      uint64
+	lastHash string
+}
+
+// Open opens the audit log file and restores the chain from its last entry.
+func Open(cfg Config) (*Log, error) {
+	if cfg.FilePath == "" {
+		return nil, errors.New("audit log file path is required")
+	}
+	file, err := os.OpenFile(cfg.FilePath, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	line, err := lastLine(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	l := &Log{file: file, sync: cfg.Sync}
+	if line != nil {
+		var last Entry
+		if err = json.Unmarshal(line, &last); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("invalid last audit log entry: %w", err)
+		}
+		l.seq, l.lastHash = last.Seq, last.Hash
+	}
+	return l, nil
+}
+
+// lastLine returns the last line of the file without the line break, nil is returned if the file is empty.
+func lastLine(file *os.File) ([]byte, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := info.Size()
+	if size == 0 {
+		return nil, nil
+	}
+	start := size - maxEntrySize
+	if start < 0 {
+		start = 0
+	}
+	buf := make([]byte, size-start)
+	if _, err = file.ReadAt(buf, start); err != nil {
+		return nil, err
+	}
+	if buf[len(buf)-1] != '\n' {
+		return nil, errors.New("audit log ends with a partial entry")
+	}
+	buf = buf[:len(buf)-1]
+	return buf[bytes.LastIndexByte(buf, '\n')+1:], nil
+}
+
+// Append fills the sequence, the time and the hashes of the entry, and appends it to the file.
+func (l *Log) Append(entry *Entry) error {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	entry.Seq = l.seq + 1
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	entry.Time = entry.Time.UTC()
+	entry.PrevHash = l.lastHash
+	hash, err := entry.ComputeHash()
+	if err != nil {
+		return err
+	}
+	entry.Hash = hash
+	bz, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if _, err = l.file.Write(append(bz, '\n')); err != nil {
+		return err
+	}
+	if l.sync {
+		if err = l.file.Sync(); err != nil {
+			return err
+		}
+	}
+	l.seq, l.lastHash = entry.Seq, entry.Hash
+	return nil
+}
+
+// Close closes the file.
+func (l *Log) Close() error {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	return l.file.Close()
+}
+
+// VerifyError reports the first entry which breaks the chain.
+type VerifyError struct {
+	Line   int
+	Seq    uint64
+	Reason string
+}
+
+func (e *VerifyError) Error() string {
+	return fmt.Sprintf("audit log is broken at line %d seq %d: %s", e.Line, e.Seq, e.Reason)
+}
+
+// Verify checks the hashes, the sequences and the links of the entries read from r, and returns the number of
+// the verified entries and the last one, which should be anchored out of the SP, e.g. kept by the auditor, to
+// detect the removed tail of the log. The entries may start from any sequence, e.g. the exported entries, but the first
+// entry of the whole log must be the sequence 1 without the previous hash.
+func Verify(r io.Reader) (int, *Entry, error) {
+	var (
+		prev    *Entry
+		count   int
+		scanner = newScanner(r)
+	)
+	for scanner.Scan() {
+		line := count + 1
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return count, prev, &VerifyError{Line: line, Reason: "invalid entry: " + err.Error()}
+		}
+		hash, err := entry.ComputeHash()
+		if err != nil {
+			return count, prev, err
+		}
+		switch {
+		case hash != entry.Hash:
+			return count, prev, &VerifyError{Line: line, Seq: entry.Seq, Reason: "hash mismatch"}
+		case prev == nil && entry.Seq == 1 && entry.PrevHash != "":
+			return count, prev, &VerifyError{Line: line, Seq: entry.Seq, Reason: "first entry has previous hash"}
+		case prev != nil && entry.Seq != prev.Seq+1:
+			return count, prev, &VerifyError{Line: line, Seq: entry.Seq, Reason: fmt.Sprintf("sequence gap after %d", prev.Seq)}
+		case prev != nil && entry.PrevHash != prev.Hash:
+			return count, prev, &VerifyError{Line: line, Seq: entry.Seq, Reason: "previous hash mismatch"}
+		}
+		prev = &entry
+		count++
+	}
+	return count, prev, scanner.Err()
+}
+
+// Export copies the entries whose time is in [from, to) from r to w, the zero from or to is unbounded. The
+// exported entries keep their hashes, so they can be verified by Verify.
+func Export(r io.Reader, w io.Writer, from, to time.Time) (int, error) {
+	var (
+		line    int
+		count   int
+		scanner = newScanner(r)
+	)
+	for scanner.Scan() {
+		line++
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return count, fmt.Errorf("invalid entry at line %d: %w", line, err)
+		}
+		if (!from.IsZero() && entry.Time.Before(from)) || (!to.IsZero() && !entry.Time.Before(to)) {
+			continue
+		}
+		if _, err := w.Write(append(scanner.Bytes(), '\n')); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, scanner.Err()
+}
+
+func newScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxEntrySize)
+	return scanner
+}
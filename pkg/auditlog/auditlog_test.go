@@ -0,0 +1,109 @@
+package auditlog
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func appendMockEntries(t *testing.T, path string, start time.Time, n int) {
+	l, err := Open(Config{FilePath: path, Sync: true})
+	assert.Nil(t, err)
+	defer l.Close()
+	for i := 0; i < n; i++ {
+		entry := &Entry{
+			Time:          start.Add(time.Duration(i) * time.Minute),
+			Caller:        "manager",
+			Method:        "SealObject",
+			MsgType:       "mechain.storage.MsgSealObject",
+			Scope:         "seal",
+			PayloadDigest: Digest([]byte{byte(i)}),
+			TxHash:        "tx",
+			Result:        ResultSuccess,
+		}
+		assert.Nil(t, l.Append(entry))
+	}
+}
+
+func TestLog(t *testing.T) {
+	_, err := Open(Config{})
+	assert.NotNil(t, err)
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	appendMockEntries(t, path, start, 3)
+	// the chain is restored from the last entry after reopen
+	appendMockEntries(t, path, start.Add(3*time.Minute), 2)
+
+	bz, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	n, last, err := Verify(bytes.NewReader(bz))
+	assert.Nil(t, err)
+	assert.Equal(t, 5, n)
+	assert.Equal(t, uint64(5), last.Seq)
+
+	// the file ending with a partial entry is not opened
+	assert.Nil(t, os.WriteFile(path, append(bz, []byte(`{"seq":6`)...), 0o600))
+	_, err = Open(Config{FilePath: path})
+	assert.NotNil(t, err)
+}
+
+func TestVerify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	appendMockEntries(t, path, time.Now(), 4)
+	bz, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	lines := strings.SplitAfter(string(bz), "\n")[:4]
+
+	cases := []struct {
+		name   string
+		lines  []string
+		line   int
+		reason string
+	}{
+		{"modified", []string{lines[0], strings.Replace(lines[1], `"seal"`, `"gc"`, 1), lines[2]}, 2, "hash mismatch"},
+		{"removed", []string{lines[0], lines[2], lines[3]}, 2, "sequence gap after 1"},
+		{"reordered", []string{lines[0], lines[2], lines[1]}, 2, "sequence gap after 1"},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			n, last, err := Verify(strings.NewReader(strings.Join(tt.lines, "")))
+			var verifyErr *VerifyError
+			assert.True(t, errors.As(err, &verifyErr))
+			assert.Equal(t, tt.line, verifyErr.Line)
+			assert.Equal(t, tt.reason, verifyErr.Reason)
+			assert.Equal(t, tt.line-1, n)
+			assert.Equal(t, uint64(1), last.Seq)
+		})
+	}
+}
+
+func TestExport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	appendMockEntries(t, path, start, 5)
+	bz, err := os.ReadFile(path)
+	assert.Nil(t, err)
+
+	var out bytes.Buffer
+	n, err := Export(bytes.NewReader(bz), &out, start.Add(time.Minute), start.Add(4*time.Minute))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, n)
+	// the exported entries are still chained
+	n, last, err := Verify(&out)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, uint64(4), last.Seq)
+
+	out.Reset()
+	n, err = Export(bytes.NewReader(bz), &out, time.Time{}, time.Time{})
+	assert.Nil(t, err)
+	assert.Equal(t, 5, n)
+	assert.Equal(t, bz, out.Bytes())
+}
@@ -0,0 +1,89 @@
+package cors
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultCacheTTL defines the default time which the config of a bucket is cached.
+	DefaultCacheTTL = time.Minute
+	// DefaultCacheSize defines the default max number of the cached buckets.
+	DefaultCacheSize = 10000
+)
+
+// Loader loads the CORS config of the bucket, it returns (nil, nil) if the bucket has no config.
+type Loader func(bucketName string) (*Config, error)
+
+type cacheEntry struct {
+	config   *Config
+	expireAt time.Time
+}
+
+// Cache caches the validated CORS configs of the buckets including the buckets without config, so that
+// evaluating the CORS requests does not query the db every time. The config updated by the other
+// gateways takes effect after the ttl.
+type Cache struct {
+	ttl     time.Duration
+	size    int
+	loader  Loader
+	mu      sync.RWMutex
+	entries map[string]*cacheEntry
+}
+
+// NewCache returns a Cache, the zero ttl and size are replaced by the defaults.
+func NewCache(ttl time.Duration, size int, loader Loader) *Cache {
+	if ttl == 0 {
+		ttl = DefaultCacheTTL
+	}
+	if size == 0 {
+		size = DefaultCacheSize
+	}
+	return &Cache{
+		ttl:     ttl,
+		size:    size,
+		loader:  loader,
+		entries: make(map[string]*cacheEntry),
+	}
+}
+
+// Get returns the CORS config of the bucket, it returns nil if the bucket has no config.
+func (c *Cache) Get(bucketName string) (*Config, error) {
+	now := time.Now()
+	c.mu.RLock()
+	entry, ok := c.entries[bucketName]
+	c.mu.RUnlock()
+	if ok && now.Before(entry.expireAt) {
+		return entry.config, nil
+	}
+	config, err := c.loader(bucketName)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= c.size {
+		c.evictLocked(now)
+	}
+	c.entries[bucketName] = &cacheEntry{config: config, expireAt: now.Add(c.ttl)}
+	return config, nil
+}
+
+// Invalidate removes the cached config of the bucket after it is updated.
+func (c *Cache) Invalidate(bucketName string) {
+	c.mu.Lock()
+	delete(c.entries, bucketName)
+	c.mu.Unlock()
+}
+
+// evictLocked removes the expired entries, and clears the cache if it is still full.
+func (c *Cache) evictLocked(now time.Time) {
+	for bucketName, entry := range c.entries {
+		if !now.Before(entry.expireAt) {
+			delete(c.entries, bucketName)
+		}
+	}
+	if len(c.entries) >= c.size {
+		c.entries = make(map[string]*cacheEntry)
+	}
+}
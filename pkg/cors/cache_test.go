@@ -0,0 +1,64 @@
+package cors
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_Get(t *testing.T) {
+	loads := 0
+	config := &Config{Rules: []*Rule{{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}}}}
+	c := NewCache(time.Hour, 0, func(bucketName string) (*Config, error) {
+		loads++
+		switch bucketName {
+		case "configured":
+			return config, nil
+		case "broken":
+			return nil, errors.New("mock error")
+		default:
+			return nil, nil
+		}
+	})
+
+	result, err := c.Get("configured")
+	assert.Nil(t, err)
+	assert.Equal(t, config, result)
+	_, _ = c.Get("configured")
+	assert.Equal(t, 1, loads)
+
+	result, err = c.Get("empty")
+	assert.Nil(t, err)
+	assert.Nil(t, result)
+	_, _ = c.Get("empty")
+	assert.Equal(t, 2, loads)
+
+	_, err = c.Get("broken")
+	assert.NotNil(t, err)
+	_, _ = c.Get("broken")
+	assert.Equal(t, 4, loads)
+
+	c.Invalidate("configured")
+	_, _ = c.Get("configured")
+	assert.Equal(t, 5, loads)
+}
+
+func TestCache_Expire(t *testing.T) {
+	loads := 0
+	c := NewCache(time.Millisecond, 2, func(bucketName string) (*Config, error) {
+		loads++
+		return nil, nil
+	})
+	_, _ = c.Get("bucket1")
+	time.Sleep(2 * time.Millisecond)
+	_, _ = c.Get("bucket1")
+	assert.Equal(t, 2, loads)
+
+	c = NewCache(time.Hour, 2, func(bucketName string) (*Config, error) { return nil, nil })
+	_, _ = c.Get("bucket1")
+	_, _ = c.Get("bucket2")
+	_, _ = c.Get("bucket3")
+	assert.Equal(t, 1, len(c.entries))
+}
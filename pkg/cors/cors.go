@@ -0,0 +1,215 @@
+package cors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MaxRuleNumber defines the max number of the rules of a bucket.
+	MaxRuleNumber = 100
+	// MaxMaxAgeSeconds defines the max value of the preflight cache time.
+	MaxMaxAgeSeconds = 24 * 60 * 60
+
+	AllowOriginHeader      = "Access-Control-Allow-Origin"
+	AllowMethodsHeader     = "Access-Control-Allow-Methods"
+	AllowHeadersHeader     = "Access-Control-Allow-Headers"
+	ExposeHeadersHeader    = "Access-Control-Expose-Headers"
+	MaxAgeHeader           = "Access-Control-Max-Age"
+	RequestMethodHeader    = "Access-Control-Request-Method"
+	RequestHeadersHeader   = "Access-Control-Request-Headers"
+	OriginHeader           = "Origin"
+	VaryHeader             = "Vary"
+	preflightVaryHeaderVal = "Origin, Access-Control-Request-Method, Access-Control-Request-Headers"
+)
+
+var supportedMethods = map[string]bool{
+	http.MethodGet:    true,
+	http.MethodPut:    true,
+	http.MethodPost:   true,
+	http.MethodDelete: true,
+	http.MethodHead:   true,
+	http.MethodPatch:  true,
+}
+
+// Rule defines which cross-origin requests are allowed, it follows the CORS rule of S3. The origin and the
+// header may contain at most one "*" wildcard, and a single "*" matches everything.
+type Rule struct {
+	AllowedOrigins []string `json:"allowed_origins"`
+	AllowedMethods []string `json:"allowed_methods"`
+	AllowedHeaders []string `json:"allowed_headers,omitempty"`
+	ExposeHeaders  []string `json:"expose_headers,omitempty"`
+	MaxAgeSeconds  int64    `json:"max_age_seconds,omitempty"`
+}
+
+// Validate checks the rule.
+func (r *Rule) Validate() error {
+	if len(r.AllowedOrigins) == 0 {
+		return errors.New("allowed origin is required")
+	}
+	if len(r.AllowedMethods) == 0 {
+		return errors.New("allowed method is required")
+	}
+	for _, origin := range r.AllowedOrigins {
+		if strings.Count(origin, "*") > 1 {
+			return fmt.Errorf("allowed origin %s contains more than one wildcard", origin)
+		}
+	}
+	for _, header := range r.AllowedHeaders {
+		if strings.Count(header, "*") > 1 {
+			return fmt.Errorf("allowed header %s contains more than one wildcard", header)
+		}
+	}
+	for _, method := range r.AllowedMethods {
+		if !supportedMethods[method] {
+			return fmt.Errorf("unsupported allowed method %s", method)
+		}
+	}
+	if r.MaxAgeSeconds < 0 || r.MaxAgeSeconds > MaxMaxAgeSeconds {
+		return fmt.Errorf("max age seconds must be in [0, %d]", MaxMaxAgeSeconds)
+	}
+	return nil
+}
+
+func (r *Rule) matchOrigin(origin string) bool {
+	for _, allowed := range r.AllowedOrigins {
+		if wildcardMatch(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) matchMethod(method string) bool {
+	for _, allowed := range r.AllowedMethods {
+		if allowed == method {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) matchHeaders(headers []string) bool {
+	for _, header := range headers {
+		matched := false
+		for _, allowed := range r.AllowedHeaders {
+			if wildcardMatch(strings.ToLower(allowed), header) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Rule) allowAnyOrigin() bool {
+	for _, allowed := range r.AllowedOrigins {
+		if allowed == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// Config is the CORS configuration of a bucket, the first matched rule is applied.
+type Config struct {
+	Rules []*Rule
+}
+
+// Validate checks the config.
+func (c *Config) Validate() error {
+	if len(c.Rules) == 0 {
+		return errors.New("cors rule is required")
+	}
+	if len(c.Rules) > MaxRuleNumber {
+		return fmt.Errorf("the number of cors rules exceeds %d", MaxRuleNumber)
+	}
+	for i, rule := range c.Rules {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("invalid cors rule %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Match returns the first rule which allows the origin, the method and the request headers, it returns nil
+// if the request is not allowed. The request headers are only checked by the preflight request.
+func (c *Config) Match(origin, method string, requestHeaders []string) *Rule {
+	if c == nil || origin == "" {
+		return nil
+	}
+	for _, rule := range c.Rules {
+		if rule.matchOrigin(origin) && rule.matchMethod(method) && rule.matchHeaders(requestHeaders) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// IsPreflight returns whether the request is a CORS preflight request.
+func IsPreflight(r *http.Request) bool {
+	return r.Method == http.MethodOptions && r.Header.Get(OriginHeader) != "" && r.Header.Get(RequestMethodHeader) != ""
+}
+
+// ParseRequestHeaders parses the lower-cased Access-Control-Request-Headers of the preflight request.
+func ParseRequestHeaders(r *http.Request) []string {
+	var headers []string
+	for _, value := range r.Header.Values(RequestHeadersHeader) {
+		for _, header := range strings.Split(value, ",") {
+			if header = strings.ToLower(strings.TrimSpace(header)); header != "" {
+				headers = append(headers, header)
+			}
+		}
+	}
+	return headers
+}
+
+// WritePreflightHeaders writes the response headers of the allowed preflight request.
+func WritePreflightHeaders(w http.ResponseWriter, rule *Rule, origin, method string, requestHeaders []string) {
+	h := w.Header()
+	writeAllowOrigin(h, rule, origin)
+	h.Set(VaryHeader, preflightVaryHeaderVal)
+	h.Set(AllowMethodsHeader, method)
+	if len(requestHeaders) > 0 {
+		h.Set(AllowHeadersHeader, strings.Join(requestHeaders, ", "))
+	}
+	if len(rule.ExposeHeaders) > 0 {
+		h.Set(ExposeHeadersHeader, strings.Join(rule.ExposeHeaders, ", "))
+	}
+	if rule.MaxAgeSeconds > 0 {
+		h.Set(MaxAgeHeader, strconv.FormatInt(rule.MaxAgeSeconds, 10))
+	}
+}
+
+// WriteActualHeaders writes the response headers of the allowed actual request.
+func WriteActualHeaders(w http.ResponseWriter, rule *Rule, origin string) {
+	h := w.Header()
+	writeAllowOrigin(h, rule, origin)
+	h.Add(VaryHeader, OriginHeader)
+	if len(rule.ExposeHeaders) > 0 {
+		h.Set(ExposeHeadersHeader, strings.Join(rule.ExposeHeaders, ", "))
+	}
+}
+
+func writeAllowOrigin(h http.Header, rule *Rule, origin string) {
+	if rule.allowAnyOrigin() {
+		h.Set(AllowOriginHeader, "*")
+		return
+	}
+	h.Set(AllowOriginHeader, origin)
+}
+
+// wildcardMatch matches the value with the pattern which contains at most one "*" wildcard.
+func wildcardMatch(pattern, value string) bool {
+	prefix, suffix, found := strings.Cut(pattern, "*")
+	if !found {
+		return pattern == value
+	}
+	return len(value) >= len(prefix)+len(suffix) && strings.HasPrefix(value, prefix) && strings.HasSuffix(value, suffix)
+}
@@ -0,0 +1,82 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWildcardMatch(t *testing.T) {
+	assert.True(t, wildcardMatch("*", "https://a.com"))
+	assert.True(t, wildcardMatch("https://*.example.com", "https://app.example.com"))
+	assert.False(t, wildcardMatch("https://*.example.com", "https://example.com"))
+	assert.False(t, wildcardMatch("https://*.example.com", "https://app.example.com.evil.io"))
+	assert.True(t, wildcardMatch("https://example.com", "https://example.com"))
+	assert.False(t, wildcardMatch("https://example.com", "http://example.com"))
+	assert.True(t, wildcardMatch("x-gnfd-*", "x-gnfd-user-address"))
+}
+
+func TestConfig_Validate(t *testing.T) {
+	valid := &Rule{AllowedOrigins: []string{"*"}, AllowedMethods: []string{http.MethodGet}}
+	assert.Nil(t, (&Config{Rules: []*Rule{valid}}).Validate())
+	assert.NotNil(t, (&Config{}).Validate())
+	cases := []*Rule{
+		{AllowedMethods: []string{http.MethodGet}},
+		{AllowedOrigins: []string{"*"}},
+		{AllowedOrigins: []string{"https://*.*.com"}, AllowedMethods: []string{http.MethodGet}},
+		{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"TRACE"}},
+		{AllowedOrigins: []string{"*"}, AllowedMethods: []string{http.MethodGet}, AllowedHeaders: []string{"**"}},
+		{AllowedOrigins: []string{"*"}, AllowedMethods: []string{http.MethodGet}, MaxAgeSeconds: -1},
+	}
+	for _, rule := range cases {
+		assert.NotNil(t, (&Config{Rules: []*Rule{rule}}).Validate())
+	}
+	rules := make([]*Rule, MaxRuleNumber+1)
+	for i := range rules {
+		rules[i] = valid
+	}
+	assert.NotNil(t, (&Config{Rules: rules}).Validate())
+}
+
+func TestConfig_Match(t *testing.T) {
+	first := &Rule{AllowedOrigins: []string{"https://app.example.com"}, AllowedMethods: []string{http.MethodPut},
+		AllowedHeaders: []string{"Authorization", "x-gnfd-*"}}
+	second := &Rule{AllowedOrigins: []string{"*"}, AllowedMethods: []string{http.MethodGet}}
+	c := &Config{Rules: []*Rule{first, second}}
+	assert.Equal(t, first, c.Match("https://app.example.com", http.MethodPut, []string{"authorization", "x-gnfd-user-address"}))
+	assert.Nil(t, c.Match("https://app.example.com", http.MethodPut, []string{"x-other"}))
+	assert.Nil(t, c.Match("https://other.com", http.MethodPut, nil))
+	assert.Equal(t, second, c.Match("https://other.com", http.MethodGet, nil))
+	assert.Nil(t, c.Match("", http.MethodGet, nil))
+	var nilConfig *Config
+	assert.Nil(t, nilConfig.Match("https://other.com", http.MethodGet, nil))
+}
+
+func TestPreflight(t *testing.T) {
+	r := httptest.NewRequest(http.MethodOptions, "/bucket/object", nil)
+	assert.False(t, IsPreflight(r))
+	r.Header.Set(OriginHeader, "https://app.example.com")
+	r.Header.Set(RequestMethodHeader, http.MethodPut)
+	r.Header.Add(RequestHeadersHeader, "Authorization, X-Gnfd-User-Address")
+	r.Header.Add(RequestHeadersHeader, "Content-Type")
+	assert.True(t, IsPreflight(r))
+	headers := ParseRequestHeaders(r)
+	assert.Equal(t, []string{"authorization", "x-gnfd-user-address", "content-type"}, headers)
+
+	rule := &Rule{AllowedOrigins: []string{"https://app.example.com"}, AllowedMethods: []string{http.MethodPut},
+		AllowedHeaders: []string{"*"}, ExposeHeaders: []string{"ETag"}, MaxAgeSeconds: 600}
+	w := httptest.NewRecorder()
+	WritePreflightHeaders(w, rule, "https://app.example.com", http.MethodPut, headers)
+	assert.Equal(t, "https://app.example.com", w.Header().Get(AllowOriginHeader))
+	assert.Equal(t, http.MethodPut, w.Header().Get(AllowMethodsHeader))
+	assert.Equal(t, "authorization, x-gnfd-user-address, content-type", w.Header().Get(AllowHeadersHeader))
+	assert.Equal(t, "ETag", w.Header().Get(ExposeHeadersHeader))
+	assert.Equal(t, "600", w.Header().Get(MaxAgeHeader))
+
+	w = httptest.NewRecorder()
+	WriteActualHeaders(w, &Rule{AllowedOrigins: []string{"*"}, AllowedMethods: []string{http.MethodGet}}, "https://a.com")
+	assert.Equal(t, "*", w.Header().Get(AllowOriginHeader))
+	assert.Equal(t, OriginHeader, w.Header().Get(VaryHeader))
+}
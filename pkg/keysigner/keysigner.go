@@ -0,0 +1,164 @@
+// Package keysigner provides the backends holding the SP keys, the callers only see the public keys and the
+// signatures, the private keys never leave the backends.
+package keysigner
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// KeyType is the type of the key held by the backend.
+type KeyType string
+
+const (
+	// KeyTypeSecp256k1 is the secp256k1 key of the accounts.
+	KeyTypeSecp256k1 KeyType = "secp256k1"
+	// KeyTypeBLS is the bls12-381 key of the secondary SP signatures.
+	KeyTypeBLS KeyType = "bls"
+
+	// DigestLength defines the length of the digest signed by the secp256k1 key.
+	DigestLength = 32
+	// Secp256k1SignatureLength defines the length of the [R || S || V] signature of the secp256k1 key.
+	Secp256k1SignatureLength = 65
+)
+
+var (
+	// ErrKeyNotFound is returned if the backend does not hold the key.
+	ErrKeyNotFound = errors.New("key not found")
+	// ErrInvalidDigest is returned if the secp256k1 signer is asked to sign a message which is not a digest.
+	ErrInvalidDigest = fmt.Errorf("digest must be %d bytes", DigestLength)
+)
+
+// Signer signs by a key which is held by the backend.
+type Signer interface {
+	// KeyType returns the type of the key.
+	KeyType() KeyType
+	// PublicKey returns the public key, the 33 bytes compressed key for secp256k1 and the 48 bytes key for bls.
+	PublicKey() []byte
+	// Sign signs the msg. The secp256k1 signer signs the 32 bytes digest and returns the 65 bytes [R || S || V]
+	// signature whose V is 0 or 1, the bls signer signs the msg.
+	Sign(msg []byte) ([]byte, error)
+}
+
+// Backend provides the signers of the keys by their ids.
+type Backend interface {
+	// Signer returns the signer of the key, ErrKeyNotFound is returned if the backend does not hold the key.
+	Signer(keyID string) (Signer, error)
+	// Close releases the resources of the backend.
+	Close() error
+}
+
+// secp256k1Signer is the signer of the secp256k1 private key in memory.
+type secp256k1Signer struct {
+	key    *ecdsa.PrivateKey
+	pubKey []byte
+}
+
+// NewSecp256k1Signer returns the signer of the hex secp256k1 private key in memory.
+func NewSecp256k1Signer(hexKey string) (Signer, error) {
+	key, err := crypto.HexToECDSA(hexKey)
+	if err != nil {
+		return nil, err
+	}
+	return &secp256k1Signer{key: key, pubKey: crypto.CompressPubkey(&key.PublicKey)}, nil
+}
+
+func (s *secp256k1Signer) KeyType() KeyType {
+	return KeyTypeSecp256k1
+}
+
+func (s *secp256k1Signer) PublicKey() []byte {
+	return s.pubKey
+}
+
+func (s *secp256k1Signer) Sign(digest []byte) ([]byte, error) {
+	if len(digest) != DigestLength {
+		return nil, ErrInvalidDigest
+	}
+	return crypto.Sign(digest, s.key)
+}
+
+// LocalBackend is the backend of the secp256k1 private keys in memory, it is used by the tests and the remote
+// signing service which loads the keys from its own secret store.
+type LocalBackend struct {
+	mux     sync.RWMutex
+	signers map[string]Signer
+}
+
+// NewLocalBackend returns the backend of the hex secp256k1 private keys keyed by the key ids.
+func NewLocalBackend(hexKeys map[string]string) (*LocalBackend, error) {
+	backend := &LocalBackend{signers: make(map[string]Signer, len(hexKeys))}
+	for keyID, hexKey := range hexKeys {
+		signer, err := NewSecp256k1Signer(hexKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %s: %w", keyID, err)
+		}
+		backend.signers[keyID] = signer
+	}
+	return backend, nil
+}
+
+// Add adds the signer of the key to the backend.
+func (b *LocalBackend) Add(keyID string, signer Signer) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.signers[keyID] = signer
+}
+
+func (b *LocalBackend) Signer(keyID string) (Signer, error) {
+	b.mux.RLock()
+	defer b.mux.RUnlock()
+	signer, ok := b.signers[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, keyID)
+	}
+	return signer, nil
+}
+
+func (b *LocalBackend) Close() error {
+	return nil
+}
+
+var (
+	secp256k1N     = crypto.S256().Params().N
+	secp256k1HalfN = new(big.Int).Rsh(secp256k1N, 1)
+)
+
+// RecoverableSignature converts the [R || S] signature of the digest signed by the secp256k1 key, e.g. the
+// signature of the HSM, to the [R || S || V] signature with the low S required by the chain.
+func RecoverableSignature(digest, sig, compressedPubKey []byte) ([]byte, error) {
+	if len(sig) != 64 {
+		return nil, fmt.Errorf("invalid secp256k1 signature length: %d", len(sig))
+	}
+	s := new(big.Int).SetBytes(sig[32:])
+	if s.Cmp(secp256k1HalfN) > 0 {
+		s.Sub(secp256k1N, s)
+	}
+	rsv := make([]byte, Secp256k1SignatureLength)
+	copy(rsv[:32], sig[:32])
+	s.FillBytes(rsv[32:64])
+	for v := byte(0); v < 2; v++ {
+		rsv[64] = v
+		pubKey, err := crypto.SigToPub(digest, rsv)
+		if err == nil && bytes.Equal(crypto.CompressPubkey(pubKey), compressedPubKey) {
+			return rsv, nil
+		}
+	}
+	return nil, errors.New("failed to recover the public key from the secp256k1 signature")
+}
+
+// PKCS11Config is the config of the PKCS#11 HSM holding the secp256k1 keys, the keys are found by their labels.
+type PKCS11Config struct {
+	// Module is the path of the PKCS#11 library, e.g. /usr/lib/softhsm/libsofthsm2.so.
+	Module string
+	// TokenLabel is the label of the token holding the keys.
+	TokenLabel string
+	// Pin is the user pin of the token.
+	Pin string
+}
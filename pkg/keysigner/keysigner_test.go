@@ -0,0 +1,82 @@
+package keysigner
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+const mockHexKey = "b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291"
+
+func TestSecp256k1Signer(t *testing.T) {
+	_, err := NewSecp256k1Signer("invalid")
+	assert.NotNil(t, err)
+
+	signer, err := NewSecp256k1Signer(mockHexKey)
+	assert.Nil(t, err)
+	assert.Equal(t, KeyTypeSecp256k1, signer.KeyType())
+	assert.Len(t, signer.PublicKey(), 33)
+
+	_, err = signer.Sign([]byte("not a digest"))
+	assert.Equal(t, ErrInvalidDigest, err)
+	digest := crypto.Keccak256([]byte("mock message"))
+	sig, err := signer.Sign(digest)
+	assert.Nil(t, err)
+	pubKey, err := crypto.SigToPub(digest, sig)
+	assert.Nil(t, err)
+	assert.Equal(t, signer.PublicKey(), crypto.CompressPubkey(pubKey))
+}
+
+func TestLocalBackend(t *testing.T) {
+	_, err := NewLocalBackend(map[string]string{"operator": "invalid"})
+	assert.NotNil(t, err)
+
+	backend, err := NewLocalBackend(map[string]string{"operator": mockHexKey})
+	assert.Nil(t, err)
+	defer backend.Close()
+	signer, err := backend.Signer("operator")
+	assert.Nil(t, err)
+	assert.Equal(t, KeyTypeSecp256k1, signer.KeyType())
+	_, err = backend.Signer("seal")
+	assert.True(t, errors.Is(err, ErrKeyNotFound))
+
+	backend.Add("seal", signer)
+	_, err = backend.Signer("seal")
+	assert.Nil(t, err)
+}
+
+func TestRecoverableSignature(t *testing.T) {
+	key, err := crypto.HexToECDSA(mockHexKey)
+	assert.Nil(t, err)
+	pubKey := crypto.CompressPubkey(&key.PublicKey)
+	digest := crypto.Keccak256([]byte("mock message"))
+	sig, err := crypto.Sign(digest, key)
+	assert.Nil(t, err)
+
+	// the HSM returns the [R || S] signature, the S may be high
+	highS := new(big.Int).Sub(secp256k1N, new(big.Int).SetBytes(sig[32:64]))
+	cases := []struct {
+		name string
+		rs   []byte
+	}{
+		{"low s", sig[:64]},
+		{"high s", append(append([]byte{}, sig[:32]...), highS.FillBytes(make([]byte, 32))...)},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			rsv, err := RecoverableSignature(digest, tt.rs, pubKey)
+			assert.Nil(t, err)
+			assert.Equal(t, sig, rsv)
+		})
+	}
+
+	_, err = RecoverableSignature(digest, sig, pubKey)
+	assert.NotNil(t, err)
+	other, err := crypto.GenerateKey()
+	assert.Nil(t, err)
+	_, err = RecoverableSignature(digest, sig[:64], crypto.CompressPubkey(&other.PublicKey))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,188 @@
+//go:build cgo
+
+package keysigner
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/miekg/pkcs11"
+)
+
+// secp256k1OID is the DER of the OID 1.3.132.0.10 in the CKA_EC_PARAMS of the secp256k1 keys.
+var secp256k1OID = []byte{0x06, 0x05, 0x2b, 0x81, 0x04, 0x00, 0x0a}
+
+// PKCS11Backend is the backend of the secp256k1 keys held by the PKCS#11 HSM, the private keys are not
+// extractable, the digests are signed in the HSM by CKM_ECDSA. The key id is the CKA_LABEL of the private key
+// and its public key.
+type PKCS11Backend struct {
+	mu      sync.Mutex
+	ctx     *pkcs11.Ctx
+	session pkcs11.SessionHandle
+}
+
+// NewPKCS11Backend loads the PKCS#11 library and logs in the token.
+func NewPKCS11Backend(cfg PKCS11Config) (*PKCS11Backend, error) {
+	if cfg.Module == "" || cfg.TokenLabel == "" {
+		return nil, errors.New("pkcs11 module and token label are required")
+	}
+	ctx := pkcs11.New(cfg.Module)
+	if ctx == nil {
+		return nil, fmt.Errorf("failed to load pkcs11 module %s", cfg.Module)
+	}
+	if err := ctx.Initialize(); err != nil {
+		ctx.Destroy()
+		return nil, err
+	}
+	session, err := openSession(ctx, cfg)
+	if err != nil {
+		_ = ctx.Finalize()
+		ctx.Destroy()
+		return nil, err
+	}
+	return &PKCS11Backend{ctx: ctx, session: session}, nil
+}
+
+func openSession(ctx *pkcs11.Ctx, cfg PKCS11Config) (pkcs11.SessionHandle, error) {
+	slots, err := ctx.GetSlotList(true)
+	if err != nil {
+		return 0, err
+	}
+	for _, slot := range slots {
+		info, err := ctx.GetTokenInfo(slot)
+		if err != nil || strings.TrimSpace(info.Label) != cfg.TokenLabel {
+			continue
+		}
+		session, err := ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION)
+		if err != nil {
+			return 0, err
+		}
+		if err = ctx.Login(session, pkcs11.CKU_USER, cfg.Pin); err != nil {
+			_ = ctx.CloseSession(session)
+			return 0, err
+		}
+		return session, nil
+	}
+	return 0, fmt.Errorf("pkcs11 token %s not found", cfg.TokenLabel)
+}
+
+func (b *PKCS11Backend) Signer(keyID string) (Signer, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	privKey, err := b.findObject(pkcs11.CKO_PRIVATE_KEY, keyID)
+	if err != nil {
+		return nil, err
+	}
+	pubKey, err := b.findObject(pkcs11.CKO_PUBLIC_KEY, keyID)
+	if err != nil {
+		return nil, err
+	}
+	attrs, err := b.ctx.GetAttributeValue(b.session, pubKey, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, nil),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
+	})
+	if err != nil {
+		return nil, err
+	}
+	var params, point []byte
+	for _, attr := range attrs {
+		switch attr.Type {
+		case pkcs11.CKA_EC_PARAMS:
+			params = attr.Value
+		case pkcs11.CKA_EC_POINT:
+			point = attr.Value
+		}
+	}
+	if !bytes.Equal(params, secp256k1OID) {
+		return nil, fmt.Errorf("key %s is not a secp256k1 key", keyID)
+	}
+	compressed, err := compressECPoint(point)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key of key %s: %w", keyID, err)
+	}
+	return &pkcs11Signer{backend: b, handle: privKey, pubKey: compressed}, nil
+}
+
+func (b *PKCS11Backend) findObject(class uint, label string) (pkcs11.ObjectHandle, error) {
+	if err := b.ctx.FindObjectsInit(b.session, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_EC),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+	}); err != nil {
+		return 0, err
+	}
+	objects, _, err := b.ctx.FindObjects(b.session, 1)
+	finalErr := b.ctx.FindObjectsFinal(b.session)
+	if err != nil {
+		return 0, err
+	}
+	if finalErr != nil {
+		return 0, finalErr
+	}
+	if len(objects) == 0 {
+		return 0, fmt.Errorf("%w: %s", ErrKeyNotFound, label)
+	}
+	return objects[0], nil
+}
+
+// compressECPoint compresses the CKA_EC_POINT, which is the DER octet string of the uncompressed point, some
+// HSMs return the raw point.
+func compressECPoint(point []byte) ([]byte, error) {
+	raw := point
+	if len(point) != 65 || point[0] != 0x04 {
+		if _, err := asn1.Unmarshal(point, &raw); err != nil {
+			return nil, err
+		}
+	}
+	pubKey, err := crypto.UnmarshalPubkey(raw)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.CompressPubkey(pubKey), nil
+}
+
+func (b *PKCS11Backend) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_ = b.ctx.Logout(b.session)
+	_ = b.ctx.CloseSession(b.session)
+	err := b.ctx.Finalize()
+	b.ctx.Destroy()
+	return err
+}
+
+type pkcs11Signer struct {
+	backend *PKCS11Backend
+	handle  pkcs11.ObjectHandle
+	pubKey  []byte
+}
+
+func (s *pkcs11Signer) KeyType() KeyType {
+	return KeyTypeSecp256k1
+}
+
+func (s *pkcs11Signer) PublicKey() []byte {
+	return s.pubKey
+}
+
+func (s *pkcs11Signer) Sign(digest []byte) ([]byte, error) {
+	if len(digest) != DigestLength {
+		return nil, ErrInvalidDigest
+	}
+	s.backend.mu.Lock()
+	defer s.backend.mu.Unlock()
+	if err := s.backend.ctx.SignInit(s.backend.session,
+		[]*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_ECDSA, nil)}, s.handle); err != nil {
+		return nil, err
+	}
+	sig, err := s.backend.ctx.Sign(s.backend.session, digest)
+	if err != nil {
+		return nil, err
+	}
+	return RecoverableSignature(digest, sig, s.pubKey)
+}
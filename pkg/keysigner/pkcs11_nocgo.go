@@ -0,0 +1,21 @@
+//go:build !cgo
+
+package keysigner
+
+import "errors"
+
+// PKCS11Backend is the backend of the keys held by the PKCS#11 HSM, it requires cgo.
+type PKCS11Backend struct{}
+
+// NewPKCS11Backend returns an error since the PKCS#11 library is loaded by cgo.
+func NewPKCS11Backend(cfg PKCS11Config) (*PKCS11Backend, error) {
+	return nil, errors.New("pkcs11 backend requires cgo")
+}
+
+func (b *PKCS11Backend) Signer(keyID string) (Signer, error) {
+	return nil, errors.New("pkcs11 backend requires cgo")
+}
+
+func (b *PKCS11Backend) Close() error {
+	return nil
+}
@@ -0,0 +1,62 @@
+//go:build cgo
+
+package keysigner
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/miekg/pkcs11"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPKCS11Backend runs against the token of SoftHSM, e.g.
+//
+//	softhsm2-util --init-token --free --label sp --pin 1234 --so-pin 1234
+//	PKCS11_MODULE=/usr/lib/softhsm/libsofthsm2.so PKCS11_TOKEN_LABEL=sp PKCS11_PIN=1234 go test ./pkg/keysigner/
+func TestPKCS11Backend(t *testing.T) {
+	cfg := PKCS11Config{
+		Module:     os.Getenv("PKCS11_MODULE"),
+		TokenLabel: os.Getenv("PKCS11_TOKEN_LABEL"),
+		Pin:        os.Getenv("PKCS11_PIN"),
+	}
+	if cfg.Module == "" {
+		t.Skip("PKCS11_MODULE is not set")
+	}
+	backend, err := NewPKCS11Backend(cfg)
+	assert.Nil(t, err)
+	defer backend.Close()
+
+	label := "keysigner-test"
+	_, _, err = backend.ctx.GenerateKeyPair(backend.session,
+		[]*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_EC_KEY_PAIR_GEN, nil)},
+		[]*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_TOKEN, false),
+			pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+			pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, secp256k1OID),
+			pkcs11.NewAttribute(pkcs11.CKA_VERIFY, true),
+		},
+		[]*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_TOKEN, false),
+			pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+			pkcs11.NewAttribute(pkcs11.CKA_SIGN, true),
+			pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, true),
+			pkcs11.NewAttribute(pkcs11.CKA_EXTRACTABLE, false),
+		})
+	assert.Nil(t, err)
+
+	signer, err := backend.Signer(label)
+	assert.Nil(t, err)
+	for i := 0; i < 10; i++ {
+		digest := crypto.Keccak256([]byte{byte(i)})
+		sig, err := signer.Sign(digest)
+		assert.Nil(t, err)
+		pubKey, err := crypto.SigToPub(digest, sig)
+		assert.Nil(t, err)
+		assert.Equal(t, signer.PublicKey(), crypto.CompressPubkey(pubKey))
+	}
+	_, err = backend.Signer("not-exist")
+	assert.True(t, errors.Is(err, ErrKeyNotFound))
+}
@@ -0,0 +1,413 @@
+package keysigner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	// RemoteSignServiceName defines the gRPC service of the remote signing service.
+	RemoteSignServiceName = "keysigner.RemoteSignService"
+	// DefaultRemoteTimeout defines the default timeout of the requests to the remote signing service.
+	DefaultRemoteTimeout = 5 * time.Second
+
+	getPublicKeyMethod = "/" + RemoteSignServiceName + "/GetPublicKey"
+	signMethod         = "/" + RemoteSignServiceName + "/Sign"
+)
+
+// ErrInsecureRemoteSigner is returned if the remote signing service is not on the loopback address and the
+// connection is not secured by the transport credentials.
+var ErrInsecureRemoteSigner = errors.New("remote signer on a non-loopback address requires tls")
+
+// The messages of the remote signing service, they are encoded as the protobuf messages below, so the service
+// can be implemented in any language:
+//
+//	service RemoteSignService {
+//	  rpc GetPublicKey(GetPublicKeyRequest) returns (GetPublicKeyResponse);
+//	  rpc Sign(SignRequest) returns (SignResponse);
+//	}
+//	message GetPublicKeyRequest { string key_id = 1; }
+//	message GetPublicKeyResponse { string key_type = 1; bytes public_key = 2; }
+//	message SignRequest { string key_id = 1; bytes msg = 2; }
+//	message SignResponse { bytes signature = 1; }
+type (
+	getPublicKeyRequest struct {
+		keyID string
+	}
+	getPublicKeyResponse struct {
+		keyType   string
+		publicKey []byte
+	}
+	signRequest struct {
+		keyID string
+		msg   []byte
+	}
+	signResponse struct {
+		signature []byte
+	}
+)
+
+// wireMessage is the message of the remote signing service encoded by protowire.
+type wireMessage interface {
+	marshal() []byte
+	unmarshal(b []byte) error
+}
+
+func (m *getPublicKeyRequest) marshal() []byte {
+	return appendString(nil, 1, m.keyID)
+}
+
+func (m *getPublicKeyRequest) unmarshal(b []byte) error {
+	return unmarshalFields(b, func(num protowire.Number, v []byte) {
+		if num == 1 {
+			m.keyID = string(v)
+		}
+	})
+}
+
+func (m *getPublicKeyResponse) marshal() []byte {
+	return appendBytes(appendString(nil, 1, m.keyType), 2, m.publicKey)
+}
+
+func (m *getPublicKeyResponse) unmarshal(b []byte) error {
+	return unmarshalFields(b, func(num protowire.Number, v []byte) {
+		switch num {
+		case 1:
+			m.keyType = string(v)
+		case 2:
+			m.publicKey = append([]byte(nil), v...)
+		}
+	})
+}
+
+func (m *signRequest) marshal() []byte {
+	return appendBytes(appendString(nil, 1, m.keyID), 2, m.msg)
+}
+
+func (m *signRequest) unmarshal(b []byte) error {
+	return unmarshalFields(b, func(num protowire.Number, v []byte) {
+		switch num {
+		case 1:
+			m.keyID = string(v)
+		case 2:
+			m.msg = append([]byte(nil), v...)
+		}
+	})
+}
+
+func (m *signResponse) marshal() []byte {
+	return appendBytes(nil, 1, m.signature)
+}
+
+func (m *signResponse) unmarshal(b []byte) error {
+	return unmarshalFields(b, func(num protowire.Number, v []byte) {
+		if num == 1 {
+			m.signature = append([]byte(nil), v...)
+		}
+	})
+}
+
+func appendString(b []byte, num protowire.Number, v string) []byte {
+	if v == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, v)
+}
+
+func appendBytes(b []byte, num protowire.Number, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+// unmarshalFields calls the fn with the length-delimited fields and skips the others.
+func unmarshalFields(b []byte, fn func(num protowire.Number, v []byte)) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		if typ != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			b = b[n:]
+			continue
+		}
+		v, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		fn(num, v)
+		b = b[n:]
+	}
+	return nil
+}
+
+// codec encodes the messages of the remote signing service, the other messages are encoded by the default
+// proto codec, so the server option is safe for the servers of other services.
+type codec struct{}
+
+func (codec) Marshal(v interface{}) ([]byte, error) {
+	if m, ok := v.(wireMessage); ok {
+		return m.marshal(), nil
+	}
+	return encoding.GetCodec("proto").Marshal(v)
+}
+
+func (codec) Unmarshal(data []byte, v interface{}) error {
+	if m, ok := v.(wireMessage); ok {
+		return m.unmarshal(data)
+	}
+	return encoding.GetCodec("proto").Unmarshal(data, v)
+}
+
+func (codec) Name() string {
+	return "proto"
+}
+
+// RemoteConfig is the config of the remote signing service.
+type RemoteConfig struct {
+	// Address is the gRPC address of the remote signing service.
+	Address string
+	// Creds is the transport credentials of the connection, e.g. the mutual TLS. The connection is insecure if
+	// it is nil, which is only allowed for the loopback address.
+	Creds credentials.TransportCredentials
+	// Timeout is the timeout of every request.
+	Timeout time.Duration
+}
+
+// RemoteBackend is the backend of the keys held by the remote signing service.
+type RemoteBackend struct {
+	conn    *grpc.ClientConn
+	timeout time.Duration
+}
+
+// NewRemoteBackend returns the backend connecting to the remote signing service.
+func NewRemoteBackend(cfg RemoteConfig) (*RemoteBackend, error) {
+	if cfg.Address == "" {
+		return nil, errors.New("remote signer address is required")
+	}
+	if cfg.Creds == nil {
+		if !isLoopbackAddress(cfg.Address) {
+			return nil, ErrInsecureRemoteSigner
+		}
+		cfg.Creds = insecure.NewCredentials()
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultRemoteTimeout
+	}
+	conn, err := grpc.Dial(cfg.Address, grpc.WithTransportCredentials(cfg.Creds),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(codec{})))
+	if err != nil {
+		return nil, err
+	}
+	return &RemoteBackend{conn: conn, timeout: cfg.Timeout}, nil
+}
+
+func (b *RemoteBackend) Signer(keyID string) (Signer, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+	resp := &getPublicKeyResponse{}
+	if err := b.conn.Invoke(ctx, getPublicKeyMethod, &getPublicKeyRequest{keyID: keyID}, resp); err != nil {
+		return nil, fromStatus(keyID, err)
+	}
+	keyType := KeyType(resp.keyType)
+	if keyType != KeyTypeSecp256k1 && keyType != KeyTypeBLS {
+		return nil, fmt.Errorf("unsupported key type %s of key %s", resp.keyType, keyID)
+	}
+	if keyType == KeyTypeSecp256k1 {
+		if _, err := crypto.DecompressPubkey(resp.publicKey); err != nil {
+			return nil, fmt.Errorf("invalid public key of key %s: %w", keyID, err)
+		}
+	}
+	return &remoteSigner{backend: b, keyID: keyID, keyType: keyType, pubKey: resp.publicKey}, nil
+}
+
+func (b *RemoteBackend) Close() error {
+	return b.conn.Close()
+}
+
+type remoteSigner struct {
+	backend *RemoteBackend
+	keyID   string
+	keyType KeyType
+	pubKey  []byte
+}
+
+func (s *remoteSigner) KeyType() KeyType {
+	return s.keyType
+}
+
+func (s *remoteSigner) PublicKey() []byte {
+	return s.pubKey
+}
+
+func (s *remoteSigner) Sign(msg []byte) ([]byte, error) {
+	if s.keyType == KeyTypeSecp256k1 && len(msg) != DigestLength {
+		return nil, ErrInvalidDigest
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.backend.timeout)
+	defer cancel()
+	resp := &signResponse{}
+	if err := s.backend.conn.Invoke(ctx, signMethod, &signRequest{keyID: s.keyID, msg: msg}, resp); err != nil {
+		return nil, fromStatus(s.keyID, err)
+	}
+	if s.keyType == KeyTypeSecp256k1 {
+		// verify the signature, so a misconfigured service never makes the txs signed by an unexpected key
+		pubKey, err := crypto.SigToPub(msg, resp.signature)
+		if err != nil || !bytes.Equal(crypto.CompressPubkey(pubKey), s.pubKey) {
+			return nil, fmt.Errorf("invalid signature of key %s from remote signer", s.keyID)
+		}
+	}
+	return resp.signature, nil
+}
+
+func fromStatus(keyID string, err error) error {
+	if status.Code(err) == codes.NotFound {
+		return fmt.Errorf("%w: %s", ErrKeyNotFound, keyID)
+	}
+	return err
+}
+
+// Server serves the keys of the backend as the remote signing service, e.g. it runs next to the HSM in an
+// isolated environment. The requests are only served if the client certificate is verified by the mutual TLS
+// of the gRPC server, or the client is on the loopback address.
+type Server struct {
+	backend Backend
+}
+
+// NewServer returns the remote signing service of the backend.
+func NewServer(backend Backend) *Server {
+	return &Server{backend: backend}
+}
+
+// ServerOption returns the option of the gRPC server to encode the messages of the remote signing service. The
+// server is expected to be created with the mutual TLS credentials which require and verify the client
+// certificates, otherwise only the loopback clients are served.
+func ServerOption() grpc.ServerOption {
+	return grpc.ForceServerCodec(codec{})
+}
+
+// Register registers the remote signing service to the gRPC server created with the ServerOption.
+func (s *Server) Register(server *grpc.Server) {
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: RemoteSignServiceName,
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{
+			{MethodName: "GetPublicKey", Handler: s.getPublicKeyHandler},
+			{MethodName: "Sign", Handler: s.signHandler},
+		},
+		Metadata: "keysigner",
+	}, s)
+}
+
+func (s *Server) getPublicKeyHandler(_ interface{}, ctx context.Context, dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	req := &getPublicKeyRequest{}
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		if err := authorize(ctx); err != nil {
+			return nil, err
+		}
+		signer, err := s.signer(req.(*getPublicKeyRequest).keyID)
+		if err != nil {
+			return nil, err
+		}
+		return &getPublicKeyResponse{keyType: string(signer.KeyType()), publicKey: signer.PublicKey()}, nil
+	}
+	if interceptor == nil {
+		return handler(ctx, req)
+	}
+	return interceptor(ctx, req, &grpc.UnaryServerInfo{Server: s, FullMethod: getPublicKeyMethod}, handler)
+}
+
+func (s *Server) signHandler(_ interface{}, ctx context.Context, dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	req := &signRequest{}
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		if err := authorize(ctx); err != nil {
+			return nil, err
+		}
+		r := req.(*signRequest)
+		signer, err := s.signer(r.keyID)
+		if err != nil {
+			return nil, err
+		}
+		signature, err := signer.Sign(r.msg)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return &signResponse{signature: signature}, nil
+	}
+	if interceptor == nil {
+		return handler(ctx, req)
+	}
+	return interceptor(ctx, req, &grpc.UnaryServerInfo{Server: s, FullMethod: signMethod}, handler)
+}
+
+func (s *Server) signer(keyID string) (Signer, error) {
+	signer, err := s.backend.Signer(keyID)
+	if errors.Is(err, ErrKeyNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return signer, nil
+}
+
+// authorize accepts the client whose certificate is verified by the mutual TLS, or the client on the loopback
+// address.
+func authorize(ctx context.Context) error {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "unknown remote signer client")
+	}
+	if info, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(info.State.VerifiedChains) != 0 {
+		return nil
+	}
+	if p.Addr != nil && isLoopbackAddress(p.Addr.String()) {
+		return nil
+	}
+	return status.Error(codes.Unauthenticated, "remote signer client certificate is required")
+}
+
+// isLoopbackAddress returns whether the host of the address is localhost or a loopback ip.
+func isLoopbackAddress(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
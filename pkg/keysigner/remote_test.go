@@ -0,0 +1,154 @@
+package keysigner
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+type mockBLSSigner struct{}
+
+func (mockBLSSigner) KeyType() KeyType                { return KeyTypeBLS }
+func (mockBLSSigner) PublicKey() []byte               { return make([]byte, 48) }
+func (mockBLSSigner) Sign(msg []byte) ([]byte, error) { return append([]byte("bls:"), msg...), nil }
+
+type mockWrongSigner struct{ Signer }
+
+func (s mockWrongSigner) Sign(digest []byte) ([]byte, error) {
+	other, _ := NewSecp256k1Signer("8a6d31fbf3c0c5e0e9f4d8d2e5a1f5f6c1d4c6b9b2a4e3f7d1c8b5a6e4f3d2c1")
+	return other.Sign(digest)
+}
+
+func startMockRemoteSigner(t *testing.T) string {
+	backend, err := NewLocalBackend(map[string]string{"operator": mockHexKey})
+	assert.Nil(t, err)
+	backend.Add("bls", mockBLSSigner{})
+	operator, _ := backend.Signer("operator")
+	backend.Add("wrong", mockWrongSigner{operator})
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	server := grpc.NewServer(ServerOption())
+	NewServer(backend).Register(server)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	return lis.Addr().String()
+}
+
+func TestRemoteBackend(t *testing.T) {
+	_, err := NewRemoteBackend(RemoteConfig{})
+	assert.NotNil(t, err)
+
+	backend, err := NewRemoteBackend(RemoteConfig{Address: startMockRemoteSigner(t)})
+	assert.Nil(t, err)
+	defer backend.Close()
+
+	local, err := NewSecp256k1Signer(mockHexKey)
+	assert.Nil(t, err)
+	signer, err := backend.Signer("operator")
+	assert.Nil(t, err)
+	assert.Equal(t, KeyTypeSecp256k1, signer.KeyType())
+	assert.Equal(t, local.PublicKey(), signer.PublicKey())
+	digest := crypto.Keccak256([]byte("mock message"))
+	sig, err := signer.Sign(digest)
+	assert.Nil(t, err)
+	expected, _ := local.Sign(digest)
+	assert.Equal(t, expected, sig)
+	_, err = signer.Sign([]byte("not a digest"))
+	assert.Equal(t, ErrInvalidDigest, err)
+
+	bls, err := backend.Signer("bls")
+	assert.Nil(t, err)
+	assert.Equal(t, KeyTypeBLS, bls.KeyType())
+	sig, err = bls.Sign([]byte("msg"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("bls:msg"), sig)
+
+	_, err = backend.Signer("seal")
+	assert.True(t, errors.Is(err, ErrKeyNotFound))
+
+	wrong, err := backend.Signer("wrong")
+	assert.Nil(t, err)
+	_, err = wrong.Sign(digest)
+	assert.NotNil(t, err)
+}
+
+func TestNewRemoteBackend_Insecure(t *testing.T) {
+	cases := []struct {
+		address   string
+		wantedErr error
+	}{
+		{"127.0.0.1:9444", nil},
+		{"localhost:9444", nil},
+		{"[::1]:9444", nil},
+		{"signer.internal:9444", ErrInsecureRemoteSigner},
+		{"10.0.0.1:9444", ErrInsecureRemoteSigner},
+	}
+	for _, tt := range cases {
+		t.Run(tt.address, func(t *testing.T) {
+			backend, err := NewRemoteBackend(RemoteConfig{Address: tt.address})
+			assert.Equal(t, tt.wantedErr, err)
+			if backend != nil {
+				_ = backend.Close()
+			}
+		})
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	verified := credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{}}}}}
+	cases := []struct {
+		name        string
+		peer        *peer.Peer
+		wantedAllow bool
+	}{
+		{"loopback", &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1}}, true},
+		{"remote without tls", &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 1}}, false},
+		{"remote without client certificate", &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 1},
+			AuthInfo: credentials.TLSInfo{}}, false},
+		{"remote with client certificate", &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 1},
+			AuthInfo: verified}, true},
+		{"no peer", nil, false},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.peer != nil {
+				ctx = peer.NewContext(ctx, tt.peer)
+			}
+			err := authorize(ctx)
+			if tt.wantedAllow {
+				assert.Nil(t, err)
+			} else {
+				assert.Equal(t, codes.Unauthenticated, status.Code(err))
+			}
+		})
+	}
+}
+
+func TestWireMessage(t *testing.T) {
+	req := &signRequest{keyID: "operator", msg: []byte("msg")}
+	decoded := &signRequest{}
+	assert.Nil(t, decoded.unmarshal(req.marshal()))
+	assert.Equal(t, req, decoded)
+
+	// the unknown fields are skipped
+	b := protowire.AppendTag(req.marshal(), 3, protowire.VarintType)
+	b = protowire.AppendVarint(b, 1)
+	decoded = &signRequest{}
+	assert.Nil(t, decoded.unmarshal(b))
+	assert.Equal(t, req, decoded)
+	assert.NotNil(t, decoded.unmarshal([]byte{0x0a, 0x05}))
+}
@@ -5,11 +5,41 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
 )
 
+const (
+	// RotateHourly rotates the log file every hour, it is the default rotate interval.
+	RotateHourly = "hour"
+	// RotateDaily rotates the log file every day.
+	RotateDaily = "day"
+)
+
+// RotateConfig defines the rotation and the retention of the log files. The log file is named
+// <path>.<time>[.<index>] and <path> links to the current one, the index is increased once the file
+// exceeds MaxSize in the same interval.
+type RotateConfig struct {
+	// Interval is RotateHourly or RotateDaily.
+	Interval string
+	// MaxSize is the max bytes of a log file, zero means no limit.
+	MaxSize int64
+	// MaxBackups is the max number of the rotated log files kept, zero means no limit.
+	MaxBackups int
+	// MaxAge is the max duration the rotated log files are kept since modified, zero means no limit.
+	MaxAge time.Duration
+}
+
+func (c RotateConfig) timeLayout() string {
+	if c.Interval == RotateDaily {
+		return "2006-01-02"
+	}
+	return "2006-01-02_15"
+}
+
 type HourTicker struct {
 	stop chan struct{}
 	C    <-chan time.Time
@@ -51,25 +81,35 @@ func (ht *HourTicker) Ticker() <-chan time.Time {
 type AsyncFileWriter struct {
 	filePath string
 	fd       *os.File
-
-	wg         sync.WaitGroup
-	started    int32
-	buf        chan []byte
-	stop       chan struct{}
-	hourTicker *HourTicker
+	rotate   RotateConfig
+	// period and index identify the current log file, size is its bytes
+	period string
+	index  int
+	size   int64
+
+	wg      sync.WaitGroup
+	started int32
+	buf     chan []byte
+	stop    chan struct{}
 }
 
+// NewAsyncFileWriter returns an AsyncFileWriter which rotates the log file hourly and keeps all the rotated ones.
 func NewAsyncFileWriter(filePath string, bufSize int64) *AsyncFileWriter {
+	return NewRotateFileWriter(filePath, bufSize, RotateConfig{})
+}
+
+// NewRotateFileWriter returns an AsyncFileWriter which rotates and cleans the log files by the config.
+func NewRotateFileWriter(filePath string, bufSize int64, rotate RotateConfig) *AsyncFileWriter {
 	absFilePath, err := filepath.Abs(filePath)
 	if err != nil {
 		logger.With("filePath", filePath, "err", err).Panic("get file path of logger error")
 	}
 
 	w := &AsyncFileWriter{
-		filePath:   absFilePath,
-		buf:        make(chan []byte, bufSize),
-		stop:       make(chan struct{}),
-		hourTicker: NewHourTicker(),
+		filePath: absFilePath,
+		rotate:   rotate,
+		buf:      make(chan []byte, bufSize),
+		stop:     make(chan struct{}),
 	}
 
 	if err := w.Start(); err != nil {
@@ -85,13 +125,31 @@ func (w *AsyncFileWriter) initLogFile() error {
 		err error
 	)
 
-	realFilePath := w.timeFilePath(w.filePath)
+	period := time.Now().Format(w.rotate.timeLayout())
+	if period != w.period {
+		w.period = period
+		w.index = 0
+	}
+	realFilePath := w.indexFilePath()
+	// skip the full files of the interval written before restart
+	for w.rotate.MaxSize > 0 {
+		info, statErr := os.Stat(realFilePath)
+		if statErr != nil || info.Size() < w.rotate.MaxSize {
+			break
+		}
+		w.index++
+		realFilePath = w.indexFilePath()
+	}
 	fd, err = os.OpenFile(realFilePath, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0644)
 	if err != nil {
 		return err
 	}
 
 	w.fd = fd
+	w.size = 0
+	if info, statErr := fd.Stat(); statErr == nil {
+		w.size = info.Size()
+	}
 	_, err = os.Lstat(w.filePath)
 	if err == nil || os.IsExist(err) {
 		err = os.Remove(w.filePath)
@@ -117,6 +175,7 @@ func (w *AsyncFileWriter) Start() error {
 	if err != nil {
 		return err
 	}
+	w.cleanFiles()
 
 	w.wg.Add(1)
 	go func() {
@@ -157,30 +216,70 @@ func (w *AsyncFileWriter) flushBuffer() {
 }
 
 func (w *AsyncFileWriter) SyncWrite(msg []byte) {
-	w.rotateFile()
+	w.rotateFile(int64(len(msg)))
 	if w.fd != nil {
-		w.fd.Write(msg)
+		n, _ := w.fd.Write(msg)
+		w.size += int64(n)
 	}
 }
 
-func (w *AsyncFileWriter) rotateFile() {
-	select {
-	case <-w.hourTicker.C:
-		if err := w.flushAndClose(); err != nil {
-			fmt.Fprintf(os.Stderr, "flush and close file error. err=%s", err)
+// rotateFile rotates the log file if the interval is passed or the file is full.
+func (w *AsyncFileWriter) rotateFile(size int64) {
+	period := time.Now().Format(w.rotate.timeLayout())
+	if period == w.period && (w.rotate.MaxSize <= 0 || w.size == 0 || w.size+size <= w.rotate.MaxSize) {
+		return
+	}
+	if period == w.period {
+		w.index++
+	}
+	if err := w.flushAndClose(); err != nil {
+		fmt.Fprintf(os.Stderr, "flush and close file error. err=%s", err)
+	}
+	if err := w.initLogFile(); err != nil {
+		fmt.Fprintf(os.Stderr, "init log file error. err=%s", err)
+	}
+	w.cleanFiles()
+}
+
+// cleanFiles removes the rotated log files exceeding MaxBackups or MaxAge.
+func (w *AsyncFileWriter) cleanFiles() {
+	if w.rotate.MaxBackups <= 0 && w.rotate.MaxAge <= 0 {
+		return
+	}
+	matches, err := filepath.Glob(w.filePath + ".*")
+	if err != nil {
+		return
+	}
+	current := w.indexFilePath()
+	type backup struct {
+		path    string
+		modTime time.Time
+	}
+	var backups []backup
+	for _, match := range matches {
+		if match == current {
+			continue
 		}
-		if err := w.initLogFile(); err != nil {
-			fmt.Fprintf(os.Stderr, "init log file error. err=%s", err)
+		info, statErr := os.Lstat(match)
+		if statErr != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		backups = append(backups, backup{path: match, modTime: info.ModTime()})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].modTime.After(backups[j].modTime) })
+	for i, b := range backups {
+		if (w.rotate.MaxBackups > 0 && i >= w.rotate.MaxBackups) ||
+			(w.rotate.MaxAge > 0 && time.Since(b.modTime) > w.rotate.MaxAge) {
+			if err = os.Remove(b.path); err != nil {
+				fmt.Fprintf(os.Stderr, "remove log file error. err=%s", err)
+			}
 		}
-	default:
 	}
 }
 
 func (w *AsyncFileWriter) Stop() error {
 	w.stop <- struct{}{}
 	w.wg.Wait()
-
-	w.hourTicker.Stop()
 	return nil
 }
 
@@ -215,6 +314,9 @@ func (w *AsyncFileWriter) flushAndClose() error {
 	return w.fd.Close()
 }
 
-func (w *AsyncFileWriter) timeFilePath(filePath string) string {
-	return filePath + "." + time.Now().Format("2006-01-02_15")
+func (w *AsyncFileWriter) indexFilePath() string {
+	if w.index == 0 {
+		return w.filePath + "." + w.period
+	}
+	return w.filePath + "." + w.period + "." + strconv.Itoa(w.index)
 }
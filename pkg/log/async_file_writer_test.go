@@ -2,8 +2,12 @@ package log
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestWriter(t *testing.T) {
@@ -22,3 +26,33 @@ func TestWriter(t *testing.T) {
 		}
 	}
 }
+
+func TestRotateFileWriter(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "rotate.log")
+	// a backup rotated long ago is removed on start
+	expired := filePath + ".2000-01-01_00"
+	assert.Nil(t, os.WriteFile(expired, []byte("expired\n"), 0o644))
+	assert.Nil(t, os.Chtimes(expired, time.Now().Add(-48*time.Hour), time.Now().Add(-48*time.Hour)))
+
+	w := NewRotateFileWriter(filePath, 100, RotateConfig{Interval: RotateDaily, MaxSize: 10, MaxBackups: 2, MaxAge: 24 * time.Hour})
+	_, err := os.Stat(expired)
+	assert.True(t, os.IsNotExist(err))
+	// each line exceeds the max size with the previous one, so it is written to a new file
+	for _, line := range []string{"line-1\n", "line-2\n", "line-3\n", "line-4\n"} {
+		w.SyncWrite([]byte(line))
+	}
+	assert.Nil(t, w.Stop())
+
+	matches, err := filepath.Glob(filePath + ".*")
+	assert.Nil(t, err)
+	// the current file and the 2 newest backups
+	assert.Equal(t, 3, len(matches))
+	content, err := os.ReadFile(filePath)
+	assert.Nil(t, err)
+	assert.Equal(t, "line-4\n", string(content))
+	content, err = os.ReadFile(w.indexFilePath())
+	assert.Nil(t, err)
+	assert.Equal(t, "line-4\n", string(content))
+	assert.Equal(t, 3, w.index)
+}
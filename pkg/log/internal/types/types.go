@@ -3,6 +3,7 @@ package types
 import (
 	"context"
 	"io"
+	"time"
 )
 
 type Level int
@@ -26,6 +27,24 @@ const (
 	PanicLevel
 )
 
+// String returns the lower case name of the level.
+func (l Level) String() string {
+	switch l {
+	case DebugLevel:
+		return "debug"
+	case InfoLevel:
+		return "info"
+	case WarnLevel:
+		return "warn"
+	case ErrorLevel:
+		return "error"
+	case PanicLevel:
+		return "panic"
+	default:
+		return "unknown"
+	}
+}
+
 type Logger interface {
 	// SetLevel resets enabled log level
 	SetLevel(lvl Level)
@@ -33,6 +52,20 @@ type Logger interface {
 	// SetWriter resets log writer
 	SetWriter(w AsyncWriter)
 
+	// SetModuleLevel sets the level of the module, which overrides the global level for the callers whose
+	// file path contains /<module>/, e.g. executor or modular/executor.
+	SetModuleLevel(module string, lvl Level)
+
+	// UnsetModuleLevel removes the level of the module.
+	UnsetModuleLevel(module string)
+
+	// Levels returns the global level and the module levels.
+	Levels() (Level, map[string]Level)
+
+	// SetSampling logs the first entries with the same level and message in each tick, and then every
+	// thereafter entries. The sampling is disabled if tick or first is not positive.
+	SetSampling(tick time.Duration, first, thereafter int)
+
 	// AddCallerSkip increases the number of callers skipped by caller annotation
 	// (as enabled by the AddCaller option). When building wrappers around the
 	// Logger and SugaredLogger, supplying this Option prevents zap from always
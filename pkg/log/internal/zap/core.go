@@ -0,0 +1,231 @@
+package zap
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// filterCore filters the entries by the level of the module which the caller belongs to, and samples the
+// repeated entries. The state is shared by the derived cores, so the changes take effect on all the loggers.
+type filterCore struct {
+	zapcore.Core
+	level   *levelEnabler
+	sampler *atomic.Pointer[sampler]
+}
+
+func (c *filterCore) Enabled(lvl zapcore.Level) bool {
+	return c.level.Enabled(lvl)
+}
+
+func (c *filterCore) With(fields []zapcore.Field) zapcore.Core {
+	return &filterCore{Core: c.Core.With(fields), level: c.level, sampler: c.sampler}
+}
+
+func (c *filterCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write drops the entry if it is below the level of its module, the caller is only known here.
+func (c *filterCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if !c.level.EnabledFor(ent.Level, ent.Caller.File) {
+		return nil
+	}
+	if s := c.sampler.Load(); s != nil && !s.allow(ent) {
+		return nil
+	}
+	return c.Core.Write(ent, fields)
+}
+
+// moduleLevel is the level of the files whose path contains /<module>/.
+type moduleLevel struct {
+	module  string
+	pattern string
+	level   zapcore.Level
+}
+
+// moduleLevels is immutable, a new one is built on each change so that the cache is dropped with it.
+type moduleLevels struct {
+	levels []moduleLevel
+	// cache caches the index of the matched module level of the file, -1 if none is matched
+	cache sync.Map
+}
+
+func (m *moduleLevels) match(file string) int {
+	if idx, ok := m.cache.Load(file); ok {
+		return idx.(int)
+	}
+	idx := -1
+	for i, l := range m.levels {
+		if strings.Contains(file, l.pattern) {
+			idx = i
+			break
+		}
+	}
+	m.cache.Store(file, idx)
+	return idx
+}
+
+type levelEnabler struct {
+	mu sync.Mutex
+	// rawLevel is the global level, minLevel is the lowest of the global level and the module levels
+	rawLevel int32
+	minLevel int32
+	modules  atomic.Pointer[moduleLevels]
+}
+
+func newLevelEnabler(lvl zapcore.Level) *levelEnabler {
+	return &levelEnabler{rawLevel: int32(lvl), minLevel: int32(lvl)}
+}
+
+// Enabled returns whether the level is enabled by the global level or any of the module levels.
+func (e *levelEnabler) Enabled(lvl zapcore.Level) bool {
+	return lvl >= zapcore.Level(atomic.LoadInt32(&e.minLevel))
+}
+
+// EnabledFor returns whether the level is enabled for the file of the caller.
+func (e *levelEnabler) EnabledFor(lvl zapcore.Level, file string) bool {
+	if modules := e.modules.Load(); modules != nil && file != "" {
+		if idx := modules.match(file); idx >= 0 {
+			return lvl >= modules.levels[idx].level
+		}
+	}
+	return lvl >= zapcore.Level(atomic.LoadInt32(&e.rawLevel))
+}
+
+func (e *levelEnabler) SetLevel(lvl zapcore.Level) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	atomic.StoreInt32(&e.rawLevel, int32(lvl))
+	e.updateMinLevel()
+}
+
+// SetModuleLevel sets the level of the module, the module is removed if remove is true.
+func (e *levelEnabler) SetModuleLevel(module string, lvl zapcore.Level, remove bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	module = strings.Trim(module, "/")
+	var levels []moduleLevel
+	if modules := e.modules.Load(); modules != nil {
+		for _, l := range modules.levels {
+			if l.module != module {
+				levels = append(levels, l)
+			}
+		}
+	}
+	if !remove {
+		levels = append(levels, moduleLevel{module: module, pattern: "/" + module + "/", level: lvl})
+	}
+	if len(levels) == 0 {
+		e.modules.Store(nil)
+	} else {
+		// the longest module is matched first, so that a/b overrides a
+		sort.Slice(levels, func(i, j int) bool {
+			if len(levels[i].module) != len(levels[j].module) {
+				return len(levels[i].module) > len(levels[j].module)
+			}
+			return levels[i].module < levels[j].module
+		})
+		e.modules.Store(&moduleLevels{levels: levels})
+	}
+	e.updateMinLevel()
+}
+
+// Levels returns the global level and the module levels.
+func (e *levelEnabler) Levels() (zapcore.Level, map[string]zapcore.Level) {
+	modules := make(map[string]zapcore.Level)
+	if m := e.modules.Load(); m != nil {
+		for _, l := range m.levels {
+			modules[l.module] = l.level
+		}
+	}
+	return zapcore.Level(atomic.LoadInt32(&e.rawLevel)), modules
+}
+
+// updateMinLevel lowers the level checked by Enabled to the lowest module level. The caller of an entry is
+// only known in Write, so e.g. a debug module makes the debug entries of all the modules be built before
+// the ones of the other modules are dropped.
+func (e *levelEnabler) updateMinLevel() {
+	minLevel := atomic.LoadInt32(&e.rawLevel)
+	if modules := e.modules.Load(); modules != nil {
+		for _, l := range modules.levels {
+			if int32(l.level) < minLevel {
+				minLevel = int32(l.level)
+			}
+		}
+	}
+	atomic.StoreInt32(&e.minLevel, minLevel)
+}
+
+const (
+	_numLevels        = zapcore.FatalLevel - zapcore.DebugLevel + 1
+	_countersPerLevel = 4096
+)
+
+// sampler logs the first entries with the same level and message in each tick, and then every thereafter
+// entries, the entries at or above the DPanic level are never sampled. It is the same as the sampler of zap
+// but shared by the derived loggers.
+type sampler struct {
+	tick       time.Duration
+	first      uint64
+	thereafter uint64
+	counts     [_numLevels][_countersPerLevel]counter
+}
+
+type counter struct {
+	resetAt atomic.Int64
+	counter atomic.Uint64
+}
+
+func newSampler(tick time.Duration, first, thereafter int) *sampler {
+	return &sampler{tick: tick, first: uint64(first), thereafter: uint64(thereafter)}
+}
+
+func (s *sampler) allow(ent zapcore.Entry) bool {
+	if ent.Level < zapcore.DebugLevel || ent.Level >= zapcore.DPanicLevel {
+		return true
+	}
+	c := &s.counts[ent.Level-zapcore.DebugLevel][fnv32a(ent.Message)%_countersPerLevel]
+	n := c.incCheckReset(ent.Time, s.tick)
+	if n <= s.first {
+		return true
+	}
+	return s.thereafter > 0 && (n-s.first)%s.thereafter == 0
+}
+
+func (c *counter) incCheckReset(t time.Time, tick time.Duration) uint64 {
+	tn := t.UnixNano()
+	resetAfter := c.resetAt.Load()
+	if resetAfter > tn {
+		return c.counter.Add(1)
+	}
+	c.counter.Store(1)
+	newResetAfter := tn + tick.Nanoseconds()
+	if !c.resetAt.CompareAndSwap(resetAfter, newResetAfter) {
+		// we raced with another goroutine trying to reset, and it also reset the counter to 1, so we need to
+		// reincrement the counter
+		return c.counter.Add(1)
+	}
+	return 1
+}
+
+// fnv32a is the 32-bit FNV-1a hash of the string without allocation.
+func fnv32a(s string) uint32 {
+	const (
+		offset32 = 2166136261
+		prime32  = 16777619
+	)
+	hash := uint32(offset32)
+	for i := 0; i < len(s); i++ {
+		hash ^= uint32(s[i])
+		hash *= prime32
+	}
+	return hash
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strconv"
 	"sync/atomic"
+	"time"
 	"unsafe"
 
 	"go.uber.org/multierr"
@@ -27,8 +28,9 @@ const (
 var _ types.Logger = (*logger)(nil)
 
 type logger struct {
-	level  *levelEnabler
-	writer *asyncWriterProxy
+	level   *levelEnabler
+	sampler *atomic.Pointer[sampler]
+	writer  *asyncWriterProxy
 
 	base *zap.Logger
 }
@@ -49,11 +51,17 @@ func NewLogger(lvl types.Level, w types.AsyncWriter) *logger {
 		EncodeCaller:   zapcore.ShortCallerEncoder,
 	}
 	l := &logger{
-		level:  newLevelEnabler(toZapLevel(lvl)),
-		writer: newAsyncWriter(w),
-		base:   nil,
+		level:   newLevelEnabler(toZapLevel(lvl)),
+		sampler: &atomic.Pointer[sampler]{},
+		writer:  newAsyncWriter(w),
+		base:    nil,
+	}
+	// the levels are checked by the filter core
+	core := &filterCore{
+		Core:    zapcore.NewCore(newZapJSONEncoder(encoderConfig, false), l.writer, zapcore.DebugLevel),
+		level:   l.level,
+		sampler: l.sampler,
 	}
-	core := zapcore.NewCore(newZapJSONEncoder(encoderConfig, false), l.writer, l.level)
 	l.base = zap.New(core,
 		zap.AddCaller(),
 		zap.AddCallerSkip(baseCallDepth),
@@ -80,27 +88,69 @@ func toZapLevel(lvl types.Level) zapcore.Level {
 	}
 }
 
+func fromZapLevel(lvl zapcore.Level) types.Level {
+	switch lvl {
+	case zap.DebugLevel:
+		return types.DebugLevel
+	case zap.InfoLevel:
+		return types.InfoLevel
+	case zap.WarnLevel:
+		return types.WarnLevel
+	case zap.ErrorLevel:
+		return types.ErrorLevel
+	default:
+		return types.PanicLevel
+	}
+}
+
 func (zl *logger) SetLevel(lvl types.Level) {
 	zl.level.SetLevel(toZapLevel(lvl))
 }
 
+func (zl *logger) SetModuleLevel(module string, lvl types.Level) {
+	zl.level.SetModuleLevel(module, toZapLevel(lvl), false)
+}
+
+func (zl *logger) UnsetModuleLevel(module string) {
+	zl.level.SetModuleLevel(module, zapcore.InfoLevel, true)
+}
+
+func (zl *logger) Levels() (types.Level, map[string]types.Level) {
+	lvl, modules := zl.level.Levels()
+	levels := make(map[string]types.Level, len(modules))
+	for module, l := range modules {
+		levels[module] = fromZapLevel(l)
+	}
+	return fromZapLevel(lvl), levels
+}
+
+func (zl *logger) SetSampling(tick time.Duration, first, thereafter int) {
+	if tick <= 0 || first <= 0 {
+		zl.sampler.Store(nil)
+		return
+	}
+	zl.sampler.Store(newSampler(tick, first, thereafter))
+}
+
 func (zl *logger) SetWriter(w types.AsyncWriter) {
 	zl.writer.SetWriter(w)
 }
 
 func (zl *logger) AddCallerSkip(skip int) types.Logger {
 	return &logger{
-		level:  zl.level,
-		writer: zl.writer,
-		base:   zl.base.WithOptions(zap.AddCallerSkip(skip)),
+		level:   zl.level,
+		sampler: zl.sampler,
+		writer:  zl.writer,
+		base:    zl.base.WithOptions(zap.AddCallerSkip(skip)),
 	}
 }
 
 func (zl *logger) With(args ...interface{}) types.Logger {
 	return &logger{
-		level:  zl.level,
-		writer: zl.writer,
-		base:   zl.base.With(zl.sweetenFields(args, 0)...),
+		level:   zl.level,
+		sampler: zl.sampler,
+		writer:  zl.writer,
+		base:    zl.base.With(zl.sweetenFields(args, 0)...),
 	}
 }
 
@@ -361,22 +411,6 @@ func (ps invalidPairs) MarshalLogArray(enc zapcore.ArrayEncoder) error {
 	return err
 }
 
-type levelEnabler struct {
-	rawLevel int32
-}
-
-func newLevelEnabler(lvl zapcore.Level) *levelEnabler {
-	return &levelEnabler{rawLevel: int32(lvl)}
-}
-
-func (e *levelEnabler) Enabled(lvl zapcore.Level) bool {
-	return lvl >= zapcore.Level(atomic.LoadInt32(&e.rawLevel))
-}
-
-func (e *levelEnabler) SetLevel(lvl zapcore.Level) {
-	atomic.StoreInt32(&e.rawLevel, int32(lvl))
-}
-
 type asyncWriterProxy struct {
 	raw unsafe.Pointer
 }
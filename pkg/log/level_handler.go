@@ -0,0 +1,80 @@
+package log
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+)
+
+// LevelResponse is the response of the level handler.
+type LevelResponse struct {
+	Level   string            `json:"level"`
+	Modules map[string]string `json:"modules"`
+}
+
+// LevelRequest changes the level at runtime. The global level is changed if Module is empty, otherwise
+// the level of the module is set, or removed if Level is empty.
+type LevelRequest struct {
+	Module string `json:"module"`
+	Level  string `json:"level"`
+}
+
+// LevelHandler returns an HTTP handler which responds the levels on GET, and changes the level by the
+// LevelRequest in the body on PUT or POST. The handler is served by the pprof server without authentication,
+// so it only accepts the requests from the loopback addresses, lowering the level floods the logs.
+func LevelHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !isLoopback(r.RemoteAddr) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			req := &LevelRequest{}
+			if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+				http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			if req.Module != "" && req.Level == "" {
+				UnsetModuleLevel(req.Module)
+				Infow("log level of module is removed", "module", req.Module)
+				break
+			}
+			lvl, err := ParseLevel(req.Level)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if req.Module == "" {
+				SetLevel(lvl)
+			} else {
+				SetModuleLevel(req.Module, lvl)
+			}
+			Infow("log level is changed", "module", req.Module, "level", lvl.String())
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		lvl, modules := Levels()
+		resp := &LevelResponse{Level: lvl.String(), Modules: make(map[string]string, len(modules))}
+		for module, l := range modules {
+			resp.Modules[module] = l.String()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			Errorw("failed to write log level response", "error", err)
+		}
+	}
+}
+
+// isLoopback returns whether the remote address of the request is a loopback address, the forwarded headers
+// are ignored because they are set by the client.
+func isLoopback(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,55 @@
+package log_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+)
+
+func TestLevelHandler(t *testing.T) {
+	setupBufferLogger(t, log.InfoLevel)
+	handler := log.LevelHandler()
+
+	cases := []struct {
+		name         string
+		method       string
+		remoteAddr   string
+		body         string
+		wantedStatus int
+		wantedResp   *log.LevelResponse
+	}{
+		{"get", http.MethodGet, "127.0.0.1:1234", "", http.StatusOK, &log.LevelResponse{Level: "info", Modules: map[string]string{}}},
+		{"set global level", http.MethodPut, "127.0.0.1:1234", `{"level":"warn"}`, http.StatusOK,
+			&log.LevelResponse{Level: "warn", Modules: map[string]string{}}},
+		{"set module level", http.MethodPost, "127.0.0.1:1234", `{"module":"executor","level":"debug"}`, http.StatusOK,
+			&log.LevelResponse{Level: "warn", Modules: map[string]string{"executor": "debug"}}},
+		{"unset module level", http.MethodPut, "127.0.0.1:1234", `{"module":"executor"}`, http.StatusOK,
+			&log.LevelResponse{Level: "warn", Modules: map[string]string{}}},
+		{"invalid level", http.MethodPut, "127.0.0.1:1234", `{"level":"mock"}`, http.StatusBadRequest, nil},
+		{"invalid body", http.MethodPut, "127.0.0.1:1234", `{`, http.StatusBadRequest, nil},
+		{"invalid method", http.MethodDelete, "127.0.0.1:1234", "", http.StatusMethodNotAllowed, nil},
+		{"ipv6 loopback", http.MethodGet, "[::1]:1234", "", http.StatusOK,
+			&log.LevelResponse{Level: "warn", Modules: map[string]string{}}},
+		{"remote address", http.MethodPut, "192.168.1.1:1234", `{"level":"debug"}`, http.StatusForbidden, nil},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, "/debug/log/level", strings.NewReader(tt.body))
+			req.RemoteAddr = tt.remoteAddr
+			handler(w, req)
+			assert.Equal(t, tt.wantedStatus, w.Code)
+			if tt.wantedResp != nil {
+				resp := &log.LevelResponse{}
+				assert.Nil(t, json.Unmarshal(w.Body.Bytes(), resp))
+				assert.Equal(t, tt.wantedResp, resp)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package log_test
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+)
+
+type bufferWriter struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (w *bufferWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *bufferWriter) Sync() error { return nil }
+
+func (w *bufferWriter) Stop() error { return nil }
+
+func (w *bufferWriter) lines() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	content := strings.TrimSpace(w.buf.String())
+	w.buf.Reset()
+	if content == "" {
+		return nil
+	}
+	return strings.Split(content, "\n")
+}
+
+func setupBufferLogger(t *testing.T, lvl log.Level) *bufferWriter {
+	w := &bufferWriter{}
+	log.SetWriter(w)
+	log.SetLevel(lvl)
+	t.Cleanup(func() {
+		log.SetLevel(log.DebugLevel)
+		_, modules := log.Levels()
+		for module := range modules {
+			log.UnsetModuleLevel(module)
+		}
+		log.SetSampling(0, 0, 0)
+	})
+	return w
+}
+
+func TestModuleLevel(t *testing.T) {
+	w := setupBufferLogger(t, log.DebugLevel)
+
+	// the caller of this test is in pkg/log
+	log.SetModuleLevel("log", log.ErrorLevel)
+	log.Info("dropped")
+	log.Errorw("logged")
+	lines := w.lines()
+	assert.Equal(t, 1, len(lines))
+	assert.Contains(t, lines[0], "logged")
+
+	// the longer module overrides the shorter one
+	log.SetModuleLevel("pkg/log", log.DebugLevel)
+	log.Debug("logged")
+	assert.Equal(t, 1, len(w.lines()))
+
+	// the module level is lower than the global level
+	log.SetLevel(log.ErrorLevel)
+	log.UnsetModuleLevel("pkg/log")
+	log.SetModuleLevel("other", log.DebugLevel)
+	log.With("k", "v").Info("dropped")
+	assert.Equal(t, 0, len(w.lines()))
+	log.SetModuleLevel("log", log.InfoLevel)
+	log.With("k", "v").Info("logged")
+	assert.Equal(t, 1, len(w.lines()))
+
+	lvl, modules := log.Levels()
+	assert.Equal(t, log.ErrorLevel, lvl)
+	assert.Equal(t, map[string]log.Level{"log": log.InfoLevel, "other": log.DebugLevel}, modules)
+}
+
+func TestSampling(t *testing.T) {
+	w := setupBufferLogger(t, log.DebugLevel)
+
+	log.SetSampling(time.Minute, 2, 3)
+	for i := 0; i < 10; i++ {
+		log.Errorw("repeated error", "i", i)
+	}
+	log.Errorw("another error")
+	// the first 2 lines, then the 5th and the 8th ones
+	lines := w.lines()
+	assert.Equal(t, 5, len(lines))
+	assert.Contains(t, lines[2], `"i":4`)
+	assert.Contains(t, lines[3], `"i":7`)
+
+	log.SetSampling(0, 0, 0)
+	for i := 0; i < 10; i++ {
+		log.Errorw("repeated error", "i", i)
+	}
+	assert.Equal(t, 10, len(w.lines()))
+}
@@ -86,8 +86,26 @@ func (w writerWrapper) Stop() error {
 	return nil
 }
 
+// Option customizes the log initialization.
+type Option func(*options)
+
+type options struct {
+	rotate RotateConfig
+}
+
+// WithRotation sets the rotation and the retention of the log files.
+func WithRotation(rotate RotateConfig) Option {
+	return func(o *options) {
+		o.rotate = rotate
+	}
+}
+
 // Init auto setting level and creating log directory
-func Init(lvl Level, path string) {
+func Init(lvl Level, path string, opts ...Option) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
 	logger.SetLevel(lvl)
 
 	if path != "" {
@@ -100,7 +118,7 @@ func Init(lvl Level, path string) {
 			logger.With("err", err).Panicf("invalid dir stat")
 		}
 
-		logger.SetWriter(NewMultiWriteSyncer(NewAsyncFileWriter(path, 10*1024*1024),
+		logger.SetWriter(NewMultiWriteSyncer(NewRotateFileWriter(path, 10*1024*1024, o.rotate),
 			&zapcore.BufferedWriteSyncer{WS: os.Stdout, FlushInterval: time.Second}))
 	}
 }
@@ -115,6 +133,31 @@ func SetWriter(w types.AsyncWriter) {
 	logger.SetWriter(w)
 }
 
+// SetModuleLevel sets the level of the module, which overrides the global level for the callers whose
+// file path contains /<module>/, e.g. executor for modular/executor or p2p for modular/p2p. The module
+// level lower than the global level lowers the level checked before the caller is known, so the entries of
+// all the modules down to that level are encoded and then dropped by the caller, which costs on hot paths.
+func SetModuleLevel(module string, lvl Level) {
+	logger.SetModuleLevel(module, lvl)
+}
+
+// UnsetModuleLevel removes the level of the module, the global level is used again.
+func UnsetModuleLevel(module string) {
+	logger.UnsetModuleLevel(module)
+}
+
+// Levels returns the global level and the module levels.
+func Levels() (Level, map[string]Level) {
+	return logger.Levels()
+}
+
+// SetSampling logs the first entries with the same level and message in each tick, and then every
+// thereafter entries, so that the repeated lines of the retry loops do not fill the disk. The sampling
+// is disabled if tick or first is not positive.
+func SetSampling(tick time.Duration, first, thereafter int) {
+	logger.SetSampling(tick, first, thereafter)
+}
+
 // Stop flushes any buffered log entries, then stops writer.
 // This method should be called when program stopping.
 // For example,
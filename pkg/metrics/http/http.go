@@ -42,6 +42,14 @@ func (wd *responseWriterDelegator) Write(b []byte) (int, error) {
 	return n, err
 }
 
+// Flush implements http.Flusher so that the streaming responses such as server-sent events
+// can be flushed through the metrics middleware.
+func (wd *responseWriterDelegator) Flush() {
+	if f, ok := wd.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 func (wd *responseWriterDelegator) GetBody() []byte {
 	return wd.body.Bytes()
 }
@@ -39,6 +39,14 @@ var MetricsItems = []prometheus.Collector{
 	GnfdChainTime,
 	GnfdChainCounter,
 	BlockHeightLagGauge,
+	ConsensusCacheCounter,
+	ConsensusCacheSizeGauge,
+	GnfdEndpointProbeCounter,
+	GnfdEndpointLatencyGauge,
+	GnfdEndpointHeightLagGauge,
+	GnfdEndpointSelectedGauge,
+	GnfdObjectEventCounter,
+	GnfdObjectEventSubscribedGauge,
 
 	// common module metrics items
 	ReqCounter,
@@ -94,6 +102,11 @@ var MetricsItems = []prometheus.Collector{
 	MigrateGVGCounter,
 	MigrateObjectTimeHistogram,
 	MigrateObjectCounter,
+
+	// signer metrics category
+	SignerGasEstimateCounter,
+	SignerFeeSpentGauge,
+	SignerFeeBudgetUsageGauge,
 }
 
 // basic metrics items
@@ -171,6 +184,38 @@ var (
 		Name: "block_syncer_height",
 		Help: "Current block number of block syncer progress.",
 	}, []string{"block_syncer_height"})
+	ConsensusCacheCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "consensus_cache_counter",
+		Help: "Track the lookups of the consensus cache by the method and the result.",
+	}, []string{"method", "result"})
+	ConsensusCacheSizeGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "consensus_cache_size",
+		Help: "Track the number of the entries in the consensus cache.",
+	})
+	GnfdEndpointProbeCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gnfd_endpoint_probe_counter",
+		Help: "Track the probes of the chain endpoints by the endpoint and the result.",
+	}, []string{"endpoint", "result"})
+	GnfdEndpointLatencyGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "gnfd_endpoint_latency_seconds",
+		Help: "Track the moving average of the probe latency of the chain endpoints.",
+	}, []string{"endpoint"})
+	GnfdEndpointHeightLagGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "gnfd_endpoint_height_lag",
+		Help: "Track the number of the blocks the chain endpoints are behind the highest endpoint.",
+	}, []string{"endpoint"})
+	GnfdEndpointSelectedGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "gnfd_endpoint_selected",
+		Help: "Track whether the chain endpoints are selected for the reads or the broadcasts.",
+	}, []string{"endpoint", "route"})
+	GnfdObjectEventCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gnfd_object_event_counter",
+		Help: "Track the subscribed seal and reject events of the objects.",
+	}, []string{"event"})
+	GnfdObjectEventSubscribedGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "gnfd_object_event_subscribed",
+		Help: "Track whether the object events are subscribed, the objects are polled if not.",
+	})
 )
 
 // module metrics items, include gateway, approver, uploader, manager, task executor,
@@ -369,3 +414,19 @@ var (
 		Help: "Track migrate object number",
 	}, []string{"migrate_object_counter"})
 )
+
+// signer metrics
+var (
+	SignerGasEstimateCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "signer_gas_estimate_counter",
+		Help: "Track the gas estimations of the signer txs by the messages and the result.",
+	}, []string{"msgs", "result"})
+	SignerFeeSpentGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "signer_daily_fee_spent",
+		Help: "Track the fee amount paid by the signer account in the current UTC day.",
+	}, []string{"account"})
+	SignerFeeBudgetUsageGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "signer_daily_fee_budget_usage",
+		Help: "Track the used ratio of the daily fee budget of the signer account.",
+	}, []string{"account"})
+)
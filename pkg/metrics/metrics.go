@@ -62,6 +62,11 @@ func (m *Metrics) RegisterMetricItems(cs ...prometheus.Collector) {
 	m.registry.MustRegister(cs...)
 }
 
+// Gatherer returns the registry of the metrics, e.g. to read the histograms in process.
+func (m *Metrics) Gatherer() prometheus.Gatherer {
+	return m.registry
+}
+
 func (m *Metrics) serve() {
 	router := mux.NewRouter()
 	router.Path("/metrics").Handler(promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
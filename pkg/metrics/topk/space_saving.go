@@ -0,0 +1,110 @@
+package topk
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// Entry is a key tracked by the SpaceSaving sketch. Count overestimates the real weight of the key by at
+// most Error, which is the count of the key evicted when the key is tracked.
+type Entry struct {
+	Key   string
+	Count float64
+	Error float64
+
+	index int
+}
+
+// SpaceSaving implements the space-saving algorithm, it tracks the heaviest keys of a stream in a fixed
+// capacity, any key whose real weight is larger than total/capacity is guaranteed to be tracked. It is not
+// safe for concurrent use.
+type SpaceSaving struct {
+	capacity int
+	entries  map[string]*Entry
+	minHeap  entryHeap
+}
+
+// NewSpaceSaving returns a SpaceSaving sketch tracking at most capacity keys.
+func NewSpaceSaving(capacity int) *SpaceSaving {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &SpaceSaving{
+		capacity: capacity,
+		entries:  make(map[string]*Entry, capacity),
+		minHeap:  make(entryHeap, 0, capacity),
+	}
+}
+
+// Add adds the weight to the key, the lightest key is replaced by the key if the sketch is full.
+func (s *SpaceSaving) Add(key string, weight float64) {
+	if entry, ok := s.entries[key]; ok {
+		entry.Count += weight
+		heap.Fix(&s.minHeap, entry.index)
+		return
+	}
+	if len(s.minHeap) < s.capacity {
+		entry := &Entry{Key: key, Count: weight}
+		s.entries[key] = entry
+		heap.Push(&s.minHeap, entry)
+		return
+	}
+	entry := s.minHeap[0]
+	delete(s.entries, entry.Key)
+	entry.Key = key
+	entry.Error = entry.Count
+	entry.Count += weight
+	s.entries[key] = entry
+	heap.Fix(&s.minHeap, 0)
+}
+
+// Top returns at most n heaviest keys in descending order of the count.
+func (s *SpaceSaving) Top(n int) []Entry {
+	entries := make([]Entry, 0, len(s.minHeap))
+	for _, entry := range s.minHeap {
+		entries = append(entries, Entry{Key: entry.Key, Count: entry.Count, Error: entry.Error})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Count != entries[j].Count {
+			return entries[i].Count > entries[j].Count
+		}
+		return entries[i].Key < entries[j].Key
+	})
+	if n >= 0 && len(entries) > n {
+		entries = entries[:n]
+	}
+	return entries
+}
+
+// Len returns the number of the tracked keys.
+func (s *SpaceSaving) Len() int {
+	return len(s.minHeap)
+}
+
+// entryHeap is the min heap of the entries ordered by the count.
+type entryHeap []*Entry
+
+func (h entryHeap) Len() int { return len(h) }
+
+func (h entryHeap) Less(i, j int) bool { return h[i].Count < h[j].Count }
+
+func (h entryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *entryHeap) Push(x interface{}) {
+	entry := x.(*Entry)
+	entry.index = len(*h)
+	*h = append(*h, entry)
+}
+
+func (h *entryHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	entry := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return entry
+}
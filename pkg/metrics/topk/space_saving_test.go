@@ -0,0 +1,49 @@
+package topk
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpaceSaving(t *testing.T) {
+	s := NewSpaceSaving(2)
+	s.Add("a", 3)
+	s.Add("b", 1)
+	s.Add("a", 1)
+	assert.Equal(t, 2, s.Len())
+	assert.Equal(t, []Entry{{Key: "a", Count: 4}, {Key: "b", Count: 1}}, s.Top(-1))
+
+	// the lightest key b is replaced by c, whose count is overestimated by the count of b
+	s.Add("c", 2)
+	assert.Equal(t, 2, s.Len())
+	top := s.Top(1)
+	assert.Equal(t, 1, len(top))
+	assert.Equal(t, "a", top[0].Key)
+	top = s.Top(2)
+	assert.Equal(t, "c", top[1].Key)
+	assert.Equal(t, float64(3), top[1].Count)
+	assert.Equal(t, float64(1), top[1].Error)
+}
+
+func TestSpaceSavingHeavyHitters(t *testing.T) {
+	s := NewSpaceSaving(10)
+	// the heavy keys are interleaved with many light keys
+	for i := 0; i < 1000; i++ {
+		s.Add(fmt.Sprintf("light-%d", i), 1)
+		if i%5 == 0 {
+			s.Add("heavy-1", 10)
+		}
+		if i%10 == 0 {
+			s.Add("heavy-2", 10)
+		}
+	}
+	assert.Equal(t, 10, s.Len())
+	top := s.Top(2)
+	assert.Equal(t, "heavy-1", top[0].Key)
+	assert.GreaterOrEqual(t, top[0].Count, float64(2000))
+	assert.LessOrEqual(t, top[0].Count-top[0].Error, float64(2000))
+	assert.Equal(t, "heavy-2", top[1].Key)
+	assert.GreaterOrEqual(t, top[1].Count, float64(1000))
+}
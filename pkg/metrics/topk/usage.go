@@ -0,0 +1,121 @@
+package topk
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// OtherLabel is the label value of the usage of all the keys out of the top n, the bucket names and the
+	// account addresses never start with the underscore so it does not conflict with the real keys.
+	OtherLabel = "_other"
+	// DefaultTopN defines the default number of the heaviest keys exported.
+	DefaultTopN = 50
+	// CapacityFactor is the ratio of the sketch capacity to the exported top n, the extra capacity keeps the
+	// estimation of the exported keys accurate.
+	CapacityFactor = 10
+)
+
+type stat int
+
+const (
+	statRequests stat = iota
+	statErrors
+	statUploadBytes
+	statDownloadBytes
+	statNum
+)
+
+var statNames = [statNum]string{"requests", "errors", "upload_bytes", "download_bytes"}
+
+var statHelps = [statNum]string{
+	"Track the estimated number of the requests of the heaviest %ss.",
+	"Track the estimated number of the failed requests of the heaviest %ss.",
+	"Track the estimated bytes uploaded by the heaviest %ss.",
+	"Track the estimated bytes downloaded by the heaviest %ss.",
+}
+
+// UsageTracker is a prometheus collector which exports the requests, the errors and the uploaded and
+// downloaded bytes of the heaviest n keys of a dimension such as bucket or account, the usage of the other
+// keys is summed into the OtherLabel, so that the cardinality is bounded by n+1. Each stat is ranked by
+// its own sketch, and the values are the cumulative estimations since the process starts.
+type UsageTracker struct {
+	topN int
+
+	mu       sync.Mutex
+	sketches [statNum]*SpaceSaving
+	totals   [statNum]float64
+	descs    [statNum]*prometheus.Desc
+}
+
+var _ prometheus.Collector = &UsageTracker{}
+
+// NewUsageTracker returns a UsageTracker of the dimension, the metrics are named top_<dimension>_<stat> and
+// labeled by the dimension.
+func NewUsageTracker(dimension string, topN int) *UsageTracker {
+	if topN <= 0 {
+		topN = DefaultTopN
+	}
+	t := &UsageTracker{topN: topN}
+	for i := stat(0); i < statNum; i++ {
+		t.sketches[i] = NewSpaceSaving(topN * CapacityFactor)
+		t.descs[i] = prometheus.NewDesc("top_"+dimension+"_"+statNames[i],
+			fmt.Sprintf(statHelps[i], dimension), []string{dimension}, nil)
+	}
+	return t
+}
+
+// Observe records a request of the key, the empty key is ignored.
+func (t *UsageTracker) Observe(key string, uploadBytes, downloadBytes int64, failed bool) {
+	if key == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.add(statRequests, key, 1)
+	if failed {
+		t.add(statErrors, key, 1)
+	}
+	if uploadBytes > 0 {
+		t.add(statUploadBytes, key, float64(uploadBytes))
+	}
+	if downloadBytes > 0 {
+		t.add(statDownloadBytes, key, float64(downloadBytes))
+	}
+}
+
+func (t *UsageTracker) add(s stat, key string, weight float64) {
+	t.sketches[s].Add(key, weight)
+	t.totals[s] += weight
+}
+
+// Describe implements prometheus.Collector.
+func (t *UsageTracker) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range t.descs {
+		ch <- desc
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (t *UsageTracker) Collect(ch chan<- prometheus.Metric) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i := stat(0); i < statNum; i++ {
+		if t.totals[i] == 0 {
+			continue
+		}
+		var topSum float64
+		for _, entry := range t.sketches[i].Top(t.topN) {
+			topSum += entry.Count
+			ch <- prometheus.MustNewConstMetric(t.descs[i], prometheus.GaugeValue, entry.Count, entry.Key)
+		}
+		// the counts of the sketch are overestimated, so the other may be negative
+		other := t.totals[i] - topSum
+		if other < 0 {
+			other = 0
+		}
+		ch <- prometheus.MustNewConstMetric(t.descs[i], prometheus.GaugeValue, other, OtherLabel)
+	}
+}
@@ -0,0 +1,51 @@
+package topk
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsageTracker(t *testing.T) {
+	tracker := NewUsageTracker("bucket", 1)
+	assert.Equal(t, CapacityFactor, tracker.sketches[statRequests].capacity)
+	tracker.Observe("", 1, 1, true)
+	tracker.Observe("bucket-a", 100, 0, false)
+	tracker.Observe("bucket-a", 0, 10, true)
+	tracker.Observe("bucket-b", 0, 50, false)
+
+	registry := prometheus.NewRegistry()
+	assert.Nil(t, registry.Register(tracker))
+	expected := `
+# HELP top_bucket_download_bytes Track the estimated bytes downloaded by the heaviest buckets.
+# TYPE top_bucket_download_bytes gauge
+top_bucket_download_bytes{bucket="_other"} 10
+top_bucket_download_bytes{bucket="bucket-b"} 50
+# HELP top_bucket_errors Track the estimated number of the failed requests of the heaviest buckets.
+# TYPE top_bucket_errors gauge
+top_bucket_errors{bucket="_other"} 0
+top_bucket_errors{bucket="bucket-a"} 1
+# HELP top_bucket_requests Track the estimated number of the requests of the heaviest buckets.
+# TYPE top_bucket_requests gauge
+top_bucket_requests{bucket="_other"} 1
+top_bucket_requests{bucket="bucket-a"} 2
+# HELP top_bucket_upload_bytes Track the estimated bytes uploaded by the heaviest buckets.
+# TYPE top_bucket_upload_bytes gauge
+top_bucket_upload_bytes{bucket="_other"} 0
+top_bucket_upload_bytes{bucket="bucket-a"} 100
+`
+	assert.Nil(t, testutil.GatherAndCompare(registry, strings.NewReader(expected)))
+}
+
+func TestUsageTrackerBoundedCardinality(t *testing.T) {
+	tracker := NewUsageTracker("account", 0)
+	for i := 0; i < 10000; i++ {
+		tracker.Observe(strings.Repeat("a", i%1000+1), 0, 0, false)
+	}
+	// the top n and the other
+	assert.Equal(t, DefaultTopN+1, testutil.CollectAndCount(tracker, "top_account_requests"))
+	assert.Equal(t, 0, testutil.CollectAndCount(tracker, "top_account_upload_bytes"))
+}
@@ -0,0 +1,187 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	slimiter "github.com/ulule/limiter/v3"
+
+	"github.com/zkMeLabs/mechain-storage-provider/base/types/gfsperrors"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+)
+
+const (
+	// DefaultAccountLimitRatePeriod defines the default period of the account limits.
+	DefaultAccountLimitRatePeriod = "S"
+	// DefaultAccountLimitOverrideCacheSecond defines the default seconds of caching the per-account override.
+	DefaultAccountLimitOverrideCacheSecond = 60
+	// maxCachedAccountOverrides bounds the cached overrides, the expired ones are dropped when it is reached.
+	maxCachedAccountOverrides = 100000
+)
+
+var ErrAccountBanned = gfsperrors.Register(Middleware, http.StatusForbidden, 960002, "the account is banned, please try it again later")
+
+// AccountLimitConfig limits the requests by the authenticated account and by the bucket, the reads and the
+// writes have separate budgets. The zero rate limit means no limit.
+type AccountLimitConfig struct {
+	On                   bool     `comment:"optional"`
+	ReadRateLimit        int      `comment:"optional"`
+	WriteRateLimit       int      `comment:"optional"`
+	BucketReadRateLimit  int      `comment:"optional"`
+	BucketWriteRateLimit int      `comment:"optional"`
+	RatePeriod           string   `comment:"optional"`
+	OverrideCacheSecond  int64    `comment:"optional"`
+	AdminAccounts        []string `comment:"optional"`
+}
+
+// AccountLimitOverride overrides the default account limits, the zero rate limit uses the default. The
+// override is ignored after it expires, the zero ExpireTimestampSecond means it never expires.
+type AccountLimitOverride struct {
+	ReadRateLimit         int
+	WriteRateLimit        int
+	Banned                bool
+	ExpireTimestampSecond int64
+}
+
+// AccountLimitLoader loads the override of the account, returns (nil, nil) if the account has no override.
+type AccountLimitLoader func(account string) (*AccountLimitOverride, error)
+
+type cachedAccountOverride struct {
+	override *AccountLimitOverride
+	expireAt time.Time
+}
+
+// AccountLimiter limits the authenticated requests by the account and the bucket.
+type AccountLimiter struct {
+	store         slimiter.Store
+	period        time.Duration
+	cfg           AccountLimitConfig
+	loader        AccountLimitLoader
+	cacheDuration time.Duration
+
+	mu        sync.Mutex
+	overrides map[string]*cachedAccountOverride
+}
+
+// NewAccountLimiter returns an AccountLimiter, the counters are kept in the store selected by storeCfg.
+func NewAccountLimiter(cfg AccountLimitConfig, storeCfg RateLimiterStoreConfig, loader AccountLimitLoader) (*AccountLimiter, error) {
+	if cfg.RatePeriod == "" {
+		cfg.RatePeriod = DefaultAccountLimitRatePeriod
+	}
+	rate, err := slimiter.NewRateFromFormatted(fmt.Sprintf("1-%s", cfg.RatePeriod))
+	if err != nil {
+		return nil, err
+	}
+	store, err := newLimiterStore(storeCfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.OverrideCacheSecond == 0 {
+		cfg.OverrideCacheSecond = DefaultAccountLimitOverrideCacheSecond
+	}
+	return &AccountLimiter{
+		store:         store,
+		period:        rate.Period,
+		cfg:           cfg,
+		loader:        loader,
+		cacheDuration: time.Duration(cfg.OverrideCacheSecond) * time.Second,
+		overrides:     make(map[string]*cachedAccountOverride),
+	}, nil
+}
+
+// IsAdmin returns whether the account is allowed to manage the account overrides.
+func (a *AccountLimiter) IsAdmin(account string) bool {
+	for _, admin := range a.cfg.AdminAccounts {
+		if strings.EqualFold(admin, account) {
+			return true
+		}
+	}
+	return false
+}
+
+// Allow checks the account and the bucket limits of the request, all counters get increased even if one of
+// them reaches the limit. The limits are not enforced if the store fails.
+func (a *AccountLimiter) Allow(ctx context.Context, account, bucket string, write bool) error {
+	account = strings.ToLower(account)
+	override := a.override(account)
+	if override != nil && override.Banned {
+		return ErrAccountBanned
+	}
+
+	op, accountLimit, bucketLimit := "read", a.cfg.ReadRateLimit, a.cfg.BucketReadRateLimit
+	if write {
+		op, accountLimit, bucketLimit = "write", a.cfg.WriteRateLimit, a.cfg.BucketWriteRateLimit
+	}
+	if override != nil {
+		if write && override.WriteRateLimit != 0 {
+			accountLimit = override.WriteRateLimit
+		} else if !write && override.ReadRateLimit != 0 {
+			accountLimit = override.ReadRateLimit
+		}
+	}
+
+	allow := true
+	if account != "" && accountLimit > 0 {
+		allow = a.increment(ctx, "account_"+op+"_"+account, accountLimit) && allow
+	}
+	if bucket != "" && bucketLimit > 0 {
+		allow = a.increment(ctx, "bucket_"+op+"_"+bucket, bucketLimit) && allow
+	}
+	if !allow {
+		return ErrTooManyRequest
+	}
+	return nil
+}
+
+// Invalidate drops the cached override of the account, the other gateway replicas reload it after the cache expires.
+func (a *AccountLimiter) Invalidate(account string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.overrides, strings.ToLower(account))
+}
+
+func (a *AccountLimiter) increment(ctx context.Context, key string, limit int) bool {
+	limiterCtx, err := a.store.Increment(ctx, key, 1, slimiter.Rate{Period: a.period, Limit: int64(limit)})
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to increase account limit counter", "key", key, "error", err)
+		return true
+	}
+	return !limiterCtx.Reached
+}
+
+// override returns the unexpired override of the account, the override is cached for cacheDuration.
+func (a *AccountLimiter) override(account string) *AccountLimitOverride {
+	if account == "" || a.loader == nil {
+		return nil
+	}
+	now := time.Now()
+	a.mu.Lock()
+	cached, ok := a.overrides[account]
+	a.mu.Unlock()
+	if !ok || now.After(cached.expireAt) {
+		override, err := a.loader(account)
+		if err != nil {
+			log.Errorw("failed to load account limit override", "account", account, "error", err)
+			return nil
+		}
+		cached = &cachedAccountOverride{override: override, expireAt: now.Add(a.cacheDuration)}
+		a.mu.Lock()
+		if len(a.overrides) >= maxCachedAccountOverrides {
+			for k, v := range a.overrides {
+				if now.After(v.expireAt) {
+					delete(a.overrides, k)
+				}
+			}
+		}
+		a.overrides[account] = cached
+		a.mu.Unlock()
+	}
+	if cached.override == nil || (cached.override.ExpireTimestampSecond != 0 && now.Unix() >= cached.override.ExpireTimestampSecond) {
+		return nil
+	}
+	return cached.override
+}
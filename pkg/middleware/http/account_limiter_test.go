@@ -0,0 +1,79 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const mockAccount = "0x76d244CE05c3De4BbC6fDd7F56379B145709ade9"
+
+func TestAccountLimiter_Allow(t *testing.T) {
+	cfg := AccountLimitConfig{On: true, ReadRateLimit: 2, WriteRateLimit: 1, BucketReadRateLimit: 3, RatePeriod: "H"}
+	a, err := NewAccountLimiter(cfg, RateLimiterStoreConfig{}, nil)
+	assert.Nil(t, err)
+	ctx := context.Background()
+
+	// the reads and writes have separate budgets
+	assert.Nil(t, a.Allow(ctx, mockAccount, "bucket", false))
+	assert.Nil(t, a.Allow(ctx, mockAccount, "bucket", false))
+	assert.Equal(t, ErrTooManyRequest, a.Allow(ctx, mockAccount, "bucket", false))
+	assert.Nil(t, a.Allow(ctx, mockAccount, "bucket", true))
+	assert.Equal(t, ErrTooManyRequest, a.Allow(ctx, mockAccount, "bucket", true))
+
+	// the bucket limit is shared by the accounts, it was reached by the third read above
+	assert.Equal(t, ErrTooManyRequest, a.Allow(ctx, "0x01", "bucket", false))
+	assert.Nil(t, a.Allow(ctx, "0x01", "other", false))
+	// the anonymous request is limited by the bucket only
+	assert.Nil(t, a.Allow(ctx, "", "other", false))
+}
+
+func TestAccountLimiter_Override(t *testing.T) {
+	override := &AccountLimitOverride{ReadRateLimit: 3}
+	var loadTimes int
+	loader := func(account string) (*AccountLimitOverride, error) {
+		loadTimes++
+		assert.Equal(t, "0x76d244ce05c3de4bbc6fdd7f56379b145709ade9", account)
+		return override, nil
+	}
+	cfg := AccountLimitConfig{On: true, ReadRateLimit: 1, WriteRateLimit: 1, RatePeriod: "H", AdminAccounts: []string{mockAccount}}
+	a, err := NewAccountLimiter(cfg, RateLimiterStoreConfig{}, loader)
+	assert.Nil(t, err)
+	ctx := context.Background()
+	assert.True(t, a.IsAdmin("0x76D244CE05C3DE4BBC6FDD7F56379B145709ADE9"))
+	assert.False(t, a.IsAdmin("0x01"))
+
+	// the raised read limit and the default write limit
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, a.Allow(ctx, mockAccount, "", false))
+	}
+	assert.Equal(t, ErrTooManyRequest, a.Allow(ctx, mockAccount, "", false))
+	assert.Nil(t, a.Allow(ctx, mockAccount, "", true))
+	assert.Equal(t, 1, loadTimes)
+
+	// the cached override is used until it is invalidated
+	override = &AccountLimitOverride{Banned: true}
+	assert.Equal(t, ErrTooManyRequest, a.Allow(ctx, mockAccount, "", false))
+	a.Invalidate(mockAccount)
+	assert.Equal(t, ErrAccountBanned, a.Allow(ctx, mockAccount, "", false))
+
+	// the expired ban is ignored
+	override = &AccountLimitOverride{Banned: true, ExpireTimestampSecond: time.Now().Unix() - 1}
+	a.Invalidate(mockAccount)
+	assert.Equal(t, ErrTooManyRequest, a.Allow(ctx, mockAccount, "", false))
+
+	// the default limits are used if the override fails to load
+	a.loader = func(string) (*AccountLimitOverride, error) { return nil, errors.New("mock error") }
+	a.Invalidate(mockAccount)
+	assert.Equal(t, ErrTooManyRequest, a.Allow(ctx, mockAccount, "", false))
+}
+
+func TestNewAccountLimiterFailure(t *testing.T) {
+	_, err := NewAccountLimiter(AccountLimitConfig{On: true, RatePeriod: "X"}, RateLimiterStoreConfig{}, nil)
+	assert.NotNil(t, err)
+	_, err = NewAccountLimiter(AccountLimitConfig{On: true}, RateLimiterStoreConfig{Type: "etcd"}, nil)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,109 @@
+package http
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	slimiter "github.com/ulule/limiter/v3"
+	"github.com/ulule/limiter/v3/drivers/store/common"
+)
+
+// DefaultMemoryStoreCleanUpInterval defines the default interval of removing the expired counters of the
+// memory store.
+const DefaultMemoryStoreCleanUpInterval = 5 * time.Second
+
+// memoryCounter is the counter of a key in the current period.
+type memoryCounter struct {
+	value      int64
+	expiration time.Time
+}
+
+// memoryStore keeps the rate limit counters in the memory of the replica. It replaces the memory store of
+// the limiter library, whose keys alias a pooled buffer and are overwritten by the following requests, so
+// the counters of different accounts or buckets were mixed up.
+type memoryStore struct {
+	prefix          string
+	cleanUpInterval time.Duration
+
+	mu        sync.Mutex
+	counters  map[string]*memoryCounter
+	cleanedAt time.Time
+}
+
+func newMemoryStore(prefix string, cleanUpInterval time.Duration) *memoryStore {
+	if cleanUpInterval <= 0 {
+		cleanUpInterval = DefaultMemoryStoreCleanUpInterval
+	}
+	return &memoryStore{
+		prefix:          prefix,
+		cleanUpInterval: cleanUpInterval,
+		counters:        make(map[string]*memoryCounter),
+		cleanedAt:       time.Now(),
+	}
+}
+
+func (m *memoryStore) key(key string) string {
+	return m.prefix + ":" + key
+}
+
+// cleanUpLocked removes the expired counters at most once per cleanUpInterval, so the store does not need
+// a background goroutine.
+func (m *memoryStore) cleanUpLocked(now time.Time) {
+	if now.Sub(m.cleanedAt) < m.cleanUpInterval {
+		return
+	}
+	for key, counter := range m.counters {
+		if !now.Before(counter.expiration) {
+			delete(m.counters, key)
+		}
+	}
+	m.cleanedAt = now
+}
+
+// counterLocked returns the counter of the key in the current period, the expired counter is restarted.
+func (m *memoryStore) counterLocked(key string, now time.Time, period time.Duration) *memoryCounter {
+	m.cleanUpLocked(now)
+	counter, ok := m.counters[key]
+	if !ok || !now.Before(counter.expiration) {
+		counter = &memoryCounter{expiration: now.Add(period)}
+		m.counters[key] = counter
+	}
+	return counter
+}
+
+// Get increases the counter of the given key by one and returns the limit.
+func (m *memoryStore) Get(ctx context.Context, key string, rate slimiter.Rate) (slimiter.Context, error) {
+	return m.Increment(ctx, key, 1, rate)
+}
+
+// Peek returns the limit for the given key without increasing the counter.
+func (m *memoryStore) Peek(_ context.Context, key string, rate slimiter.Rate) (slimiter.Context, error) {
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	counter, ok := m.counters[m.key(key)]
+	if !ok || !now.Before(counter.expiration) {
+		return common.GetContextFromState(now, rate, now.Add(rate.Period), 0), nil
+	}
+	return common.GetContextFromState(now, rate, counter.expiration, counter.value), nil
+}
+
+// Reset resets the counter of the given key.
+func (m *memoryStore) Reset(_ context.Context, key string, rate slimiter.Rate) (slimiter.Context, error) {
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.counters, m.key(key))
+	return common.GetContextFromState(now, rate, now.Add(rate.Period), 0), nil
+}
+
+// Increment increases the counter of the given key by count.
+func (m *memoryStore) Increment(_ context.Context, key string, count int64, rate slimiter.Rate) (slimiter.Context, error) {
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	counter := m.counterLocked(m.key(key), now, rate.Period)
+	counter.value += count
+	return common.GetContextFromState(now, rate, counter.expiration, counter.value), nil
+}
@@ -0,0 +1,71 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	slimiter "github.com/ulule/limiter/v3"
+)
+
+func TestMemoryStore(t *testing.T) {
+	m := newMemoryStore(DefaultStorePrefix, 0)
+	rate := slimiter.Rate{Period: time.Hour, Limit: 2}
+	ctx := context.Background()
+
+	for i := int64(1); i <= 3; i++ {
+		limiterCtx, err := m.Increment(ctx, "key", 1, rate)
+		assert.Nil(t, err)
+		assert.Equal(t, i > 2, limiterCtx.Reached)
+	}
+	limiterCtx, err := m.Peek(ctx, "key", rate)
+	assert.Nil(t, err)
+	assert.True(t, limiterCtx.Reached)
+	limiterCtx, err = m.Peek(ctx, "other", rate)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), limiterCtx.Remaining)
+
+	// the expired counter restarts and is removed by the clean up
+	m.counters[m.key("key")].expiration = time.Now().Add(-time.Second)
+	limiterCtx, err = m.Get(ctx, "key", rate)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), limiterCtx.Remaining)
+	m.counters[m.key("key")].expiration = time.Now().Add(-time.Second)
+	m.cleanedAt = time.Now().Add(-time.Minute)
+	_, _ = m.Increment(ctx, "another", 1, rate)
+	assert.Len(t, m.counters, 1)
+
+	limiterCtx, err = m.Reset(ctx, "another", rate)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), limiterCtx.Remaining)
+	assert.Len(t, m.counters, 0)
+}
+
+func TestMemoryStoreConcurrentKeys(t *testing.T) {
+	m := newMemoryStore(DefaultStorePrefix, 0)
+	rate := slimiter.Rate{Period: time.Hour, Limit: 100}
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				_, _ = m.Increment(ctx, fmt.Sprintf("account-%d", i), 1, rate)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// every key keeps its own counter
+	assert.Len(t, m.counters, 50)
+	for i := 0; i < 50; i++ {
+		limiterCtx, err := m.Peek(ctx, fmt.Sprintf("account-%d", i), rate)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(90), limiterCtx.Remaining)
+	}
+}
@@ -7,10 +7,8 @@ import (
 	"regexp"
 	"strings"
 	"sync"
-	"time"
 
 	slimiter "github.com/ulule/limiter/v3"
-	smemory "github.com/ulule/limiter/v3/drivers/store/memory"
 	modelgateway "github.com/zkMeLabs/mechain-storage-provider/model/gateway"
 
 	"github.com/zkMeLabs/mechain-storage-provider/base/types/gfsperrors"
@@ -37,11 +35,13 @@ type IPLimitConfig struct {
 }
 
 type RateLimiterConfig struct {
-	IPLimitCfg  IPLimitConfig
-	PathPattern []KeyToRateLimiterNameCell `comment:"optional"`
-	HostPattern []KeyToRateLimiterNameCell `comment:"optional"`
-	APILimits   []KeyToRateLimiterNameCell `comment:"optional"`
-	NameToLimit []MemoryLimiterConfig      `comment:"optional"`
+	IPLimitCfg      IPLimitConfig
+	AccountLimitCfg AccountLimitConfig
+	PathPattern     []KeyToRateLimiterNameCell `comment:"optional"`
+	HostPattern     []KeyToRateLimiterNameCell `comment:"optional"`
+	APILimits       []KeyToRateLimiterNameCell `comment:"optional"`
+	NameToLimit     []MemoryLimiterConfig      `comment:"optional"`
+	Store           RateLimiterStoreConfig
 }
 
 type MemoryLimiterConfig struct {
@@ -57,6 +57,7 @@ type APILimiterConfig struct {
 	APILimits    map[string][]MemoryLimiterConfig // routePrefix-apiName  =>  limit config
 	HostPattern  map[string][]MemoryLimiterConfig
 	HostSequence []string
+	Store        RateLimiterStoreConfig
 }
 
 type rateLimiterWithName struct {
@@ -73,12 +74,12 @@ type apiLimiter struct {
 var limiter *apiLimiter
 
 func NewAPILimiter(cfg *APILimiterConfig) error {
-	localStore := smemory.NewStoreWithOptions(slimiter.StoreOptions{
-		Prefix:          "sp_api_rate_limiter",
-		CleanUpInterval: 5 * time.Second,
-	})
+	store, err := newLimiterStore(cfg.Store)
+	if err != nil {
+		return err
+	}
 	limiter = &apiLimiter{
-		store: localStore,
+		store: store,
 		cfg: APILimiterConfig{
 			APILimits:    make(map[string][]MemoryLimiterConfig),
 			PathPattern:  make(map[string][]MemoryLimiterConfig),
@@ -86,10 +87,10 @@ func NewAPILimiter(cfg *APILimiterConfig) error {
 			HostPattern:  make(map[string][]MemoryLimiterConfig),
 			HostSequence: cfg.HostSequence,
 			IPLimitCfg:   cfg.IPLimitCfg,
+			Store:        cfg.Store,
 		},
 	}
 
-	var err error
 	var rate slimiter.Rate
 
 	for k, v := range cfg.PathPattern {
@@ -107,7 +108,7 @@ func NewAPILimiter(cfg *APILimiterConfig) error {
 				return err
 			}
 
-			limiter.limiterMap.Store(strings.ToLower(k), slimiter.New(localStore, rate))
+			limiter.limiterMap.Store(strings.ToLower(k), slimiter.New(store, rate))
 		}
 	}
 
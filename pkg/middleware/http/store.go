@@ -0,0 +1,175 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	slimiter "github.com/ulule/limiter/v3"
+	sredis "github.com/ulule/limiter/v3/drivers/store/redis"
+
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+)
+
+const (
+	// MemoryStoreType keeps the rate limit counters in the memory of each gateway replica.
+	MemoryStoreType = "memory"
+	// RedisStoreType shares the rate limit counters among the gateway replicas by the redis protocol store.
+	RedisStoreType = "redis"
+
+	// DefaultStorePrefix defines the default prefix of the rate limit counter keys.
+	DefaultStorePrefix = "sp_api_rate_limiter"
+	// DefaultStoreTimeoutMillisecond defines the default timeout of accessing the shared store.
+	DefaultStoreTimeoutMillisecond = 100
+	// DefaultStoreFallbackRetrySecond defines the default interval of retrying the shared store after it is
+	// unreachable, the local store is used in the meantime.
+	DefaultStoreFallbackRetrySecond = 10
+)
+
+// RateLimiterStoreConfig selects the store of the rate limit counters.
+type RateLimiterStoreConfig struct {
+	// Type is memory or redis, the default is memory.
+	Type                string `comment:"optional"`
+	Address             string `comment:"optional"`
+	Username            string `comment:"optional"`
+	Password            string `comment:"optional"`
+	DB                  int    `comment:"optional"`
+	Prefix              string `comment:"optional"`
+	TimeoutMillisecond  int64  `comment:"optional"`
+	FallbackRetrySecond int64  `comment:"optional"`
+}
+
+func newLimiterStore(cfg RateLimiterStoreConfig) (slimiter.Store, error) {
+	if cfg.Prefix == "" {
+		cfg.Prefix = DefaultStorePrefix
+	}
+	localStore := newMemoryStore(cfg.Prefix, DefaultMemoryStoreCleanUpInterval)
+	switch strings.ToLower(cfg.Type) {
+	case "", MemoryStoreType:
+		return localStore, nil
+	case RedisStoreType:
+		if cfg.Address == "" {
+			return nil, fmt.Errorf("the address of the %s rate limiter store is required", RedisStoreType)
+		}
+		if cfg.TimeoutMillisecond == 0 {
+			cfg.TimeoutMillisecond = DefaultStoreTimeoutMillisecond
+		}
+		if cfg.FallbackRetrySecond == 0 {
+			cfg.FallbackRetrySecond = DefaultStoreFallbackRetrySecond
+		}
+		timeout := time.Duration(cfg.TimeoutMillisecond) * time.Millisecond
+		client := redis.NewClient(&redis.Options{
+			Addr:         cfg.Address,
+			Username:     cfg.Username,
+			Password:     cfg.Password,
+			DB:           cfg.DB,
+			DialTimeout:  timeout,
+			ReadTimeout:  timeout,
+			WriteTimeout: timeout,
+			MaxRetries:   -1,
+		})
+		return newFallbackStore(func() (slimiter.Store, error) {
+			return sredis.NewStoreWithOptions(client, slimiter.StoreOptions{Prefix: cfg.Prefix, MaxRetry: slimiter.DefaultMaxRetry})
+		}, localStore, time.Duration(cfg.FallbackRetrySecond)*time.Second), nil
+	default:
+		return nil, fmt.Errorf("unsupported rate limiter store type: %s", cfg.Type)
+	}
+}
+
+// fallbackStore counts by the shared store and falls back to the local store when the shared store is
+// unreachable, so the limits are still enforced per replica rather than failing open or closed. The shared
+// store is retried after retryInterval.
+type fallbackStore struct {
+	newShared     func() (slimiter.Store, error)
+	local         slimiter.Store
+	retryInterval time.Duration
+
+	mu        sync.Mutex
+	shared    slimiter.Store
+	downUntil time.Time
+}
+
+func newFallbackStore(newShared func() (slimiter.Store, error), local slimiter.Store,
+	retryInterval time.Duration) *fallbackStore {
+	return &fallbackStore{
+		newShared:     newShared,
+		local:         local,
+		retryInterval: retryInterval,
+	}
+}
+
+// sharedStore returns the shared store, nil means the shared store is unreachable now.
+func (f *fallbackStore) sharedStore() slimiter.Store {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if time.Now().Before(f.downUntil) {
+		return nil
+	}
+	if f.shared == nil {
+		// the shared store loads the scripts on creation, it fails if the store is unreachable
+		shared, err := f.newShared()
+		if err != nil {
+			f.markDownLocked(err)
+			return nil
+		}
+		f.shared = shared
+	}
+	return f.shared
+}
+
+func (f *fallbackStore) markDown(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.markDownLocked(err)
+}
+
+func (f *fallbackStore) markDownLocked(err error) {
+	if time.Now().Before(f.downUntil) {
+		return
+	}
+	log.Warnw("shared rate limiter store is unreachable, fall back to the local store",
+		"retry_interval", f.retryInterval, "error", err)
+	f.downUntil = time.Now().Add(f.retryInterval)
+}
+
+func (f *fallbackStore) do(fn func(store slimiter.Store) (slimiter.Context, error)) (slimiter.Context, error) {
+	if shared := f.sharedStore(); shared != nil {
+		limiterCtx, err := fn(shared)
+		if err == nil {
+			return limiterCtx, nil
+		}
+		f.markDown(err)
+	}
+	return fn(f.local)
+}
+
+// Get returns the limit for the given key.
+func (f *fallbackStore) Get(ctx context.Context, key string, rate slimiter.Rate) (slimiter.Context, error) {
+	return f.do(func(store slimiter.Store) (slimiter.Context, error) {
+		return store.Get(ctx, key, rate)
+	})
+}
+
+// Peek returns the limit for the given key without increasing the counter.
+func (f *fallbackStore) Peek(ctx context.Context, key string, rate slimiter.Rate) (slimiter.Context, error) {
+	return f.do(func(store slimiter.Store) (slimiter.Context, error) {
+		return store.Peek(ctx, key, rate)
+	})
+}
+
+// Reset resets the counter of the given key.
+func (f *fallbackStore) Reset(ctx context.Context, key string, rate slimiter.Rate) (slimiter.Context, error) {
+	return f.do(func(store slimiter.Store) (slimiter.Context, error) {
+		return store.Reset(ctx, key, rate)
+	})
+}
+
+// Increment increases the counter of the given key by count.
+func (f *fallbackStore) Increment(ctx context.Context, key string, count int64, rate slimiter.Rate) (slimiter.Context, error) {
+	return f.do(func(store slimiter.Store) (slimiter.Context, error) {
+		return store.Increment(ctx, key, count, rate)
+	})
+}
@@ -0,0 +1,114 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	slimiter "github.com/ulule/limiter/v3"
+)
+
+func mockRedisStore(t *testing.T, address string) *fallbackStore {
+	t.Helper()
+	store, err := newLimiterStore(RateLimiterStoreConfig{Type: RedisStoreType, Address: address})
+	assert.Nil(t, err)
+	f, ok := store.(*fallbackStore)
+	assert.True(t, ok)
+	f.retryInterval = 50 * time.Millisecond
+	return f
+}
+
+func TestNewLimiterStore(t *testing.T) {
+	store, err := newLimiterStore(RateLimiterStoreConfig{})
+	assert.Nil(t, err)
+	_, ok := store.(*fallbackStore)
+	assert.False(t, ok)
+
+	_, err = newLimiterStore(RateLimiterStoreConfig{Type: RedisStoreType})
+	assert.NotNil(t, err)
+	_, err = newLimiterStore(RateLimiterStoreConfig{Type: "etcd"})
+	assert.NotNil(t, err)
+}
+
+func TestFallbackStoreShared(t *testing.T) {
+	s := miniredis.RunT(t)
+	rate := slimiter.Rate{Period: time.Minute, Limit: 3}
+	ctx := context.Background()
+	// the counters are shared by the replicas
+	replica1, replica2 := mockRedisStore(t, s.Addr()), mockRedisStore(t, s.Addr())
+	for i := 0; i < 3; i++ {
+		limiterCtx, err := replica1.Increment(ctx, "key", 1, rate)
+		assert.Nil(t, err)
+		assert.False(t, limiterCtx.Reached)
+	}
+	limiterCtx, err := replica2.Increment(ctx, "key", 1, rate)
+	assert.Nil(t, err)
+	assert.True(t, limiterCtx.Reached)
+	assert.True(t, s.Exists(DefaultStorePrefix+":key"))
+
+	limiterCtx, err = replica2.Reset(ctx, "key", rate)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), limiterCtx.Remaining)
+	limiterCtx, err = replica1.Peek(ctx, "key", rate)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), limiterCtx.Remaining)
+}
+
+func TestFallbackStoreUnreachable(t *testing.T) {
+	s := miniredis.RunT(t)
+	rate := slimiter.Rate{Period: time.Minute, Limit: 1}
+	ctx := context.Background()
+	f := mockRedisStore(t, s.Addr())
+	_, err := f.Increment(ctx, "shared", 1, rate)
+	assert.Nil(t, err)
+
+	// the local limits are enforced when the shared store is unreachable
+	s.Close()
+	limiterCtx, err := f.Increment(ctx, "local", 1, rate)
+	assert.Nil(t, err)
+	assert.False(t, limiterCtx.Reached)
+	limiterCtx, err = f.Increment(ctx, "local", 1, rate)
+	assert.Nil(t, err)
+	assert.True(t, limiterCtx.Reached)
+	assert.Nil(t, f.sharedStore())
+
+	// the shared store is used again after the retry interval
+	assert.Nil(t, s.Restart())
+	time.Sleep(2 * f.retryInterval)
+	limiterCtx, err = f.Increment(ctx, "shared", 1, rate)
+	assert.Nil(t, err)
+	assert.True(t, limiterCtx.Reached)
+}
+
+func TestFallbackStoreUnreachableOnStart(t *testing.T) {
+	s := miniredis.RunT(t)
+	address := s.Addr()
+	s.Close()
+	f := mockRedisStore(t, address)
+	limiterCtx, err := f.Increment(context.Background(), "key", 1, slimiter.Rate{Period: time.Minute, Limit: 1})
+	assert.Nil(t, err)
+	assert.False(t, limiterCtx.Reached)
+	assert.Nil(t, f.shared)
+}
+
+func TestLimitWithSharedStore(t *testing.T) {
+	s := miniredis.RunT(t)
+	cfg := &APILimiterConfig{
+		IPLimitCfg: IPLimitConfig{On: true, RateLimit: 1, RatePeriod: "M"},
+		Store:      RateLimiterStoreConfig{Type: RedisStoreType, Address: s.Addr()},
+	}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	// each replica creates its own limiter and they share the ip limit
+	var codes []int
+	for i := 0; i < 2; i++ {
+		assert.Nil(t, NewAPILimiter(cfg))
+		w := httptest.NewRecorder()
+		Limit("www.route-test.com")(handler).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://www.route-test.com/status", nil))
+		codes = append(codes, w.Code)
+	}
+	assert.Equal(t, []int{http.StatusOK, http.StatusTooManyRequests}, codes)
+}
@@ -0,0 +1,211 @@
+package mtls
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+)
+
+// AnyCaller allows any caller with a verified certificate.
+const AnyCaller = "*"
+
+// CallerModules returns the modules of the caller, which are the OrganizationalUnit of its verified client
+// certificate, e.g. OU=manager,OU=taskexecutor for the process running the manager and the executor.
+func CallerModules(ctx context.Context) ([]string, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return nil, false
+	}
+	return info.State.VerifiedChains[0][0].Subject.OrganizationalUnit, true
+}
+
+// CallerModulesMetadataKey is the gRPC metadata key of the modules claimed by the caller, it identifies the
+// caller when the mutual TLS is disabled.
+const CallerModulesMetadataKey = "x-gfsp-caller-modules"
+
+// ClaimedCallerModules returns the modules claimed by the caller in the gRPC metadata. Unlike CallerModules, they
+// are not verified, so they are only recorded but never authorized.
+func ClaimedCallerModules(ctx context.Context) ([]string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	var modules []string
+	for _, value := range md.Get(CallerModulesMetadataKey) {
+		for _, module := range strings.Split(value, ",") {
+			if module = strings.TrimSpace(module); module != "" {
+				modules = append(modules, module)
+			}
+		}
+	}
+	return modules, len(modules) > 0
+}
+
+// UnaryClientInterceptor returns the interceptor claiming the modules of the caller in the metadata of the calls.
+func UnaryClientInterceptor(modules []string) grpc.UnaryClientInterceptor {
+	claimed := strings.Join(modules, ",")
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption,
+	) error {
+		return invoker(metadata.AppendToOutgoingContext(ctx, CallerModulesMetadataKey, claimed), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor returns the interceptor claiming the modules of the caller in the metadata of the streams.
+func StreamClientInterceptor(modules []string) grpc.StreamClientInterceptor {
+	claimed := strings.Join(modules, ",")
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+		streamer grpc.Streamer, opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		return streamer(metadata.AppendToOutgoingContext(ctx, CallerModulesMetadataKey, claimed), desc, cc, method, opts...)
+	}
+}
+
+// Policy authorizes the callers by rules, a rule is keyed by a gRPC service like /pkg.Service, a method like
+// /pkg.Service/Method, or a request of a method like /pkg.Service/Method/field, where field is the name of the
+// field set in the oneof of the request, so that the requests multiplexed by one method are authorized
+// separately. The most specific rule is applied, and the calls without any rule are allowed.
+type Policy struct {
+	rules map[string]map[string]struct{}
+}
+
+// NewPolicy returns an empty Policy.
+func NewPolicy() *Policy {
+	return &Policy{rules: make(map[string]map[string]struct{})}
+}
+
+// Allow sets the modules allowed by the rule, it overrides the modules set before.
+func (p *Policy) Allow(rule string, modules ...string) {
+	allowed := make(map[string]struct{}, len(modules))
+	for _, module := range modules {
+		allowed[module] = struct{}{}
+	}
+	p.rules[rule] = allowed
+}
+
+// Parse sets the rules in the format of "<rule>=<module>,<module>".
+func (p *Policy) Parse(items []string) error {
+	for _, item := range items {
+		rule, modules, ok := strings.Cut(item, "=")
+		rule = strings.TrimSpace(rule)
+		if !ok || !strings.HasPrefix(rule, "/") {
+			return fmt.Errorf("invalid caller policy: %s", item)
+		}
+		var allowed []string
+		for _, module := range strings.Split(modules, ",") {
+			if module = strings.TrimSpace(module); module != "" {
+				allowed = append(allowed, module)
+			}
+		}
+		p.Allow(rule, allowed...)
+	}
+	return nil
+}
+
+// Authorize returns the PermissionDenied error if none of the callers is allowed to call the method with
+// the request, the request is nil for the streams.
+func (p *Policy) Authorize(fullMethod string, req interface{}, callers []string) error {
+	allowed, rule, ok := p.match(fullMethod, req)
+	if !ok {
+		return nil
+	}
+	if _, ok = allowed[AnyCaller]; ok {
+		return nil
+	}
+	for _, caller := range callers {
+		if _, ok = allowed[caller]; ok {
+			return nil
+		}
+	}
+	return status.Errorf(codes.PermissionDenied, "caller %v is not allowed to call %s", callers, rule)
+}
+
+func (p *Policy) match(fullMethod string, req interface{}) (map[string]struct{}, string, bool) {
+	var rules []string
+	if field := oneofField(req); field != "" {
+		rules = append(rules, fullMethod+"/"+field)
+	}
+	rules = append(rules, fullMethod)
+	if idx := strings.LastIndex(fullMethod, "/"); idx > 0 {
+		rules = append(rules, fullMethod[:idx])
+	}
+	for _, rule := range rules {
+		if allowed, ok := p.rules[rule]; ok {
+			return allowed, rule, true
+		}
+	}
+	return nil, "", false
+}
+
+// oneofField returns the name of the field set in the first set oneof of the generated proto message, it
+// reads the struct tags which are the same for the gogo and the golang protobuf.
+func oneofField(req interface{}) string {
+	v := reflect.ValueOf(req)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return ""
+	}
+	v = v.Elem()
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+	for i := 0; i < v.NumField(); i++ {
+		if _, ok := v.Type().Field(i).Tag.Lookup("protobuf_oneof"); !ok {
+			continue
+		}
+		field := v.Field(i)
+		if field.Kind() != reflect.Interface || field.IsNil() {
+			continue
+		}
+		wrapper := field.Elem()
+		if wrapper.Kind() == reflect.Ptr {
+			wrapper = wrapper.Elem()
+		}
+		if wrapper.Kind() != reflect.Struct || wrapper.NumField() == 0 {
+			continue
+		}
+		for _, part := range strings.Split(wrapper.Type().Field(0).Tag.Get("protobuf"), ",") {
+			if name, ok := strings.CutPrefix(part, "name="); ok {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// UnaryServerInterceptor returns the interceptor authorizing the unary calls by the policy.
+func UnaryServerInterceptor(policy *Policy) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		callers, _ := CallerModules(ctx)
+		if err := policy.Authorize(info.FullMethod, req, callers); err != nil {
+			log.CtxWarnw(ctx, "unauthorized grpc call", "method", info.FullMethod, "callers", callers, "error", err)
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns the interceptor authorizing the streams by the policy.
+func StreamServerInterceptor(policy *Policy) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		callers, _ := CallerModules(ss.Context())
+		if err := policy.Authorize(info.FullMethod, nil, callers); err != nil {
+			log.CtxWarnw(ss.Context(), "unauthorized grpc stream", "method", info.FullMethod, "callers", callers, "error", err)
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,98 @@
+package mtls
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// mockSignRequest mirrors the generated message with a oneof.
+type mockSignRequest struct {
+	Request isMockSignRequest_Request `protobuf_oneof:"request"`
+}
+
+type isMockSignRequest_Request interface{ isMockSignRequest_Request() }
+
+type mockSignRequest_SealObjectInfo struct {
+	SealObjectInfo *struct{} `protobuf:"bytes,4,opt,name=seal_object_info,json=sealObjectInfo,proto3,oneof"`
+}
+
+func (*mockSignRequest_SealObjectInfo) isMockSignRequest_Request() {}
+
+func TestOneofField(t *testing.T) {
+	assert.Equal(t, "seal_object_info",
+		oneofField(&mockSignRequest{Request: &mockSignRequest_SealObjectInfo{}}))
+	assert.Equal(t, "", oneofField(&mockSignRequest{}))
+	assert.Equal(t, "", oneofField((*mockSignRequest)(nil)))
+	assert.Equal(t, "", oneofField(nil))
+	assert.Equal(t, "", oneofField("request"))
+}
+
+func TestPolicy_Authorize(t *testing.T) {
+	policy := NewPolicy()
+	assert.Nil(t, policy.Parse([]string{
+		"/svc.Sign = approver, manager",
+		"/svc.Sign/Sign/seal_object_info=manager,taskexecutor",
+		"/svc.Query/List=*",
+		"/svc.Query=",
+	}))
+	seal := &mockSignRequest{Request: &mockSignRequest_SealObjectInfo{}}
+	cases := []struct {
+		name    string
+		method  string
+		req     interface{}
+		callers []string
+		allowed bool
+	}{
+		{"request rule allows", "/svc.Sign/Sign", seal, []string{"taskexecutor"}, true},
+		{"request rule denies", "/svc.Sign/Sign", seal, []string{"approver"}, false},
+		{"service rule allows", "/svc.Sign/Sign", &mockSignRequest{}, []string{"approver"}, true},
+		{"service rule denies", "/svc.Sign/Sign", nil, []string{"gateway"}, false},
+		{"any caller", "/svc.Query/List", nil, nil, true},
+		{"empty rule denies", "/svc.Query/Get", nil, []string{"manager"}, false},
+		{"no rule", "/svc.Upload/Upload", nil, nil, true},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Authorize(tt.method, tt.req, tt.callers)
+			if tt.allowed {
+				assert.Nil(t, err)
+			} else {
+				assert.Equal(t, codes.PermissionDenied, status.Code(err))
+			}
+		})
+	}
+
+	assert.NotNil(t, NewPolicy().Parse([]string{"svc.Sign=manager"}))
+	assert.NotNil(t, NewPolicy().Parse([]string{"/svc.Sign"}))
+}
+
+func TestClaimedCallerModules(t *testing.T) {
+	var claimed context.Context
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		opts ...grpc.CallOption,
+	) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		claimed = metadata.NewIncomingContext(ctx, md)
+		return nil
+	}
+	interceptor := UnaryClientInterceptor([]string{"manager", "taskexecutor"})
+	assert.Nil(t, interceptor(context.Background(), "/svc.Sign/Sign", nil, nil, nil, invoker))
+	modules, ok := ClaimedCallerModules(claimed)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"manager", "taskexecutor"}, modules)
+
+	// the claimed modules are not verified callers
+	_, ok = CallerModules(claimed)
+	assert.False(t, ok)
+	_, ok = ClaimedCallerModules(context.Background())
+	assert.False(t, ok)
+	_, ok = ClaimedCallerModules(metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(CallerModulesMetadataKey, " , ")))
+	assert.False(t, ok)
+}
@@ -0,0 +1,191 @@
+package mtls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+)
+
+// DefaultReloadInterval defines the default min interval of checking whether the certificate files change.
+const DefaultReloadInterval = time.Minute
+
+// Config is the config of the mutual TLS of the internal gRPC endpoints.
+type Config struct {
+	// CAFile is the pem of the CAs which issue the certificates of the servers and the clients.
+	CAFile string
+	// CertFile and KeyFile are the pem of the certificate and the key of this process, which is used both as
+	// the server and as the client.
+	CertFile string
+	KeyFile  string
+	// ServerName overrides the name to verify the server certificates, default is the host of the endpoint.
+	ServerName string
+	// ReloadInterval is the min interval of checking the modification of the files.
+	ReloadInterval time.Duration
+}
+
+// Reloader holds the certificate and the CAs loaded from the files, the files are checked on the handshakes
+// at most once per reload interval and reloaded if they are modified, so that the rotated certificates take
+// effect on the new connections without restart. The old ones are kept if the new files are invalid.
+type Reloader struct {
+	cfg Config
+
+	cert atomic.Pointer[tls.Certificate]
+	pool atomic.Pointer[x509.CertPool]
+
+	mu        sync.Mutex
+	lastCheck time.Time
+	modTimes  [3]time.Time
+}
+
+// NewReloader returns a Reloader with the files loaded.
+func NewReloader(cfg Config) (*Reloader, error) {
+	if cfg.CAFile == "" || cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, errors.New("ca, cert and key files are required")
+	}
+	if cfg.ReloadInterval <= 0 {
+		cfg.ReloadInterval = DefaultReloadInterval
+	}
+	r := &Reloader{cfg: cfg}
+	modTimes, err := r.stat()
+	if err != nil {
+		return nil, err
+	}
+	if err = r.load(); err != nil {
+		return nil, err
+	}
+	r.modTimes = modTimes
+	r.lastCheck = time.Now()
+	return r, nil
+}
+
+// Reload reloads the files if any of them is modified since the last load.
+func (r *Reloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastCheck = time.Now()
+	modTimes, err := r.stat()
+	if err != nil {
+		return err
+	}
+	if modTimes == r.modTimes {
+		return nil
+	}
+	if err = r.load(); err != nil {
+		return err
+	}
+	r.modTimes = modTimes
+	log.Infow("succeed to reload tls certificates", "cert", r.cfg.CertFile, "ca", r.cfg.CAFile)
+	return nil
+}
+
+func (r *Reloader) maybeReload() {
+	r.mu.Lock()
+	due := time.Since(r.lastCheck) >= r.cfg.ReloadInterval
+	r.mu.Unlock()
+	if !due {
+		return
+	}
+	if err := r.Reload(); err != nil {
+		log.Errorw("failed to reload tls certificates, keep the loaded ones", "error", err)
+	}
+}
+
+func (r *Reloader) stat() ([3]time.Time, error) {
+	var modTimes [3]time.Time
+	for i, file := range []string{r.cfg.CAFile, r.cfg.CertFile, r.cfg.KeyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return modTimes, err
+		}
+		modTimes[i] = info.ModTime()
+	}
+	return modTimes, nil
+}
+
+func (r *Reloader) load() error {
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load key pair: %w", err)
+	}
+	caPem, err := os.ReadFile(r.cfg.CAFile)
+	if err != nil {
+		return err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPem) {
+		return fmt.Errorf("no certificate in ca file %s", r.cfg.CAFile)
+	}
+	r.cert.Store(&cert)
+	r.pool.Store(pool)
+	return nil
+}
+
+// Certificate returns the loaded certificate.
+func (r *Reloader) Certificate() *tls.Certificate {
+	return r.cert.Load()
+}
+
+// ServerTLSConfig returns the server config which requires and verifies the client certificates.
+func (r *Reloader) ServerTLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.maybeReload()
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*r.cert.Load()},
+				ClientCAs:    r.pool.Load(),
+				ClientAuth:   tls.RequireAndVerifyClientCert,
+			}, nil
+		},
+	}
+}
+
+// ClientTLSConfig returns the client config which presents the certificate and verifies the servers by the
+// current CAs. The standard verification is skipped since it only uses the CAs at the time of the config.
+func (r *Reloader) ClientTLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: r.cfg.ServerName,
+		// the server certificates are verified by VerifyConnection
+		InsecureSkipVerify: true, // #nosec G402
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			r.maybeReload()
+			return r.cert.Load(), nil
+		},
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return errors.New("no server certificate")
+			}
+			opts := x509.VerifyOptions{
+				DNSName:       cs.ServerName,
+				Roots:         r.pool.Load(),
+				Intermediates: x509.NewCertPool(),
+			}
+			for _, cert := range cs.PeerCertificates[1:] {
+				opts.Intermediates.AddCert(cert)
+			}
+			_, err := cs.PeerCertificates[0].Verify(opts)
+			return err
+		},
+	}
+}
+
+// ServerCredentials returns the gRPC server credentials.
+func (r *Reloader) ServerCredentials() credentials.TransportCredentials {
+	return credentials.NewTLS(r.ServerTLSConfig())
+}
+
+// ClientCredentials returns the gRPC client credentials.
+func (r *Reloader) ClientCredentials() credentials.TransportCredentials {
+	return credentials.NewTLS(r.ClientTLSConfig())
+}
@@ -0,0 +1,163 @@
+package mtls
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.Nil(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.Nil(t, err)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue writes the ca, the cert and the key issued for the modules to the dir.
+func (ca *testCA) issue(t *testing.T, dir string, serial int64, modules ...string) Config {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "sp", OrganizationalUnit: modules},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	assert.Nil(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+	cfg := Config{
+		CAFile:     filepath.Join(dir, "ca.pem"),
+		CertFile:   filepath.Join(dir, "cert.pem"),
+		KeyFile:    filepath.Join(dir, "key.pem"),
+		ServerName: "localhost",
+	}
+	assert.Nil(t, os.WriteFile(cfg.CAFile, ca.pem, 0o600))
+	assert.Nil(t, os.WriteFile(cfg.CertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	assert.Nil(t, os.WriteFile(cfg.KeyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600))
+	return cfg
+}
+
+func TestReloader_Reload(t *testing.T) {
+	ca := newTestCA(t)
+	dir := t.TempDir()
+	cfg := ca.issue(t, dir, 2, "manager")
+	r, err := NewReloader(cfg)
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultReloadInterval, r.cfg.ReloadInterval)
+	leaf, err := x509.ParseCertificate(r.Certificate().Certificate[0])
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), leaf.SerialNumber.Int64())
+
+	// not modified
+	assert.Nil(t, r.Reload())
+
+	// rotated
+	ca.issue(t, dir, 3, "manager")
+	future := time.Now().Add(time.Minute)
+	assert.Nil(t, os.Chtimes(cfg.CertFile, future, future))
+	assert.Nil(t, r.Reload())
+	leaf, err = x509.ParseCertificate(r.Certificate().Certificate[0])
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), leaf.SerialNumber.Int64())
+
+	// the invalid files are not loaded
+	assert.Nil(t, os.WriteFile(cfg.KeyFile, []byte("invalid"), 0o600))
+	assert.NotNil(t, r.Reload())
+	leaf, err = x509.ParseCertificate(r.Certificate().Certificate[0])
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), leaf.SerialNumber.Int64())
+
+	_, err = NewReloader(Config{CAFile: cfg.CAFile})
+	assert.NotNil(t, err)
+	_, err = NewReloader(cfg)
+	assert.NotNil(t, err)
+}
+
+func TestMutualTLS(t *testing.T) {
+	ca := newTestCA(t)
+	serverReloader, err := NewReloader(ca.issue(t, t.TempDir(), 2, "signer"))
+	assert.Nil(t, err)
+	policy := NewPolicy()
+	policy.Allow("/grpc.health.v1.Health/Check", "manager")
+	server := grpc.NewServer(
+		grpc.Creds(serverReloader.ServerCredentials()),
+		grpc.ChainUnaryInterceptor(UnaryServerInterceptor(policy)),
+		grpc.ChainStreamInterceptor(StreamServerInterceptor(policy)),
+	)
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	go func() { _ = server.Serve(lis) }()
+	defer server.Stop()
+
+	check := func(t *testing.T, opt grpc.DialOption) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		conn, err := grpc.DialContext(ctx, lis.Addr().String(), opt)
+		assert.Nil(t, err)
+		defer conn.Close()
+		_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+		return err
+	}
+
+	t.Run("allowed caller", func(t *testing.T) {
+		r, err := NewReloader(ca.issue(t, t.TempDir(), 3, "manager", "taskexecutor"))
+		assert.Nil(t, err)
+		assert.Nil(t, check(t, grpc.WithTransportCredentials(r.ClientCredentials())))
+	})
+	t.Run("denied caller", func(t *testing.T) {
+		r, err := NewReloader(ca.issue(t, t.TempDir(), 4, "gateway"))
+		assert.Nil(t, err)
+		err = check(t, grpc.WithTransportCredentials(r.ClientCredentials()))
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+	t.Run("untrusted caller", func(t *testing.T) {
+		r, err := NewReloader(newTestCA(t).issue(t, t.TempDir(), 5, "manager"))
+		assert.Nil(t, err)
+		assert.Equal(t, codes.Unavailable, status.Code(check(t, grpc.WithTransportCredentials(r.ClientCredentials()))))
+	})
+	t.Run("insecure caller", func(t *testing.T) {
+		assert.Equal(t, codes.Unavailable, status.Code(check(t, grpc.WithTransportCredentials(insecure.NewCredentials()))))
+	})
+}
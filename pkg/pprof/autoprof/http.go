@@ -0,0 +1,112 @@
+package autoprof
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+)
+
+const (
+	// ListPath is the HTTP path listing the metas of the captured profiles.
+	ListPath = "/debug/autoprof/profiles"
+	// FetchPath is the HTTP path fetching a captured profile by the name query parameter.
+	FetchPath = "/debug/autoprof/profile"
+	// NameQuery is the query parameter of the profile name.
+	NameQuery = "name"
+)
+
+// ListHandler responds the metas of the profiles from the newest to the oldest in JSON.
+func ListHandler(store *Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		metas, err := store.List(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if metas == nil {
+			metas = []*Meta{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err = json.NewEncoder(w).Encode(metas); err != nil {
+			log.Errorw("failed to write profile list", "error", err)
+		}
+	}
+}
+
+// FetchHandler responds the data of the profile named by the name query parameter.
+func FetchHandler(store *Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get(NameQuery)
+		if name == "" {
+			http.Error(w, "missing profile name", http.StatusBadRequest)
+			return
+		}
+		data, err := store.Get(r.Context(), name)
+		if errors.Is(err, ErrProfileNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+		if _, err = w.Write(data); err != nil {
+			log.Errorw("failed to write profile", "name", name, "error", err)
+		}
+	}
+}
+
+// ListProfiles lists the profiles from the pprof server at the address.
+func ListProfiles(ctx context.Context, address string) ([]*Meta, error) {
+	body, err := get(ctx, baseURL(address)+ListPath)
+	if err != nil {
+		return nil, err
+	}
+	var metas []*Meta
+	if err = json.Unmarshal(body, &metas); err != nil {
+		return nil, fmt.Errorf("failed to parse profile list: %w", err)
+	}
+	return metas, nil
+}
+
+// FetchProfile fetches the profile of the name from the pprof server at the address.
+func FetchProfile(ctx context.Context, address, name string) ([]byte, error) {
+	return get(ctx, baseURL(address)+FetchPath+"?"+NameQuery+"="+url.QueryEscape(name))
+}
+
+func baseURL(address string) string {
+	address = strings.TrimSuffix(address, "/")
+	if strings.HasPrefix(address, "http://") || strings.HasPrefix(address, "https://") {
+		return address
+	}
+	return "http://" + address
+}
+
+func get(ctx context.Context, rawURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
@@ -0,0 +1,46 @@
+package autoprof
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListAndFetchProfiles(t *testing.T) {
+	dir, err := NewDirStore(t.TempDir())
+	assert.Nil(t, err)
+	store := NewStore(dir)
+	mux := http.NewServeMux()
+	mux.Handle(ListPath, ListHandler(store))
+	mux.Handle(FetchPath, FetchHandler(store))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	ctx := context.Background()
+
+	metas, err := ListProfiles(ctx, server.URL)
+	assert.Nil(t, err)
+	assert.Empty(t, metas)
+
+	meta := &Meta{Name: "a b.pb.gz", Trigger: HeapTriggerName, Profile: ProfileHeap, CreatedAt: time.Now().UTC()}
+	assert.Nil(t, store.Put(ctx, meta, []byte("data")))
+	metas, err = ListProfiles(ctx, strings.TrimPrefix(server.URL, "http://")+"/")
+	assert.Nil(t, err)
+	assert.Len(t, metas, 1)
+	assert.Equal(t, meta.Name, metas[0].Name)
+	assert.Equal(t, int64(4), metas[0].Size)
+	assert.True(t, meta.CreatedAt.Equal(metas[0].CreatedAt))
+
+	data, err := FetchProfile(ctx, server.URL, meta.Name)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("data"), data)
+
+	_, err = FetchProfile(ctx, server.URL, "unknown")
+	assert.ErrorContains(t, err, "unexpected status 404")
+	_, err = FetchProfile(ctx, server.URL, "")
+	assert.ErrorContains(t, err, "unexpected status 400")
+}
@@ -0,0 +1,215 @@
+package autoprof
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"runtime/pprof"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+)
+
+const (
+	// ProfileCPU is the type of the cpu profile.
+	ProfileCPU = "cpu"
+	// ProfileHeap is the type of the heap profile.
+	ProfileHeap = "heap"
+	// ProfileGoroutine is the type of the goroutine profile.
+	ProfileGoroutine = "goroutine"
+
+	// DefaultCheckInterval defines the default interval of checking the triggers.
+	DefaultCheckInterval = 10 * time.Second
+	// DefaultCooldown defines the default min interval between two captures, so that a lasting anomaly
+	// does not fill the store.
+	DefaultCooldown = 10 * time.Minute
+	// DefaultCPUDuration defines the default duration of the cpu profile.
+	DefaultCPUDuration = 10 * time.Second
+	// DefaultMaxProfiles defines the default max number of the kept profiles.
+	DefaultMaxProfiles = 60
+	// DefaultMaxAge defines the default max age of the kept profiles.
+	DefaultMaxAge = 7 * 24 * time.Hour
+	// DefaultCaptureTimeout defines the default timeout of storing a snapshot besides the cpu profile.
+	DefaultCaptureTimeout = time.Minute
+)
+
+// Config is the config of the Profiler, the zero fields are set to the defaults.
+type Config struct {
+	CheckInterval time.Duration
+	Cooldown      time.Duration
+	// CPUDuration is the duration of the cpu profile, negative disables the cpu profile.
+	CPUDuration time.Duration
+	MaxProfiles int
+	MaxAge      time.Duration
+}
+
+func (c *Config) setDefaults() {
+	if c.CheckInterval <= 0 {
+		c.CheckInterval = DefaultCheckInterval
+	}
+	if c.Cooldown <= 0 {
+		c.Cooldown = DefaultCooldown
+	}
+	if c.CPUDuration == 0 {
+		c.CPUDuration = DefaultCPUDuration
+	}
+	if c.MaxProfiles <= 0 {
+		c.MaxProfiles = DefaultMaxProfiles
+	}
+	if c.MaxAge <= 0 {
+		c.MaxAge = DefaultMaxAge
+	}
+}
+
+// Profiler checks the triggers periodically, and captures the heap, the goroutine and the cpu profiles to
+// the store when any of them fires, the profiles beyond the retention limits are pruned after each capture.
+type Profiler struct {
+	cfg      Config
+	store    *Store
+	triggers []Trigger
+
+	lastCapture time.Time
+	started     atomic.Bool
+	stopCh      chan struct{}
+	stopOnce    sync.Once
+	doneCh      chan struct{}
+}
+
+// NewProfiler returns a Profiler capturing to the store on the triggers.
+func NewProfiler(cfg Config, store *Store, triggers ...Trigger) *Profiler {
+	cfg.setDefaults()
+	return &Profiler{
+		cfg:      cfg,
+		store:    store,
+		triggers: triggers,
+		stopCh:   make(chan struct{}),
+		doneCh:   make(chan struct{}),
+	}
+}
+
+// Store returns the store of the captured profiles.
+func (p *Profiler) Store() *Store {
+	return p.store
+}
+
+// Start starts checking the triggers in the background.
+func (p *Profiler) Start() {
+	if p.started.CompareAndSwap(false, true) {
+		go p.loop()
+	}
+}
+
+// Stop stops checking and interrupts the running cpu profile, it waits for the running capture to finish.
+func (p *Profiler) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.stopCh)
+		if p.started.Load() {
+			<-p.doneCh
+		}
+	})
+}
+
+func (p *Profiler) loop() {
+	defer close(p.doneCh)
+	ticker := time.NewTicker(p.cfg.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stopCh:
+			return
+		case <-ticker.C:
+			p.check(time.Now())
+		}
+	}
+}
+
+// check checks all the triggers so that their baselines keep updated, and captures on the first fired one
+// if it is not in the cooldown.
+func (p *Profiler) check(now time.Time) {
+	var fired Trigger
+	var reason string
+	for _, trigger := range p.triggers {
+		if r, ok := trigger.Check(); ok && fired == nil {
+			fired, reason = trigger, r
+		}
+	}
+	if fired == nil {
+		return
+	}
+	if !p.lastCapture.IsZero() && now.Sub(p.lastCapture) < p.cfg.Cooldown {
+		log.Debugw("skip capturing profiles in cooldown", "trigger", fired.Name(), "reason", reason)
+		return
+	}
+	p.lastCapture = now
+	log.Warnw("anomaly is detected, start to capture profiles", "trigger", fired.Name(), "reason", reason)
+	if err := p.Capture(fired.Name(), reason); err != nil {
+		log.Errorw("failed to capture profiles", "trigger", fired.Name(), "error", err)
+	}
+}
+
+// Capture captures a snapshot of the profiles with the trigger and the reason, and prunes the store.
+func (p *Profiler) Capture(trigger, reason string) error {
+	createdAt := time.Now()
+	snapshot := createdAt.UTC().Format("20060102T150405.000Z") + "-" + trigger
+	profiles := []string{ProfileHeap, ProfileGoroutine}
+	if p.cfg.CPUDuration > 0 {
+		profiles = append(profiles, ProfileCPU)
+	}
+	for _, profile := range profiles {
+		data, err := p.profile(profile)
+		if err != nil {
+			log.Errorw("failed to capture profile", "profile", profile, "error", err)
+			continue
+		}
+		meta := &Meta{
+			Name:      snapshot + "-" + profile + ".pb.gz",
+			Snapshot:  snapshot,
+			Trigger:   trigger,
+			Reason:    reason,
+			Profile:   profile,
+			CreatedAt: createdAt,
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), DefaultCaptureTimeout)
+		err = p.store.Put(ctx, meta, data)
+		cancel()
+		if err != nil {
+			return err
+		}
+		log.Infow("succeed to capture profile", "name", meta.Name, "size", meta.Size)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultCaptureTimeout)
+	defer cancel()
+	deleted, err := p.store.Prune(ctx, p.cfg.MaxProfiles, p.cfg.MaxAge)
+	if err != nil {
+		return fmt.Errorf("failed to prune profiles: %w", err)
+	}
+	if deleted > 0 {
+		log.Infow("succeed to prune profiles", "deleted", deleted)
+	}
+	return nil
+}
+
+// profile returns the gzipped protobuf of the profile.
+func (p *Profiler) profile(profile string) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if profile != ProfileCPU {
+		if err := pprof.Lookup(profile).WriteTo(buf, 0); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	// fails if the cpu profile is running, e.g. by /debug/pprof/profile
+	if err := pprof.StartCPUProfile(buf); err != nil {
+		return nil, err
+	}
+	timer := time.NewTimer(p.cfg.CPUDuration)
+	select {
+	case <-timer.C:
+	case <-p.stopCh:
+		timer.Stop()
+	}
+	pprof.StopCPUProfile()
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,83 @@
+package autoprof
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockTrigger struct {
+	name   string
+	fired  bool
+	checks int
+}
+
+func (m *mockTrigger) Name() string { return m.name }
+
+func (m *mockTrigger) Check() (string, bool) {
+	m.checks++
+	return "mock reason", m.fired
+}
+
+func TestProfiler_check(t *testing.T) {
+	dir, err := NewDirStore(t.TempDir())
+	assert.Nil(t, err)
+	store := NewStore(dir)
+	quiet := &mockTrigger{name: "quiet"}
+	noisy := &mockTrigger{name: "noisy"}
+	profiler := NewProfiler(Config{CPUDuration: 10 * time.Millisecond, MaxProfiles: 4}, store, quiet, noisy)
+	assert.Equal(t, store, profiler.Store())
+
+	now := time.Now()
+	profiler.check(now)
+	metas, err := store.List(context.Background())
+	assert.Nil(t, err)
+	assert.Empty(t, metas)
+
+	noisy.fired = true
+	profiler.check(now)
+	metas, err = store.List(context.Background())
+	assert.Nil(t, err)
+	assert.Len(t, metas, 3)
+	profiles := map[string]bool{}
+	for _, meta := range metas {
+		profiles[meta.Profile] = true
+		assert.Equal(t, "noisy", meta.Trigger)
+		assert.Equal(t, "mock reason", meta.Reason)
+		assert.Equal(t, metas[0].Snapshot, meta.Snapshot)
+		assert.NotZero(t, meta.Size)
+	}
+	assert.Equal(t, map[string]bool{ProfileCPU: true, ProfileHeap: true, ProfileGoroutine: true}, profiles)
+
+	// in the cooldown
+	profiler.check(now.Add(time.Minute))
+	metas, err = store.List(context.Background())
+	assert.Nil(t, err)
+	assert.Len(t, metas, 3)
+
+	// the old profiles are pruned by MaxProfiles
+	profiler.check(now.Add(DefaultCooldown))
+	metas, err = store.List(context.Background())
+	assert.Nil(t, err)
+	assert.Len(t, metas, 4)
+	assert.Equal(t, 4, quiet.checks)
+}
+
+func TestProfiler_StartStop(t *testing.T) {
+	dir, err := NewDirStore(t.TempDir())
+	assert.Nil(t, err)
+	trigger := &mockTrigger{name: "mock", fired: true}
+	profiler := NewProfiler(Config{CheckInterval: time.Millisecond, CPUDuration: time.Hour}, NewStore(dir), trigger)
+	profiler.Start()
+	assert.Eventually(t, func() bool {
+		metas, _ := profiler.Store().List(context.Background())
+		return len(metas) == 2
+	}, 5*time.Second, time.Millisecond)
+	// the cpu profile of an hour is interrupted
+	profiler.Stop()
+	profiler.Stop()
+
+	NewProfiler(Config{}, NewStore(dir)).Stop()
+}
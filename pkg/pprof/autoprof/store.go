@@ -0,0 +1,240 @@
+package autoprof
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corepiecestore "github.com/zkMeLabs/mechain-storage-provider/core/piecestore"
+)
+
+// indexKey is the key of the blob which records the metas of the captured profiles.
+const indexKey = "index.json"
+
+// ErrProfileNotFound is returned if the profile is not in the store.
+var ErrProfileNotFound = errors.New("profile not found")
+
+// Meta describes a captured profile.
+type Meta struct {
+	// Name is the unique name of the profile, it is used to fetch the profile.
+	Name string `json:"name"`
+	// Snapshot is the name shared by the profiles captured at the same time.
+	Snapshot string `json:"snapshot"`
+	// Trigger is the name of the trigger which fired the capture.
+	Trigger string `json:"trigger"`
+	// Reason describes why the trigger fired.
+	Reason string `json:"reason"`
+	// Profile is the type of the profile, one of cpu, heap and goroutine.
+	Profile   string    `json:"profile"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BlobStore is the backend storing the profiles.
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte) error
+	// Get returns os.ErrNotExist if the key does not exist.
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Store stores the profiles and their metas in a BlobStore, the metas are kept in an index blob so that
+// the backends without listing such as the piece store can be used. It is safe for concurrent use.
+type Store struct {
+	mu   sync.Mutex
+	blob BlobStore
+}
+
+// NewStore returns a Store on the blob store.
+func NewStore(blob BlobStore) *Store {
+	return &Store{blob: blob}
+}
+
+// Put stores the profile data and records its meta.
+func (s *Store) Put(ctx context.Context, meta *Meta, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	metas, err := s.load(ctx)
+	if err != nil {
+		return err
+	}
+	if err = s.blob.Put(ctx, meta.Name, data); err != nil {
+		return err
+	}
+	meta.Size = int64(len(data))
+	return s.save(ctx, append(metas, meta))
+}
+
+// Get returns the data of the profile, ErrProfileNotFound is returned if the profile is not recorded.
+func (s *Store) Get(ctx context.Context, name string) ([]byte, error) {
+	s.mu.Lock()
+	metas, err := s.load(ctx)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	// only the recorded names are accepted, so the name never escapes from the store
+	for _, meta := range metas {
+		if meta.Name == name {
+			return s.blob.Get(ctx, name)
+		}
+	}
+	return nil, ErrProfileNotFound
+}
+
+// List returns the metas of the profiles from the newest to the oldest.
+func (s *Store) List(ctx context.Context) ([]*Meta, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	metas, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(metas, func(i, j int) bool {
+		return metas[i].CreatedAt.After(metas[j].CreatedAt)
+	})
+	return metas, nil
+}
+
+// Prune keeps at most maxProfiles newest profiles which are not older than maxAge, zero means no limit.
+// It returns the number of the deleted profiles.
+func (s *Store) Prune(ctx context.Context, maxProfiles int, maxAge time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	metas, err := s.load(ctx)
+	if err != nil {
+		return 0, err
+	}
+	sort.SliceStable(metas, func(i, j int) bool {
+		return metas[i].CreatedAt.After(metas[j].CreatedAt)
+	})
+	var (
+		kept    []*Meta
+		deleted int
+		now     = time.Now()
+	)
+	for i, meta := range metas {
+		if (maxProfiles > 0 && i >= maxProfiles) || (maxAge > 0 && now.Sub(meta.CreatedAt) > maxAge) {
+			if err = s.blob.Delete(ctx, meta.Name); err != nil && !errors.Is(err, os.ErrNotExist) {
+				// keep the meta to retry in the next prune
+				kept = append(kept, meta)
+				continue
+			}
+			deleted++
+			continue
+		}
+		kept = append(kept, meta)
+	}
+	if deleted == 0 {
+		return 0, nil
+	}
+	return deleted, s.save(ctx, kept)
+}
+
+func (s *Store) load(ctx context.Context) ([]*Meta, error) {
+	data, err := s.blob.Get(ctx, indexKey)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var metas []*Meta
+	if err = json.Unmarshal(data, &metas); err != nil {
+		return nil, fmt.Errorf("failed to parse profile index: %w", err)
+	}
+	return metas, nil
+}
+
+func (s *Store) save(ctx context.Context, metas []*Meta) error {
+	data, err := json.Marshal(metas)
+	if err != nil {
+		return err
+	}
+	return s.blob.Put(ctx, indexKey, data)
+}
+
+// DirStore is a BlobStore on a local directory.
+type DirStore struct {
+	dir string
+}
+
+var _ BlobStore = &DirStore{}
+
+// NewDirStore returns a DirStore on the directory, the directory is created if it does not exist.
+func NewDirStore(dir string) (*DirStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &DirStore{dir: dir}, nil
+}
+
+// Put writes to a temporary file and renames it, so that the index is never partially written.
+func (d *DirStore) Put(ctx context.Context, key string, data []byte) error {
+	path := filepath.Join(d.dir, filepath.Base(key))
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (d *DirStore) Get(ctx context.Context, key string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(d.dir, filepath.Base(key)))
+}
+
+func (d *DirStore) Delete(ctx context.Context, key string) error {
+	return os.Remove(filepath.Join(d.dir, filepath.Base(key)))
+}
+
+// PieceBlobStore is a BlobStore on the piece store, the keys are prefixed to be separated from the pieces.
+type PieceBlobStore struct {
+	pieceStore corepiecestore.PieceStore
+	prefix     string
+}
+
+var _ BlobStore = &PieceBlobStore{}
+
+// NewPieceBlobStore returns a PieceBlobStore whose keys are prefixed with the prefix.
+func NewPieceBlobStore(pieceStore corepiecestore.PieceStore, prefix string) *PieceBlobStore {
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return &PieceBlobStore{pieceStore: pieceStore, prefix: prefix}
+}
+
+func (p *PieceBlobStore) Put(ctx context.Context, key string, data []byte) error {
+	return p.pieceStore.PutPiece(ctx, p.prefix+key, data)
+}
+
+// Get maps the not found errors of the storage backends to os.ErrNotExist, so that the missing index is
+// treated as empty.
+func (p *PieceBlobStore) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := p.pieceStore.GetPiece(ctx, p.prefix+key, 0, -1)
+	if err != nil && isNotExist(err) {
+		return nil, os.ErrNotExist
+	}
+	return data, err
+}
+
+func (p *PieceBlobStore) Delete(ctx context.Context, key string) error {
+	return p.pieceStore.DeletePiece(ctx, p.prefix+key)
+}
+
+// isNotExist reports whether the error of the piece store means the key does not exist, the storage
+// backends return the errors of their sdks such as NoSuchKey and NotFound.
+func isNotExist(err error) bool {
+	if errors.Is(err, os.ErrNotExist) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "no such") || strings.Contains(msg, "not found") ||
+		strings.Contains(msg, "notfound") || strings.Contains(msg, "not exist")
+}
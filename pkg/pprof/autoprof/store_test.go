@@ -0,0 +1,86 @@
+package autoprof
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	corepiecestore "github.com/zkMeLabs/mechain-storage-provider/core/piecestore"
+)
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	dir, err := NewDirStore(t.TempDir())
+	assert.Nil(t, err)
+	store := NewStore(dir)
+
+	metas, err := store.List(ctx)
+	assert.Nil(t, err)
+	assert.Empty(t, metas)
+
+	now := time.Now()
+	for i, name := range []string{"a", "b", "c"} {
+		meta := &Meta{Name: name, Profile: ProfileHeap, CreatedAt: now.Add(time.Duration(i-2) * time.Hour)}
+		assert.Nil(t, store.Put(ctx, meta, []byte(name+name)))
+		assert.Equal(t, int64(2), meta.Size)
+	}
+	metas, err = store.List(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"c", "b", "a"}, metaNames(metas))
+
+	data, err := store.Get(ctx, "b")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("bb"), data)
+	_, err = store.Get(ctx, "../b")
+	assert.Equal(t, ErrProfileNotFound, err)
+
+	deleted, err := store.Prune(ctx, 2, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, deleted)
+	_, err = store.Get(ctx, "a")
+	assert.Equal(t, ErrProfileNotFound, err)
+	_, err = dir.Get(ctx, "a")
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+
+	deleted, err = store.Prune(ctx, 0, 30*time.Minute)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, deleted)
+	metas, err = store.List(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"c"}, metaNames(metas))
+}
+
+func TestPieceBlobStore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ps := corepiecestore.NewMockPieceStore(ctrl)
+	blob := NewPieceBlobStore(ps, "autoprof/app")
+	ctx := context.Background()
+
+	ps.EXPECT().GetPiece(gomock.Any(), "autoprof/app/index.json", int64(0), int64(-1)).
+		Return(nil, errors.New("NoSuchKey: The specified key does not exist.")).Times(1)
+	_, err := blob.Get(ctx, indexKey)
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+
+	mockErr := errors.New("mock error")
+	ps.EXPECT().GetPiece(gomock.Any(), "autoprof/app/a", int64(0), int64(-1)).Return(nil, mockErr).Times(1)
+	_, err = blob.Get(ctx, "a")
+	assert.Equal(t, mockErr, err)
+
+	ps.EXPECT().PutPiece(gomock.Any(), "autoprof/app/a", []byte("a")).Return(nil).Times(1)
+	assert.Nil(t, blob.Put(ctx, "a", []byte("a")))
+	ps.EXPECT().DeletePiece(gomock.Any(), "autoprof/app/a").Return(nil).Times(1)
+	assert.Nil(t, blob.Delete(ctx, "a"))
+}
+
+func metaNames(metas []*Meta) []string {
+	names := make([]string, 0, len(metas))
+	for _, meta := range metas {
+		names = append(names, meta.Name)
+	}
+	return names
+}
@@ -0,0 +1,252 @@
+package autoprof
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"runtime/metrics"
+	"sort"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// HeapTriggerName is the name of the trigger firing on the heap above the threshold.
+	HeapTriggerName = "heap"
+	// GoroutineTriggerName is the name of the trigger firing on the goroutine count spike.
+	GoroutineTriggerName = "goroutine"
+	// LatencyTriggerName is the name of the trigger firing on the request latency p99 regression.
+	LatencyTriggerName = "latency"
+
+	// MinGoroutineSpike is the min increase of the goroutines over the baseline to be a spike, so that a
+	// small baseline does not fire on a few goroutines.
+	MinGoroutineSpike = 100
+	// MinLatencySamples is the min number of the requests in a check interval to compute the p99.
+	MinLatencySamples = 100
+	// LatencyQuantile is the quantile of the request latency watched by the latency trigger.
+	LatencyQuantile = 0.99
+	// baselineWeight is the weight of the new value in the moving average of the baseline.
+	baselineWeight = 0.1
+)
+
+// DefaultLatencyMetrics are the histograms of the HTTP and the gRPC server latency.
+var DefaultLatencyMetrics = []string{"http_request_duration_seconds", "grpc_server_handling_seconds"}
+
+// Trigger detects an anomaly of the process.
+type Trigger interface {
+	// Name returns the name of the trigger, it is recorded in the meta of the captured profiles.
+	Name() string
+	// Check is called on every check interval, it returns the reason and true if the anomaly is detected.
+	Check() (string, bool)
+}
+
+var (
+	_ Trigger = &HeapTrigger{}
+	_ Trigger = &GoroutineTrigger{}
+	_ Trigger = &LatencyTrigger{}
+)
+
+// HeapTrigger fires if the bytes of the heap objects are above the threshold.
+type HeapTrigger struct {
+	threshold uint64
+	heapBytes func() uint64
+}
+
+// NewHeapTrigger returns a HeapTrigger with the threshold in bytes.
+func NewHeapTrigger(threshold uint64) *HeapTrigger {
+	return &HeapTrigger{threshold: threshold, heapBytes: heapObjectBytes}
+}
+
+func (t *HeapTrigger) Name() string {
+	return HeapTriggerName
+}
+
+func (t *HeapTrigger) Check() (string, bool) {
+	heap := t.heapBytes()
+	if heap < t.threshold {
+		return "", false
+	}
+	return fmt.Sprintf("heap %d MB is above the threshold %d MB", heap>>20, t.threshold>>20), true
+}
+
+// heapObjectBytes reads the heap from runtime/metrics, which does not stop the world as ReadMemStats.
+func heapObjectBytes() uint64 {
+	samples := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	metrics.Read(samples)
+	if samples[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return samples[0].Value.Uint64()
+}
+
+// GoroutineTrigger fires if the goroutine count reaches the threshold, or spikes to spikeRatio times of
+// the baseline, which is the moving average of the counts not fired.
+type GoroutineTrigger struct {
+	threshold  int
+	spikeRatio float64
+	baseline   float64
+	count      func() int
+}
+
+// NewGoroutineTrigger returns a GoroutineTrigger, zero threshold or spikeRatio disables the check.
+func NewGoroutineTrigger(threshold int, spikeRatio float64) *GoroutineTrigger {
+	return &GoroutineTrigger{threshold: threshold, spikeRatio: spikeRatio, count: runtime.NumGoroutine}
+}
+
+func (t *GoroutineTrigger) Name() string {
+	return GoroutineTriggerName
+}
+
+func (t *GoroutineTrigger) Check() (string, bool) {
+	count := t.count()
+	if t.threshold > 0 && count >= t.threshold {
+		return fmt.Sprintf("goroutine count %d reaches the threshold %d", count, t.threshold), true
+	}
+	if t.spikeRatio > 0 && t.baseline > 0 && float64(count) >= t.baseline*t.spikeRatio &&
+		float64(count)-t.baseline >= MinGoroutineSpike {
+		return fmt.Sprintf("goroutine count %d spikes from the baseline %.0f", count, t.baseline), true
+	}
+	t.baseline = movingAverage(t.baseline, float64(count))
+	return "", false
+}
+
+// LatencyTrigger fires if the p99 of the request latency in the check interval is above the threshold, or
+// regresses to ratio times of the baseline, which is the moving average of the p99s not fired. The p99 is
+// computed from the buckets of the latency histograms in the gatherer, the series of all the labels are
+// merged.
+type LatencyTrigger struct {
+	gatherer  prometheus.Gatherer
+	names     map[string]struct{}
+	threshold time.Duration
+	ratio     float64
+
+	baseline float64
+	last     *histogram
+}
+
+// NewLatencyTrigger returns a LatencyTrigger on the histograms of the names, zero threshold or ratio
+// disables the check.
+func NewLatencyTrigger(gatherer prometheus.Gatherer, names []string, threshold time.Duration, ratio float64) *LatencyTrigger {
+	t := &LatencyTrigger{
+		gatherer:  gatherer,
+		names:     make(map[string]struct{}, len(names)),
+		threshold: threshold,
+		ratio:     ratio,
+	}
+	for _, name := range names {
+		t.names[name] = struct{}{}
+	}
+	return t
+}
+
+func (t *LatencyTrigger) Name() string {
+	return LatencyTriggerName
+}
+
+func (t *LatencyTrigger) Check() (string, bool) {
+	current, err := t.gather()
+	if err != nil {
+		return "", false
+	}
+	last := t.last
+	t.last = current
+	if last == nil {
+		return "", false
+	}
+	window := current.sub(last)
+	if window.count < MinLatencySamples {
+		return "", false
+	}
+	p99 := window.quantile(LatencyQuantile)
+	if t.threshold > 0 && p99 > t.threshold.Seconds() {
+		return fmt.Sprintf("latency p99 %.3fs is above the threshold %.3fs", p99, t.threshold.Seconds()), true
+	}
+	if t.ratio > 0 && t.baseline > 0 && p99 > t.baseline*t.ratio {
+		return fmt.Sprintf("latency p99 %.3fs regresses from the baseline %.3fs", p99, t.baseline), true
+	}
+	t.baseline = movingAverage(t.baseline, p99)
+	return "", false
+}
+
+func (t *LatencyTrigger) gather() (*histogram, error) {
+	families, err := t.gatherer.Gather()
+	if err != nil {
+		return nil, err
+	}
+	h := &histogram{buckets: make(map[float64]uint64)}
+	for _, family := range families {
+		if _, ok := t.names[family.GetName()]; !ok {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			if metric.GetHistogram() == nil {
+				continue
+			}
+			h.count += metric.GetHistogram().GetSampleCount()
+			for _, bucket := range metric.GetHistogram().GetBucket() {
+				h.buckets[bucket.GetUpperBound()] += bucket.GetCumulativeCount()
+			}
+		}
+	}
+	return h, nil
+}
+
+// histogram is the merged cumulative buckets keyed by the upper bound, the +Inf bucket is the count.
+type histogram struct {
+	count   uint64
+	buckets map[float64]uint64
+}
+
+// sub returns the observations between the last and h, h is returned if the counters are reset.
+func (h *histogram) sub(last *histogram) *histogram {
+	if h.count < last.count {
+		return h
+	}
+	window := &histogram{count: h.count - last.count, buckets: make(map[float64]uint64, len(h.buckets))}
+	for bound, count := range h.buckets {
+		if count < last.buckets[bound] {
+			return h
+		}
+		window.buckets[bound] = count - last.buckets[bound]
+	}
+	return window
+}
+
+// quantile estimates the quantile by the linear interpolation in the bucket as histogram_quantile of
+// prometheus, the highest finite bound is returned if the quantile falls in the +Inf bucket.
+func (h *histogram) quantile(q float64) float64 {
+	if h.count == 0 {
+		return 0
+	}
+	bounds := make([]float64, 0, len(h.buckets))
+	for bound := range h.buckets {
+		if !math.IsInf(bound, 1) {
+			bounds = append(bounds, bound)
+		}
+	}
+	if len(bounds) == 0 {
+		return 0
+	}
+	sort.Float64s(bounds)
+	rank := q * float64(h.count)
+	var lowerBound, lowerCount float64
+	for _, bound := range bounds {
+		count := float64(h.buckets[bound])
+		if count >= rank {
+			if count == lowerCount {
+				return bound
+			}
+			return lowerBound + (bound-lowerBound)*(rank-lowerCount)/(count-lowerCount)
+		}
+		lowerBound, lowerCount = bound, count
+	}
+	return bounds[len(bounds)-1]
+}
+
+func movingAverage(average, value float64) float64 {
+	if average == 0 {
+		return value
+	}
+	return average*(1-baselineWeight) + value*baselineWeight
+}
@@ -0,0 +1,139 @@
+package autoprof
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeapTrigger(t *testing.T) {
+	trigger := NewHeapTrigger(100 << 20)
+	assert.Equal(t, HeapTriggerName, trigger.Name())
+	assert.NotZero(t, trigger.heapBytes())
+
+	trigger.heapBytes = func() uint64 { return 99 << 20 }
+	_, fired := trigger.Check()
+	assert.False(t, fired)
+	trigger.heapBytes = func() uint64 { return 100 << 20 }
+	reason, fired := trigger.Check()
+	assert.True(t, fired)
+	assert.Equal(t, "heap 100 MB is above the threshold 100 MB", reason)
+}
+
+func TestGoroutineTrigger(t *testing.T) {
+	cases := []struct {
+		name      string
+		threshold int
+		ratio     float64
+		counts    []int
+		fired     []bool
+	}{
+		{
+			name:      "threshold",
+			threshold: 1000,
+			counts:    []int{10, 999, 1000},
+			fired:     []bool{false, false, true},
+		},
+		{
+			name:   "spike",
+			ratio:  2,
+			counts: []int{200, 200, 399, 500},
+			fired:  []bool{false, false, false, true},
+		},
+		{
+			name:   "small spike is ignored",
+			ratio:  2,
+			counts: []int{10, 50},
+			fired:  []bool{false, false},
+		},
+		{
+			name:   "disabled",
+			counts: []int{10, 100000},
+			fired:  []bool{false, false},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			trigger := NewGoroutineTrigger(tt.threshold, tt.ratio)
+			for i, count := range tt.counts {
+				trigger.count = func() int { return count }
+				_, fired := trigger.Check()
+				assert.Equal(t, tt.fired[i], fired, "check %d", i)
+			}
+		})
+	}
+}
+
+func TestLatencyTrigger(t *testing.T) {
+	newTrigger := func(threshold time.Duration, ratio float64) (*LatencyTrigger, *prometheus.HistogramVec) {
+		registry := prometheus.NewRegistry()
+		histogram := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Buckets: []float64{0.1, 0.5, 1, 5},
+		}, []string{"handler_name"})
+		registry.MustRegister(histogram)
+		return NewLatencyTrigger(registry, DefaultLatencyMetrics, threshold, ratio), histogram
+	}
+	observe := func(histogram *prometheus.HistogramVec, n int, latency float64) {
+		for i := 0; i < n; i++ {
+			histogram.WithLabelValues([]string{"get", "put"}[i%2]).Observe(latency)
+		}
+	}
+
+	t.Run("threshold", func(t *testing.T) {
+		trigger, histogram := newTrigger(time.Second, 0)
+		assert.Equal(t, LatencyTriggerName, trigger.Name())
+		_, fired := trigger.Check()
+		assert.False(t, fired)
+
+		observe(histogram, MinLatencySamples, 0.05)
+		_, fired = trigger.Check()
+		assert.False(t, fired)
+
+		observe(histogram, MinLatencySamples-1, 2)
+		_, fired = trigger.Check()
+		assert.False(t, fired)
+
+		observe(histogram, MinLatencySamples, 2)
+		reason, fired := trigger.Check()
+		assert.True(t, fired)
+		assert.Contains(t, reason, "above the threshold 1.000s")
+	})
+
+	t.Run("regression", func(t *testing.T) {
+		trigger, histogram := newTrigger(0, 6)
+		trigger.Check()
+		observe(histogram, MinLatencySamples, 0.05)
+		_, fired := trigger.Check()
+		assert.False(t, fired)
+		assert.InDelta(t, 0.099, trigger.baseline, 0.001)
+
+		observe(histogram, MinLatencySamples, 0.2)
+		_, fired = trigger.Check()
+		assert.False(t, fired)
+
+		observe(histogram, MinLatencySamples, 10)
+		reason, fired := trigger.Check()
+		assert.True(t, fired)
+		assert.Contains(t, reason, "regresses from the baseline")
+	})
+}
+
+func TestHistogramQuantile(t *testing.T) {
+	h := &histogram{count: 100, buckets: map[float64]uint64{0.1: 50, 1: 90, 10: 100}}
+	assert.InDelta(t, 0.1, h.quantile(0.5), 1e-9)
+	assert.InDelta(t, 5.5, h.quantile(0.95), 1e-9)
+
+	// the quantile falls in the +Inf bucket
+	h = &histogram{count: 100, buckets: map[float64]uint64{0.1: 50, 1: 90}}
+	assert.Equal(t, 1.0, h.quantile(0.99))
+
+	assert.Equal(t, 0.0, (&histogram{}).quantile(0.99))
+
+	// the counters are reset
+	last := &histogram{count: 100, buckets: map[float64]uint64{0.1: 100}}
+	current := &histogram{count: 10, buckets: map[float64]uint64{0.1: 10}}
+	assert.Equal(t, current, current.sub(last))
+}
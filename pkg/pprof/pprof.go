@@ -13,6 +13,7 @@ import (
 	coremodule "github.com/zkMeLabs/mechain-storage-provider/core/module"
 	corercmgr "github.com/zkMeLabs/mechain-storage-provider/core/rcmgr"
 	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/pprof/autoprof"
 )
 
 var PProfModularName = strings.ToLower("PProf")
@@ -22,6 +23,7 @@ var _ coremodule.Modular = &PProf{}
 type PProf struct {
 	httpAddress string
 	httpServer  *http.Server
+	profiler    *autoprof.Profiler
 }
 
 // NewPProf returns an instance of pprof
@@ -29,6 +31,12 @@ func NewPProf(address string) *PProf {
 	return &PProf{httpAddress: address}
 }
 
+// SetAutoProfiler sets the profiler capturing the profiles on the anomalies, it is started and stopped
+// with the pprof service, and the captured profiles are served by the pprof HTTP server.
+func (p *PProf) SetAutoProfiler(profiler *autoprof.Profiler) {
+	p.profiler = profiler
+}
+
 // Name describes pprof service name
 func (p *PProf) Name() string {
 	return PProfModularName
@@ -36,6 +44,9 @@ func (p *PProf) Name() string {
 
 // Start HTTP server
 func (p *PProf) Start(ctx context.Context) error {
+	if p.profiler != nil {
+		p.profiler.Start()
+	}
 	go p.serve()
 	return nil
 }
@@ -43,6 +54,9 @@ func (p *PProf) Start(ctx context.Context) error {
 // Stop HTTP server
 func (p *PProf) Stop(ctx context.Context) error {
 	var errs []error
+	if p.profiler != nil {
+		p.profiler.Stop()
+	}
 	if err := p.httpServer.Shutdown(ctx); err != nil {
 		errs = append(errs, err)
 	}
@@ -88,4 +102,13 @@ func (p *PProf) registerProfiler(r *mux.Router) {
 	r.Handle("/debug/pprof/threadcreate", pprof.Handler("threadcreate"))
 	r.Handle("/debug/pprof/block", pprof.Handler("block"))
 	r.Handle("/debug/pprof/mutex", pprof.Handler("mutex"))
+
+	// change the global and the module log levels at runtime
+	r.HandleFunc("/debug/log/level", log.LevelHandler())
+
+	// list and fetch the profiles captured on the anomalies
+	if p.profiler != nil {
+		r.HandleFunc(autoprof.ListPath, autoprof.ListHandler(p.profiler.Store()))
+		r.HandleFunc(autoprof.FetchPath, autoprof.FetchHandler(p.profiler.Store()))
+	}
 }
@@ -0,0 +1,200 @@
+package probe
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+)
+
+const (
+	// StatusUp indicates the dependency is available.
+	StatusUp = "up"
+	// StatusDown indicates the dependency is unavailable or the check is timeout.
+	StatusDown = "down"
+
+	// DefaultHealthCheckInterval defines the default interval of running the dependency checks.
+	DefaultHealthCheckInterval = 10 * time.Second
+	// DefaultHealthCheckTimeout defines the default timeout of each dependency check.
+	DefaultHealthCheckTimeout = 3 * time.Second
+)
+
+// CheckFunc checks a dependency, it returns the status detail such as the latest block height on success.
+type CheckFunc func(ctx context.Context) (detail string, err error)
+
+// Check is a dependency check of the sp.
+type Check struct {
+	Name string
+	// Timeout is the timeout of the check, DefaultHealthCheckTimeout is used if it is zero.
+	Timeout time.Duration
+	// Critical makes the sp unready if the check fails, the failures of the others are only reported.
+	Critical bool
+	Func     CheckFunc
+}
+
+// HealthCheckProvider is implemented by the modules which have their own dependency checks, the checks are
+// registered to the probe of the process on the start time.
+type HealthCheckProvider interface {
+	HealthChecks() []Check
+}
+
+// CheckResult is the latest result of a dependency check.
+type CheckResult struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Critical  bool   `json:"critical"`
+	Detail    string `json:"detail,omitempty"`
+	Error     string `json:"error,omitempty"`
+	LatencyMs int64  `json:"latency_ms"`
+}
+
+// HealthReport is the response of the health endpoint.
+type HealthReport struct {
+	Status    string        `json:"status"`
+	Healthy   bool          `json:"healthy"`
+	Ready     bool          `json:"ready"`
+	CheckedAt time.Time     `json:"checked_at"`
+	Checks    []CheckResult `json:"checks"`
+}
+
+// HealthChecker runs the dependency checks periodically and caches their latest results, so that the
+// readiness probes are answered without touching the dependencies.
+type HealthChecker struct {
+	interval time.Duration
+
+	mu        sync.RWMutex
+	checks    []Check
+	results   []CheckResult
+	checkedAt time.Time
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+}
+
+// NewHealthChecker returns a HealthChecker running the checks every interval.
+func NewHealthChecker(interval time.Duration) *HealthChecker {
+	if interval <= 0 {
+		interval = DefaultHealthCheckInterval
+	}
+	return &HealthChecker{interval: interval, stopCh: make(chan struct{})}
+}
+
+// Register adds the checks, they are run from the next round.
+func (h *HealthChecker) Register(checks ...Check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks = append(h.checks, checks...)
+}
+
+// Start runs the checks immediately and then every interval until Stop is called.
+func (h *HealthChecker) Start() {
+	go func() {
+		ticker := time.NewTicker(h.interval)
+		defer ticker.Stop()
+		for {
+			h.RunChecks(context.Background())
+			select {
+			case <-ticker.C:
+			case <-h.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops running the checks.
+func (h *HealthChecker) Stop() {
+	h.stopOnce.Do(func() { close(h.stopCh) })
+}
+
+// RunChecks runs all the checks concurrently, each with its own timeout, and caches the results.
+func (h *HealthChecker) RunChecks(ctx context.Context) {
+	h.mu.RLock()
+	checks := append([]Check(nil), h.checks...)
+	h.mu.RUnlock()
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i := range checks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = runCheck(ctx, checks[i])
+		}(i)
+	}
+	wg.Wait()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, result := range results {
+		if result.Status == StatusDown && (len(h.results) <= i || h.results[i].Status != StatusDown) {
+			log.Warnw("dependency check failed", "check", result.Name, "critical", result.Critical, "error", result.Error)
+		}
+	}
+	h.results = results
+	h.checkedAt = time.Now()
+}
+
+// Ready returns whether all the critical checks passed in the latest round, it is false before the first
+// round is finished unless there is no check.
+func (h *HealthChecker) Ready() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if len(h.checks) == 0 {
+		return true
+	}
+	if h.checkedAt.IsZero() {
+		return false
+	}
+	for _, result := range h.results {
+		if result.Critical && result.Status != StatusUp {
+			return false
+		}
+	}
+	return true
+}
+
+// Results returns the latest results and the time they are checked.
+func (h *HealthChecker) Results() ([]CheckResult, time.Time) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return append([]CheckResult(nil), h.results...), h.checkedAt
+}
+
+func runCheck(ctx context.Context, check Check) CheckResult {
+	timeout := check.Timeout
+	if timeout <= 0 {
+		timeout = DefaultHealthCheckTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	result := CheckResult{Name: check.Name, Critical: check.Critical}
+	startTime := time.Now()
+	// the check may ignore the context, it is abandoned after the timeout
+	type output struct {
+		detail string
+		err    error
+	}
+	ch := make(chan output, 1)
+	go func() {
+		detail, err := check.Func(ctx)
+		ch <- output{detail: detail, err: err}
+	}()
+	select {
+	case out := <-ch:
+		result.Detail = out.detail
+		if out.err != nil {
+			result.Status = StatusDown
+			result.Error = out.err.Error()
+		} else {
+			result.Status = StatusUp
+		}
+	case <-ctx.Done():
+		result.Status = StatusDown
+		result.Error = "check timeout after " + timeout.String()
+	}
+	result.LatencyMs = time.Since(startTime).Milliseconds()
+	return result
+}
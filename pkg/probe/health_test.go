@@ -0,0 +1,120 @@
+package probe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthChecker_RunChecks(t *testing.T) {
+	h := NewHealthChecker(0)
+	assert.Equal(t, DefaultHealthCheckInterval, h.interval)
+	// no check is registered
+	assert.True(t, h.Ready())
+
+	h.Register(Check{
+		Name:     "up",
+		Critical: true,
+		Func:     func(ctx context.Context) (string, error) { return "height 1", nil },
+	}, Check{
+		Name: "non_critical",
+		Func: func(ctx context.Context) (string, error) { return "", errors.New("mock error") },
+	})
+	// the checks have not been run yet
+	assert.False(t, h.Ready())
+	h.RunChecks(context.Background())
+	assert.True(t, h.Ready())
+	results, checkedAt := h.Results()
+	assert.False(t, checkedAt.IsZero())
+	assert.Equal(t, 2, len(results))
+	assert.Equal(t, CheckResult{Name: "up", Status: StatusUp, Critical: true, Detail: "height 1", LatencyMs: results[0].LatencyMs}, results[0])
+	assert.Equal(t, StatusDown, results[1].Status)
+	assert.Equal(t, "mock error", results[1].Error)
+
+	// the check ignoring the context is abandoned after the timeout
+	h.Register(Check{
+		Name:     "timeout",
+		Timeout:  10 * time.Millisecond,
+		Critical: true,
+		Func: func(ctx context.Context) (string, error) {
+			time.Sleep(time.Second)
+			return "", nil
+		},
+	})
+	startTime := time.Now()
+	h.RunChecks(context.Background())
+	assert.Less(t, time.Since(startTime), time.Second)
+	assert.False(t, h.Ready())
+	results, _ = h.Results()
+	assert.Equal(t, StatusDown, results[2].Status)
+	assert.Equal(t, "check timeout after 10ms", results[2].Error)
+}
+
+func TestHealthChecker_StartStop(t *testing.T) {
+	h := NewHealthChecker(10 * time.Millisecond)
+	count := make(chan struct{}, 10)
+	h.Register(Check{Name: "mock", Func: func(ctx context.Context) (string, error) {
+		select {
+		case count <- struct{}{}:
+		default:
+		}
+		return "", nil
+	}})
+	h.Start()
+	for i := 0; i < 2; i++ {
+		select {
+		case <-count:
+		case <-time.After(time.Second):
+			t.Fatal("checks are not run periodically")
+		}
+	}
+	h.Stop()
+	h.Stop()
+}
+
+func TestHTTPProbe_HealthHandler(t *testing.T) {
+	p := NewHTTPProbe()
+	p.Healthy()
+	p.Ready()
+	assert.True(t, p.IsReady())
+
+	h := NewHealthChecker(time.Second)
+	h.Register(Check{Name: "piece_store", Critical: true, Func: func(ctx context.Context) (string, error) {
+		return "", errors.New("no such bucket")
+	}})
+	p.SetHealthChecker(h)
+	assert.Equal(t, h, p.HealthChecker())
+	h.RunChecks(context.Background())
+	assert.False(t, p.IsReady())
+
+	w := httptest.NewRecorder()
+	p.HealthHandler()(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	report := &HealthReport{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), report))
+	assert.Equal(t, StatusDown, report.Status)
+	assert.True(t, report.Healthy)
+	assert.False(t, report.Ready)
+	assert.Equal(t, 1, len(report.Checks))
+	assert.Equal(t, "no such bucket", report.Checks[0].Error)
+
+	w = httptest.NewRecorder()
+	p.ReadyHandler()(w, httptest.NewRequest(http.MethodGet, "/-/ready", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	p.SetHealthChecker(nil)
+	w = httptest.NewRecorder()
+	p.HealthHandler()(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	report = &HealthReport{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), report))
+	assert.Equal(t, StatusUp, report.Status)
+	assert.Equal(t, 0, len(report.Checks))
+}
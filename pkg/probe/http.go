@@ -1,6 +1,7 @@
 package probe
 
 import (
+	"encoding/json"
 	"io"
 	"net/http"
 	"sync/atomic"
@@ -14,6 +15,8 @@ type check func() bool
 type HTTPProbe struct {
 	ready   atomic.Uint32
 	healthy atomic.Uint32
+	// health is the optional dependency checker, the component is unready if any critical check fails.
+	health *HealthChecker
 }
 
 // NewHTTPProbe returns HTTPProbe representing readiness and liveness of given component.
@@ -31,6 +34,48 @@ func (p *HTTPProbe) ReadyHandler() http.HandlerFunc {
 	return p.handler(p.IsReady)
 }
 
+// SetHealthChecker sets the dependency checker which gates the readiness and serves the health report.
+func (p *HTTPProbe) SetHealthChecker(health *HealthChecker) {
+	p.health = health
+}
+
+// HealthChecker returns the dependency checker, it is nil if not set.
+func (p *HTTPProbe) HealthChecker() *HealthChecker {
+	return p.health
+}
+
+// HealthHandler returns an HTTP handler which responds the JSON health report including the status detail of
+// each dependency check, the status code is 503 if the component is not ready.
+func (p *HTTPProbe) HealthHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		report := p.HealthReport()
+		w.Header().Set("Content-Type", "application/json")
+		if !report.Ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		if err := json.NewEncoder(w).Encode(report); err != nil {
+			log.Errorw("failed to write health response", "error", err)
+		}
+	}
+}
+
+// HealthReport returns the health and readiness status with the latest results of the dependency checks.
+func (p *HTTPProbe) HealthReport() *HealthReport {
+	report := &HealthReport{
+		Healthy: p.IsHealthy(),
+		Ready:   p.IsReady(),
+		Checks:  []CheckResult{},
+	}
+	if p.health != nil {
+		report.Checks, report.CheckedAt = p.health.Results()
+	}
+	report.Status = StatusUp
+	if !report.Ready {
+		report.Status = StatusDown
+	}
+	return report
+}
+
 func (p *HTTPProbe) handler(c check) http.HandlerFunc {
 	return func(w http.ResponseWriter, _ *http.Request) {
 		if !c() {
@@ -43,10 +88,13 @@ func (p *HTTPProbe) handler(c check) http.HandlerFunc {
 	}
 }
 
-// IsReady returns true if component is ready.
+// IsReady returns true if component is ready and all the critical dependency checks passed.
 func (p *HTTPProbe) IsReady() bool {
 	value := p.ready.Load()
-	return value > 0
+	if value == 0 {
+		return false
+	}
+	return p.health == nil || p.health.Ready()
 }
 
 // IsHealthy returns true if component is healthy.
@@ -36,14 +36,20 @@ func (p *Probe) Name() string {
 	return ProbeModularName
 }
 
-// Start HTTP server
+// Start HTTP server and the dependency checks
 func (p *Probe) Start(ctx context.Context) error {
+	if health := p.httpProbe.HealthChecker(); health != nil {
+		health.Start()
+	}
 	go p.serve()
 	return nil
 }
 
-// Stop HTTP server
+// Stop HTTP server and the dependency checks
 func (p *Probe) Stop(ctx context.Context) error {
+	if health := p.httpProbe.HealthChecker(); health != nil {
+		health.Stop()
+	}
 	if err := p.httpServer.Shutdown(ctx); err != nil {
 		log.Errorw("failed to shutdown http server", "error", err)
 		return err
@@ -75,4 +81,5 @@ func (p *Probe) ReleaseResource(ctx context.Context, scope corercmgr.ResourceSco
 func (p *Probe) registerProbes(r *mux.Router, h *HTTPProbe) {
 	r.HandleFunc("/-/healthy", h.HealthyHandler())
 	r.HandleFunc("/-/ready", h.ReadyHandler())
+	r.HandleFunc("/health", h.HealthHandler())
 }
@@ -0,0 +1,92 @@
+package thumbnail
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+)
+
+// DefaultCacheSize defines the default max bytes of the cached previews.
+const DefaultCacheSize = 256 * 1024 * 1024
+
+// Entry is a rendered preview.
+type Entry struct {
+	Data        []byte
+	ContentType string
+}
+
+type cacheItem struct {
+	key   string
+	entry *Entry
+}
+
+// Cache is a LRU cache of the rendered previews limited by the total bytes. The key contains the object id
+// and version, so the preview of the updated object is never served.
+type Cache struct {
+	maxSize int64
+	mu      sync.Mutex
+	size    int64
+	items   map[string]*list.Element
+	lru     *list.List
+}
+
+// NewCache returns a Cache, the zero maxSize is replaced by the default.
+func NewCache(maxSize int64) *Cache {
+	if maxSize == 0 {
+		maxSize = DefaultCacheSize
+	}
+	return &Cache{
+		maxSize: maxSize,
+		items:   make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// Key returns the cache key of the preview of the object.
+func Key(objectID uint64, version int64, opts *Options) string {
+	return fmt.Sprintf("%d/%d/%s", objectID, version, opts.String())
+}
+
+// Get returns the cached preview.
+func (c *Cache) Get(key string) (*Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*cacheItem).entry, true
+}
+
+// Add caches the preview, the least recently used previews are evicted if the cache is full. The preview
+// larger than the cache is ignored.
+func (c *Cache) Add(key string, entry *Entry) {
+	entrySize := int64(len(entry.Data))
+	if entrySize > c.maxSize {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.items[key]; ok {
+		c.removeLocked(elem)
+	}
+	for c.size+entrySize > c.maxSize {
+		c.removeLocked(c.lru.Back())
+	}
+	c.items[key] = c.lru.PushFront(&cacheItem{key: key, entry: entry})
+	c.size += entrySize
+}
+
+// Size returns the total bytes of the cached previews.
+func (c *Cache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+func (c *Cache) removeLocked(elem *list.Element) {
+	item := c.lru.Remove(elem).(*cacheItem)
+	delete(c.items, item.key)
+	c.size -= int64(len(item.entry.Data))
+}
@@ -0,0 +1,37 @@
+package thumbnail
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	c := NewCache(10)
+	opts := &Options{Width: 100, Fit: FitContain}
+	assert.Equal(t, "1/2/w=100,h=0,fit=contain,format=", Key(1, 2, opts))
+
+	c.Add("a", &Entry{Data: make([]byte, 4), ContentType: "image/png"})
+	c.Add("b", &Entry{Data: make([]byte, 4)})
+	entry, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, "image/png", entry.ContentType)
+
+	// b is the least recently used
+	c.Add("c", &Entry{Data: make([]byte, 4)})
+	_, ok = c.Get("b")
+	assert.False(t, ok)
+	_, ok = c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, int64(8), c.Size())
+
+	// replace the entry
+	c.Add("a", &Entry{Data: make([]byte, 2)})
+	assert.Equal(t, int64(6), c.Size())
+
+	// the entry larger than the cache is ignored
+	c.Add("d", &Entry{Data: make([]byte, 11)})
+	_, ok = c.Get("d")
+	assert.False(t, ok)
+	assert.Equal(t, int64(6), c.Size())
+}
@@ -0,0 +1,234 @@
+package thumbnail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	// WidthQuery defines the query of the max width of the preview.
+	WidthQuery = "w"
+	// HeightQuery defines the query of the max height of the preview.
+	HeightQuery = "h"
+	// FitQuery defines the query of how the image is fitted into the width and the height.
+	FitQuery = "fit"
+	// FormatQuery defines the query of the encoding format of the preview.
+	FormatQuery = "format"
+
+	// FitContain scales the image to fit inside the width and the height, the aspect ratio is kept.
+	FitContain = "contain"
+	// FitCover scales the image to cover the width and the height, the overflow is cropped around the center.
+	FitCover = "cover"
+	// FitFill stretches the image to the width and the height.
+	FitFill = "fill"
+
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+	FormatGIF  = "gif"
+
+	// DefaultJPEGQuality defines the quality of the encoded jpeg preview.
+	DefaultJPEGQuality = 85
+)
+
+var (
+	// ErrInvalidOptions is returned if the preview query is invalid.
+	ErrInvalidOptions = errors.New("invalid thumbnail options")
+	// ErrUnsupportedImage is returned if the object is not a jpeg, png or gif image.
+	ErrUnsupportedImage = errors.New("unsupported image format")
+	// ErrImageTooLarge is returned if the pixels of the object exceed the limit.
+	ErrImageTooLarge = errors.New("image is too large")
+)
+
+var contentTypes = map[string]string{
+	FormatJPEG: "image/jpeg",
+	FormatPNG:  "image/png",
+	FormatGIF:  "image/gif",
+}
+
+// Options defines the preview of the image. The zero Width or Height means it is derived from the other one
+// by the aspect ratio, and the empty Format means the format of the source image.
+type Options struct {
+	Width  int
+	Height int
+	Fit    string
+	Format string
+}
+
+// String returns the canonical form of the options, it is used as a part of the cache key.
+func (o *Options) String() string {
+	return fmt.Sprintf("w=%d,h=%d,fit=%s,format=%s", o.Width, o.Height, o.Fit, o.Format)
+}
+
+// ParseOptions parses the preview options from the query, it returns nil if the query has no preview
+// parameter. The width and the height must be in (0, maxDimension].
+func ParseOptions(values url.Values, maxDimension int) (*Options, error) {
+	if !values.Has(WidthQuery) && !values.Has(HeightQuery) && !values.Has(FitQuery) && !values.Has(FormatQuery) {
+		return nil, nil
+	}
+	opts := &Options{Fit: FitContain}
+	var err error
+	if opts.Width, err = parseDimension(values, WidthQuery, maxDimension); err != nil {
+		return nil, err
+	}
+	if opts.Height, err = parseDimension(values, HeightQuery, maxDimension); err != nil {
+		return nil, err
+	}
+	if fit := strings.ToLower(values.Get(FitQuery)); fit != "" {
+		if fit != FitContain && fit != FitCover && fit != FitFill {
+			return nil, fmt.Errorf("%w: unsupported fit %s", ErrInvalidOptions, fit)
+		}
+		opts.Fit = fit
+	}
+	if format := strings.ToLower(values.Get(FormatQuery)); format != "" {
+		if format == "jpg" {
+			format = FormatJPEG
+		}
+		if _, ok := contentTypes[format]; !ok {
+			return nil, fmt.Errorf("%w: unsupported format %s", ErrInvalidOptions, format)
+		}
+		opts.Format = format
+	}
+	if opts.Fit != FitContain && (opts.Width == 0 || opts.Height == 0) {
+		return nil, fmt.Errorf("%w: fit %s requires both width and height", ErrInvalidOptions, opts.Fit)
+	}
+	return opts, nil
+}
+
+func parseDimension(values url.Values, key string, maxDimension int) (int, error) {
+	value := values.Get(key)
+	if value == "" {
+		return 0, nil
+	}
+	dimension, err := strconv.Atoi(value)
+	if err != nil || dimension <= 0 || dimension > maxDimension {
+		return 0, fmt.Errorf("%w: %s must be in [1, %d]", ErrInvalidOptions, key, maxDimension)
+	}
+	return dimension, nil
+}
+
+// Render decodes the jpeg, png or gif image and encodes the preview, it returns the preview and its content
+// type. The image is never enlarged, and only the first frame of the animated gif is rendered. The pixels of
+// the image are checked by maxPixels before decoding.
+func Render(data []byte, opts *Options, maxPixels int) ([]byte, string, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %s", ErrUnsupportedImage, err)
+	}
+	if _, ok := contentTypes[format]; !ok {
+		return nil, "", fmt.Errorf("%w: %s", ErrUnsupportedImage, format)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxPixels {
+		return nil, "", fmt.Errorf("%w: %dx%d", ErrImageTooLarge, config.Width, config.Height)
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %s", ErrUnsupportedImage, err)
+	}
+
+	crop, width, height := layout(src.Bounds(), opts)
+	dst := resize(src, crop, width, height)
+
+	if opts.Format != "" {
+		format = opts.Format
+	}
+	buf := &bytes.Buffer{}
+	switch format {
+	case FormatJPEG:
+		// jpeg has no alpha channel, the transparent pixels are rendered on white
+		opaque := image.NewRGBA(dst.Bounds())
+		draw.Draw(opaque, opaque.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+		draw.Draw(opaque, opaque.Bounds(), dst, image.Point{}, draw.Over)
+		err = jpeg.Encode(buf, opaque, &jpeg.Options{Quality: DefaultJPEGQuality})
+	case FormatPNG:
+		err = png.Encode(buf, dst)
+	case FormatGIF:
+		err = gif.Encode(buf, dst, nil)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), contentTypes[format], nil
+}
+
+// layout returns the region of the source which is rendered and the size of the preview.
+func layout(bounds image.Rectangle, opts *Options) (image.Rectangle, int, int) {
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+	width, height := opts.Width, opts.Height
+	switch {
+	case width == 0 && height == 0:
+		return bounds, srcWidth, srcHeight
+	case opts.Fit == FitFill:
+		return bounds, min(width, srcWidth), min(height, srcHeight)
+	case opts.Fit == FitCover:
+		// crop the largest region with the aspect ratio of the preview around the center
+		crop := bounds
+		if srcWidth*height > srcHeight*width {
+			cropWidth := max(srcHeight*width/height, 1)
+			crop.Min.X += (srcWidth - cropWidth) / 2
+			crop.Max.X = crop.Min.X + cropWidth
+		} else {
+			cropHeight := max(srcWidth*height/width, 1)
+			crop.Min.Y += (srcHeight - cropHeight) / 2
+			crop.Max.Y = crop.Min.Y + cropHeight
+		}
+		if width > crop.Dx() || height > crop.Dy() {
+			return crop, crop.Dx(), crop.Dy()
+		}
+		return crop, width, height
+	}
+	// contain, the zero width or height is unlimited
+	scale := 1.0
+	if width > 0 {
+		scale = min(scale, float64(width)/float64(srcWidth))
+	}
+	if height > 0 {
+		scale = min(scale, float64(height)/float64(srcHeight))
+	}
+	return bounds, max(int(float64(srcWidth)*scale+0.5), 1), max(int(float64(srcHeight)*scale+0.5), 1)
+}
+
+// resize scales the region of the source to the size by averaging the source pixels covered by each
+// preview pixel, the size must not be larger than the region.
+func resize(src image.Image, region image.Rectangle, width, height int) *image.RGBA {
+	rgba := image.NewRGBA(image.Rect(0, 0, region.Dx(), region.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), src, region.Min, draw.Src)
+	if width == region.Dx() && height == region.Dy() {
+		return rgba
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	srcWidth, srcHeight := region.Dx(), region.Dy()
+	for y := 0; y < height; y++ {
+		y0, y1 := y*srcHeight/height, max((y+1)*srcHeight/height, y*srcHeight/height+1)
+		for x := 0; x < width; x++ {
+			x0, x1 := x*srcWidth/width, max((x+1)*srcWidth/width, x*srcWidth/width+1)
+			var r, g, b, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				offset := rgba.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					r += uint32(rgba.Pix[offset])
+					g += uint32(rgba.Pix[offset+1])
+					b += uint32(rgba.Pix[offset+2])
+					a += uint32(rgba.Pix[offset+3])
+					offset += 4
+					n++
+				}
+			}
+			offset := dst.PixOffset(x, y)
+			dst.Pix[offset] = uint8(r / n)
+			dst.Pix[offset+1] = uint8(g / n)
+			dst.Pix[offset+2] = uint8(b / n)
+			dst.Pix[offset+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
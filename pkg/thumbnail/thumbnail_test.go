@@ -0,0 +1,144 @@
+package thumbnail
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mockImage(t *testing.T, format string, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			// the left half is red and the right half is blue
+			if x < width/2 {
+				img.Set(x, y, color.RGBA{R: 255, A: 255})
+			} else {
+				img.Set(x, y, color.RGBA{B: 255, A: 255})
+			}
+		}
+	}
+	buf := &bytes.Buffer{}
+	var err error
+	switch format {
+	case FormatJPEG:
+		err = jpeg.Encode(buf, img, nil)
+	case FormatPNG:
+		err = png.Encode(buf, img)
+	case FormatGIF:
+		err = gif.Encode(buf, img, nil)
+	}
+	assert.Nil(t, err)
+	return buf.Bytes()
+}
+
+func TestParseOptions(t *testing.T) {
+	cases := []struct {
+		name       string
+		query      string
+		wantedOpts *Options
+		wantedErr  bool
+	}{
+		{name: "no preview query", query: "view=1"},
+		{name: "width only", query: "w=100", wantedOpts: &Options{Width: 100, Fit: FitContain}},
+		{name: "format only", query: "format=JPG", wantedOpts: &Options{Fit: FitContain, Format: FormatJPEG}},
+		{name: "cover", query: "w=100&h=50&fit=cover&format=png", wantedOpts: &Options{Width: 100, Height: 50, Fit: FitCover, Format: FormatPNG}},
+		{name: "zero width", query: "w=0", wantedErr: true},
+		{name: "too large height", query: "h=5000", wantedErr: true},
+		{name: "bad width", query: "w=abc", wantedErr: true},
+		{name: "unsupported fit", query: "w=1&fit=tile", wantedErr: true},
+		{name: "unsupported format", query: "format=webp", wantedErr: true},
+		{name: "cover without height", query: "w=100&fit=cover", wantedErr: true},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			values, _ := url.ParseQuery(tt.query)
+			opts, err := ParseOptions(values, 4096)
+			if tt.wantedErr {
+				assert.True(t, errors.Is(err, ErrInvalidOptions))
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantedOpts, opts)
+		})
+	}
+}
+
+func TestLayout(t *testing.T) {
+	bounds := image.Rect(0, 0, 400, 200)
+	cases := []struct {
+		name         string
+		opts         *Options
+		wantedCrop   image.Rectangle
+		wantedWidth  int
+		wantedHeight int
+	}{
+		{name: "format only", opts: &Options{Fit: FitContain}, wantedCrop: bounds, wantedWidth: 400, wantedHeight: 200},
+		{name: "contain by width", opts: &Options{Width: 100, Fit: FitContain}, wantedCrop: bounds, wantedWidth: 100, wantedHeight: 50},
+		{name: "contain by height", opts: &Options{Width: 100, Height: 20, Fit: FitContain}, wantedCrop: bounds, wantedWidth: 40, wantedHeight: 20},
+		{name: "contain never enlarges", opts: &Options{Width: 800, Fit: FitContain}, wantedCrop: bounds, wantedWidth: 400, wantedHeight: 200},
+		{name: "fill", opts: &Options{Width: 50, Height: 50, Fit: FitFill}, wantedCrop: bounds, wantedWidth: 50, wantedHeight: 50},
+		{name: "cover", opts: &Options{Width: 50, Height: 50, Fit: FitCover}, wantedCrop: image.Rect(100, 0, 300, 200), wantedWidth: 50, wantedHeight: 50},
+		{name: "cover never enlarges", opts: &Options{Width: 300, Height: 300, Fit: FitCover}, wantedCrop: image.Rect(100, 0, 300, 200), wantedWidth: 200, wantedHeight: 200},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			crop, width, height := layout(bounds, tt.opts)
+			assert.Equal(t, tt.wantedCrop, crop)
+			assert.Equal(t, tt.wantedWidth, width)
+			assert.Equal(t, tt.wantedHeight, height)
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	for _, format := range []string{FormatJPEG, FormatPNG, FormatGIF} {
+		t.Run(format, func(t *testing.T) {
+			data, contentType, err := Render(mockImage(t, format, 64, 32), &Options{Width: 16, Fit: FitContain}, 1<<20)
+			assert.Nil(t, err)
+			assert.Equal(t, contentTypes[format], contentType)
+			config, decodedFormat, err := image.DecodeConfig(bytes.NewReader(data))
+			assert.Nil(t, err)
+			assert.Equal(t, format, decodedFormat)
+			assert.Equal(t, 16, config.Width)
+			assert.Equal(t, 8, config.Height)
+		})
+	}
+
+	// convert the format
+	data, contentType, err := Render(mockImage(t, FormatPNG, 64, 32), &Options{Width: 8, Height: 8, Fit: FitFill, Format: FormatJPEG}, 1<<20)
+	assert.Nil(t, err)
+	assert.Equal(t, "image/jpeg", contentType)
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	assert.Nil(t, err)
+	r, _, b, _ := img.At(0, 0).RGBA()
+	assert.True(t, r > b)
+	r, _, b, _ = img.At(7, 0).RGBA()
+	assert.True(t, b > r)
+
+	_, _, err = Render([]byte("not an image"), &Options{Width: 8}, 1<<20)
+	assert.True(t, errors.Is(err, ErrUnsupportedImage))
+	_, _, err = Render(mockImage(t, FormatPNG, 64, 32), &Options{Width: 8}, 100)
+	assert.True(t, errors.Is(err, ErrImageTooLarge))
+}
+
+func TestResize(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for x := 0; x < 4; x++ {
+		src.Set(x, 0, color.RGBA{R: uint8(x * 60), A: 255})
+		src.Set(x, 1, color.RGBA{R: uint8(x * 60), A: 255})
+	}
+	dst := resize(src, src.Bounds(), 2, 1)
+	assert.Equal(t, image.Rect(0, 0, 2, 1), dst.Bounds())
+	assert.Equal(t, uint8(30), dst.RGBAAt(0, 0).R)
+	assert.Equal(t, uint8(150), dst.RGBAAt(1, 0).R)
+}
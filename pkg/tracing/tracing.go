@@ -0,0 +1,150 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+)
+
+const (
+	// TracerName is the instrumentation name of the spans created by the sp.
+	TracerName = "github.com/zkMeLabs/mechain-storage-provider"
+	// DefaultServiceName defines the default service name of the exported spans.
+	DefaultServiceName = "mechain-sp"
+	// DefaultEndpoint defines the default address of the otlp grpc collector.
+	DefaultEndpoint = "localhost:4317"
+	// DefaultShutdownTimeout defines the default timeout of flushing the spans on the stop time.
+	DefaultShutdownTimeout = 5 * time.Second
+
+	// AlwaysOnSampler samples all the traces.
+	AlwaysOnSampler = "always_on"
+	// AlwaysOffSampler samples none of the traces.
+	AlwaysOffSampler = "always_off"
+	// RatioSampler samples the root spans by SampleRatio, the child spans follow the decision of the parent
+	// which may be made by the client or the other sp modules.
+	RatioSampler = "ratio"
+	// DefaultSampler defines the default sampler.
+	DefaultSampler = RatioSampler
+	// DefaultSampleRatio defines the default ratio of RatioSampler.
+	DefaultSampleRatio = 0.01
+)
+
+// Config defines the tracing of the sp.
+type Config struct {
+	// Endpoint is the address of the otlp grpc collector.
+	Endpoint string
+	// Insecure disables the tls to the collector.
+	Insecure bool
+	// Sampler is one of always_on, always_off and ratio.
+	Sampler     string
+	SampleRatio float64
+	ServiceName string
+	// Modules are the modules running in the process, they are recorded in the resource of the spans.
+	Modules []string
+}
+
+// Tracer returns the tracer of the sp, the spans are dropped if the tracing is disabled.
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
+
+// StartSpan starts a span as the child of the span in ctx.
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// EndSpan records the error if any and ends the span.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Provider exports the spans of the process to the otlp collector.
+type Provider struct {
+	provider *sdktrace.TracerProvider
+}
+
+// NewProvider returns a Provider and installs it as the global tracer provider, the trace context is
+// propagated by the w3c traceparent and baggage headers.
+func NewProvider(cfg Config) (*Provider, error) {
+	sampler, err := newSampler(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = DefaultEndpoint
+	}
+	if cfg.ServiceName == "" {
+		cfg.ServiceName = DefaultServiceName
+	}
+	opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.Endpoint)}
+	if cfg.Insecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+	// the exporter connects lazily, the unreachable collector does not block the start of the sp
+	exporter, err := otlptracegrpc.New(context.Background(), opts...)
+	if err != nil {
+		return nil, err
+	}
+	hostname, _ := os.Hostname()
+	res := resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+		semconv.HostName(hostname),
+		attribute.String("sp.modules", strings.Join(cfg.Modules, ",")))
+	p := &Provider{provider: sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sampler),
+		sdktrace.WithResource(res),
+	)}
+	otel.SetTracerProvider(p.provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	log.Infow("succeed to init tracing", "endpoint", cfg.Endpoint, "sampler", cfg.Sampler, "ratio", cfg.SampleRatio)
+	return p, nil
+}
+
+// Close flushes the pending spans and stops the exporter, it is nil-safe.
+func (p *Provider) Close() error {
+	if p == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultShutdownTimeout)
+	defer cancel()
+	return p.provider.Shutdown(ctx)
+}
+
+func newSampler(cfg Config) (sdktrace.Sampler, error) {
+	switch cfg.Sampler {
+	case AlwaysOnSampler:
+		return sdktrace.ParentBased(sdktrace.AlwaysSample()), nil
+	case AlwaysOffSampler:
+		return sdktrace.NeverSample(), nil
+	case RatioSampler, "":
+		ratio := cfg.SampleRatio
+		if ratio == 0 {
+			ratio = DefaultSampleRatio
+		}
+		if ratio < 0 || ratio > 1 {
+			return nil, fmt.Errorf("invalid trace sample ratio %v", ratio)
+		}
+		return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio)), nil
+	default:
+		return nil, fmt.Errorf("unsupported trace sampler: %s", cfg.Sampler)
+	}
+}
@@ -0,0 +1,122 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+)
+
+// mockCollector is an in-process otlp collector recording the names of the received spans.
+type mockCollector struct {
+	coltracepb.UnimplementedTraceServiceServer
+	mu    sync.Mutex
+	spans []string
+}
+
+func (c *mockCollector) Export(_ context.Context, req *coltracepb.ExportTraceServiceRequest) (
+	*coltracepb.ExportTraceServiceResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, rs := range req.GetResourceSpans() {
+		for _, ss := range rs.GetScopeSpans() {
+			for _, span := range ss.GetSpans() {
+				c.spans = append(c.spans, span.GetName())
+			}
+		}
+	}
+	return &coltracepb.ExportTraceServiceResponse{}, nil
+}
+
+func (c *mockCollector) names() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.spans...)
+}
+
+func startCollector(t *testing.T) (*mockCollector, string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	collector := &mockCollector{}
+	server := grpc.NewServer()
+	coltracepb.RegisterTraceServiceServer(server, collector)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+	return collector, listener.Addr().String()
+}
+
+func TestProviderExport(t *testing.T) {
+	collector, endpoint := startCollector(t)
+	p, err := NewProvider(Config{Endpoint: endpoint, Insecure: true, Sampler: AlwaysOnSampler})
+	assert.Nil(t, err)
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	ctx, parent := StartSpan(context.Background(), "parent")
+	_, child := StartSpan(ctx, "child")
+	EndSpan(child, errors.New("mock error"))
+	EndSpan(parent, nil)
+	// the spans are flushed on the close time
+	assert.Nil(t, p.Close())
+	assert.ElementsMatch(t, []string{"parent", "child"}, collector.names())
+}
+
+func TestProviderSampler(t *testing.T) {
+	collector, endpoint := startCollector(t)
+	p, err := NewProvider(Config{Endpoint: endpoint, Insecure: true, Sampler: AlwaysOffSampler})
+	assert.Nil(t, err)
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	_, span := StartSpan(context.Background(), "dropped")
+	assert.False(t, span.SpanContext().IsSampled())
+	EndSpan(span, nil)
+	assert.Nil(t, p.Close())
+	assert.Empty(t, collector.names())
+}
+
+func TestNewProviderFailure(t *testing.T) {
+	_, err := NewProvider(Config{Sampler: "random"})
+	assert.NotNil(t, err)
+	_, err = NewProvider(Config{Sampler: RatioSampler, SampleRatio: 2})
+	assert.NotNil(t, err)
+}
+
+func TestProviderCloseNil(t *testing.T) {
+	var p *Provider
+	assert.Nil(t, p.Close())
+}
+
+func TestTransport(t *testing.T) {
+	collector, endpoint := startCollector(t)
+	p, err := NewProvider(Config{Endpoint: endpoint, Insecure: true, Sampler: AlwaysOnSampler})
+	assert.Nil(t, err)
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	var traceParent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceParent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport(nil)}
+	ctx, span := StartSpan(context.Background(), "caller")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+"/rpc", nil)
+	assert.Nil(t, err)
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	_ = resp.Body.Close()
+	EndSpan(span, nil)
+	// the server receives the trace id of the caller
+	assert.Contains(t, traceParent, span.SpanContext().TraceID().String())
+	assert.Nil(t, p.Close())
+	assert.ElementsMatch(t, []string{"caller", "HTTP POST"}, collector.names())
+}
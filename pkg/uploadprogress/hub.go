@@ -0,0 +1,148 @@
+package uploadprogress
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultSubscriberBufferSize defines the default buffer size of a subscriber channel.
+const DefaultSubscriberBufferSize = 256
+
+// Event defines the upload state transition of an object.
+type Event struct {
+	BucketName       string `json:"bucket_name"`
+	ObjectName       string `json:"object_name"`
+	ObjectID         uint64 `json:"object_id"`
+	State            int32  `json:"state"`
+	StateDescription string `json:"state_description"`
+	ErrorDescription string `json:"error_description,omitempty"`
+	Timestamp        int64  `json:"timestamp"`
+}
+
+// Filter returns an indicator whether the subscriber is interested in the event.
+type Filter func(event *Event) bool
+
+// ObjectFilter returns the filter which only accepts the events of the object.
+func ObjectFilter(objectID uint64) Filter {
+	return func(event *Event) bool {
+		return event.ObjectID == objectID
+	}
+}
+
+// BucketFilter returns the filter which accepts the events of all objects in the bucket.
+func BucketFilter(bucketName string) Filter {
+	return func(event *Event) bool {
+		return event.BucketName == bucketName
+	}
+}
+
+// Subscription receives the events accepted by its filter.
+type Subscription struct {
+	hub    *Hub
+	filter Filter
+	ch     chan *Event
+	once   sync.Once
+}
+
+// C returns the channel of the events, it is closed after the subscription is canceled.
+func (s *Subscription) C() <-chan *Event {
+	return s.ch
+}
+
+// Cancel unsubscribes from the hub, it is safe to call more than once.
+func (s *Subscription) Cancel() {
+	s.once.Do(func() {
+		s.hub.remove(s)
+		close(s.ch)
+	})
+}
+
+// Hub fans out the upload state transitions published by the manager to the subscribers.
+// The publishing never blocks, the events are dropped for the slow subscribers, so the
+// subscribers should treat the events as hints and query the latest state when they need
+// an accurate one.
+type Hub struct {
+	mu   sync.RWMutex
+	subs map[*Subscription]struct{}
+	// complete indicates the hub receives the transitions of all the managers.
+	complete bool
+	// broker shares the events among the processes, nil means the events stay in the process.
+	broker broker
+}
+
+// NewHub returns a Hub which fans out the events published in the same process, it receives
+// the transitions of all the objects if the manager runs in the same process.
+func NewHub(complete bool) *Hub {
+	return &Hub{subs: make(map[*Subscription]struct{}), complete: complete}
+}
+
+// Complete returns an indicator whether the hub receives the transitions of all the objects, the
+// subscribers must query the upload states by themselves if not.
+func (h *Hub) Complete() bool {
+	return h.complete
+}
+
+// Close stops sharing the events among the processes, it is safe to call on a nil Hub.
+func (h *Hub) Close() {
+	if h == nil || h.broker == nil {
+		return
+	}
+	h.broker.close()
+}
+
+// Subscribe returns a subscription which receives the events accepted by the filter.
+func (h *Hub) Subscribe(filter Filter, bufferSize int) *Subscription {
+	if bufferSize <= 0 {
+		bufferSize = DefaultSubscriberBufferSize
+	}
+	sub := &Subscription{hub: h, filter: filter, ch: make(chan *Event, bufferSize)}
+	h.mu.Lock()
+	h.subs[sub] = struct{}{}
+	h.mu.Unlock()
+	return sub
+}
+
+// Publish delivers the event to the interested subscribers, the event is sent to the subscribers
+// of all the processes if the hub is shared. It is safe to call on a nil Hub.
+func (h *Hub) Publish(event *Event) {
+	if h == nil || event == nil {
+		return
+	}
+	if event.Timestamp == 0 {
+		event.Timestamp = time.Now().Unix()
+	}
+	if h.broker != nil {
+		// the event is delivered when it is received back from the broker
+		h.broker.publish(event)
+		return
+	}
+	h.deliver(event)
+}
+
+// deliver fans out the event to the interested subscribers in the process.
+func (h *Hub) deliver(event *Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for sub := range h.subs {
+		if sub.filter != nil && !sub.filter(event) {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+		}
+	}
+}
+
+// SubscriberNumber returns the number of the subscribers.
+func (h *Hub) SubscriberNumber() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subs)
+}
+
+func (h *Hub) remove(sub *Subscription) {
+	h.mu.Lock()
+	delete(h.subs, sub)
+	h.mu.Unlock()
+}
@@ -0,0 +1,91 @@
+package uploadprogress
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHub_PublishAndSubscribe(t *testing.T) {
+	h := NewHub(true)
+	objectSub := h.Subscribe(ObjectFilter(1), 0)
+	defer objectSub.Cancel()
+	bucketSub := h.Subscribe(BucketFilter("bucket"), 0)
+	defer bucketSub.Cancel()
+	assert.Equal(t, 2, h.SubscriberNumber())
+	assert.True(t, h.Complete())
+
+	h.Publish(&Event{BucketName: "bucket", ObjectName: "object1", ObjectID: 1, State: 1})
+	h.Publish(&Event{BucketName: "bucket", ObjectName: "object2", ObjectID: 2, State: 1})
+	h.Publish(&Event{BucketName: "other", ObjectName: "object3", ObjectID: 3, State: 1})
+
+	event := <-objectSub.C()
+	assert.Equal(t, uint64(1), event.ObjectID)
+	assert.NotZero(t, event.Timestamp)
+	assert.Equal(t, 0, len(objectSub.C()))
+
+	assert.Equal(t, uint64(1), (<-bucketSub.C()).ObjectID)
+	assert.Equal(t, uint64(2), (<-bucketSub.C()).ObjectID)
+	assert.Equal(t, 0, len(bucketSub.C()))
+}
+
+func TestHub_PublishDropForSlowSubscriber(t *testing.T) {
+	h := NewHub(true)
+	sub := h.Subscribe(nil, 1)
+	defer sub.Cancel()
+	h.Publish(&Event{ObjectID: 1})
+	h.Publish(&Event{ObjectID: 2})
+	assert.Equal(t, uint64(1), (<-sub.C()).ObjectID)
+	assert.Equal(t, 0, len(sub.C()))
+}
+
+func TestSubscription_Cancel(t *testing.T) {
+	h := NewHub(true)
+	sub := h.Subscribe(nil, 0)
+	sub.Cancel()
+	sub.Cancel()
+	assert.Equal(t, 0, h.SubscriberNumber())
+	_, ok := <-sub.C()
+	assert.False(t, ok)
+	h.Publish(&Event{ObjectID: 1})
+}
+
+func TestHub_PublishNil(t *testing.T) {
+	var h *Hub
+	h.Publish(&Event{ObjectID: 1})
+	NewHub(true).Publish(nil)
+}
+
+func TestRedisHub(t *testing.T) {
+	server := miniredis.RunT(t)
+	_, err := NewRedisHub(RedisConfig{})
+	assert.NotNil(t, err)
+
+	// the events published by the manager process are received by the gateway process
+	manager, err := NewRedisHub(RedisConfig{Address: server.Addr()})
+	assert.Nil(t, err)
+	defer manager.Close()
+	gateway, err := NewRedisHub(RedisConfig{Address: server.Addr()})
+	assert.Nil(t, err)
+	defer gateway.Close()
+	assert.True(t, gateway.Complete())
+
+	sub := gateway.Subscribe(BucketFilter("bucket"), 0)
+	defer sub.Cancel()
+	manager.Publish(&Event{BucketName: "other", ObjectID: 1})
+	manager.Publish(&Event{BucketName: "bucket", ObjectID: 2, State: 1})
+	select {
+	case event := <-sub.C():
+		assert.Equal(t, uint64(2), event.ObjectID)
+		assert.Equal(t, int32(1), event.State)
+		assert.NotZero(t, event.Timestamp)
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout waiting for upload progress event")
+	}
+
+	manager.Close()
+	manager.Close()
+	manager.Publish(&Event{BucketName: "bucket", ObjectID: 3})
+}
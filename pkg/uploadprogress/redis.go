@@ -0,0 +1,163 @@
+package uploadprogress
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+)
+
+const (
+	// DefaultRedisChannel defines the default redis channel of the upload progress events.
+	DefaultRedisChannel = "sp_upload_progress"
+	// DefaultRedisTimeoutMillisecond defines the default timeout of publishing an event to redis.
+	DefaultRedisTimeoutMillisecond = 100
+	// publishQueueSize defines the size of the events waiting to be published, the events are dropped
+	// if redis can not keep up, the same as the slow subscribers.
+	publishQueueSize = 1024
+)
+
+// RedisConfig defines the redis protocol server which shares the upload progress events among the
+// processes, so the gateway streams the transitions published by the managers in other processes.
+type RedisConfig struct {
+	Address            string `comment:"optional"`
+	Username           string `comment:"optional"`
+	Password           string `comment:"optional"`
+	DB                 int    `comment:"optional"`
+	Channel            string `comment:"optional"`
+	TimeoutMillisecond int64  `comment:"optional"`
+}
+
+// broker shares the published events among the processes.
+type broker interface {
+	publish(event *Event)
+	close()
+}
+
+// NewRedisHub returns a Hub shared by all the processes subscribing the same redis channel, it
+// receives the transitions of all the objects.
+func NewRedisHub(cfg RedisConfig) (*Hub, error) {
+	if cfg.Address == "" {
+		return nil, fmt.Errorf("the address of the upload progress redis is required")
+	}
+	if cfg.Channel == "" {
+		cfg.Channel = DefaultRedisChannel
+	}
+	if cfg.TimeoutMillisecond == 0 {
+		cfg.TimeoutMillisecond = DefaultRedisTimeoutMillisecond
+	}
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Address,
+		Username: cfg.Username,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+	hub := NewHub(true)
+	b, err := newRedisBroker(client, cfg.Channel, time.Duration(cfg.TimeoutMillisecond)*time.Millisecond, hub.deliver)
+	if err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+	hub.broker = b
+	return hub, nil
+}
+
+// redisBroker publishes the events to the redis channel and delivers the events received from it.
+type redisBroker struct {
+	client  *redis.Client
+	channel string
+	timeout time.Duration
+	pubsub  *redis.PubSub
+	queue   chan *Event
+	quit    chan struct{}
+	once    sync.Once
+	wg      sync.WaitGroup
+}
+
+func newRedisBroker(client *redis.Client, channel string, timeout time.Duration, deliver func(*Event)) (*redisBroker, error) {
+	ctx := context.Background()
+	pubsub := client.Subscribe(ctx, channel)
+	// wait for the confirmation so that the unreachable redis fails the start
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return nil, fmt.Errorf("failed to subscribe upload progress channel: %w", err)
+	}
+	b := &redisBroker{
+		client:  client,
+		channel: channel,
+		timeout: timeout,
+		pubsub:  pubsub,
+		queue:   make(chan *Event, publishQueueSize),
+		quit:    make(chan struct{}),
+	}
+	b.wg.Add(2)
+	go b.send()
+	go b.receive(deliver)
+	return b, nil
+}
+
+// publish enqueues the event without blocking the publisher.
+func (b *redisBroker) publish(event *Event) {
+	select {
+	case b.queue <- event:
+	default:
+		log.Warnw("upload progress publish queue is full, drop the event", "object_id", event.ObjectID)
+	}
+}
+
+func (b *redisBroker) send() {
+	defer b.wg.Done()
+	for {
+		select {
+		case <-b.quit:
+			return
+		case event := <-b.queue:
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+			if err = b.client.Publish(ctx, b.channel, data).Err(); err != nil {
+				log.Errorw("failed to publish upload progress event", "object_id", event.ObjectID, "error", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// receive delivers the events of the channel until the broker is closed, the subscription is
+// re-established by the client after the connection is broken.
+func (b *redisBroker) receive(deliver func(*Event)) {
+	defer b.wg.Done()
+	messages := b.pubsub.Channel()
+	for {
+		select {
+		case <-b.quit:
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			event := &Event{}
+			if err := json.Unmarshal([]byte(msg.Payload), event); err != nil {
+				log.Errorw("failed to unmarshal upload progress event", "error", err)
+				continue
+			}
+			deliver(event)
+		}
+	}
+}
+
+func (b *redisBroker) close() {
+	b.once.Do(func() {
+		close(b.quit)
+		_ = b.pubsub.Close()
+		b.wg.Wait()
+		_ = b.client.Close()
+	})
+}
@@ -0,0 +1,200 @@
+package urlfetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// DefaultDialTimeout defines the default timeout of connecting the source.
+	DefaultDialTimeout = 10 * time.Second
+	// DefaultResponseHeaderTimeout defines the default timeout of waiting for the response header.
+	DefaultResponseHeaderTimeout = 30 * time.Second
+	// DefaultMaxRedirects defines the default max number of the followed redirects.
+	DefaultMaxRedirects = 5
+)
+
+var (
+	// ErrUnsupportedScheme is returned if the source url is not a http or https url.
+	ErrUnsupportedScheme = errors.New("only http and https source url is supported")
+	// ErrForbiddenAddress is returned if the source resolves to a loopback, private or otherwise non-public address.
+	ErrForbiddenAddress = errors.New("source address is not allowed")
+	// ErrTooManyRedirects is returned if the source redirects more than the max redirects.
+	ErrTooManyRedirects = errors.New("too many redirects")
+)
+
+// StatusError is returned if the source responds with a non-2xx status code.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("source responds with status code %d", e.StatusCode)
+}
+
+// Config defines the options of the Fetcher.
+type Config struct {
+	// AllowPrivateNetwork allows fetching from the loopback, private and link-local addresses,
+	// it should only be enabled in the test environment.
+	AllowPrivateNetwork   bool
+	DialTimeout           time.Duration
+	ResponseHeaderTimeout time.Duration
+	MaxRedirects          int
+}
+
+// Fetcher opens the http(s) source url on behalf of the user. The resolved address is checked when
+// the connection is dialed, including the redirects, so that the SP can not be used to reach its
+// internal services by the dns rebinding or the redirect to an internal address.
+type Fetcher struct {
+	client *http.Client
+}
+
+// NewFetcher returns a Fetcher with the config, the zero values are replaced by the defaults.
+func NewFetcher(cfg Config) *Fetcher {
+	cfg.fillDefault()
+	return &Fetcher{
+		client: &http.Client{
+			Transport: NewTransport(cfg),
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) > cfg.MaxRedirects {
+					return ErrTooManyRedirects
+				}
+				return checkScheme(req.URL)
+			},
+		},
+	}
+}
+
+func (c *Config) fillDefault() {
+	if c.DialTimeout == 0 {
+		c.DialTimeout = DefaultDialTimeout
+	}
+	if c.ResponseHeaderTimeout == 0 {
+		c.ResponseHeaderTimeout = DefaultResponseHeaderTimeout
+	}
+	if c.MaxRedirects == 0 {
+		c.MaxRedirects = DefaultMaxRedirects
+	}
+}
+
+// NewTransport returns the http transport which refuses to dial the non-public addresses unless
+// AllowPrivateNetwork is set. It is shared by the requests the SP sends to the user given urls.
+func NewTransport(cfg Config) *http.Transport {
+	cfg.fillDefault()
+	dialer := &net.Dialer{Timeout: cfg.DialTimeout}
+	if !cfg.AllowPrivateNetwork {
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if !IsPublicIP(net.ParseIP(host)) {
+				return ErrForbiddenAddress
+			}
+			return nil
+		}
+	}
+	return &http.Transport{
+		// the proxy is disabled so that the dialed address is always the checked source address
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          16,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   cfg.DialTimeout,
+		ResponseHeaderTimeout: cfg.ResponseHeaderTimeout,
+	}
+}
+
+// Open sends the GET request of the source url and returns the response whose body must be closed
+// by the caller. The ctx bounds the whole fetch including reading the body.
+func (f *Fetcher) Open(ctx context.Context, rawURL string) (*http.Response, error) {
+	u, err := ParseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			switch {
+			case errors.Is(urlErr.Err, ErrForbiddenAddress):
+				return nil, ErrForbiddenAddress
+			case errors.Is(urlErr.Err, ErrTooManyRedirects):
+				return nil, ErrTooManyRedirects
+			case errors.Is(urlErr.Err, ErrUnsupportedScheme):
+				return nil, ErrUnsupportedScheme
+			}
+		}
+		return nil, err
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		_ = resp.Body.Close()
+		return nil, &StatusError{StatusCode: resp.StatusCode}
+	}
+	return resp, nil
+}
+
+// ParseURL parses the source url which must be an absolute http or https url.
+func ParseURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkScheme(u); err != nil {
+		return nil, err
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("source url has no host")
+	}
+	if u.User != nil {
+		return nil, fmt.Errorf("source url must not contain the user info")
+	}
+	return u, nil
+}
+
+// CheckHost returns ErrForbiddenAddress if the host of the url is a non-public ip or the localhost, the
+// resolved address of a domain is still checked when the connection is dialed.
+func CheckHost(u *url.URL) error {
+	host := strings.ToLower(u.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrForbiddenAddress
+	}
+	if ip := net.ParseIP(host); ip != nil && !IsPublicIP(ip) {
+		return ErrForbiddenAddress
+	}
+	return nil
+}
+
+func checkScheme(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ErrUnsupportedScheme
+	}
+	return nil
+}
+
+// IsPublicIP returns whether the ip is a public unicast address.
+func IsPublicIP(ip net.IP) bool {
+	if ip == nil || ip.IsUnspecified() || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		// 100.64.0.0/10 carrier-grade nat and 0.0.0.0/8 this network
+		if (ip4[0] == 100 && ip4[1]&0xc0 == 64) || ip4[0] == 0 {
+			return false
+		}
+	}
+	return true
+}
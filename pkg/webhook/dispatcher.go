@@ -0,0 +1,396 @@
+package webhook
+
+import (
+	"bytes"
+	"container/heap"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/urlfetch"
+)
+
+const (
+	// DefaultWorkers defines the default number of the delivery workers.
+	DefaultWorkers = 4
+	// DefaultQueueSize defines the default size of the pending event queue.
+	DefaultQueueSize = 10240
+	// DefaultMaxRetry defines the default max retry number of a delivery.
+	DefaultMaxRetry = 5
+	// DefaultTimeout defines the default timeout of a delivery http request.
+	DefaultTimeout = 5 * time.Second
+	// DefaultInitialBackoff defines the default backoff before the first retry.
+	DefaultInitialBackoff = time.Second
+	// DefaultMaxBackoff defines the default max backoff between two retries.
+	DefaultMaxBackoff = time.Minute
+)
+
+// Config defines the webhook dispatcher configuration.
+type Config struct {
+	Workers        int
+	QueueSize      int
+	MaxRetry       int
+	Timeout        time.Duration
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// AllowPrivateNetwork allows delivering to the loopback, private and link-local addresses,
+	// it should only be enabled in the test environment.
+	AllowPrivateNetwork bool
+}
+
+func (c *Config) fillDefault() {
+	if c.Workers <= 0 {
+		c.Workers = DefaultWorkers
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = DefaultQueueSize
+	}
+	if c.MaxRetry < 0 {
+		c.MaxRetry = 0
+	} else if c.MaxRetry == 0 {
+		c.MaxRetry = DefaultMaxRetry
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultTimeout
+	}
+	if c.InitialBackoff <= 0 {
+		c.InitialBackoff = DefaultInitialBackoff
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = DefaultMaxBackoff
+	}
+}
+
+// Resolver returns the subscriptions that should receive the event, it is used to
+// decouple the dispatcher from the subscription storage.
+type Resolver func(ctx context.Context, event *Event) ([]*Subscription, error)
+
+// delivery is an event to be sent to a subscription.
+type delivery struct {
+	sub     *Subscription
+	event   *Event
+	body    []byte
+	attempt int
+	due     time.Time
+}
+
+// Dispatcher delivers the events to the subscribers asynchronously, the delivery is
+// signed by the subscription secret and retried with exponential backoff on failure.
+// The failed deliveries are requeued with a delay instead of holding the workers, and
+// an endpoint that keeps failing is backed off as a whole, so the dead endpoints of
+// some owners do not starve the deliveries of the others.
+type Dispatcher struct {
+	cfg      Config
+	resolver Resolver
+	client   *http.Client
+
+	queue chan *Event
+	// retry is the channel of the due deliveries sent by the retry scheduler to the workers.
+	retry chan *delivery
+	quit  chan struct{}
+	wg    sync.WaitGroup
+
+	retryMu sync.Mutex
+	// pending is the deliveries waiting for the retry, ordered by the due time.
+	pending deliveryHeap
+	wake    chan struct{}
+	// backoffUntil is the time until which the deliveries to the endpoint are deferred.
+	backoffUntil map[string]time.Time
+
+	closeMu sync.RWMutex
+	closed  bool
+}
+
+// NewDispatcher returns an instance of Dispatcher and starts the delivery workers.
+func NewDispatcher(cfg Config, resolver Resolver) *Dispatcher {
+	cfg.fillDefault()
+	d := &Dispatcher{
+		cfg:      cfg,
+		resolver: resolver,
+		client: &http.Client{
+			Timeout:   cfg.Timeout,
+			Transport: urlfetch.NewTransport(urlfetch.Config{AllowPrivateNetwork: cfg.AllowPrivateNetwork}),
+			// the redirects are not followed, the endpoint must respond by itself
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		queue:        make(chan *Event, cfg.QueueSize),
+		retry:        make(chan *delivery),
+		quit:         make(chan struct{}),
+		wake:         make(chan struct{}, 1),
+		backoffUntil: make(map[string]time.Time),
+	}
+	for i := 0; i < cfg.Workers; i++ {
+		d.wg.Add(1)
+		go d.work()
+	}
+	d.wg.Add(1)
+	go d.scheduleRetry()
+	return d
+}
+
+// Notify enqueues the event without blocking, returns false if the dispatcher is
+// nil, closed or the queue is full. It is safe to call on a nil Dispatcher so that
+// callers do not need to check whether webhook is enabled.
+func (d *Dispatcher) Notify(event *Event) bool {
+	if d == nil || event == nil {
+		return false
+	}
+	if event.ID == "" {
+		event.ID = newDeliveryID()
+	}
+	if event.Timestamp == 0 {
+		event.Timestamp = time.Now().Unix()
+	}
+	d.closeMu.RLock()
+	defer d.closeMu.RUnlock()
+	if d.closed {
+		return false
+	}
+	select {
+	case d.queue <- event:
+		return true
+	default:
+		log.Warnw("webhook queue is full, drop the event", "event_id", event.ID, "event_type", event.Type,
+			"bucket_name", event.BucketName, "object_name", event.ObjectName)
+		return false
+	}
+}
+
+// Close stops the workers, the pending events in queue and the pending retries are dropped.
+func (d *Dispatcher) Close() {
+	if d == nil {
+		return
+	}
+	d.closeMu.Lock()
+	if d.closed {
+		d.closeMu.Unlock()
+		return
+	}
+	d.closed = true
+	close(d.quit)
+	d.closeMu.Unlock()
+	d.wg.Wait()
+}
+
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+	for {
+		select {
+		case <-d.quit:
+			return
+		case event := <-d.queue:
+			d.dispatch(event)
+		case dl := <-d.retry:
+			d.attempt(dl)
+		}
+	}
+}
+
+func (d *Dispatcher) dispatch(event *Event) {
+	ctx := context.Background()
+	subs, err := d.resolver(ctx, event)
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to resolve webhook subscriptions", "event_id", event.ID,
+			"event_type", event.Type, "bucket_name", event.BucketName, "error", err)
+		return
+	}
+	if len(subs) == 0 {
+		return
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to marshal webhook event", "event_id", event.ID, "error", err)
+		return
+	}
+	for _, sub := range subs {
+		if sub == nil || !sub.Accepts(event.Type) {
+			continue
+		}
+		d.attempt(&delivery{sub: sub, event: event, body: body})
+	}
+}
+
+// attempt sends the delivery once, the retryable failure is requeued with the backoff of the attempt, and
+// the endpoint is backed off as well. The delivery to an endpoint in backoff is deferred without sending.
+func (d *Dispatcher) attempt(dl *delivery) {
+	ctx := context.Background()
+	now := time.Now()
+	if until := d.endpointBackoff(dl.sub.URL, now); until.After(now) {
+		d.requeue(dl, until)
+		return
+	}
+	retry, err := d.deliver(ctx, dl.sub, dl.event, dl.body)
+	if err == nil {
+		d.setEndpointBackoff(dl.sub.URL, time.Time{})
+		return
+	}
+	if !retry || dl.attempt >= d.cfg.MaxRetry {
+		log.CtxErrorw(ctx, "failed to deliver webhook event", "event_id", dl.event.ID, "event_type", dl.event.Type,
+			"owner", dl.sub.OwnerAddress, "url", dl.sub.URL, "attempt", dl.attempt, "error", err)
+		return
+	}
+	log.CtxDebugw(ctx, "failed to deliver webhook event, retry later", "event_id", dl.event.ID,
+		"url", dl.sub.URL, "attempt", dl.attempt, "error", err)
+	due := now.Add(d.backoff(dl.attempt))
+	dl.attempt++
+	d.setEndpointBackoff(dl.sub.URL, due)
+	d.requeue(dl, due)
+}
+
+// backoff returns the delay before the retry of the attempt.
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	backoff := d.cfg.InitialBackoff
+	for i := 0; i < attempt && backoff < d.cfg.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > d.cfg.MaxBackoff {
+		backoff = d.cfg.MaxBackoff
+	}
+	return backoff
+}
+
+func (d *Dispatcher) endpointBackoff(endpoint string, now time.Time) time.Time {
+	d.retryMu.Lock()
+	defer d.retryMu.Unlock()
+	until, ok := d.backoffUntil[endpoint]
+	if ok && !until.After(now) {
+		delete(d.backoffUntil, endpoint)
+	}
+	return until
+}
+
+// setEndpointBackoff defers the deliveries to the endpoint until the time, the zero time clears the backoff.
+func (d *Dispatcher) setEndpointBackoff(endpoint string, until time.Time) {
+	d.retryMu.Lock()
+	defer d.retryMu.Unlock()
+	if until.IsZero() {
+		delete(d.backoffUntil, endpoint)
+		return
+	}
+	if until.After(d.backoffUntil[endpoint]) {
+		d.backoffUntil[endpoint] = until
+	}
+}
+
+// requeue schedules the delivery at the due time, the delivery is dropped if the pending retries are full.
+func (d *Dispatcher) requeue(dl *delivery, due time.Time) {
+	d.retryMu.Lock()
+	if len(d.pending) >= d.cfg.QueueSize {
+		d.retryMu.Unlock()
+		log.Warnw("webhook retry queue is full, drop the event", "event_id", dl.event.ID,
+			"event_type", dl.event.Type, "url", dl.sub.URL)
+		return
+	}
+	dl.due = due
+	heap.Push(&d.pending, dl)
+	d.retryMu.Unlock()
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// scheduleRetry sends the due deliveries to the workers.
+func (d *Dispatcher) scheduleRetry() {
+	defer d.wg.Done()
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+	for {
+		var dl *delivery
+		d.retryMu.Lock()
+		wait := time.Hour
+		if len(d.pending) > 0 {
+			if wait = time.Until(d.pending[0].due); wait <= 0 {
+				dl = heap.Pop(&d.pending).(*delivery)
+			}
+		}
+		d.retryMu.Unlock()
+		if dl != nil {
+			select {
+			case <-d.quit:
+				return
+			case d.retry <- dl:
+			}
+			continue
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(wait)
+		select {
+		case <-d.quit:
+			return
+		case <-d.wake:
+		case <-timer.C:
+		}
+	}
+}
+
+// deliver sends the signed event to the subscription endpoint, returns whether the
+// failure is retryable.
+func (d *Dispatcher) deliver(ctx context.Context, sub *Subscription, event *Event, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, string(event.Type))
+	req.Header.Set(HeaderDelivery, event.ID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(sub.Secret, timestamp, body))
+	resp, err := d.client.Do(req)
+	if err != nil {
+		// the endpoint resolving to a non-public address is not retried
+		if errors.Is(err, urlfetch.ErrForbiddenAddress) {
+			return false, urlfetch.ErrForbiddenAddress
+		}
+		return true, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		return false, nil
+	}
+	err = fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	// the client errors are not retried except for rate limiting and request timeout
+	if resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests ||
+		resp.StatusCode == http.StatusRequestTimeout {
+		return true, err
+	}
+	return false, err
+}
+
+// deliveryHeap is the min heap of the deliveries ordered by the due time.
+type deliveryHeap []*delivery
+
+func (h deliveryHeap) Len() int            { return len(h) }
+func (h deliveryHeap) Less(i, j int) bool  { return h[i].due.Before(h[j].due) }
+func (h deliveryHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *deliveryHeap) Push(x interface{}) { *h = append(*h, x.(*delivery)) }
+func (h *deliveryHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	dl := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return dl
+}
+
+func newDeliveryID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,214 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/urlfetch"
+)
+
+func newTestDispatcher(maxRetry int, resolver Resolver) *Dispatcher {
+	return NewDispatcher(Config{
+		Workers:        1,
+		QueueSize:      8,
+		MaxRetry:       maxRetry,
+		Timeout:        time.Second,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+
+		AllowPrivateNetwork: true,
+	}, resolver)
+}
+
+func TestDispatcher_NotifyNil(t *testing.T) {
+	var d *Dispatcher
+	assert.False(t, d.Notify(&Event{Type: EventObjectSealed}))
+	d.Close()
+}
+
+func TestDispatcher_DeliverSigned(t *testing.T) {
+	received := make(chan *Event, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+		if !Verify("secret", timestamp, body, r.Header.Get(HeaderSignature)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		event := &Event{}
+		_ = json.Unmarshal(body, event)
+		assert.Equal(t, string(event.Type), r.Header.Get(HeaderEvent))
+		assert.Equal(t, event.ID, r.Header.Get(HeaderDelivery))
+		received <- event
+	}))
+	defer server.Close()
+
+	d := newTestDispatcher(1, func(ctx context.Context, event *Event) ([]*Subscription, error) {
+		return []*Subscription{{OwnerAddress: "owner", URL: server.URL, Secret: "secret"}}, nil
+	})
+	defer d.Close()
+
+	assert.True(t, d.Notify(&Event{Type: EventObjectSealed, BucketName: "bucket", ObjectName: "object", ObjectID: 1}))
+	select {
+	case event := <-received:
+		assert.Equal(t, EventObjectSealed, event.Type)
+		assert.Equal(t, "object", event.ObjectName)
+		assert.NotEmpty(t, event.ID)
+		assert.NotZero(t, event.Timestamp)
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout waiting for webhook delivery")
+	}
+}
+
+func TestDispatcher_RetryOnServerError(t *testing.T) {
+	var calls atomic.Int32
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		close(done)
+	}))
+	defer server.Close()
+
+	d := newTestDispatcher(3, func(ctx context.Context, event *Event) ([]*Subscription, error) {
+		return []*Subscription{{URL: server.URL, Secret: "secret"}}, nil
+	})
+	defer d.Close()
+
+	assert.True(t, d.Notify(&Event{Type: EventObjectDeleted}))
+	select {
+	case <-done:
+		assert.Equal(t, int32(3), calls.Load())
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout waiting for webhook retry")
+	}
+}
+
+func TestDispatcher_NoRetryOnClientError(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	sub := &Subscription{URL: server.URL, Secret: "secret"}
+	d := newTestDispatcher(3, nil)
+	defer d.Close()
+
+	d.attempt(&delivery{sub: sub, event: &Event{ID: "1", Type: EventObjectUploaded}, body: []byte("{}")})
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Equal(t, 0, d.pending.Len())
+}
+
+func TestDispatcher_ForbiddenAddress(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer server.Close()
+
+	d := NewDispatcher(Config{Workers: 1, MaxRetry: 3}, nil)
+	defer d.Close()
+	retry, err := d.deliver(context.Background(), &Subscription{URL: server.URL}, &Event{ID: "1"}, []byte("{}"))
+	assert.False(t, retry)
+	assert.Equal(t, urlfetch.ErrForbiddenAddress, err)
+	assert.Equal(t, int32(0), calls.Load())
+}
+
+func TestDispatcher_DeadEndpointNotStarve(t *testing.T) {
+	var deadCalls atomic.Int32
+	dead := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deadCalls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer dead.Close()
+	received := make(chan string, 8)
+	alive := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Header.Get(HeaderDelivery)
+	}))
+	defer alive.Close()
+
+	d := NewDispatcher(Config{
+		Workers:             1,
+		QueueSize:           8,
+		MaxRetry:            3,
+		Timeout:             time.Second,
+		InitialBackoff:      time.Hour,
+		MaxBackoff:          time.Hour,
+		AllowPrivateNetwork: true,
+	}, func(ctx context.Context, event *Event) ([]*Subscription, error) {
+		if event.BucketName == "dead" {
+			return []*Subscription{{URL: dead.URL}}, nil
+		}
+		return []*Subscription{{URL: alive.URL}}, nil
+	})
+	defer d.Close()
+
+	// the failed delivery waits for the retry without holding the only worker, and the other
+	// deliveries to the dead endpoint are deferred without sending
+	assert.True(t, d.Notify(&Event{ID: "1", BucketName: "dead"}))
+	assert.True(t, d.Notify(&Event{ID: "2", BucketName: "dead"}))
+	assert.True(t, d.Notify(&Event{ID: "3", BucketName: "alive"}))
+	select {
+	case id := <-received:
+		assert.Equal(t, "3", id)
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout waiting for webhook delivery")
+	}
+	assert.Equal(t, int32(1), deadCalls.Load())
+	d.retryMu.Lock()
+	assert.Equal(t, 2, d.pending.Len())
+	d.retryMu.Unlock()
+}
+
+func TestDispatcher_Backoff(t *testing.T) {
+	d := &Dispatcher{cfg: Config{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}}
+	assert.Equal(t, time.Second, d.backoff(0))
+	assert.Equal(t, 4*time.Second, d.backoff(2))
+	assert.Equal(t, 5*time.Second, d.backoff(3))
+	assert.Equal(t, 5*time.Second, d.backoff(100))
+}
+
+func TestDispatcher_FilterAndResolveError(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer server.Close()
+
+	d := newTestDispatcher(0, func(ctx context.Context, event *Event) ([]*Subscription, error) {
+		if event.Type == EventObjectDeleted {
+			return nil, errors.New("mock error")
+		}
+		return []*Subscription{{URL: server.URL, Events: []EventType{EventObjectSealed}}}, nil
+	})
+	defer d.Close()
+
+	d.dispatch(&Event{ID: "1", Type: EventObjectDeleted})
+	d.dispatch(&Event{ID: "2", Type: EventObjectUploaded})
+	assert.Equal(t, int32(0), calls.Load())
+	d.dispatch(&Event{ID: "3", Type: EventObjectSealed})
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestDispatcher_NotifyAfterClose(t *testing.T) {
+	d := newTestDispatcher(0, func(ctx context.Context, event *Event) ([]*Subscription, error) {
+		return nil, nil
+	})
+	d.Close()
+	d.Close()
+	assert.False(t, d.Notify(&Event{Type: EventObjectSealed}))
+}
@@ -0,0 +1,73 @@
+package webhook
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// SecretKeyEnv defines the environment variable of the key which encrypts the subscription secrets.
+const SecretKeyEnv = "WEBHOOK_SECRET_KEY"
+
+// SecretCipherKeyLength defines the length of the key which encrypts the subscription secrets, the key is
+// configured as the hex string of 32 bytes.
+const SecretCipherKeyLength = 32
+
+// ErrInvalidSealedSecret is returned if the stored secret is not sealed by the key.
+var ErrInvalidSealedSecret = errors.New("invalid sealed webhook secret")
+
+// SecretCipher encrypts the subscription secrets by aes-gcm before they are stored in SPDB, the secret must
+// be recovered to sign the deliveries, so it can not be hashed.
+type SecretCipher struct {
+	aead cipher.AEAD
+}
+
+// NewSecretCipher returns a SecretCipher of the hex encoded key.
+func NewSecretCipher(hexKey string) (*SecretCipher, error) {
+	key, err := hex.DecodeString(hexKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode webhook secret key: %w", err)
+	}
+	if len(key) != SecretCipherKeyLength {
+		return nil, fmt.Errorf("webhook secret key must be %d bytes, got %d", SecretCipherKeyLength, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &SecretCipher{aead: aead}, nil
+}
+
+// Seal encrypts the secret, the owner address is bound as the additional data so that the sealed secret can
+// not be copied to another subscription.
+func (c *SecretCipher) Seal(owner, secret string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(secret), []byte(owner))
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts the secret sealed by Seal.
+func (c *SecretCipher) Open(owner, sealed string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(data) < c.aead.NonceSize() {
+		return "", ErrInvalidSealedSecret
+	}
+	nonce, ciphertext := data[:c.aead.NonceSize()], data[c.aead.NonceSize():]
+	secret, err := c.aead.Open(nil, nonce, ciphertext, []byte(owner))
+	if err != nil {
+		return "", ErrInvalidSealedSecret
+	}
+	return string(secret), nil
+}
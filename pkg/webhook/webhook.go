@@ -0,0 +1,113 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+// EventType defines the type of the object or bucket event that is pushed to the subscribers.
+type EventType string
+
+const (
+	// EventObjectUploaded defines the event that the payload of object has been uploaded to the primary sp.
+	EventObjectUploaded EventType = "object.uploaded"
+	// EventObjectSealed defines the event that the object has been sealed on chain.
+	EventObjectSealed EventType = "object.sealed"
+	// EventObjectSealFailed defines the event that the object failed to seal and is rejected on chain.
+	EventObjectSealFailed EventType = "object.seal_failed"
+	// EventObjectDeleted defines the event that the object has been deleted and its pieces are gc'd.
+	EventObjectDeleted EventType = "object.deleted"
+	// EventBucketMigrationCompleted defines the event that the bucket migration has been completed.
+	EventBucketMigrationCompleted EventType = "bucket.migration_completed"
+)
+
+// AllEventTypes returns all the supported event types.
+func AllEventTypes() []EventType {
+	return []EventType{
+		EventObjectUploaded,
+		EventObjectSealed,
+		EventObjectSealFailed,
+		EventObjectDeleted,
+		EventBucketMigrationCompleted,
+	}
+}
+
+// IsValidEventType returns an indicator whether the event type is supported.
+func IsValidEventType(eventType string) bool {
+	for _, t := range AllEventTypes() {
+		if string(t) == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// define the http headers of the webhook request.
+const (
+	// HeaderEvent defines the event type of the delivery.
+	HeaderEvent = "X-Mechain-Webhook-Event"
+	// HeaderDelivery defines the unique id of the delivery, the same delivery id is kept on retry.
+	HeaderDelivery = "X-Mechain-Webhook-Delivery"
+	// HeaderTimestamp defines the unix timestamp(second) of the delivery, it is part of the signed content.
+	HeaderTimestamp = "X-Mechain-Webhook-Timestamp"
+	// HeaderSignature defines the hmac-sha256 signature of the delivery.
+	HeaderSignature = "X-Mechain-Webhook-Signature"
+	// SignaturePrefix defines the prefix of the signature header value.
+	SignaturePrefix = "sha256="
+)
+
+// Event defines the object or bucket event which is pushed to the subscribers.
+type Event struct {
+	ID          string    `json:"id"`
+	Type        EventType `json:"type"`
+	Timestamp   int64     `json:"timestamp"`
+	BucketName  string    `json:"bucket_name"`
+	BucketID    uint64    `json:"bucket_id,omitempty"`
+	BucketOwner string    `json:"bucket_owner,omitempty"`
+	ObjectName  string    `json:"object_name,omitempty"`
+	ObjectID    uint64    `json:"object_id,omitempty"`
+	Detail      string    `json:"detail,omitempty"`
+}
+
+// Subscription defines the webhook endpoint of a bucket owner.
+type Subscription struct {
+	OwnerAddress string
+	URL          string
+	Secret       string
+	// Events is the event type filter, empty means subscribing all events.
+	Events []EventType
+}
+
+// Accepts returns an indicator whether the subscription subscribes the event type.
+func (s *Subscription) Accepts(eventType EventType) bool {
+	if len(s.Events) == 0 {
+		return true
+	}
+	for _, t := range s.Events {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Sign returns the hmac-sha256 signature of the delivery, the signed content is
+// the timestamp and the body joined by ".".
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return SignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature of the delivery, it is used by the receiver side.
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	if !strings.HasPrefix(signature, SignaturePrefix) {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
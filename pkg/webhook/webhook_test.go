@@ -0,0 +1,58 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsValidEventType(t *testing.T) {
+	for _, eventType := range AllEventTypes() {
+		assert.True(t, IsValidEventType(string(eventType)))
+	}
+	assert.False(t, IsValidEventType("object.unknown"))
+	assert.False(t, IsValidEventType(""))
+}
+
+func TestSubscription_Accepts(t *testing.T) {
+	sub := &Subscription{}
+	assert.True(t, sub.Accepts(EventObjectSealed))
+
+	sub.Events = []EventType{EventObjectSealed, EventObjectDeleted}
+	assert.True(t, sub.Accepts(EventObjectSealed))
+	assert.True(t, sub.Accepts(EventObjectDeleted))
+	assert.False(t, sub.Accepts(EventObjectUploaded))
+}
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"id":"1","type":"object.sealed"}`)
+	signature := Sign("secret", 1700000000, body)
+	assert.Equal(t, SignaturePrefix, signature[:len(SignaturePrefix)])
+	assert.True(t, Verify("secret", 1700000000, body, signature))
+	assert.False(t, Verify("other-secret", 1700000000, body, signature))
+	assert.False(t, Verify("secret", 1700000001, body, signature))
+	assert.False(t, Verify("secret", 1700000000, []byte("tampered"), signature))
+	assert.False(t, Verify("secret", 1700000000, body, signature[len(SignaturePrefix):]))
+}
+
+func TestSecretCipher(t *testing.T) {
+	_, err := NewSecretCipher("invalid")
+	assert.NotNil(t, err)
+	_, err = NewSecretCipher("00112233")
+	assert.NotNil(t, err)
+
+	c, err := NewSecretCipher("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	assert.Nil(t, err)
+	sealed, err := c.Seal("owner", "secret-of-the-owner")
+	assert.Nil(t, err)
+	assert.NotContains(t, sealed, "secret-of-the-owner")
+	secret, err := c.Open("owner", sealed)
+	assert.Nil(t, err)
+	assert.Equal(t, "secret-of-the-owner", secret)
+
+	// the sealed secret is bound to the owner
+	_, err = c.Open("other-owner", sealed)
+	assert.Equal(t, ErrInvalidSealedSecret, err)
+	_, err = c.Open("owner", "secret-of-the-owner")
+	assert.Equal(t, ErrInvalidSealedSecret, err)
+}
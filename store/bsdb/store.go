@@ -1,6 +1,7 @@
 package bsdb
 
 import (
+	"context"
 	"fmt"
 	syslog "log"
 	"os"
@@ -35,6 +36,15 @@ func NewBsDB(cfg *gfspconfig.GfSpConfig) (*BsDBImpl, error) {
 	return &BsDBImpl{db: db}, nil
 }
 
+// Ping verifies the connection to the block syncer database is still alive.
+func (b *BsDBImpl) Ping(ctx context.Context) error {
+	db, err := b.db.DB()
+	if err != nil {
+		return err
+	}
+	return db.PingContext(ctx)
+}
+
 // InitDB init a block syncer db instance
 func InitDB(config *config.SQLDBConfig) (*gorm.DB, error) {
 	dsn := fmt.Sprintf("%s:%s@tcp(%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
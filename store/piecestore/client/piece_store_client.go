@@ -6,9 +6,13 @@ import (
 	"io"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
 	corepiecestore "github.com/zkMeLabs/mechain-storage-provider/core/piecestore"
 	"github.com/zkMeLabs/mechain-storage-provider/pkg/log"
 	"github.com/zkMeLabs/mechain-storage-provider/pkg/metrics"
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/tracing"
 	"github.com/zkMeLabs/mechain-storage-provider/store/piecestore/piece"
 	"github.com/zkMeLabs/mechain-storage-provider/store/piecestore/storage"
 )
@@ -57,7 +61,9 @@ func NewStoreClient(pieceConfig *storage.PieceStoreConfig) (*StoreClient, error)
 // GetPiece gets piece data from piece store.
 func (client *StoreClient) GetPiece(ctx context.Context, key string, offset, limit int64) (data []byte, err error) {
 	startTime := time.Now()
+	ctx, span := client.startSpan(ctx, "GetPiece", key)
 	defer func() {
+		tracing.EndSpan(span, err)
 		if err != nil {
 			metrics.PieceStoreCounter.WithLabelValues(PieceStoreFailureGet).Inc()
 			metrics.PieceStoreTime.WithLabelValues(PieceStoreFailureGet).Observe(
@@ -89,7 +95,9 @@ func (client *StoreClient) PutPiece(ctx context.Context, key string, value []byt
 		startTime = time.Now()
 		err       error
 	)
+	ctx, span := client.startSpan(ctx, "PutPiece", key)
 	defer func() {
+		tracing.EndSpan(span, err)
 		if err != nil {
 			metrics.PieceStoreCounter.WithLabelValues(PieceStoreFailurePut).Inc()
 			metrics.PieceStoreTime.WithLabelValues(PieceStoreFailurePut).Observe(
@@ -112,7 +120,9 @@ func (client *StoreClient) DeletePiece(ctx context.Context, key string) error {
 		err       error
 		valSize   int
 	)
+	ctx, span := client.startSpan(ctx, "DeletePiece", key)
 	defer func() {
+		tracing.EndSpan(span, err)
 		if err != nil {
 			metrics.PieceStoreCounter.WithLabelValues(PieceStoreFailureDel).Inc()
 			metrics.PieceStoreTime.WithLabelValues(PieceStoreFailureDel).Observe(
@@ -140,7 +150,9 @@ func (client *StoreClient) DeletePiecesByPrefix(ctx context.Context, key string)
 		err       error
 		valSize   uint64
 	)
+	ctx, span := client.startSpan(ctx, "DeletePiecesByPrefix", key)
 	defer func() {
+		tracing.EndSpan(span, err)
 		if err != nil {
 			metrics.PieceStoreCounter.WithLabelValues(PieceStoreFailureDel).Inc()
 			metrics.PieceStoreTime.WithLabelValues(PieceStoreFailureDel).Observe(
@@ -157,3 +169,16 @@ func (client *StoreClient) DeletePiecesByPrefix(ctx context.Context, key string)
 
 	return valSize, err
 }
+
+// HeadBucket checks the bucket of the piece store is accessible, it is used by the health checks.
+func (client *StoreClient) HeadBucket(ctx context.Context) (err error) {
+	ctx, span := client.startSpan(ctx, "HeadBucket", "")
+	defer func() { tracing.EndSpan(span, err) }()
+	return client.ps.HeadBucket(ctx)
+}
+
+// startSpan starts the span of the piece store operation as the child of the span in ctx.
+func (client *StoreClient) startSpan(ctx context.Context, operation, key string) (context.Context, trace.Span) {
+	return tracing.StartSpan(ctx, "piecestore."+operation,
+		attribute.String("piecestore.storage", client.name), attribute.String("piecestore.key", key))
+}
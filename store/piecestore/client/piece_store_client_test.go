@@ -218,3 +218,24 @@ func TestDeletePieceGetFailure(t *testing.T) {
 	err = client.DeletePiece(context.Background(), "mock")
 	assert.Equal(t, errors.New("failed to get"), err)
 }
+
+func TestHeadBucket(t *testing.T) {
+	cfg := &storage.PieceStoreConfig{
+		Shards: 0,
+		Store: storage.ObjectStorageConfig{
+			Storage:   storage.MemoryStore,
+			BucketURL: "mock",
+			IAMType:   storage.AKSKIAMType,
+		},
+	}
+	client, err := NewStoreClient(cfg)
+	assert.Nil(t, err)
+	assert.Nil(t, client.HeadBucket(context.Background()))
+
+	ctrl := gomock.NewController(t)
+	p := piece.NewMockPieceAPI(ctrl)
+	p.EXPECT().HeadBucket(gomock.Any()).Return(errors.New("failed to head bucket")).Times(1)
+	client.ps = p
+	err = client.HeadBucket(context.Background())
+	assert.Equal(t, errors.New("failed to head bucket"), err)
+}
@@ -15,6 +15,7 @@ type PieceAPI interface {
 	Put(ctx context.Context, key string, reader io.Reader) error
 	Delete(ctx context.Context, key string) error
 	DeleteByPrefix(ctx context.Context, key string) (uint64, error)
+	HeadBucket(ctx context.Context) error
 }
 
 type PieceStore struct {
@@ -45,3 +46,8 @@ func (p *PieceStore) DeleteByPrefix(ctx context.Context, key string) (uint64, er
 func (p *PieceStore) Head(ctx context.Context, key string) (storage.Object, error) {
 	return p.storeAPI.HeadObject(ctx, key)
 }
+
+// HeadBucket checks the bucket of PieceStore is accessible
+func (p *PieceStore) HeadBucket(ctx context.Context) error {
+	return p.storeAPI.HeadBucket(ctx)
+}
@@ -84,6 +84,20 @@ func (mr *MockPieceAPIMockRecorder) Get(ctx, key, offset, limit any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockPieceAPI)(nil).Get), ctx, key, offset, limit)
 }
 
+// HeadBucket mocks base method.
+func (m *MockPieceAPI) HeadBucket(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HeadBucket", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// HeadBucket indicates an expected call of HeadBucket.
+func (mr *MockPieceAPIMockRecorder) HeadBucket(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HeadBucket", reflect.TypeOf((*MockPieceAPI)(nil).HeadBucket), ctx)
+}
+
 // Put mocks base method.
 func (m *MockPieceAPI) Put(ctx context.Context, key string, reader io.Reader) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,79 @@
+package sqldb
+
+import (
+	"fmt"
+
+	"gorm.io/gorm/clause"
+
+	corespdb "github.com/zkMeLabs/mechain-storage-provider/core/spdb"
+)
+
+// SetAccountRateLimit inserts or overwrites the rate limit override of the account
+func (s *SpDBImpl) SetAccountRateLimit(limit *corespdb.AccountRateLimit) error {
+	record := &AccountRateLimitTable{
+		Account:               limit.Account,
+		ReadRateLimit:         limit.ReadRateLimit,
+		WriteRateLimit:        limit.WriteRateLimit,
+		Banned:                limit.Banned,
+		Reason:                limit.Reason,
+		ExpireTimestampSecond: limit.ExpireTimestampSecond,
+		UpdateTimestampSecond: limit.UpdateTimestampSecond,
+	}
+	err := s.db.Table(AccountRateLimitTableName).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "account"}},
+		DoUpdates: clause.AssignmentColumns([]string{"read_rate_limit", "write_rate_limit", "banned", "reason",
+			"expire_timestamp_second", "update_timestamp_second"}),
+	}).Create(record).Error
+	if err != nil {
+		return fmt.Errorf("failed to set record in AccountRateLimitTable: %s", err)
+	}
+	return nil
+}
+
+// GetAccountRateLimit queries the rate limit override of the account, returns (nil, nil) if not found
+func (s *SpDBImpl) GetAccountRateLimit(account string) (*corespdb.AccountRateLimit, error) {
+	queryReturn := &AccountRateLimitTable{}
+	result := s.db.First(queryReturn, "account = ?", account)
+	if result.Error != nil {
+		if errIsNotFound(result.Error) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to query AccountRateLimitTable: %s", result.Error)
+	}
+	return toAccountRateLimit(queryReturn), nil
+}
+
+// DeleteAccountRateLimit deletes the rate limit override of the account
+func (s *SpDBImpl) DeleteAccountRateLimit(account string) error {
+	err := s.db.Where("account = ?", account).Delete(&AccountRateLimitTable{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete record in AccountRateLimitTable: %s", err)
+	}
+	return nil
+}
+
+// ListAccountRateLimits queries all the rate limit overrides of the accounts
+func (s *SpDBImpl) ListAccountRateLimits() ([]*corespdb.AccountRateLimit, error) {
+	var queryReturns []AccountRateLimitTable
+	result := s.db.Find(&queryReturns)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list AccountRateLimitTable: %s", result.Error)
+	}
+	limits := make([]*corespdb.AccountRateLimit, 0, len(queryReturns))
+	for i := range queryReturns {
+		limits = append(limits, toAccountRateLimit(&queryReturns[i]))
+	}
+	return limits, nil
+}
+
+func toAccountRateLimit(record *AccountRateLimitTable) *corespdb.AccountRateLimit {
+	return &corespdb.AccountRateLimit{
+		Account:               record.Account,
+		ReadRateLimit:         record.ReadRateLimit,
+		WriteRateLimit:        record.WriteRateLimit,
+		Banned:                record.Banned,
+		Reason:                record.Reason,
+		ExpireTimestampSecond: record.ExpireTimestampSecond,
+		UpdateTimestampSecond: record.UpdateTimestampSecond,
+	}
+}
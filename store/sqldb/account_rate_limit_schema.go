@@ -0,0 +1,17 @@
+package sqldb
+
+// AccountRateLimitTable table schema
+type AccountRateLimitTable struct {
+	Account               string `gorm:"primary_key;type:varchar(64)"`
+	ReadRateLimit         int64
+	WriteRateLimit        int64
+	Banned                bool
+	Reason                string `gorm:"type:varchar(256)"`
+	ExpireTimestampSecond int64
+	UpdateTimestampSecond int64
+}
+
+// TableName is used to set AccountRateLimit Schema's table name in database
+func (AccountRateLimitTable) TableName() string {
+	return AccountRateLimitTableName
+}
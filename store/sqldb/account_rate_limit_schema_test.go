@@ -0,0 +1,13 @@
+package sqldb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountRateLimitTable_TableName(t *testing.T) {
+	table := AccountRateLimitTable{Account: "mockAccount"}
+	result := table.TableName()
+	assert.Equal(t, AccountRateLimitTableName, result)
+}
@@ -0,0 +1,120 @@
+package sqldb
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	corespdb "github.com/zkMeLabs/mechain-storage-provider/core/spdb"
+)
+
+const (
+	mockAccountRateLimitInsertSQL = "INSERT INTO `account_rate_limit` (`account`,`read_rate_limit`,`write_rate_limit`,`banned`,`reason`,`expire_timestamp_second`,`update_timestamp_second`) VALUES (?,?,?,?,?,?,?) ON DUPLICATE KEY UPDATE `read_rate_limit`=VALUES(`read_rate_limit`),`write_rate_limit`=VALUES(`write_rate_limit`),`banned`=VALUES(`banned`),`reason`=VALUES(`reason`),`expire_timestamp_second`=VALUES(`expire_timestamp_second`),`update_timestamp_second`=VALUES(`update_timestamp_second`)"
+	mockAccountRateLimitQuerySQL  = "SELECT * FROM `account_rate_limit` WHERE account = ? ORDER BY `account_rate_limit`.`account` LIMIT 1"
+	mockAccountRateLimitListSQL   = "SELECT * FROM `account_rate_limit`"
+	mockAccountRateLimitDeleteSQL = "DELETE FROM `account_rate_limit` WHERE account = ?"
+	mockAccountRateLimitAccount   = "0x76d244ce05c3de4bbc6fdd7f56379b145709ade9"
+)
+
+var mockAccountRateLimitColumns = []string{"account", "read_rate_limit", "write_rate_limit", "banned", "reason",
+	"expire_timestamp_second", "update_timestamp_second"}
+
+func TestSpDBImpl_SetAccountRateLimitSuccess(t *testing.T) {
+	limit := &corespdb.AccountRateLimit{
+		Account:               mockAccountRateLimitAccount,
+		ReadRateLimit:         10,
+		Banned:                true,
+		Reason:                "abuse",
+		ExpireTimestampSecond: 2,
+		UpdateTimestampSecond: 1,
+	}
+	s, mock := setupDB(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(mockAccountRateLimitInsertSQL).WithArgs(limit.Account, limit.ReadRateLimit, limit.WriteRateLimit,
+		limit.Banned, limit.Reason, limit.ExpireTimestampSecond, limit.UpdateTimestampSecond).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	err := s.SetAccountRateLimit(limit)
+	assert.Nil(t, err)
+}
+
+func TestSpDBImpl_SetAccountRateLimitFailure(t *testing.T) {
+	limit := &corespdb.AccountRateLimit{Account: mockAccountRateLimitAccount}
+	s, mock := setupDB(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(mockAccountRateLimitInsertSQL).WillReturnError(mockDBInternalError)
+	mock.ExpectRollback()
+	mock.ExpectCommit()
+	err := s.SetAccountRateLimit(limit)
+	assert.Contains(t, err.Error(), mockDBInternalError.Error())
+}
+
+func TestSpDBImpl_GetAccountRateLimitSuccess1(t *testing.T) {
+	t.Log("Success case description: query db and has data")
+	s, mock := setupDB(t)
+	mock.ExpectQuery(mockAccountRateLimitQuerySQL).WithArgs(mockAccountRateLimitAccount).
+		WillReturnRows(sqlmock.NewRows(mockAccountRateLimitColumns).AddRow(mockAccountRateLimitAccount, 10, 0, true, "abuse", 2, 1))
+	result, err := s.GetAccountRateLimit(mockAccountRateLimitAccount)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10), result.ReadRateLimit)
+	assert.True(t, result.Banned)
+	assert.Equal(t, "abuse", result.Reason)
+	assert.Equal(t, int64(2), result.ExpireTimestampSecond)
+}
+
+func TestSpDBImpl_GetAccountRateLimitSuccess2(t *testing.T) {
+	t.Log("Success case description: query db and no record")
+	s, mock := setupDB(t)
+	mock.ExpectQuery(mockAccountRateLimitQuerySQL).WithArgs(mockAccountRateLimitAccount).WillReturnError(gorm.ErrRecordNotFound)
+	result, err := s.GetAccountRateLimit(mockAccountRateLimitAccount)
+	assert.Nil(t, err)
+	assert.Nil(t, result)
+}
+
+func TestSpDBImpl_GetAccountRateLimitFailure(t *testing.T) {
+	s, mock := setupDB(t)
+	mock.ExpectQuery(mockAccountRateLimitQuerySQL).WithArgs(mockAccountRateLimitAccount).WillReturnError(mockDBInternalError)
+	result, err := s.GetAccountRateLimit(mockAccountRateLimitAccount)
+	assert.Contains(t, err.Error(), mockDBInternalError.Error())
+	assert.Nil(t, result)
+}
+
+func TestSpDBImpl_ListAccountRateLimitsSuccess(t *testing.T) {
+	s, mock := setupDB(t)
+	mock.ExpectQuery(mockAccountRateLimitListSQL).WillReturnRows(sqlmock.NewRows(mockAccountRateLimitColumns).
+		AddRow(mockAccountRateLimitAccount, 10, 0, false, "", 0, 1).AddRow("0x01", 0, 0, true, "abuse", 2, 1))
+	result, err := s.ListAccountRateLimits()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(result))
+	assert.Equal(t, mockAccountRateLimitAccount, result[0].Account)
+	assert.True(t, result[1].Banned)
+}
+
+func TestSpDBImpl_ListAccountRateLimitsFailure(t *testing.T) {
+	s, mock := setupDB(t)
+	mock.ExpectQuery(mockAccountRateLimitListSQL).WillReturnError(mockDBInternalError)
+	result, err := s.ListAccountRateLimits()
+	assert.Contains(t, err.Error(), mockDBInternalError.Error())
+	assert.Nil(t, result)
+}
+
+func TestSpDBImpl_DeleteAccountRateLimitSuccess(t *testing.T) {
+	s, mock := setupDB(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(mockAccountRateLimitDeleteSQL).WithArgs(mockAccountRateLimitAccount).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	err := s.DeleteAccountRateLimit(mockAccountRateLimitAccount)
+	assert.Nil(t, err)
+}
+
+func TestSpDBImpl_DeleteAccountRateLimitFailure(t *testing.T) {
+	s, mock := setupDB(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(mockAccountRateLimitDeleteSQL).WithArgs(mockAccountRateLimitAccount).WillReturnError(mockDBInternalError)
+	mock.ExpectRollback()
+	mock.ExpectCommit()
+	err := s.DeleteAccountRateLimit(mockAccountRateLimitAccount)
+	assert.Contains(t, err.Error(), mockDBInternalError.Error())
+}
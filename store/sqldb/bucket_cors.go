@@ -0,0 +1,61 @@
+package sqldb
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gorm.io/gorm/clause"
+
+	corespdb "github.com/zkMeLabs/mechain-storage-provider/core/spdb"
+)
+
+// SetBucketCORS inserts or overwrites the CORS rules of the bucket
+func (s *SpDBImpl) SetBucketCORS(cors *corespdb.BucketCORS) error {
+	rules, err := json.Marshal(cors.Rules)
+	if err != nil {
+		return fmt.Errorf("failed to marshal bucket cors rules: %s", err)
+	}
+	record := &BucketCORSTable{
+		BucketName:            cors.BucketName,
+		Rules:                 string(rules),
+		UpdateTimestampSecond: cors.UpdateTimestampSecond,
+	}
+	err = s.db.Table(BucketCORSTableName).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "bucket_name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rules", "update_timestamp_second"}),
+	}).Create(record).Error
+	if err != nil {
+		return fmt.Errorf("failed to set record in BucketCORSTable: %s", err)
+	}
+	return nil
+}
+
+// GetBucketCORS queries the CORS rules of the bucket, returns (nil, nil) if not found
+func (s *SpDBImpl) GetBucketCORS(bucketName string) (*corespdb.BucketCORS, error) {
+	queryReturn := &BucketCORSTable{}
+	result := s.db.First(queryReturn, "bucket_name = ?", bucketName)
+	if result.Error != nil {
+		if errIsNotFound(result.Error) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to query BucketCORSTable: %s", result.Error)
+	}
+	var rules []*corespdb.BucketCORSRule
+	if err := json.Unmarshal([]byte(queryReturn.Rules), &rules); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal bucket cors rules: %s", err)
+	}
+	return &corespdb.BucketCORS{
+		BucketName:            queryReturn.BucketName,
+		Rules:                 rules,
+		UpdateTimestampSecond: queryReturn.UpdateTimestampSecond,
+	}, nil
+}
+
+// DeleteBucketCORS deletes the CORS rules of the bucket
+func (s *SpDBImpl) DeleteBucketCORS(bucketName string) error {
+	err := s.db.Where("bucket_name = ?", bucketName).Delete(&BucketCORSTable{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete record in BucketCORSTable: %s", err)
+	}
+	return nil
+}
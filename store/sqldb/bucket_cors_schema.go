@@ -0,0 +1,13 @@
+package sqldb
+
+// BucketCORSTable table schema
+type BucketCORSTable struct {
+	BucketName            string `gorm:"primary_key;type:varchar(64)"`
+	Rules                 string `gorm:"type:text"` // json encoded rules
+	UpdateTimestampSecond int64
+}
+
+// TableName is used to set BucketCORS Schema's table name in database
+func (BucketCORSTable) TableName() string {
+	return BucketCORSTableName
+}
@@ -0,0 +1,13 @@
+package sqldb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBucketCORSTable_TableName(t *testing.T) {
+	table := BucketCORSTable{BucketName: "mockBucketName"}
+	result := table.TableName()
+	assert.Equal(t, BucketCORSTableName, result)
+}
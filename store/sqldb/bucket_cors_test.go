@@ -0,0 +1,115 @@
+package sqldb
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	corespdb "github.com/zkMeLabs/mechain-storage-provider/core/spdb"
+)
+
+const (
+	mockBucketCORSInsertSQL = "INSERT INTO `bucket_cors` (`bucket_name`,`rules`,`update_timestamp_second`) VALUES (?,?,?) ON DUPLICATE KEY UPDATE `rules`=VALUES(`rules`),`update_timestamp_second`=VALUES(`update_timestamp_second`)"
+	mockBucketCORSQuerySQL  = "SELECT * FROM `bucket_cors` WHERE bucket_name = ? ORDER BY `bucket_cors`.`bucket_name` LIMIT 1"
+	mockBucketCORSDeleteSQL = "DELETE FROM `bucket_cors` WHERE bucket_name = ?"
+	mockBucketCORSRules     = `[{"allowed_origins":["https://app.example.com"],"allowed_methods":["GET","PUT"],"max_age_seconds":600}]`
+)
+
+var mockBucketCORSColumns = []string{"bucket_name", "rules", "update_timestamp_second"}
+
+func TestSpDBImpl_SetBucketCORSSuccess(t *testing.T) {
+	cors := &corespdb.BucketCORS{
+		BucketName: "mockBucketName",
+		Rules: []*corespdb.BucketCORSRule{{
+			AllowedOrigins: []string{"https://app.example.com"},
+			AllowedMethods: []string{"GET", "PUT"},
+			MaxAgeSeconds:  600,
+		}},
+		UpdateTimestampSecond: 1,
+	}
+	s, mock := setupDB(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(mockBucketCORSInsertSQL).WithArgs(cors.BucketName, mockBucketCORSRules, cors.UpdateTimestampSecond).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	err := s.SetBucketCORS(cors)
+	assert.Nil(t, err)
+}
+
+func TestSpDBImpl_SetBucketCORSFailure(t *testing.T) {
+	cors := &corespdb.BucketCORS{BucketName: "mockBucketName"}
+	s, mock := setupDB(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(mockBucketCORSInsertSQL).WillReturnError(mockDBInternalError)
+	mock.ExpectRollback()
+	mock.ExpectCommit()
+	err := s.SetBucketCORS(cors)
+	assert.Contains(t, err.Error(), mockDBInternalError.Error())
+}
+
+func TestSpDBImpl_GetBucketCORSSuccess1(t *testing.T) {
+	t.Log("Success case description: query db and has data")
+	bucketName := "mockBucketName"
+	s, mock := setupDB(t)
+	mock.ExpectQuery(mockBucketCORSQuerySQL).WithArgs(bucketName).
+		WillReturnRows(sqlmock.NewRows(mockBucketCORSColumns).AddRow(bucketName, mockBucketCORSRules, 1))
+	result, err := s.GetBucketCORS(bucketName)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(result.Rules))
+	assert.Equal(t, []string{"GET", "PUT"}, result.Rules[0].AllowedMethods)
+	assert.Equal(t, int64(600), result.Rules[0].MaxAgeSeconds)
+}
+
+func TestSpDBImpl_GetBucketCORSSuccess2(t *testing.T) {
+	t.Log("Success case description: query db and no record")
+	bucketName := "mockBucketName"
+	s, mock := setupDB(t)
+	mock.ExpectQuery(mockBucketCORSQuerySQL).WithArgs(bucketName).WillReturnError(gorm.ErrRecordNotFound)
+	result, err := s.GetBucketCORS(bucketName)
+	assert.Nil(t, err)
+	assert.Nil(t, result)
+}
+
+func TestSpDBImpl_GetBucketCORSFailure1(t *testing.T) {
+	t.Log("Failure case description: query db returns error")
+	bucketName := "mockBucketName"
+	s, mock := setupDB(t)
+	mock.ExpectQuery(mockBucketCORSQuerySQL).WithArgs(bucketName).WillReturnError(mockDBInternalError)
+	result, err := s.GetBucketCORS(bucketName)
+	assert.Contains(t, err.Error(), mockDBInternalError.Error())
+	assert.Nil(t, result)
+}
+
+func TestSpDBImpl_GetBucketCORSFailure2(t *testing.T) {
+	t.Log("Failure case description: failed to unmarshal rules")
+	bucketName := "mockBucketName"
+	s, mock := setupDB(t)
+	mock.ExpectQuery(mockBucketCORSQuerySQL).WithArgs(bucketName).
+		WillReturnRows(sqlmock.NewRows(mockBucketCORSColumns).AddRow(bucketName, "{", 1))
+	result, err := s.GetBucketCORS(bucketName)
+	assert.NotNil(t, err)
+	assert.Nil(t, result)
+}
+
+func TestSpDBImpl_DeleteBucketCORSSuccess(t *testing.T) {
+	bucketName := "mockBucketName"
+	s, mock := setupDB(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(mockBucketCORSDeleteSQL).WithArgs(bucketName).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	err := s.DeleteBucketCORS(bucketName)
+	assert.Nil(t, err)
+}
+
+func TestSpDBImpl_DeleteBucketCORSFailure(t *testing.T) {
+	bucketName := "mockBucketName"
+	s, mock := setupDB(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(mockBucketCORSDeleteSQL).WithArgs(bucketName).WillReturnError(mockDBInternalError)
+	mock.ExpectRollback()
+	mock.ExpectCommit()
+	err := s.DeleteBucketCORS(bucketName)
+	assert.Contains(t, err.Error(), mockDBInternalError.Error())
+}
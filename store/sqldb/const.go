@@ -54,6 +54,12 @@ const (
 	RecoverFailedObjectTableName = "recover_failed_object"
 	// MigrateBucketProgressTableName defines the progress of migrate bucket.
 	MigrateBucketProgressTableName = "migrate_bucket_progress"
+	// WebhookSubscriptionTableName defines the webhook subscription table name of the bucket owners.
+	WebhookSubscriptionTableName = "webhook_subscription"
+	// BucketCORSTableName defines the CORS rules table name of the buckets.
+	BucketCORSTableName = "bucket_cors"
+	// AccountRateLimitTableName defines the rate limit override table name of the accounts.
+	AccountRateLimitTableName = "account_rate_limit"
 )
 
 // define error name constant.
@@ -1,6 +1,7 @@
 package sqldb
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"time"
@@ -67,6 +68,15 @@ func (s *SpDBImpl) RegisterStdDBStats() (prometheus.Collector, error) {
 	return collector, nil
 }
 
+// Ping verifies the connection to the database is still alive.
+func (s *SpDBImpl) Ping(ctx context.Context) error {
+	db, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+	return db.PingContext(ctx)
+}
+
 // InitDB init a db instance
 func InitDB(config *config.SQLDBConfig) (*gorm.DB, error) {
 	dsn := fmt.Sprintf("%s:%s@tcp(%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
@@ -76,6 +86,10 @@ func InitDB(config *config.SQLDBConfig) (*gorm.DB, error) {
 		log.Errorw("gorm failed to open db", "error", err)
 		return nil, err
 	}
+	if err = db.Use(&tracingPlugin{}); err != nil {
+		log.Errorw("gorm failed to use tracing plugin", "error", err)
+		return nil, err
+	}
 	sqlDB, err := db.DB()
 	if err != nil {
 		log.Errorw("gorm failed to set db params", "error", err)
@@ -184,6 +198,18 @@ func InitDB(config *config.SQLDBConfig) (*gorm.DB, error) {
 		log.Errorw("failed to create shadow integrity meta table", "error", err)
 		return nil, err
 	}
+	if err = db.AutoMigrate(&WebhookSubscriptionTable{}); err != nil && !isAlreadyExists(err) {
+		log.Errorw("failed to create webhook subscription table", "error", err)
+		return nil, err
+	}
+	if err = db.AutoMigrate(&BucketCORSTable{}); err != nil && !isAlreadyExists(err) {
+		log.Errorw("failed to create bucket cors table", "error", err)
+		return nil, err
+	}
+	if err = db.AutoMigrate(&AccountRateLimitTable{}); err != nil && !isAlreadyExists(err) {
+		log.Errorw("failed to create account rate limit table", "error", err)
+		return nil, err
+	}
 	return db, nil
 }
 
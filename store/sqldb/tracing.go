@@ -0,0 +1,83 @@
+package sqldb
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/tracing"
+)
+
+const (
+	tracingPluginName = "sp:tracing"
+	tracingSpanKey    = "sp:tracing_span"
+)
+
+// tracingPlugin creates a span for each sql statement whose context carries a span, the span is the child of
+// it. Most SPDB methods do not carry the request context, their statements are not traced rather than
+// starting an orphan trace per statement.
+type tracingPlugin struct{}
+
+var _ gorm.Plugin = &tracingPlugin{}
+
+func (p *tracingPlugin) Name() string {
+	return tracingPluginName
+}
+
+func (p *tracingPlugin) Initialize(db *gorm.DB) error {
+	callbacks := []struct {
+		operation string
+		before    func(string, func(*gorm.DB)) error
+		after     func(string, func(*gorm.DB)) error
+	}{
+		{"create", db.Callback().Create().Before("gorm:create").Register, db.Callback().Create().After("gorm:create").Register},
+		{"query", db.Callback().Query().Before("gorm:query").Register, db.Callback().Query().After("gorm:query").Register},
+		{"update", db.Callback().Update().Before("gorm:update").Register, db.Callback().Update().After("gorm:update").Register},
+		{"delete", db.Callback().Delete().Before("gorm:delete").Register, db.Callback().Delete().After("gorm:delete").Register},
+		{"row", db.Callback().Row().Before("gorm:row").Register, db.Callback().Row().After("gorm:row").Register},
+		{"raw", db.Callback().Raw().Before("gorm:raw").Register, db.Callback().Raw().After("gorm:raw").Register},
+	}
+	for _, c := range callbacks {
+		if err := c.before(tracingPluginName+":before_"+c.operation, startStatementSpan(c.operation)); err != nil {
+			return err
+		}
+		if err := c.after(tracingPluginName+":after_"+c.operation, endStatementSpan); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func startStatementSpan(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		if !trace.SpanContextFromContext(db.Statement.Context).IsValid() {
+			return
+		}
+		_, span := tracing.Tracer().Start(db.Statement.Context, "spdb."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemMySQL, semconv.DBOperation(operation)))
+		db.InstanceSet(tracingSpanKey, span)
+	}
+}
+
+func endStatementSpan(db *gorm.DB) {
+	value, ok := db.InstanceGet(tracingSpanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	span.SetAttributes(semconv.DBSQLTable(db.Statement.Table), semconv.DBStatement(db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.RowsAffected))
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,45 @@
+package sqldb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestTracingPlugin(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	s, mock := setupDB(t)
+	assert.Nil(t, s.db.Use(&tracingPlugin{}))
+	mock.ExpectExec("DELETE FROM mock_table WHERE id = ?").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT * FROM `mock_table` WHERE id = ? LIMIT 1").WithArgs(2).WillReturnError(mockDBInternalError)
+	mock.ExpectQuery("SELECT * FROM `mock_table` WHERE id = ? LIMIT 1").WithArgs(3).WillReturnError(mockDBInternalError)
+
+	// the span is the child of the span in the statement context
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+	assert.Nil(t, s.db.WithContext(ctx).Exec("DELETE FROM mock_table WHERE id = ?", 1).Error)
+	row := map[string]interface{}{}
+	assert.NotNil(t, s.db.WithContext(ctx).Table("mock_table").Where("id = ?", 2).Take(&row).Error)
+	parent.End()
+	// the statement without a span in its context is not traced
+	assert.NotNil(t, s.db.Table("mock_table").Where("id = ?", 3).Take(&row).Error)
+
+	spans := exporter.GetSpans()
+	assert.Equal(t, 3, len(spans))
+	assert.Equal(t, "spdb.raw", spans[0].Name)
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent.SpanID())
+	assert.Equal(t, codes.Unset, spans[0].Status.Code)
+	assert.Equal(t, "spdb.query", spans[1].Name)
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[1].Parent.SpanID())
+	assert.Equal(t, codes.Error, spans[1].Status.Code)
+	assert.Equal(t, "parent", spans[2].Name)
+}
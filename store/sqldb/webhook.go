@@ -0,0 +1,65 @@
+package sqldb
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm/clause"
+
+	corespdb "github.com/zkMeLabs/mechain-storage-provider/core/spdb"
+)
+
+const webhookEventsSeparator = ","
+
+// SetWebhookSubscription inserts or overwrites the webhook subscription of the bucket owner
+func (s *SpDBImpl) SetWebhookSubscription(sub *corespdb.WebhookSubscription) error {
+	record := &WebhookSubscriptionTable{
+		OwnerAddress:          sub.OwnerAddress,
+		URL:                   sub.URL,
+		Secret:                sub.Secret,
+		Events:                strings.Join(sub.Events, webhookEventsSeparator),
+		CreateTimestampSecond: sub.CreateTimestampSecond,
+		UpdateTimestampSecond: sub.UpdateTimestampSecond,
+	}
+	err := s.db.Table(WebhookSubscriptionTableName).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "owner_address"}},
+		DoUpdates: clause.AssignmentColumns([]string{"url", "secret", "events", "update_timestamp_second"}),
+	}).Create(record).Error
+	if err != nil {
+		return fmt.Errorf("failed to set record in WebhookSubscriptionTable: %s", err)
+	}
+	return nil
+}
+
+// GetWebhookSubscription queries the webhook subscription of the bucket owner, returns (nil, nil) if not found
+func (s *SpDBImpl) GetWebhookSubscription(ownerAddress string) (*corespdb.WebhookSubscription, error) {
+	queryReturn := &WebhookSubscriptionTable{}
+	result := s.db.First(queryReturn, "owner_address = ?", ownerAddress)
+	if result.Error != nil {
+		if errIsNotFound(result.Error) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to query WebhookSubscriptionTable: %s", result.Error)
+	}
+	var events []string
+	if queryReturn.Events != "" {
+		events = strings.Split(queryReturn.Events, webhookEventsSeparator)
+	}
+	return &corespdb.WebhookSubscription{
+		OwnerAddress:          queryReturn.OwnerAddress,
+		URL:                   queryReturn.URL,
+		Secret:                queryReturn.Secret,
+		Events:                events,
+		CreateTimestampSecond: queryReturn.CreateTimestampSecond,
+		UpdateTimestampSecond: queryReturn.UpdateTimestampSecond,
+	}, nil
+}
+
+// DeleteWebhookSubscription deletes the webhook subscription of the bucket owner
+func (s *SpDBImpl) DeleteWebhookSubscription(ownerAddress string) error {
+	err := s.db.Where("owner_address = ?", ownerAddress).Delete(&WebhookSubscriptionTable{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete record in WebhookSubscriptionTable: %s", err)
+	}
+	return nil
+}
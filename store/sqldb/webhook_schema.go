@@ -0,0 +1,16 @@
+package sqldb
+
+// WebhookSubscriptionTable table schema
+type WebhookSubscriptionTable struct {
+	OwnerAddress          string `gorm:"primary_key;type:varchar(42)"`
+	URL                   string `gorm:"type:varchar(1024)"`
+	Secret                string `gorm:"type:varchar(512)"`  // sealed by the webhook secret cipher
+	Events                string `gorm:"type:varchar(1024)"` // event types joined by ",", empty means all events
+	CreateTimestampSecond int64
+	UpdateTimestampSecond int64
+}
+
+// TableName is used to set WebhookSubscription Schema's table name in database
+func (WebhookSubscriptionTable) TableName() string {
+	return WebhookSubscriptionTableName
+}
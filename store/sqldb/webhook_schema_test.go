@@ -0,0 +1,13 @@
+package sqldb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookSubscriptionTable_TableName(t *testing.T) {
+	table := WebhookSubscriptionTable{OwnerAddress: mockUser}
+	result := table.TableName()
+	assert.Equal(t, WebhookSubscriptionTableName, result)
+}
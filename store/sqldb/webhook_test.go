@@ -0,0 +1,116 @@
+package sqldb
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	corespdb "github.com/zkMeLabs/mechain-storage-provider/core/spdb"
+)
+
+const (
+	mockWebhookSubscriptionInsertSQL = "INSERT INTO `webhook_subscription` (`owner_address`,`url`,`secret`,`events`,`create_timestamp_second`,`update_timestamp_second`) VALUES (?,?,?,?,?,?) ON DUPLICATE KEY UPDATE `url`=VALUES(`url`),`secret`=VALUES(`secret`),`events`=VALUES(`events`),`update_timestamp_second`=VALUES(`update_timestamp_second`)"
+	mockWebhookSubscriptionQuerySQL  = "SELECT * FROM `webhook_subscription` WHERE owner_address = ? ORDER BY `webhook_subscription`.`owner_address` LIMIT 1"
+	mockWebhookSubscriptionDeleteSQL = "DELETE FROM `webhook_subscription` WHERE owner_address = ?"
+)
+
+var mockWebhookSubscriptionColumns = []string{"owner_address", "url", "secret", "events", "create_timestamp_second",
+	"update_timestamp_second"}
+
+func TestSpDBImpl_SetWebhookSubscriptionSuccess(t *testing.T) {
+	sub := &corespdb.WebhookSubscription{
+		OwnerAddress:          "mockOwnerAddress",
+		URL:                   "https://example.com/hook",
+		Secret:                "mockSecret",
+		Events:                []string{"object.sealed", "object.deleted"},
+		CreateTimestampSecond: 1,
+		UpdateTimestampSecond: 2,
+	}
+	s, mock := setupDB(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(mockWebhookSubscriptionInsertSQL).
+		WithArgs(sub.OwnerAddress, sub.URL, sub.Secret, "object.sealed,object.deleted", sub.CreateTimestampSecond,
+			sub.UpdateTimestampSecond).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	err := s.SetWebhookSubscription(sub)
+	assert.Nil(t, err)
+}
+
+func TestSpDBImpl_SetWebhookSubscriptionFailure(t *testing.T) {
+	sub := &corespdb.WebhookSubscription{OwnerAddress: "mockOwnerAddress", URL: "https://example.com/hook"}
+	s, mock := setupDB(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(mockWebhookSubscriptionInsertSQL).WillReturnError(mockDBInternalError)
+	mock.ExpectRollback()
+	mock.ExpectCommit()
+	err := s.SetWebhookSubscription(sub)
+	assert.Contains(t, err.Error(), mockDBInternalError.Error())
+}
+
+func TestSpDBImpl_GetWebhookSubscriptionSuccess1(t *testing.T) {
+	t.Log("Success case description: query db and has data")
+	ownerAddress := "mockOwnerAddress"
+	s, mock := setupDB(t)
+	mock.ExpectQuery(mockWebhookSubscriptionQuerySQL).WithArgs(ownerAddress).
+		WillReturnRows(sqlmock.NewRows(mockWebhookSubscriptionColumns).
+			AddRow(ownerAddress, "https://example.com/hook", "mockSecret", "object.sealed,object.deleted", 1, 2))
+	result, err := s.GetWebhookSubscription(ownerAddress)
+	assert.Nil(t, err)
+	assert.Equal(t, "https://example.com/hook", result.URL)
+	assert.Equal(t, []string{"object.sealed", "object.deleted"}, result.Events)
+}
+
+func TestSpDBImpl_GetWebhookSubscriptionSuccess2(t *testing.T) {
+	t.Log("Success case description: query db and subscribe all events")
+	ownerAddress := "mockOwnerAddress"
+	s, mock := setupDB(t)
+	mock.ExpectQuery(mockWebhookSubscriptionQuerySQL).WithArgs(ownerAddress).
+		WillReturnRows(sqlmock.NewRows(mockWebhookSubscriptionColumns).
+			AddRow(ownerAddress, "https://example.com/hook", "mockSecret", "", 1, 2))
+	result, err := s.GetWebhookSubscription(ownerAddress)
+	assert.Nil(t, err)
+	assert.Nil(t, result.Events)
+}
+
+func TestSpDBImpl_GetWebhookSubscriptionSuccess3(t *testing.T) {
+	t.Log("Success case description: query db and no record")
+	ownerAddress := "mockOwnerAddress"
+	s, mock := setupDB(t)
+	mock.ExpectQuery(mockWebhookSubscriptionQuerySQL).WithArgs(ownerAddress).WillReturnError(gorm.ErrRecordNotFound)
+	result, err := s.GetWebhookSubscription(ownerAddress)
+	assert.Nil(t, err)
+	assert.Nil(t, result)
+}
+
+func TestSpDBImpl_GetWebhookSubscriptionFailure(t *testing.T) {
+	ownerAddress := "mockOwnerAddress"
+	s, mock := setupDB(t)
+	mock.ExpectQuery(mockWebhookSubscriptionQuerySQL).WithArgs(ownerAddress).WillReturnError(mockDBInternalError)
+	result, err := s.GetWebhookSubscription(ownerAddress)
+	assert.Contains(t, err.Error(), mockDBInternalError.Error())
+	assert.Nil(t, result)
+}
+
+func TestSpDBImpl_DeleteWebhookSubscriptionSuccess(t *testing.T) {
+	ownerAddress := "mockOwnerAddress"
+	s, mock := setupDB(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(mockWebhookSubscriptionDeleteSQL).WithArgs(ownerAddress).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	err := s.DeleteWebhookSubscription(ownerAddress)
+	assert.Nil(t, err)
+}
+
+func TestSpDBImpl_DeleteWebhookSubscriptionFailure(t *testing.T) {
+	ownerAddress := "mockOwnerAddress"
+	s, mock := setupDB(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(mockWebhookSubscriptionDeleteSQL).WillReturnError(mockDBInternalError)
+	mock.ExpectRollback()
+	mock.ExpectCommit()
+	err := s.DeleteWebhookSubscription(ownerAddress)
+	assert.Contains(t, err.Error(), mockDBInternalError.Error())
+}
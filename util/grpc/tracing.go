@@ -0,0 +1,166 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/tracing"
+)
+
+// GetTracingServerInterceptor returns the gRPC server interceptor which continues the trace propagated by
+// the client, the spans are dropped if the tracing is disabled.
+func GetTracingServerInterceptor() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(TracingUnaryServerInterceptor),
+		grpc.ChainStreamInterceptor(TracingStreamServerInterceptor),
+	}
+}
+
+// GetTracingClientInterceptor returns the gRPC client interceptor which propagates the trace to the server.
+func GetTracingClientInterceptor() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(TracingUnaryClientInterceptor),
+		grpc.WithChainStreamInterceptor(TracingStreamClientInterceptor),
+	}
+}
+
+// metadataCarrier adapts the gRPC metadata to the otel propagator.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// TracingUnaryServerInterceptor starts the server span of the unary call.
+func TracingUnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, span := startServerSpan(ctx, info.FullMethod)
+	resp, err := handler(ctx, req)
+	endSpan(span, err)
+	return resp, err
+}
+
+// TracingStreamServerInterceptor starts the server span of the stream call.
+func TracingStreamServerInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+	ctx, span := startServerSpan(ss.Context(), info.FullMethod)
+	err := handler(srv, &tracingServerStream{ServerStream: ss, ctx: ctx})
+	endSpan(span, err)
+	return err
+}
+
+// TracingUnaryClientInterceptor starts the client span of the unary call.
+func TracingUnaryClientInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	ctx, span := startClientSpan(ctx, method)
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	endSpan(span, err)
+	return err
+}
+
+// TracingStreamClientInterceptor starts the client span of the stream call, the span ends when the stream
+// is finished.
+func TracingStreamClientInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+	streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	ctx, span := startClientSpan(ctx, method)
+	cs, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		endSpan(span, err)
+		return nil, err
+	}
+	return &tracingClientStream{ClientStream: cs, span: span}, nil
+}
+
+type tracingServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *tracingServerStream) Context() context.Context {
+	return s.ctx
+}
+
+type tracingClientStream struct {
+	grpc.ClientStream
+	span  trace.Span
+	ended bool
+}
+
+func (s *tracingClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil && !s.ended {
+		s.ended = true
+		if errors.Is(err, io.EOF) {
+			err = nil
+		}
+		endSpan(s.span, err)
+	}
+	return err
+}
+
+func startServerSpan(ctx context.Context, fullMethod string) (context.Context, trace.Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+	return tracing.Tracer().Start(ctx, fullMethod,
+		trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(rpcAttributes(fullMethod)...))
+}
+
+func startClientSpan(ctx context.Context, fullMethod string) (context.Context, trace.Span) {
+	ctx, span := tracing.Tracer().Start(ctx, fullMethod,
+		trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(rpcAttributes(fullMethod)...))
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+	otel.GetTextMapPropagator().Inject(ctx, metadataCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md), span
+}
+
+func endSpan(span trace.Span, err error) {
+	s, _ := status.FromError(err)
+	span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(s.Code())))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, s.Message())
+	}
+	span.End()
+}
+
+// rpcAttributes splits the full method like /package.Service/Method into the semantic attributes.
+func rpcAttributes(fullMethod string) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{semconv.RPCSystemGRPC}
+	name := strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		attrs = append(attrs, semconv.RPCService(name[:i]), semconv.RPCMethod(name[i+1:]))
+	}
+	return attrs
+}
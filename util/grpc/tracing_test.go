@@ -0,0 +1,76 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestGetTracingServerInterceptor(t *testing.T) {
+	options := GetTracingServerInterceptor()
+	assert.Equal(t, 2, len(options))
+}
+
+func TestGetTracingClientInterceptor(t *testing.T) {
+	options := GetTracingClientInterceptor()
+	assert.Equal(t, 2, len(options))
+}
+
+func TestTracingPropagation(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	}()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	server := grpc.NewServer(GetTracingServerInterceptor()...)
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	options := append(GetTracingClientInterceptor(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.Dial(listener.Addr().String(), options...)
+	assert.Nil(t, err)
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "gateway")
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{})
+	assert.Nil(t, err)
+	// the unknown service fails
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{Service: "unknown"})
+	assert.NotNil(t, err)
+	parent.End()
+
+	spans := exporter.GetSpans()
+	assert.Equal(t, 5, len(spans))
+	var server0, client0 tracetest.SpanStub
+	for _, span := range spans {
+		assert.Equal(t, parent.SpanContext().TraceID(), span.SpanContext.TraceID())
+		if span.Name == healthpb.Health_Check_FullMethodName && span.SpanKind == trace.SpanKindServer && server0.Name == "" {
+			server0 = span
+		}
+		if span.Name == healthpb.Health_Check_FullMethodName && span.SpanKind == trace.SpanKindClient && client0.Name == "" {
+			client0 = span
+		}
+	}
+	// the server span is the child of the client span, which is the child of the gateway span
+	assert.Equal(t, client0.SpanContext.SpanID(), server0.Parent.SpanID())
+	assert.Equal(t, parent.SpanContext().SpanID(), client0.Parent.SpanID())
+}
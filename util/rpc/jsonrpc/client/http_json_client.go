@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"net/url"
 	"time"
+
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/tracing"
 )
 
 const (
@@ -127,8 +129,9 @@ func DefaultHTTPClient(remoteAddr string) (*http.Client, error) {
 
 	client := &http.Client{
 		// Please care about this timeout, it's the timeout for all request
-		Timeout:   10 * time.Minute,
-		Transport: transport,
+		Timeout: 10 * time.Minute,
+		// the chain requests are traced as the children of the span in the request context
+		Transport: tracing.NewTransport(transport),
 	}
 
 	return client, nil
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/zkMeLabs/mechain-storage-provider/pkg/tracing"
 )
 
 func Test_newParsedURL(t *testing.T) {
@@ -186,6 +188,7 @@ func TestDefaultHTTPClient(t *testing.T) {
 			} else {
 				assert.Nil(t, err)
 				assert.Equal(t, tt.wantedResult, result.Timeout)
+				assert.IsType(t, &tracing.Transport{}, result.Transport)
 			}
 		})
 	}